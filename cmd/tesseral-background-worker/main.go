@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
@@ -20,13 +21,22 @@ import (
 	"github.com/riverqueue/rivercontrib/otelriver"
 	"github.com/ssoready/conf"
 	svix "github.com/svix/svix-webhooks/go"
+	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/apikeyworker"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/auditlogstreamworker"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/backendapikeyworker"
 	"github.com/tesseral-labs/tesseral/internal/backgroundworker/emailworker"
 	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/userroleassignmentworker"
 	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
 	"github.com/tesseral-labs/tesseral/internal/common/sentryintegration"
 	"github.com/tesseral-labs/tesseral/internal/dbconn"
+	"github.com/tesseral-labs/tesseral/internal/emailevent"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
+	"github.com/tesseral-labs/tesseral/internal/kms"
 	"github.com/tesseral-labs/tesseral/internal/loadenv"
 	"github.com/tesseral-labs/tesseral/internal/multislog"
+	"github.com/tesseral-labs/tesseral/internal/restrictedhttp"
 	"github.com/tesseral-labs/tesseral/internal/secretload"
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/otel"
@@ -61,14 +71,22 @@ func main() {
 	loadenv.LoadEnv()
 
 	config := struct {
-		OTELExportTraces      bool          `conf:"otel_export_traces,noredact"`
-		OTLPTraceGRPCInsecure bool          `conf:"otlp_trace_grpc_insecure,noredact"`
-		ServeAddr             string        `conf:"serve_addr,noredact"`
-		DB                    dbconn.Config `conf:"db,noredact"`
-		SvixApiKey            string        `conf:"svix_api_key"`
-		SESBaseEndpoint       string        `conf:"ses_base_endpoint,noredact"`
-		ConsoleProjectID      string        `conf:"console_project_id,noredact"`
-		ConsoleDomain         string        `conf:"console_domain,noredact"`
+		OTELExportTraces         bool                  `conf:"otel_export_traces,noredact"`
+		OTLPTraceGRPCInsecure    bool                  `conf:"otlp_trace_grpc_insecure,noredact"`
+		ServeAddr                string                `conf:"serve_addr,noredact"`
+		DB                       dbconn.Config         `conf:"db,noredact"`
+		SvixApiKey               string                `conf:"svix_api_key"`
+		SESBaseEndpoint          string                `conf:"ses_base_endpoint,noredact"`
+		ConsoleProjectID         string                `conf:"console_project_id,noredact"`
+		ConsoleDomain            string                `conf:"console_domain,noredact"`
+		WebhookSigningSecretsKMS kms.Config            `conf:"webhook_signing_secrets_kms,noredact"`
+		AuditLogStreamSecretsKMS kms.Config            `conf:"audit_log_stream_secrets_kms,noredact"`
+		WebhookMaxAttempts       int                   `conf:"webhook_max_attempts,noredact"`
+		WebhookRetryBaseDelay    time.Duration         `conf:"webhook_retry_base_delay,noredact"`
+		WebhookRetryMaxDelay     time.Duration         `conf:"webhook_retry_max_delay,noredact"`
+		Egress                   restrictedhttp.Config `conf:"egress,noredact"`
+		Email                    emailsender.Config    `conf:"email,noredact"`
+		EmailEvents              emailevent.Config     `conf:"email_events,noredact"`
 	}{}
 
 	conf.Load(&config)
@@ -139,28 +157,87 @@ func main() {
 		panic(fmt.Errorf("load aws config: %w", err))
 	}
 
-	sesClient := sesv2.NewFromConfig(awsConfig, func(o *sesv2.Options) {
-		if config.SESBaseEndpoint != "" {
-			o.BaseEndpoint = &config.SESBaseEndpoint
-		}
-	})
+	var sesClient *sesv2.Client
+	if config.Email.UsesSES() {
+		sesClient = sesv2.NewFromConfig(awsConfig, func(o *sesv2.Options) {
+			if config.SESBaseEndpoint != "" {
+				o.BaseEndpoint = &config.SESBaseEndpoint
+			}
+		})
+	}
+
+	emailSender, err := emailsender.New(config.Email, sesClient)
+	if err != nil {
+		panic(fmt.Errorf("create email sender: %w", err))
+	}
+
+	webhookSigningSecretsKMS, err := kms.New(context.Background(), config.WebhookSigningSecretsKMS)
+	if err != nil {
+		panic(fmt.Errorf("create webhook signing secrets kms: %w", err))
+	}
+
+	auditLogStreamSecretsKMS, err := kms.New(context.Background(), config.AuditLogStreamSecretsKMS)
+	if err != nil {
+		panic(fmt.Errorf("create audit log stream secrets kms: %w", err))
+	}
+
+	egressPolicy, err := restrictedhttp.NewPolicy(config.Egress)
+	if err != nil {
+		panic(fmt.Errorf("create egress policy: %w", err))
+	}
 
 	backgroundStore := &store.Store{
-		DB:                      db,
-		Svix:                    svixClient,
-		DirectWebhookHTTPClient: &http.Client{},
-		SES:                     sesClient,
-		ConsoleProjectID:        config.ConsoleProjectID,
-		ConsoleDomain:           config.ConsoleDomain,
+		DB:                       db,
+		Svix:                     svixClient,
+		DirectWebhookHTTPClient:  egressPolicy.Client(),
+		EgressPolicy:             egressPolicy,
+		WebhookSigningSecretsKMS: webhookSigningSecretsKMS,
+		AuditLogStreamSecretsKMS: auditLogStreamSecretsKMS,
+		EmailSender:              emailSender,
+		ConsoleProjectID:         config.ConsoleProjectID,
+		ConsoleDomain:            config.ConsoleDomain,
+		AuditlogStore:            &auditlogstore.Store{},
 	}
 
 	riverWorkers := river.NewWorkers()
 	river.AddWorker(riverWorkers, &webhookworker.Worker{
 		Store: backgroundStore,
 	})
+	river.AddWorker(riverWorkers, &webhookworker.TypedEventWorker{
+		Store: backgroundStore,
+	})
+	river.AddWorker(riverWorkers, &webhookworker.OrderedWorker{
+		Store:       backgroundStore,
+		MaxAttempts: config.WebhookMaxAttempts,
+	})
+	river.AddWorker(riverWorkers, &webhookworker.PruneWorker{
+		Store: backgroundStore,
+	})
+	river.AddWorker(riverWorkers, &webhookworker.EndpointWorker{
+		Store:          backgroundStore,
+		MaxAttempts:    config.WebhookMaxAttempts,
+		RetryBaseDelay: config.WebhookRetryBaseDelay,
+		RetryMaxDelay:  config.WebhookRetryMaxDelay,
+	})
 	river.AddWorker(riverWorkers, &emailworker.Worker{
 		Store: backgroundStore,
 	})
+	river.AddWorker(riverWorkers, &auditlogstreamworker.Worker{
+		Store: backgroundStore,
+	})
+	river.AddWorker(riverWorkers, &auditlogstreamworker.EnqueueWorker{
+		Store: backgroundStore,
+	})
+	river.AddWorker(riverWorkers, &backendapikeyworker.ExpireWorker{
+		Store: backgroundStore,
+	})
+
+	river.AddWorker(riverWorkers, &apikeyworker.ExpirePreviousSecretTokensWorker{
+		Store: backgroundStore,
+	})
+	river.AddWorker(riverWorkers, &userroleassignmentworker.ExpireWorker{
+		Store: backgroundStore,
+	})
 
 	riverClient, err := river.NewClient(riverpgxv5.New(db), &river.Config{
 		Logger: slog.Default(),
@@ -173,6 +250,13 @@ func main() {
 			},
 		},
 		Workers: riverWorkers,
+		PeriodicJobs: []*river.PeriodicJob{
+			auditlogstreamworker.PeriodicJob(),
+			backendapikeyworker.PeriodicJob(),
+			apikeyworker.PeriodicJob(),
+			userroleassignmentworker.PeriodicJob(),
+			webhookworker.PrunePeriodicJob(),
+		},
 	})
 	if err != nil {
 		panic(fmt.Errorf("create river client: %w", err))
@@ -207,6 +291,14 @@ func main() {
 		_, _ = w.Write([]byte("ok"))
 	}))
 
+	// bounce, complaint, and delivery notifications
+	mux.Handle("/api/internal/email-events/", http.StripPrefix("/api/internal/email-events", emailevent.Handler(config.EmailEvents, &emailevent.SNSVerifier{HTTPClient: egressPolicy.Client()}, backgroundStore)))
+
+	// let developers read the emails the file backend writes
+	if config.Email.Backend == emailsender.BackendFile {
+		mux.Handle("/api/internal/mailbox/", http.StripPrefix("/api/internal/mailbox", emailsender.MailboxHandler(config.Email.FileDirectory)))
+	}
+
 	slog.Info("serve")
 	if err := http.ListenAndServe(config.ServeAddr, mux); err != nil {
 		panic(err)
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"connectrpc.com/connect"
 	"connectrpc.com/vanguard"
@@ -32,6 +37,7 @@ import (
 	backendstore "github.com/tesseral-labs/tesseral/internal/backend/store"
 	"github.com/tesseral-labs/tesseral/internal/backgroundworker/emailworker"
 	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
 	"github.com/tesseral-labs/tesseral/internal/cloudflaredoh"
 	"github.com/tesseral-labs/tesseral/internal/common/accesstoken"
 	"github.com/tesseral-labs/tesseral/internal/common/corstrusteddomains"
@@ -44,6 +50,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/dbconn"
 	defaultoauthservice "github.com/tesseral-labs/tesseral/internal/defaultoauth/service"
 	defaultoauthstore "github.com/tesseral-labs/tesseral/internal/defaultoauth/store"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	frontendinterceptor "github.com/tesseral-labs/tesseral/internal/frontend/authn/interceptor"
 	"github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1/frontendv1connect"
 	frontendservice "github.com/tesseral-labs/tesseral/internal/frontend/service"
@@ -75,6 +82,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/secretload"
 	"github.com/tesseral-labs/tesseral/internal/slogcorrelation"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webauthn"
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
@@ -110,44 +118,52 @@ func main() {
 	loadenv.LoadEnv()
 
 	config := struct {
-		OTELExportTraces                  bool          `conf:"otel_export_traces,noredact"`
-		OTLPTraceGRPCInsecure             bool          `conf:"otlp_trace_grpc_insecure,noredact"`
-		ConsoleDomain                     string        `conf:"console_domain,noredact"`
-		AuthAppsRootDomain                string        `conf:"auth_apps_root_domain,noredact"`
-		TesseralDNSVaultCNAMEValue        string        `conf:"tesseral_dns_vault_cname_value,noredact"`
-		SESSPFMXRecordValue               string        `conf:"ses_spf_mx_record_value,noredact"`
-		DB                                dbconn.Config `conf:"db,noredact"`
-		CloudflareAPIToken                string        `conf:"cloudflare_api_token"`
-		ConsoleProjectID                  string        `conf:"console_project_id,noredact"`
-		IntermediateSessionKMSKeyID       string        `conf:"intermediate_session_kms_key_id,noredact"`
-		KMSEndpoint                       string        `conf:"kms_endpoint_resolver_url,noredact"`
-		PageEncodingValue                 string        `conf:"page-encoding-value"`
-		S3UserContentBucketName           string        `conf:"s3_user_content_bucket_name,noredact"`
-		S3Endpoint                        string        `conf:"s3_endpoint_resolver_url,noredact"`
-		SESEndpoint                       string        `conf:"ses_endpoint_resolver_url,noredact"`
-		ServeAddr                         string        `conf:"serve_addr,noredact"`
-		SessionSigningKeysKMS             kms.Config    `conf:"session_signing_keys_kms,noredact"`
-		GithubOAuthClientSecretsKMS       kms.Config    `conf:"github_oauth_client_secrets_kms,noredact"`
-		GoogleOAuthClientSecretsKMS       kms.Config    `conf:"google_oauth_client_secrets_kms,noredact"`
-		MicrosoftOAuthClientSecretsKMS    kms.Config    `conf:"microsoft_oauth_client_secrets_kms,noredact"`
-		OIDCClientSecretsKMS              kms.Config    `conf:"oidc_client_secrets_kms,noredact"`
-		AuthenticatorAppSecretsKMS        kms.Config    `conf:"authenticator_app_secrets_kms,noredact"`
-		UserContentBaseUrl                string        `conf:"user_content_base_url,redact"`
-		TesseralDNSCloudflareZoneID       string        `conf:"tesseral_dns_cloudflare_zone_id,noredact"`
-		StripeAPIKey                      string        `conf:"stripe_api_key"`
-		StripePriceIDGrowthTier           string        `conf:"stripe_price_id_growth_tier,noredact"`
-		SvixApiKey                        string        `conf:"svix_api_key"`
-		DefaultGoogleOAuthClientID        string        `conf:"default_google_oauth_client_id,noredact"`
-		DefaultGoogleOAuthClientSecret    string        `conf:"default_google_oauth_client_secret"`
-		DefaultGoogleOAuthRedirectURI     string        `conf:"default_google_oauth_redirect_uri,noredact"`
-		DefaultMicrosoftOAuthClientID     string        `conf:"default_microsoft_oauth_client_id,noredact"`
-		DefaultMicrosoftOAuthClientSecret string        `conf:"default_microsoft_oauth_client_secret"`
-		DefaultMicrosoftOAuthRedirectURI  string        `conf:"default_microsoft_oauth_redirect_uri,noredact"`
-		DefaultGitHubOAuthClientID        string        `conf:"default_github_oauth_client_id,noredact"`
-		DefaultGitHubOAuthClientSecret    string        `conf:"default_github_oauth_client_secret"`
-		DefaultGitHubOAuthRedirectURI     string        `conf:"default_github_oauth_redirect_uri,noredact"`
+		OTELExportTraces                  bool                  `conf:"otel_export_traces,noredact"`
+		OTLPTraceGRPCInsecure             bool                  `conf:"otlp_trace_grpc_insecure,noredact"`
+		ConsoleDomain                     string                `conf:"console_domain,noredact"`
+		AuthAppsRootDomain                string                `conf:"auth_apps_root_domain,noredact"`
+		TesseralDNSVaultCNAMEValue        string                `conf:"tesseral_dns_vault_cname_value,noredact"`
+		SESSPFMXRecordValue               string                `conf:"ses_spf_mx_record_value,noredact"`
+		DB                                dbconn.Config         `conf:"db,noredact"`
+		CloudflareAPIToken                string                `conf:"cloudflare_api_token"`
+		ConsoleProjectID                  string                `conf:"console_project_id,noredact"`
+		IntermediateSessionKMSKeyID       string                `conf:"intermediate_session_kms_key_id,noredact"`
+		KMSEndpoint                       string                `conf:"kms_endpoint_resolver_url,noredact"`
+		PageEncodingValue                 string                `conf:"page-encoding-value"`
+		S3UserContentBucketName           string                `conf:"s3_user_content_bucket_name,noredact"`
+		S3Endpoint                        string                `conf:"s3_endpoint_resolver_url,noredact"`
+		SESEndpoint                       string                `conf:"ses_endpoint_resolver_url,noredact"`
+		ServeAddr                         string                `conf:"serve_addr,noredact"`
+		SessionSigningKeysKMS             kms.Config            `conf:"session_signing_keys_kms,noredact"`
+		GithubOAuthClientSecretsKMS       kms.Config            `conf:"github_oauth_client_secrets_kms,noredact"`
+		GoogleOAuthClientSecretsKMS       kms.Config            `conf:"google_oauth_client_secrets_kms,noredact"`
+		MicrosoftOAuthClientSecretsKMS    kms.Config            `conf:"microsoft_oauth_client_secrets_kms,noredact"`
+		OIDCClientSecretsKMS              kms.Config            `conf:"oidc_client_secrets_kms,noredact"`
+		AuthenticatorAppSecretsKMS        kms.Config            `conf:"authenticator_app_secrets_kms,noredact"`
+		WebhookSigningSecretsKMS          kms.Config            `conf:"webhook_signing_secrets_kms,noredact"`
+		AuditLogStreamSecretsKMS          kms.Config            `conf:"audit_log_stream_secrets_kms,noredact"`
+		UserContentBaseUrl                string                `conf:"user_content_base_url,redact"`
+		TesseralDNSCloudflareZoneID       string                `conf:"tesseral_dns_cloudflare_zone_id,noredact"`
+		StripeAPIKey                      string                `conf:"stripe_api_key"`
+		StripePriceIDGrowthTier           string                `conf:"stripe_price_id_growth_tier,noredact"`
+		SvixApiKey                        string                `conf:"svix_api_key"`
+		DefaultGoogleOAuthClientID        string                `conf:"default_google_oauth_client_id,noredact"`
+		DefaultGoogleOAuthClientSecret    string                `conf:"default_google_oauth_client_secret"`
+		DefaultGoogleOAuthRedirectURI     string                `conf:"default_google_oauth_redirect_uri,noredact"`
+		DefaultMicrosoftOAuthClientID     string                `conf:"default_microsoft_oauth_client_id,noredact"`
+		DefaultMicrosoftOAuthClientSecret string                `conf:"default_microsoft_oauth_client_secret"`
+		DefaultMicrosoftOAuthRedirectURI  string                `conf:"default_microsoft_oauth_redirect_uri,noredact"`
+		DefaultGitHubOAuthClientID        string                `conf:"default_github_oauth_client_id,noredact"`
+		DefaultGitHubOAuthClientSecret    string                `conf:"default_github_oauth_client_secret"`
+		DefaultGitHubOAuthRedirectURI     string                `conf:"default_github_oauth_redirect_uri,noredact"`
+		TrustedClientIPHeader             string                `conf:"trusted_client_ip_header,noredact"`
+		TrustedClientIPProxies            int                   `conf:"trusted_client_ip_proxies,noredact"`
+		Egress                            restrictedhttp.Config `conf:"egress,noredact"`
+		Email                             emailsender.Config    `conf:"email,noredact"`
+		FIDOMetadataPath                  string                `conf:"fido_metadata_path,noredact"`
 	}{
-		PageEncodingValue: "0000000000000000000000000000000000000000000000000000000000000000",
+		PageEncodingValue:      "0000000000000000000000000000000000000000000000000000000000000000",
+		TrustedClientIPProxies: 1,
 	}
 
 	conf.Load(&config)
@@ -254,6 +270,16 @@ func main() {
 		panic(fmt.Errorf("create authenticator app secrets kms: %w", err))
 	}
 
+	webhookSigningSecretsKMS, err := kms.New(context.Background(), config.WebhookSigningSecretsKMS)
+	if err != nil {
+		panic(fmt.Errorf("create webhook signing secrets kms: %w", err))
+	}
+
+	auditLogStreamSecretsKMS, err := kms.New(context.Background(), config.AuditLogStreamSecretsKMS)
+	if err != nil {
+		panic(fmt.Errorf("create audit log stream secrets kms: %w", err))
+	}
+
 	s3_ := s3.NewFromConfig(awsConfig, func(o *s3.Options) {
 		if config.S3Endpoint != "" {
 			o.BaseEndpoint = &config.S3Endpoint
@@ -261,11 +287,16 @@ func main() {
 		}
 	})
 
-	ses_ := sesv2.NewFromConfig(awsConfig, func(o *sesv2.Options) {
-		if config.SESEndpoint != "" {
-			o.BaseEndpoint = &config.SESEndpoint
-		}
-	})
+	// the internal api doesn't send email itself, but it manages the SES
+	// identities that custom email send-from domains require
+	var ses_ *sesv2.Client
+	if config.Email.UsesSES() {
+		ses_ = sesv2.NewFromConfig(awsConfig, func(o *sesv2.Options) {
+			if config.SESEndpoint != "" {
+				o.BaseEndpoint = &config.SESEndpoint
+			}
+		})
+	}
 
 	svixClient, err := svix.New(config.SvixApiKey, nil)
 	if err != nil {
@@ -279,6 +310,8 @@ func main() {
 	// https://riverqueue.com/docs#insert-only-clients
 	riverWorkers := river.NewWorkers()
 	river.AddWorker(riverWorkers, &webhookworker.Worker{})
+	river.AddWorker(riverWorkers, &webhookworker.TypedEventWorker{})
+	river.AddWorker(riverWorkers, &webhookworker.OrderedWorker{})
 	river.AddWorker(riverWorkers, &emailworker.Worker{})
 	riverClient, err := river.NewClient(riverpgxv5.New(db), &river.Config{
 		Middleware: []rivertype.Middleware{
@@ -299,10 +332,25 @@ func main() {
 	cookier := cookies.Cookier{Store: commonStore}
 
 	auditlogStore := auditlogstore.Store{}
+	egressPolicy, err := restrictedhttp.NewPolicy(config.Egress)
+	if err != nil {
+		panic(fmt.Errorf("create egress policy: %w", err))
+	}
+
+	// OIDC providers are customer-supplied, so requests to them are held to
+	// the egress policy: https only, unless egress.allow_http is set.
 	oidcClient := &oidcclient.Client{
-		HTTPClient: &http.Client{
-			Transport: restrictedhttp.NewTransport(),
-		},
+		HTTPClient: egressPolicy.Client(),
+	}
+
+	// passkey attestations are trusted against the FIDO Metadata Service blob
+	// downloaded by bin/update-fido-mds; without one, none are
+	fidoMetadata, err := webauthn.LoadMetadata(config.FIDOMetadataPath)
+	if err != nil {
+		panic(fmt.Errorf("load fido metadata: %w", err))
+	}
+	if fidoMetadata.Empty() {
+		slog.Warn("fido_metadata_empty")
 	}
 
 	// Register the backend service
@@ -315,6 +363,8 @@ func main() {
 		MicrosoftOAuthClientSecretsKMS: microsoftOAuthClientSecretsKMS,
 		GithubOAuthClientSecretsKMS:    githubOAuthClientSecretsKMS,
 		OIDCClientSecretsKMS:           oidcClientSecretsKMS,
+		WebhookSigningSecretsKMS:       webhookSigningSecretsKMS,
+		AuditLogStreamSecretsKMS:       auditLogStreamSecretsKMS,
 		SES:                            ses_,
 		PageEncoder:                    pagetoken.Encoder{Secret: pageEncodingValue},
 		S3:                             s3_,
@@ -331,8 +381,25 @@ func main() {
 		SvixClient:                     svixClient,
 		AuditlogStore:                  &auditlogStore,
 		OIDCClient:                     oidcClient,
+		FIDOMetadata:                   fidoMetadata,
 		RiverClient:                    riverClient,
 	})
+
+	// backend api key last-used times and ips, and api key request counts, are
+	// written in batches; on shutdown, the flushers write what they hold before
+	// returning
+	flushCtx, cancelFlush := context.WithCancel(context.Background())
+	var flushers sync.WaitGroup
+	flushers.Add(2)
+	go func() {
+		defer flushers.Done()
+		backendStore.RunBackendAPIKeyUsageFlusher(flushCtx)
+	}()
+	go func() {
+		defer flushers.Done()
+		backendStore.RunAPIKeyUsageFlusher(flushCtx)
+	}()
+
 	backendConnectPath, backendConnectHandler := backendv1connect.NewBackendServiceHandler(
 		&backendservice.Service{
 			Store: backendStore,
@@ -361,6 +428,7 @@ func main() {
 		SvixClient:                 svixClient,
 		AuditlogStore:              &auditlogStore,
 		OIDCClient:                 oidcClient,
+		FIDOMetadata:               fidoMetadata,
 		RiverClient:                riverClient,
 	})
 	frontendConnectPath, frontendConnectHandler := frontendv1connect.NewFrontendServiceHandler(
@@ -404,6 +472,7 @@ func main() {
 		StripeClient:                      stripeClient,
 		SvixClient:                        svixClient,
 		AuditlogStore:                     &auditlogStore,
+		FIDOMetadata:                      fidoMetadata,
 		DefaultGoogleOAuthClientID:        config.DefaultGoogleOAuthClientID,
 		DefaultGoogleOAuthClientSecret:    config.DefaultGoogleOAuthClientSecret,
 		DefaultGoogleOAuthRedirectURI:     config.DefaultGoogleOAuthRedirectURI,
@@ -435,6 +504,7 @@ func main() {
 	samlStore := samlstore.New(samlstore.NewStoreParams{
 		DB:            db,
 		AuditlogStore: &auditlogStore,
+		RiverClient:   riverClient,
 	})
 	samlService := samlservice.Service{
 		Store:             samlStore,
@@ -449,6 +519,7 @@ func main() {
 		OIDCClientSecretsKMS: oidcClientSecretsKMS,
 		OIDCClient:           oidcClient,
 		AuditlogStore:        &auditlogStore,
+		RiverClient:          riverClient,
 	})
 	oidcService := oidcservice.Service{
 		Store:             oidcStore,
@@ -461,6 +532,7 @@ func main() {
 	scimStore := scimstore.New(scimstore.NewStoreParams{
 		DB:            db,
 		AuditlogStore: &auditlogStore,
+		RiverClient:   riverClient,
 	})
 	scimService := scimservice.Service{
 		Store: scimStore,
@@ -527,6 +599,9 @@ func main() {
 	// add correlation IDs to logs
 	serve := slogcorrelation.NewHandler(mux)
 
+	// record client ips, for enforcing organization ip allowlists
+	serve = clientip.NewHandler(config.TrustedClientIPHeader, config.TrustedClientIPProxies, serve)
+
 	// wrap all http requests with sentry
 	serve = sentryhttp.New(sentryhttp.Options{
 		Repanic: true,
@@ -535,8 +610,34 @@ func main() {
 	// add traces
 	serve = otelhttp.NewHandler(serve, "serve")
 
+	server := &http.Server{Addr: config.ServeAddr, Handler: serve}
+
+	stopped := make(chan struct{})
+	go func() {
+		sigintOrTerm := make(chan os.Signal, 1)
+		signal.Notify(sigintOrTerm, syscall.SIGINT, syscall.SIGTERM)
+
+		<-sigintOrTerm
+
+		slog.Info("shutdown")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("shutdown", "error", err)
+		}
+
+		// flush the usage recorded by the requests drained above
+		slog.Info("flush_api_key_usage")
+		cancelFlush()
+		flushers.Wait()
+
+		close(stopped)
+	}()
+
 	slog.Info("serve")
-	if err := http.ListenAndServe(config.ServeAddr, serve); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		panic(err)
 	}
+
+	<-stopped
 }
@@ -0,0 +1,8 @@
+create table organization_ip_allowlist_cidrs
+(
+    id              uuid not null primary key,
+    organization_id uuid not null references organizations (id) on delete cascade,
+    cidr            cidr not null,
+
+    unique (organization_id, cidr)
+);
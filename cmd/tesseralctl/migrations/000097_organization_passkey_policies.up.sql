@@ -0,0 +1,12 @@
+alter table organizations
+    add column passkey_require_attestation boolean not null default false;
+
+create table organization_passkey_aaguids
+(
+    id              uuid    not null primary key,
+    organization_id uuid    not null references organizations (id) on delete cascade,
+    aaguid          uuid    not null,
+    allowed         boolean not null,
+
+    unique (organization_id, aaguid)
+);
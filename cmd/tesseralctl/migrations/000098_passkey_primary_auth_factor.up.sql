@@ -0,0 +1,4 @@
+alter type primary_auth_factor add value 'passkey';
+
+alter table intermediate_sessions
+    add column verified_passkey_id uuid references passkeys (id) on delete set null;
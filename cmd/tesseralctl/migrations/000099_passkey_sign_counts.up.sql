@@ -0,0 +1,5 @@
+alter table passkeys
+    add column sign_count bigint not null default 0;
+
+alter table organizations
+    add column passkey_require_user_verification boolean not null default false;
@@ -0,0 +1,49 @@
+create table project_password_policies
+(
+    project_id               uuid    not null primary key references projects (id) on delete cascade,
+    min_length               integer not null,
+    require_lowercase        boolean not null,
+    require_uppercase        boolean not null,
+    require_digit            boolean not null,
+    require_symbol           boolean not null,
+    disallow_personal_info   boolean not null,
+    history_count            integer not null,
+    max_age_days             integer not null,
+    lockout_attempts         integer not null,
+    lockout_duration_seconds integer not null
+);
+
+create table organization_password_policies
+(
+    organization_id          uuid    not null primary key references organizations (id) on delete cascade,
+    min_length               integer not null,
+    require_lowercase        boolean not null,
+    require_uppercase        boolean not null,
+    require_digit            boolean not null,
+    require_symbol           boolean not null,
+    disallow_personal_info   boolean not null,
+    history_count            integer not null,
+    max_age_days             integer not null,
+    lockout_attempts         integer not null,
+    lockout_duration_seconds integer not null
+);
+
+create table user_password_histories
+(
+    id              uuid                     not null primary key,
+    user_id         uuid                     not null references users (id) on delete cascade,
+    create_time     timestamp with time zone not null default now(),
+    password_bcrypt varchar                  not null
+);
+
+create index on user_password_histories (user_id, create_time desc);
+
+alter table users
+    add column password_update_time timestamp with time zone;
+
+update users
+set password_update_time = now()
+where password_bcrypt is not null;
+
+alter table intermediate_sessions
+    add column password_expired boolean not null default false;
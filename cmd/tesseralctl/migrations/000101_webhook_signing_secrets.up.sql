@@ -0,0 +1,4 @@
+alter table project_webhook_settings
+  add column signing_secret_ciphertext bytea,
+  add column previous_signing_secret_ciphertext bytea,
+  add column previous_signing_secret_expire_time timestamp with time zone;
@@ -0,0 +1,13 @@
+create table webhook_endpoints
+(
+    id          uuid                     not null primary key,
+    project_id  uuid                     not null references projects (id) on delete cascade,
+    url         varchar                  not null,
+    description varchar                  not null default '',
+    event_types varchar[]                not null default '{}',
+    enabled     boolean                  not null default true,
+    create_time timestamp with time zone not null default now(),
+    update_time timestamp with time zone not null default now()
+);
+
+create index on webhook_endpoints (project_id);
@@ -0,0 +1,22 @@
+create type webhook_delivery_state as enum ('succeeded', 'failed', 'dead_lettered');
+
+create table webhook_deliveries
+(
+    id                  uuid                     not null primary key,
+    project_id          uuid                     not null references projects (id) on delete cascade,
+    webhook_endpoint_id uuid                     references webhook_endpoints (id) on delete set null,
+    message_id          varchar                  not null,
+    event_type          varchar                  not null,
+    payload             jsonb                    not null,
+    url                 varchar                  not null,
+    attempt             integer                  not null,
+    state               webhook_delivery_state   not null,
+    status_code         integer,
+    latency_millis      integer                  not null,
+    response_body       varchar,
+    error               varchar,
+    create_time         timestamp with time zone not null default now()
+);
+
+create index on webhook_deliveries (project_id, id);
+create index on webhook_deliveries (webhook_endpoint_id, id);
@@ -0,0 +1,23 @@
+create type audit_log_stream_type as enum ('webhook', 'syslog', 'splunk_hec');
+
+create table audit_log_streams
+(
+    id                          uuid                     not null primary key,
+    project_id                  uuid                     not null references projects (id) on delete cascade,
+    organization_id             uuid references organizations (id) on delete cascade,
+    display_name                varchar                  not null,
+    type                        audit_log_stream_type    not null,
+    enabled                     boolean                  not null default true,
+    webhook_url                 varchar,
+    syslog_address              varchar,
+    syslog_tls                  boolean                  not null default false,
+    splunk_hec_url              varchar,
+    splunk_hec_token_ciphertext bytea,
+    cursor_audit_log_event_id   uuid                     not null,
+    last_error                  varchar,
+    last_error_time             timestamp with time zone,
+    create_time                 timestamp with time zone not null default now(),
+    update_time                 timestamp with time zone not null default now()
+);
+
+create index on audit_log_streams (project_id);
@@ -0,0 +1,21 @@
+create table webhook_resource_sequences
+(
+    project_id    uuid    not null references projects (id) on delete cascade,
+    resource_key  varchar not null,
+    last_sequence bigint  not null,
+
+    primary key (project_id, resource_key)
+);
+
+create table webhook_outbox_events
+(
+    id           uuid                     not null primary key,
+    project_id   uuid                     not null references projects (id) on delete cascade,
+    resource_key varchar                  not null,
+    sequence     bigint                   not null,
+    event_type   varchar                  not null,
+    payload      jsonb                    not null,
+    create_time  timestamp with time zone not null default now(),
+
+    unique (project_id, resource_key, sequence)
+);
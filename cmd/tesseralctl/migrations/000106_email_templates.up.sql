@@ -0,0 +1,22 @@
+create type email_template_type as enum ('verify_email', 'password_reset', 'user_invite');
+
+create table email_templates
+(
+    id          uuid                     not null primary key,
+    project_id  uuid                     not null references projects (id) on delete cascade,
+    type        email_template_type      not null,
+    locale      varchar                  not null,
+    subject     varchar                  not null,
+    text_body   varchar                  not null,
+    html_body   varchar                  not null,
+    create_time timestamp with time zone not null default now(),
+    update_time timestamp with time zone not null default now(),
+
+    unique (project_id, type, locale)
+);
+
+alter table organizations
+    add column locale varchar;
+
+alter table users
+    add column locale varchar;
@@ -0,0 +1,32 @@
+create type email_event_type as enum ('sent', 'delivered', 'bounced', 'complained', 'suppressed');
+
+create table email_events
+(
+    id             uuid                     not null primary key,
+    project_id     uuid                     not null references projects (id) on delete cascade,
+    message_id     uuid,
+    email_address  varchar                  not null,
+    type           email_event_type         not null,
+    user_invite_id uuid references user_invites (id) on delete set null,
+    detail         varchar,
+    create_time    timestamp with time zone not null default now()
+);
+
+create index on email_events (project_id, id);
+create index on email_events (message_id);
+
+create type email_suppression_reason as enum ('bounce', 'complaint');
+
+create table email_suppressions
+(
+    id            uuid                     not null primary key,
+    project_id    uuid                     not null references projects (id) on delete cascade,
+    email_address varchar                  not null,
+    reason        email_suppression_reason not null,
+    create_time   timestamp with time zone not null default now(),
+
+    unique (project_id, email_address)
+);
+
+alter table user_invites
+    add column email_delivery_status email_event_type;
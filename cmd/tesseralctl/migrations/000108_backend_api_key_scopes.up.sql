@@ -0,0 +1,5 @@
+alter table backend_api_keys
+  add column scopes varchar[];
+
+alter table backend_api_keys
+  add column organization_ids uuid[];
@@ -0,0 +1,18 @@
+alter table backend_api_keys
+  add column expire_time timestamp with time zone,
+  add column expire_logged boolean not null default false,
+  add column last_used_time timestamp with time zone,
+  add column last_used_ip varchar,
+  add column ip_allowlist_cidrs cidr[];
+
+create index on backend_api_keys (expire_time) where expire_time is not null and not expire_logged;
+
+create table backend_api_key_ips (
+  backend_api_key_id uuid not null references backend_api_keys (id) on delete cascade,
+  ip varchar not null,
+  create_time timestamp with time zone not null default now(),
+
+  primary key (backend_api_key_id, ip)
+);
+
+alter type audit_log_event_resource_type add value 'backend_api_key';
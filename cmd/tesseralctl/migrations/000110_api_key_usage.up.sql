@@ -0,0 +1,13 @@
+alter table api_keys
+  add column rate_limit_per_minute integer check (rate_limit_per_minute > 0);
+
+create table api_key_usage_buckets (
+  api_key_id uuid not null references api_keys (id) on delete cascade,
+  start_time timestamp with time zone not null,
+  request_count bigint not null default 0,
+  rate_limited_count bigint not null default 0,
+
+  primary key (api_key_id, start_time)
+);
+
+create index on api_key_usage_buckets (start_time);
@@ -0,0 +1,8 @@
+alter table api_keys
+  add column previous_secret_token_sha256 bytea,
+  add column previous_secret_token_expire_time timestamp with time zone,
+  add constraint api_keys_previous_secret_token_requires_expire_time
+    check (previous_secret_token_sha256 is null or previous_secret_token_expire_time is not null);
+
+create index on api_keys (previous_secret_token_sha256) where previous_secret_token_sha256 is not null;
+create index on api_keys (previous_secret_token_expire_time) where previous_secret_token_sha256 is not null;
@@ -0,0 +1,7 @@
+alter table api_keys
+  add column user_id uuid references users (id) on delete cascade,
+  add column actions varchar[],
+  add constraint api_keys_actions_requires_user_id
+    check (actions is null or user_id is not null);
+
+create index on api_keys (user_id) where user_id is not null;
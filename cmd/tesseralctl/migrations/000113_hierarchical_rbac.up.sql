@@ -0,0 +1,19 @@
+alter table roles
+  add column action_wildcards varchar[] not null default '{}',
+  add column default_role boolean not null default false,
+  add constraint roles_default_role_requires_project_role
+    check (not default_role or organization_id is null);
+
+create index on roles (project_id) where default_role;
+
+create table role_inherited_roles
+(
+    id                uuid not null primary key,
+    role_id           uuid not null references roles (id) on delete cascade,
+    inherited_role_id uuid not null references roles (id) on delete cascade,
+
+    unique (role_id, inherited_role_id),
+    check (role_id <> inherited_role_id)
+);
+
+create index on role_inherited_roles (inherited_role_id);
@@ -0,0 +1,41 @@
+create table resource_types
+(
+    id          uuid    not null primary key,
+    project_id  uuid    not null references projects (id) on delete cascade,
+    name        varchar not null,
+    description varchar not null,
+
+    unique (project_id, name)
+);
+
+create table resource_relations
+(
+    id               uuid      not null primary key,
+    resource_type_id uuid      not null references resource_types (id) on delete cascade,
+    name             varchar   not null,
+    description      varchar   not null,
+    implied_by       varchar[] not null default '{}',
+
+    unique (resource_type_id, name)
+);
+
+create table relationships
+(
+    id                   uuid                     not null primary key,
+    organization_id      uuid                     not null references organizations (id) on delete cascade,
+    resource_relation_id uuid                     not null references resource_relations (id) on delete cascade,
+    resource_id          varchar                  not null,
+    user_id              uuid references users (id) on delete cascade,
+    api_key_id           uuid references api_keys (id) on delete cascade,
+    role_id              uuid references roles (id) on delete cascade,
+    create_time          timestamp with time zone not null default now(),
+
+    constraint relationships_one_subject check (num_nonnulls(user_id, api_key_id, role_id) = 1)
+);
+
+create unique index on relationships (resource_relation_id, resource_id, user_id) where user_id is not null;
+create unique index on relationships (resource_relation_id, resource_id, api_key_id) where api_key_id is not null;
+create unique index on relationships (resource_relation_id, resource_id, role_id) where role_id is not null;
+create index on relationships (organization_id, resource_relation_id, resource_id);
+
+alter type audit_log_event_resource_type add value 'relationship';
@@ -0,0 +1,23 @@
+alter table user_role_assignments
+  add column expire_time timestamp with time zone;
+
+create index on user_role_assignments (expire_time) where expire_time is not null;
+
+create type role_elevation_request_status as enum ('pending', 'approved', 'denied');
+
+create table role_elevation_requests
+(
+    id               uuid                          not null primary key,
+    user_id          uuid                          not null references users (id) on delete cascade,
+    role_id          uuid                          not null references roles (id) on delete cascade,
+    reason           varchar                       not null,
+    duration_seconds integer                       not null check (duration_seconds > 0),
+    status           role_elevation_request_status not null default 'pending',
+    reviewer_user_id uuid references users (id) on delete set null,
+    review_time      timestamp with time zone,
+    create_time      timestamp with time zone      not null default now()
+);
+
+create index on role_elevation_requests (user_id);
+
+alter type audit_log_event_resource_type add value 'role_elevation_request';
@@ -0,0 +1,5 @@
+alter table projects
+  add column log_in_with_passwordless_passkey boolean not null default false;
+
+alter table organizations
+  add column log_in_with_passwordless_passkey boolean not null default false;
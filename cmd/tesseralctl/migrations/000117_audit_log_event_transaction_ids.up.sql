@@ -0,0 +1,18 @@
+-- Audit log streams read events in commit order, using the ID of the
+-- transaction that wrote each event. Events written before this migration are
+-- all committed, so they share transaction ID 0 and are ordered by ID alone.
+alter table audit_log_events
+  add column transaction_id bigint not null default 0;
+
+alter table audit_log_events
+  alter column transaction_id set default pg_current_xact_id()::text::bigint;
+
+create index on audit_log_events (project_id, transaction_id, id);
+
+-- Existing streams resume from their cursor among the events written before
+-- this migration. New streams start from the transaction that creates them.
+alter table audit_log_streams
+  add column cursor_transaction_id bigint not null default 0;
+
+alter table audit_log_streams
+  alter column cursor_transaction_id set default pg_current_xact_id()::text::bigint;
@@ -0,0 +1,5 @@
+alter table webhook_outbox_events
+  add column attempts integer not null default 0;
+
+alter table webhook_resource_sequences
+  add column update_time timestamp with time zone not null default now();
@@ -0,0 +1,7 @@
+drop index relationships_resource_relation_id_resource_id_user_id_idx;
+drop index relationships_resource_relation_id_resource_id_api_key_id_idx;
+drop index relationships_resource_relation_id_resource_id_role_id_idx;
+
+create unique index on relationships (organization_id, resource_relation_id, resource_id, user_id) where user_id is not null;
+create unique index on relationships (organization_id, resource_relation_id, resource_id, api_key_id) where api_key_id is not null;
+create unique index on relationships (organization_id, resource_relation_id, resource_id, role_id) where role_id is not null;
@@ -0,0 +1,2 @@
+alter table webhook_outbox_events
+  add column next_attempt_time timestamp with time zone;
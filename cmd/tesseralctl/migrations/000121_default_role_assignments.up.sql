@@ -0,0 +1,9 @@
+-- default roles used to apply to users without being assigned; assign them to
+-- existing users so that no one loses access
+insert into user_role_assignments (id, role_id, user_id)
+select gen_random_uuid(), roles.id, users.id
+from users
+  join organizations on users.organization_id = organizations.id
+  join roles on organizations.project_id = roles.project_id
+where roles.default_role
+on conflict (role_id, user_id) do nothing;
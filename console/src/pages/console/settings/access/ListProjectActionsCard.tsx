@@ -171,6 +171,7 @@ function CreateProjectActionButton() {
 
     await createActionMutation.mutateAsync({
       rbacPolicy: {
+        resourceTypes: getRBACPolicyResponse?.rbacPolicy?.resourceTypes || [],
         actions: [
           ...(getRBACPolicyResponse?.rbacPolicy?.actions || []),
           {
@@ -309,6 +310,7 @@ function ManageProjectActionButton({ action }: { action: Action }) {
 
     await updateActionMutation.mutateAsync({
       rbacPolicy: {
+        resourceTypes: getRBACPolicyResponse?.rbacPolicy?.resourceTypes || [],
         actions: updatedActions,
       },
     });
@@ -328,6 +330,7 @@ function ManageProjectActionButton({ action }: { action: Action }) {
 
     await updateActionMutation.mutateAsync({
       rbacPolicy: {
+        resourceTypes: getRBACPolicyResponse?.rbacPolicy?.resourceTypes || [],
         actions: updatedActions,
       },
     });
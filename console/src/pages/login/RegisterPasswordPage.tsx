@@ -27,6 +27,7 @@ import {
 import { Input } from "@/components/ui/input";
 import { registerPassword } from "@/gen/tesseral/intermediate/v1/intermediate-IntermediateService_connectquery";
 import { useRedirectNextLoginFlowPage } from "@/hooks/use-redirect-next-login-flow-page";
+import { parseErrorMessage } from "@/lib/errors";
 
 const schema = z.object({
   password: z.string().nonempty(),
@@ -65,6 +66,18 @@ export function RegisterPasswordPage() {
         return;
       }
 
+      if (
+        e instanceof ConnectError &&
+        e.code === Code.FailedPrecondition &&
+        e.rawMessage === "password_policy_violation"
+      ) {
+        form.setError("password", {
+          type: "manual",
+          message: parseErrorMessage(e),
+        });
+        return;
+      }
+
       throw e;
     } finally {
       setSubmitting(false);
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
@@ -17,13 +18,32 @@ func (s *Store) GetAPIKey(ctx context.Context, db queries.DBTX, id uuid.UUID) (*
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
 
+	var userID *string
+	if qAPIKey.UserID != nil {
+		formattedUserID := idformat.User.Format(*qAPIKey.UserID)
+		userID = &formattedUserID
+	}
+
 	return &auditlogv1.APIKey{
-		Id:                idformat.APIKey.Format(qAPIKey.ID),
-		CreateTime:        timestamppb.New(*qAPIKey.CreateTime),
-		UpdateTime:        timestamppb.New(*qAPIKey.UpdateTime),
-		ExpireTime:        timestampOrNil(qAPIKey.ExpireTime),
-		DisplayName:       qAPIKey.DisplayName,
-		SecretTokenSuffix: derefOrEmpty(qAPIKey.SecretTokenSuffix),
-		Revoked:           qAPIKey.SecretTokenSha256 == nil,
+		Id:                            idformat.APIKey.Format(qAPIKey.ID),
+		CreateTime:                    timestamppb.New(*qAPIKey.CreateTime),
+		UpdateTime:                    timestamppb.New(*qAPIKey.UpdateTime),
+		ExpireTime:                    timestampOrNil(qAPIKey.ExpireTime),
+		DisplayName:                   qAPIKey.DisplayName,
+		SecretTokenSuffix:             derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		Revoked:                       qAPIKey.SecretTokenSha256 == nil,
+		RateLimitPerMinute:            qAPIKey.RateLimitPerMinute,
+		PreviousSecretTokenExpireTime: previousSecretTokenExpireTimeOrNil(qAPIKey.PreviousSecretTokenSha256, qAPIKey.PreviousSecretTokenExpireTime),
+		UserId:                        userID,
+		Actions:                       qAPIKey.Actions,
 	}, nil
 }
+
+// previousSecretTokenExpireTimeOrNil returns when the previous secret token of
+// an API key expires, if the key has one.
+func previousSecretTokenExpireTimeOrNil(previousSecretTokenSHA256 []byte, previousSecretTokenExpireTime *time.Time) *timestamppb.Timestamp {
+	if previousSecretTokenSHA256 == nil {
+		return nil
+	}
+	return timestampOrNil(previousSecretTokenExpireTime)
+}
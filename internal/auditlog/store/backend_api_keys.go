@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) GetBackendAPIKey(ctx context.Context, db queries.DBTX, id uuid.UUID) (*auditlogv1.BackendAPIKey, error) {
+	qBackendAPIKey, err := queries.New(db).GetBackendAPIKey(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get backend api key: %w", err)
+	}
+
+	var organizationIDs []string
+	for _, orgID := range qBackendAPIKey.OrganizationIds {
+		organizationIDs = append(organizationIDs, idformat.Organization.Format(orgID))
+	}
+
+	var ipAllowlistCIDRs []string
+	for _, cidr := range qBackendAPIKey.IpAllowlistCidrs {
+		ipAllowlistCIDRs = append(ipAllowlistCIDRs, cidr.String())
+	}
+
+	return &auditlogv1.BackendAPIKey{
+		Id:               idformat.BackendAPIKey.Format(qBackendAPIKey.ID),
+		CreateTime:       timestamppb.New(*qBackendAPIKey.CreateTime),
+		UpdateTime:       timestamppb.New(*qBackendAPIKey.UpdateTime),
+		ExpireTime:       timestampOrNil(qBackendAPIKey.ExpireTime),
+		DisplayName:      qBackendAPIKey.DisplayName,
+		Revoked:          qBackendAPIKey.SecretTokenSha256 == nil,
+		Scopes:           qBackendAPIKey.Scopes,
+		OrganizationIds:  organizationIDs,
+		IpAllowlistCidrs: ipAllowlistCIDRs,
+	}, nil
+}
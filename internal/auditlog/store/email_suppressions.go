@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func (s *Store) GetEmailSuppression(ctx context.Context, db queries.DBTX, id uuid.UUID) (*auditlogv1.EmailSuppression, error) {
+	qEmailSuppression, err := queries.New(db).GetEmailSuppression(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get email suppression: %w", err)
+	}
+
+	var reason auditlogv1.EmailSuppressionReason
+	switch qEmailSuppression.Reason {
+	case queries.EmailSuppressionReasonBounce:
+		reason = auditlogv1.EmailSuppressionReason_EMAIL_SUPPRESSION_REASON_BOUNCE
+	case queries.EmailSuppressionReasonComplaint:
+		reason = auditlogv1.EmailSuppressionReason_EMAIL_SUPPRESSION_REASON_COMPLAINT
+	}
+
+	return &auditlogv1.EmailSuppression{
+		Id:           idformat.EmailSuppression.Format(qEmailSuppression.ID),
+		CreateTime:   timestampOrNil(qEmailSuppression.CreateTime),
+		EmailAddress: qEmailSuppression.EmailAddress,
+		Reason:       reason,
+	}, nil
+}
@@ -18,22 +18,23 @@ func (s *Store) GetOrganization(ctx context.Context, db queries.DBTX, id uuid.UU
 	}
 
 	return &auditlogv1.Organization{
-		Id:                        idformat.Organization.Format(qOrganization.ID),
-		DisplayName:               qOrganization.DisplayName,
-		CreateTime:                timestamppb.New(*qOrganization.CreateTime),
-		UpdateTime:                timestamppb.New(*qOrganization.UpdateTime),
-		LogInWithPassword:         &qOrganization.LogInWithPassword,
-		LogInWithGoogle:           &qOrganization.LogInWithGoogle,
-		LogInWithMicrosoft:        &qOrganization.LogInWithMicrosoft,
-		LogInWithSaml:             &qOrganization.LogInWithSaml,
-		LogInWithOidc:             &qOrganization.LogInWithOidc,
-		ScimEnabled:               &qOrganization.ScimEnabled,
-		LogInWithAuthenticatorApp: &qOrganization.LogInWithAuthenticatorApp,
-		LogInWithPasskey:          &qOrganization.LogInWithPasskey,
-		RequireMfa:                &qOrganization.RequireMfa,
-		LogInWithEmail:            &qOrganization.LogInWithEmail,
-		CustomRolesEnabled:        &qOrganization.CustomRolesEnabled,
-		ApiKeysEnabled:            &qOrganization.ApiKeysEnabled,
-		LogInWithGithub:           &qOrganization.LogInWithGithub,
+		Id:                           idformat.Organization.Format(qOrganization.ID),
+		DisplayName:                  qOrganization.DisplayName,
+		CreateTime:                   timestamppb.New(*qOrganization.CreateTime),
+		UpdateTime:                   timestamppb.New(*qOrganization.UpdateTime),
+		LogInWithPassword:            &qOrganization.LogInWithPassword,
+		LogInWithGoogle:              &qOrganization.LogInWithGoogle,
+		LogInWithMicrosoft:           &qOrganization.LogInWithMicrosoft,
+		LogInWithSaml:                &qOrganization.LogInWithSaml,
+		LogInWithOidc:                &qOrganization.LogInWithOidc,
+		ScimEnabled:                  &qOrganization.ScimEnabled,
+		LogInWithAuthenticatorApp:    &qOrganization.LogInWithAuthenticatorApp,
+		LogInWithPasskey:             &qOrganization.LogInWithPasskey,
+		LogInWithPasswordlessPasskey: &qOrganization.LogInWithPasswordlessPasskey,
+		RequireMfa:                   &qOrganization.RequireMfa,
+		LogInWithEmail:               &qOrganization.LogInWithEmail,
+		CustomRolesEnabled:           &qOrganization.CustomRolesEnabled,
+		ApiKeysEnabled:               &qOrganization.ApiKeysEnabled,
+		LogInWithGithub:              &qOrganization.LogInWithGithub,
 	}, nil
 }
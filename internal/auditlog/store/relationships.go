@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) GetRelationship(ctx context.Context, db queries.DBTX, id uuid.UUID) (*auditlogv1.Relationship, error) {
+	qRelationship, err := queries.New(db).GetRelationship(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get relationship: %w", err)
+	}
+
+	var subjectID string
+	switch {
+	case qRelationship.UserID != nil:
+		subjectID = idformat.User.Format(*qRelationship.UserID)
+	case qRelationship.ApiKeyID != nil:
+		subjectID = idformat.APIKey.Format(*qRelationship.ApiKeyID)
+	case qRelationship.RoleID != nil:
+		subjectID = idformat.Role.Format(*qRelationship.RoleID)
+	}
+
+	return &auditlogv1.Relationship{
+		Id:             idformat.Relationship.Format(qRelationship.ID),
+		OrganizationId: idformat.Organization.Format(qRelationship.OrganizationID),
+		ResourceType:   qRelationship.ResourceTypeName,
+		ResourceId:     qRelationship.ResourceID,
+		Relation:       qRelationship.ResourceRelationName,
+		SubjectId:      subjectID,
+		CreateTime:     timestamppb.New(*qRelationship.CreateTime),
+	}, nil
+}
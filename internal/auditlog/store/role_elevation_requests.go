@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func (s *Store) GetRoleElevationRequest(ctx context.Context, db queries.DBTX, id uuid.UUID) (*auditlogv1.RoleElevationRequest, error) {
+	qRoleElevationRequest, err := queries.New(db).GetRoleElevationRequest(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get role elevation request: %w", err)
+	}
+
+	var status auditlogv1.RoleElevationRequestStatus
+	switch qRoleElevationRequest.Status {
+	case queries.RoleElevationRequestStatusPending:
+		status = auditlogv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_PENDING
+	case queries.RoleElevationRequestStatusApproved:
+		status = auditlogv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_APPROVED
+	case queries.RoleElevationRequestStatusDenied:
+		status = auditlogv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_DENIED
+	}
+
+	var reviewerUserID string
+	if qRoleElevationRequest.ReviewerUserID != nil {
+		reviewerUserID = idformat.User.Format(*qRoleElevationRequest.ReviewerUserID)
+	}
+
+	return &auditlogv1.RoleElevationRequest{
+		Id:              idformat.RoleElevationRequest.Format(qRoleElevationRequest.ID),
+		UserId:          idformat.User.Format(qRoleElevationRequest.UserID),
+		RoleId:          idformat.Role.Format(qRoleElevationRequest.RoleID),
+		Reason:          qRoleElevationRequest.Reason,
+		DurationSeconds: qRoleElevationRequest.DurationSeconds,
+		Status:          status,
+		ReviewerUserId:  reviewerUserID,
+		ReviewTime:      timestampOrNil(qRoleElevationRequest.ReviewTime),
+		CreateTime:      timestampOrNil(qRoleElevationRequest.CreateTime),
+	}, nil
+}
@@ -22,6 +22,11 @@ func (s *Store) GetRole(ctx context.Context, db queries.DBTX, id uuid.UUID) (*au
 		return nil, fmt.Errorf("get role actions: %w", err)
 	}
 
+	qRoleInheritedRoles, err := queries.New(db).BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, fmt.Errorf("get role inherited roles: %w", err)
+	}
+
 	qActions, err := queries.New(db).GetActions(ctx, qRole.ProjectID)
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
@@ -41,12 +46,21 @@ func (s *Store) GetRole(ctx context.Context, db queries.DBTX, id uuid.UUID) (*au
 		}
 	}
 
+	actions = append(actions, qRole.ActionWildcards...)
+
+	var inheritedRoleIDs []string
+	for _, qRoleInheritedRole := range qRoleInheritedRoles {
+		inheritedRoleIDs = append(inheritedRoleIDs, idformat.Role.Format(qRoleInheritedRole.InheritedRoleID))
+	}
+
 	return &auditlogv1.Role{
-		Id:          idformat.Role.Format(qRole.ID),
-		CreateTime:  timestamppb.New(*qRole.CreateTime),
-		UpdateTime:  timestamppb.New(*qRole.UpdateTime),
-		DisplayName: qRole.DisplayName,
-		Description: qRole.Description,
-		Actions:     actions,
+		Id:               idformat.Role.Format(qRole.ID),
+		CreateTime:       timestamppb.New(*qRole.CreateTime),
+		UpdateTime:       timestamppb.New(*qRole.UpdateTime),
+		DisplayName:      qRole.DisplayName,
+		Description:      qRole.Description,
+		Actions:          actions,
+		InheritedRoleIds: inheritedRoleIDs,
+		DefaultRole:      qRole.DefaultRole,
 	}, nil
 }
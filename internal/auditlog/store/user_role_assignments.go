@@ -17,8 +17,9 @@ func (s *Store) GetUserRoleAssignment(ctx context.Context, db queries.DBTX, id u
 	}
 
 	return &auditlogv1.UserRoleAssignment{
-		Id:     idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
-		UserId: idformat.User.Format(qUserRoleAssignment.UserID),
-		RoleId: idformat.Role.Format(qUserRoleAssignment.RoleID),
+		Id:         idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
+		UserId:     idformat.User.Format(qUserRoleAssignment.UserID),
+		RoleId:     idformat.Role.Format(qUserRoleAssignment.RoleID),
+		ExpireTime: timestampOrNil(qUserRoleAssignment.ExpireTime),
 	}, nil
 }
@@ -0,0 +1,79 @@
+// Package auditlogstream formats audit log events for delivery to the SIEMs
+// that audit log streams support.
+package auditlogstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// syslogPriority is facility local0 (16), severity informational (6).
+	syslogPriority = 16*8 + 6
+
+	syslogAppName = "tesseral"
+	syslogMsgID   = "audit"
+
+	// syslogSDID is the structured data ID events are tagged with. 32473 is
+	// the private enterprise number RFC 5424 reserves for documentation.
+	syslogSDID = "tesseral@32473"
+
+	// SplunkHECSourceType is the sourcetype events are sent to Splunk with.
+	SplunkHECSourceType = "tesseral:audit"
+)
+
+// SyslogMessage returns an RFC 5424 syslog message for an audit log event,
+// framed with RFC 6587 octet counting so that it can be written directly to a
+// TCP or TLS connection. eventJSON becomes the message body.
+func SyslogMessage(hostname string, eventTime time.Time, eventName string, eventJSON []byte) []byte {
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	msg := fmt.Sprintf(
+		"<%d>1 %s %s %s - %s [%s eventName=\"%s\"] %s",
+		syslogPriority,
+		eventTime.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		hostname,
+		syslogAppName,
+		syslogMsgID,
+		syslogSDID,
+		escapeSDParamValue(eventName),
+		eventJSON,
+	)
+
+	return []byte(fmt.Sprintf("%d %s", len(msg), msg))
+}
+
+// escapeSDParamValue escapes the characters RFC 5424 requires to be escaped
+// within a structured data parameter value.
+func escapeSDParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+type splunkHECEvent struct {
+	Time       float64         `json:"time"`
+	Host       string          `json:"host,omitempty"`
+	Source     string          `json:"source"`
+	SourceType string          `json:"sourcetype"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// SplunkHECEvent returns the body to POST to a Splunk HTTP Event Collector's
+// event endpoint for an audit log event.
+func SplunkHECEvent(hostname string, eventTime time.Time, eventJSON []byte) ([]byte, error) {
+	body, err := json.Marshal(splunkHECEvent{
+		Time:       float64(eventTime.UnixMilli()) / 1000,
+		Host:       hostname,
+		Source:     syslogAppName,
+		SourceType: SplunkHECSourceType,
+		Event:      eventJSON,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal splunk hec event: %w", err)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,38 @@
+package auditlogstream
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogMessage(t *testing.T) {
+	eventTime := time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	msg := SyslogMessage("auth.example.com", eventTime, `tesseral.users.create`, []byte(`{"id":"audit_log_event_x"}`))
+
+	body := `<134>1 2025-01-02T03:04:05.000006Z auth.example.com tesseral - audit [tesseral@32473 eventName="tesseral.users.create"] {"id":"audit_log_event_x"}`
+	require.Equal(t, fmt.Sprintf("%d %s", len(body), body), string(msg))
+}
+
+func TestSyslogMessage_EscapesSDParamValue(t *testing.T) {
+	msg := SyslogMessage("", time.Unix(0, 0), `a"b]c\d`, []byte(`{}`))
+
+	require.Contains(t, string(msg), ` - tesseral - audit [tesseral@32473 eventName="a\"b\]c\\d"] {}`)
+}
+
+func TestSplunkHECEvent(t *testing.T) {
+	eventTime := time.Date(2025, 1, 2, 3, 4, 5, 123000000, time.UTC)
+
+	body, err := SplunkHECEvent("auth.example.com", eventTime, []byte(`{"id":"audit_log_event_x"}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"time": 1735787045.123,
+		"host": "auth.example.com",
+		"source": "tesseral",
+		"sourcetype": "tesseral:audit",
+		"event": {"id": "audit_log_event_x"}
+	}`, string(body))
+}
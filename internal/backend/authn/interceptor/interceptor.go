@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,6 +15,8 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/backend/store"
 	"github.com/tesseral-labs/tesseral/internal/ujwt"
 	"go.opentelemetry.io/otel"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 var skipRPCs = []string{
@@ -22,10 +25,15 @@ var skipRPCs = []string{
 
 var authenticationRPCs = []string{
 	"/tesseral.backend.v1.BackendService/AuthenticateAPIKey",
+	"/tesseral.backend.v1.BackendService/IssueAPIKeyAccessToken",
+	"/tesseral.backend.v1.BackendService/AuthenticateAccessToken",
+	"/tesseral.backend.v1.BackendService/HasPermission",
 }
 
 var errAuthorizationHeaderRequired = errors.New("authorization header is required")
 
+var errPermissionDenied = errors.New("permission denied")
+
 var tracer = otel.Tracer("github.com/tesseral-labs/tesseral/internal/backend/authn/interceptor")
 
 func New(s *store.Store, consoleProjectID string) connect.UnaryInterceptorFunc {
@@ -64,13 +72,17 @@ func New(s *store.Store, consoleProjectID string) connect.UnaryInterceptorFunc {
 				}
 
 				if res.AuthenticationOnly && !reqContainsAllowedRPC(req, authenticationRPCs) {
-					return nil, connect.NewError(connect.CodePermissionDenied, errors.New("permission denied"))
+					return nil, connect.NewError(connect.CodePermissionDenied, errPermissionDenied)
 				}
 
 				ctx = authn.NewBackendAPIKeyContext(ctx, &authn.BackendAPIKeyContextData{
 					BackendAPIKeyID: res.BackendAPIKeyID,
 					ProjectID:       res.ProjectID,
 				})
+
+				if err := authorizeBackendAPIKey(ctx, s, req, res); err != nil {
+					return nil, err
+				}
 			} else {
 				// look for access token in cookie
 				var accessToken string
@@ -149,6 +161,79 @@ func authenticateAccessToken(ctx context.Context, s *store.Store, consoleProject
 	}, nil
 }
 
+// authorizeBackendAPIKey returns an error unless the key described by res may
+// make req, according to the key's scopes and organizations and the policy
+// rpcPolicies declares for req.
+func authorizeBackendAPIKey(ctx context.Context, s *store.Store, req connect.AnyRequest, res *store.AuthenticateBackendAPIKeyResponse) error {
+	if res.Scopes == nil && res.OrganizationIDs == nil {
+		return nil
+	}
+
+	policy, ok := rpcPolicies[req.Spec().Procedure]
+	if !ok {
+		return connect.NewError(connect.CodePermissionDenied, errPermissionDenied)
+	}
+
+	if policy.unrestrictedOnly {
+		return connect.NewError(connect.CodePermissionDenied, fmt.Errorf("backend api key is limited to scopes or organizations"))
+	}
+
+	if res.Scopes != nil && !authn.HasScope(res.Scopes, policy.scope) {
+		return connect.NewError(connect.CodePermissionDenied, fmt.Errorf("backend api key does not have scope %q", policy.scope))
+	}
+
+	if res.OrganizationIDs == nil {
+		return nil
+	}
+
+	if len(policy.organizationFields) == 0 {
+		return connect.NewError(connect.CodePermissionDenied, fmt.Errorf("backend api key is limited to organizations"))
+	}
+
+	msg, ok := req.Any().(proto.Message)
+	if !ok {
+		return fmt.Errorf("request is not a proto message: %T", req.Any())
+	}
+
+	for _, path := range policy.organizationFields {
+		id := getStringField(msg.ProtoReflect(), path)
+		if id == "" {
+			return connect.NewError(connect.CodePermissionDenied, fmt.Errorf("backend api key is limited to organizations, but request does not set %s", path))
+		}
+
+		orgID, err := s.GetResourceOrganizationID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("get resource organization id: %w", err)
+		}
+
+		if orgID == nil || !slices.Contains(res.OrganizationIDs, *orgID) {
+			return connect.NewError(connect.CodePermissionDenied, fmt.Errorf("backend api key may not act on %s", id))
+		}
+	}
+
+	return nil
+}
+
+// getStringField returns the value of the string field at path, a
+// dot-separated list of field names, or "" if any field on the way is unset.
+func getStringField(msg protoreflect.Message, path string) string {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.Kind() != protoreflect.MessageKind || !msg.Has(fd) {
+			return ""
+		}
+
+		msg = msg.Get(fd).Message()
+	}
+
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(names[len(names)-1]))
+	if fd == nil || fd.Kind() != protoreflect.StringKind {
+		return ""
+	}
+	return msg.Get(fd).String()
+}
+
 func reqContainsAllowedRPC(req connect.AnyRequest, allowedRPCs []string) bool {
 	for _, rpc := range allowedRPCs {
 		if rpc == req.Spec().Procedure {
@@ -0,0 +1,167 @@
+package interceptor
+
+import (
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	"github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1/backendv1connect"
+)
+
+// rpcPolicy describes what a backend API key needs to call an RPC.
+type rpcPolicy struct {
+	// scope is the scope a key limited to scopes needs to call the RPC.
+	scope string
+
+	// organizationFields are the paths of the request fields that identify
+	// the organizations the RPC acts on, either by their IDs or by the IDs of
+	// resources within them. A key limited to organizations may only call
+	// RPCs with organizationFields, and only if every one of them identifies
+	// one of its organizations.
+	organizationFields []string
+
+	// unrestrictedOnly is set on RPCs that manage backend API keys. Keys
+	// limited to scopes or organizations may never call them, so that they
+	// cannot create or widen keys with more access than their own.
+	unrestrictedOnly bool
+}
+
+var rpcPolicies = map[string]rpcPolicy{
+	backendv1connect.BackendServiceGetProjectProcedure:                            {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceListOrganizationsProcedure:                     {scope: authn.ScopeOrganizationsRead},
+	backendv1connect.BackendServiceGetOrganizationProcedure:                       {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateOrganizationProcedure:                    {scope: authn.ScopeOrganizationsWrite},
+	backendv1connect.BackendServiceUpdateOrganizationProcedure:                    {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceDeleteOrganizationProcedure:                    {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceGetOrganizationDomainsProcedure:                {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceUpdateOrganizationDomainsProcedure:             {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetOrganizationGoogleHostedDomainsProcedure:    {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceUpdateOrganizationGoogleHostedDomainsProcedure: {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetOrganizationMicrosoftTenantIDsProcedure:     {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceUpdateOrganizationMicrosoftTenantIDsProcedure:  {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetOrganizationIPAllowlistProcedure:            {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceUpdateOrganizationIPAllowlistProcedure:         {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetOrganizationPasskeyPolicyProcedure:          {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceUpdateOrganizationPasskeyPolicyProcedure:       {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetProjectPasswordPolicyProcedure:              {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceUpdateProjectPasswordPolicyProcedure:           {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceRotateProjectWebhookSigningSecretProcedure:     {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceListWebhookEndpointsProcedure:                  {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceGetWebhookEndpointProcedure:                    {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceCreateWebhookEndpointProcedure:                 {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceUpdateWebhookEndpointProcedure:                 {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceDeleteWebhookEndpointProcedure:                 {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceListWebhookDeliveriesProcedure:                 {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceGetWebhookDeliveryProcedure:                    {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceRedeliverWebhookProcedure:                      {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceListAuditLogStreamsProcedure:                   {scope: authn.ScopeAuditLogsRead},
+	backendv1connect.BackendServiceGetAuditLogStreamProcedure:                     {scope: authn.ScopeAuditLogsRead},
+	backendv1connect.BackendServiceCreateAuditLogStreamProcedure:                  {scope: authn.ScopeAuditLogsWrite},
+	backendv1connect.BackendServiceUpdateAuditLogStreamProcedure:                  {scope: authn.ScopeAuditLogsWrite},
+	backendv1connect.BackendServiceDeleteAuditLogStreamProcedure:                  {scope: authn.ScopeAuditLogsWrite},
+	backendv1connect.BackendServiceListEmailTemplatesProcedure:                    {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceGetEmailTemplateProcedure:                      {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceCreateEmailTemplateProcedure:                   {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceUpdateEmailTemplateProcedure:                   {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceDeleteEmailTemplateProcedure:                   {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServicePreviewEmailTemplateProcedure:                  {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceListEmailEventsProcedure:                       {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceListEmailSuppressionsProcedure:                 {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceDeleteEmailSuppressionProcedure:                {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceGetOrganizationPasswordPolicyProcedure:         {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceUpdateOrganizationPasswordPolicyProcedure:      {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceListSAMLConnectionsProcedure:                   {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetSAMLConnectionProcedure:                     {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateSAMLConnectionProcedure:                  {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"saml_connection.organization_id"}},
+	backendv1connect.BackendServiceUpdateSAMLConnectionProcedure:                  {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceDeleteSAMLConnectionProcedure:                  {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListOIDCConnectionsProcedure:                   {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetOIDCConnectionProcedure:                     {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateOIDCConnectionProcedure:                  {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"oidc_connection.organization_id"}},
+	backendv1connect.BackendServiceUpdateOIDCConnectionProcedure:                  {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceDeleteOIDCConnectionProcedure:                  {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListSCIMAPIKeysProcedure:                       {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetSCIMAPIKeyProcedure:                         {scope: authn.ScopeOrganizationsRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateSCIMAPIKeyProcedure:                      {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"scim_api_key.organization_id"}},
+	backendv1connect.BackendServiceUpdateSCIMAPIKeyProcedure:                      {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceDeleteSCIMAPIKeyProcedure:                      {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceRevokeSCIMAPIKeyProcedure:                      {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListUsersProcedure:                             {scope: authn.ScopeUsersRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetUserProcedure:                               {scope: authn.ScopeUsersRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateUserProcedure:                            {scope: authn.ScopeUsersWrite, organizationFields: []string{"user.organization_id"}},
+	backendv1connect.BackendServiceUpdateUserProcedure:                            {scope: authn.ScopeUsersWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceDeleteUserProcedure:                            {scope: authn.ScopeUsersWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListPasskeysProcedure:                          {scope: authn.ScopeUsersRead, organizationFields: []string{"user_id"}},
+	backendv1connect.BackendServiceGetPasskeyProcedure:                            {scope: authn.ScopeUsersRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceUpdatePasskeyProcedure:                         {scope: authn.ScopeUsersWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceDeletePasskeyProcedure:                         {scope: authn.ScopeUsersWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListSessionsProcedure:                          {scope: authn.ScopeUsersRead, organizationFields: []string{"user_id"}},
+	backendv1connect.BackendServiceGetSessionProcedure:                            {scope: authn.ScopeUsersRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListUserInvitesProcedure:                       {scope: authn.ScopeUsersRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetUserInviteProcedure:                         {scope: authn.ScopeUsersRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateUserInviteProcedure:                      {scope: authn.ScopeUsersWrite, organizationFields: []string{"user_invite.organization_id"}},
+	backendv1connect.BackendServiceDeleteUserInviteProcedure:                      {scope: authn.ScopeUsersWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceGetRBACPolicyProcedure:                         {scope: authn.ScopeRolesRead},
+	backendv1connect.BackendServiceUpdateRBACPolicyProcedure:                      {scope: authn.ScopeRolesWrite},
+	backendv1connect.BackendServiceListRolesProcedure:                             {scope: authn.ScopeRolesRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetRoleProcedure:                               {scope: authn.ScopeRolesRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateRoleProcedure:                            {scope: authn.ScopeRolesWrite, organizationFields: []string{"role.organization_id"}},
+	backendv1connect.BackendServiceUpdateRoleProcedure:                            {scope: authn.ScopeRolesWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceDeleteRoleProcedure:                            {scope: authn.ScopeRolesWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListUserRoleAssignmentsProcedure:               {scope: authn.ScopeRolesRead, organizationFields: []string{"user_id"}},
+	backendv1connect.BackendServiceGetUserRoleAssignmentProcedure:                 {scope: authn.ScopeRolesRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateUserRoleAssignmentProcedure:              {scope: authn.ScopeRolesWrite, organizationFields: []string{"user_role_assignment.user_id"}},
+	backendv1connect.BackendServiceDeleteUserRoleAssignmentProcedure:              {scope: authn.ScopeRolesWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListRelationshipsProcedure:                     {scope: authn.ScopeRolesRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceGetRelationshipProcedure:                       {scope: authn.ScopeRolesRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateRelationshipProcedure:                    {scope: authn.ScopeRolesWrite, organizationFields: []string{"relationship.organization_id"}},
+	backendv1connect.BackendServiceDeleteRelationshipProcedure:                    {scope: authn.ScopeRolesWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCheckRelationshipProcedure:                     {scope: authn.ScopeRolesRead, organizationFields: []string{"subject_id"}},
+	backendv1connect.BackendServiceBatchCheckRelationshipsProcedure:               {scope: authn.ScopeRolesRead},
+	backendv1connect.BackendServiceListRelationshipObjectsProcedure:               {scope: authn.ScopeRolesRead, organizationFields: []string{"subject_id"}},
+	backendv1connect.BackendServiceBatchListRelationshipObjectsProcedure:          {scope: authn.ScopeRolesRead},
+	backendv1connect.BackendServiceCreateAPIKeyProcedure:                          {scope: authn.ScopeAPIKeysWrite, organizationFields: []string{"api_key.organization_id"}},
+	backendv1connect.BackendServiceDeleteAPIKeyProcedure:                          {scope: authn.ScopeAPIKeysWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceGetAPIKeyProcedure:                             {scope: authn.ScopeAPIKeysRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceGetAPIKeyUsageProcedure:                        {scope: authn.ScopeAPIKeysRead, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListAPIKeysProcedure:                           {scope: authn.ScopeAPIKeysRead, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceRevokeAPIKeyProcedure:                          {scope: authn.ScopeAPIKeysWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceRotateAPIKeyProcedure:                          {scope: authn.ScopeAPIKeysWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceUpdateAPIKeyProcedure:                          {scope: authn.ScopeAPIKeysWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceCreateAPIKeyRoleAssignmentProcedure:            {scope: authn.ScopeAPIKeysWrite, organizationFields: []string{"api_key_role_assignment.api_key_id"}},
+	backendv1connect.BackendServiceDeleteAPIKeyRoleAssignmentProcedure:            {scope: authn.ScopeAPIKeysWrite, organizationFields: []string{"id"}},
+	backendv1connect.BackendServiceListAPIKeyRoleAssignmentsProcedure:             {scope: authn.ScopeAPIKeysRead, organizationFields: []string{"api_key_id"}},
+	backendv1connect.BackendServiceAuthenticateAPIKeyProcedure:                    {scope: authn.ScopeAuthentication},
+	backendv1connect.BackendServiceIssueAPIKeyAccessTokenProcedure:                {scope: authn.ScopeAuthentication},
+	backendv1connect.BackendServiceAuthenticateAccessTokenProcedure:               {scope: authn.ScopeAuthentication},
+	backendv1connect.BackendServiceHasPermissionProcedure:                         {scope: authn.ScopeAuthentication},
+	backendv1connect.BackendServiceCreateAuditLogEventProcedure:                   {scope: authn.ScopeAuditLogsWrite, organizationFields: []string{"audit_log_event.organization_id"}},
+	backendv1connect.BackendServiceDisableOrganizationLoginsProcedure:             {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceDisableProjectLoginsProcedure:                  {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceEnableOrganizationLoginsProcedure:              {scope: authn.ScopeOrganizationsWrite, organizationFields: []string{"organization_id"}},
+	backendv1connect.BackendServiceEnableProjectLoginsProcedure:                   {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceUpdateProjectProcedure:                         {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceGetVaultDomainSettingsProcedure:                {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceUpdateVaultDomainSettingsProcedure:             {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceEnableCustomVaultDomainProcedure:               {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceEnableEmailSendFromDomainProcedure:             {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceGetProjectUISettingsProcedure:                  {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceUpdateProjectUISettingsProcedure:               {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceListBackendAPIKeysProcedure:                    {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceGetBackendAPIKeyProcedure:                      {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceCreateBackendAPIKeyProcedure:                   {scope: authn.ScopeProjectWrite, unrestrictedOnly: true},
+	backendv1connect.BackendServiceUpdateBackendAPIKeyProcedure:                   {scope: authn.ScopeProjectWrite, unrestrictedOnly: true},
+	backendv1connect.BackendServiceDeleteBackendAPIKeyProcedure:                   {scope: authn.ScopeProjectWrite, unrestrictedOnly: true},
+	backendv1connect.BackendServiceRevokeBackendAPIKeyProcedure:                   {scope: authn.ScopeProjectWrite, unrestrictedOnly: true},
+	backendv1connect.BackendServiceListPublishableKeysProcedure:                   {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceGetPublishableKeyProcedure:                     {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceCreatePublishableKeyProcedure:                  {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceUpdatePublishableKeyProcedure:                  {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceDeletePublishableKeyProcedure:                  {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceCreateUserImpersonationTokenProcedure:          {scope: authn.ScopeImpersonation, organizationFields: []string{"user_impersonation_token.impersonated_id"}},
+	backendv1connect.BackendServiceGetProjectEntitlementsProcedure:                {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceCreateStripeCheckoutLinkProcedure:              {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceGetProjectWebhookManagementURLProcedure:        {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceConsoleListAuditLogEventsProcedure:             {scope: authn.ScopeAuditLogsRead},
+	backendv1connect.BackendServiceConsoleListAuditLogEventNamesProcedure:         {scope: authn.ScopeAuditLogsRead},
+	backendv1connect.BackendServiceGetProjectOnboardingProgressProcedure:          {scope: authn.ScopeProjectRead},
+	backendv1connect.BackendServiceUpdateProjectOnboardingProgressProcedure:       {scope: authn.ScopeProjectWrite},
+	backendv1connect.BackendServiceConsoleCreateProjectProcedure:                  {scope: authn.ScopeProjectWrite},
+}
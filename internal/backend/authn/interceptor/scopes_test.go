@@ -0,0 +1,69 @@
+package interceptor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestRPCPolicies(t *testing.T) {
+	methods := backendv1.File_tesseral_backend_v1_backend_proto.Services().ByName("BackendService").Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		procedure := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+		if slices.Contains(skipRPCs, procedure) {
+			continue
+		}
+
+		policy, ok := rpcPolicies[procedure]
+		require.True(t, ok, "no policy for %s", procedure)
+		require.Contains(t, authn.Scopes, policy.scope, "invalid scope for %s", procedure)
+
+		for _, path := range policy.organizationFields {
+			require.True(t, hasStringField(method.Input(), path), "no string field %s in %s", path, method.Input().FullName())
+		}
+	}
+}
+
+func hasStringField(msg protoreflect.MessageDescriptor, path string) bool {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		fd := msg.Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.Kind() != protoreflect.MessageKind {
+			return false
+		}
+		msg = fd.Message()
+	}
+
+	fd := msg.Fields().ByName(protoreflect.Name(names[len(names)-1]))
+	return fd != nil && fd.Kind() == protoreflect.StringKind
+}
+
+func TestGetStringField(t *testing.T) {
+	req := &backendv1.CreateUserRequest{User: &backendv1.User{OrganizationId: "org_123"}}
+	require.Equal(t, "org_123", getStringField(req.ProtoReflect(), "user.organization_id"))
+	require.Equal(t, "", getStringField((&backendv1.CreateUserRequest{}).ProtoReflect(), "user.organization_id"))
+	require.Equal(t, "", getStringField(req.ProtoReflect(), "user.missing"))
+}
+
+func TestHasScope(t *testing.T) {
+	require.True(t, authn.HasScope([]string{authn.ScopeUsersRead}, authn.ScopeUsersRead))
+	require.True(t, authn.HasScope([]string{authn.ScopeUsersWrite}, authn.ScopeUsersRead))
+	require.False(t, authn.HasScope([]string{authn.ScopeUsersRead}, authn.ScopeUsersWrite))
+	require.False(t, authn.HasScope([]string{authn.ScopeOrganizationsWrite}, authn.ScopeUsersRead))
+	require.False(t, authn.HasScope(nil, authn.ScopeAuthentication))
+}
+
+func TestRPCPolicies_BackendAPIKeysUnrestrictedOnly(t *testing.T) {
+	for procedure, policy := range rpcPolicies {
+		if strings.HasSuffix(procedure, "BackendAPIKey") && !strings.HasPrefix(procedure, "/tesseral.backend.v1.BackendService/Get") {
+			require.True(t, policy.unrestrictedOnly, "%s must be unrestrictedOnly", procedure)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package authn
+
+import "strings"
+
+// Scopes a backend API key may be limited to. Each backend RPC requires one of
+// these.
+const (
+	ScopeProjectRead        = "project:read"
+	ScopeProjectWrite       = "project:write"
+	ScopeOrganizationsRead  = "organizations:read"
+	ScopeOrganizationsWrite = "organizations:write"
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopeRolesRead          = "roles:read"
+	ScopeRolesWrite         = "roles:write"
+	ScopeAPIKeysRead        = "api_keys:read"
+	ScopeAPIKeysWrite       = "api_keys:write"
+	ScopeAuditLogsRead      = "audit_logs:read"
+	ScopeAuditLogsWrite     = "audit_logs:write"
+	ScopeAuthentication     = "authentication"
+	ScopeImpersonation      = "impersonation"
+)
+
+// Scopes is the list of every valid scope.
+var Scopes = []string{
+	ScopeProjectRead,
+	ScopeProjectWrite,
+	ScopeOrganizationsRead,
+	ScopeOrganizationsWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeRolesRead,
+	ScopeRolesWrite,
+	ScopeAPIKeysRead,
+	ScopeAPIKeysWrite,
+	ScopeAuditLogsRead,
+	ScopeAuditLogsWrite,
+	ScopeAuthentication,
+	ScopeImpersonation,
+}
+
+// HasScope returns whether scopes grant scope. A "write" scope also grants the
+// corresponding "read" scope.
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+
+		if resource, ok := strings.CutSuffix(scope, ":read"); ok && s == resource+":write" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) AuthenticateAccessToken(ctx context.Context, req *connect.Request[backendv1.AuthenticateAccessTokenRequest]) (*connect.Response[backendv1.AuthenticateAccessTokenResponse], error) {
+	res, err := s.Store.AuthenticateAccessToken(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) HasPermission(ctx context.Context, req *connect.Request[backendv1.HasPermissionRequest]) (*connect.Response[backendv1.HasPermissionResponse], error) {
+	res, err := s.Store.HasPermission(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
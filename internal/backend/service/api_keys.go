@@ -70,3 +70,30 @@ func (s *Service) AuthenticateAPIKey(ctx context.Context, req *connect.Request[b
 
 	return connect.NewResponse(res), nil
 }
+
+func (s *Service) IssueAPIKeyAccessToken(ctx context.Context, req *connect.Request[backendv1.IssueAPIKeyAccessTokenRequest]) (*connect.Response[backendv1.IssueAPIKeyAccessTokenResponse], error) {
+	res, err := s.Store.IssueAPIKeyAccessToken(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetAPIKeyUsage(ctx context.Context, req *connect.Request[backendv1.GetAPIKeyUsageRequest]) (*connect.Response[backendv1.GetAPIKeyUsageResponse], error) {
+	res, err := s.Store.GetAPIKeyUsage(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) RotateAPIKey(ctx context.Context, req *connect.Request[backendv1.RotateAPIKeyRequest]) (*connect.Response[backendv1.RotateAPIKeyResponse], error) {
+	res, err := s.Store.RotateAPIKey(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListAuditLogStreams(ctx context.Context, req *connect.Request[backendv1.ListAuditLogStreamsRequest]) (*connect.Response[backendv1.ListAuditLogStreamsResponse], error) {
+	res, err := s.Store.ListAuditLogStreams(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetAuditLogStream(ctx context.Context, req *connect.Request[backendv1.GetAuditLogStreamRequest]) (*connect.Response[backendv1.GetAuditLogStreamResponse], error) {
+	res, err := s.Store.GetAuditLogStream(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CreateAuditLogStream(ctx context.Context, req *connect.Request[backendv1.CreateAuditLogStreamRequest]) (*connect.Response[backendv1.CreateAuditLogStreamResponse], error) {
+	res, err := s.Store.CreateAuditLogStream(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateAuditLogStream(ctx context.Context, req *connect.Request[backendv1.UpdateAuditLogStreamRequest]) (*connect.Response[backendv1.UpdateAuditLogStreamResponse], error) {
+	res, err := s.Store.UpdateAuditLogStream(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DeleteAuditLogStream(ctx context.Context, req *connect.Request[backendv1.DeleteAuditLogStreamRequest]) (*connect.Response[backendv1.DeleteAuditLogStreamResponse], error) {
+	res, err := s.Store.DeleteAuditLogStream(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListEmailEvents(ctx context.Context, req *connect.Request[backendv1.ListEmailEventsRequest]) (*connect.Response[backendv1.ListEmailEventsResponse], error) {
+	res, err := s.Store.ListEmailEvents(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
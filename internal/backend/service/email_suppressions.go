@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListEmailSuppressions(ctx context.Context, req *connect.Request[backendv1.ListEmailSuppressionsRequest]) (*connect.Response[backendv1.ListEmailSuppressionsResponse], error) {
+	res, err := s.Store.ListEmailSuppressions(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DeleteEmailSuppression(ctx context.Context, req *connect.Request[backendv1.DeleteEmailSuppressionRequest]) (*connect.Response[backendv1.DeleteEmailSuppressionResponse], error) {
+	res, err := s.Store.DeleteEmailSuppression(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) GetOrganizationPasskeyPolicy(ctx context.Context, req *connect.Request[backendv1.GetOrganizationPasskeyPolicyRequest]) (*connect.Response[backendv1.GetOrganizationPasskeyPolicyResponse], error) {
+	res, err := s.Store.GetOrganizationPasskeyPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateOrganizationPasskeyPolicy(ctx context.Context, req *connect.Request[backendv1.UpdateOrganizationPasskeyPolicyRequest]) (*connect.Response[backendv1.UpdateOrganizationPasskeyPolicyResponse], error) {
+	res, err := s.Store.UpdateOrganizationPasskeyPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
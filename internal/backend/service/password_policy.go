@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) GetProjectPasswordPolicy(ctx context.Context, req *connect.Request[backendv1.GetProjectPasswordPolicyRequest]) (*connect.Response[backendv1.GetProjectPasswordPolicyResponse], error) {
+	res, err := s.Store.GetProjectPasswordPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateProjectPasswordPolicy(ctx context.Context, req *connect.Request[backendv1.UpdateProjectPasswordPolicyRequest]) (*connect.Response[backendv1.UpdateProjectPasswordPolicyResponse], error) {
+	res, err := s.Store.UpdateProjectPasswordPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetOrganizationPasswordPolicy(ctx context.Context, req *connect.Request[backendv1.GetOrganizationPasswordPolicyRequest]) (*connect.Response[backendv1.GetOrganizationPasswordPolicyResponse], error) {
+	res, err := s.Store.GetOrganizationPasswordPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateOrganizationPasswordPolicy(ctx context.Context, req *connect.Request[backendv1.UpdateOrganizationPasswordPolicyRequest]) (*connect.Response[backendv1.UpdateOrganizationPasswordPolicyResponse], error) {
+	res, err := s.Store.UpdateOrganizationPasswordPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
@@ -16,3 +16,12 @@ func (s *Service) GetProjectWebhookManagementURL(ctx context.Context, req *conne
 
 	return connect.NewResponse(res), nil
 }
+
+func (s *Service) RotateProjectWebhookSigningSecret(ctx context.Context, req *connect.Request[backendv1.RotateProjectWebhookSigningSecretRequest]) (*connect.Response[backendv1.RotateProjectWebhookSigningSecretResponse], error) {
+	res, err := s.Store.RotateProjectWebhookSigningSecret(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListRelationships(ctx context.Context, req *connect.Request[backendv1.ListRelationshipsRequest]) (*connect.Response[backendv1.ListRelationshipsResponse], error) {
+	res, err := s.Store.ListRelationships(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetRelationship(ctx context.Context, req *connect.Request[backendv1.GetRelationshipRequest]) (*connect.Response[backendv1.GetRelationshipResponse], error) {
+	res, err := s.Store.GetRelationship(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CreateRelationship(ctx context.Context, req *connect.Request[backendv1.CreateRelationshipRequest]) (*connect.Response[backendv1.CreateRelationshipResponse], error) {
+	res, err := s.Store.CreateRelationship(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DeleteRelationship(ctx context.Context, req *connect.Request[backendv1.DeleteRelationshipRequest]) (*connect.Response[backendv1.DeleteRelationshipResponse], error) {
+	res, err := s.Store.DeleteRelationship(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CheckRelationship(ctx context.Context, req *connect.Request[backendv1.CheckRelationshipRequest]) (*connect.Response[backendv1.CheckRelationshipResponse], error) {
+	res, err := s.Store.CheckRelationship(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) BatchCheckRelationships(ctx context.Context, req *connect.Request[backendv1.BatchCheckRelationshipsRequest]) (*connect.Response[backendv1.BatchCheckRelationshipsResponse], error) {
+	res, err := s.Store.BatchCheckRelationships(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) ListRelationshipObjects(ctx context.Context, req *connect.Request[backendv1.ListRelationshipObjectsRequest]) (*connect.Response[backendv1.ListRelationshipObjectsResponse], error) {
+	res, err := s.Store.ListRelationshipObjects(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) BatchListRelationshipObjects(ctx context.Context, req *connect.Request[backendv1.BatchListRelationshipObjectsRequest]) (*connect.Response[backendv1.BatchListRelationshipObjectsResponse], error) {
+	res, err := s.Store.BatchListRelationshipObjects(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
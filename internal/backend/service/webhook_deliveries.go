@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListWebhookDeliveries(ctx context.Context, req *connect.Request[backendv1.ListWebhookDeliveriesRequest]) (*connect.Response[backendv1.ListWebhookDeliveriesResponse], error) {
+	res, err := s.Store.ListWebhookDeliveries(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetWebhookDelivery(ctx context.Context, req *connect.Request[backendv1.GetWebhookDeliveryRequest]) (*connect.Response[backendv1.GetWebhookDeliveryResponse], error) {
+	res, err := s.Store.GetWebhookDelivery(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) RedeliverWebhook(ctx context.Context, req *connect.Request[backendv1.RedeliverWebhookRequest]) (*connect.Response[backendv1.RedeliverWebhookResponse], error) {
+	res, err := s.Store.RedeliverWebhook(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,320 @@
+package store
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	commonv1 "github.com/tesseral-labs/tesseral/internal/common/gen/tesseral/common/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/ujwt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sessionPublicKeyCacheTTL is how long session public keys are cached for
+// before they are reloaded from the database.
+const sessionPublicKeyCacheTTL = time.Minute * 5
+
+// sessionPublicKeyMinReloadInterval is the least time between reloads of a
+// project's session public keys prompted by an unknown key ID. Key IDs come
+// from unauthenticated access tokens, so without it anyone could make every
+// request reload the keys from the database.
+const sessionPublicKeyMinReloadInterval = time.Second * 10
+
+// sessionPublicKeyCache caches the session public keys of projects, so that
+// authenticating an access token does not need to load them from the database
+// on every request.
+type sessionPublicKeyCache struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]*sessionPublicKeyCacheEntry
+}
+
+type sessionPublicKeyCacheEntry struct {
+	keys     map[string]*ecdsa.PublicKey
+	loadTime time.Time
+}
+
+// get returns the project's cached session public key with the given ID, and
+// whether the cache can be relied on. A key ID the cache does not know is
+// reported as missing without a reload if the keys were loaded less than
+// sessionPublicKeyMinReloadInterval ago.
+func (c *sessionPublicKeyCache) get(projectID uuid.UUID, kid string, now time.Time) (*ecdsa.PublicKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[projectID]
+	if !ok || now.Sub(entry.loadTime) > sessionPublicKeyCacheTTL {
+		return nil, false
+	}
+
+	if pub, ok := entry.keys[kid]; ok {
+		return pub, true
+	}
+
+	return nil, now.Sub(entry.loadTime) < sessionPublicKeyMinReloadInterval
+}
+
+func (c *sessionPublicKeyCache) set(projectID uuid.UUID, keys map[string]*ecdsa.PublicKey, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[uuid.UUID]*sessionPublicKeyCacheEntry{}
+	}
+
+	c.entries[projectID] = &sessionPublicKeyCacheEntry{
+		keys:     keys,
+		loadTime: now,
+	}
+}
+
+// getSessionPublicKey returns the project's session public key with the given
+// ID, or nil if there is no such key. Keys are reloaded from the database when
+// the cache has expired or does not have the key, at most once every
+// sessionPublicKeyMinReloadInterval for the latter, so that newly rotated keys
+// are picked up quickly.
+func (s *Store) getSessionPublicKey(ctx context.Context, q *queries.Queries, kid string) (*ecdsa.PublicKey, error) {
+	now := time.Now()
+	if pub, ok := s.sessionPublicKeyCache.get(authn.ProjectID(ctx), kid, now); ok {
+		return pub, nil
+	}
+
+	qSessionSigningKeys, err := q.GetSessionSigningKeysByProjectID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get session signing keys by project id: %w", err)
+	}
+
+	keys := map[string]*ecdsa.PublicKey{}
+	for _, qSessionSigningKey := range qSessionSigningKeys {
+		pub, err := x509.ParsePKIXPublicKey(qSessionSigningKey.PublicKey)
+		if err != nil {
+			panic(fmt.Errorf("public key from bytes: %w", err))
+		}
+
+		keys[idformat.SessionSigningKey.Format(qSessionSigningKey.ID)] = pub.(*ecdsa.PublicKey)
+	}
+
+	s.sessionPublicKeyCache.set(authn.ProjectID(ctx), keys, now)
+	return keys[kid], nil
+}
+
+func (s *Store) AuthenticateAccessToken(ctx context.Context, req *backendv1.AuthenticateAccessTokenRequest) (*backendv1.AuthenticateAccessTokenResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	return s.authenticateAccessToken(ctx, q, req.AccessToken)
+}
+
+// apiKeyAccessTokenAudienceSuffix is appended to a project's access token
+// issuer to get the aud of access tokens issued for its API keys. Access
+// tokens issued for sessions have the issuer as their aud instead, so that an
+// access token issued for an API key can't be passed off as a User's.
+const apiKeyAccessTokenAudienceSuffix = "/api-keys"
+
+// accessTokenIssuer returns the iss of the access tokens issued for the
+// project in ctx, which is also the aud of those issued for sessions.
+func accessTokenIssuer(ctx context.Context) string {
+	return fmt.Sprintf("https://%s.tesseral.app", strings.ReplaceAll(idformat.Project.Format(authn.ProjectID(ctx)), "_", "-"))
+}
+
+// authenticateAccessToken verifies an access token issued for either a session
+// or an API key, and checks that the session or API key has not since been
+// revoked.
+func (s *Store) authenticateAccessToken(ctx context.Context, q *queries.Queries, accessToken string) (*backendv1.AuthenticateAccessTokenResponse, error) {
+	kid, err := ujwt.KeyID(accessToken)
+	if err != nil {
+		return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("get access token key id: %w", err))
+	}
+
+	pub, err := s.getSessionPublicKey(ctx, q, kid)
+	if err != nil {
+		return nil, fmt.Errorf("get session public key: %w", err)
+	}
+
+	if pub == nil {
+		return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("session signing key not found: %s", kid))
+	}
+
+	// access tokens issued for api keys are told apart by their aud
+	now := time.Now()
+	isAPIKeyAccessToken := false
+	var rawClaims json.RawMessage
+	if err := ujwt.Claims(pub, accessTokenIssuer(ctx), now, &rawClaims, accessToken); err != nil {
+		if err := ujwt.Claims(pub, accessTokenIssuer(ctx)+apiKeyAccessTokenAudienceSuffix, now, &rawClaims, accessToken); err != nil {
+			return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("verify access token: %w", err))
+		}
+
+		isAPIKeyAccessToken = true
+	}
+
+	var claims structpb.Struct
+	if err := protojson.Unmarshal(rawClaims, &claims); err != nil {
+		return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("unmarshal claims: %w", err))
+	}
+
+	// reject tokens whose claims are for the other type of access token
+	_, hasAPIKey := claims.Fields["apiKey"]
+	_, hasSession := claims.Fields["session"]
+	if isAPIKeyAccessToken != hasAPIKey || isAPIKeyAccessToken == hasSession {
+		return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("access token claims do not match its aud"))
+	}
+
+	if isAPIKeyAccessToken {
+		res, err := s.authenticateAPIKeyAccessTokenClaims(ctx, q, rawClaims)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Claims = &claims
+		return res, nil
+	}
+
+	res, err := s.authenticateSessionAccessTokenClaims(ctx, q, rawClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Claims = &claims
+	return res, nil
+}
+
+func (s *Store) authenticateSessionAccessTokenClaims(ctx context.Context, q *queries.Queries, rawClaims []byte) (*backendv1.AuthenticateAccessTokenResponse, error) {
+	var claims commonv1.AccessTokenData
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(rawClaims, &claims); err != nil {
+		return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("unmarshal access token claims: %w", err))
+	}
+
+	sessionID, err := idformat.Session.Parse(claims.GetSession().GetId())
+	if err != nil {
+		return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("parse session id: %w", err))
+	}
+
+	qSession, err := q.GetSession(ctx, queries.GetSessionParams{
+		ID:        sessionID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("get session: %w", err))
+		}
+
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+
+	if qSession.RefreshTokenSha256 == nil {
+		return nil, apierror.NewUnauthenticatedError("session has been revoked", fmt.Errorf("session revoked"))
+	}
+
+	if qSession.ExpireTime != nil && qSession.ExpireTime.Before(time.Now()) {
+		return nil, apierror.NewUnauthenticatedError("session has expired", fmt.Errorf("session expired"))
+	}
+
+	return &backendv1.AuthenticateAccessTokenResponse{
+		SessionId:      idformat.Session.Format(qSession.ID),
+		OrganizationId: claims.GetOrganization().GetId(),
+		UserId:         idformat.User.Format(qSession.UserID),
+		Actions:        claims.Actions,
+		ExpireTime:     timestamppb.New(time.Unix(int64(claims.Exp), 0)),
+	}, nil
+}
+
+func (s *Store) authenticateAPIKeyAccessTokenClaims(ctx context.Context, q *queries.Queries, rawClaims []byte) (*backendv1.AuthenticateAccessTokenResponse, error) {
+	var claims commonv1.APIKeyAccessTokenData
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(rawClaims, &claims); err != nil {
+		return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("unmarshal api key access token claims: %w", err))
+	}
+
+	apiKeyID, err := idformat.APIKey.Parse(claims.GetApiKey().GetId())
+	if err != nil {
+		return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("parse api key id: %w", err))
+	}
+
+	qAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+		ID:        apiKeyID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewUnauthenticatedError("invalid access token", fmt.Errorf("get api key by id: %w", err))
+		}
+
+		return nil, fmt.Errorf("get api key by id: %w", err)
+	}
+
+	if qAPIKey.SecretTokenSha256 == nil {
+		return nil, apierror.NewUnauthenticatedError("api key has been revoked", fmt.Errorf("api key revoked"))
+	}
+
+	return &backendv1.AuthenticateAccessTokenResponse{
+		ApiKeyId:       idformat.APIKey.Format(qAPIKey.ID),
+		OrganizationId: idformat.Organization.Format(qAPIKey.OrganizationID),
+		UserId:         claims.GetUser().GetId(),
+		Actions:        claims.Actions,
+		ExpireTime:     timestamppb.New(time.Unix(int64(claims.Exp), 0)),
+	}, nil
+}
+
+func (s *Store) HasPermission(ctx context.Context, req *backendv1.HasPermissionRequest) (*backendv1.HasPermissionResponse, error) {
+	if (req.AccessToken == "") == (req.ApiKeySecretToken == "") {
+		return nil, apierror.NewInvalidArgumentError("exactly one of access_token and api_key_secret_token must be provided", fmt.Errorf("exactly one of access_token and api_key_secret_token must be provided"))
+	}
+
+	if req.Action == "" {
+		return nil, apierror.NewInvalidArgumentError("action is required", fmt.Errorf("action is required"))
+	}
+
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	if req.AccessToken != "" {
+		res, err := s.authenticateAccessToken(ctx, q, req.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return &backendv1.HasPermissionResponse{
+			Permitted:      slices.Contains(res.Actions, req.Action),
+			OrganizationId: res.OrganizationId,
+			UserId:         res.UserId,
+			ApiKeyId:       res.ApiKeyId,
+		}, nil
+	}
+
+	apiKey, err := s.authenticateAPIKey(ctx, q, req.ApiKeySecretToken, req.ClientIp)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID string
+	if apiKey.user != nil {
+		userID = idformat.User.Format(apiKey.user.ID)
+	}
+
+	return &backendv1.HasPermissionResponse{
+		Permitted:      slices.Contains(apiKey.actions, req.Action),
+		OrganizationId: idformat.Organization.Format(apiKey.organizationID),
+		UserId:         userID,
+		ApiKeyId:       idformat.APIKey.Format(apiKey.id),
+	}, nil
+}
@@ -0,0 +1,225 @@
+package store
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/ujwt"
+)
+
+func TestSessionPublicKeyCache(t *testing.T) {
+	t.Parallel()
+
+	var c sessionPublicKeyCache
+	projectID := uuid.New()
+	pub := &ecdsa.PublicKey{}
+	now := time.Now()
+
+	_, ok := c.get(projectID, "kid", now)
+	require.False(t, ok)
+
+	c.set(projectID, map[string]*ecdsa.PublicKey{"kid": pub}, now)
+	got, ok := c.get(projectID, "kid", now)
+	require.True(t, ok)
+	require.Same(t, pub, got)
+
+	// unknown key ids do not cause a reload until the reload interval passes
+	got, ok = c.get(projectID, "unknown", now)
+	require.True(t, ok)
+	require.Nil(t, got)
+
+	_, ok = c.get(projectID, "unknown", now.Add(sessionPublicKeyMinReloadInterval))
+	require.False(t, ok)
+
+	// known key ids are reloaded once the ttl passes
+	_, ok = c.get(projectID, "kid", now.Add(sessionPublicKeyCacheTTL+time.Second))
+	require.False(t, ok)
+}
+
+func TestAuthenticateAccessToken_APIKey(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2);
+`,
+		uuid.UUID(projectID).String(),
+		"test.action.1",
+	)
+	require.NoError(t, err)
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			OrganizationId: orgID,
+			DisplayName:    "test-role",
+			Actions:        []string{"test.action.1"},
+		},
+	})
+	require.NoError(t, err)
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateAPIKeyRoleAssignment(ctx, &backendv1.CreateAPIKeyRoleAssignmentRequest{
+		ApiKeyRoleAssignment: &backendv1.APIKeyRoleAssignment{
+			ApiKeyId: createResp.ApiKey.Id,
+			RoleId:   roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	issueResp, err := u.Store.IssueAPIKeyAccessToken(ctx, &backendv1.IssueAPIKeyAccessTokenRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	require.NoError(t, err)
+
+	authResp, err := u.Store.AuthenticateAccessToken(ctx, &backendv1.AuthenticateAccessTokenRequest{
+		AccessToken: issueResp.AccessToken,
+	})
+	require.NoError(t, err)
+	require.Equal(t, createResp.ApiKey.Id, authResp.ApiKeyId)
+	require.Equal(t, orgID, authResp.OrganizationId)
+	require.Empty(t, authResp.SessionId)
+	require.Equal(t, []string{"test.action.1"}, authResp.Actions)
+	require.Equal(t, createResp.ApiKey.Id, authResp.Claims.Fields["sub"].GetStringValue())
+
+	permittedResp, err := u.Store.HasPermission(ctx, &backendv1.HasPermissionRequest{
+		AccessToken: issueResp.AccessToken,
+		Action:      "test.action.1",
+	})
+	require.NoError(t, err)
+	require.True(t, permittedResp.Permitted)
+	require.Equal(t, createResp.ApiKey.Id, permittedResp.ApiKeyId)
+
+	notPermittedResp, err := u.Store.HasPermission(ctx, &backendv1.HasPermissionRequest{
+		ApiKeySecretToken: createResp.ApiKey.SecretToken,
+		Action:            "test.action.2",
+	})
+	require.NoError(t, err)
+	require.False(t, notPermittedResp.Permitted)
+	require.Equal(t, orgID, notPermittedResp.OrganizationId)
+
+	// access tokens stop working as soon as their api key is revoked
+	_, err = u.Store.RevokeAPIKey(ctx, &backendv1.RevokeAPIKeyRequest{Id: createResp.ApiKey.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.AuthenticateAccessToken(ctx, &backendv1.AuthenticateAccessTokenRequest{
+		AccessToken: issueResp.AccessToken,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeUnauthenticated, connectErr.Code())
+}
+
+func TestAuthenticateAccessToken_Invalid(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.AuthenticateAccessToken(ctx, &backendv1.AuthenticateAccessTokenRequest{
+		AccessToken: "not-an-access-token",
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeUnauthenticated, connectErr.Code())
+}
+
+func TestHasPermission_RequiresOneCredential(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.HasPermission(ctx, &backendv1.HasPermissionRequest{
+		Action: "test.action.1",
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+// signAccessToken signs claims with the current session signing key of the
+// project in ctx, the same way access tokens are signed.
+func (u *testUtil) signAccessToken(t *testing.T, ctx context.Context, claims map[string]any) string {
+	qSessionSigningKey, err := queries.New(u.Store.db).GetCurrentSessionSigningKeyByProjectID(ctx, authn.ProjectID(ctx))
+	require.NoError(t, err)
+
+	decryptRes, err := u.Store.sessionSigningKeyKMS.Decrypt(ctx, qSessionSigningKey.PrivateKeyCipherText)
+	require.NoError(t, err)
+
+	priv, err := x509.ParseECPrivateKey(decryptRes)
+	require.NoError(t, err)
+
+	return ujwt.Sign(idformat.SessionSigningKey.Format(qSessionSigningKey.ID), priv, claims)
+}
+
+func TestAuthenticateAccessToken_WrongType(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	iss := accessTokenIssuer(ctx)
+	now := time.Now()
+	newClaims := func(aud string) map[string]any {
+		return map[string]any{
+			"iss":          iss,
+			"sub":          createResp.ApiKey.Id,
+			"aud":          aud,
+			"exp":          now.Add(time.Minute).Unix(),
+			"nbf":          now.Unix(),
+			"iat":          now.Unix(),
+			"organization": map[string]any{"id": orgID},
+		}
+	}
+
+	// api key claims under a session access token's aud
+	apiKeyClaims := newClaims(iss)
+	apiKeyClaims["apiKey"] = map[string]any{"id": createResp.ApiKey.Id}
+
+	// session claims under an api key access token's aud
+	sessionClaims := newClaims(iss + apiKeyAccessTokenAudienceSuffix)
+	sessionClaims["session"] = map[string]any{"id": idformat.Session.Format(uuid.New())}
+
+	for _, claims := range []map[string]any{apiKeyClaims, sessionClaims} {
+		_, err := u.Store.AuthenticateAccessToken(ctx, &backendv1.AuthenticateAccessTokenRequest{
+			AccessToken: u.signAccessToken(t, ctx, claims),
+		})
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Equal(t, connect.CodeUnauthenticated, connectErr.Code())
+	}
+}
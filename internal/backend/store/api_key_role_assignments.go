@@ -43,10 +43,15 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *backendv1.C
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
 
-	if _, err := q.GetRole(ctx, queries.GetRoleParams{
+	if qAPIKey.UserID != nil {
+		return nil, apierror.NewFailedPreconditionError("personal api keys have their owner's actions, and cannot be assigned roles", fmt.Errorf("api key is a personal api key"))
+	}
+
+	qRole, err := q.GetRole(ctx, queries.GetRoleParams{
 		ID:        roleID,
 		ProjectID: authn.ProjectID(ctx),
-	}); err != nil {
+	})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apierror.NewNotFoundError("role not found", fmt.Errorf("get role: %w", err))
 		}
@@ -54,6 +59,10 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *backendv1.C
 		return nil, fmt.Errorf("get role: %w", err)
 	}
 
+	if qRole.OrganizationID != nil && *qRole.OrganizationID != qAPIKey.OrganizationID {
+		return nil, apierror.NewInvalidArgumentError("role belongs to a different organization", fmt.Errorf("role belongs to a different organization"))
+	}
+
 	qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
 		ID:        qAPIKey.OrganizationID,
 		ProjectID: authn.ProjectID(ctx),
@@ -79,7 +88,7 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *backendv1.C
 		return nil, fmt.Errorf("get audit log api key role assignment: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.assign_role",
 		EventDetails: &auditlogv1.AssignAPIKeyRole{
 			ApiKeyRoleAssignment: auditAPIKeyRoleAssignment,
@@ -146,7 +155,7 @@ func (s *Store) DeleteAPIKeyRoleAssignment(ctx context.Context, req *backendv1.D
 		return nil, fmt.Errorf("delete api key role assignment: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.unassign_role",
 		EventDetails: &auditlogv1.UnassignAPIKeyRole{
 			ApiKeyRoleAssignment: auditAPIKeyRoleAssignment,
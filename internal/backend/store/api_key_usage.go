@@ -0,0 +1,300 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// APIKeyUsageFlushInterval is how often RunAPIKeyUsageFlusher writes buffered
+// API key usage to the database. Rate limits are shared between servers
+// through the database, so they are only as accurate as this interval allows.
+const APIKeyUsageFlushInterval = 10 * time.Second
+
+// apiKeyUsageRetention is how long API key usage is kept for.
+const apiKeyUsageRetention = 90 * 24 * time.Hour
+
+// maxAPIKeyUsageBuckets is the most buckets GetAPIKeyUsage returns, e.g. a
+// day of minutes.
+const maxAPIKeyUsageBuckets = 24 * 60
+
+// apiKeyUsage counts the requests authenticated with API keys, in buckets of
+// a minute, so that authenticating a key does not write to the database on
+// every request.
+type apiKeyUsage struct {
+	mu sync.Mutex
+
+	// pending holds the counts recorded since the last flush.
+	pending map[apiKeyUsageBucket]*apiKeyUsageCount
+
+	// flushed holds the request count of a bucket across all servers, as of
+	// the last flush. Every flush reloads the counts of all the keys in it, so
+	// that requests other servers take count towards the same limit.
+	flushed map[apiKeyUsageBucket]int64
+}
+
+type apiKeyUsageBucket struct {
+	apiKeyID  uuid.UUID
+	startTime time.Time
+}
+
+type apiKeyUsageCount struct {
+	requests    int64
+	rateLimited int64
+}
+
+// record counts a request authenticated with apiKeyID at now, and reports
+// whether it is within rateLimitPerMinute. A nil rateLimitPerMinute means the
+// key is not rate limited.
+func (u *apiKeyUsage) record(apiKeyID uuid.UUID, now time.Time, rateLimitPerMinute *int32) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.pending == nil {
+		u.pending = map[apiKeyUsageBucket]*apiKeyUsageCount{}
+	}
+
+	bucket := apiKeyUsageBucket{apiKeyID: apiKeyID, startTime: now.Truncate(time.Minute)}
+	count, ok := u.pending[bucket]
+	if !ok {
+		count = &apiKeyUsageCount{}
+		u.pending[bucket] = count
+	}
+
+	if rateLimitPerMinute != nil && u.flushed[bucket]+count.requests >= int64(*rateLimitPerMinute) {
+		count.rateLimited++
+		return false
+	}
+
+	count.requests++
+	return true
+}
+
+func (u *apiKeyUsage) take() map[apiKeyUsageBucket]*apiKeyUsageCount {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	pending := u.pending
+	u.pending = nil
+	return pending
+}
+
+func (u *apiKeyUsage) setFlushed(bucket apiKeyUsageBucket, requests int64, now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.flushed == nil {
+		u.flushed = map[apiKeyUsageBucket]int64{}
+	}
+
+	// only the current minute matters for rate limiting
+	currentMinute := now.Truncate(time.Minute)
+	for b := range u.flushed {
+		if b.startTime.Before(currentMinute) {
+			delete(u.flushed, b)
+		}
+	}
+
+	if !bucket.startTime.Before(currentMinute) {
+		u.flushed[bucket] = requests
+	}
+}
+
+// flushedAPIKeyIDs returns the keys with a flushed count for the minute
+// starting at currentMinute.
+func (u *apiKeyUsage) flushedAPIKeyIDs(currentMinute time.Time) []uuid.UUID {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var apiKeyIDs []uuid.UUID
+	for b := range u.flushed {
+		if b.startTime.Equal(currentMinute) {
+			apiKeyIDs = append(apiKeyIDs, b.apiKeyID)
+		}
+	}
+	return apiKeyIDs
+}
+
+// RunAPIKeyUsageFlusher calls FlushAPIKeyUsage every APIKeyUsageFlushInterval,
+// and once more when ctx is done.
+func (s *Store) RunAPIKeyUsageFlusher(ctx context.Context) {
+	ticker := time.NewTicker(APIKeyUsageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.FlushAPIKeyUsage(context.WithoutCancel(ctx)); err != nil {
+				slog.ErrorContext(ctx, "flush_api_key_usage", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.FlushAPIKeyUsage(ctx); err != nil {
+				slog.ErrorContext(ctx, "flush_api_key_usage", "error", err)
+			}
+		}
+	}
+}
+
+// FlushAPIKeyUsage adds the API key usage counted since the last flush to the
+// database, and deletes usage older than apiKeyUsageRetention.
+func (s *Store) FlushAPIKeyUsage(ctx context.Context) error {
+	var errs []error
+	for bucket, count := range s.apiKeyUsage.take() {
+		startTime := bucket.startTime
+		requests, err := s.q.UpsertAPIKeyUsageBucket(ctx, queries.UpsertAPIKeyUsageBucketParams{
+			ID:               bucket.apiKeyID,
+			StartTime:        &startTime,
+			RequestCount:     count.requests,
+			RateLimitedCount: count.rateLimited,
+		})
+		if err != nil {
+			// the key was deleted since it was used
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+
+			errs = append(errs, fmt.Errorf("upsert api key usage bucket: %s: %w", idformat.APIKey.Format(bucket.apiKeyID), err))
+			continue
+		}
+
+		s.apiKeyUsage.setFlushed(bucket, requests, time.Now())
+	}
+
+	// reload the current minute of every key this server has seen in it, not
+	// just the keys it flushed, so that other servers' requests are counted
+	// even while this server has none of its own to flush
+	if err := s.reloadAPIKeyUsage(ctx, time.Now()); err != nil {
+		errs = append(errs, err)
+	}
+
+	retentionStartTime := time.Now().Add(-apiKeyUsageRetention)
+	if err := s.q.DeleteAPIKeyUsageBucketsBefore(ctx, &retentionStartTime); err != nil {
+		errs = append(errs, fmt.Errorf("delete api key usage buckets before: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Store) reloadAPIKeyUsage(ctx context.Context, now time.Time) error {
+	currentMinute := now.Truncate(time.Minute)
+	apiKeyIDs := s.apiKeyUsage.flushedAPIKeyIDs(currentMinute)
+	if len(apiKeyIDs) == 0 {
+		return nil
+	}
+
+	qCounts, err := s.q.ListAPIKeyUsageBucketRequestCounts(ctx, queries.ListAPIKeyUsageBucketRequestCountsParams{
+		StartTime: &currentMinute,
+		ApiKeyIds: apiKeyIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("list api key usage bucket request counts: %w", err)
+	}
+
+	for _, qCount := range qCounts {
+		s.apiKeyUsage.setFlushed(apiKeyUsageBucket{apiKeyID: qCount.ApiKeyID, startTime: currentMinute}, qCount.RequestCount, now)
+	}
+
+	return nil
+}
+
+func (s *Store) GetAPIKeyUsage(ctx context.Context, req *backendv1.GetAPIKeyUsageRequest) (*backendv1.GetAPIKeyUsageResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	apiKeyID, err := idformat.APIKey.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid api key id", fmt.Errorf("parse api key id: %w", err))
+	}
+
+	if _, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+		ID:        apiKeyID,
+		ProjectID: authn.ProjectID(ctx),
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
+		}
+
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+
+	startTime, endTime, granularity, err := parseAPIKeyUsagePeriod(req.StartTime, req.EndTime, req.Granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	qBuckets, err := q.ListAPIKeyUsageBuckets(ctx, queries.ListAPIKeyUsageBucketsParams{
+		Granularity: granularity,
+		ApiKeyID:    apiKeyID,
+		StartTime:   &startTime,
+		EndTime:     &endTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list api key usage buckets: %w", err)
+	}
+
+	var buckets []*backendv1.APIKeyUsageBucket
+	for _, qBucket := range qBuckets {
+		buckets = append(buckets, &backendv1.APIKeyUsageBucket{
+			StartTime:        timestamppb.New(*qBucket.StartTime),
+			RequestCount:     qBucket.RequestCount,
+			RateLimitedCount: qBucket.RateLimitedCount,
+		})
+	}
+
+	return &backendv1.GetAPIKeyUsageResponse{
+		Buckets: buckets,
+	}, nil
+}
+
+// parseAPIKeyUsagePeriod applies the defaults of GetAPIKeyUsageRequest, and
+// returns the granularity as a date_trunc field.
+func parseAPIKeyUsagePeriod(start, end *timestamppb.Timestamp, granularity backendv1.APIKeyUsageGranularity) (time.Time, time.Time, string, error) {
+	endTime := time.Now()
+	if end != nil {
+		endTime = end.AsTime()
+	}
+
+	startTime := endTime.Add(-24 * time.Hour)
+	if start != nil {
+		startTime = start.AsTime()
+	}
+
+	if !startTime.Before(endTime) {
+		return time.Time{}, time.Time{}, "", apierror.NewInvalidArgumentError("start_time must be before end_time", fmt.Errorf("start time not before end time"))
+	}
+
+	var field string
+	var width time.Duration
+	switch granularity {
+	case backendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_MINUTE:
+		field, width = "minute", time.Minute
+	case backendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_UNSPECIFIED, backendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_HOUR:
+		field, width = "hour", time.Hour
+	case backendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_DAY:
+		field, width = "day", 24*time.Hour
+	default:
+		return time.Time{}, time.Time{}, "", apierror.NewInvalidArgumentError("invalid granularity", fmt.Errorf("invalid granularity: %v", granularity))
+	}
+
+	if endTime.Sub(startTime)/width > maxAPIKeyUsageBuckets {
+		return time.Time{}, time.Time{}, "", apierror.NewInvalidArgumentError(fmt.Sprintf("period must contain at most %d buckets", maxAPIKeyUsageBuckets), fmt.Errorf("too many buckets"))
+	}
+
+	return startTime, endTime, field, nil
+}
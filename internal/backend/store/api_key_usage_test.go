@@ -0,0 +1,164 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAPIKeyUsage_Record(t *testing.T) {
+	t.Parallel()
+
+	var u apiKeyUsage
+	apiKeyID := uuid.New()
+	now := time.Now()
+	rateLimit := int32(2)
+
+	require.True(t, u.record(apiKeyID, now, &rateLimit))
+	require.True(t, u.record(apiKeyID, now, &rateLimit))
+	require.False(t, u.record(apiKeyID, now, &rateLimit))
+
+	// other servers' requests count towards the limit once flushed
+	pending := u.take()
+	bucket := apiKeyUsageBucket{apiKeyID: apiKeyID, startTime: now.Truncate(time.Minute)}
+	require.Equal(t, &apiKeyUsageCount{requests: 2, rateLimited: 1}, pending[bucket])
+
+	u.setFlushed(bucket, 1, now)
+	require.Equal(t, []uuid.UUID{apiKeyID}, u.flushedAPIKeyIDs(bucket.startTime))
+	require.True(t, u.record(apiKeyID, now, &rateLimit))
+	require.False(t, u.record(apiKeyID, now, &rateLimit))
+
+	// the limit applies per minute
+	require.True(t, u.record(apiKeyID, now.Add(time.Minute), &rateLimit))
+
+	// keys without a limit are never limited
+	for range 10 {
+		require.True(t, u.record(uuid.New(), now, nil))
+	}
+}
+
+func TestAuthenticateAPIKey_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId:     orgID,
+			DisplayName:        "key1",
+			RateLimitPerMinute: refOrNil(int32(2)),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), createResp.ApiKey.GetRateLimitPerMinute())
+
+	for range 2 {
+		_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+			SecretToken: createResp.ApiKey.SecretToken,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeResourceExhausted, connectErr.Code())
+
+	// setting the limit to 0 removes it
+	updateResp, err := u.Store.UpdateAPIKey(ctx, &backendv1.UpdateAPIKeyRequest{
+		Id: createResp.ApiKey.Id,
+		ApiKey: &backendv1.APIKey{
+			DisplayName:        "key1",
+			RateLimitPerMinute: refOrNil(int32(0)),
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, updateResp.ApiKey.RateLimitPerMinute)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	require.NoError(t, err)
+}
+
+func TestCreateAPIKey_NegativeRateLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	_, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId:     orgID,
+			DisplayName:        "key1",
+			RateLimitPerMinute: refOrNil(int32(-1)),
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestGetAPIKeyUsage(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	for range 3 {
+		_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+			SecretToken: createResp.ApiKey.SecretToken,
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, u.Store.FlushAPIKeyUsage(ctx))
+
+	usageResp, err := u.Store.GetAPIKeyUsage(ctx, &backendv1.GetAPIKeyUsageRequest{
+		Id:          createResp.ApiKey.Id,
+		Granularity: backendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_DAY,
+		StartTime:   timestamppb.New(time.Now().Add(-24 * time.Hour)),
+		EndTime:     timestamppb.New(time.Now().Add(time.Minute)),
+	})
+	require.NoError(t, err)
+
+	var requestCount int64
+	for _, bucket := range usageResp.Buckets {
+		requestCount += bucket.RequestCount
+	}
+	require.Equal(t, int64(3), requestCount)
+
+	_, err = u.Store.GetAPIKeyUsage(ctx, &backendv1.GetAPIKeyUsageRequest{
+		Id:          createResp.ApiKey.Id,
+		Granularity: backendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_MINUTE,
+		StartTime:   timestamppb.New(time.Now().Add(-7 * 24 * time.Hour)),
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
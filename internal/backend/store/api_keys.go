@@ -4,8 +4,11 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/netip"
 	"slices"
 	"time"
 
@@ -15,14 +18,23 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	commonv1 "github.com/tesseral-labs/tesseral/internal/common/gen/tesseral/common/v1"
 	"github.com/tesseral-labs/tesseral/internal/prettysecret"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/ujwt"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const apiKeySecretTokenSuffixLength = 4
 
+// maxAPIKeyRotationOverlap is the longest a rotated API key's previous secret
+// token may remain valid for. Rotation is meant to retire a secret token, so
+// it may not be used to keep one around indefinitely.
+const maxAPIKeyRotationOverlap = 7 * 24 * time.Hour
+
 func (s *Store) CreateAPIKey(ctx context.Context, req *backendv1.CreateAPIKeyRequest) (*backendv1.CreateAPIKeyResponse, error) {
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -79,13 +91,61 @@ func (s *Store) CreateAPIKey(ctx context.Context, req *backendv1.CreateAPIKeyReq
 		expireTime = &formattedExpireTime
 	}
 
+	rateLimitPerMinute, err := parseAPIKeyRateLimitPerMinute(req.ApiKey.RateLimitPerMinute)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID *uuid.UUID
+	if req.ApiKey.UserId != nil {
+		id, err := idformat.User.Parse(*req.ApiKey.UserId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
+		}
+
+		qUser, err := q.GetUser(ctx, queries.GetUserParams{
+			ID:        id,
+			ProjectID: authn.ProjectID(ctx),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("user not found", fmt.Errorf("get user: %w", err))
+			}
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+
+		if qUser.OrganizationID != orgID {
+			return nil, apierror.NewInvalidArgumentError("user belongs to a different organization", fmt.Errorf("user belongs to a different organization"))
+		}
+
+		userID = &qUser.ID
+	}
+
+	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get actions: %w", err)
+	}
+
+	var projectActions []string
+	for _, qAction := range qActions {
+		projectActions = append(projectActions, qAction.Name)
+	}
+
+	actions, err := parsePersonalAPIKeyActions(userID, req.ApiKey.Actions, projectActions)
+	if err != nil {
+		return nil, err
+	}
+
 	qAPIKey, err := q.CreateAPIKey(ctx, queries.CreateAPIKeyParams{
-		ID:                uuid.New(),
-		DisplayName:       req.ApiKey.DisplayName,
-		ExpireTime:        expireTime,
-		OrganizationID:    orgID,
-		SecretTokenSha256: secretTokenSHA256[:],
-		SecretTokenSuffix: &secretTokenSuffix,
+		ID:                 uuid.New(),
+		DisplayName:        req.ApiKey.DisplayName,
+		ExpireTime:         expireTime,
+		OrganizationID:     orgID,
+		SecretTokenSha256:  secretTokenSHA256[:],
+		SecretTokenSuffix:  &secretTokenSuffix,
+		RateLimitPerMinute: rateLimitPerMinute,
+		UserID:             userID,
+		Actions:            actions,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create api key: %w", err)
@@ -96,7 +156,7 @@ func (s *Store) CreateAPIKey(ctx context.Context, req *backendv1.CreateAPIKeyReq
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.create",
 		EventDetails: &auditlogv1.CreateAPIKey{
 			ApiKey: auditAPIKey,
@@ -158,7 +218,7 @@ func (s *Store) DeleteAPIKey(ctx context.Context, req *backendv1.DeleteAPIKeyReq
 		return nil, fmt.Errorf("delete api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.delete",
 		EventDetails: &auditlogv1.DeleteAPIKey{
 			ApiKey: auditAPIKey,
@@ -219,6 +279,15 @@ func (s *Store) ListAPIKeys(ctx context.Context, req *backendv1.ListAPIKeysReque
 		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
 	}
 
+	var userID *uuid.UUID
+	if req.UserId != "" {
+		id, err := idformat.User.Parse(req.UserId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
+		}
+		userID = (*uuid.UUID)(&id)
+	}
+
 	var startID uuid.UUID
 	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
 		return nil, err
@@ -230,6 +299,7 @@ func (s *Store) ListAPIKeys(ctx context.Context, req *backendv1.ListAPIKeysReque
 		ID_2:      startID,
 		ProjectID: authn.ProjectID(ctx),
 		Limit:     int32(limit + 1),
+		UserID:    userID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list api keys: %w", err)
@@ -299,7 +369,7 @@ func (s *Store) RevokeAPIKey(ctx context.Context, req *backendv1.RevokeAPIKeyReq
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.revoke",
 		EventDetails: &auditlogv1.RevokeAPIKey{
 			ApiKey:         auditAPIKey,
@@ -319,6 +389,112 @@ func (s *Store) RevokeAPIKey(ctx context.Context, req *backendv1.RevokeAPIKeyReq
 	return &backendv1.RevokeAPIKeyResponse{}, nil
 }
 
+func (s *Store) RotateAPIKey(ctx context.Context, req *backendv1.RotateAPIKeyRequest) (*backendv1.RotateAPIKeyResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	apiKeyID, err := idformat.APIKey.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid api key id", fmt.Errorf("parse api key id: %w", err))
+	}
+
+	qPreviousAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+		ID:        apiKeyID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
+		}
+		return nil, fmt.Errorf("get api key by id: %w", err)
+	}
+
+	if qPreviousAPIKey.SecretTokenSha256 == nil {
+		return nil, apierror.NewFailedPreconditionError("revoked api keys cannot be rotated", fmt.Errorf("api key is revoked"))
+	}
+
+	if qPreviousAPIKey.ExpireTime != nil && !qPreviousAPIKey.ExpireTime.After(time.Now()) {
+		return nil, apierror.NewFailedPreconditionError("expired api keys cannot be rotated", fmt.Errorf("api key is expired"))
+	}
+
+	var previousSecretTokenExpireTime *time.Time
+	if req.PreviousSecretTokenExpireTime != nil {
+		t := req.PreviousSecretTokenExpireTime.AsTime()
+		if !t.After(time.Now()) {
+			return nil, apierror.NewInvalidArgumentError("previous_secret_token_expire_time must be in the future", fmt.Errorf("previous secret token expire time not in the future"))
+		}
+		if t.After(time.Now().Add(maxAPIKeyRotationOverlap)) {
+			return nil, apierror.NewInvalidArgumentError("previous_secret_token_expire_time must be at most 7 days in the future", fmt.Errorf("previous secret token expire time too far in the future"))
+		}
+		previousSecretTokenExpireTime = &t
+	}
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	if qProject.ApiKeySecretTokenPrefix == nil {
+		return nil, apierror.NewPermissionDeniedError("api key secret token prefix is not set for this project", fmt.Errorf("api key secret token prefix not set for project"))
+	}
+
+	var secretTokenValue [35]byte
+	if _, err := rand.Read(secretTokenValue[:]); err != nil {
+		return nil, fmt.Errorf("generate secret token: %w", err)
+	}
+
+	secretToken := prettysecret.Format(*qProject.ApiKeySecretTokenPrefix, secretTokenValue)
+	secretTokenSuffix := secretToken[len(secretToken)-apiKeySecretTokenSuffixLength:]
+	secretTokenSHA256 := sha256.Sum256(secretTokenValue[:])
+
+	auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit log api key: %w", err)
+	}
+
+	qAPIKey, err := q.RotateAPIKey(ctx, queries.RotateAPIKeyParams{
+		ID:                            apiKeyID,
+		SecretTokenSha256:             secretTokenSHA256[:],
+		SecretTokenSuffix:             &secretTokenSuffix,
+		ProjectID:                     authn.ProjectID(ctx),
+		PreviousSecretTokenExpireTime: previousSecretTokenExpireTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	auditAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit log api key: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.api_keys.rotate",
+		EventDetails: &auditlogv1.RotateAPIKey{
+			ApiKey:         auditAPIKey,
+			PreviousApiKey: auditPreviousAPIKey,
+		},
+		OrganizationID: &qAPIKey.OrganizationID,
+		ResourceType:   queries.AuditLogEventResourceTypeApiKey,
+		ResourceID:     &qAPIKey.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	apiKey := parseAPIKey(qAPIKey)
+	apiKey.SecretToken = secretToken
+	return &backendv1.RotateAPIKeyResponse{
+		ApiKey: apiKey,
+	}, nil
+}
+
 func (s *Store) UpdateAPIKey(ctx context.Context, req *backendv1.UpdateAPIKeyRequest) (*backendv1.UpdateAPIKeyResponse, error) {
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -347,10 +523,19 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *backendv1.UpdateAPIKeyReq
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
+	rateLimitPerMinute := qPreviousAPIKey.RateLimitPerMinute
+	if req.ApiKey.RateLimitPerMinute != nil {
+		rateLimitPerMinute, err = parseAPIKeyRateLimitPerMinute(req.ApiKey.RateLimitPerMinute)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	qUpdatedAPIKey, err := q.UpdateAPIKey(ctx, queries.UpdateAPIKeyParams{
-		ID:          apiKeyID,
-		DisplayName: req.ApiKey.DisplayName,
-		ProjectID:   authn.ProjectID(ctx),
+		ID:                 apiKeyID,
+		DisplayName:        req.ApiKey.DisplayName,
+		ProjectID:          authn.ProjectID(ctx),
+		RateLimitPerMinute: rateLimitPerMinute,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("update api key: %w", err)
@@ -361,7 +546,7 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *backendv1.UpdateAPIKeyReq
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.update",
 		EventDetails: &auditlogv1.UpdateAPIKey{
 			ApiKey:         auditAPIKey,
@@ -390,6 +575,117 @@ func (s *Store) AuthenticateAPIKey(ctx context.Context, req *backendv1.Authentic
 	}
 	defer rollback()
 
+	apiKey, err := s.authenticateAPIKey(ctx, q, req.SecretToken, req.ClientIp)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID string
+	if apiKey.user != nil {
+		userID = idformat.User.Format(apiKey.user.ID)
+	}
+
+	return &backendv1.AuthenticateAPIKeyResponse{
+		ApiKeyId:       idformat.APIKey.Format(apiKey.id),
+		Actions:        apiKey.actions,
+		OrganizationId: idformat.Organization.Format(apiKey.organizationID),
+		UserId:         userID,
+	}, nil
+}
+
+// apiKeyAccessTokenDuration is how long access tokens issued for API keys are
+// valid for. They cannot be revoked, so this is kept as short as the
+// access tokens issued for sessions.
+const apiKeyAccessTokenDuration = time.Minute * 5
+
+func (s *Store) IssueAPIKeyAccessToken(ctx context.Context, req *backendv1.IssueAPIKeyAccessTokenRequest) (*backendv1.IssueAPIKeyAccessTokenResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	apiKey, err := s.authenticateAPIKey(ctx, q, req.SecretToken, req.ClientIp)
+	if err != nil {
+		return nil, err
+	}
+
+	qSessionSigningKey, err := q.GetCurrentSessionSigningKeyByProjectID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get current session signing key by project id: %w", err)
+	}
+
+	decryptRes, err := s.sessionSigningKeyKMS.Decrypt(ctx, qSessionSigningKey.PrivateKeyCipherText)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt session signing key ciphertext: %w", err)
+	}
+
+	priv, err := x509.ParseECPrivateKey(decryptRes)
+	if err != nil {
+		return nil, fmt.Errorf("parse session signing key: %w", err)
+	}
+
+	// the access token must not outlive the api key it was issued for
+	now := time.Now()
+	expireTime := now.Add(apiKeyAccessTokenDuration)
+	if apiKey.expireTime != nil && apiKey.expireTime.Before(expireTime) {
+		expireTime = *apiKey.expireTime
+	}
+
+	iss := accessTokenIssuer(ctx)
+	claims := &commonv1.APIKeyAccessTokenData{
+		Iss: iss,
+		Sub: idformat.APIKey.Format(apiKey.id),
+		Aud: iss + apiKeyAccessTokenAudienceSuffix,
+		Exp: float64(expireTime.Unix()),
+		Nbf: float64(now.Unix()),
+		Iat: float64(now.Unix()),
+		ApiKey: &commonv1.AccessTokenAPIKey{
+			Id: idformat.APIKey.Format(apiKey.id),
+		},
+		Organization: &commonv1.AccessTokenOrganization{
+			Id:          idformat.Organization.Format(apiKey.organizationID),
+			DisplayName: apiKey.organizationDisplayName,
+		},
+		Actions: apiKey.actions,
+	}
+
+	if apiKey.user != nil {
+		claims.User = &commonv1.AccessTokenUser{
+			Id:                idformat.User.Format(apiKey.user.ID),
+			Email:             apiKey.user.Email,
+			DisplayName:       derefOrEmpty(apiKey.user.DisplayName),
+			ProfilePictureUrl: derefOrEmpty(apiKey.user.ProfilePictureUrl),
+		}
+	}
+
+	// claims is a proto message, so we have to use protojson to encode it first
+	encodedClaims, err := protojson.Marshal(claims)
+	if err != nil {
+		panic(fmt.Errorf("marshal claims: %w", err))
+	}
+
+	accessToken := ujwt.Sign(idformat.SessionSigningKey.Format(qSessionSigningKey.ID), priv, json.RawMessage(encodedClaims))
+	return &backendv1.IssueAPIKeyAccessTokenResponse{
+		AccessToken: accessToken,
+		ExpireTime:  timestamppb.New(time.Unix(int64(claims.Exp), 0)),
+	}, nil
+}
+
+type authenticatedAPIKey struct {
+	id                      uuid.UUID
+	organizationID          uuid.UUID
+	organizationDisplayName string
+	expireTime              *time.Time
+	actions                 []string
+
+	// user is the owner of a personal API key, or nil.
+	user *queries.User
+}
+
+// authenticateAPIKey validates an API key secret token presented by a client
+// at clientIPAddr, and returns the key's details and actions.
+func (s *Store) authenticateAPIKey(ctx context.Context, q *queries.Queries, secretToken, clientIPAddr string) (*authenticatedAPIKey, error) {
 	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get project by id: %w", err)
@@ -403,7 +699,7 @@ func (s *Store) AuthenticateAPIKey(ctx context.Context, req *backendv1.Authentic
 		return nil, apierror.NewPermissionDeniedError("api key secret token prefix is not set for this project", fmt.Errorf("api key secret token prefix not set for project"))
 	}
 
-	secretTokenBytes, err := prettysecret.Parse(*qProject.ApiKeySecretTokenPrefix, req.SecretToken)
+	secretTokenBytes, err := prettysecret.Parse(*qProject.ApiKeySecretTokenPrefix, secretToken)
 	if err != nil {
 		return nil, apierror.NewUnauthenticatedApiKeyError("malformed_api_key_secret_token", fmt.Errorf("parse secret token: %w", err))
 	}
@@ -436,31 +732,166 @@ func (s *Store) AuthenticateAPIKey(ctx context.Context, req *backendv1.Authentic
 		return nil, apierror.NewPermissionDeniedError("api keys are not enabled for this organization", fmt.Errorf("api keys not enabled for organization"))
 	}
 
-	// Get all actions for the api key
-	actions, err := q.GetAPIKeyActions(ctx, qApiKeyDetails.ID)
+	qIPAllowlistCIDRs, err := q.GetOrganizationIPAllowlistCIDRs(ctx, queries.GetOrganizationIPAllowlistCIDRsParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: qOrg.ID,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("get actions: %w", err)
+		return nil, fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	var ipAllowlist []netip.Prefix
+	for _, qCIDR := range qIPAllowlistCIDRs {
+		ipAllowlist = append(ipAllowlist, qCIDR.Cidr)
+	}
+
+	// a missing or malformed client ip is only acceptable if the organization
+	// has no ip allowlist
+	clientIP, _ := netip.ParseAddr(clientIPAddr)
+	if !clientip.Allowed(clientIP, ipAllowlist) {
+		return nil, apierror.NewIPAddressNotAllowedError("client ip address is not allowed by organization ip allowlist", fmt.Errorf("client ip not in organization ip allowlist"))
+	}
+
+	if !s.apiKeyUsage.record(qApiKeyDetails.ID, time.Now(), qApiKeyDetails.RateLimitPerMinute) {
+		return nil, apierror.NewRateLimitExceededError("api key rate limit exceeded", fmt.Errorf("api key rate limit exceeded"))
+	}
+
+	var actions []string
+	var qUser *queries.User
+	if qApiKeyDetails.UserID != nil {
+		// personal api keys act on behalf of their owner
+		qOwner, err := q.GetUser(ctx, queries.GetUserParams{
+			ID:        *qApiKeyDetails.UserID,
+			ProjectID: authn.ProjectID(ctx),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+
+		qUser = &qOwner
+		actions, err = s.getPersonalAPIKeyActions(ctx, q, qOwner, qApiKeyDetails.Actions)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Get all actions for the api key
+		actions, err = q.GetAPIKeyActions(ctx, qApiKeyDetails.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get actions: %w", err)
+		}
 	}
 
 	slices.Sort(actions)
 
-	return &backendv1.AuthenticateAPIKeyResponse{
-		ApiKeyId:       idformat.APIKey.Format(qApiKeyDetails.ID),
-		Actions:        actions,
-		OrganizationId: idformat.Organization.Format(qApiKeyDetails.OrganizationID),
+	return &authenticatedAPIKey{
+		id:                      qApiKeyDetails.ID,
+		organizationID:          qApiKeyDetails.OrganizationID,
+		organizationDisplayName: qOrg.DisplayName,
+		expireTime:              qApiKeyDetails.ExpireTime,
+		actions:                 actions,
+		user:                    qUser,
 	}, nil
 }
 
+// getPersonalAPIKeyActions returns the actions of a personal API key owned by
+// qUser: the user's actions, limited to keyActions if there are any.
+func (s *Store) getPersonalAPIKeyActions(ctx context.Context, q *queries.Queries, qUser queries.User, keyActions []string) ([]string, error) {
+	var userActions []string
+	if qUser.IsOwner {
+		qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("get project actions: %w", err)
+		}
+
+		for _, qAction := range qActions {
+			userActions = append(userActions, qAction.Name)
+		}
+	} else {
+		var err error
+		userActions, err = q.GetUserActions(ctx, qUser.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get user actions: %w", err)
+		}
+	}
+
+	if len(keyActions) == 0 {
+		return userActions, nil
+	}
+
+	var actions []string
+	for _, action := range userActions {
+		if slices.Contains(keyActions, action) {
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
+}
+
+// parsePersonalAPIKeyActions validates the actions requested for an API key
+// owned by userID. Only personal API keys may be limited to actions, and only
+// to ones in allowedActions.
+func parsePersonalAPIKeyActions(userID *uuid.UUID, actions []string, allowedActions []string) ([]string, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+
+	if userID == nil {
+		return nil, apierror.NewInvalidArgumentError("actions can only be set on personal api keys", fmt.Errorf("actions set on api key without user id"))
+	}
+
+	for _, action := range actions {
+		if !slices.Contains(allowedActions, action) {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("action not allowed: %s", action), fmt.Errorf("action not allowed: %s", action))
+		}
+	}
+
+	actions = slices.Clone(actions)
+	slices.Sort(actions)
+	return slices.Compact(actions), nil
+}
+
+// parseAPIKeyRateLimitPerMinute validates a requested rate limit. 0 means the
+// key is not rate limited.
+func parseAPIKeyRateLimitPerMinute(rateLimitPerMinute *int32) (*int32, error) {
+	if rateLimitPerMinute == nil || *rateLimitPerMinute == 0 {
+		return nil, nil
+	}
+
+	if *rateLimitPerMinute < 0 {
+		return nil, apierror.NewInvalidArgumentError("rate limit per minute must not be negative", fmt.Errorf("negative rate limit per minute: %d", *rateLimitPerMinute))
+	}
+
+	return rateLimitPerMinute, nil
+}
+
 func parseAPIKey(qAPIKey queries.ApiKey) *backendv1.APIKey {
+	var userID *string
+	if qAPIKey.UserID != nil {
+		userID = refOrNil(idformat.User.Format(*qAPIKey.UserID))
+	}
+
 	return &backendv1.APIKey{
-		Id:                idformat.APIKey.Format(qAPIKey.ID),
-		OrganizationId:    idformat.Organization.Format(qAPIKey.OrganizationID),
-		DisplayName:       qAPIKey.DisplayName,
-		CreateTime:        timestamppb.New(*qAPIKey.CreateTime),
-		UpdateTime:        timestamppb.New(*qAPIKey.UpdateTime),
-		ExpireTime:        timestampOrNil(qAPIKey.ExpireTime),
-		Revoked:           qAPIKey.SecretTokenSha256 == nil,
-		SecretToken:       "", // intentionally left blank
-		SecretTokenSuffix: derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		Id:                            idformat.APIKey.Format(qAPIKey.ID),
+		OrganizationId:                idformat.Organization.Format(qAPIKey.OrganizationID),
+		DisplayName:                   qAPIKey.DisplayName,
+		CreateTime:                    timestamppb.New(*qAPIKey.CreateTime),
+		UpdateTime:                    timestamppb.New(*qAPIKey.UpdateTime),
+		ExpireTime:                    timestampOrNil(qAPIKey.ExpireTime),
+		Revoked:                       qAPIKey.SecretTokenSha256 == nil,
+		SecretToken:                   "", // intentionally left blank
+		SecretTokenSuffix:             derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		RateLimitPerMinute:            qAPIKey.RateLimitPerMinute,
+		PreviousSecretTokenExpireTime: previousSecretTokenExpireTimeOrNil(qAPIKey),
+		UserId:                        userID,
+		Actions:                       qAPIKey.Actions,
+	}
+}
+
+// previousSecretTokenExpireTimeOrNil returns when the previous secret token of
+// qAPIKey expires, if qAPIKey is being rotated.
+func previousSecretTokenExpireTimeOrNil(qAPIKey queries.ApiKey) *timestamppb.Timestamp {
+	if qAPIKey.PreviousSecretTokenSha256 == nil || qAPIKey.PreviousSecretTokenExpireTime == nil || !qAPIKey.PreviousSecretTokenExpireTime.After(time.Now()) {
+		return nil
 	}
+	return timestamppb.New(*qAPIKey.PreviousSecretTokenExpireTime)
 }
@@ -1,13 +1,19 @@
 package store
 
 import (
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/ujwt"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestCreateAPIKey_ApiKeysEnabled(t *testing.T) {
@@ -286,6 +292,340 @@ func TestAuthenticateAPIKey_Success(t *testing.T) {
 	require.Equal(t, orgID, authResp.OrganizationId)
 }
 
+func TestAuthenticateAPIKey_PersonalAPIKey(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2),
+  		 (gen_random_uuid(), $1::uuid, $3, $3),
+  		 (gen_random_uuid(), $1::uuid, $4, $4);
+`,
+		uuid.UUID(projectID).String(),
+		"test.action.1",
+		"test.action.2",
+		"test.action.3",
+	)
+	require.NoError(t, err)
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			OrganizationId: orgID,
+			DisplayName:    "test-role",
+			Actions:        []string{"test.action.1", "test.action.2"},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId: userID,
+			RoleId: roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "personal",
+			UserId:         &userID,
+			Actions:        []string{"test.action.3", "test.action.2"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, userID, createResp.ApiKey.GetUserId())
+	require.Equal(t, []string{"test.action.2", "test.action.3"}, createResp.ApiKey.Actions)
+
+	// the api key only has the actions its owner has
+	authResp, err := u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	require.NoError(t, err)
+	require.Equal(t, userID, authResp.UserId)
+	require.Equal(t, []string{"test.action.2"}, authResp.Actions)
+
+	_, err = u.Store.CreateAPIKeyRoleAssignment(ctx, &backendv1.CreateAPIKeyRoleAssignmentRequest{
+		ApiKeyRoleAssignment: &backendv1.APIKeyRoleAssignment{
+			ApiKeyId: createResp.ApiKey.Id,
+			RoleId:   roleResp.Role.Id,
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	// deleting the owner revokes and deletes their personal api keys
+	_, err = u.Store.DeleteUser(ctx, &backendv1.DeleteUserRequest{Id: userID})
+	require.NoError(t, err)
+
+	apiKeyID, err := idformat.APIKey.Parse(createResp.ApiKey.Id)
+	require.NoError(t, err)
+
+	var revokeEvents int
+	err = u.Environment.DB.QueryRow(t.Context(), `
+SELECT count(*) FROM audit_log_events WHERE event_name = 'tesseral.api_keys.revoke' AND resource_id = $1::uuid;
+`, uuid.UUID(apiKeyID).String()).Scan(&revokeEvents)
+	require.NoError(t, err)
+	require.Equal(t, 1, revokeEvents)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestCreateAPIKey_ActionsRequireUser(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	_, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+			Actions:        []string{"test.action.1"},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestAuthenticateAPIKey_IPAllowlist(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.UpdateOrganizationIPAllowlist(ctx, &backendv1.UpdateOrganizationIPAllowlistRequest{
+		OrganizationId: orgID,
+		OrganizationIpAllowlist: &backendv1.OrganizationIPAllowlist{
+			Cidrs: []string{"192.0.2.0/24"},
+		},
+	})
+	require.NoError(t, err)
+
+	authResp, err := u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+		ClientIp:    "192.0.2.1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, orgID, authResp.OrganizationId)
+
+	for _, clientIP := range []string{"198.51.100.1", ""} {
+		_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+			SecretToken: createResp.ApiKey.SecretToken,
+			ClientIp:    clientIP,
+		})
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Equal(t, connect.CodePermissionDenied, connectErr.Code())
+	}
+}
+
+func TestIssueAPIKeyAccessToken(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	expireTime := time.Now().Add(time.Minute).Truncate(time.Second)
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+			ExpireTime:     timestamppb.New(expireTime),
+		},
+	})
+	require.NoError(t, err)
+
+	issueResp, err := u.Store.IssueAPIKeyAccessToken(ctx, &backendv1.IssueAPIKeyAccessTokenRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	require.NoError(t, err)
+
+	// the access token does not outlive the api key
+	require.True(t, expireTime.Equal(issueResp.ExpireTime.AsTime()))
+
+	kid, err := ujwt.KeyID(issueResp.AccessToken)
+	require.NoError(t, err)
+
+	publicKeys, err := u.Store.GetSessionPublicKeysByProjectID(ctx, u.ProjectID)
+	require.NoError(t, err)
+
+	var publicKey *ecdsa.PublicKey
+	for _, k := range publicKeys {
+		if k.ID == kid {
+			publicKey = k.PublicKey
+		}
+	}
+	require.NotNil(t, publicKey)
+
+	// the access token is not valid where a session access token is expected
+	iss := fmt.Sprintf("https://%s.tesseral.app", strings.ReplaceAll(u.ProjectID, "_", "-"))
+	var claims map[string]any
+	require.Error(t, ujwt.Claims(publicKey, iss, time.Now(), &claims, issueResp.AccessToken))
+	require.NoError(t, ujwt.Claims(publicKey, iss+"/api-keys", time.Now(), &claims, issueResp.AccessToken))
+	require.Equal(t, iss, claims["iss"])
+	require.Equal(t, createResp.ApiKey.Id, claims["sub"])
+	require.Equal(t, map[string]any{"id": createResp.ApiKey.Id}, claims["apiKey"])
+	require.Equal(t, orgID, claims["organization"].(map[string]any)["id"])
+}
+
+func TestIssueAPIKeyAccessToken_InvalidSecretToken(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.IssueAPIKeyAccessToken(ctx, &backendv1.IssueAPIKeyAccessTokenRequest{
+		SecretToken: idformat.APIKey.Format(uuid.New()),
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+	oldSecretToken := createResp.ApiKey.SecretToken
+
+	rotateResp, err := u.Store.RotateAPIKey(ctx, &backendv1.RotateAPIKeyRequest{
+		Id:                            createResp.ApiKey.Id,
+		PreviousSecretTokenExpireTime: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, createResp.ApiKey.Id, rotateResp.ApiKey.Id)
+	require.NotEqual(t, oldSecretToken, rotateResp.ApiKey.SecretToken)
+	require.NotNil(t, rotateResp.ApiKey.PreviousSecretTokenExpireTime)
+	newSecretToken := rotateResp.ApiKey.SecretToken
+
+	// both secret tokens are valid during the overlap
+	for _, secretToken := range []string{oldSecretToken, newSecretToken} {
+		authResp, err := u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+			SecretToken: secretToken,
+		})
+		require.NoError(t, err)
+		require.Equal(t, createResp.ApiKey.Id, authResp.ApiKeyId)
+	}
+
+	// rotating without an overlap invalidates the current secret token
+	// immediately, and the one before it too
+	rotateResp, err = u.Store.RotateAPIKey(ctx, &backendv1.RotateAPIKeyRequest{
+		Id: createResp.ApiKey.Id,
+	})
+	require.NoError(t, err)
+	require.Nil(t, rotateResp.ApiKey.PreviousSecretTokenExpireTime)
+
+	for _, secretToken := range []string{oldSecretToken, newSecretToken} {
+		_, err := u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+			SecretToken: secretToken,
+		})
+		require.Error(t, err)
+	}
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: rotateResp.ApiKey.SecretToken,
+	})
+	require.NoError(t, err)
+}
+
+func TestRotateAPIKey_OverlapTooLong(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.RotateAPIKey(ctx, &backendv1.RotateAPIKeyRequest{
+		Id:                            createResp.ApiKey.Id,
+		PreviousSecretTokenExpireTime: timestamppb.New(time.Now().Add(maxAPIKeyRotationOverlap + time.Hour)),
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestRotateAPIKey_Revoked(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.RevokeAPIKey(ctx, &backendv1.RevokeAPIKeyRequest{Id: createResp.ApiKey.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.RotateAPIKey(ctx, &backendv1.RotateAPIKeyRequest{Id: createResp.ApiKey.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+}
+
 func TestAuthenticateAPIKey_InvalidSecretToken(t *testing.T) {
 	t.Parallel()
 
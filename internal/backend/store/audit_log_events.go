@@ -199,6 +199,14 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			}
 			listParams.ResourceID = (*uuid.UUID)(&userID)
 			listParams.ResourceType = &resourceType
+		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_BACKEND_API_KEY:
+			resourceType := queries.AuditLogEventResourceTypeBackendApiKey
+			backendAPIKeyID, err := idformat.BackendAPIKey.Parse(req.ResourceId)
+			if err != nil {
+				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse backend api key id: %w", err))
+			}
+			listParams.ResourceID = (*uuid.UUID)(&backendAPIKeyID)
+			listParams.ResourceType = &resourceType
 		default:
 			return nil, apierror.NewInvalidArgumentError("invalid resource_type", fmt.Errorf("unknown resource type: %s", req.ResourceType))
 		}
@@ -344,6 +352,9 @@ func (s *Store) ConsoleListAuditLogEventNames(ctx context.Context, req *backendv
 		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_USER:
 			resourceType := queries.AuditLogEventResourceTypeUser
 			listParams.ResourceType = &resourceType
+		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_BACKEND_API_KEY:
+			resourceType := queries.AuditLogEventResourceTypeBackendApiKey
+			listParams.ResourceType = &resourceType
 		default:
 			return nil, apierror.NewInvalidArgumentError("invalid resource_type", fmt.Errorf("unknown resource type: %s", req.ResourceType))
 		}
@@ -0,0 +1,383 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListAuditLogStreams(ctx context.Context, req *backendv1.ListAuditLogStreamsRequest) (*backendv1.ListAuditLogStreamsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qAuditLogStreams, err := q.ListAuditLogStreams(ctx, queries.ListAuditLogStreamsParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        startID,
+		Limit:     int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list audit log streams: %w", err)
+	}
+
+	var auditLogStreams []*backendv1.AuditLogStream
+	for _, qAuditLogStream := range qAuditLogStreams {
+		auditLogStreams = append(auditLogStreams, parseAuditLogStream(qAuditLogStream))
+	}
+
+	var nextPageToken string
+	if len(auditLogStreams) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qAuditLogStreams[limit].ID)
+		auditLogStreams = auditLogStreams[:limit]
+	}
+
+	return &backendv1.ListAuditLogStreamsResponse{
+		AuditLogStreams: auditLogStreams,
+		NextPageToken:   nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetAuditLogStream(ctx context.Context, req *backendv1.GetAuditLogStreamRequest) (*backendv1.GetAuditLogStreamResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qAuditLogStream, err := s.getAuditLogStream(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backendv1.GetAuditLogStreamResponse{AuditLogStream: parseAuditLogStream(*qAuditLogStream)}, nil
+}
+
+func (s *Store) CreateAuditLogStream(ctx context.Context, req *backendv1.CreateAuditLogStreamRequest) (*backendv1.CreateAuditLogStreamResponse, error) {
+	if req.AuditLogStream == nil {
+		return nil, apierror.NewInvalidArgumentError("audit_log_stream is required", fmt.Errorf("audit log stream is nil"))
+	}
+
+	params := queries.CreateAuditLogStreamParams{
+		ID:          uuid.New(),
+		ProjectID:   authn.ProjectID(ctx),
+		DisplayName: req.AuditLogStream.DisplayName,
+		Enabled:     true,
+		// only stream events written from now on; the cursor's transaction ID
+		// defaults to that of this transaction
+		CursorAuditLogEventID: uuidv7.NewWithTime(time.Now()),
+	}
+
+	if req.AuditLogStream.Enabled != nil {
+		params.Enabled = *req.AuditLogStream.Enabled
+	}
+
+	switch req.AuditLogStream.Type {
+	case backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_WEBHOOK:
+		if err := validateAuditLogStreamURL("webhook_url", req.AuditLogStream.WebhookUrl); err != nil {
+			return nil, err
+		}
+
+		params.Type = queries.AuditLogStreamTypeWebhook
+		params.WebhookUrl = &req.AuditLogStream.WebhookUrl
+	case backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_SYSLOG:
+		if err := validateAuditLogStreamSyslogAddress(req.AuditLogStream.SyslogAddress); err != nil {
+			return nil, err
+		}
+
+		params.Type = queries.AuditLogStreamTypeSyslog
+		params.SyslogAddress = &req.AuditLogStream.SyslogAddress
+		params.SyslogTls = derefOrEmpty(req.AuditLogStream.SyslogTls)
+	case backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_SPLUNK_HEC:
+		if err := validateAuditLogStreamURL("splunk_hec_url", req.AuditLogStream.SplunkHecUrl); err != nil {
+			return nil, err
+		}
+
+		if req.AuditLogStream.SplunkHecToken == "" {
+			return nil, apierror.NewInvalidArgumentError("splunk_hec_token is required", fmt.Errorf("splunk hec token is empty"))
+		}
+
+		// encrypt outside a transaction, so that we can carry out KMS operations
+		splunkHecTokenCiphertext, err := s.auditLogStreamSecretsKMS.Encrypt(ctx, []byte(req.AuditLogStream.SplunkHecToken))
+		if err != nil {
+			return nil, fmt.Errorf("encrypt splunk hec token: %w", err)
+		}
+
+		params.Type = queries.AuditLogStreamTypeSplunkHec
+		params.SplunkHecUrl = &req.AuditLogStream.SplunkHecUrl
+		params.SplunkHecTokenCiphertext = splunkHecTokenCiphertext
+	default:
+		return nil, apierror.NewInvalidArgumentError("type is required", fmt.Errorf("invalid audit log stream type: %v", req.AuditLogStream.Type))
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	if req.AuditLogStream.OrganizationId != "" {
+		orgID, err := idformat.Organization.Parse(req.AuditLogStream.OrganizationId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+		}
+
+		if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        orgID,
+		}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization by id: %w", err))
+			}
+
+			return nil, fmt.Errorf("get organization: %w", err)
+		}
+
+		orgUUID := uuid.UUID(orgID)
+		params.OrganizationID = &orgUUID
+	}
+
+	qAuditLogStream, err := q.CreateAuditLogStream(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("create audit log stream: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.CreateAuditLogStreamResponse{AuditLogStream: parseAuditLogStream(qAuditLogStream)}, nil
+}
+
+func (s *Store) UpdateAuditLogStream(ctx context.Context, req *backendv1.UpdateAuditLogStreamRequest) (*backendv1.UpdateAuditLogStreamResponse, error) {
+	if req.AuditLogStream == nil {
+		return nil, apierror.NewInvalidArgumentError("audit_log_stream is required", fmt.Errorf("audit log stream is nil"))
+	}
+
+	var splunkHecTokenCiphertext []byte
+	if req.AuditLogStream.SplunkHecToken != "" {
+		// encrypt outside a transaction, so that we can carry out KMS operations
+		ciphertext, err := s.auditLogStreamSecretsKMS.Encrypt(ctx, []byte(req.AuditLogStream.SplunkHecToken))
+		if err != nil {
+			return nil, fmt.Errorf("encrypt splunk hec token: %w", err)
+		}
+		splunkHecTokenCiphertext = ciphertext
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qAuditLogStream, err := s.getAuditLogStream(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := queries.UpdateAuditLogStreamParams{
+		ID:                       qAuditLogStream.ID,
+		DisplayName:              qAuditLogStream.DisplayName,
+		Enabled:                  qAuditLogStream.Enabled,
+		WebhookUrl:               qAuditLogStream.WebhookUrl,
+		SyslogAddress:            qAuditLogStream.SyslogAddress,
+		SyslogTls:                qAuditLogStream.SyslogTls,
+		SplunkHecUrl:             qAuditLogStream.SplunkHecUrl,
+		SplunkHecTokenCiphertext: qAuditLogStream.SplunkHecTokenCiphertext,
+	}
+
+	if req.AuditLogStream.DisplayName != "" {
+		updates.DisplayName = req.AuditLogStream.DisplayName
+	}
+
+	if req.AuditLogStream.Enabled != nil {
+		updates.Enabled = *req.AuditLogStream.Enabled
+	}
+
+	if req.AuditLogStream.WebhookUrl != "" {
+		if qAuditLogStream.Type != queries.AuditLogStreamTypeWebhook {
+			return nil, apierror.NewInvalidArgumentError("webhook_url can only be set on webhook audit log streams", fmt.Errorf("audit log stream type is %q", qAuditLogStream.Type))
+		}
+
+		if err := validateAuditLogStreamURL("webhook_url", req.AuditLogStream.WebhookUrl); err != nil {
+			return nil, err
+		}
+
+		updates.WebhookUrl = &req.AuditLogStream.WebhookUrl
+	}
+
+	if req.AuditLogStream.SyslogAddress != "" || req.AuditLogStream.SyslogTls != nil {
+		if qAuditLogStream.Type != queries.AuditLogStreamTypeSyslog {
+			return nil, apierror.NewInvalidArgumentError("syslog settings can only be set on syslog audit log streams", fmt.Errorf("audit log stream type is %q", qAuditLogStream.Type))
+		}
+
+		if req.AuditLogStream.SyslogAddress != "" {
+			if err := validateAuditLogStreamSyslogAddress(req.AuditLogStream.SyslogAddress); err != nil {
+				return nil, err
+			}
+
+			updates.SyslogAddress = &req.AuditLogStream.SyslogAddress
+		}
+
+		if req.AuditLogStream.SyslogTls != nil {
+			updates.SyslogTls = *req.AuditLogStream.SyslogTls
+		}
+	}
+
+	if req.AuditLogStream.SplunkHecUrl != "" || splunkHecTokenCiphertext != nil {
+		if qAuditLogStream.Type != queries.AuditLogStreamTypeSplunkHec {
+			return nil, apierror.NewInvalidArgumentError("splunk hec settings can only be set on splunk hec audit log streams", fmt.Errorf("audit log stream type is %q", qAuditLogStream.Type))
+		}
+
+		if req.AuditLogStream.SplunkHecUrl != "" {
+			if err := validateAuditLogStreamURL("splunk_hec_url", req.AuditLogStream.SplunkHecUrl); err != nil {
+				return nil, err
+			}
+
+			updates.SplunkHecUrl = &req.AuditLogStream.SplunkHecUrl
+		}
+
+		if splunkHecTokenCiphertext != nil {
+			updates.SplunkHecTokenCiphertext = splunkHecTokenCiphertext
+		}
+	}
+
+	qUpdatedAuditLogStream, err := q.UpdateAuditLogStream(ctx, updates)
+	if err != nil {
+		return nil, fmt.Errorf("update audit log stream: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateAuditLogStreamResponse{AuditLogStream: parseAuditLogStream(qUpdatedAuditLogStream)}, nil
+}
+
+func (s *Store) DeleteAuditLogStream(ctx context.Context, req *backendv1.DeleteAuditLogStreamRequest) (*backendv1.DeleteAuditLogStreamResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qAuditLogStream, err := s.getAuditLogStream(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.DeleteAuditLogStream(ctx, qAuditLogStream.ID); err != nil {
+		return nil, fmt.Errorf("delete audit log stream: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteAuditLogStreamResponse{}, nil
+}
+
+func (s *Store) getAuditLogStream(ctx context.Context, q *queries.Queries, id string) (*queries.AuditLogStream, error) {
+	auditLogStreamID, err := idformat.AuditLogStream.Parse(id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid audit log stream id", fmt.Errorf("parse audit log stream id: %w", err))
+	}
+
+	qAuditLogStream, err := q.GetAuditLogStream(ctx, queries.GetAuditLogStreamParams{
+		ID:        auditLogStreamID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("audit log stream not found", fmt.Errorf("get audit log stream: %w", err))
+		}
+
+		return nil, fmt.Errorf("get audit log stream: %w", err)
+	}
+
+	return &qAuditLogStream, nil
+}
+
+func validateAuditLogStreamURL(field, streamURL string) error {
+	u, err := url.Parse(streamURL)
+	if err != nil {
+		return apierror.NewInvalidArgumentError(fmt.Sprintf("invalid %s", field), fmt.Errorf("parse %s: %w", field, err))
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return apierror.NewInvalidArgumentError(fmt.Sprintf("%s must be an absolute http or https url", field), fmt.Errorf("invalid %s scheme: %q", field, u.Scheme))
+	}
+
+	if u.Host == "" {
+		return apierror.NewInvalidArgumentError(fmt.Sprintf("%s must be an absolute http or https url", field), fmt.Errorf("%s has no host", field))
+	}
+
+	return nil
+}
+
+func validateAuditLogStreamSyslogAddress(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" || port == "" {
+		return apierror.NewInvalidArgumentError("syslog_address must be of the form host:port", fmt.Errorf("invalid syslog address: %q", address))
+	}
+
+	return nil
+}
+
+func parseAuditLogStream(qAuditLogStream queries.AuditLogStream) *backendv1.AuditLogStream {
+	var organizationID string
+	if qAuditLogStream.OrganizationID != nil {
+		organizationID = idformat.Organization.Format(*qAuditLogStream.OrganizationID)
+	}
+
+	var streamType backendv1.AuditLogStreamType
+	switch qAuditLogStream.Type {
+	case queries.AuditLogStreamTypeWebhook:
+		streamType = backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_WEBHOOK
+	case queries.AuditLogStreamTypeSyslog:
+		streamType = backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_SYSLOG
+	case queries.AuditLogStreamTypeSplunkHec:
+		streamType = backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_SPLUNK_HEC
+	}
+
+	var lastErrorTime *timestamppb.Timestamp
+	if qAuditLogStream.LastErrorTime != nil {
+		lastErrorTime = timestamppb.New(*qAuditLogStream.LastErrorTime)
+	}
+
+	return &backendv1.AuditLogStream{
+		Id:             idformat.AuditLogStream.Format(qAuditLogStream.ID),
+		OrganizationId: organizationID,
+		DisplayName:    qAuditLogStream.DisplayName,
+		Type:           streamType,
+		Enabled:        &qAuditLogStream.Enabled,
+		WebhookUrl:     derefOrEmpty(qAuditLogStream.WebhookUrl),
+		SyslogAddress:  derefOrEmpty(qAuditLogStream.SyslogAddress),
+		SyslogTls:      &qAuditLogStream.SyslogTls,
+		SplunkHecUrl:   derefOrEmpty(qAuditLogStream.SplunkHecUrl),
+		LastError:      derefOrEmpty(qAuditLogStream.LastError),
+		LastErrorTime:  lastErrorTime,
+		CreateTime:     timestamppb.New(*qAuditLogStream.CreateTime),
+		UpdateTime:     timestamppb.New(*qAuditLogStream.UpdateTime),
+	}
+}
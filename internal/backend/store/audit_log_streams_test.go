@@ -0,0 +1,127 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func TestCreateAuditLogStream_Webhook(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	res, err := u.Store.CreateAuditLogStream(ctx, &backendv1.CreateAuditLogStreamRequest{
+		AuditLogStream: &backendv1.AuditLogStream{
+			OrganizationId: orgID,
+			DisplayName:    "siem",
+			Type:           backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_WEBHOOK,
+			WebhookUrl:     "https://example.com/audit-logs",
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.AuditLogStream.Id)
+	require.Equal(t, orgID, res.AuditLogStream.OrganizationId)
+	require.Equal(t, "siem", res.AuditLogStream.DisplayName)
+	require.Equal(t, backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_WEBHOOK, res.AuditLogStream.Type)
+	require.Equal(t, "https://example.com/audit-logs", res.AuditLogStream.WebhookUrl)
+	require.True(t, res.AuditLogStream.GetEnabled())
+}
+
+func TestCreateAuditLogStream_SplunkHEC(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateAuditLogStream(ctx, &backendv1.CreateAuditLogStreamRequest{
+		AuditLogStream: &backendv1.AuditLogStream{
+			DisplayName:    "splunk",
+			Type:           backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_SPLUNK_HEC,
+			SplunkHecUrl:   "https://splunk.example.com:8088/services/collector/event",
+			SplunkHecToken: "token",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://splunk.example.com:8088/services/collector/event", res.AuditLogStream.SplunkHecUrl)
+	require.Empty(t, res.AuditLogStream.SplunkHecToken)
+}
+
+func TestCreateAuditLogStream_InvalidSyslogAddress(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateAuditLogStream(ctx, &backendv1.CreateAuditLogStreamRequest{
+		AuditLogStream: &backendv1.AuditLogStream{
+			Type:          backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_SYSLOG,
+			SyslogAddress: "syslog.example.com",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestUpdateAuditLogStream(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateAuditLogStream(ctx, &backendv1.CreateAuditLogStreamRequest{
+		AuditLogStream: &backendv1.AuditLogStream{
+			Type:          backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_SYSLOG,
+			SyslogAddress: "syslog.example.com:6514",
+		},
+	})
+	require.NoError(t, err)
+
+	updateRes, err := u.Store.UpdateAuditLogStream(ctx, &backendv1.UpdateAuditLogStreamRequest{
+		Id: createRes.AuditLogStream.Id,
+		AuditLogStream: &backendv1.AuditLogStream{
+			SyslogTls: refOrNil(true),
+			Enabled:   refOrNil(false),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "syslog.example.com:6514", updateRes.AuditLogStream.SyslogAddress)
+	require.True(t, updateRes.AuditLogStream.GetSyslogTls())
+	require.False(t, updateRes.AuditLogStream.GetEnabled())
+
+	// webhook settings don't apply to syslog streams
+	_, err = u.Store.UpdateAuditLogStream(ctx, &backendv1.UpdateAuditLogStreamRequest{
+		Id: createRes.AuditLogStream.Id,
+		AuditLogStream: &backendv1.AuditLogStream{
+			WebhookUrl: "https://example.com/audit-logs",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestDeleteAuditLogStream(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateAuditLogStream(ctx, &backendv1.CreateAuditLogStreamRequest{
+		AuditLogStream: &backendv1.AuditLogStream{
+			Type:       backendv1.AuditLogStreamType_AUDIT_LOG_STREAM_TYPE_WEBHOOK,
+			WebhookUrl: "https://example.com/audit-logs",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.DeleteAuditLogStream(ctx, &backendv1.DeleteAuditLogStreamRequest{Id: createRes.AuditLogStream.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.GetAuditLogStream(ctx, &backendv1.GetAuditLogStreamRequest{Id: createRes.AuditLogStream.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
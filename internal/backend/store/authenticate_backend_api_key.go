@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 )
@@ -17,6 +19,14 @@ type AuthenticateBackendAPIKeyResponse struct {
 	BackendAPIKeyID    string
 	ProjectID          string
 	AuthenticationOnly bool
+
+	// Scopes is the list of scopes the key is limited to, or nil if the key is
+	// not limited to any scopes.
+	Scopes []string
+
+	// OrganizationIDs is the list of organizations the key is limited to, or
+	// nil if the key may act on every organization.
+	OrganizationIDs []uuid.UUID
 }
 
 func (s *Store) AuthenticateBackendAPIKey(ctx context.Context, bearerToken string) (*AuthenticateBackendAPIKeyResponse, error) {
@@ -41,9 +51,22 @@ func (s *Store) AuthenticateBackendAPIKey(ctx context.Context, bearerToken strin
 		return nil, fmt.Errorf("get backend api key by secret token sha256: %w", err)
 	}
 
+	clientIP := clientip.FromContext(ctx)
+	if !clientip.Allowed(clientIP, qBackendAPIKey.IpAllowlistCidrs) {
+		return nil, apierror.NewIPAddressNotAllowedError("client ip address is not allowed by backend api key ip allowlist", fmt.Errorf("client ip not in backend api key ip allowlist"))
+	}
+
+	var ip string
+	if clientIP.IsValid() {
+		ip = clientIP.String()
+	}
+	s.backendAPIKeyUsage.record(qBackendAPIKey.ID, qBackendAPIKey.ProjectID, ip)
+
 	return &AuthenticateBackendAPIKeyResponse{
 		BackendAPIKeyID:    idformat.BackendAPIKey.Format(qBackendAPIKey.ID),
 		ProjectID:          idformat.Project.Format(qBackendAPIKey.ProjectID),
 		AuthenticationOnly: qBackendAPIKey.AuthenticationOnly,
+		Scopes:             qBackendAPIKey.Scopes,
+		OrganizationIDs:    qBackendAPIKey.OrganizationIds,
 	}, nil
 }
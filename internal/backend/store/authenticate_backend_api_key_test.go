@@ -1,12 +1,17 @@
 package store
 
 import (
+	"net/netip"
 	"testing"
+	"time"
 
+	"connectrpc.com/connect"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestAuthenticateBackendAPIKey_Success(t *testing.T) {
@@ -37,3 +42,112 @@ func TestAuthenticateBackendAPIKey_InvalidToken(t *testing.T) {
 	_, err := u.Store.AuthenticateBackendAPIKey(ctx, idformat.BackendAPIKey.Format(uuid.New()))
 	require.Error(t, err)
 }
+
+func TestAuthenticateBackendAPIKey_Expired(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createResp, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName: "test-key",
+			ExpireTime:  timestamppb.New(time.Now().Add(time.Hour)),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.AuthenticateBackendAPIKey(ctx, createResp.BackendApiKey.SecretToken)
+	require.NoError(t, err)
+
+	backendAPIKeyID, err := idformat.BackendAPIKey.Parse(createResp.BackendApiKey.Id)
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(ctx, `
+	UPDATE backend_api_keys
+	SET expire_time = now() - interval '1 minute'
+	WHERE id = $1;
+	`,
+		uuid.UUID(backendAPIKeyID).String(),
+	)
+	require.NoError(t, err)
+
+	_, err = u.Store.AuthenticateBackendAPIKey(ctx, createResp.BackendApiKey.SecretToken)
+	require.Error(t, err)
+}
+
+func TestAuthenticateBackendAPIKey_IPAllowlist(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createResp, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName:      "test-key",
+			IpAllowlistCidrs: []string{"192.0.2.0/24"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.0/24"}, createResp.BackendApiKey.IpAllowlistCidrs)
+
+	_, err = u.Store.AuthenticateBackendAPIKey(clientip.NewContext(ctx, netip.MustParseAddr("192.0.2.1")), createResp.BackendApiKey.SecretToken)
+	require.NoError(t, err)
+
+	_, err = u.Store.AuthenticateBackendAPIKey(clientip.NewContext(ctx, netip.MustParseAddr("198.51.100.1")), createResp.BackendApiKey.SecretToken)
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodePermissionDenied, connectErr.Code())
+}
+
+func TestFlushBackendAPIKeyUsage(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createResp, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName: "test-key",
+		},
+	})
+	require.NoError(t, err)
+
+	backendAPIKeyID, err := idformat.BackendAPIKey.Parse(createResp.BackendApiKey.Id)
+	require.NoError(t, err)
+
+	countNewIPEvents := func() int {
+		var count int
+		err := u.Environment.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM audit_log_events
+		WHERE resource_id = $1 AND event_name = 'tesseral.backend_api_keys.use_from_new_ip';
+		`,
+			uuid.UUID(backendAPIKeyID).String(),
+		).Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+
+	ipCtx := clientip.NewContext(ctx, netip.MustParseAddr("192.0.2.1"))
+	for range 3 {
+		_, err = u.Store.AuthenticateBackendAPIKey(ipCtx, createResp.BackendApiKey.SecretToken)
+		require.NoError(t, err)
+	}
+
+	// nothing is written until the usage is flushed
+	getResp, err := u.Store.GetBackendAPIKey(ctx, &backendv1.GetBackendAPIKeyRequest{Id: createResp.BackendApiKey.Id})
+	require.NoError(t, err)
+	require.Nil(t, getResp.BackendApiKey.LastUsedTime)
+
+	require.NoError(t, u.Store.FlushBackendAPIKeyUsage(ctx))
+
+	getResp, err = u.Store.GetBackendAPIKey(ctx, &backendv1.GetBackendAPIKeyRequest{Id: createResp.BackendApiKey.Id})
+	require.NoError(t, err)
+	require.NotNil(t, getResp.BackendApiKey.LastUsedTime)
+	require.Equal(t, "192.0.2.1", getResp.BackendApiKey.LastUsedIp)
+	require.Equal(t, 1, countNewIPEvents())
+
+	// a known ip does not log another event
+	_, err = u.Store.AuthenticateBackendAPIKey(ipCtx, createResp.BackendApiKey.SecretToken)
+	require.NoError(t, err)
+	require.NoError(t, u.Store.FlushBackendAPIKeyUsage(ctx))
+	require.Equal(t, 1, countNewIPEvents())
+}
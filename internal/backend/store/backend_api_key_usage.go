@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// BackendAPIKeyUsageFlushInterval is how often RunBackendAPIKeyUsageFlusher
+// writes buffered backend API key usage to the database.
+const BackendAPIKeyUsageFlushInterval = 30 * time.Second
+
+// backendAPIKeyUsage buffers the uses of backend API keys, so that
+// authenticating a key does not write to the database on every request.
+type backendAPIKeyUsage struct {
+	mu   sync.Mutex
+	uses map[uuid.UUID]*backendAPIKeyUse
+}
+
+type backendAPIKeyUse struct {
+	projectID    uuid.UUID
+	lastUsedTime time.Time
+	lastUsedIP   string
+
+	// ips is the set of IP addresses the key was used from since the last
+	// flush.
+	ips map[string]struct{}
+}
+
+func (u *backendAPIKeyUsage) record(backendAPIKeyID, projectID uuid.UUID, ip string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.uses == nil {
+		u.uses = map[uuid.UUID]*backendAPIKeyUse{}
+	}
+
+	use, ok := u.uses[backendAPIKeyID]
+	if !ok {
+		use = &backendAPIKeyUse{projectID: projectID, ips: map[string]struct{}{}}
+		u.uses[backendAPIKeyID] = use
+	}
+
+	use.lastUsedTime = time.Now()
+	use.lastUsedIP = ip
+	if ip != "" {
+		use.ips[ip] = struct{}{}
+	}
+}
+
+func (u *backendAPIKeyUsage) take() map[uuid.UUID]*backendAPIKeyUse {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	uses := u.uses
+	u.uses = nil
+	return uses
+}
+
+// RunBackendAPIKeyUsageFlusher calls FlushBackendAPIKeyUsage every
+// BackendAPIKeyUsageFlushInterval, and once more when ctx is done.
+func (s *Store) RunBackendAPIKeyUsageFlusher(ctx context.Context) {
+	ticker := time.NewTicker(BackendAPIKeyUsageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.FlushBackendAPIKeyUsage(context.WithoutCancel(ctx)); err != nil {
+				slog.ErrorContext(ctx, "flush_backend_api_key_usage", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.FlushBackendAPIKeyUsage(ctx); err != nil {
+				slog.ErrorContext(ctx, "flush_backend_api_key_usage", "error", err)
+			}
+		}
+	}
+}
+
+// FlushBackendAPIKeyUsage writes the backend API key uses buffered since the
+// last flush to the database, and logs an audit event for every key used from
+// an IP address it was never used from before.
+//
+// A key that fails to flush, e.g. because it was deleted in the meantime, does
+// not stop the other keys from being flushed.
+func (s *Store) FlushBackendAPIKeyUsage(ctx context.Context) error {
+	var errs []error
+	for backendAPIKeyID, use := range s.backendAPIKeyUsage.take() {
+		if err := s.flushBackendAPIKeyUse(ctx, backendAPIKeyID, use); err != nil {
+			errs = append(errs, fmt.Errorf("flush backend api key use: %s: %w", idformat.BackendAPIKey.Format(backendAPIKeyID), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Store) flushBackendAPIKeyUse(ctx context.Context, backendAPIKeyID uuid.UUID, use *backendAPIKeyUse) error {
+	// the audit events are attributed to the key itself
+	ctx = authn.NewBackendAPIKeyContext(ctx, &authn.BackendAPIKeyContextData{
+		BackendAPIKeyID: idformat.BackendAPIKey.Format(backendAPIKeyID),
+		ProjectID:       idformat.Project.Format(use.projectID),
+	})
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback()
+
+	if err := q.UpdateBackendAPIKeyLastUsed(ctx, queries.UpdateBackendAPIKeyLastUsedParams{
+		ID:           backendAPIKeyID,
+		LastUsedTime: &use.lastUsedTime,
+		LastUsedIp:   refOrNil(use.lastUsedIP),
+	}); err != nil {
+		return fmt.Errorf("update backend api key last used: %w", err)
+	}
+
+	for ip := range use.ips {
+		created, err := q.CreateBackendAPIKeyIP(ctx, queries.CreateBackendAPIKeyIPParams{
+			BackendApiKeyID: backendAPIKeyID,
+			Ip:              ip,
+		})
+		if err != nil {
+			return fmt.Errorf("create backend api key ip: %w", err)
+		}
+
+		if created == 0 {
+			continue
+		}
+
+		auditBackendAPIKey, err := s.auditlogStore.GetBackendAPIKey(ctx, tx, backendAPIKeyID)
+		if err != nil {
+			return fmt.Errorf("get audit backend api key: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+			EventName: "tesseral.backend_api_keys.use_from_new_ip",
+			EventDetails: &auditlogv1.UseBackendAPIKeyFromNewIP{
+				BackendApiKey: auditBackendAPIKey,
+				Ip:            ip,
+			},
+			ResourceType: queries.AuditLogEventResourceTypeBackendApiKey,
+			ResourceID:   &backendAPIKeyID,
+		}); err != nil {
+			return fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	if err := commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
@@ -5,12 +5,17 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"net/netip"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -98,7 +103,7 @@ func (s *Store) CreateBackendAPIKey(ctx context.Context, req *backendv1.CreateBa
 		return nil, fmt.Errorf("not entitled to backend api keys")
 	}
 
-	_, q, commit, rollback, err := s.tx(ctx)
+	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -109,6 +114,31 @@ func (s *Store) CreateBackendAPIKey(ctx context.Context, req *backendv1.CreateBa
 		authenticationOnly = *req.BackendApiKey.AuthenticationOnly
 	}
 
+	scopes, err := validateBackendAPIKeyScopes(req.BackendApiKey.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationIDs, err := parseBackendAPIKeyOrganizationIDs(ctx, q, req.BackendApiKey.OrganizationIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var expireTime *time.Time
+	if req.BackendApiKey.ExpireTime != nil {
+		t := req.BackendApiKey.ExpireTime.AsTime()
+		if !t.After(time.Now()) {
+			return nil, apierror.NewInvalidArgumentError("expire_time must be in the future", fmt.Errorf("expire time is not in the future"))
+		}
+
+		expireTime = &t
+	}
+
+	ipAllowlistCIDRs, err := parseBackendAPIKeyIPAllowlistCIDRs(req.BackendApiKey.IpAllowlistCidrs)
+	if err != nil {
+		return nil, err
+	}
+
 	token := uuid.New()
 	tokenSHA256 := sha256.Sum256(token[:])
 	qBackendAPIKey, err := q.CreateBackendAPIKey(ctx, queries.CreateBackendAPIKeyParams{
@@ -117,11 +147,31 @@ func (s *Store) CreateBackendAPIKey(ctx context.Context, req *backendv1.CreateBa
 		DisplayName:        req.BackendApiKey.DisplayName,
 		SecretTokenSha256:  tokenSHA256[:],
 		AuthenticationOnly: authenticationOnly,
+		Scopes:             scopes,
+		OrganizationIds:    organizationIDs,
+		ExpireTime:         expireTime,
+		IpAllowlistCidrs:   ipAllowlistCIDRs,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create backend api key: %w", err)
 	}
 
+	auditBackendAPIKey, err := s.auditlogStore.GetBackendAPIKey(ctx, tx, qBackendAPIKey.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit backend api key: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.backend_api_keys.create",
+		EventDetails: &auditlogv1.CreateBackendAPIKey{
+			BackendApiKey: auditBackendAPIKey,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeBackendApiKey,
+		ResourceID:   &qBackendAPIKey.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
@@ -160,8 +210,12 @@ func (s *Store) UpdateBackendAPIKey(ctx context.Context, req *backendv1.UpdateBa
 	}
 
 	updates := queries.UpdateBackendAPIKeyParams{
-		ID:          backendAPIKeyID,
-		DisplayName: qBackendAPIKey.DisplayName,
+		ID:                 backendAPIKeyID,
+		DisplayName:        qBackendAPIKey.DisplayName,
+		AuthenticationOnly: qBackendAPIKey.AuthenticationOnly,
+		Scopes:             qBackendAPIKey.Scopes,
+		OrganizationIds:    qBackendAPIKey.OrganizationIds,
+		IpAllowlistCidrs:   qBackendAPIKey.IpAllowlistCidrs,
 	}
 
 	if req.BackendApiKey.DisplayName != "" {
@@ -172,6 +226,47 @@ func (s *Store) UpdateBackendAPIKey(ctx context.Context, req *backendv1.UpdateBa
 		updates.AuthenticationOnly = *req.BackendApiKey.AuthenticationOnly
 	}
 
+	// an empty list can't be told apart from an unset one, so clearing takes
+	// an explicit flag
+	if req.ClearScopes {
+		if len(req.BackendApiKey.Scopes) > 0 {
+			return nil, apierror.NewInvalidArgumentError("scopes must be empty when clear_scopes is set", fmt.Errorf("scopes set with clear_scopes"))
+		}
+
+		updates.Scopes = nil
+	} else if req.BackendApiKey.Scopes != nil {
+		scopes, err := validateBackendAPIKeyScopes(req.BackendApiKey.Scopes)
+		if err != nil {
+			return nil, err
+		}
+
+		updates.Scopes = scopes
+	}
+
+	if req.ClearOrganizationIds {
+		if len(req.BackendApiKey.OrganizationIds) > 0 {
+			return nil, apierror.NewInvalidArgumentError("organization_ids must be empty when clear_organization_ids is set", fmt.Errorf("organization ids set with clear_organization_ids"))
+		}
+
+		updates.OrganizationIds = nil
+	} else if req.BackendApiKey.OrganizationIds != nil {
+		organizationIDs, err := parseBackendAPIKeyOrganizationIDs(ctx, q, req.BackendApiKey.OrganizationIds)
+		if err != nil {
+			return nil, err
+		}
+
+		updates.OrganizationIds = organizationIDs
+	}
+
+	if req.BackendApiKey.IpAllowlistCidrs != nil {
+		ipAllowlistCIDRs, err := parseBackendAPIKeyIPAllowlistCIDRs(req.BackendApiKey.IpAllowlistCidrs)
+		if err != nil {
+			return nil, err
+		}
+
+		updates.IpAllowlistCidrs = ipAllowlistCIDRs
+	}
+
 	qUpdatedBackendAPIKey, err := q.UpdateBackendAPIKey(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update backend api key: %w", err)
@@ -256,6 +351,16 @@ func (s *Store) RevokeBackendAPIKey(ctx context.Context, req *backendv1.RevokeBa
 }
 
 func parseBackendAPIKey(qBackendAPIKey queries.BackendApiKey) *backendv1.BackendAPIKey {
+	var ipAllowlistCIDRs []string
+	for _, cidr := range qBackendAPIKey.IpAllowlistCidrs {
+		ipAllowlistCIDRs = append(ipAllowlistCIDRs, cidr.String())
+	}
+
+	var organizationIDs []string
+	for _, orgID := range qBackendAPIKey.OrganizationIds {
+		organizationIDs = append(organizationIDs, idformat.Organization.Format(orgID))
+	}
+
 	return &backendv1.BackendAPIKey{
 		Id:                 idformat.BackendAPIKey.Format(qBackendAPIKey.ID),
 		DisplayName:        qBackendAPIKey.DisplayName,
@@ -264,5 +369,73 @@ func parseBackendAPIKey(qBackendAPIKey queries.BackendApiKey) *backendv1.Backend
 		SecretToken:        "", // intentionally left blank
 		Revoked:            qBackendAPIKey.SecretTokenSha256 == nil,
 		AuthenticationOnly: refOrNil(qBackendAPIKey.AuthenticationOnly),
+		Scopes:             qBackendAPIKey.Scopes,
+		OrganizationIds:    organizationIDs,
+		ExpireTime:         timestampOrNil(qBackendAPIKey.ExpireTime),
+		LastUsedTime:       timestampOrNil(qBackendAPIKey.LastUsedTime),
+		LastUsedIp:         derefOrEmpty(qBackendAPIKey.LastUsedIp),
+		IpAllowlistCidrs:   ipAllowlistCIDRs,
+	}
+}
+
+// parseBackendAPIKeyIPAllowlistCIDRs returns the distinct CIDRs in cidrs, or
+// nil if cidrs is empty.
+func parseBackendAPIKeyIPAllowlistCIDRs(cidrs []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		prefix, err := clientip.ParseCIDR(cidr)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid cidr: %q", cidr), fmt.Errorf("parse cidr: %w", err))
+		}
+
+		if !slices.Contains(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes, nil
+}
+
+// validateBackendAPIKeyScopes returns the distinct scopes in scopes, or nil if
+// scopes is empty.
+func validateBackendAPIKeyScopes(scopes []string) ([]string, error) {
+	var validScopes []string
+	for _, scope := range scopes {
+		if !slices.Contains(authn.Scopes, scope) {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid scope: %q", scope), fmt.Errorf("invalid scope: %q", scope))
+		}
+
+		if !slices.Contains(validScopes, scope) {
+			validScopes = append(validScopes, scope)
+		}
+	}
+	return validScopes, nil
+}
+
+// parseBackendAPIKeyOrganizationIDs returns the distinct organizations in
+// organizationIDs, or nil if organizationIDs is empty. Every organization must
+// belong to the current project.
+func parseBackendAPIKeyOrganizationIDs(ctx context.Context, q *queries.Queries, organizationIDs []string) ([]uuid.UUID, error) {
+	var orgIDs []uuid.UUID
+	for _, organizationID := range organizationIDs {
+		orgID, err := idformat.Organization.Parse(organizationID)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+		}
+
+		if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        orgID,
+		}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization: %w", err))
+			}
+
+			return nil, fmt.Errorf("get organization: %w", err)
+		}
+
+		if !slices.Contains(orgIDs, orgID) {
+			orgIDs = append(orgIDs, orgID)
+		}
 	}
+	return orgIDs, nil
 }
@@ -109,6 +109,83 @@ func TestUpdateBackendAPIKey_UpdatesFields(t *testing.T) {
 	require.Equal(t, "key2", updateResp.BackendApiKey.DisplayName)
 }
 
+func TestCreateBackendAPIKey_Scopes(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "Test Organization",
+	})
+
+	createResp, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName:     "key1",
+			Scopes:          []string{"users:read", "users:read", "organizations:write"},
+			OrganizationIds: []string{orgID},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"users:read", "organizations:write"}, createResp.BackendApiKey.Scopes)
+	require.Equal(t, []string{orgID}, createResp.BackendApiKey.OrganizationIds)
+
+	authResp, err := u.Store.AuthenticateBackendAPIKey(ctx, createResp.BackendApiKey.SecretToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{"users:read", "organizations:write"}, authResp.Scopes)
+	require.Len(t, authResp.OrganizationIDs, 1)
+
+	// updating other fields leaves the scopes alone
+	updateResp, err := u.Store.UpdateBackendAPIKey(ctx, &backendv1.UpdateBackendAPIKeyRequest{
+		Id: createResp.BackendApiKey.Id,
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName: "key2",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"users:read", "organizations:write"}, updateResp.BackendApiKey.Scopes)
+	require.Equal(t, []string{orgID}, updateResp.BackendApiKey.OrganizationIds)
+
+	updateResp, err = u.Store.UpdateBackendAPIKey(ctx, &backendv1.UpdateBackendAPIKeyRequest{
+		Id: createResp.BackendApiKey.Id,
+		BackendApiKey: &backendv1.BackendAPIKey{
+			Scopes: []string{"audit_logs:write"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"audit_logs:write"}, updateResp.BackendApiKey.Scopes)
+}
+
+func TestCreateBackendAPIKey_InvalidScope(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName: "key1",
+			Scopes:      []string{"everything"},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestCreateBackendAPIKey_UnknownOrganization(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName:     "key1",
+			OrganizationIds: []string{idformat.Organization.Format(uuid.New())},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
 func TestRevokeBackendAPIKey(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListEmailEvents(ctx context.Context, req *backendv1.ListEmailEventsRequest) (*backendv1.ListEmailEventsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var userInviteID *uuid.UUID
+	if req.UserInviteId != "" {
+		id, err := idformat.UserInvite.Parse(req.UserInviteId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid user invite id", fmt.Errorf("parse user invite id: %w", err))
+		}
+
+		userInviteID = (*uuid.UUID)(&id)
+	}
+
+	startID := uuid.Max
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qEmailEvents, err := q.ListEmailEvents(ctx, queries.ListEmailEventsParams{
+		ProjectID:    authn.ProjectID(ctx),
+		ID:           startID,
+		EmailAddress: refOrNil(strings.ToLower(req.EmailAddress)),
+		UserInviteID: userInviteID,
+		Limit:        int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list email events: %w", err)
+	}
+
+	var emailEvents []*backendv1.EmailEvent
+	for _, qEmailEvent := range qEmailEvents {
+		emailEvents = append(emailEvents, parseEmailEvent(qEmailEvent))
+	}
+
+	var nextPageToken string
+	if len(emailEvents) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qEmailEvents[limit].ID)
+		emailEvents = emailEvents[:limit]
+	}
+
+	return &backendv1.ListEmailEventsResponse{
+		EmailEvents:   emailEvents,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func parseEmailEventType(eventType queries.EmailEventType) backendv1.EmailEventType {
+	switch eventType {
+	case queries.EmailEventTypeSent:
+		return backendv1.EmailEventType_EMAIL_EVENT_TYPE_SENT
+	case queries.EmailEventTypeDelivered:
+		return backendv1.EmailEventType_EMAIL_EVENT_TYPE_DELIVERED
+	case queries.EmailEventTypeBounced:
+		return backendv1.EmailEventType_EMAIL_EVENT_TYPE_BOUNCED
+	case queries.EmailEventTypeComplained:
+		return backendv1.EmailEventType_EMAIL_EVENT_TYPE_COMPLAINED
+	case queries.EmailEventTypeSuppressed:
+		return backendv1.EmailEventType_EMAIL_EVENT_TYPE_SUPPRESSED
+	default:
+		return backendv1.EmailEventType_EMAIL_EVENT_TYPE_UNSPECIFIED
+	}
+}
+
+func parseEmailEvent(qEmailEvent queries.EmailEvent) *backendv1.EmailEvent {
+	var userInviteID string
+	if qEmailEvent.UserInviteID != nil {
+		userInviteID = idformat.UserInvite.Format(*qEmailEvent.UserInviteID)
+	}
+
+	return &backendv1.EmailEvent{
+		Id:           idformat.EmailEvent.Format(qEmailEvent.ID),
+		EmailAddress: qEmailEvent.EmailAddress,
+		Type:         parseEmailEventType(qEmailEvent.Type),
+		UserInviteId: userInviteID,
+		Detail:       derefOrEmpty(qEmailEvent.Detail),
+		CreateTime:   timestamppb.New(*qEmailEvent.CreateTime),
+	}
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+)
+
+func (u *testUtil) newEmailEvent(t *testing.T, emailAddress, eventType string) string {
+	emailEventID := uuidv7.NewWithTime(time.Now())
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(t.Context(), `
+	INSERT INTO email_events (id, project_id, message_id, email_address, type)
+	VALUES ($1::uuid, $2::uuid, $3::uuid, $4, $5)
+	`,
+		emailEventID.String(),
+		uuid.UUID(projectID).String(),
+		uuid.New().String(),
+		emailAddress,
+		eventType,
+	)
+	require.NoError(t, err)
+
+	return idformat.EmailEvent.Format(emailEventID)
+}
+
+func TestListEmailEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	sentID := u.newEmailEvent(t, "jane@example.com", "sent")
+	bouncedID := u.newEmailEvent(t, "jane@example.com", "bounced")
+	otherID := u.newEmailEvent(t, "john@example.com", "sent")
+
+	res, err := u.Store.ListEmailEvents(ctx, &backendv1.ListEmailEventsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.EmailEvents, 3)
+	require.Equal(t, otherID, res.EmailEvents[0].Id)
+
+	res, err = u.Store.ListEmailEvents(ctx, &backendv1.ListEmailEventsRequest{EmailAddress: "Jane@Example.com"})
+	require.NoError(t, err)
+	require.Len(t, res.EmailEvents, 2)
+	require.Equal(t, bouncedID, res.EmailEvents[0].Id)
+	require.Equal(t, backendv1.EmailEventType_EMAIL_EVENT_TYPE_BOUNCED, res.EmailEvents[0].Type)
+	require.Equal(t, sentID, res.EmailEvents[1].Id)
+	require.Equal(t, backendv1.EmailEventType_EMAIL_EVENT_TYPE_SENT, res.EmailEvents[1].Type)
+}
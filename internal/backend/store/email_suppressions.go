@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListEmailSuppressions(ctx context.Context, req *backendv1.ListEmailSuppressionsRequest) (*backendv1.ListEmailSuppressionsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	startID := uuid.Max
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qEmailSuppressions, err := q.ListEmailSuppressions(ctx, queries.ListEmailSuppressionsParams{
+		ProjectID:    authn.ProjectID(ctx),
+		ID:           startID,
+		EmailAddress: refOrNil(strings.ToLower(req.EmailAddress)),
+		Limit:        int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list email suppressions: %w", err)
+	}
+
+	var emailSuppressions []*backendv1.EmailSuppression
+	for _, qEmailSuppression := range qEmailSuppressions {
+		emailSuppressions = append(emailSuppressions, parseEmailSuppression(qEmailSuppression))
+	}
+
+	var nextPageToken string
+	if len(emailSuppressions) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qEmailSuppressions[limit].ID)
+		emailSuppressions = emailSuppressions[:limit]
+	}
+
+	return &backendv1.ListEmailSuppressionsResponse{
+		EmailSuppressions: emailSuppressions,
+		NextPageToken:     nextPageToken,
+	}, nil
+}
+
+func (s *Store) DeleteEmailSuppression(ctx context.Context, req *backendv1.DeleteEmailSuppressionRequest) (*backendv1.DeleteEmailSuppressionResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	emailSuppressionID, err := idformat.EmailSuppression.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid email suppression id", fmt.Errorf("parse email suppression id: %w", err))
+	}
+
+	qEmailSuppression, err := q.GetEmailSuppression(ctx, queries.GetEmailSuppressionParams{
+		ID:        emailSuppressionID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("email suppression not found", fmt.Errorf("get email suppression: %w", err))
+		}
+
+		return nil, fmt.Errorf("get email suppression: %w", err)
+	}
+
+	auditEmailSuppression, err := s.auditlogStore.GetEmailSuppression(ctx, tx, qEmailSuppression.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit email suppression: %w", err)
+	}
+
+	if err := q.DeleteEmailSuppression(ctx, qEmailSuppression.ID); err != nil {
+		return nil, fmt.Errorf("delete email suppression: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.email_suppressions.delete",
+		EventDetails: &auditlogv1.DeleteEmailSuppression{
+			EmailSuppression: auditEmailSuppression,
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteEmailSuppressionResponse{}, nil
+}
+
+func parseEmailSuppressionReason(reason queries.EmailSuppressionReason) backendv1.EmailSuppressionReason {
+	switch reason {
+	case queries.EmailSuppressionReasonBounce:
+		return backendv1.EmailSuppressionReason_EMAIL_SUPPRESSION_REASON_BOUNCE
+	case queries.EmailSuppressionReasonComplaint:
+		return backendv1.EmailSuppressionReason_EMAIL_SUPPRESSION_REASON_COMPLAINT
+	default:
+		return backendv1.EmailSuppressionReason_EMAIL_SUPPRESSION_REASON_UNSPECIFIED
+	}
+}
+
+func parseEmailSuppression(qEmailSuppression queries.EmailSuppression) *backendv1.EmailSuppression {
+	return &backendv1.EmailSuppression{
+		Id:           idformat.EmailSuppression.Format(qEmailSuppression.ID),
+		EmailAddress: qEmailSuppression.EmailAddress,
+		Reason:       parseEmailSuppressionReason(qEmailSuppression.Reason),
+		CreateTime:   timestamppb.New(*qEmailSuppression.CreateTime),
+	}
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+)
+
+func (u *testUtil) newEmailSuppression(t *testing.T, emailAddress, reason string) string {
+	emailSuppressionID := uuidv7.NewWithTime(time.Now())
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(t.Context(), `
+	INSERT INTO email_suppressions (id, project_id, email_address, reason)
+	VALUES ($1::uuid, $2::uuid, $3, $4)
+	`,
+		emailSuppressionID.String(),
+		uuid.UUID(projectID).String(),
+		emailAddress,
+		reason,
+	)
+	require.NoError(t, err)
+
+	return idformat.EmailSuppression.Format(emailSuppressionID)
+}
+
+func TestListEmailSuppressions(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	bounceID := u.newEmailSuppression(t, "jane@example.com", "bounce")
+	complaintID := u.newEmailSuppression(t, "john@example.com", "complaint")
+
+	res, err := u.Store.ListEmailSuppressions(ctx, &backendv1.ListEmailSuppressionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.EmailSuppressions, 2)
+	require.Equal(t, complaintID, res.EmailSuppressions[0].Id)
+	require.Equal(t, backendv1.EmailSuppressionReason_EMAIL_SUPPRESSION_REASON_COMPLAINT, res.EmailSuppressions[0].Reason)
+
+	res, err = u.Store.ListEmailSuppressions(ctx, &backendv1.ListEmailSuppressionsRequest{EmailAddress: "Jane@Example.com"})
+	require.NoError(t, err)
+	require.Len(t, res.EmailSuppressions, 1)
+	require.Equal(t, bounceID, res.EmailSuppressions[0].Id)
+	require.Equal(t, backendv1.EmailSuppressionReason_EMAIL_SUPPRESSION_REASON_BOUNCE, res.EmailSuppressions[0].Reason)
+}
+
+func TestDeleteEmailSuppression(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	emailSuppressionID := u.newEmailSuppression(t, "jane@example.com", "bounce")
+
+	_, err := u.Store.DeleteEmailSuppression(ctx, &backendv1.DeleteEmailSuppressionRequest{Id: emailSuppressionID})
+	require.NoError(t, err)
+
+	res, err := u.Store.ListEmailSuppressions(ctx, &backendv1.ListEmailSuppressionsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, res.EmailSuppressions)
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+
+	var deleteEvents int
+	err = u.Environment.DB.QueryRow(t.Context(), `
+SELECT count(*) FROM audit_log_events WHERE event_name = 'tesseral.email_suppressions.delete' AND project_id = $1::uuid;
+`, uuid.UUID(projectID).String()).Scan(&deleteEvents)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleteEvents)
+
+	_, err = u.Store.DeleteEmailSuppression(ctx, &backendv1.DeleteEmailSuppressionRequest{Id: emailSuppressionID})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
@@ -0,0 +1,362 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/emailtemplate"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListEmailTemplates(ctx context.Context, req *backendv1.ListEmailTemplatesRequest) (*backendv1.ListEmailTemplatesResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qEmailTemplates, err := q.ListEmailTemplates(ctx, queries.ListEmailTemplatesParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        startID,
+		Limit:     int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list email templates: %w", err)
+	}
+
+	var emailTemplates []*backendv1.EmailTemplate
+	for _, qEmailTemplate := range qEmailTemplates {
+		emailTemplates = append(emailTemplates, parseEmailTemplate(qEmailTemplate))
+	}
+
+	var nextPageToken string
+	if len(emailTemplates) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qEmailTemplates[limit].ID)
+		emailTemplates = emailTemplates[:limit]
+	}
+
+	return &backendv1.ListEmailTemplatesResponse{
+		EmailTemplates: emailTemplates,
+		NextPageToken:  nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetEmailTemplate(ctx context.Context, req *backendv1.GetEmailTemplateRequest) (*backendv1.GetEmailTemplateResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qEmailTemplate, err := s.getEmailTemplate(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backendv1.GetEmailTemplateResponse{EmailTemplate: parseEmailTemplate(*qEmailTemplate)}, nil
+}
+
+func (s *Store) CreateEmailTemplate(ctx context.Context, req *backendv1.CreateEmailTemplateRequest) (*backendv1.CreateEmailTemplateResponse, error) {
+	if req.EmailTemplate == nil {
+		return nil, apierror.NewInvalidArgumentError("email_template is required", fmt.Errorf("email template is nil"))
+	}
+
+	templateType, err := parseEmailTemplateType(req.EmailTemplate.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	locale, err := emailtemplate.ParseLocale(req.EmailTemplate.Locale)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("locale must be a valid BCP 47 language tag", fmt.Errorf("parse locale: %w", err))
+	}
+
+	tmpl := &emailtemplate.Template{
+		Subject:  req.EmailTemplate.Subject,
+		TextBody: req.EmailTemplate.TextBody,
+		HTMLBody: req.EmailTemplate.HtmlBody,
+	}
+	if err := validateEmailTemplate(tmpl); err != nil {
+		return nil, err
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qEmailTemplate, err := q.CreateEmailTemplate(ctx, queries.CreateEmailTemplateParams{
+		ID:        uuid.New(),
+		ProjectID: authn.ProjectID(ctx),
+		Type:      templateType,
+		Locale:    locale,
+		Subject:   tmpl.Subject,
+		TextBody:  tmpl.TextBody,
+		HtmlBody:  tmpl.HTMLBody,
+	})
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) {
+			if pgxErr.Code == "23505" && pgxErr.ConstraintName == "email_templates_project_id_type_locale_key" {
+				return nil, apierror.NewAlreadyExistsError("an email template for that type and locale already exists", fmt.Errorf("create email template: %w", err))
+			}
+		}
+
+		return nil, fmt.Errorf("create email template: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.CreateEmailTemplateResponse{EmailTemplate: parseEmailTemplate(qEmailTemplate)}, nil
+}
+
+func (s *Store) UpdateEmailTemplate(ctx context.Context, req *backendv1.UpdateEmailTemplateRequest) (*backendv1.UpdateEmailTemplateResponse, error) {
+	if req.EmailTemplate == nil {
+		return nil, apierror.NewInvalidArgumentError("email_template is required", fmt.Errorf("email template is nil"))
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qEmailTemplate, err := s.getEmailTemplate(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := queries.UpdateEmailTemplateParams{
+		ID:       qEmailTemplate.ID,
+		Subject:  qEmailTemplate.Subject,
+		TextBody: qEmailTemplate.TextBody,
+		HtmlBody: qEmailTemplate.HtmlBody,
+	}
+
+	if req.EmailTemplate.Subject != "" {
+		updates.Subject = req.EmailTemplate.Subject
+	}
+
+	if req.EmailTemplate.TextBody != "" {
+		updates.TextBody = req.EmailTemplate.TextBody
+	}
+
+	if req.EmailTemplate.HtmlBody != "" {
+		updates.HtmlBody = req.EmailTemplate.HtmlBody
+	}
+
+	if err := validateEmailTemplate(&emailtemplate.Template{
+		Subject:  updates.Subject,
+		TextBody: updates.TextBody,
+		HTMLBody: updates.HtmlBody,
+	}); err != nil {
+		return nil, err
+	}
+
+	qUpdatedEmailTemplate, err := q.UpdateEmailTemplate(ctx, updates)
+	if err != nil {
+		return nil, fmt.Errorf("update email template: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateEmailTemplateResponse{EmailTemplate: parseEmailTemplate(qUpdatedEmailTemplate)}, nil
+}
+
+func (s *Store) DeleteEmailTemplate(ctx context.Context, req *backendv1.DeleteEmailTemplateRequest) (*backendv1.DeleteEmailTemplateResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qEmailTemplate, err := s.getEmailTemplate(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.DeleteEmailTemplate(ctx, qEmailTemplate.ID); err != nil {
+		return nil, fmt.Errorf("delete email template: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteEmailTemplateResponse{}, nil
+}
+
+func (s *Store) PreviewEmailTemplate(ctx context.Context, req *backendv1.PreviewEmailTemplateRequest) (*backendv1.PreviewEmailTemplateResponse, error) {
+	templateType, err := parseEmailTemplateType(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := emailtemplate.DefaultLocale
+	if req.Locale != "" {
+		locale, err = emailtemplate.ParseLocale(req.Locale)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("locale must be a valid BCP 47 language tag", fmt.Errorf("parse locale: %w", err))
+		}
+	}
+
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	qProjectUISettings, err := q.GetProjectUISettings(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project ui settings: %w", err)
+	}
+
+	var tmpl *emailtemplate.Template
+	if req.Subject != "" || req.TextBody != "" || req.HtmlBody != "" {
+		tmpl = &emailtemplate.Template{
+			Subject:  req.Subject,
+			TextBody: req.TextBody,
+			HTMLBody: req.HtmlBody,
+		}
+	} else {
+		qEmailTemplates, err := q.ListEmailTemplatesByType(ctx, queries.ListEmailTemplatesByTypeParams{
+			ProjectID: authn.ProjectID(ctx),
+			Type:      templateType,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list email templates by type: %w", err)
+		}
+
+		overrides := map[string]*emailtemplate.Template{}
+		for _, qEmailTemplate := range qEmailTemplates {
+			overrides[qEmailTemplate.Locale] = &emailtemplate.Template{
+				Subject:  qEmailTemplate.Subject,
+				TextBody: qEmailTemplate.TextBody,
+				HTMLBody: qEmailTemplate.HtmlBody,
+			}
+		}
+
+		tmpl, locale = emailtemplate.Resolve(emailtemplate.Type(templateType), overrides, locale)
+	}
+
+	email, err := emailtemplate.Render(tmpl, emailtemplate.SampleData(qProject.DisplayName, derefOrEmpty(qProjectUISettings.LogoUrl), derefOrEmpty(qProjectUISettings.PrimaryColor)))
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid email template: %s", err), fmt.Errorf("render email template: %w", err))
+	}
+
+	return &backendv1.PreviewEmailTemplateResponse{
+		Subject:  email.Subject,
+		TextBody: email.TextBody,
+		HtmlBody: email.HTMLBody,
+		Locale:   locale,
+	}, nil
+}
+
+func (s *Store) getEmailTemplate(ctx context.Context, q *queries.Queries, id string) (*queries.EmailTemplate, error) {
+	emailTemplateID, err := idformat.EmailTemplate.Parse(id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid email template id", fmt.Errorf("parse email template id: %w", err))
+	}
+
+	qEmailTemplate, err := q.GetEmailTemplate(ctx, queries.GetEmailTemplateParams{
+		ID:        emailTemplateID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("email template not found", fmt.Errorf("get email template: %w", err))
+		}
+
+		return nil, fmt.Errorf("get email template: %w", err)
+	}
+
+	return &qEmailTemplate, nil
+}
+
+func validateEmailTemplate(tmpl *emailtemplate.Template) error {
+	if tmpl.Subject == "" || tmpl.TextBody == "" || tmpl.HTMLBody == "" {
+		return apierror.NewInvalidArgumentError("subject, text_body, and html_body are required", fmt.Errorf("email template is missing subject, text body, or html body"))
+	}
+
+	if err := emailtemplate.Validate(tmpl); err != nil {
+		return apierror.NewInvalidArgumentError(fmt.Sprintf("invalid email template: %s", err), fmt.Errorf("validate email template: %w", err))
+	}
+
+	return nil
+}
+
+// parseOptionalLocale validates a User or Organization locale. An empty locale
+// clears it.
+func parseOptionalLocale(locale *string) (*string, error) {
+	if locale == nil || *locale == "" {
+		return nil, nil
+	}
+
+	parsed, err := emailtemplate.ParseLocale(*locale)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("locale must be a valid BCP 47 language tag", fmt.Errorf("parse locale: %w", err))
+	}
+
+	return &parsed, nil
+}
+
+func parseEmailTemplateType(templateType backendv1.EmailTemplateType) (queries.EmailTemplateType, error) {
+	switch templateType {
+	case backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_VERIFY_EMAIL:
+		return queries.EmailTemplateTypeVerifyEmail, nil
+	case backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_PASSWORD_RESET:
+		return queries.EmailTemplateTypePasswordReset, nil
+	case backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_USER_INVITE:
+		return queries.EmailTemplateTypeUserInvite, nil
+	default:
+		return "", apierror.NewInvalidArgumentError("type is required", fmt.Errorf("invalid email template type: %v", templateType))
+	}
+}
+
+func parseEmailTemplate(qEmailTemplate queries.EmailTemplate) *backendv1.EmailTemplate {
+	var templateType backendv1.EmailTemplateType
+	switch qEmailTemplate.Type {
+	case queries.EmailTemplateTypeVerifyEmail:
+		templateType = backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_VERIFY_EMAIL
+	case queries.EmailTemplateTypePasswordReset:
+		templateType = backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_PASSWORD_RESET
+	case queries.EmailTemplateTypeUserInvite:
+		templateType = backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_USER_INVITE
+	}
+
+	return &backendv1.EmailTemplate{
+		Id:         idformat.EmailTemplate.Format(qEmailTemplate.ID),
+		Type:       templateType,
+		Locale:     qEmailTemplate.Locale,
+		Subject:    qEmailTemplate.Subject,
+		TextBody:   qEmailTemplate.TextBody,
+		HtmlBody:   qEmailTemplate.HtmlBody,
+		CreateTime: timestamppb.New(*qEmailTemplate.CreateTime),
+		UpdateTime: timestamppb.New(*qEmailTemplate.UpdateTime),
+	}
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func TestCreateEmailTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_USER_INVITE,
+			Locale:   "pt-br",
+			Subject:  "Junte-se a {{ .OrganizationDisplayName }}",
+			TextBody: "{{ .SignupLink }}",
+			HtmlBody: `<a href="{{ .SignupLink }}">Cadastre-se</a>`,
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.EmailTemplate.Id)
+	require.Equal(t, backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_USER_INVITE, res.EmailTemplate.Type)
+	require.Equal(t, "pt-BR", res.EmailTemplate.Locale)
+
+	_, err = u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_USER_INVITE,
+			Locale:   "pt-BR",
+			Subject:  "subject",
+			TextBody: "text",
+			HtmlBody: "html",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeAlreadyExists, connectErr.Code())
+}
+
+func TestCreateEmailTemplate_InvalidTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_VERIFY_EMAIL,
+			Locale:   "en",
+			Subject:  "subject",
+			TextBody: "{{ .NoSuchVariable }}",
+			HtmlBody: "html",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestUpdateEmailTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_PASSWORD_RESET,
+			Locale:   "en",
+			Subject:  "subject",
+			TextBody: "{{ .PasswordResetCode }}",
+			HtmlBody: "<p>{{ .PasswordResetCode }}</p>",
+		},
+	})
+	require.NoError(t, err)
+
+	updateRes, err := u.Store.UpdateEmailTemplate(ctx, &backendv1.UpdateEmailTemplateRequest{
+		Id: createRes.EmailTemplate.Id,
+		EmailTemplate: &backendv1.EmailTemplate{
+			Subject: "Reset your {{ .ProjectDisplayName }} password",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Reset your {{ .ProjectDisplayName }} password", updateRes.EmailTemplate.Subject)
+	require.Equal(t, "{{ .PasswordResetCode }}", updateRes.EmailTemplate.TextBody)
+}
+
+func TestDeleteEmailTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_VERIFY_EMAIL,
+			Locale:   "en",
+			Subject:  "subject",
+			TextBody: "text",
+			HtmlBody: "html",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.DeleteEmailTemplate(ctx, &backendv1.DeleteEmailTemplateRequest{Id: createRes.EmailTemplate.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.GetEmailTemplate(ctx, &backendv1.GetEmailTemplateRequest{Id: createRes.EmailTemplate.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+func TestPreviewEmailTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	// no override; falls back to the built-in template for the base language
+	res, err := u.Store.PreviewEmailTemplate(ctx, &backendv1.PreviewEmailTemplateRequest{
+		Type:   backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_VERIFY_EMAIL,
+		Locale: "de-AT",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "de", res.Locale)
+	require.Contains(t, res.Subject, "Bestätigen Sie Ihre E-Mail-Adresse")
+	require.Contains(t, res.HtmlBody, "<html>")
+
+	_, err = u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_VERIFY_EMAIL,
+			Locale:   "de-AT",
+			Subject:  "Servus",
+			TextBody: "{{ .EmailVerificationLink }}",
+			HtmlBody: "<p>Servus</p>",
+		},
+	})
+	require.NoError(t, err)
+
+	res, err = u.Store.PreviewEmailTemplate(ctx, &backendv1.PreviewEmailTemplateRequest{
+		Type:   backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_VERIFY_EMAIL,
+		Locale: "de-AT",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "de-AT", res.Locale)
+	require.Equal(t, "Servus", res.Subject)
+
+	// drafts are previewed without being saved
+	res, err = u.Store.PreviewEmailTemplate(ctx, &backendv1.PreviewEmailTemplateRequest{
+		Type:     backendv1.EmailTemplateType_EMAIL_TEMPLATE_TYPE_VERIFY_EMAIL,
+		Subject:  "Draft for {{ .ProjectDisplayName }}",
+		TextBody: "text",
+		HtmlBody: "html",
+	})
+	require.NoError(t, err)
+	require.Contains(t, res.Subject, "Draft for ")
+}
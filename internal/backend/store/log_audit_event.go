@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"github.com/tesseral-labs/tesseral/internal/uuidv7"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -23,7 +25,9 @@ type logAuditEventParams struct {
 	ResourceID   *uuid.UUID
 }
 
-func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, data logAuditEventParams) (queries.AuditLogEvent, error) {
+func (s *Store) logAuditEvent(ctx context.Context, tx pgx.Tx, data logAuditEventParams) (queries.AuditLogEvent, error) {
+	q := queries.New(tx)
+
 	// Generate the UUIDv7 based on the event time.
 	eventTime := time.Now()
 	eventID := uuidv7.NewWithTime(eventTime)
@@ -71,5 +75,16 @@ func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, data logA
 		return queries.AuditLogEvent{}, err
 	}
 
+	// Resource changes are also sent as typed webhook events.
+	if err := backgroundworkerstore.InsertTypedEventTx(ctx, tx, s.riverClient, backgroundworkerstore.NewTypedEventArgsParams{
+		ProjectID:         qEvent.ProjectID,
+		AuditLogEventID:   qEvent.ID,
+		AuditLogEventName: qEvent.EventName,
+		EventTime:         eventTime,
+		EventDetails:      data.EventDetails,
+	}); err != nil {
+		return queries.AuditLogEvent{}, fmt.Errorf("insert typed event: %w", err)
+	}
+
 	return qEvent, nil
 }
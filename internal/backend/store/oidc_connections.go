@@ -199,7 +199,7 @@ func (s *Store) CreateOIDCConnection(ctx context.Context, req *backendv1.CreateO
 		return nil, fmt.Errorf("get oidc connection for audit log: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.oidc_connections.create",
 		EventDetails: &auditlogv1.CreateOIDCConnection{
 			OidcConnection: auditOIDCConnection,
@@ -309,7 +309,7 @@ func (s *Store) UpdateOIDCConnection(ctx context.Context, req *backendv1.UpdateO
 		return nil, fmt.Errorf("get audit oidc connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.oidc_connections.update",
 		EventDetails: &auditlogv1.UpdateOIDCConnection{
 			OidcConnection:         auditOIDCConnection,
@@ -362,7 +362,7 @@ func (s *Store) DeleteOIDCConnection(ctx context.Context, req *backendv1.DeleteO
 		return nil, fmt.Errorf("delete oidc connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.oidc_connections.delete",
 		EventDetails: &auditlogv1.DeleteOIDCConnection{
 			OidcConnection: auditOIDCConnection,
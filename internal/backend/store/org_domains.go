@@ -47,7 +47,7 @@ func (s *Store) GetOrganizationDomains(ctx context.Context, req *backendv1.GetOr
 }
 
 func (s *Store) UpdateOrganizationDomains(ctx context.Context, req *backendv1.UpdateOrganizationDomainsRequest) (*backendv1.UpdateOrganizationDomainsResponse, error) {
-	_, q, commit, rollback, err := s.tx(ctx)
+	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +97,7 @@ func (s *Store) UpdateOrganizationDomains(ctx context.Context, req *backendv1.Up
 	}
 
 	organizationDomains := parseOrganizationDomains(qOrg, qDomains)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.organizations.update_domains",
 		EventDetails: &auditlogv1.UpdateOrganizationDomains{
 			Domains:         organizationDomains.Domains,
@@ -47,7 +47,7 @@ func (s *Store) GetOrganizationGoogleHostedDomains(ctx context.Context, req *bac
 }
 
 func (s *Store) UpdateOrganizationGoogleHostedDomains(ctx context.Context, req *backendv1.UpdateOrganizationGoogleHostedDomainsRequest) (*backendv1.UpdateOrganizationGoogleHostedDomainsResponse, error) {
-	_, q, commit, rollback, err := s.tx(ctx)
+	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +97,7 @@ func (s *Store) UpdateOrganizationGoogleHostedDomains(ctx context.Context, req *
 	}
 
 	googleHostedDomains := parseOrganizationGoogleHostedDomains(qOrg, qGoogleHostedDomains)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.organizations.update_google_hosted_domains",
 		EventDetails: &auditlogv1.UpdateOrganizationGoogleHostedDomains{
 			GoogleHostedDomains:         googleHostedDomains.GoogleHostedDomains,
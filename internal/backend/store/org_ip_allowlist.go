@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func (s *Store) GetOrganizationIPAllowlist(ctx context.Context, req *backendv1.GetOrganizationIPAllowlistRequest) (*backendv1.GetOrganizationIPAllowlistResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	qCIDRs, err := q.GetOrganizationIPAllowlistCIDRs(ctx, queries.GetOrganizationIPAllowlistCIDRsParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	return &backendv1.GetOrganizationIPAllowlistResponse{
+		OrganizationIpAllowlist: parseOrganizationIPAllowlist(qOrg, qCIDRs),
+	}, nil
+}
+
+func (s *Store) UpdateOrganizationIPAllowlist(ctx context.Context, req *backendv1.UpdateOrganizationIPAllowlistRequest) (*backendv1.UpdateOrganizationIPAllowlistResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	qPreviousCIDRs, err := q.GetOrganizationIPAllowlistCIDRs(ctx, queries.GetOrganizationIPAllowlistCIDRsParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	if err := q.DeleteOrganizationIPAllowlistCIDRs(ctx, orgID); err != nil {
+		return nil, fmt.Errorf("delete organization ip allowlist cidrs: %w", err)
+	}
+
+	var prefixes []netip.Prefix
+	for _, cidr := range req.OrganizationIpAllowlist.Cidrs {
+		prefix, err := clientip.ParseCIDR(cidr)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid cidr: %q", cidr), fmt.Errorf("parse cidr: %w", err))
+		}
+
+		if slices.Contains(prefixes, prefix) {
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	for _, prefix := range prefixes {
+		if _, err := q.CreateOrganizationIPAllowlistCIDR(ctx, queries.CreateOrganizationIPAllowlistCIDRParams{
+			ID:             uuid.New(),
+			OrganizationID: orgID,
+			Cidr:           prefix,
+		}); err != nil {
+			return nil, fmt.Errorf("create organization ip allowlist cidr: %w", err)
+		}
+	}
+
+	qCIDRs, err := q.GetOrganizationIPAllowlistCIDRs(ctx, queries.GetOrganizationIPAllowlistCIDRsParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	organizationIPAllowlist := parseOrganizationIPAllowlist(qOrg, qCIDRs)
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.organizations.update_ip_allowlist",
+		EventDetails: &auditlogv1.UpdateOrganizationIPAllowlist{
+			Cidrs:         organizationIPAllowlist.Cidrs,
+			PreviousCidrs: parseOrganizationIPAllowlist(qOrg, qPreviousCIDRs).Cidrs,
+		},
+		OrganizationID: &qOrg.ID,
+		ResourceType:   queries.AuditLogEventResourceTypeOrganization,
+		ResourceID:     &qOrg.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateOrganizationIPAllowlistResponse{
+		OrganizationIpAllowlist: organizationIPAllowlist,
+	}, nil
+}
+
+func parseOrganizationIPAllowlist(qOrg queries.Organization, qCIDRs []queries.OrganizationIpAllowlistCidr) *backendv1.OrganizationIPAllowlist {
+	var cidrs []string
+	for _, qCIDR := range qCIDRs {
+		cidrs = append(cidrs, qCIDR.Cidr.String())
+	}
+	return &backendv1.OrganizationIPAllowlist{
+		OrganizationId: idformat.Organization.Format(qOrg.ID),
+		Cidrs:          cidrs,
+	}
+}
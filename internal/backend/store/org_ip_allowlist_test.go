@@ -0,0 +1,95 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func TestGetOrganizationIPAllowlist_Empty(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	resp, err := u.Store.GetOrganizationIPAllowlist(ctx, &backendv1.GetOrganizationIPAllowlistRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.OrganizationIpAllowlist)
+	require.Equal(t, orgID, resp.OrganizationIpAllowlist.OrganizationId)
+	require.Empty(t, resp.OrganizationIpAllowlist.Cidrs)
+}
+
+func TestUpdateOrganizationIPAllowlist_AddAndGet(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	updateResp, err := u.Store.UpdateOrganizationIPAllowlist(ctx, &backendv1.UpdateOrganizationIPAllowlistRequest{
+		OrganizationId: orgID,
+		OrganizationIpAllowlist: &backendv1.OrganizationIPAllowlist{
+			Cidrs: []string{"192.0.2.0/24", "2001:db8::1", "192.0.2.0/24"},
+		},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"192.0.2.0/24", "2001:db8::1/128"}, updateResp.OrganizationIpAllowlist.Cidrs)
+
+	getResp, err := u.Store.GetOrganizationIPAllowlist(ctx, &backendv1.GetOrganizationIPAllowlistRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"192.0.2.0/24", "2001:db8::1/128"}, getResp.OrganizationIpAllowlist.Cidrs)
+}
+
+func TestUpdateOrganizationIPAllowlist_Clear(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	_, err := u.Store.UpdateOrganizationIPAllowlist(ctx, &backendv1.UpdateOrganizationIPAllowlistRequest{
+		OrganizationId: orgID,
+		OrganizationIpAllowlist: &backendv1.OrganizationIPAllowlist{
+			Cidrs: []string{"192.0.2.0/24"},
+		},
+	})
+	require.NoError(t, err)
+
+	updateResp, err := u.Store.UpdateOrganizationIPAllowlist(ctx, &backendv1.UpdateOrganizationIPAllowlistRequest{
+		OrganizationId:          orgID,
+		OrganizationIpAllowlist: &backendv1.OrganizationIPAllowlist{},
+	})
+	require.NoError(t, err)
+	require.Empty(t, updateResp.OrganizationIpAllowlist.Cidrs)
+}
+
+func TestUpdateOrganizationIPAllowlist_InvalidCIDR(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	for _, cidr := range []string{"not-an-ip", "192.0.2.1/24", "192.0.2.0/33"} {
+		_, err := u.Store.UpdateOrganizationIPAllowlist(ctx, &backendv1.UpdateOrganizationIPAllowlistRequest{
+			OrganizationId: orgID,
+			OrganizationIpAllowlist: &backendv1.OrganizationIPAllowlist{
+				Cidrs: []string{cidr},
+			},
+		})
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+	}
+}
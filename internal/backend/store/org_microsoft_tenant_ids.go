@@ -47,7 +47,7 @@ func (s *Store) GetOrganizationMicrosoftTenantIDs(ctx context.Context, req *back
 }
 
 func (s *Store) UpdateOrganizationMicrosoftTenantIDs(ctx context.Context, req *backendv1.UpdateOrganizationMicrosoftTenantIDsRequest) (*backendv1.UpdateOrganizationMicrosoftTenantIDsResponse, error) {
-	_, q, commit, rollback, err := s.tx(ctx)
+	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +97,7 @@ func (s *Store) UpdateOrganizationMicrosoftTenantIDs(ctx context.Context, req *b
 	}
 
 	microsoftTenantIDs := parseOrganizationMicrosoftTenantIDs(qOrg, qMicrosoftTenantIDs)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.organizations.update_microsoft_tenant_ids",
 		EventDetails: &auditlogv1.UpdateOrganizationMicrosoftTenantIDs{
 			MicrosoftTenantIds:         microsoftTenantIDs.MicrosoftTenantIds,
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func (s *Store) GetOrganizationPasskeyPolicy(ctx context.Context, req *backendv1.GetOrganizationPasskeyPolicyRequest) (*backendv1.GetOrganizationPasskeyPolicyResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	qAAGUIDs, err := q.GetOrganizationPasskeyAAGUIDs(ctx, queries.GetOrganizationPasskeyAAGUIDsParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization passkey aaguids: %w", err)
+	}
+
+	return &backendv1.GetOrganizationPasskeyPolicyResponse{
+		OrganizationPasskeyPolicy: parseOrganizationPasskeyPolicy(qOrg, qAAGUIDs),
+	}, nil
+}
+
+func (s *Store) UpdateOrganizationPasskeyPolicy(ctx context.Context, req *backendv1.UpdateOrganizationPasskeyPolicyRequest) (*backendv1.UpdateOrganizationPasskeyPolicyResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	qPreviousOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	qPreviousAAGUIDs, err := q.GetOrganizationPasskeyAAGUIDs(ctx, queries.GetOrganizationPasskeyAAGUIDsParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization passkey aaguids: %w", err)
+	}
+
+	aaguids, err := validatePasskeyPolicyAAGUIDs(req.OrganizationPasskeyPolicy.AllowedAaguids, req.OrganizationPasskeyPolicy.DeniedAaguids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validatePasskeyPolicyAttestation(req.OrganizationPasskeyPolicy.RequireAttestation, req.OrganizationPasskeyPolicy.AllowedAaguids); err != nil {
+		return nil, err
+	}
+
+	qOrg, err := q.UpdateOrganizationPasskeyPolicy(ctx, queries.UpdateOrganizationPasskeyPolicyParams{
+		ID:                             orgID,
+		PasskeyRequireAttestation:      req.OrganizationPasskeyPolicy.RequireAttestation,
+		PasskeyRequireUserVerification: req.OrganizationPasskeyPolicy.RequireUserVerification,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update organization passkey policy: %w", err)
+	}
+
+	if err := q.DeleteOrganizationPasskeyAAGUIDs(ctx, orgID); err != nil {
+		return nil, fmt.Errorf("delete organization passkey aaguids: %w", err)
+	}
+
+	for aaguid, allowed := range aaguids {
+		if _, err := q.CreateOrganizationPasskeyAAGUID(ctx, queries.CreateOrganizationPasskeyAAGUIDParams{
+			ID:             uuid.New(),
+			OrganizationID: orgID,
+			Aaguid:         aaguid,
+			Allowed:        allowed,
+		}); err != nil {
+			return nil, fmt.Errorf("create organization passkey aaguid: %w", err)
+		}
+	}
+
+	qAAGUIDs, err := q.GetOrganizationPasskeyAAGUIDs(ctx, queries.GetOrganizationPasskeyAAGUIDsParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization passkey aaguids: %w", err)
+	}
+
+	passkeyPolicy := parseOrganizationPasskeyPolicy(qOrg, qAAGUIDs)
+	previousPasskeyPolicy := parseOrganizationPasskeyPolicy(qPreviousOrg, qPreviousAAGUIDs)
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.organizations.update_passkey_policy",
+		EventDetails: &auditlogv1.UpdateOrganizationPasskeyPolicy{
+			PasskeyPolicy: &auditlogv1.OrganizationPasskeyPolicy{
+				RequireAttestation:      passkeyPolicy.RequireAttestation,
+				AllowedAaguids:          passkeyPolicy.AllowedAaguids,
+				DeniedAaguids:           passkeyPolicy.DeniedAaguids,
+				RequireUserVerification: passkeyPolicy.RequireUserVerification,
+			},
+			PreviousPasskeyPolicy: &auditlogv1.OrganizationPasskeyPolicy{
+				RequireAttestation:      previousPasskeyPolicy.RequireAttestation,
+				AllowedAaguids:          previousPasskeyPolicy.AllowedAaguids,
+				DeniedAaguids:           previousPasskeyPolicy.DeniedAaguids,
+				RequireUserVerification: previousPasskeyPolicy.RequireUserVerification,
+			},
+		},
+		OrganizationID: &qOrg.ID,
+		ResourceType:   queries.AuditLogEventResourceTypeOrganization,
+		ResourceID:     &qOrg.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateOrganizationPasskeyPolicyResponse{
+		OrganizationPasskeyPolicy: passkeyPolicy,
+	}, nil
+}
+
+// validatePasskeyPolicyAAGUIDs parses allowed and denied AAGUIDs into a map
+// from AAGUID to whether it is allowed.
+func validatePasskeyPolicyAAGUIDs(allowed, denied []string) (map[uuid.UUID]bool, error) {
+	aaguids := map[uuid.UUID]bool{}
+	for _, s := range allowed {
+		aaguid, err := uuid.Parse(s)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid aaguid: %q", s), fmt.Errorf("parse aaguid: %w", err))
+		}
+		aaguids[aaguid] = true
+	}
+
+	for _, s := range denied {
+		aaguid, err := uuid.Parse(s)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid aaguid: %q", s), fmt.Errorf("parse aaguid: %w", err))
+		}
+
+		if aaguids[aaguid] {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("aaguid is both allowed and denied: %q", s), fmt.Errorf("aaguid both allowed and denied"))
+		}
+		aaguids[aaguid] = false
+	}
+
+	return aaguids, nil
+}
+
+// validatePasskeyPolicyAttestation rejects passkey policies that require
+// trusted attestations when no FIDO metadata is configured to trust
+// attestations against, because no passkey could then be registered.
+func (s *Store) validatePasskeyPolicyAttestation(requireAttestation bool, allowed []string) error {
+	if !requireAttestation && len(allowed) == 0 {
+		return nil
+	}
+
+	if s.fidoMetadata.Empty() {
+		return apierror.NewFailedPreconditionError("passkey attestation cannot be required, or aaguids allowed, because no fido metadata is configured", fmt.Errorf("fido metadata is empty"))
+	}
+
+	return nil
+}
+
+func parseOrganizationPasskeyPolicy(qOrg queries.Organization, qAAGUIDs []queries.OrganizationPasskeyAaguid) *backendv1.OrganizationPasskeyPolicy {
+	var allowed, denied []string
+	for _, qAAGUID := range qAAGUIDs {
+		if qAAGUID.Allowed {
+			allowed = append(allowed, qAAGUID.Aaguid.String())
+		} else {
+			denied = append(denied, qAAGUID.Aaguid.String())
+		}
+	}
+	return &backendv1.OrganizationPasskeyPolicy{
+		OrganizationId:          idformat.Organization.Format(qOrg.ID),
+		RequireAttestation:      qOrg.PasskeyRequireAttestation,
+		AllowedAaguids:          allowed,
+		DeniedAaguids:           denied,
+		RequireUserVerification: qOrg.PasskeyRequireUserVerification,
+	}
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/webauthn"
+)
+
+func TestGetOrganizationPasskeyPolicy_Empty(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	resp, err := u.Store.GetOrganizationPasskeyPolicy(ctx, &backendv1.GetOrganizationPasskeyPolicyRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, orgID, resp.OrganizationPasskeyPolicy.OrganizationId)
+	require.False(t, resp.OrganizationPasskeyPolicy.RequireAttestation)
+	require.Empty(t, resp.OrganizationPasskeyPolicy.AllowedAaguids)
+	require.Empty(t, resp.OrganizationPasskeyPolicy.DeniedAaguids)
+}
+
+func TestUpdateOrganizationPasskeyPolicy_AddAndGet(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	allowed := []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8", "ee882879-721c-4913-9775-3dfcce97072a"}
+	denied := []string{"ea9b8d66-4d01-1d21-3ce4-b6b48cb575d4"}
+	updateResp, err := u.Store.UpdateOrganizationPasskeyPolicy(ctx, &backendv1.UpdateOrganizationPasskeyPolicyRequest{
+		OrganizationId: orgID,
+		OrganizationPasskeyPolicy: &backendv1.OrganizationPasskeyPolicy{
+			RequireAttestation: true,
+			AllowedAaguids:     allowed,
+			DeniedAaguids:      denied,
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, updateResp.OrganizationPasskeyPolicy.RequireAttestation)
+	require.ElementsMatch(t, allowed, updateResp.OrganizationPasskeyPolicy.AllowedAaguids)
+	require.ElementsMatch(t, denied, updateResp.OrganizationPasskeyPolicy.DeniedAaguids)
+
+	getResp, err := u.Store.GetOrganizationPasskeyPolicy(ctx, &backendv1.GetOrganizationPasskeyPolicyRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+	require.True(t, getResp.OrganizationPasskeyPolicy.RequireAttestation)
+	require.ElementsMatch(t, allowed, getResp.OrganizationPasskeyPolicy.AllowedAaguids)
+	require.ElementsMatch(t, denied, getResp.OrganizationPasskeyPolicy.DeniedAaguids)
+}
+
+func TestUpdateOrganizationPasskeyPolicy_RequireUserVerification(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	updateResp, err := u.Store.UpdateOrganizationPasskeyPolicy(ctx, &backendv1.UpdateOrganizationPasskeyPolicyRequest{
+		OrganizationId: orgID,
+		OrganizationPasskeyPolicy: &backendv1.OrganizationPasskeyPolicy{
+			RequireUserVerification: true,
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, updateResp.OrganizationPasskeyPolicy.RequireUserVerification)
+	require.False(t, updateResp.OrganizationPasskeyPolicy.RequireAttestation)
+
+	getResp, err := u.Store.GetOrganizationPasskeyPolicy(ctx, &backendv1.GetOrganizationPasskeyPolicyRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+	require.True(t, getResp.OrganizationPasskeyPolicy.RequireUserVerification)
+}
+
+func TestUpdateOrganizationPasskeyPolicy_Invalid(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	for _, policy := range []*backendv1.OrganizationPasskeyPolicy{
+		{AllowedAaguids: []string{"not-a-uuid"}},
+		{DeniedAaguids: []string{"not-a-uuid"}},
+		{
+			AllowedAaguids: []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"},
+			DeniedAaguids:  []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"},
+		},
+	} {
+		_, err := u.Store.UpdateOrganizationPasskeyPolicy(ctx, &backendv1.UpdateOrganizationPasskeyPolicyRequest{
+			OrganizationId:            orgID,
+			OrganizationPasskeyPolicy: policy,
+		})
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+	}
+}
+
+func TestUpdateOrganizationPasskeyPolicy_AttestationWithoutFIDOMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	u.Store.fidoMetadata = &webauthn.Metadata{}
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	for _, policy := range []*backendv1.OrganizationPasskeyPolicy{
+		{RequireAttestation: true},
+		{AllowedAaguids: []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"}},
+	} {
+		_, err := u.Store.UpdateOrganizationPasskeyPolicy(ctx, &backendv1.UpdateOrganizationPasskeyPolicyRequest{
+			OrganizationId:            orgID,
+			OrganizationPasskeyPolicy: policy,
+		})
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+	}
+}
@@ -12,7 +12,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
-	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -78,25 +78,40 @@ func (s *Store) CreateOrganization(ctx context.Context, req *backendv1.CreateOrg
 		return nil, apierror.NewPermissionDeniedError("log in with passkey is not enabled for this project", fmt.Errorf("log in with passkey is not enabled for this project"))
 	}
 
+	if derefOrEmpty(req.Organization.LogInWithPasswordlessPasskey) && !qProject.LogInWithPasswordlessPasskey {
+		return nil, apierror.NewPermissionDeniedError("log in with passwordless passkey is not enabled for this project", fmt.Errorf("log in with passwordless passkey is not enabled for this project"))
+	}
+
+	if derefOrEmpty(req.Organization.LogInWithPasswordlessPasskey) && !derefOrEmpty(req.Organization.LogInWithPasskey) {
+		return nil, apierror.NewInvalidArgumentError("log in with passwordless passkey requires log in with passkey to be enabled", fmt.Errorf("log in with passwordless passkey requires log in with passkey to be enabled"))
+	}
+
 	var scimEnabled bool
 	if req.Organization.ScimEnabled != nil {
 		scimEnabled = *req.Organization.ScimEnabled
 	}
 
+	locale, err := parseOptionalLocale(req.Organization.Locale)
+	if err != nil {
+		return nil, err
+	}
+
 	qOrg, err := q.CreateOrganization(ctx, queries.CreateOrganizationParams{
-		ID:                        uuid.New(),
-		ProjectID:                 authn.ProjectID(ctx),
-		DisplayName:               req.Organization.DisplayName,
-		LogInWithGoogle:           derefOrEmpty(req.Organization.LogInWithGoogle),
-		LogInWithMicrosoft:        derefOrEmpty(req.Organization.LogInWithMicrosoft),
-		LogInWithGithub:           derefOrEmpty(req.Organization.LogInWithGithub),
-		LogInWithEmail:            derefOrEmpty(req.Organization.LogInWithEmail),
-		LogInWithPassword:         derefOrEmpty(req.Organization.LogInWithPassword),
-		LogInWithSaml:             derefOrEmpty(req.Organization.LogInWithSaml),
-		LogInWithOidc:             derefOrEmpty(req.Organization.LogInWithOidc),
-		LogInWithAuthenticatorApp: derefOrEmpty(req.Organization.LogInWithAuthenticatorApp),
-		LogInWithPasskey:          derefOrEmpty(req.Organization.LogInWithPasskey),
-		ScimEnabled:               scimEnabled,
+		ID:                           uuid.New(),
+		ProjectID:                    authn.ProjectID(ctx),
+		DisplayName:                  req.Organization.DisplayName,
+		LogInWithGoogle:              derefOrEmpty(req.Organization.LogInWithGoogle),
+		LogInWithMicrosoft:           derefOrEmpty(req.Organization.LogInWithMicrosoft),
+		LogInWithGithub:              derefOrEmpty(req.Organization.LogInWithGithub),
+		LogInWithEmail:               derefOrEmpty(req.Organization.LogInWithEmail),
+		LogInWithPassword:            derefOrEmpty(req.Organization.LogInWithPassword),
+		LogInWithSaml:                derefOrEmpty(req.Organization.LogInWithSaml),
+		LogInWithOidc:                derefOrEmpty(req.Organization.LogInWithOidc),
+		LogInWithAuthenticatorApp:    derefOrEmpty(req.Organization.LogInWithAuthenticatorApp),
+		LogInWithPasskey:             derefOrEmpty(req.Organization.LogInWithPasskey),
+		LogInWithPasswordlessPasskey: derefOrEmpty(req.Organization.LogInWithPasswordlessPasskey),
+		ScimEnabled:                  scimEnabled,
+		Locale:                       locale,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create organization: %w", err)
@@ -107,7 +122,7 @@ func (s *Store) CreateOrganization(ctx context.Context, req *backendv1.CreateOrg
 		return nil, fmt.Errorf("get audit organization: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.organizations.create",
 		EventDetails: &auditlogv1.CreateOrganization{
 			Organization: auditOrganization,
@@ -340,6 +355,24 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *backendv1.UpdateOrg
 		updates.LogInWithPasskey = *req.Organization.LogInWithPasskey
 	}
 
+	updates.LogInWithPasswordlessPasskey = qOrg.LogInWithPasswordlessPasskey
+	if req.Organization.LogInWithPasswordlessPasskey != nil {
+		if *req.Organization.LogInWithPasswordlessPasskey && !qProject.LogInWithPasswordlessPasskey {
+			return nil, apierror.NewPermissionDeniedError("log in with passwordless passkey is not enabled for this project", fmt.Errorf("log in with passwordless passkey is not enabled for this project"))
+		}
+
+		if *req.Organization.LogInWithPasswordlessPasskey && !updates.LogInWithPasskey {
+			return nil, apierror.NewInvalidArgumentError("log in with passwordless passkey requires log in with passkey to be enabled", fmt.Errorf("log in with passwordless passkey requires log in with passkey to be enabled"))
+		}
+
+		updates.LogInWithPasswordlessPasskey = *req.Organization.LogInWithPasswordlessPasskey
+	}
+
+	// passwordless passkey login is turned off along with passkeys
+	if !updates.LogInWithPasskey {
+		updates.LogInWithPasswordlessPasskey = false
+	}
+
 	updates.ScimEnabled = qOrg.ScimEnabled
 	if req.Organization.ScimEnabled != nil {
 		updates.ScimEnabled = *req.Organization.ScimEnabled
@@ -366,6 +399,14 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *backendv1.UpdateOrg
 		updates.ApiKeysEnabled = *req.Organization.ApiKeysEnabled
 	}
 
+	updates.Locale = qOrg.Locale
+	if req.Organization.Locale != nil {
+		updates.Locale, err = parseOptionalLocale(req.Organization.Locale)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	qUpdatedOrg, err := q.UpdateOrganization(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update organization: %w", err)
@@ -376,7 +417,7 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *backendv1.UpdateOrg
 		return nil, fmt.Errorf("get audit organization: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.organizations.update",
 		EventDetails: &auditlogv1.UpdateOrganization{
 			Organization:         auditOrganization,
@@ -435,7 +476,7 @@ func (s *Store) DeleteOrganization(ctx context.Context, req *backendv1.DeleteOrg
 		return nil, fmt.Errorf("delete organization: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.organizations.delete",
 		EventDetails: &auditlogv1.DeleteOrganization{
 			Organization: auditOrganization,
@@ -508,20 +549,23 @@ func (s *Store) EnableOrganizationLogins(ctx context.Context, req *backendv1.Ena
 }
 
 func (s *Store) sendSyncOrganizationEvent(ctx context.Context, tx pgx.Tx, qOrg queries.Organization) error {
-	// Add the sync organization event to the background worker queue
-	jobInsertRes, err := s.riverClient.InsertTx(ctx, tx, webhookworker.Args{
-		ProjectID: idformat.Project.Format(authn.ProjectID(ctx)),
-		EventName: "sync.organization",
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	orgID := idformat.Organization.Format(qOrg.ID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: orgID,
+		EventType:   "sync.organization",
 		Payload: map[string]any{
 			"type":           "sync.organization",
-			"organizationId": idformat.Organization.Format(qOrg.ID),
+			"organizationId": orgID,
 		},
-	}, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("insert job: %w", err)
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
 	}
 
-	slog.InfoContext(ctx, "webhook_worker_job_inserted", "job_id", jobInsertRes.Job.ID, "event_type", "sync.organization", "organization_id", idformat.Organization.Format(qOrg.ID))
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.organization", "organization_id", orgID)
 
 	return nil
 }
@@ -530,22 +574,24 @@ func parseOrganization(qProject queries.Project, qOrg queries.Organization) *bac
 	apiKeysEnabled := qProject.EntitledBackendApiKeys && qProject.ApiKeysEnabled && qOrg.ApiKeysEnabled
 
 	return &backendv1.Organization{
-		Id:                        idformat.Organization.Format(qOrg.ID),
-		DisplayName:               qOrg.DisplayName,
-		CreateTime:                timestamppb.New(*qOrg.CreateTime),
-		UpdateTime:                timestamppb.New(*qOrg.UpdateTime),
-		LogInWithGoogle:           &qOrg.LogInWithGoogle,
-		LogInWithMicrosoft:        &qOrg.LogInWithMicrosoft,
-		LogInWithGithub:           &qOrg.LogInWithGithub,
-		LogInWithEmail:            &qOrg.LogInWithEmail,
-		LogInWithPassword:         &qOrg.LogInWithPassword,
-		LogInWithSaml:             &qOrg.LogInWithSaml,
-		LogInWithOidc:             &qOrg.LogInWithOidc,
-		LogInWithAuthenticatorApp: &qOrg.LogInWithAuthenticatorApp,
-		LogInWithPasskey:          &qOrg.LogInWithPasskey,
-		RequireMfa:                &qOrg.RequireMfa,
-		ScimEnabled:               &qOrg.ScimEnabled,
-		CustomRolesEnabled:        &qOrg.CustomRolesEnabled,
-		ApiKeysEnabled:            &apiKeysEnabled,
+		Id:                           idformat.Organization.Format(qOrg.ID),
+		DisplayName:                  qOrg.DisplayName,
+		CreateTime:                   timestamppb.New(*qOrg.CreateTime),
+		UpdateTime:                   timestamppb.New(*qOrg.UpdateTime),
+		LogInWithGoogle:              &qOrg.LogInWithGoogle,
+		LogInWithMicrosoft:           &qOrg.LogInWithMicrosoft,
+		LogInWithGithub:              &qOrg.LogInWithGithub,
+		LogInWithEmail:               &qOrg.LogInWithEmail,
+		LogInWithPassword:            &qOrg.LogInWithPassword,
+		LogInWithSaml:                &qOrg.LogInWithSaml,
+		LogInWithOidc:                &qOrg.LogInWithOidc,
+		LogInWithAuthenticatorApp:    &qOrg.LogInWithAuthenticatorApp,
+		LogInWithPasskey:             &qOrg.LogInWithPasskey,
+		LogInWithPasswordlessPasskey: &qOrg.LogInWithPasswordlessPasskey,
+		RequireMfa:                   &qOrg.RequireMfa,
+		ScimEnabled:                  &qOrg.ScimEnabled,
+		CustomRolesEnabled:           &qOrg.CustomRolesEnabled,
+		ApiKeysEnabled:               &apiKeysEnabled,
+		Locale:                       qOrg.Locale,
 	}
 }
@@ -199,3 +199,79 @@ func TestDeleteOrganization_OIDCConnections(t *testing.T) {
 	_, err = u.Store.DeleteOrganization(ctx, &backendv1.DeleteOrganizationRequest{Id: orgID})
 	require.NoError(t, err)
 }
+
+func TestUpdateOrganization_Locale(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	locale := "fr-ca"
+	res, err := u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id:           orgID,
+		Organization: &backendv1.Organization{Locale: &locale},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "fr-CA", res.Organization.GetLocale())
+
+	// unrelated updates leave the locale in place
+	res, err = u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id:           orgID,
+		Organization: &backendv1.Organization{DisplayName: "renamed"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "fr-CA", res.Organization.GetLocale())
+
+	invalid := "not a locale!"
+	_, err = u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id:           orgID,
+		Organization: &backendv1.Organization{Locale: &invalid},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestUpdateOrganization_LogInWithPasswordlessPasskey(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	enabled, disabled := true, false
+
+	// passwordless passkey login requires passkeys
+	_, err := u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id: orgID,
+		Organization: &backendv1.Organization{
+			LogInWithPasswordlessPasskey: &enabled,
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	res, err := u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id: orgID,
+		Organization: &backendv1.Organization{
+			LogInWithPasskey:             &enabled,
+			LogInWithPasswordlessPasskey: &enabled,
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, res.Organization.GetLogInWithPasswordlessPasskey())
+
+	// disabling passkeys disables passwordless passkey login
+	res, err = u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id: orgID,
+		Organization: &backendv1.Organization{
+			LogInWithPasskey: &disabled,
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, res.Organization.GetLogInWithPasswordlessPasskey())
+}
@@ -153,7 +153,7 @@ func (s *Store) UpdatePasskey(ctx context.Context, req *backendv1.UpdatePasskeyR
 	}
 
 	passkey := parsePasskey(qUpdatedPasskey)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.passkeys.update",
 		EventDetails: &auditlogv1.UpdatePasskey{
 			Passkey:         auditPasskey,
@@ -217,7 +217,7 @@ func (s *Store) DeletePasskey(ctx context.Context, req *backendv1.DeletePasskeyR
 		return nil, fmt.Errorf("delete passkey: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.passkeys.delete",
 		EventDetails: &auditlogv1.DeletePasskey{
 			Passkey: auditPasskey,
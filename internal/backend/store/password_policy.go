@@ -0,0 +1,245 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/passwordpolicy"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func (s *Store) GetProjectPasswordPolicy(ctx context.Context, req *backendv1.GetProjectPasswordPolicyRequest) (*backendv1.GetProjectPasswordPolicyResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qPasswordPolicy, err := q.GetProjectPasswordPolicy(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &backendv1.GetProjectPasswordPolicyResponse{PasswordPolicy: &backendv1.PasswordPolicy{}}, nil
+		}
+
+		return nil, fmt.Errorf("get project password policy: %w", err)
+	}
+
+	return &backendv1.GetProjectPasswordPolicyResponse{
+		PasswordPolicy: parseProjectPasswordPolicy(qPasswordPolicy),
+	}, nil
+}
+
+func (s *Store) UpdateProjectPasswordPolicy(ctx context.Context, req *backendv1.UpdateProjectPasswordPolicyRequest) (*backendv1.UpdateProjectPasswordPolicyResponse, error) {
+	if err := validatePasswordPolicy(req.PasswordPolicy); err != nil {
+		return nil, err
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qPasswordPolicy, err := q.UpsertProjectPasswordPolicy(ctx, queries.UpsertProjectPasswordPolicyParams{
+		ProjectID:              authn.ProjectID(ctx),
+		MinLength:              req.PasswordPolicy.MinLength,
+		RequireLowercase:       req.PasswordPolicy.RequireLowercase,
+		RequireUppercase:       req.PasswordPolicy.RequireUppercase,
+		RequireDigit:           req.PasswordPolicy.RequireDigit,
+		RequireSymbol:          req.PasswordPolicy.RequireSymbol,
+		DisallowPersonalInfo:   req.PasswordPolicy.DisallowPersonalInfo,
+		HistoryCount:           req.PasswordPolicy.HistoryCount,
+		MaxAgeDays:             req.PasswordPolicy.MaxAgeDays,
+		LockoutAttempts:        req.PasswordPolicy.LockoutAttempts,
+		LockoutDurationSeconds: req.PasswordPolicy.LockoutDurationSeconds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("upsert project password policy: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateProjectPasswordPolicyResponse{
+		PasswordPolicy: parseProjectPasswordPolicy(qPasswordPolicy),
+	}, nil
+}
+
+func (s *Store) GetOrganizationPasswordPolicy(ctx context.Context, req *backendv1.GetOrganizationPasswordPolicyRequest) (*backendv1.GetOrganizationPasswordPolicyResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	qPasswordPolicy, err := q.GetOrganizationPasswordPolicy(ctx, qOrg.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &backendv1.GetOrganizationPasswordPolicyResponse{PasswordPolicy: &backendv1.PasswordPolicy{}}, nil
+		}
+
+		return nil, fmt.Errorf("get organization password policy: %w", err)
+	}
+
+	return &backendv1.GetOrganizationPasswordPolicyResponse{
+		PasswordPolicy: parseOrganizationPasswordPolicy(qPasswordPolicy),
+	}, nil
+}
+
+func (s *Store) UpdateOrganizationPasswordPolicy(ctx context.Context, req *backendv1.UpdateOrganizationPasswordPolicyRequest) (*backendv1.UpdateOrganizationPasswordPolicyResponse, error) {
+	if err := validatePasswordPolicy(req.PasswordPolicy); err != nil {
+		return nil, err
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	previousPasswordPolicy := &backendv1.PasswordPolicy{}
+	qPreviousPasswordPolicy, err := q.GetOrganizationPasswordPolicy(ctx, qOrg.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get organization password policy: %w", err)
+	}
+	if err == nil {
+		previousPasswordPolicy = parseOrganizationPasswordPolicy(qPreviousPasswordPolicy)
+	}
+
+	qPasswordPolicy, err := q.UpsertOrganizationPasswordPolicy(ctx, queries.UpsertOrganizationPasswordPolicyParams{
+		OrganizationID:         qOrg.ID,
+		MinLength:              req.PasswordPolicy.MinLength,
+		RequireLowercase:       req.PasswordPolicy.RequireLowercase,
+		RequireUppercase:       req.PasswordPolicy.RequireUppercase,
+		RequireDigit:           req.PasswordPolicy.RequireDigit,
+		RequireSymbol:          req.PasswordPolicy.RequireSymbol,
+		DisallowPersonalInfo:   req.PasswordPolicy.DisallowPersonalInfo,
+		HistoryCount:           req.PasswordPolicy.HistoryCount,
+		MaxAgeDays:             req.PasswordPolicy.MaxAgeDays,
+		LockoutAttempts:        req.PasswordPolicy.LockoutAttempts,
+		LockoutDurationSeconds: req.PasswordPolicy.LockoutDurationSeconds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("upsert organization password policy: %w", err)
+	}
+
+	passwordPolicy := parseOrganizationPasswordPolicy(qPasswordPolicy)
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.organizations.update_password_policy",
+		EventDetails: &auditlogv1.UpdateOrganizationPasswordPolicy{
+			PasswordPolicy:         auditlogPasswordPolicy(passwordPolicy),
+			PreviousPasswordPolicy: auditlogPasswordPolicy(previousPasswordPolicy),
+		},
+		OrganizationID: &qOrg.ID,
+		ResourceType:   queries.AuditLogEventResourceTypeOrganization,
+		ResourceID:     &qOrg.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateOrganizationPasswordPolicyResponse{
+		PasswordPolicy: passwordPolicy,
+	}, nil
+}
+
+func validatePasswordPolicy(passwordPolicy *backendv1.PasswordPolicy) error {
+	if passwordPolicy == nil {
+		return apierror.NewInvalidArgumentError("password_policy is required", fmt.Errorf("password policy is nil"))
+	}
+
+	policy := passwordpolicy.Policy{
+		MinLength:       int(passwordPolicy.MinLength),
+		HistoryCount:    int(passwordPolicy.HistoryCount),
+		MaxAge:          time.Duration(passwordPolicy.MaxAgeDays) * time.Hour * 24,
+		LockoutAttempts: int(passwordPolicy.LockoutAttempts),
+		LockoutDuration: time.Duration(passwordPolicy.LockoutDurationSeconds) * time.Second,
+	}
+	if err := policy.Validate(); err != nil {
+		return apierror.NewInvalidArgumentError(err.Error(), fmt.Errorf("validate password policy: %w", err))
+	}
+	return nil
+}
+
+func parseProjectPasswordPolicy(qPasswordPolicy queries.ProjectPasswordPolicy) *backendv1.PasswordPolicy {
+	return &backendv1.PasswordPolicy{
+		MinLength:              qPasswordPolicy.MinLength,
+		RequireLowercase:       qPasswordPolicy.RequireLowercase,
+		RequireUppercase:       qPasswordPolicy.RequireUppercase,
+		RequireDigit:           qPasswordPolicy.RequireDigit,
+		RequireSymbol:          qPasswordPolicy.RequireSymbol,
+		DisallowPersonalInfo:   qPasswordPolicy.DisallowPersonalInfo,
+		HistoryCount:           qPasswordPolicy.HistoryCount,
+		MaxAgeDays:             qPasswordPolicy.MaxAgeDays,
+		LockoutAttempts:        qPasswordPolicy.LockoutAttempts,
+		LockoutDurationSeconds: qPasswordPolicy.LockoutDurationSeconds,
+	}
+}
+
+func parseOrganizationPasswordPolicy(qPasswordPolicy queries.OrganizationPasswordPolicy) *backendv1.PasswordPolicy {
+	return &backendv1.PasswordPolicy{
+		MinLength:              qPasswordPolicy.MinLength,
+		RequireLowercase:       qPasswordPolicy.RequireLowercase,
+		RequireUppercase:       qPasswordPolicy.RequireUppercase,
+		RequireDigit:           qPasswordPolicy.RequireDigit,
+		RequireSymbol:          qPasswordPolicy.RequireSymbol,
+		DisallowPersonalInfo:   qPasswordPolicy.DisallowPersonalInfo,
+		HistoryCount:           qPasswordPolicy.HistoryCount,
+		MaxAgeDays:             qPasswordPolicy.MaxAgeDays,
+		LockoutAttempts:        qPasswordPolicy.LockoutAttempts,
+		LockoutDurationSeconds: qPasswordPolicy.LockoutDurationSeconds,
+	}
+}
+
+func auditlogPasswordPolicy(passwordPolicy *backendv1.PasswordPolicy) *auditlogv1.PasswordPolicy {
+	return &auditlogv1.PasswordPolicy{
+		MinLength:              passwordPolicy.MinLength,
+		RequireLowercase:       passwordPolicy.RequireLowercase,
+		RequireUppercase:       passwordPolicy.RequireUppercase,
+		RequireDigit:           passwordPolicy.RequireDigit,
+		RequireSymbol:          passwordPolicy.RequireSymbol,
+		DisallowPersonalInfo:   passwordPolicy.DisallowPersonalInfo,
+		HistoryCount:           passwordPolicy.HistoryCount,
+		MaxAgeDays:             passwordPolicy.MaxAgeDays,
+		LockoutAttempts:        passwordPolicy.LockoutAttempts,
+		LockoutDurationSeconds: passwordPolicy.LockoutDurationSeconds,
+	}
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func TestGetProjectPasswordPolicy_Empty(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	resp, err := u.Store.GetProjectPasswordPolicy(ctx, &backendv1.GetProjectPasswordPolicyRequest{})
+	require.NoError(t, err)
+	require.Zero(t, resp.PasswordPolicy.MinLength)
+	require.False(t, resp.PasswordPolicy.RequireDigit)
+}
+
+func TestUpdateProjectPasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	updateResp, err := u.Store.UpdateProjectPasswordPolicy(ctx, &backendv1.UpdateProjectPasswordPolicyRequest{
+		PasswordPolicy: &backendv1.PasswordPolicy{
+			MinLength:    12,
+			RequireDigit: true,
+			HistoryCount: 5,
+			MaxAgeDays:   90,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(12), updateResp.PasswordPolicy.MinLength)
+	require.True(t, updateResp.PasswordPolicy.RequireDigit)
+
+	getResp, err := u.Store.GetProjectPasswordPolicy(ctx, &backendv1.GetProjectPasswordPolicyRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(12), getResp.PasswordPolicy.MinLength)
+	require.True(t, getResp.PasswordPolicy.RequireDigit)
+	require.Equal(t, int32(5), getResp.PasswordPolicy.HistoryCount)
+	require.Equal(t, int32(90), getResp.PasswordPolicy.MaxAgeDays)
+}
+
+func TestUpdateOrganizationPasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	getResp, err := u.Store.GetOrganizationPasswordPolicy(ctx, &backendv1.GetOrganizationPasswordPolicyRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, getResp.PasswordPolicy.LockoutAttempts)
+
+	updateResp, err := u.Store.UpdateOrganizationPasswordPolicy(ctx, &backendv1.UpdateOrganizationPasswordPolicyRequest{
+		OrganizationId: orgID,
+		PasswordPolicy: &backendv1.PasswordPolicy{
+			RequireSymbol:          true,
+			LockoutAttempts:        3,
+			LockoutDurationSeconds: 3600,
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, updateResp.PasswordPolicy.RequireSymbol)
+
+	getResp, err = u.Store.GetOrganizationPasswordPolicy(ctx, &backendv1.GetOrganizationPasswordPolicyRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+	require.True(t, getResp.PasswordPolicy.RequireSymbol)
+	require.Equal(t, int32(3), getResp.PasswordPolicy.LockoutAttempts)
+	require.Equal(t, int32(3600), getResp.PasswordPolicy.LockoutDurationSeconds)
+}
+
+func TestUpdateOrganizationPasswordPolicy_Invalid(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	_, err := u.Store.UpdateOrganizationPasswordPolicy(ctx, &backendv1.UpdateOrganizationPasswordPolicyRequest{
+		OrganizationId: orgID,
+		PasswordPolicy: &backendv1.PasswordPolicy{
+			HistoryCount: 100,
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
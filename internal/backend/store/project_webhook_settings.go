@@ -2,11 +2,19 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/svix/svix-webhooks/go/models"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/standardwebhooks"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *Store) GetProjectWebhookManagementURL(ctx context.Context, req *backendv1.GetProjectWebhookManagementURLRequest) (*backendv1.GetProjectWebhookManagementURLResponse, error) {
@@ -34,3 +42,87 @@ func (s *Store) GetProjectWebhookManagementURL(ctx context.Context, req *backend
 
 	return res, nil
 }
+
+const (
+	defaultWebhookSigningSecretOverlap = time.Hour * 24
+	maxWebhookSigningSecretOverlap     = time.Hour * 24 * 7
+)
+
+func (s *Store) RotateProjectWebhookSigningSecret(ctx context.Context, req *backendv1.RotateProjectWebhookSigningSecretRequest) (*backendv1.RotateProjectWebhookSigningSecretResponse, error) {
+	overlap := defaultWebhookSigningSecretOverlap
+	if req.OverlapSeconds != nil {
+		overlap = time.Duration(*req.OverlapSeconds) * time.Second
+	}
+	if overlap < 0 || overlap > maxWebhookSigningSecretOverlap {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("overlap_seconds must be between 0 and %d", int(maxWebhookSigningSecretOverlap.Seconds())), fmt.Errorf("invalid overlap: %v", overlap))
+	}
+
+	// generate and encrypt the new secret outside a transaction, so that we can
+	// carry out KMS operations
+	signingSecret, err := standardwebhooks.NewSecret()
+	if err != nil {
+		return nil, fmt.Errorf("new signing secret: %w", err)
+	}
+
+	signingSecretCiphertext, err := s.webhookSigningSecretsKMS.Encrypt(ctx, signingSecret)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt signing secret: %w", err)
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("start transaction: %w", err)
+	}
+	defer rollback()
+
+	qProjectWebhookSettings, err := q.GetProjectWebhookSettings(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("project webhook settings not found", fmt.Errorf("get project webhook settings: %w", err))
+		}
+
+		return nil, fmt.Errorf("get project webhook settings: %w", err)
+	}
+
+	// keep signing with the current secret for the overlap period, so that
+	// receivers have time to switch over
+	var previousSigningSecretCiphertext []byte
+	var previousSigningSecretExpireTime *time.Time
+	if qProjectWebhookSettings.SigningSecretCiphertext != nil && overlap > 0 {
+		previousSigningSecretCiphertext = qProjectWebhookSettings.SigningSecretCiphertext
+		previousSigningSecretExpireTime = refOrNil(time.Now().Add(overlap))
+	}
+
+	if _, err := q.UpdateProjectWebhookSettingsSigningSecret(ctx, queries.UpdateProjectWebhookSettingsSigningSecretParams{
+		ProjectID:                       authn.ProjectID(ctx),
+		SigningSecretCiphertext:         signingSecretCiphertext,
+		PreviousSigningSecretCiphertext: previousSigningSecretCiphertext,
+		PreviousSigningSecretExpireTime: previousSigningSecretExpireTime,
+	}); err != nil {
+		return nil, fmt.Errorf("update project webhook settings signing secret: %w", err)
+	}
+
+	eventDetails := &auditlogv1.RotateProjectWebhookSigningSecret{}
+	if previousSigningSecretExpireTime != nil {
+		eventDetails.PreviousSigningSecretExpireTime = timestamppb.New(*previousSigningSecretExpireTime)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName:    "tesseral.projects.rotate_webhook_signing_secret",
+		EventDetails: eventDetails,
+	}); err != nil {
+		return nil, fmt.Errorf("log audit event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	res := &backendv1.RotateProjectWebhookSigningSecretResponse{
+		SigningSecret: standardwebhooks.FormatSecret(signingSecret),
+	}
+	if previousSigningSecretExpireTime != nil {
+		res.PreviousSigningSecretExpireTime = timestamppb.New(*previousSigningSecretExpireTime)
+	}
+	return res, nil
+}
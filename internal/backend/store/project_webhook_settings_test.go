@@ -2,8 +2,81 @@ package store
 
 import (
 	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/standardwebhooks"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 )
 
+func (u *testUtil) newProjectWebhookSettings(t *testing.T) {
+	projectUUID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(t.Context(), `
+	INSERT INTO project_webhook_settings (id, project_id, direct_webhook_url)
+	VALUES ($1::uuid, $2::uuid, $3)
+	`,
+		uuid.New().String(),
+		uuid.UUID(projectUUID).String(),
+		"https://example.com/webhooks",
+	)
+	require.NoError(t, err)
+}
+
 func TestGetProjectWebhookManagementURL(t *testing.T) {
 	t.Skip("svix is not available in test environment")
 }
+
+func TestRotateProjectWebhookSigningSecret(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	u.newProjectWebhookSettings(t)
+
+	res, err := u.Store.RotateProjectWebhookSigningSecret(ctx, &backendv1.RotateProjectWebhookSigningSecretRequest{})
+	require.NoError(t, err)
+	_, err = standardwebhooks.ParseSecret(res.SigningSecret)
+	require.NoError(t, err)
+	require.Nil(t, res.PreviousSigningSecretExpireTime) // no previous secret to keep
+
+	res2, err := u.Store.RotateProjectWebhookSigningSecret(ctx, &backendv1.RotateProjectWebhookSigningSecretRequest{})
+	require.NoError(t, err)
+	require.NotEqual(t, res.SigningSecret, res2.SigningSecret)
+	require.NotNil(t, res2.PreviousSigningSecretExpireTime)
+
+	overlapSeconds := int32(0)
+	res3, err := u.Store.RotateProjectWebhookSigningSecret(ctx, &backendv1.RotateProjectWebhookSigningSecretRequest{
+		OverlapSeconds: &overlapSeconds,
+	})
+	require.NoError(t, err)
+	require.Nil(t, res3.PreviousSigningSecretExpireTime)
+}
+
+func TestRotateProjectWebhookSigningSecret_InvalidOverlap(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	u.newProjectWebhookSettings(t)
+
+	overlapSeconds := int32(60 * 60 * 24 * 30)
+	_, err := u.Store.RotateProjectWebhookSigningSecret(ctx, &backendv1.RotateProjectWebhookSigningSecretRequest{
+		OverlapSeconds: &overlapSeconds,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestRotateProjectWebhookSigningSecret_NoSettings(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.RotateProjectWebhookSigningSecret(ctx, &backendv1.RotateProjectWebhookSigningSecretRequest{})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
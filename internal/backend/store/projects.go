@@ -218,6 +218,20 @@ func (s *Store) UpdateProject(ctx context.Context, req *backendv1.UpdateProjectR
 		updates.LogInWithPasskey = *req.Project.LogInWithPasskey
 	}
 
+	updates.LogInWithPasswordlessPasskey = qProject.LogInWithPasswordlessPasskey
+	if req.Project.LogInWithPasswordlessPasskey != nil {
+		if *req.Project.LogInWithPasswordlessPasskey && !updates.LogInWithPasskey {
+			return nil, apierror.NewInvalidArgumentError("log in with passwordless passkey requires log in with passkey to be enabled", fmt.Errorf("log in with passwordless passkey requires log in with passkey to be enabled"))
+		}
+
+		updates.LogInWithPasswordlessPasskey = *req.Project.LogInWithPasswordlessPasskey
+	}
+
+	// passwordless passkey login is turned off along with passkeys
+	if !updates.LogInWithPasskey {
+		updates.LogInWithPasswordlessPasskey = false
+	}
+
 	updates.ApiKeysEnabled = qProject.ApiKeysEnabled
 	if req.Project.ApiKeysEnabled != nil {
 		updates.ApiKeysEnabled = *req.Project.ApiKeysEnabled
@@ -353,6 +367,13 @@ func (s *Store) UpdateProject(ctx context.Context, req *backendv1.UpdateProjectR
 		}
 	}
 
+	if !qUpdatedProject.LogInWithPasswordlessPasskey {
+		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_passwordless_passkey")
+		if err := q.DisableProjectOrganizationsLogInWithPasswordlessPasskey(ctx, authn.ProjectID(ctx)); err != nil {
+			return nil, fmt.Errorf("disable project organizations log in with passwordless passkey: %w", err)
+		}
+	}
+
 	// only update project trusted domains if mentioned in request
 	if len(req.Project.TrustedDomains) > 0 {
 		// always include the default vault domain (project-xxx.tesseral.app)
@@ -654,35 +675,36 @@ func (s *Store) parseProject(qProject *queries.Project, qProjectTrustedDomains [
 	}
 
 	return &backendv1.Project{
-		Id:                         idformat.Project.Format(qProject.ID),
-		DisplayName:                qProject.DisplayName,
-		CreateTime:                 timestamppb.New(*qProject.CreateTime),
-		UpdateTime:                 timestamppb.New(*qProject.UpdateTime),
-		LogInWithGoogle:            &qProject.LogInWithGoogle,
-		LogInWithMicrosoft:         &qProject.LogInWithMicrosoft,
-		LogInWithGithub:            &qProject.LogInWithGithub,
-		LogInWithEmail:             &qProject.LogInWithEmail,
-		LogInWithPassword:          &qProject.LogInWithPassword,
-		LogInWithSaml:              &qProject.LogInWithSaml,
-		LogInWithOidc:              &qProject.LogInWithOidc,
-		LogInWithAuthenticatorApp:  &qProject.LogInWithAuthenticatorApp,
-		LogInWithPasskey:           &qProject.LogInWithPasskey,
-		GoogleOauthClientId:        qProject.GoogleOauthClientID,
-		GoogleOauthClientSecret:    "", // intentionally left blank
-		MicrosoftOauthClientId:     qProject.MicrosoftOauthClientID,
-		MicrosoftOauthClientSecret: "", // intentionally left blank
-		GithubOauthClientId:        qProject.GithubOauthClientID,
-		GithubOauthClientSecret:    "", // intentionally left blank
-		VaultDomain:                qProject.VaultDomain,
-		VaultDomainCustom:          qProject.VaultDomain != fmt.Sprintf("%s.%s", strings.ReplaceAll(idformat.Project.Format(qProject.ID), "_", "-"), s.authAppsRootDomain),
-		TrustedDomains:             trustedDomains,
-		CookieDomain:               qProject.CookieDomain,
-		EmailSendFromDomain:        qProject.EmailSendFromDomain,
-		ApiKeysEnabled:             &qProject.ApiKeysEnabled,
-		ApiKeySecretTokenPrefix:    qProject.ApiKeySecretTokenPrefix,
-		AuditLogsEnabled:           refOrNil(qProject.AuditLogsEnabled),
-		CustomEmailVerifyEmail:     &qProject.CustomEmailVerifyEmail,
-		CustomEmailPasswordReset:   &qProject.CustomEmailPasswordReset,
-		CustomEmailUserInvite:      &qProject.CustomEmailUserInvite,
+		Id:                           idformat.Project.Format(qProject.ID),
+		DisplayName:                  qProject.DisplayName,
+		CreateTime:                   timestamppb.New(*qProject.CreateTime),
+		UpdateTime:                   timestamppb.New(*qProject.UpdateTime),
+		LogInWithGoogle:              &qProject.LogInWithGoogle,
+		LogInWithMicrosoft:           &qProject.LogInWithMicrosoft,
+		LogInWithGithub:              &qProject.LogInWithGithub,
+		LogInWithEmail:               &qProject.LogInWithEmail,
+		LogInWithPassword:            &qProject.LogInWithPassword,
+		LogInWithSaml:                &qProject.LogInWithSaml,
+		LogInWithOidc:                &qProject.LogInWithOidc,
+		LogInWithAuthenticatorApp:    &qProject.LogInWithAuthenticatorApp,
+		LogInWithPasskey:             &qProject.LogInWithPasskey,
+		LogInWithPasswordlessPasskey: &qProject.LogInWithPasswordlessPasskey,
+		GoogleOauthClientId:          qProject.GoogleOauthClientID,
+		GoogleOauthClientSecret:      "", // intentionally left blank
+		MicrosoftOauthClientId:       qProject.MicrosoftOauthClientID,
+		MicrosoftOauthClientSecret:   "", // intentionally left blank
+		GithubOauthClientId:          qProject.GithubOauthClientID,
+		GithubOauthClientSecret:      "", // intentionally left blank
+		VaultDomain:                  qProject.VaultDomain,
+		VaultDomainCustom:            qProject.VaultDomain != fmt.Sprintf("%s.%s", strings.ReplaceAll(idformat.Project.Format(qProject.ID), "_", "-"), s.authAppsRootDomain),
+		TrustedDomains:               trustedDomains,
+		CookieDomain:                 qProject.CookieDomain,
+		EmailSendFromDomain:          qProject.EmailSendFromDomain,
+		ApiKeysEnabled:               &qProject.ApiKeysEnabled,
+		ApiKeySecretTokenPrefix:      qProject.ApiKeySecretTokenPrefix,
+		AuditLogsEnabled:             refOrNil(qProject.AuditLogsEnabled),
+		CustomEmailVerifyEmail:       &qProject.CustomEmailVerifyEmail,
+		CustomEmailPasswordReset:     &qProject.CustomEmailPasswordReset,
+		CustomEmailUserInvite:        &qProject.CustomEmailUserInvite,
 	}
 }
@@ -25,7 +25,17 @@ func (s *Store) GetRBACPolicy(ctx context.Context, req *backendv1.GetRBACPolicyR
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
-	return &backendv1.GetRBACPolicyResponse{RbacPolicy: parseRBACPolicy(qActions)}, nil
+	qResourceTypes, err := q.GetResourceTypes(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource types: %w", err)
+	}
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
+	return &backendv1.GetRBACPolicyResponse{RbacPolicy: parseRBACPolicy(qActions, qResourceTypes, qResourceRelations)}, nil
 }
 
 var actionPattern = regexp.MustCompile(`^[a-z0-9_]+\.[a-z0-9_]+\.[a-z0-9_]+`)
@@ -40,6 +50,41 @@ func validateActionName(action string) error {
 	return nil
 }
 
+var resourceNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func validateResourceTypes(resourceTypes []*backendv1.ResourceType) error {
+	resourceTypeNames := map[string]struct{}{}
+	for _, resourceType := range resourceTypes {
+		if !resourceNamePattern.MatchString(resourceType.Name) {
+			return apierror.NewInvalidArgumentError("resource type names must only contain a-z0-9_", nil)
+		}
+		if _, ok := resourceTypeNames[resourceType.Name]; ok {
+			return apierror.NewInvalidArgumentError(fmt.Sprintf("duplicate resource type: %s", resourceType.Name), nil)
+		}
+		resourceTypeNames[resourceType.Name] = struct{}{}
+
+		relationNames := map[string]struct{}{}
+		for _, relation := range resourceType.Relations {
+			if !resourceNamePattern.MatchString(relation.Name) {
+				return apierror.NewInvalidArgumentError("resource relation names must only contain a-z0-9_", nil)
+			}
+			if _, ok := relationNames[relation.Name]; ok {
+				return apierror.NewInvalidArgumentError(fmt.Sprintf("duplicate relation on resource type %s: %s", resourceType.Name, relation.Name), nil)
+			}
+			relationNames[relation.Name] = struct{}{}
+		}
+
+		for _, relation := range resourceType.Relations {
+			for _, impliedBy := range relation.ImpliedBy {
+				if _, ok := relationNames[impliedBy]; !ok || impliedBy == relation.Name {
+					return apierror.NewInvalidArgumentError(fmt.Sprintf("relation %s on resource type %s is implied by an invalid relation: %s", relation.Name, resourceType.Name, impliedBy), nil)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (s *Store) UpdateRBACPolicy(ctx context.Context, req *backendv1.UpdateRBACPolicyRequest) (*backendv1.UpdateRBACPolicyResponse, error) {
 	_, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -53,6 +98,10 @@ func (s *Store) UpdateRBACPolicy(ctx context.Context, req *backendv1.UpdateRBACP
 		}
 	}
 
+	if err := validateResourceTypes(req.RbacPolicy.ResourceTypes); err != nil {
+		return nil, fmt.Errorf("validate resource types: %w", err)
+	}
+
 	names := []string{} // initialize because passing NULL has the wrong behavior in the postgres query
 	for _, action := range req.RbacPolicy.Actions {
 		names = append(names, action.Name)
@@ -73,19 +122,75 @@ func (s *Store) UpdateRBACPolicy(ctx context.Context, req *backendv1.UpdateRBACP
 		return nil, fmt.Errorf("delete actions by name not in list: %w", err)
 	}
 
+	resourceTypeNames := []string{}
+	for _, resourceType := range req.RbacPolicy.ResourceTypes {
+		resourceTypeNames = append(resourceTypeNames, resourceType.Name)
+		qResourceType, err := q.UpsertResourceType(ctx, queries.UpsertResourceTypeParams{
+			ID:          uuid.New(),
+			ProjectID:   authn.ProjectID(ctx),
+			Name:        resourceType.Name,
+			Description: resourceType.Description,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("upsert resource type: %w", err)
+		}
+
+		relationNames := []string{}
+		for _, relation := range resourceType.Relations {
+			relationNames = append(relationNames, relation.Name)
+
+			impliedBy := []string{}
+			impliedBy = append(impliedBy, relation.ImpliedBy...)
+			if err := q.UpsertResourceRelation(ctx, queries.UpsertResourceRelationParams{
+				ID:             uuid.New(),
+				ResourceTypeID: qResourceType.ID,
+				Name:           relation.Name,
+				Description:    relation.Description,
+				ImpliedBy:      impliedBy,
+			}); err != nil {
+				return nil, fmt.Errorf("upsert resource relation: %w", err)
+			}
+		}
+
+		// deleting a relation deletes the relationships that grant it
+		if err := q.DeleteResourceRelationsByNameNotInList(ctx, queries.DeleteResourceRelationsByNameNotInListParams{
+			ResourceTypeID: qResourceType.ID,
+			Names:          relationNames,
+		}); err != nil {
+			return nil, fmt.Errorf("delete resource relations by name not in list: %w", err)
+		}
+	}
+
+	if err := q.DeleteResourceTypesByNameNotInList(ctx, queries.DeleteResourceTypesByNameNotInListParams{
+		ProjectID: authn.ProjectID(ctx),
+		Names:     resourceTypeNames,
+	}); err != nil {
+		return nil, fmt.Errorf("delete resource types by name not in list: %w", err)
+	}
+
 	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
+	qResourceTypes, err := q.GetResourceTypes(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource types: %w", err)
+	}
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
 
-	return &backendv1.UpdateRBACPolicyResponse{RbacPolicy: parseRBACPolicy(qActions)}, nil
+	return &backendv1.UpdateRBACPolicyResponse{RbacPolicy: parseRBACPolicy(qActions, qResourceTypes, qResourceRelations)}, nil
 }
 
-func parseRBACPolicy(qActions []queries.Action) *backendv1.RBACPolicy {
+func parseRBACPolicy(qActions []queries.Action, qResourceTypes []queries.ResourceType, qResourceRelations []queries.GetResourceRelationsRow) *backendv1.RBACPolicy {
 	var actions []*backendv1.Action
 	for _, qAction := range qActions {
 		actions = append(actions, &backendv1.Action{
@@ -94,7 +199,30 @@ func parseRBACPolicy(qActions []queries.Action) *backendv1.RBACPolicy {
 		})
 	}
 
+	var resourceTypes []*backendv1.ResourceType
+	for _, qResourceType := range qResourceTypes {
+		var relations []*backendv1.ResourceRelation
+		for _, qResourceRelation := range qResourceRelations {
+			if qResourceRelation.ResourceTypeID != qResourceType.ID {
+				continue
+			}
+
+			relations = append(relations, &backendv1.ResourceRelation{
+				Name:        qResourceRelation.Name,
+				Description: qResourceRelation.Description,
+				ImpliedBy:   qResourceRelation.ImpliedBy,
+			})
+		}
+
+		resourceTypes = append(resourceTypes, &backendv1.ResourceType{
+			Name:        qResourceType.Name,
+			Description: qResourceType.Description,
+			Relations:   relations,
+		})
+	}
+
 	return &backendv1.RBACPolicy{
-		Actions: actions,
+		Actions:       actions,
+		ResourceTypes: resourceTypes,
 	}
 }
@@ -0,0 +1,622 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// maxRelationshipBatchSize is the maximum number of checks or lists in a batch
+// request.
+const maxRelationshipBatchSize = 100
+
+func (s *Store) ListRelationships(ctx context.Context, req *backendv1.ListRelationshipsRequest) (*backendv1.ListRelationshipsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	// authz
+	if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization: %w", err))
+		}
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	if req.ResourceId != "" && req.ResourceType == "" {
+		return nil, apierror.NewInvalidArgumentError("resource_type is required when resource_id is set", nil)
+	}
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
+	var resourceRelationIDs []uuid.UUID
+	if req.ResourceType != "" {
+		resourceRelationIDs, err = getResourceTypeRelationIDs(ctx, q, qResourceRelations, req.ResourceType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qRelationships, err := q.ListRelationships(ctx, queries.ListRelationshipsParams{
+		OrganizationID:      orgID,
+		ResourceRelationIds: resourceRelationIDs,
+		ResourceID:          refOrNil(req.ResourceId),
+		ID:                  startID,
+		Limit:               int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list relationships: %w", err)
+	}
+
+	var relationships []*backendv1.Relationship
+	for _, qRelationship := range qRelationships {
+		relationships = append(relationships, parseRelationship(qRelationship, qResourceRelations))
+	}
+
+	var nextPageToken string
+	if len(relationships) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qRelationships[limit].ID)
+		relationships = relationships[:limit]
+	}
+
+	return &backendv1.ListRelationshipsResponse{
+		Relationships: relationships,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetRelationship(ctx context.Context, req *backendv1.GetRelationshipRequest) (*backendv1.GetRelationshipResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	id, err := idformat.Relationship.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid relationship id", fmt.Errorf("parse relationship id: %w", err))
+	}
+
+	qRelationship, err := q.GetRelationship(ctx, queries.GetRelationshipParams{
+		ID:        id,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("relationship not found", fmt.Errorf("get relationship: %w", err))
+		}
+		return nil, fmt.Errorf("get relationship: %w", err)
+	}
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
+	return &backendv1.GetRelationshipResponse{Relationship: parseRelationship(qRelationship, qResourceRelations)}, nil
+}
+
+func (s *Store) CreateRelationship(ctx context.Context, req *backendv1.CreateRelationshipRequest) (*backendv1.CreateRelationshipResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.Relationship.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization: %w", err))
+		}
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	if req.Relationship.ResourceId == "" {
+		return nil, apierror.NewInvalidArgumentError("resource_id is required", nil)
+	}
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
+	qResourceRelation, err := findResourceRelation(qResourceRelations, req.Relationship.ResourceType, req.Relationship.Relation)
+	if err != nil {
+		return nil, err
+	}
+
+	params := queries.CreateRelationshipParams{
+		ID:                 uuid.New(),
+		OrganizationID:     qOrg.ID,
+		ResourceRelationID: qResourceRelation.ID,
+		ResourceID:         req.Relationship.ResourceId,
+	}
+
+	if userID, err := idformat.User.Parse(req.Relationship.SubjectId); err == nil {
+		qUser, err := q.GetUser(ctx, queries.GetUserParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        userID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("user not found", fmt.Errorf("get user: %w", err))
+			}
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+
+		if qUser.OrganizationID != qOrg.ID {
+			return nil, apierror.NewInvalidArgumentError("user belongs to a different organization", fmt.Errorf("user belongs to a different organization"))
+		}
+
+		params.UserID = &qUser.ID
+	} else if apiKeyID, err := idformat.APIKey.Parse(req.Relationship.SubjectId); err == nil {
+		qAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        apiKeyID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
+			}
+			return nil, fmt.Errorf("get api key: %w", err)
+		}
+
+		if qAPIKey.OrganizationID != qOrg.ID {
+			return nil, apierror.NewInvalidArgumentError("api key belongs to a different organization", fmt.Errorf("api key belongs to a different organization"))
+		}
+
+		params.ApiKeyID = &qAPIKey.ID
+	} else if roleID, err := idformat.Role.Parse(req.Relationship.SubjectId); err == nil {
+		qRole, err := q.GetRole(ctx, queries.GetRoleParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        roleID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("role not found", fmt.Errorf("get role: %w", err))
+			}
+			return nil, fmt.Errorf("get role: %w", err)
+		}
+
+		if qRole.OrganizationID != nil && *qRole.OrganizationID != qOrg.ID {
+			return nil, apierror.NewInvalidArgumentError("role belongs to a different organization", fmt.Errorf("role belongs to a different organization"))
+		}
+
+		params.RoleID = &qRole.ID
+	} else {
+		return nil, apierror.NewInvalidArgumentError("subject_id must be a user, api key, or role id", nil)
+	}
+
+	qRelationship, err := q.CreateRelationship(ctx, params)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return nil, apierror.NewAlreadyExistsError("relationship already exists", fmt.Errorf("create relationship: %w", err))
+		}
+		return nil, fmt.Errorf("create relationship: %w", err)
+	}
+
+	auditRelationship, err := s.auditlogStore.GetRelationship(ctx, tx, qRelationship.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit relationship: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.relationships.create",
+		EventDetails: &auditlogv1.CreateRelationship{
+			Relationship: auditRelationship,
+		},
+		OrganizationID: &qOrg.ID,
+		ResourceType:   queries.AuditLogEventResourceTypeRelationship,
+		ResourceID:     &qRelationship.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.CreateRelationshipResponse{Relationship: parseRelationship(qRelationship, qResourceRelations)}, nil
+}
+
+func (s *Store) DeleteRelationship(ctx context.Context, req *backendv1.DeleteRelationshipRequest) (*backendv1.DeleteRelationshipResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	id, err := idformat.Relationship.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid relationship id", fmt.Errorf("parse relationship id: %w", err))
+	}
+
+	qRelationship, err := q.GetRelationship(ctx, queries.GetRelationshipParams{
+		ID:        id,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("relationship not found", fmt.Errorf("get relationship: %w", err))
+		}
+		return nil, fmt.Errorf("get relationship: %w", err)
+	}
+
+	auditRelationship, err := s.auditlogStore.GetRelationship(ctx, tx, qRelationship.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit relationship: %w", err)
+	}
+
+	if err := q.DeleteRelationship(ctx, qRelationship.ID); err != nil {
+		return nil, fmt.Errorf("delete relationship: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.relationships.delete",
+		EventDetails: &auditlogv1.DeleteRelationship{
+			Relationship: auditRelationship,
+		},
+		OrganizationID: &qRelationship.OrganizationID,
+		ResourceType:   queries.AuditLogEventResourceTypeRelationship,
+		ResourceID:     &qRelationship.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteRelationshipResponse{}, nil
+}
+
+func (s *Store) CheckRelationship(ctx context.Context, req *backendv1.CheckRelationshipRequest) (*backendv1.CheckRelationshipResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
+	return s.checkRelationship(ctx, q, qResourceRelations, req)
+}
+
+func (s *Store) BatchCheckRelationships(ctx context.Context, req *backendv1.BatchCheckRelationshipsRequest) (*backendv1.BatchCheckRelationshipsResponse, error) {
+	if len(req.Checks) > maxRelationshipBatchSize {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("at most %d checks are allowed", maxRelationshipBatchSize), nil)
+	}
+
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
+	var results []*backendv1.CheckRelationshipResponse
+	for i, check := range req.Checks {
+		result, err := s.checkRelationship(ctx, q, qResourceRelations, check)
+		if err != nil {
+			return nil, fmt.Errorf("check %d: %w", i, err)
+		}
+		results = append(results, result)
+	}
+
+	return &backendv1.BatchCheckRelationshipsResponse{Results: results}, nil
+}
+
+func (s *Store) checkRelationship(ctx context.Context, q *queries.Queries, qResourceRelations []queries.GetResourceRelationsRow, req *backendv1.CheckRelationshipRequest) (*backendv1.CheckRelationshipResponse, error) {
+	resourceRelationIDs, err := getImplyingResourceRelationIDs(qResourceRelations, req.ResourceType, req.Relation)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := getRelationshipSubject(ctx, q, req.SubjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowed bool
+	if subject.userID != nil {
+		allowed, err = q.CheckUserRelationship(ctx, queries.CheckUserRelationshipParams{
+			OrganizationID:      subject.organizationID,
+			ResourceRelationIds: resourceRelationIDs,
+			ResourceID:          req.ResourceId,
+			UserID:              *subject.userID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("check user relationship: %w", err)
+		}
+	} else {
+		allowed, err = q.CheckAPIKeyRelationship(ctx, queries.CheckAPIKeyRelationshipParams{
+			OrganizationID:      subject.organizationID,
+			ResourceRelationIds: resourceRelationIDs,
+			ResourceID:          req.ResourceId,
+			ApiKeyID:            *subject.apiKeyID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("check api key relationship: %w", err)
+		}
+	}
+
+	return &backendv1.CheckRelationshipResponse{Allowed: allowed}, nil
+}
+
+func (s *Store) ListRelationshipObjects(ctx context.Context, req *backendv1.ListRelationshipObjectsRequest) (*backendv1.ListRelationshipObjectsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
+	return s.listRelationshipObjects(ctx, q, qResourceRelations, req)
+}
+
+func (s *Store) BatchListRelationshipObjects(ctx context.Context, req *backendv1.BatchListRelationshipObjectsRequest) (*backendv1.BatchListRelationshipObjectsResponse, error) {
+	if len(req.Requests) > maxRelationshipBatchSize {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("at most %d requests are allowed", maxRelationshipBatchSize), nil)
+	}
+
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qResourceRelations, err := q.GetResourceRelations(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource relations: %w", err)
+	}
+
+	var responses []*backendv1.ListRelationshipObjectsResponse
+	for i, listReq := range req.Requests {
+		res, err := s.listRelationshipObjects(ctx, q, qResourceRelations, listReq)
+		if err != nil {
+			return nil, fmt.Errorf("request %d: %w", i, err)
+		}
+		responses = append(responses, res)
+	}
+
+	return &backendv1.BatchListRelationshipObjectsResponse{Responses: responses}, nil
+}
+
+func (s *Store) listRelationshipObjects(ctx context.Context, q *queries.Queries, qResourceRelations []queries.GetResourceRelationsRow, req *backendv1.ListRelationshipObjectsRequest) (*backendv1.ListRelationshipObjectsResponse, error) {
+	resourceRelationIDs, err := getImplyingResourceRelationIDs(qResourceRelations, req.ResourceType, req.Relation)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := getRelationshipSubject(ctx, q, req.SubjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var startResourceID string
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startResourceID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 100
+	var resourceIDs []string
+	if subject.userID != nil {
+		resourceIDs, err = q.ListUserRelationshipResourceIDs(ctx, queries.ListUserRelationshipResourceIDsParams{
+			OrganizationID:      subject.organizationID,
+			ResourceRelationIds: resourceRelationIDs,
+			UserID:              *subject.userID,
+			StartResourceID:     startResourceID,
+			PageLimit:           int32(limit + 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list user relationship resource ids: %w", err)
+		}
+	} else {
+		resourceIDs, err = q.ListAPIKeyRelationshipResourceIDs(ctx, queries.ListAPIKeyRelationshipResourceIDsParams{
+			OrganizationID:      subject.organizationID,
+			ResourceRelationIds: resourceRelationIDs,
+			ApiKeyID:            *subject.apiKeyID,
+			StartResourceID:     startResourceID,
+			PageLimit:           int32(limit + 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list api key relationship resource ids: %w", err)
+		}
+	}
+
+	// resource ids are unique and ordered, so the next page starts after the
+	// last one on this page
+	var nextPageToken string
+	if len(resourceIDs) == limit+1 {
+		resourceIDs = resourceIDs[:limit]
+		nextPageToken = s.pageEncoder.Marshal(resourceIDs[limit-1])
+	}
+
+	return &backendv1.ListRelationshipObjectsResponse{
+		ResourceIds:   resourceIDs,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// relationshipSubject is a User or API Key whose relations are being checked.
+type relationshipSubject struct {
+	organizationID uuid.UUID
+	userID         *uuid.UUID
+	apiKeyID       *uuid.UUID
+}
+
+func getRelationshipSubject(ctx context.Context, q *queries.Queries, subjectID string) (*relationshipSubject, error) {
+	if userID, err := idformat.User.Parse(subjectID); err == nil {
+		qUser, err := q.GetUser(ctx, queries.GetUserParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        userID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("user not found", fmt.Errorf("get user: %w", err))
+			}
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+
+		return &relationshipSubject{organizationID: qUser.OrganizationID, userID: &qUser.ID}, nil
+	}
+
+	if apiKeyID, err := idformat.APIKey.Parse(subjectID); err == nil {
+		qAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        apiKeyID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
+			}
+			return nil, fmt.Errorf("get api key: %w", err)
+		}
+
+		return &relationshipSubject{organizationID: qAPIKey.OrganizationID, apiKeyID: &qAPIKey.ID}, nil
+	}
+
+	return nil, apierror.NewInvalidArgumentError("subject_id must be a user or api key id", nil)
+}
+
+// getResourceTypeRelationIDs returns the IDs of the relations of resourceType.
+func getResourceTypeRelationIDs(ctx context.Context, q *queries.Queries, qResourceRelations []queries.GetResourceRelationsRow, resourceType string) ([]uuid.UUID, error) {
+	qResourceTypes, err := q.GetResourceTypes(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get resource types: %w", err)
+	}
+
+	var found bool
+	for _, qResourceType := range qResourceTypes {
+		if qResourceType.Name == resourceType {
+			found = true
+		}
+	}
+	if !found {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("unknown resource type: %s", resourceType), nil)
+	}
+
+	resourceRelationIDs := []uuid.UUID{} // initialize so that a resource type without relations matches nothing
+	for _, qResourceRelation := range qResourceRelations {
+		if qResourceRelation.ResourceTypeName == resourceType {
+			resourceRelationIDs = append(resourceRelationIDs, qResourceRelation.ID)
+		}
+	}
+	return resourceRelationIDs, nil
+}
+
+// getImplyingResourceRelationIDs returns the IDs of relation on resourceType
+// and of every relation that implies it, directly or transitively.
+func getImplyingResourceRelationIDs(qResourceRelations []queries.GetResourceRelationsRow, resourceType, relation string) ([]uuid.UUID, error) {
+	if _, err := findResourceRelation(qResourceRelations, resourceType, relation); err != nil {
+		return nil, err
+	}
+
+	var resourceRelationIDs []uuid.UUID
+	seen := map[string]bool{relation: true}
+	queue := []string{relation}
+	for len(queue) > 0 {
+		qResourceRelation, err := findResourceRelation(qResourceRelations, resourceType, queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+
+		resourceRelationIDs = append(resourceRelationIDs, qResourceRelation.ID)
+		for _, impliedBy := range qResourceRelation.ImpliedBy {
+			if !seen[impliedBy] {
+				seen[impliedBy] = true
+				queue = append(queue, impliedBy)
+			}
+		}
+	}
+
+	return resourceRelationIDs, nil
+}
+
+func findResourceRelation(qResourceRelations []queries.GetResourceRelationsRow, resourceType, relation string) (*queries.GetResourceRelationsRow, error) {
+	for _, qResourceRelation := range qResourceRelations {
+		if qResourceRelation.ResourceTypeName == resourceType && qResourceRelation.Name == relation {
+			return &qResourceRelation, nil
+		}
+	}
+	return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("unknown relation on resource type %s: %s", resourceType, relation), nil)
+}
+
+func parseRelationship(qRelationship queries.Relationship, qResourceRelations []queries.GetResourceRelationsRow) *backendv1.Relationship {
+	var resourceType, relation string
+	for _, qResourceRelation := range qResourceRelations {
+		if qResourceRelation.ID == qRelationship.ResourceRelationID {
+			resourceType, relation = qResourceRelation.ResourceTypeName, qResourceRelation.Name
+		}
+	}
+
+	var subjectID string
+	switch {
+	case qRelationship.UserID != nil:
+		subjectID = idformat.User.Format(*qRelationship.UserID)
+	case qRelationship.ApiKeyID != nil:
+		subjectID = idformat.APIKey.Format(*qRelationship.ApiKeyID)
+	case qRelationship.RoleID != nil:
+		subjectID = idformat.Role.Format(*qRelationship.RoleID)
+	}
+
+	return &backendv1.Relationship{
+		Id:             idformat.Relationship.Format(qRelationship.ID),
+		OrganizationId: idformat.Organization.Format(qRelationship.OrganizationID),
+		ResourceType:   resourceType,
+		ResourceId:     qRelationship.ResourceID,
+		Relation:       relation,
+		SubjectId:      subjectID,
+		CreateTime:     timestampOrNil(qRelationship.CreateTime),
+	}
+}
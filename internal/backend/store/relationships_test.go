@@ -0,0 +1,255 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func TestRelationships(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "org",
+		ApiKeysEnabled: refOrNil(true),
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+	otherUserID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "other@example.com",
+	})
+
+	_, err := u.Store.UpdateRBACPolicy(ctx, &backendv1.UpdateRBACPolicyRequest{
+		RbacPolicy: &backendv1.RBACPolicy{
+			ResourceTypes: []*backendv1.ResourceType{
+				{
+					Name: "project",
+					Relations: []*backendv1.ResourceRelation{
+						{Name: "owner"},
+						{Name: "editor", ImpliedBy: []string{"owner"}},
+						{Name: "viewer", ImpliedBy: []string{"editor"}},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// relations may only be implied by relations on the same resource type
+	_, err = u.Store.UpdateRBACPolicy(ctx, &backendv1.UpdateRBACPolicyRequest{
+		RbacPolicy: &backendv1.RBACPolicy{
+			ResourceTypes: []*backendv1.ResourceType{
+				{
+					Name:      "project",
+					Relations: []*backendv1.ResourceRelation{{Name: "viewer", ImpliedBy: []string{"admin"}}},
+				},
+			},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	getPolicyResp, err := u.Store.GetRBACPolicy(ctx, &backendv1.GetRBACPolicyRequest{})
+	require.NoError(t, err)
+	require.Len(t, getPolicyResp.RbacPolicy.ResourceTypes, 1)
+	require.Len(t, getPolicyResp.RbacPolicy.ResourceTypes[0].Relations, 3)
+
+	createResp, err := u.Store.CreateRelationship(ctx, &backendv1.CreateRelationshipRequest{
+		Relationship: &backendv1.Relationship{
+			OrganizationId: orgID,
+			ResourceType:   "project",
+			ResourceId:     "x",
+			Relation:       "editor",
+			SubjectId:      userID,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "editor", createResp.Relationship.Relation)
+	require.Equal(t, userID, createResp.Relationship.SubjectId)
+
+	_, err = u.Store.CreateRelationship(ctx, &backendv1.CreateRelationshipRequest{
+		Relationship: &backendv1.Relationship{
+			OrganizationId: orgID,
+			ResourceType:   "project",
+			ResourceId:     "x",
+			Relation:       "editor",
+			SubjectId:      userID,
+		},
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeAlreadyExists, connectErr.Code())
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			OrganizationId: orgID,
+			DisplayName:    "auditors",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateRelationship(ctx, &backendv1.CreateRelationshipRequest{
+		Relationship: &backendv1.Relationship{
+			OrganizationId: orgID,
+			ResourceType:   "project",
+			ResourceId:     "y",
+			Relation:       "viewer",
+			SubjectId:      roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId: userID,
+			RoleId: roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	// editors are viewers; members of the role are viewers of y
+	checkResp, err := u.Store.BatchCheckRelationships(ctx, &backendv1.BatchCheckRelationshipsRequest{
+		Checks: []*backendv1.CheckRelationshipRequest{
+			{SubjectId: userID, ResourceType: "project", ResourceId: "x", Relation: "viewer"},
+			{SubjectId: userID, ResourceType: "project", ResourceId: "x", Relation: "owner"},
+			{SubjectId: userID, ResourceType: "project", ResourceId: "y", Relation: "viewer"},
+			{SubjectId: userID, ResourceType: "project", ResourceId: "y", Relation: "editor"},
+			{SubjectId: otherUserID, ResourceType: "project", ResourceId: "x", Relation: "viewer"},
+		},
+	})
+	require.NoError(t, err)
+
+	var allowed []bool
+	for _, result := range checkResp.Results {
+		allowed = append(allowed, result.Allowed)
+	}
+	require.Equal(t, []bool{true, false, true, false, false}, allowed)
+
+	listResp, err := u.Store.ListRelationshipObjects(ctx, &backendv1.ListRelationshipObjectsRequest{
+		SubjectId:    userID,
+		ResourceType: "project",
+		Relation:     "viewer",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"x", "y"}, listResp.ResourceIds)
+	require.Empty(t, listResp.NextPageToken)
+
+	_, err = u.Store.CheckRelationship(ctx, &backendv1.CheckRelationshipRequest{
+		SubjectId:    userID,
+		ResourceType: "project",
+		ResourceId:   "x",
+		Relation:     "unknown",
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	// api keys have the relations granted to them directly
+	apiKeyResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateRelationship(ctx, &backendv1.CreateRelationshipRequest{
+		Relationship: &backendv1.Relationship{
+			OrganizationId: orgID,
+			ResourceType:   "project",
+			ResourceId:     "z",
+			Relation:       "owner",
+			SubjectId:      apiKeyResp.ApiKey.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	apiKeyCheckResp, err := u.Store.CheckRelationship(ctx, &backendv1.CheckRelationshipRequest{
+		SubjectId:    apiKeyResp.ApiKey.Id,
+		ResourceType: "project",
+		ResourceId:   "z",
+		Relation:     "viewer",
+	})
+	require.NoError(t, err)
+	require.True(t, apiKeyCheckResp.Allowed)
+
+	listRelationshipsResp, err := u.Store.ListRelationships(ctx, &backendv1.ListRelationshipsRequest{
+		OrganizationId: orgID,
+		ResourceType:   "project",
+		ResourceId:     "x",
+	})
+	require.NoError(t, err)
+	require.Len(t, listRelationshipsResp.Relationships, 1)
+
+	_, err = u.Store.DeleteRelationship(ctx, &backendv1.DeleteRelationshipRequest{Id: createResp.Relationship.Id})
+	require.NoError(t, err)
+
+	checkAfterDeleteResp, err := u.Store.CheckRelationship(ctx, &backendv1.CheckRelationshipRequest{
+		SubjectId:    userID,
+		ResourceType: "project",
+		ResourceId:   "x",
+		Relation:     "viewer",
+	})
+	require.NoError(t, err)
+	require.False(t, checkAfterDeleteResp.Allowed)
+
+	// removing a resource type from the policy removes its relationships
+	_, err = u.Store.UpdateRBACPolicy(ctx, &backendv1.UpdateRBACPolicyRequest{
+		RbacPolicy: &backendv1.RBACPolicy{},
+	})
+	require.NoError(t, err)
+
+	listRelationshipsResp, err = u.Store.ListRelationships(ctx, &backendv1.ListRelationshipsRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+	require.Empty(t, listRelationshipsResp.Relationships)
+}
+
+func TestCreateRelationship_ProjectRoleInEveryOrganization(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "org",
+	})
+	otherOrgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "other",
+	})
+
+	_, err := u.Store.UpdateRBACPolicy(ctx, &backendv1.UpdateRBACPolicyRequest{
+		RbacPolicy: &backendv1.RBACPolicy{
+			ResourceTypes: []*backendv1.ResourceType{
+				{
+					Name:      "project",
+					Relations: []*backendv1.ResourceRelation{{Name: "viewer"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "auditors",
+		},
+	})
+	require.NoError(t, err)
+
+	// the same tuple may exist once per organization
+	for _, id := range []string{orgID, otherOrgID} {
+		_, err := u.Store.CreateRelationship(ctx, &backendv1.CreateRelationshipRequest{
+			Relationship: &backendv1.Relationship{
+				OrganizationId: id,
+				ResourceType:   "project",
+				ResourceId:     "x",
+				Relation:       "viewer",
+				SubjectId:      roleResp.Role.Id,
+			},
+		})
+		require.NoError(t, err)
+	}
+}
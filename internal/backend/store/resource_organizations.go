@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// GetResourceOrganizationID returns the ID of the organization that owns the
+// resource identified by id, which may be the ID of an organization or of a
+// resource within one. It returns nil for roles that belong to the project
+// rather than an organization.
+func (s *Store) GetResourceOrganizationID(ctx context.Context, id string) (*uuid.UUID, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	projectID := authn.ProjectID(ctx)
+
+	if orgID, err := idformat.Organization.Parse(id); err == nil {
+		qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+			ProjectID: projectID,
+			ID:        orgID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("organization", err)
+		}
+		return &qOrg.ID, nil
+	}
+
+	if userID, err := idformat.User.Parse(id); err == nil {
+		return getUserOrganizationID(ctx, q, userID)
+	}
+
+	if samlConnectionID, err := idformat.SAMLConnection.Parse(id); err == nil {
+		qSAMLConnection, err := q.GetSAMLConnection(ctx, queries.GetSAMLConnectionParams{
+			ProjectID: projectID,
+			ID:        samlConnectionID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("saml connection", err)
+		}
+		return &qSAMLConnection.OrganizationID, nil
+	}
+
+	if oidcConnectionID, err := idformat.OIDCConnection.Parse(id); err == nil {
+		qOIDCConnection, err := q.GetOIDCConnection(ctx, queries.GetOIDCConnectionParams{
+			ProjectID: projectID,
+			ID:        oidcConnectionID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("oidc connection", err)
+		}
+		return &qOIDCConnection.OrganizationID, nil
+	}
+
+	if scimAPIKeyID, err := idformat.SCIMAPIKey.Parse(id); err == nil {
+		qSCIMAPIKey, err := q.GetSCIMAPIKey(ctx, queries.GetSCIMAPIKeyParams{
+			ProjectID: projectID,
+			ID:        scimAPIKeyID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("scim api key", err)
+		}
+		return &qSCIMAPIKey.OrganizationID, nil
+	}
+
+	if passkeyID, err := idformat.Passkey.Parse(id); err == nil {
+		qPasskey, err := q.GetPasskey(ctx, queries.GetPasskeyParams{
+			ProjectID: projectID,
+			ID:        passkeyID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("passkey", err)
+		}
+		return getUserOrganizationID(ctx, q, qPasskey.UserID)
+	}
+
+	if sessionID, err := idformat.Session.Parse(id); err == nil {
+		qSession, err := q.GetSession(ctx, queries.GetSessionParams{
+			ProjectID: projectID,
+			ID:        sessionID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("session", err)
+		}
+		return getUserOrganizationID(ctx, q, qSession.UserID)
+	}
+
+	if userInviteID, err := idformat.UserInvite.Parse(id); err == nil {
+		qUserInvite, err := q.GetUserInvite(ctx, queries.GetUserInviteParams{
+			ProjectID: projectID,
+			ID:        userInviteID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("user invite", err)
+		}
+		return &qUserInvite.OrganizationID, nil
+	}
+
+	if roleID, err := idformat.Role.Parse(id); err == nil {
+		qRole, err := q.GetRole(ctx, queries.GetRoleParams{
+			ProjectID: projectID,
+			ID:        roleID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("role", err)
+		}
+		return qRole.OrganizationID, nil
+	}
+
+	if userRoleAssignmentID, err := idformat.UserRoleAssignment.Parse(id); err == nil {
+		qUserRoleAssignment, err := q.GetUserRoleAssignment(ctx, queries.GetUserRoleAssignmentParams{
+			ProjectID: projectID,
+			ID:        userRoleAssignmentID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("user role assignment", err)
+		}
+		return getUserOrganizationID(ctx, q, qUserRoleAssignment.UserID)
+	}
+
+	if relationshipID, err := idformat.Relationship.Parse(id); err == nil {
+		qRelationship, err := q.GetRelationship(ctx, queries.GetRelationshipParams{
+			ProjectID: projectID,
+			ID:        relationshipID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("relationship", err)
+		}
+		return &qRelationship.OrganizationID, nil
+	}
+
+	if apiKeyID, err := idformat.APIKey.Parse(id); err == nil {
+		return getAPIKeyOrganizationID(ctx, q, apiKeyID)
+	}
+
+	if apiKeyRoleAssignmentID, err := idformat.APIKeyRoleAssignment.Parse(id); err == nil {
+		qAPIKeyRoleAssignment, err := q.GetAPIKeyRoleAssignment(ctx, queries.GetAPIKeyRoleAssignmentParams{
+			ProjectID: projectID,
+			ID:        apiKeyRoleAssignmentID,
+		})
+		if err != nil {
+			return nil, resourceOrganizationError("api key role assignment", err)
+		}
+		return getAPIKeyOrganizationID(ctx, q, qAPIKeyRoleAssignment.ApiKeyID)
+	}
+
+	return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("unsupported resource id: %q", id))
+}
+
+func getUserOrganizationID(ctx context.Context, q *queries.Queries, userID uuid.UUID) (*uuid.UUID, error) {
+	qUser, err := q.GetUser(ctx, queries.GetUserParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        userID,
+	})
+	if err != nil {
+		return nil, resourceOrganizationError("user", err)
+	}
+	return &qUser.OrganizationID, nil
+}
+
+func getAPIKeyOrganizationID(ctx context.Context, q *queries.Queries, apiKeyID uuid.UUID) (*uuid.UUID, error) {
+	qAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        apiKeyID,
+	})
+	if err != nil {
+		return nil, resourceOrganizationError("api key", err)
+	}
+	return &qAPIKey.OrganizationID, nil
+}
+
+func resourceOrganizationError(resource string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apierror.NewNotFoundError(fmt.Sprintf("%s not found", resource), fmt.Errorf("get %s: %w", resource, err))
+	}
+	return fmt.Errorf("get %s: %w", resource, err)
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestGetResourceOrganizationID(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "Test Organization",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+	passkeyID := u.newPasskey(t, userID)
+
+	for _, id := range []string{orgID, userID, passkeyID} {
+		resourceOrgID, err := u.Store.GetResourceOrganizationID(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, resourceOrgID)
+		require.Equal(t, orgID, idformat.Organization.Format(*resourceOrgID))
+	}
+
+	roleRes, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "Project Role",
+		},
+	})
+	require.NoError(t, err)
+
+	resourceOrgID, err := u.Store.GetResourceOrganizationID(ctx, roleRes.Role.Id)
+	require.NoError(t, err)
+	require.Nil(t, resourceOrgID)
+}
+
+func TestGetResourceOrganizationID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.GetResourceOrganizationID(ctx, idformat.User.Format(uuid.New()))
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+
+	_, err = u.Store.GetResourceOrganizationID(ctx, idformat.WebhookEndpoint.Format(uuid.New()))
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -73,6 +76,11 @@ func (s *Store) ListRoles(ctx context.Context, req *backendv1.ListRolesRequest)
 		return nil, fmt.Errorf("batch get role actions by role ids: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, qRoleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role ids: %w", err)
+	}
+
 	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
@@ -80,7 +88,7 @@ func (s *Store) ListRoles(ctx context.Context, req *backendv1.ListRolesRequest)
 
 	var roles []*backendv1.Role
 	for _, qRole := range qRoles {
-		roles = append(roles, parseRole(qRole, qRoleActions, qActions))
+		roles = append(roles, parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions))
 	}
 
 	var nextPageToken string
@@ -124,12 +132,17 @@ func (s *Store) GetRole(ctx context.Context, req *backendv1.GetRoleRequest) (*ba
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
 	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
-	return &backendv1.GetRoleResponse{Role: parseRole(qRole, qRoleActions, qActions)}, nil
+	return &backendv1.GetRoleResponse{Role: parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions)}, nil
 }
 
 func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest) (*backendv1.CreateRoleResponse, error) {
@@ -167,32 +180,33 @@ func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
-	var qActionIDs []uuid.UUID
-	for _, action := range req.Role.Actions {
-		var ok bool
-		for _, qAction := range qActions {
-			if qAction.Name == action {
-				qActionIDs = append(qActionIDs, qAction.ID)
-				ok = true
-				break
-			}
-		}
-		if !ok {
-			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action %q", action), fmt.Errorf("action %q not found", action))
-		}
+	qActionIDs, actionWildcards, err := parseRoleActions(req.Role.Actions, qActions)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultRole := derefOrEmpty(req.Role.DefaultRole)
+	if defaultRole && roleOrganizationID != nil {
+		return nil, apierror.NewInvalidArgumentError("only project-level roles can be default roles", fmt.Errorf("default role belongs to an organization"))
 	}
 
 	qRole, err := q.CreateRole(ctx, queries.CreateRoleParams{
-		ID:             uuid.New(),
-		ProjectID:      authn.ProjectID(ctx),
-		OrganizationID: roleOrganizationID,
-		DisplayName:    req.Role.DisplayName,
-		Description:    req.Role.Description,
+		ID:              uuid.New(),
+		ProjectID:       authn.ProjectID(ctx),
+		OrganizationID:  roleOrganizationID,
+		DisplayName:     req.Role.DisplayName,
+		Description:     req.Role.Description,
+		ActionWildcards: actionWildcards,
+		DefaultRole:     defaultRole,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create role: %w", err)
 	}
 
+	if err := s.setRoleInheritedRoles(ctx, q, qRole, req.Role.InheritedRoleIds); err != nil {
+		return nil, err
+	}
+
 	for _, actionID := range qActionIDs {
 		if err := q.UpsertRoleAction(ctx, queries.UpsertRoleActionParams{
 			ID:       uuid.New(),
@@ -208,12 +222,17 @@ func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
 	auditRole, err := s.auditlogStore.GetRole(ctx, tx, qRole.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit role: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.roles.create",
 		EventDetails: &auditlogv1.CreateRole{
 			Role: auditRole,
@@ -229,7 +248,7 @@ func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest
 		return nil, fmt.Errorf("commit: %w", err)
 	}
 
-	return &backendv1.CreateRoleResponse{Role: parseRole(qRole, qRoleActions, qActions)}, nil
+	return &backendv1.CreateRoleResponse{Role: parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions)}, nil
 }
 
 func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest) (*backendv1.UpdateRoleResponse, error) {
@@ -278,23 +297,23 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 		updates.Description = req.Role.Description
 	}
 
-	if req.Role.Actions != nil {
-		var qActionIDs []uuid.UUID
-		for _, action := range req.Role.Actions {
-			var ok bool
-			for _, qAction := range qActions {
-				if qAction.Name == action {
-					qActionIDs = append(qActionIDs, qAction.ID)
-					ok = true
-					break
-				}
-			}
+	updates.DefaultRole = qRole.DefaultRole
+	if req.Role.DefaultRole != nil {
+		if *req.Role.DefaultRole && qRole.OrganizationID != nil {
+			return nil, apierror.NewInvalidArgumentError("only project-level roles can be default roles", fmt.Errorf("default role belongs to an organization"))
+		}
+		updates.DefaultRole = *req.Role.DefaultRole
+	}
 
-			if !ok {
-				return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action %q", action), fmt.Errorf("action %q not found", action))
-			}
+	updates.ActionWildcards = qRole.ActionWildcards
+	if req.Role.Actions != nil {
+		qActionIDs, actionWildcards, err := parseRoleActions(req.Role.Actions, qActions)
+		if err != nil {
+			return nil, err
 		}
 
+		updates.ActionWildcards = actionWildcards
+
 		for _, qActionID := range qActionIDs {
 			if err := q.UpsertRoleAction(ctx, queries.UpsertRoleActionParams{
 				ID:       uuid.New(),
@@ -313,6 +332,12 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 		}
 	}
 
+	if req.Role.InheritedRoleIds != nil {
+		if err := s.setRoleInheritedRoles(ctx, q, qRole, req.Role.InheritedRoleIds); err != nil {
+			return nil, err
+		}
+	}
+
 	qUpdatedRole, err := q.UpdateRole(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update role: %w", err)
@@ -328,7 +353,12 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qUpdatedRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.roles.update",
 		EventDetails: &auditlogv1.UpdateRole{
 			Role:         auditRole,
@@ -345,7 +375,7 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 		return nil, fmt.Errorf("commit: %w", err)
 	}
 
-	return &backendv1.UpdateRoleResponse{Role: parseRole(qUpdatedRole, qRoleActions, qActions)}, nil
+	return &backendv1.UpdateRoleResponse{Role: parseRole(qUpdatedRole, qRoleActions, qRoleInheritedRoles, qActions)}, nil
 }
 
 func (s *Store) DeleteRole(ctx context.Context, req *backendv1.DeleteRoleRequest) (*backendv1.DeleteRoleResponse, error) {
@@ -380,7 +410,7 @@ func (s *Store) DeleteRole(ctx context.Context, req *backendv1.DeleteRoleRequest
 		return nil, fmt.Errorf("delete role: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.roles.delete",
 		EventDetails: &auditlogv1.DeleteRole{
 			Role: auditRole,
@@ -399,7 +429,102 @@ func (s *Store) DeleteRole(ctx context.Context, req *backendv1.DeleteRoleRequest
 	return &backendv1.DeleteRoleResponse{}, nil
 }
 
-func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qActions []queries.Action) *backendv1.Role {
+// actionWildcardPattern matches the wildcards a role may grant actions with:
+// "*", "x.*" or "x.y.*".
+var actionWildcardPattern = regexp.MustCompile(`^([a-z0-9_]+\.){0,2}\*$`)
+
+// parseRoleActions splits the actions requested for a role into the IDs of the
+// qActions they name and wildcards.
+func parseRoleActions(actions []string, qActions []queries.Action) ([]uuid.UUID, []string, error) {
+	var qActionIDs []uuid.UUID
+	actionWildcards := []string{}
+	for _, action := range actions {
+		if strings.HasSuffix(action, "*") {
+			if !actionWildcardPattern.MatchString(action) {
+				return nil, nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action wildcard %q", action), fmt.Errorf("invalid action wildcard %q", action))
+			}
+
+			if !slices.Contains(actionWildcards, action) {
+				actionWildcards = append(actionWildcards, action)
+			}
+			continue
+		}
+
+		var ok bool
+		for _, qAction := range qActions {
+			if qAction.Name == action {
+				qActionIDs = append(qActionIDs, qAction.ID)
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action %q", action), fmt.Errorf("action %q not found", action))
+		}
+	}
+
+	return qActionIDs, actionWildcards, nil
+}
+
+// setRoleInheritedRoles replaces the roles qRole inherits with the roles
+// identified by inheritedRoleIDs.
+func (s *Store) setRoleInheritedRoles(ctx context.Context, q *queries.Queries, qRole queries.Role, inheritedRoleIDs []string) error {
+	var qInheritedRoleIDs []uuid.UUID
+	for _, inheritedRoleID := range inheritedRoleIDs {
+		roleID, err := idformat.Role.Parse(inheritedRoleID)
+		if err != nil {
+			return apierror.NewInvalidArgumentError("invalid inherited role id", fmt.Errorf("parse inherited role id: %w", err))
+		}
+
+		qInheritedRole, err := q.GetRole(ctx, queries.GetRoleParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        roleID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return apierror.NewNotFoundError("inherited role not found", fmt.Errorf("get inherited role: %w", err))
+			}
+			return fmt.Errorf("get inherited role: %w", err)
+		}
+
+		// roles may inherit project-level roles, or roles in their own
+		// organization
+		if qInheritedRole.OrganizationID != nil && (qRole.OrganizationID == nil || *qInheritedRole.OrganizationID != *qRole.OrganizationID) {
+			return apierror.NewInvalidArgumentError("inherited role belongs to a different organization", fmt.Errorf("inherited role belongs to a different organization"))
+		}
+
+		if !slices.Contains(qInheritedRoleIDs, qInheritedRole.ID) {
+			qInheritedRoleIDs = append(qInheritedRoleIDs, qInheritedRole.ID)
+		}
+	}
+
+	qTransitivelyInheritedRoleIDs, err := q.ListTransitivelyInheritedRoleIDs(ctx, qInheritedRoleIDs)
+	if err != nil {
+		return fmt.Errorf("list transitively inherited role ids: %w", err)
+	}
+
+	if slices.Contains(qTransitivelyInheritedRoleIDs, qRole.ID) {
+		return apierror.NewInvalidArgumentError("a role cannot inherit itself, directly or indirectly", fmt.Errorf("role inheritance cycle"))
+	}
+
+	if err := q.DeleteRoleInheritedRoles(ctx, qRole.ID); err != nil {
+		return fmt.Errorf("delete role inherited roles: %w", err)
+	}
+
+	for _, qInheritedRoleID := range qInheritedRoleIDs {
+		if err := q.CreateRoleInheritedRole(ctx, queries.CreateRoleInheritedRoleParams{
+			ID:              uuid.New(),
+			RoleID:          qRole.ID,
+			InheritedRoleID: qInheritedRoleID,
+		}); err != nil {
+			return fmt.Errorf("create role inherited role: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qRoleInheritedRoles []queries.RoleInheritedRole, qActions []queries.Action) *backendv1.Role {
 	var orgID string
 	if qRole.OrganizationID != nil {
 		orgID = idformat.Organization.Format(*qRole.OrganizationID)
@@ -419,13 +544,24 @@ func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qActions [
 		}
 	}
 
+	actions = append(actions, qRole.ActionWildcards...)
+
+	var inheritedRoleIDs []string
+	for _, qRoleInheritedRole := range qRoleInheritedRoles {
+		if qRoleInheritedRole.RoleID == qRole.ID {
+			inheritedRoleIDs = append(inheritedRoleIDs, idformat.Role.Format(qRoleInheritedRole.InheritedRoleID))
+		}
+	}
+
 	return &backendv1.Role{
-		Id:             idformat.Role.Format(qRole.ID),
-		OrganizationId: orgID,
-		CreateTime:     timestamppb.New(*qRole.CreateTime),
-		UpdateTime:     timestamppb.New(*qRole.UpdateTime),
-		DisplayName:    qRole.DisplayName,
-		Description:    qRole.Description,
-		Actions:        actions,
+		Id:               idformat.Role.Format(qRole.ID),
+		OrganizationId:   orgID,
+		CreateTime:       timestamppb.New(*qRole.CreateTime),
+		UpdateTime:       timestamppb.New(*qRole.UpdateTime),
+		DisplayName:      qRole.DisplayName,
+		Description:      qRole.Description,
+		Actions:          actions,
+		InheritedRoleIds: inheritedRoleIDs,
+		DefaultRole:      &qRole.DefaultRole,
 	}
 }
@@ -232,3 +232,131 @@ INSERT INTO actions (id, project_id, name, description)
 	}
 	require.ElementsMatch(t, organizationRoleIDs, orgIDs)
 }
+
+func TestRole_InheritanceWildcardsAndDefaults(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "org",
+		ApiKeysEnabled: refOrNil(true),
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2),
+  		 (gen_random_uuid(), $1::uuid, $3, $3),
+  		 (gen_random_uuid(), $1::uuid, $4, $4),
+  		 (gen_random_uuid(), $1::uuid, $5, $5);
+`,
+		uuid.UUID(projectID).String(),
+		"billing.invoices.read",
+		"billing.invoices.write",
+		"docs.pages.read",
+		"profile.self.read",
+	)
+	require.NoError(t, err)
+
+	defaultResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "default",
+			Actions:     []string{"profile.self.read"},
+			DefaultRole: refOrNil(true),
+		},
+	})
+	require.NoError(t, err)
+
+	baseResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName:      "base",
+			Actions:          []string{"docs.pages.read"},
+			InheritedRoleIds: []string{defaultResp.Role.Id},
+		},
+	})
+	require.NoError(t, err)
+
+	// inheritance must not be cyclic
+	_, err = u.Store.UpdateRole(ctx, &backendv1.UpdateRoleRequest{
+		Id: defaultResp.Role.Id,
+		Role: &backendv1.Role{
+			InheritedRoleIds: []string{baseResp.Role.Id},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	billingResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			OrganizationId:   orgID,
+			DisplayName:      "billing",
+			Actions:          []string{"billing.*"},
+			InheritedRoleIds: []string{baseResp.Role.Id},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"billing.*"}, billingResp.Role.Actions)
+	require.Equal(t, []string{baseResp.Role.Id}, billingResp.Role.InheritedRoleIds)
+
+	// project-level roles cannot inherit organization roles
+	_, err = u.Store.UpdateRole(ctx, &backendv1.UpdateRoleRequest{
+		Id: baseResp.Role.Id,
+		Role: &backendv1.Role{
+			InheritedRoleIds: []string{billingResp.Role.Id},
+		},
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	// organization roles cannot be default roles
+	_, err = u.Store.UpdateRole(ctx, &backendv1.UpdateRoleRequest{
+		Id:   billingResp.Role.Id,
+		Role: &backendv1.Role{DefaultRole: refOrNil(true)},
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	_, err = u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "invalid",
+			Actions:     []string{"billing.inv*"},
+		},
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId: userID,
+			RoleId: billingResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	// personal api keys have their owner's actions, resolved the same way as
+	// for access tokens
+	apiKeyResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "personal",
+			UserId:         &userID,
+		},
+	})
+	require.NoError(t, err)
+
+	authResp, err := u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: apiKeyResp.ApiKey.SecretToken,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"billing.invoices.read",
+		"billing.invoices.write",
+		"docs.pages.read",
+		"profile.self.read",
+	}, authResp.Actions)
+}
@@ -199,7 +199,7 @@ func (s *Store) CreateSAMLConnection(ctx context.Context, req *backendv1.CreateS
 	}
 
 	samlConnection := parseSAMLConnection(qProject, qSAMLConnection)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.saml_connections.create",
 		EventDetails: &auditlogv1.CreateSAMLConnection{
 			SamlConnection: auditSAMLConnection,
@@ -315,7 +315,7 @@ func (s *Store) UpdateSAMLConnection(ctx context.Context, req *backendv1.UpdateS
 		return nil, fmt.Errorf("get audit saml connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.saml_connections.update",
 		EventDetails: &auditlogv1.UpdateSAMLConnection{
 			SamlConnection:         auditSAMLConnection,
@@ -369,7 +369,7 @@ func (s *Store) DeleteSAMLConnection(ctx context.Context, req *backendv1.DeleteS
 		return nil, fmt.Errorf("delete saml connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.saml_connections.delete",
 		EventDetails: &auditlogv1.DeleteSAMLConnection{
 			SamlConnection: auditSAMLConnection,
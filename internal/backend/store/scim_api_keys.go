@@ -148,7 +148,7 @@ func (s *Store) CreateSCIMAPIKey(ctx context.Context, req *backendv1.CreateSCIMA
 	}
 
 	scimAPIKey := parseSCIMAPIKey(qSCIMAPIKey)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.scim_api_keys.create",
 		EventDetails: &auditlogv1.CreateSCIMAPIKey{
 			ScimApiKey: auditSCIMAPIKey,
@@ -217,7 +217,7 @@ func (s *Store) UpdateSCIMAPIKey(ctx context.Context, req *backendv1.UpdateSCIMA
 		return nil, fmt.Errorf("get audit scim api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.scim_api_keys.update",
 		EventDetails: &auditlogv1.UpdateSCIMAPIKey{
 			ScimApiKey:         auditSCIMAPIKey,
@@ -275,7 +275,7 @@ func (s *Store) DeleteSCIMAPIKey(ctx context.Context, req *backendv1.DeleteSCIMA
 		return nil, fmt.Errorf("delete scim api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.scim_api_keys.delete",
 		EventDetails: &auditlogv1.DeleteSCIMAPIKey{
 			ScimApiKey: auditSCIMAPIKey,
@@ -334,7 +334,7 @@ func (s *Store) RevokeSCIMAPIKey(ctx context.Context, req *backendv1.RevokeSCIMA
 		return nil, fmt.Errorf("get audit scim api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.scim_api_keys.revoke",
 		EventDetails: &auditlogv1.RevokeSCIMAPIKey{
 			ScimApiKey:         auditSCIMAPIKey,
@@ -113,6 +113,8 @@ func parseSession(qSession queries.Session) *backendv1.Session {
 		primaryAuthFactor = backendv1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_SAML
 	case "oidc":
 		primaryAuthFactor = backendv1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_OIDC
+	case "passkey":
+		primaryAuthFactor = backendv1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_PASSKEY
 	case "impersonation":
 		primaryAuthFactor = backendv1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_IMPERSONATION
 	}
@@ -22,6 +22,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/kms"
 	"github.com/tesseral-labs/tesseral/internal/oidcclient"
 	"github.com/tesseral-labs/tesseral/internal/pagetoken"
+	"github.com/tesseral-labs/tesseral/internal/webauthn"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -34,6 +35,8 @@ type Store struct {
 	microsoftOAuthClientSecretsKMS *kms.KMS
 	githubOAuthClientSecretsKMS    *kms.KMS
 	oidcClientSecretsKMS           *kms.KMS
+	webhookSigningSecretsKMS       *kms.KMS
+	auditLogStreamSecretsKMS       *kms.KMS
 	ses                            *sesv2.Client
 	cloudflare                     *cloudflare.Client
 	cloudflareDOH                  *cloudflaredoh.Client
@@ -52,7 +55,11 @@ type Store struct {
 	svixClient                     *svix.Svix
 	auditlogStore                  *auditlogstore.Store
 	oidc                           *oidcclient.Client
+	fidoMetadata                   *webauthn.Metadata
 	riverClient                    *river.Client[pgx.Tx]
+	backendAPIKeyUsage             backendAPIKeyUsage
+	apiKeyUsage                    apiKeyUsage
+	sessionPublicKeyCache          sessionPublicKeyCache
 }
 
 type NewStoreParams struct {
@@ -64,6 +71,8 @@ type NewStoreParams struct {
 	MicrosoftOAuthClientSecretsKMS *kms.KMS
 	GithubOAuthClientSecretsKMS    *kms.KMS
 	OIDCClientSecretsKMS           *kms.KMS
+	WebhookSigningSecretsKMS       *kms.KMS
+	AuditLogStreamSecretsKMS       *kms.KMS
 	SES                            *sesv2.Client
 	Cloudflare                     *cloudflare.Client
 	CloudflareDOH                  *cloudflaredoh.Client
@@ -80,6 +89,7 @@ type NewStoreParams struct {
 	SvixClient                     *svix.Svix
 	AuditlogStore                  *auditlogstore.Store
 	OIDCClient                     *oidcclient.Client
+	FIDOMetadata                   *webauthn.Metadata
 	RiverClient                    *river.Client[pgx.Tx]
 }
 
@@ -93,6 +103,8 @@ func New(p NewStoreParams) *Store {
 		microsoftOAuthClientSecretsKMS: p.MicrosoftOAuthClientSecretsKMS,
 		githubOAuthClientSecretsKMS:    p.GithubOAuthClientSecretsKMS,
 		oidcClientSecretsKMS:           p.OIDCClientSecretsKMS,
+		webhookSigningSecretsKMS:       p.WebhookSigningSecretsKMS,
+		auditLogStreamSecretsKMS:       p.AuditLogStreamSecretsKMS,
 		ses:                            p.SES,
 		cloudflare:                     p.Cloudflare,
 		cloudflareDOH:                  p.CloudflareDOH,
@@ -111,6 +123,7 @@ func New(p NewStoreParams) *Store {
 		svixClient:                     p.SvixClient,
 		auditlogStore:                  p.AuditlogStore,
 		oidc:                           p.OIDCClient,
+		fidoMetadata:                   p.FIDOMetadata,
 		riverClient:                    p.RiverClient,
 	}
 
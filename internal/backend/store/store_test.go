@@ -43,10 +43,13 @@ func newTestUtil(t *testing.T) (context.Context, *testUtil) {
 		MicrosoftOAuthClientSecretsKMS: environment.KMS.MicrosoftOAuthClientSecretsKMS,
 		GithubOAuthClientSecretsKMS:    environment.KMS.GithubOAuthClientSecretsKMS,
 		OIDCClientSecretsKMS:           environment.KMS.OIDCClientSecretsKMS,
+		WebhookSigningSecretsKMS:       environment.KMS.WebhookSigningSecretsKMS,
+		AuditLogStreamSecretsKMS:       environment.KMS.AuditLogStreamSecretsKMS,
 		ConsoleProjectID:               environment.ConsoleProjectID,
 		ConsoleDomain:                  environment.ConsoleDomain,
 		AuthAppsRootDomain:             environment.AuthAppsRootDomain,
 		OIDCClient:                     &oidcclient.Client{HTTPClient: http.DefaultClient},
+		FIDOMetadata:                   storetesting.NewFIDOMetadata("cb69481e-8ff7-4039-93ec-0a2729a154a8", "ee882879-721c-4913-9775-3dfcce97072a"),
 	})
 	commonStore := commonstore.New(commonstore.NewStoreParams{
 		AppAuthRootDomain:     environment.ConsoleDomain,
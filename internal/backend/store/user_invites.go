@@ -170,7 +170,7 @@ func (s *Store) CreateUserInvite(ctx context.Context, req *backendv1.CreateUserI
 		return nil, fmt.Errorf("get audit log user invite: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.user_invites.create",
 		EventDetails: &auditlogv1.CreateUserInvite{
 			UserInvite: auditUserInvite,
@@ -236,7 +236,7 @@ func (s *Store) DeleteUserInvite(ctx context.Context, req *backendv1.DeleteUserI
 		return nil, fmt.Errorf("delete user invite: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.user_invites.delete",
 		EventDetails: &auditlogv1.DeleteUserInvite{
 			UserInvite: auditUserInvite,
@@ -263,5 +263,7 @@ func parseUserInvite(qUserInvite queries.UserInvite) *backendv1.UserInvite {
 		UpdateTime:     timestamppb.New(*qUserInvite.UpdateTime),
 		Email:          qUserInvite.Email,
 		Owner:          qUserInvite.IsOwner,
+		// an unset status parses as unspecified
+		EmailDeliveryStatus: parseEmailEventType(qUserInvite.EmailDeliveryStatus.EmailEventType),
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -12,7 +13,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
-	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 )
@@ -182,11 +183,21 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *backendv1.Cre
 		return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
 	}
 
+	var expireTime *time.Time
+	if req.UserRoleAssignment.ExpireTime != nil {
+		t := req.UserRoleAssignment.ExpireTime.AsTime()
+		if !t.After(time.Now()) {
+			return nil, apierror.NewInvalidArgumentError("expire_time must be in the future", fmt.Errorf("expire time is not in the future"))
+		}
+		expireTime = &t
+	}
+
 	// ensure both role and user belong to project
-	if _, err := q.GetRole(ctx, queries.GetRoleParams{
+	qRole, err := q.GetRole(ctx, queries.GetRoleParams{
 		ProjectID: authn.ProjectID(ctx),
 		ID:        roleID,
-	}); err != nil {
+	})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apierror.NewNotFoundError("role not found", fmt.Errorf("get role: %w", err))
 		}
@@ -204,10 +215,15 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *backendv1.Cre
 		return nil, err
 	}
 
+	if qRole.OrganizationID != nil && *qRole.OrganizationID != qUser.OrganizationID {
+		return nil, apierror.NewInvalidArgumentError("role belongs to a different organization", fmt.Errorf("role belongs to a different organization"))
+	}
+
 	if err := q.UpsertUserRoleAssignment(ctx, queries.UpsertUserRoleAssignmentParams{
-		ID:     uuid.New(),
-		RoleID: roleID,
-		UserID: userID,
+		ID:         uuid.New(),
+		RoleID:     roleID,
+		UserID:     userID,
+		ExpireTime: expireTime,
 	}); err != nil {
 		return nil, fmt.Errorf("upsert user role assignment: %w", err)
 	}
@@ -225,7 +241,7 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *backendv1.Cre
 		return nil, fmt.Errorf("get audit user role assignment: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.assign_role",
 		EventDetails: &auditlogv1.AssignUserRole{
 			UserRoleAssignment: auditUserRoleAssignment,
@@ -292,7 +308,7 @@ func (s *Store) DeleteUserRoleAssignment(ctx context.Context, req *backendv1.Del
 		return nil, fmt.Errorf("delete user role assignment: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.unassign_role",
 		EventDetails: &auditlogv1.UnassignUserRole{
 			UserRoleAssignment: auditUserRoleAssignment,
@@ -316,29 +332,71 @@ func (s *Store) DeleteUserRoleAssignment(ctx context.Context, req *backendv1.Del
 	return &backendv1.DeleteUserRoleAssignmentResponse{}, nil
 }
 
+// assignDefaultRoles assigns a User who has just joined an Organization the
+// Project's default Roles.
+func (s *Store) assignDefaultRoles(ctx context.Context, tx pgx.Tx, q *queries.Queries, qUser queries.User) error {
+	qUserRoleAssignments, err := q.CreateDefaultUserRoleAssignments(ctx, qUser.ID)
+	if err != nil {
+		return fmt.Errorf("create default user role assignments: %w", err)
+	}
+
+	if len(qUserRoleAssignments) == 0 {
+		return nil
+	}
+
+	for _, qUserRoleAssignment := range qUserRoleAssignments {
+		auditUserRoleAssignment, err := s.auditlogStore.GetUserRoleAssignment(ctx, tx, qUserRoleAssignment.ID)
+		if err != nil {
+			return fmt.Errorf("get audit user role assignment: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+			EventName: "tesseral.users.assign_role",
+			EventDetails: &auditlogv1.AssignUserRole{
+				UserRoleAssignment: auditUserRoleAssignment,
+			},
+			OrganizationID: &qUser.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeUser,
+			ResourceID:     &qUser.ID,
+		}); err != nil {
+			return fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	if err := s.sendSyncUserRoleAssignmentsEvent(ctx, tx, qUser); err != nil {
+		return fmt.Errorf("send sync user role assignments event: %w", err)
+	}
+
+	return nil
+}
+
 func parseUserRoleAssignment(qUserRoleAssignment queries.UserRoleAssignment) *backendv1.UserRoleAssignment {
 	return &backendv1.UserRoleAssignment{
-		Id:     idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
-		RoleId: idformat.Role.Format(qUserRoleAssignment.RoleID),
-		UserId: idformat.User.Format(qUserRoleAssignment.UserID),
+		Id:         idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
+		RoleId:     idformat.Role.Format(qUserRoleAssignment.RoleID),
+		UserId:     idformat.User.Format(qUserRoleAssignment.UserID),
+		ExpireTime: timestampOrNil(qUserRoleAssignment.ExpireTime),
 	}
 }
 
 func (s *Store) sendSyncUserRoleAssignmentsEvent(ctx context.Context, tx pgx.Tx, qUser queries.User) error {
-	// Add the sync organization event to the background worker queue
-	jobInsertRes, err := s.riverClient.InsertTx(ctx, tx, webhookworker.Args{
-		ProjectID: idformat.Project.Format(authn.ProjectID(ctx)),
-		EventName: "sync.user_role_assignments",
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	userID := idformat.User.Format(qUser.ID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: userID,
+		EventType:   "sync.user_role_assignments",
 		Payload: map[string]any{
 			"type":   "sync.user_role_assignments",
-			"userId": idformat.User.Format(qUser.ID),
+			"userId": userID,
 		},
-	}, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("insert job: %w", err)
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
 	}
 
-	slog.InfoContext(ctx, "webhook_worker_job_inserted", "job_id", jobInsertRes.Job.ID, "event_type", "sync.user", "user_id", idformat.User.Format(qUser.ID))
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.user_role_assignments", "user_id", userID)
 
 	return nil
 }
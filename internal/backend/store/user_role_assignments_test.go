@@ -3,12 +3,14 @@ package store
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestCreateUserRoleAssignment_Success(t *testing.T) {
@@ -57,6 +59,102 @@ INSERT INTO actions (id, project_id, name, description)
 	require.Equal(t, roleID, resp.UserRoleAssignment.RoleId)
 }
 
+func TestCreateUserRoleAssignment_ExpireTime(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			OrganizationId: orgID,
+			DisplayName:    "test-role",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId:     userID,
+			RoleId:     roleResp.Role.Id,
+			ExpireTime: timestamppb.New(time.Now().Add(-time.Minute)),
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	expireTime := time.Now().Add(time.Hour).Truncate(time.Second)
+	resp, err := u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId:     userID,
+			RoleId:     roleResp.Role.Id,
+			ExpireTime: timestamppb.New(expireTime),
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, expireTime.Equal(resp.UserRoleAssignment.ExpireTime.AsTime()))
+
+	// expired assignments no longer grant their role, even before they are
+	// removed
+	_, err = u.Store.UpdateRBACPolicy(ctx, &backendv1.UpdateRBACPolicyRequest{
+		RbacPolicy: &backendv1.RBACPolicy{
+			ResourceTypes: []*backendv1.ResourceType{
+				{
+					Name:      "project",
+					Relations: []*backendv1.ResourceRelation{{Name: "viewer"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateRelationship(ctx, &backendv1.CreateRelationshipRequest{
+		Relationship: &backendv1.Relationship{
+			OrganizationId: orgID,
+			ResourceType:   "project",
+			ResourceId:     "x",
+			Relation:       "viewer",
+			SubjectId:      roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	checkReq := &backendv1.CheckRelationshipRequest{
+		SubjectId:    userID,
+		ResourceType: "project",
+		ResourceId:   "x",
+		Relation:     "viewer",
+	}
+	checkResp, err := u.Store.CheckRelationship(ctx, checkReq)
+	require.NoError(t, err)
+	require.True(t, checkResp.Allowed)
+
+	userUUID, err := idformat.User.Parse(userID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), "UPDATE user_role_assignments SET expire_time = now() - interval '1 minute' WHERE user_id = $1::uuid", uuid.UUID(userUUID).String())
+	require.NoError(t, err)
+
+	checkResp, err = u.Store.CheckRelationship(ctx, checkReq)
+	require.NoError(t, err)
+	require.False(t, checkResp.Allowed)
+
+	// assigning the role without an expire time makes the assignment permanent
+	resp, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId: userID,
+			RoleId: roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp.UserRoleAssignment.ExpireTime)
+}
+
 func TestCreateUserRoleAssignment_NotFound(t *testing.T) {
 	t.Parallel()
 
@@ -12,7 +12,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
-	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -123,6 +123,11 @@ func (s *Store) CreateUser(ctx context.Context, req *backendv1.CreateUserRequest
 		return nil, fmt.Errorf("get organization: %w", err)
 	}
 
+	locale, err := parseOptionalLocale(req.User.Locale)
+	if err != nil {
+		return nil, err
+	}
+
 	qUser, err := q.CreateUser(ctx, queries.CreateUserParams{
 		ID:              uuid.New(),
 		OrganizationID:  orgID,
@@ -131,6 +136,7 @@ func (s *Store) CreateUser(ctx context.Context, req *backendv1.CreateUserRequest
 		GoogleUserID:    req.User.GoogleUserId,
 		MicrosoftUserID: req.User.MicrosoftUserId,
 		GithubUserID:    req.User.GithubUserId,
+		Locale:          locale,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create user: %w", err)
@@ -142,7 +148,7 @@ func (s *Store) CreateUser(ctx context.Context, req *backendv1.CreateUserRequest
 	}
 
 	user := parseUser(qUser)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.create",
 		EventDetails: &auditlogv1.CreateUser{
 			User: auditUser,
@@ -159,6 +165,10 @@ func (s *Store) CreateUser(ctx context.Context, req *backendv1.CreateUserRequest
 		return nil, fmt.Errorf("send sync user event: %w", err)
 	}
 
+	if err := s.assignDefaultRoles(ctx, tx, q, qUser); err != nil {
+		return nil, fmt.Errorf("assign default roles: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
@@ -234,6 +244,14 @@ func (s *Store) UpdateUser(ctx context.Context, req *backendv1.UpdateUserRequest
 		updates.ProfilePictureUrl = refOrNil(*req.User.ProfilePictureUrl)
 	}
 
+	updates.Locale = qUser.Locale
+	if req.User.Locale != nil {
+		updates.Locale, err = parseOptionalLocale(req.User.Locale)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	qUpdatedUser, err := q.UpdateUser(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update user: %w", err)
@@ -245,7 +263,7 @@ func (s *Store) UpdateUser(ctx context.Context, req *backendv1.UpdateUserRequest
 	}
 
 	user := parseUser(qUpdatedUser)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.update",
 		EventDetails: &auditlogv1.UpdateUser{
 			User:         auditUser,
@@ -298,11 +316,15 @@ func (s *Store) DeleteUser(ctx context.Context, req *backendv1.DeleteUserRequest
 		return nil, fmt.Errorf("get audit user: %w", err)
 	}
 
+	if err := s.revokeUserAPIKeys(ctx, tx, q, qUser); err != nil {
+		return nil, err
+	}
+
 	if err = q.DeleteUser(ctx, userID); err != nil {
 		return nil, fmt.Errorf("delete user: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.delete",
 		EventDetails: &auditlogv1.DeleteUser{
 			User: auditUser,
@@ -327,20 +349,23 @@ func (s *Store) DeleteUser(ctx context.Context, req *backendv1.DeleteUserRequest
 }
 
 func (s *Store) sendSyncUserEvent(ctx context.Context, tx pgx.Tx, qUser queries.User) error {
-	// Add the sync organization event to the background worker queue
-	jobInsertRes, err := s.riverClient.InsertTx(ctx, tx, webhookworker.Args{
-		ProjectID: idformat.Project.Format(authn.ProjectID(ctx)),
-		EventName: "sync.user",
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	userID := idformat.User.Format(qUser.ID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: userID,
+		EventType:   "sync.user",
 		Payload: map[string]any{
 			"type":   "sync.user",
-			"userId": idformat.User.Format(qUser.ID),
+			"userId": userID,
 		},
-	}, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("insert job: %w", err)
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
 	}
 
-	slog.InfoContext(ctx, "webhook_worker_job_inserted", "job_id", jobInsertRes.Job.ID, "event_type", "sync.user", "user_id", idformat.User.Format(qUser.ID))
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.user", "user_id", userID)
 
 	return nil
 }
@@ -359,5 +384,51 @@ func parseUser(qUser queries.User) *backendv1.User {
 		HasAuthenticatorApp: qUser.AuthenticatorAppSecretCiphertext != nil,
 		DisplayName:         qUser.DisplayName,
 		ProfilePictureUrl:   qUser.ProfilePictureUrl,
+		Locale:              qUser.Locale,
 	}
 }
+
+// revokeUserAPIKeys revokes the personal API keys of qUser, logging an audit
+// event for each. It is called before deleting a user: deletion cascades to
+// the keys, but would leave no record of them being revoked.
+func (s *Store) revokeUserAPIKeys(ctx context.Context, tx pgx.Tx, q *queries.Queries, qUser queries.User) error {
+	apiKeyIDs, err := q.ListUnrevokedUserAPIKeyIDs(ctx, &qUser.ID)
+	if err != nil {
+		return fmt.Errorf("list unrevoked user api key ids: %w", err)
+	}
+
+	var auditPreviousAPIKeys []*auditlogv1.APIKey
+	for _, apiKeyID := range apiKeyIDs {
+		auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+		if err != nil {
+			return fmt.Errorf("get audit log api key: %w", err)
+		}
+		auditPreviousAPIKeys = append(auditPreviousAPIKeys, auditPreviousAPIKey)
+	}
+
+	if err := q.RevokeUserAPIKeys(ctx, &qUser.ID); err != nil {
+		return fmt.Errorf("revoke user api keys: %w", err)
+	}
+
+	for i, apiKeyID := range apiKeyIDs {
+		auditAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+		if err != nil {
+			return fmt.Errorf("get audit log api key: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+			EventName: "tesseral.api_keys.revoke",
+			EventDetails: &auditlogv1.RevokeAPIKey{
+				ApiKey:         auditAPIKey,
+				PreviousApiKey: auditPreviousAPIKeys[i],
+			},
+			OrganizationID: &qUser.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeApiKey,
+			ResourceID:     &apiKeyID,
+		}); err != nil {
+			return fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -36,6 +36,32 @@ func TestCreateUser_Success(t *testing.T) {
 	require.False(t, resp.User.GetOwner())
 }
 
+func TestCreateUser_SendsTypedWebhookEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	resp, err := u.Store.CreateUser(ctx, &backendv1.CreateUserRequest{
+		User: &backendv1.User{
+			OrganizationId: orgID,
+			Email:          "test@example.com",
+		},
+	})
+	require.NoError(t, err)
+
+	var payloadUserID string
+	err = u.Environment.DB.QueryRow(t.Context(), `
+	SELECT args->'Payload'->'data'->'user'->>'id'
+	FROM river_job
+	WHERE kind = 'webhook' AND args->>'EventName' = 'user.created' AND args->>'ProjectID' = $1
+	`, u.ProjectID).Scan(&payloadUserID)
+	require.NoError(t, err)
+	require.Equal(t, resp.User.Id, payloadUserID)
+}
+
 func TestCreateUser_OrgNotFound(t *testing.T) {
 	t.Parallel()
 
@@ -85,6 +111,62 @@ func TestGetUser_NotFound(t *testing.T) {
 	require.Equal(t, connect.CodeNotFound, connectErr.Code())
 }
 
+func TestUpdateUser_SendsOrderedSyncWebhooks(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	createResp, err := u.Store.CreateUser(ctx, &backendv1.CreateUserRequest{
+		User: &backendv1.User{
+			OrganizationId: orgID,
+			Email:          "test@example.com",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.UpdateUser(ctx, &backendv1.UpdateUserRequest{
+		Id: createResp.User.Id,
+		User: &backendv1.User{
+			DisplayName: refOrNil("Updated Name"),
+		},
+	})
+	require.NoError(t, err)
+
+	rows, err := u.Environment.DB.Query(t.Context(), `
+	SELECT sequence, (payload->>'sequence')::bigint
+	FROM webhook_outbox_events
+	WHERE resource_key = $1 AND event_type = 'sync.user'
+	ORDER BY sequence
+	`, createResp.User.Id)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var sequences, payloadSequences []int64
+	for rows.Next() {
+		var sequence, payloadSequence int64
+		require.NoError(t, rows.Scan(&sequence, &payloadSequence))
+		sequences = append(sequences, sequence)
+		payloadSequences = append(payloadSequences, payloadSequence)
+	}
+	require.NoError(t, rows.Err())
+	// a resource's sequence starts from the current time, then counts up
+	require.Len(t, sequences, 2)
+	require.Equal(t, sequences[0]+1, sequences[1])
+	require.Equal(t, sequences, payloadSequences)
+
+	var jobCount int
+	err = u.Environment.DB.QueryRow(t.Context(), `
+	SELECT count(*)
+	FROM river_job
+	WHERE kind = 'webhook_ordered' AND args->>'ResourceKey' = $1
+	`, createResp.User.Id).Scan(&jobCount)
+	require.NoError(t, err)
+	require.Equal(t, 2, jobCount)
+}
+
 func TestUpdateUser_UpdatesFields(t *testing.T) {
 	t.Parallel()
 
@@ -203,3 +285,62 @@ func TestListUsers_Pagination(t *testing.T) {
 	}
 	require.ElementsMatch(t, createdIDs, allIDs)
 }
+
+func TestCreateUser_AssignsDefaultRoles(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	existingUserID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "existing@example.com",
+	})
+
+	defaultResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "default",
+			DefaultRole: refOrNil(true),
+		},
+	})
+	require.NoError(t, err)
+
+	createResp, err := u.Store.CreateUser(ctx, &backendv1.CreateUserRequest{
+		User: &backendv1.User{
+			OrganizationId: orgID,
+			Email:          "test@example.com",
+		},
+	})
+	require.NoError(t, err)
+
+	listResp, err := u.Store.ListUserRoleAssignments(ctx, &backendv1.ListUserRoleAssignmentsRequest{
+		UserId: createResp.User.Id,
+	})
+	require.NoError(t, err)
+	require.Len(t, listResp.UserRoleAssignments, 1)
+	require.Equal(t, defaultResp.Role.Id, listResp.UserRoleAssignments[0].RoleId)
+
+	userID, err := idformat.User.Parse(createResp.User.Id)
+	require.NoError(t, err)
+
+	var assignEvents int
+	err = u.Environment.DB.QueryRow(t.Context(), `
+	SELECT count(*) FROM audit_log_events WHERE event_name = 'tesseral.users.assign_role' AND resource_id = $1::uuid
+	`, uuid.UUID(userID).String()).Scan(&assignEvents)
+	require.NoError(t, err)
+	require.Equal(t, 1, assignEvents)
+
+	var syncEvents int
+	err = u.Environment.DB.QueryRow(t.Context(), `
+	SELECT count(*) FROM webhook_outbox_events WHERE resource_key = $1 AND event_type = 'sync.user_role_assignments'
+	`, createResp.User.Id).Scan(&syncEvents)
+	require.NoError(t, err)
+	require.Equal(t, 1, syncEvents)
+
+	// users who joined before the role was made a default role don't have it
+	listResp, err = u.Store.ListUserRoleAssignments(ctx, &backendv1.ListUserRoleAssignmentsRequest{
+		UserId: existingUserID,
+	})
+	require.NoError(t, err)
+	require.Empty(t, listResp.UserRoleAssignments)
+}
@@ -16,6 +16,7 @@ import (
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/cloudflaredoh"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 )
 
@@ -53,9 +54,14 @@ func (s *Store) UpdateVaultDomainSettings(ctx context.Context, req *backendv1.Up
 		return nil, fmt.Errorf("get current pending domain: %w", err)
 	}
 
-	emailIdentity, err := s.upsertSESEmailIdentity(ctx, req.VaultDomainSettings.PendingDomain)
-	if err != nil {
-		return nil, fmt.Errorf("upsert ses email identity: %w", err)
+	// custom email send-from domains are only supported with the SES email
+	// backend
+	var emailIdentity *sesv2.GetEmailIdentityOutput
+	if s.ses != nil {
+		emailIdentity, err = s.upsertSESEmailIdentity(ctx, req.VaultDomainSettings.PendingDomain)
+		if err != nil {
+			return nil, fmt.Errorf("upsert ses email identity: %w", err)
+		}
 	}
 
 	customHostname, err := s.upsertCloudflareCustomHostname(ctx, req.VaultDomainSettings.PendingDomain)
@@ -83,10 +89,12 @@ func (s *Store) UpdateVaultDomainSettings(ctx context.Context, req *backendv1.Up
 		}
 
 		if !previousDomainInUse.Bool {
-			if _, err := s.ses.DeleteEmailIdentity(ctx, &sesv2.DeleteEmailIdentityInput{
-				EmailIdentity: &previousPendingDomain,
-			}); err != nil {
-				return nil, fmt.Errorf("delete email identity: %w", err)
+			if s.ses != nil {
+				if _, err := s.ses.DeleteEmailIdentity(ctx, &sesv2.DeleteEmailIdentityInput{
+					EmailIdentity: &previousPendingDomain,
+				}); err != nil {
+					return nil, fmt.Errorf("delete email identity: %w", err)
+				}
 			}
 
 			previousCustomHostname, err := s.getCloudflareCustomHostnameByHostname(ctx, previousPendingDomain)
@@ -166,6 +174,10 @@ func (s *Store) EnableEmailSendFromDomain(ctx context.Context, req *backendv1.En
 		return nil, fmt.Errorf("validate is console session: %w", err)
 	}
 
+	if s.ses == nil {
+		return nil, apierror.NewFailedPreconditionError("custom email send-from domains require the ses email backend", fmt.Errorf("ses client is nil"))
+	}
+
 	vaultDomainSettings, err := s.getVaultDomainSettings(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get vault domain settings: %w", err)
@@ -205,11 +217,14 @@ func (s *Store) getVaultDomainSettings(ctx context.Context) (*backendv1.VaultDom
 		return nil, fmt.Errorf("get vault domain settings: %w", err)
 	}
 
-	emailIdentity, err := s.ses.GetEmailIdentity(ctx, &sesv2.GetEmailIdentityInput{
-		EmailIdentity: &qVaultDomainSettings.PendingDomain,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("get email identity: %w", err)
+	var emailIdentity *sesv2.GetEmailIdentityOutput
+	if s.ses != nil {
+		emailIdentity, err = s.ses.GetEmailIdentity(ctx, &sesv2.GetEmailIdentityInput{
+			EmailIdentity: &qVaultDomainSettings.PendingDomain,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get email identity: %w", err)
+		}
 	}
 
 	currentCustomHostname, err := s.getCloudflareCustomHostnameByHostname(ctx, qVaultDomainSettings.PendingDomain)
@@ -391,25 +406,29 @@ func (s *Store) parseVaultDomainSettings(ctx context.Context, qVaultDomainSettin
 		},
 	}
 
-	emailSendFromRecords := []*backendv1.VaultDomainSettingsDNSRecord{
-		{
-			Type:      "MX",
-			Name:      fmt.Sprintf("mail.%s", qVaultDomainSettings.PendingDomain),
-			WantValue: s.sesSPFMXRecordValue,
-		},
-		{
-			Type:      "TXT",
-			Name:      fmt.Sprintf("mail.%s", qVaultDomainSettings.PendingDomain),
-			WantValue: "\"v=spf1 include:amazonses.com ~all\"",
-		},
-	}
+	// without an SES email identity, there is no send-from domain to set up
+	var emailSendFromRecords []*backendv1.VaultDomainSettingsDNSRecord
+	if emailIdentity != nil {
+		emailSendFromRecords = []*backendv1.VaultDomainSettingsDNSRecord{
+			{
+				Type:      "MX",
+				Name:      fmt.Sprintf("mail.%s", qVaultDomainSettings.PendingDomain),
+				WantValue: s.sesSPFMXRecordValue,
+			},
+			{
+				Type:      "TXT",
+				Name:      fmt.Sprintf("mail.%s", qVaultDomainSettings.PendingDomain),
+				WantValue: "\"v=spf1 include:amazonses.com ~all\"",
+			},
+		}
 
-	for _, token := range emailIdentity.DkimAttributes.Tokens {
-		emailSendFromRecords = append(emailSendFromRecords, &backendv1.VaultDomainSettingsDNSRecord{
-			Type:      "CNAME",
-			Name:      fmt.Sprintf("%s._domainkey.%s", token, qVaultDomainSettings.PendingDomain),
-			WantValue: fmt.Sprintf("%s.dkim.amazonses.com.", token),
-		})
+		for _, token := range emailIdentity.DkimAttributes.Tokens {
+			emailSendFromRecords = append(emailSendFromRecords, &backendv1.VaultDomainSettingsDNSRecord{
+				Type:      "CNAME",
+				Name:      fmt.Sprintf("%s._domainkey.%s", token, qVaultDomainSettings.PendingDomain),
+				WantValue: fmt.Sprintf("%s.dkim.amazonses.com.", token),
+			})
+		}
 	}
 
 	for i := range vaultDomainRecords {
@@ -429,9 +448,9 @@ func (s *Store) parseVaultDomainSettings(ctx context.Context, qVaultDomainSettin
 	}
 
 	cloudflareOK := customHostname.Status == string(custom_hostnames.CustomHostnameListResponseStatusActive)
-	emailIdentityOK := emailIdentity.VerificationStatus == types.VerificationStatusSuccess
-	dkimOK := emailIdentity.DkimAttributes.Status == types.DkimStatusSuccess
-	mailFromOK := emailIdentity.MailFromAttributes.MailFromDomainStatus == types.MailFromDomainStatusSuccess
+	emailIdentityOK := emailIdentity != nil && emailIdentity.VerificationStatus == types.VerificationStatusSuccess
+	dkimOK := emailIdentity != nil && emailIdentity.DkimAttributes.Status == types.DkimStatusSuccess
+	mailFromOK := emailIdentity != nil && emailIdentity.MailFromAttributes.MailFromDomainStatus == types.MailFromDomainStatusSuccess
 
 	return &backendv1.VaultDomainSettings{
 		PendingDomain:              qVaultDomainSettings.PendingDomain,
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListWebhookDeliveries(ctx context.Context, req *backendv1.ListWebhookDeliveriesRequest) (*backendv1.ListWebhookDeliveriesResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	// We want data sorted newest first. That corresponds to paginating through
+	// IDs high-to-low, because IDs are uuidv7s for this table.
+	startID := uuid.Max
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	listParams := queries.ListWebhookDeliveriesParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        startID,
+		Limit:     int32(limit + 1),
+	}
+
+	if req.WebhookEndpointId != "" {
+		webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.WebhookEndpointId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid webhook endpoint id", fmt.Errorf("parse webhook endpoint id: %w", err))
+		}
+
+		listParams.WebhookEndpointID = (*uuid.UUID)(&webhookEndpointID)
+	}
+
+	qWebhookDeliveries, err := q.ListWebhookDeliveries(ctx, listParams)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+
+	var webhookDeliveries []*backendv1.WebhookDelivery
+	for _, qWebhookDelivery := range qWebhookDeliveries {
+		webhookDelivery, err := parseWebhookDelivery(qWebhookDelivery)
+		if err != nil {
+			return nil, fmt.Errorf("parse webhook delivery: %w", err)
+		}
+
+		webhookDeliveries = append(webhookDeliveries, webhookDelivery)
+	}
+
+	var nextPageToken string
+	if len(webhookDeliveries) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qWebhookDeliveries[limit].ID)
+		webhookDeliveries = webhookDeliveries[:limit]
+	}
+
+	return &backendv1.ListWebhookDeliveriesResponse{
+		WebhookDeliveries: webhookDeliveries,
+		NextPageToken:     nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetWebhookDelivery(ctx context.Context, req *backendv1.GetWebhookDeliveryRequest) (*backendv1.GetWebhookDeliveryResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qWebhookDelivery, err := getWebhookDelivery(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookDelivery, err := parseWebhookDelivery(qWebhookDelivery)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook delivery: %w", err)
+	}
+
+	return &backendv1.GetWebhookDeliveryResponse{WebhookDelivery: webhookDelivery}, nil
+}
+
+func (s *Store) RedeliverWebhook(ctx context.Context, req *backendv1.RedeliverWebhookRequest) (*backendv1.RedeliverWebhookResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qWebhookDelivery, err := getWebhookDelivery(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if qWebhookDelivery.WebhookEndpointID == nil {
+		return nil, apierror.NewFailedPreconditionError("webhook delivery has no webhook endpoint to redeliver to", fmt.Errorf("webhook delivery has no webhook endpoint"))
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(qWebhookDelivery.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	// keep the message id, so that receivers can deduplicate the message
+	if _, err := s.riverClient.InsertTx(ctx, tx, backgroundworkerstore.WebhookEndpointArgs{
+		WebhookEndpointID: idformat.WebhookEndpoint.Format(*qWebhookDelivery.WebhookEndpointID),
+		EventType:         qWebhookDelivery.EventType,
+		Payload:           payload,
+		MessageID:         qWebhookDelivery.MessageID,
+	}, nil); err != nil {
+		return nil, fmt.Errorf("insert webhook endpoint job: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.RedeliverWebhookResponse{}, nil
+}
+
+func getWebhookDelivery(ctx context.Context, q *queries.Queries, id string) (queries.WebhookDelivery, error) {
+	webhookDeliveryID, err := idformat.WebhookDelivery.Parse(id)
+	if err != nil {
+		return queries.WebhookDelivery{}, apierror.NewInvalidArgumentError("invalid webhook delivery id", fmt.Errorf("parse webhook delivery id: %w", err))
+	}
+
+	qWebhookDelivery, err := q.GetWebhookDelivery(ctx, queries.GetWebhookDeliveryParams{
+		ID:        webhookDeliveryID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return queries.WebhookDelivery{}, apierror.NewNotFoundError("webhook delivery not found", fmt.Errorf("get webhook delivery: %w", err))
+		}
+
+		return queries.WebhookDelivery{}, fmt.Errorf("get webhook delivery: %w", err)
+	}
+
+	return qWebhookDelivery, nil
+}
+
+func parseWebhookDelivery(qWebhookDelivery queries.WebhookDelivery) (*backendv1.WebhookDelivery, error) {
+	var payload structpb.Struct
+	if err := protojson.Unmarshal(qWebhookDelivery.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	var webhookEndpointID string
+	if qWebhookDelivery.WebhookEndpointID != nil {
+		webhookEndpointID = idformat.WebhookEndpoint.Format(*qWebhookDelivery.WebhookEndpointID)
+	}
+
+	return &backendv1.WebhookDelivery{
+		Id:                idformat.WebhookDelivery.Format(qWebhookDelivery.ID),
+		WebhookEndpointId: webhookEndpointID,
+		MessageId:         qWebhookDelivery.MessageID,
+		EventType:         qWebhookDelivery.EventType,
+		Payload:           &payload,
+		Url:               qWebhookDelivery.Url,
+		Attempt:           qWebhookDelivery.Attempt,
+		State:             parseWebhookDeliveryState(qWebhookDelivery.State),
+		StatusCode:        qWebhookDelivery.StatusCode,
+		LatencyMillis:     qWebhookDelivery.LatencyMillis,
+		ResponseBody:      derefOrEmpty(qWebhookDelivery.ResponseBody),
+		Error:             derefOrEmpty(qWebhookDelivery.Error),
+		CreateTime:        timestamppb.New(*qWebhookDelivery.CreateTime),
+	}, nil
+}
+
+func parseWebhookDeliveryState(state queries.WebhookDeliveryState) backendv1.WebhookDeliveryState {
+	switch state {
+	case queries.WebhookDeliveryStateSucceeded:
+		return backendv1.WebhookDeliveryState_WEBHOOK_DELIVERY_STATE_SUCCEEDED
+	case queries.WebhookDeliveryStateFailed:
+		return backendv1.WebhookDeliveryState_WEBHOOK_DELIVERY_STATE_FAILED
+	case queries.WebhookDeliveryStateDeadLettered:
+		return backendv1.WebhookDeliveryState_WEBHOOK_DELIVERY_STATE_DEAD_LETTERED
+	default:
+		return backendv1.WebhookDeliveryState_WEBHOOK_DELIVERY_STATE_UNSPECIFIED
+	}
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func (u *testUtil) newWebhookDelivery(t *testing.T, webhookEndpointID string, state string) string {
+	projectUUID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+
+	webhookEndpointUUID, err := idformat.WebhookEndpoint.Parse(webhookEndpointID)
+	require.NoError(t, err)
+
+	id := uuid.Must(uuid.NewV7())
+	_, err = u.Environment.DB.Exec(t.Context(), `
+	INSERT INTO webhook_deliveries (id, project_id, webhook_endpoint_id, message_id, event_type, payload, url, attempt, state, status_code, latency_millis)
+	VALUES ($1::uuid, $2::uuid, $3::uuid, 'msg_1', 'sync.user', '{"type": "sync.user"}', 'https://example.com/webhooks', 1, $4, 500, 100)
+	`,
+		id.String(),
+		uuid.UUID(projectUUID).String(),
+		uuid.UUID(webhookEndpointUUID).String(),
+		state,
+	)
+	require.NoError(t, err)
+
+	return idformat.WebhookDelivery.Format(id)
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks",
+		},
+	})
+	require.NoError(t, err)
+
+	firstID := u.newWebhookDelivery(t, createRes.WebhookEndpoint.Id, "failed")
+	secondID := u.newWebhookDelivery(t, createRes.WebhookEndpoint.Id, "dead_lettered")
+
+	res, err := u.Store.ListWebhookDeliveries(ctx, &backendv1.ListWebhookDeliveriesRequest{
+		WebhookEndpointId: createRes.WebhookEndpoint.Id,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.WebhookDeliveries, 2)
+
+	// newest first
+	require.Equal(t, secondID, res.WebhookDeliveries[0].Id)
+	require.Equal(t, backendv1.WebhookDeliveryState_WEBHOOK_DELIVERY_STATE_DEAD_LETTERED, res.WebhookDeliveries[0].State)
+	require.Equal(t, firstID, res.WebhookDeliveries[1].Id)
+	require.Equal(t, int32(500), res.WebhookDeliveries[1].GetStatusCode())
+	require.Equal(t, "sync.user", res.WebhookDeliveries[1].Payload.Fields["type"].GetStringValue())
+}
+
+func TestGetWebhookDelivery_DoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.GetWebhookDelivery(ctx, &backendv1.GetWebhookDeliveryRequest{
+		Id: idformat.WebhookDelivery.Format(uuid.New()),
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks",
+		},
+	})
+	require.NoError(t, err)
+
+	webhookDeliveryID := u.newWebhookDelivery(t, createRes.WebhookEndpoint.Id, "dead_lettered")
+
+	_, err = u.Store.RedeliverWebhook(ctx, &backendv1.RedeliverWebhookRequest{Id: webhookDeliveryID})
+	require.NoError(t, err)
+}
+
+func TestRedeliverWebhook_DeletedEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks",
+		},
+	})
+	require.NoError(t, err)
+
+	webhookDeliveryID := u.newWebhookDelivery(t, createRes.WebhookEndpoint.Id, "failed")
+
+	_, err = u.Store.DeleteWebhookEndpoint(ctx, &backendv1.DeleteWebhookEndpointRequest{Id: createRes.WebhookEndpoint.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.RedeliverWebhook(ctx, &backendv1.RedeliverWebhookRequest{Id: webhookDeliveryID})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+}
@@ -0,0 +1,281 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListWebhookEndpoints(ctx context.Context, req *backendv1.ListWebhookEndpointsRequest) (*backendv1.ListWebhookEndpointsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qWebhookEndpoints, err := q.ListWebhookEndpoints(ctx, queries.ListWebhookEndpointsParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        startID,
+		Limit:     int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list webhook endpoints: %w", err)
+	}
+
+	var webhookEndpoints []*backendv1.WebhookEndpoint
+	for _, qWebhookEndpoint := range qWebhookEndpoints {
+		webhookEndpoints = append(webhookEndpoints, parseWebhookEndpoint(qWebhookEndpoint))
+	}
+
+	var nextPageToken string
+	if len(webhookEndpoints) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qWebhookEndpoints[limit].ID)
+		webhookEndpoints = webhookEndpoints[:limit]
+	}
+
+	return &backendv1.ListWebhookEndpointsResponse{
+		WebhookEndpoints: webhookEndpoints,
+		NextPageToken:    nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetWebhookEndpoint(ctx context.Context, req *backendv1.GetWebhookEndpointRequest) (*backendv1.GetWebhookEndpointResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid webhook endpoint id", fmt.Errorf("parse webhook endpoint id: %w", err))
+	}
+
+	qWebhookEndpoint, err := q.GetWebhookEndpoint(ctx, queries.GetWebhookEndpointParams{
+		ID:        webhookEndpointID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("webhook endpoint not found", fmt.Errorf("get webhook endpoint: %w", err))
+		}
+
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	return &backendv1.GetWebhookEndpointResponse{WebhookEndpoint: parseWebhookEndpoint(qWebhookEndpoint)}, nil
+}
+
+func (s *Store) CreateWebhookEndpoint(ctx context.Context, req *backendv1.CreateWebhookEndpointRequest) (*backendv1.CreateWebhookEndpointResponse, error) {
+	if req.WebhookEndpoint == nil {
+		return nil, apierror.NewInvalidArgumentError("webhook_endpoint is required", fmt.Errorf("webhook endpoint is nil"))
+	}
+
+	if err := validateWebhookEndpointURL(req.WebhookEndpoint.Url); err != nil {
+		return nil, err
+	}
+
+	if err := validateWebhookEndpointEventTypes(req.WebhookEndpoint.EventTypes); err != nil {
+		return nil, err
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	enabled := true
+	if req.WebhookEndpoint.Enabled != nil {
+		enabled = *req.WebhookEndpoint.Enabled
+	}
+
+	qWebhookEndpoint, err := q.CreateWebhookEndpoint(ctx, queries.CreateWebhookEndpointParams{
+		ID:          uuid.New(),
+		ProjectID:   authn.ProjectID(ctx),
+		Url:         req.WebhookEndpoint.Url,
+		Description: req.WebhookEndpoint.Description,
+		EventTypes:  eventTypesOrEmpty(req.WebhookEndpoint.EventTypes),
+		Enabled:     enabled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create webhook endpoint: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.CreateWebhookEndpointResponse{WebhookEndpoint: parseWebhookEndpoint(qWebhookEndpoint)}, nil
+}
+
+func (s *Store) UpdateWebhookEndpoint(ctx context.Context, req *backendv1.UpdateWebhookEndpointRequest) (*backendv1.UpdateWebhookEndpointResponse, error) {
+	if req.WebhookEndpoint == nil {
+		return nil, apierror.NewInvalidArgumentError("webhook_endpoint is required", fmt.Errorf("webhook endpoint is nil"))
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid webhook endpoint id", fmt.Errorf("parse webhook endpoint id: %w", err))
+	}
+
+	qWebhookEndpoint, err := q.GetWebhookEndpoint(ctx, queries.GetWebhookEndpointParams{
+		ID:        webhookEndpointID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("webhook endpoint not found", fmt.Errorf("get webhook endpoint: %w", err))
+		}
+
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	updates := queries.UpdateWebhookEndpointParams{
+		ID:          webhookEndpointID,
+		Url:         qWebhookEndpoint.Url,
+		Description: qWebhookEndpoint.Description,
+		EventTypes:  qWebhookEndpoint.EventTypes,
+		Enabled:     qWebhookEndpoint.Enabled,
+	}
+
+	if req.WebhookEndpoint.Url != "" {
+		if err := validateWebhookEndpointURL(req.WebhookEndpoint.Url); err != nil {
+			return nil, err
+		}
+
+		updates.Url = req.WebhookEndpoint.Url
+	}
+
+	if req.WebhookEndpoint.Description != "" {
+		updates.Description = req.WebhookEndpoint.Description
+	}
+
+	if req.WebhookEndpoint.EventTypes != nil {
+		if err := validateWebhookEndpointEventTypes(req.WebhookEndpoint.EventTypes); err != nil {
+			return nil, err
+		}
+
+		updates.EventTypes = req.WebhookEndpoint.EventTypes
+	}
+
+	if req.WebhookEndpoint.Enabled != nil {
+		updates.Enabled = *req.WebhookEndpoint.Enabled
+	}
+
+	qUpdatedWebhookEndpoint, err := q.UpdateWebhookEndpoint(ctx, updates)
+	if err != nil {
+		return nil, fmt.Errorf("update webhook endpoint: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateWebhookEndpointResponse{WebhookEndpoint: parseWebhookEndpoint(qUpdatedWebhookEndpoint)}, nil
+}
+
+func (s *Store) DeleteWebhookEndpoint(ctx context.Context, req *backendv1.DeleteWebhookEndpointRequest) (*backendv1.DeleteWebhookEndpointResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid webhook endpoint id", fmt.Errorf("parse webhook endpoint id: %w", err))
+	}
+
+	if _, err := q.GetWebhookEndpoint(ctx, queries.GetWebhookEndpointParams{
+		ID:        webhookEndpointID,
+		ProjectID: authn.ProjectID(ctx),
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("webhook endpoint not found", fmt.Errorf("get webhook endpoint: %w", err))
+		}
+
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	if err := q.DeleteWebhookEndpoint(ctx, webhookEndpointID); err != nil {
+		return nil, fmt.Errorf("delete webhook endpoint: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteWebhookEndpointResponse{}, nil
+}
+
+func validateWebhookEndpointURL(webhookEndpointURL string) error {
+	u, err := url.Parse(webhookEndpointURL)
+	if err != nil {
+		return apierror.NewInvalidArgumentError("invalid webhook endpoint url", fmt.Errorf("parse webhook endpoint url: %w", err))
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return apierror.NewInvalidArgumentError("webhook endpoint url must be an absolute http or https url", fmt.Errorf("invalid webhook endpoint url scheme: %q", u.Scheme))
+	}
+
+	if u.Host == "" {
+		return apierror.NewInvalidArgumentError("webhook endpoint url must be an absolute http or https url", fmt.Errorf("webhook endpoint url has no host"))
+	}
+
+	return nil
+}
+
+func validateWebhookEndpointEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !slices.Contains(webhookworker.EventTypes, eventType) {
+			return apierror.NewInvalidArgumentError(fmt.Sprintf("unknown event type: %s", eventType), fmt.Errorf("unknown event type: %q", eventType))
+		}
+	}
+
+	return nil
+}
+
+// eventTypesOrEmpty returns a non-nil slice, because event_types is not null.
+func eventTypesOrEmpty(eventTypes []string) []string {
+	if eventTypes == nil {
+		return []string{}
+	}
+	return eventTypes
+}
+
+func parseWebhookEndpoint(qWebhookEndpoint queries.WebhookEndpoint) *backendv1.WebhookEndpoint {
+	return &backendv1.WebhookEndpoint{
+		Id:          idformat.WebhookEndpoint.Format(qWebhookEndpoint.ID),
+		Url:         qWebhookEndpoint.Url,
+		Description: qWebhookEndpoint.Description,
+		EventTypes:  qWebhookEndpoint.EventTypes,
+		Enabled:     &qWebhookEndpoint.Enabled,
+		CreateTime:  timestamppb.New(*qWebhookEndpoint.CreateTime),
+		UpdateTime:  timestamppb.New(*qWebhookEndpoint.UpdateTime),
+	}
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestCreateWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url:         "https://example.com/webhooks",
+			Description: "users",
+			EventTypes:  []string{"sync.user"},
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.WebhookEndpoint.Id)
+	require.Equal(t, "https://example.com/webhooks", res.WebhookEndpoint.Url)
+	require.Equal(t, "users", res.WebhookEndpoint.Description)
+	require.Equal(t, []string{"sync.user"}, res.WebhookEndpoint.EventTypes)
+	require.True(t, res.WebhookEndpoint.GetEnabled())
+}
+
+func TestCreateWebhookEndpoint_InvalidURL(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "ftp://example.com/webhooks",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestCreateWebhookEndpoint_UnknownEventType(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url:        "https://example.com/webhooks",
+			EventTypes: []string{"sync.nonexistent"},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestUpdateWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url:        "https://example.com/webhooks",
+			EventTypes: []string{"sync.user"},
+		},
+	})
+	require.NoError(t, err)
+
+	updateRes, err := u.Store.UpdateWebhookEndpoint(ctx, &backendv1.UpdateWebhookEndpointRequest{
+		Id: createRes.WebhookEndpoint.Id,
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			EventTypes: []string{"sync.user", "sync.organization"},
+			Enabled:    refOrNil(false),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/webhooks", updateRes.WebhookEndpoint.Url)
+	require.Equal(t, []string{"sync.user", "sync.organization"}, updateRes.WebhookEndpoint.EventTypes)
+	require.False(t, updateRes.WebhookEndpoint.GetEnabled())
+}
+
+func TestListWebhookEndpoints(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	var ids []string
+	for range 3 {
+		res, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+			WebhookEndpoint: &backendv1.WebhookEndpoint{
+				Url: "https://example.com/webhooks",
+			},
+		})
+		require.NoError(t, err)
+		ids = append(ids, res.WebhookEndpoint.Id)
+	}
+
+	res, err := u.Store.ListWebhookEndpoints(ctx, &backendv1.ListWebhookEndpointsRequest{})
+	require.NoError(t, err)
+
+	var resIDs []string
+	for _, webhookEndpoint := range res.WebhookEndpoints {
+		resIDs = append(resIDs, webhookEndpoint.Id)
+	}
+	require.ElementsMatch(t, ids, resIDs)
+}
+
+func TestDeleteWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.DeleteWebhookEndpoint(ctx, &backendv1.DeleteWebhookEndpointRequest{Id: createRes.WebhookEndpoint.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.GetWebhookEndpoint(ctx, &backendv1.GetWebhookEndpointRequest{Id: createRes.WebhookEndpoint.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+func TestGetWebhookEndpoint_DoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.GetWebhookEndpoint(ctx, &backendv1.GetWebhookEndpointRequest{
+		Id: idformat.WebhookEndpoint.Format(uuid.New()),
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
@@ -0,0 +1,54 @@
+package apikeyworker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+)
+
+// ExpirePreviousSecretTokensInterval is how often rotated API keys are checked
+// for expired previous secret tokens.
+const ExpirePreviousSecretTokensInterval = time.Minute
+
+// ExpirePreviousSecretTokensWorker discards the previous secret tokens of
+// rotated API keys once they expire. It is meant to be run as a periodic
+// job, every ExpirePreviousSecretTokensInterval.
+type ExpirePreviousSecretTokensWorker struct {
+	Store *store.Store
+	river.WorkerDefaults[ExpirePreviousSecretTokensArgs]
+}
+
+type ExpirePreviousSecretTokensArgs struct{}
+
+func (ExpirePreviousSecretTokensArgs) Kind() string {
+	return "api_key_expire_previous_secret_tokens"
+}
+
+func (w *ExpirePreviousSecretTokensWorker) Work(ctx context.Context, job *river.Job[ExpirePreviousSecretTokensArgs]) error {
+	count, err := w.Store.ExpireAPIKeyPreviousSecretTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "api_key_previous_secret_tokens_expired", "count", count)
+	}
+
+	return nil
+}
+
+// PeriodicJob runs ExpirePreviousSecretTokensWorker every
+// ExpirePreviousSecretTokensInterval.
+func PeriodicJob() *river.PeriodicJob {
+	return river.NewPeriodicJob(
+		river.PeriodicInterval(ExpirePreviousSecretTokensInterval),
+		func() (river.JobArgs, *river.InsertOpts) {
+			return ExpirePreviousSecretTokensArgs{}, nil
+		},
+		&river.PeriodicJobOpts{RunOnStart: true},
+	)
+}
@@ -0,0 +1,113 @@
+package auditlogstreamworker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+)
+
+// EnqueueInterval is how often every enabled audit log stream is checked for
+// new events.
+const EnqueueInterval = 10 * time.Second
+
+// Worker sends an audit log stream's new events to its destination.
+type Worker struct {
+	Store *store.Store
+	river.WorkerDefaults[Args]
+}
+
+type Args struct {
+	AuditLogStreamID uuid.UUID
+}
+
+func (Args) Kind() string {
+	return "audit_log_stream"
+}
+
+func (Args) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		// A failed job isn't retried; the next EnqueueWorker run picks the
+		// stream up again from its cursor.
+		MaxAttempts: 1,
+
+		// At most one job per stream is in flight, so that each stream's
+		// events are sent in order.
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+			ByState: []rivertype.JobState{
+				rivertype.JobStateAvailable,
+				rivertype.JobStatePending,
+				rivertype.JobStateRunning,
+				rivertype.JobStateScheduled,
+			},
+		},
+	}
+}
+
+func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
+	slog.InfoContext(ctx, "work", "audit_log_stream_id", job.Args.AuditLogStreamID)
+
+	if err := w.Store.SendAuditLogStreamEvents(ctx, job.Args.AuditLogStreamID); err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+
+	return nil
+}
+
+// EnqueueWorker enqueues a Worker job for every enabled audit log stream. It
+// is meant to be run as a periodic job, every EnqueueInterval.
+type EnqueueWorker struct {
+	Store *store.Store
+	river.WorkerDefaults[EnqueueArgs]
+}
+
+type EnqueueArgs struct{}
+
+func (EnqueueArgs) Kind() string {
+	return "audit_log_stream_enqueue"
+}
+
+func (w *EnqueueWorker) Work(ctx context.Context, job *river.Job[EnqueueArgs]) error {
+	auditLogStreamIDs, err := w.Store.ListEnabledAuditLogStreamIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+
+	if len(auditLogStreamIDs) == 0 {
+		return nil
+	}
+
+	var params []river.InsertManyParams
+	for _, auditLogStreamID := range auditLogStreamIDs {
+		params = append(params, river.InsertManyParams{Args: Args{AuditLogStreamID: auditLogStreamID}})
+	}
+
+	riverClient, err := river.ClientFromContextSafely[pgx.Tx](ctx)
+	if err != nil {
+		return fmt.Errorf("get river client: %w", err)
+	}
+
+	if _, err := riverClient.InsertMany(ctx, params); err != nil {
+		return fmt.Errorf("insert audit log stream jobs: %w", err)
+	}
+
+	return nil
+}
+
+// PeriodicJob runs EnqueueWorker every EnqueueInterval.
+func PeriodicJob() *river.PeriodicJob {
+	return river.NewPeriodicJob(
+		river.PeriodicInterval(EnqueueInterval),
+		func() (river.JobArgs, *river.InsertOpts) {
+			return EnqueueArgs{}, nil
+		},
+		&river.PeriodicJobOpts{RunOnStart: true},
+	)
+}
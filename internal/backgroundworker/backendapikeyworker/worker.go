@@ -0,0 +1,52 @@
+package backendapikeyworker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+)
+
+// ExpireInterval is how often backend API keys are checked for expiry.
+const ExpireInterval = time.Minute
+
+// ExpireWorker logs an audit event for every backend API key that expired
+// since it last ran. It is meant to be run as a periodic job, every
+// ExpireInterval.
+type ExpireWorker struct {
+	Store *store.Store
+	river.WorkerDefaults[ExpireArgs]
+}
+
+type ExpireArgs struct{}
+
+func (ExpireArgs) Kind() string {
+	return "backend_api_key_expire"
+}
+
+func (w *ExpireWorker) Work(ctx context.Context, job *river.Job[ExpireArgs]) error {
+	count, err := w.Store.LogExpiredBackendAPIKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "backend_api_keys_expired", "count", count)
+	}
+
+	return nil
+}
+
+// PeriodicJob runs ExpireWorker every ExpireInterval.
+func PeriodicJob() *river.PeriodicJob {
+	return river.NewPeriodicJob(
+		river.PeriodicInterval(ExpireInterval),
+		func() (river.JobArgs, *river.InsertOpts) {
+			return ExpireArgs{}, nil
+		},
+		&river.PeriodicJobOpts{RunOnStart: true},
+	)
+}
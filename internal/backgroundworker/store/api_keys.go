@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// expiredAPIKeyPreviousSecretTokensBatchSize is how many API keys
+// ExpireAPIKeyPreviousSecretTokens handles per transaction.
+const expiredAPIKeyPreviousSecretTokensBatchSize = 100
+
+// ExpireAPIKeyPreviousSecretTokens discards the previous secret token of every
+// rotated API key whose previous secret token has expired, and logs a
+// "tesseral.api_keys.expire_previous_secret_token" audit event for each. It
+// returns how many keys it handled.
+func (s *Store) ExpireAPIKeyPreviousSecretTokens(ctx context.Context) (int, error) {
+	var count int
+	for {
+		n, err := s.expireAPIKeyPreviousSecretTokensBatch(ctx)
+		if err != nil {
+			return count, err
+		}
+
+		count += n
+		if n < expiredAPIKeyPreviousSecretTokensBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func (s *Store) expireAPIKeyPreviousSecretTokensBatch(ctx context.Context) (int, error) {
+	riverClient, err := river.ClientFromContextSafely[pgx.Tx](ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get river client: %w", err)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := queries.New(tx)
+	qAPIKeys, err := q.ExpireAPIKeyPreviousSecretTokens(ctx, expiredAPIKeyPreviousSecretTokensBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("expire api key previous secret tokens: %w", err)
+	}
+
+	for _, qAPIKey := range qAPIKeys {
+		auditAPIKey, err := s.AuditlogStore.GetAPIKey(ctx, tx, qAPIKey.ID)
+		if err != nil {
+			return 0, fmt.Errorf("get audit api key: %w", err)
+		}
+
+		eventDetails := &auditlogv1.ExpireAPIKeyPreviousSecretToken{
+			ApiKey: auditAPIKey,
+		}
+
+		eventDetailsBytes, err := protojson.Marshal(eventDetails)
+		if err != nil {
+			return 0, fmt.Errorf("marshal event details: %w", err)
+		}
+
+		eventID := uuidv7.NewWithTime(time.Now())
+
+		// the event happened when the secret token expired, not when it was
+		// noticed
+		eventTime := *qAPIKey.PreviousSecretTokenExpireTime
+		resourceType := queries.AuditLogEventResourceTypeApiKey
+		if err := q.CreateAuditLogEvent(ctx, queries.CreateAuditLogEventParams{
+			ID:             eventID,
+			ProjectID:      qAPIKey.ProjectID,
+			OrganizationID: &qAPIKey.OrganizationID,
+			ResourceType:   &resourceType,
+			ResourceID:     &qAPIKey.ID,
+			EventName:      "tesseral.api_keys.expire_previous_secret_token",
+			EventTime:      &eventTime,
+			EventDetails:   eventDetailsBytes,
+		}); err != nil {
+			return 0, fmt.Errorf("create audit log event: %w", err)
+		}
+
+		if err := InsertTypedEventTx(ctx, tx, riverClient, NewTypedEventArgsParams{
+			ProjectID:         qAPIKey.ProjectID,
+			AuditLogEventID:   eventID,
+			AuditLogEventName: "tesseral.api_keys.expire_previous_secret_token",
+			EventTime:         eventTime,
+			EventDetails:      eventDetails,
+		}); err != nil {
+			return 0, fmt.Errorf("insert typed event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+
+	return len(qAPIKeys), nil
+}
@@ -0,0 +1,339 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/auditlogstream"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	auditLogStreamBatchSize = 100
+
+	auditLogStreamSyslogDialTimeout = 10 * time.Second
+)
+
+// ListEnabledAuditLogStreamIDs returns the IDs of every enabled audit log
+// stream, across all projects.
+func (s *Store) ListEnabledAuditLogStreamIDs(ctx context.Context) ([]uuid.UUID, error) {
+	auditLogStreamIDs, err := s.q().ListEnabledAuditLogStreamIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list enabled audit log stream ids: %w", err)
+	}
+
+	return auditLogStreamIDs, nil
+}
+
+// SendAuditLogStreamEvents sends the audit log events written since an audit
+// log stream's cursor, in order, advancing the cursor as each is delivered.
+// Delivery is at-least-once.
+//
+// Events are written in the same transaction as the change they describe, so
+// they may become visible out of ID order. The cursor is therefore over the ID
+// of the transaction that wrote each event, and only events from transactions
+// older than every transaction still in flight are sent; no event can appear
+// behind the cursor once it has advanced past it.
+func (s *Store) SendAuditLogStreamEvents(ctx context.Context, auditLogStreamID uuid.UUID) error {
+	qAuditLogStream, err := s.q().GetAuditLogStream(ctx, auditLogStreamID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// the stream was deleted since this job was enqueued
+			return nil
+		}
+
+		return fmt.Errorf("get audit log stream: %w", err)
+	}
+
+	if !qAuditLogStream.Enabled {
+		return nil
+	}
+
+	if err := s.sendAuditLogStreamEvents(ctx, qAuditLogStream); err != nil {
+		if err := s.q().UpdateAuditLogStreamLastError(ctx, queries.UpdateAuditLogStreamLastErrorParams{
+			ID:        qAuditLogStream.ID,
+			LastError: refOrNil(err.Error()),
+		}); err != nil {
+			return fmt.Errorf("update audit log stream last error: %w", err)
+		}
+
+		return fmt.Errorf("send audit log stream events: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) sendAuditLogStreamEvents(ctx context.Context, qAuditLogStream queries.AuditLogStream) error {
+	var sink auditLogStreamSink
+	defer func() {
+		if sink != nil {
+			_ = sink.close()
+		}
+	}()
+
+	cursorTransactionID := qAuditLogStream.CursorTransactionID
+	cursorID := qAuditLogStream.CursorAuditLogEventID
+	for {
+		qAuditLogEvents, err := s.q().ListAuditLogEventsAfterCursor(ctx, queries.ListAuditLogEventsAfterCursorParams{
+			Limit:               auditLogStreamBatchSize,
+			ProjectID:           qAuditLogStream.ProjectID,
+			OrganizationID:      qAuditLogStream.OrganizationID,
+			CursorTransactionID: cursorTransactionID,
+			CursorID:            cursorID,
+		})
+		if err != nil {
+			return fmt.Errorf("list audit log events after cursor: %w", err)
+		}
+
+		if len(qAuditLogEvents) == 0 {
+			return nil
+		}
+
+		// only connect to the destination once there is something to send
+		if sink == nil {
+			sink, err = s.newAuditLogStreamSink(ctx, qAuditLogStream)
+			if err != nil {
+				return fmt.Errorf("new audit log stream sink: %w", err)
+			}
+		}
+
+		for _, qAuditLogEvent := range qAuditLogEvents {
+			eventJSON, err := formatAuditLogStreamEvent(qAuditLogEvent)
+			if err != nil {
+				return fmt.Errorf("format audit log event: %w", err)
+			}
+
+			if err := sink.send(ctx, qAuditLogEvent, eventJSON); err != nil {
+				return fmt.Errorf("send audit log event: %w", err)
+			}
+
+			if err := s.q().UpdateAuditLogStreamCursor(ctx, queries.UpdateAuditLogStreamCursorParams{
+				ID:                    qAuditLogStream.ID,
+				CursorTransactionID:   qAuditLogEvent.TransactionID,
+				CursorAuditLogEventID: qAuditLogEvent.ID,
+			}); err != nil {
+				return fmt.Errorf("update audit log stream cursor: %w", err)
+			}
+
+			cursorTransactionID = qAuditLogEvent.TransactionID
+			cursorID = qAuditLogEvent.ID
+		}
+
+		if len(qAuditLogEvents) < auditLogStreamBatchSize {
+			return nil
+		}
+	}
+}
+
+type auditLogStreamSink interface {
+	send(ctx context.Context, qAuditLogEvent queries.AuditLogEvent, eventJSON []byte) error
+	close() error
+}
+
+func (s *Store) newAuditLogStreamSink(ctx context.Context, qAuditLogStream queries.AuditLogStream) (auditLogStreamSink, error) {
+	switch qAuditLogStream.Type {
+	case queries.AuditLogStreamTypeWebhook:
+		qProjectWebhookSettings, err := s.q().GetProjectWebhookSettings(ctx, qAuditLogStream.ProjectID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("get project webhook settings: %w", err)
+		}
+
+		// generate any missing signing secret up front, rather than on every
+		// send
+		qProjectWebhookSettings, err = s.ensureWebhookSigningSecret(ctx, qProjectWebhookSettings)
+		if err != nil {
+			return nil, fmt.Errorf("ensure webhook signing secret: %w", err)
+		}
+
+		return &webhookAuditLogStreamSink{
+			store:                   s,
+			qProjectWebhookSettings: qProjectWebhookSettings,
+			url:                     *qAuditLogStream.WebhookUrl,
+		}, nil
+	case queries.AuditLogStreamTypeSyslog:
+		dialCtx, cancel := context.WithTimeout(ctx, auditLogStreamSyslogDialTimeout)
+		defer cancel()
+
+		conn, err := s.EgressPolicy.DialContext(dialCtx, "tcp", *qAuditLogStream.SyslogAddress)
+		if err != nil {
+			return nil, fmt.Errorf("dial syslog server: %w", err)
+		}
+
+		if qAuditLogStream.SyslogTls {
+			host, _, err := net.SplitHostPort(*qAuditLogStream.SyslogAddress)
+			if err != nil {
+				_ = conn.Close()
+				return nil, fmt.Errorf("split syslog address: %w", err)
+			}
+
+			tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+			if err := tlsConn.HandshakeContext(dialCtx); err != nil {
+				_ = conn.Close()
+				return nil, fmt.Errorf("tls handshake with syslog server: %w", err)
+			}
+			conn = tlsConn
+		}
+
+		return &syslogAuditLogStreamSink{conn: conn}, nil
+	case queries.AuditLogStreamTypeSplunkHec:
+		token, err := s.AuditLogStreamSecretsKMS.Decrypt(ctx, qAuditLogStream.SplunkHecTokenCiphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt splunk hec token: %w", err)
+		}
+
+		return &splunkHECAuditLogStreamSink{
+			httpClient: s.DirectWebhookHTTPClient,
+			url:        *qAuditLogStream.SplunkHecUrl,
+			token:      string(token),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown audit log stream type: %q", qAuditLogStream.Type)
+	}
+}
+
+type webhookAuditLogStreamSink struct {
+	store                   *Store
+	qProjectWebhookSettings queries.ProjectWebhookSetting
+	url                     string
+}
+
+func (w *webhookAuditLogStreamSink) send(ctx context.Context, qAuditLogEvent queries.AuditLogEvent, eventJSON []byte) error {
+	var payload map[string]any
+	if err := json.Unmarshal(eventJSON, &payload); err != nil {
+		return fmt.Errorf("unmarshal audit log event: %w", err)
+	}
+
+	// the audit log event ID doubles as the message ID, so receivers can
+	// deduplicate redelivered events
+	messageID := idformat.AuditLogEvent.Format(qAuditLogEvent.ID)
+	if _, err := w.store.sendDirectWebhook(ctx, w.qProjectWebhookSettings, w.url, messageID, payload); err != nil {
+		return fmt.Errorf("send direct webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (w *webhookAuditLogStreamSink) close() error {
+	return nil
+}
+
+type syslogAuditLogStreamSink struct {
+	conn net.Conn
+}
+
+func (s *syslogAuditLogStreamSink) send(ctx context.Context, qAuditLogEvent queries.AuditLogEvent, eventJSON []byte) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := s.conn.SetWriteDeadline(deadline); err != nil {
+			return fmt.Errorf("set write deadline: %w", err)
+		}
+	}
+
+	if _, err := s.conn.Write(auditlogstream.SyslogMessage("", *qAuditLogEvent.EventTime, qAuditLogEvent.EventName, eventJSON)); err != nil {
+		return fmt.Errorf("write syslog message: %w", err)
+	}
+
+	return nil
+}
+
+func (s *syslogAuditLogStreamSink) close() error {
+	return s.conn.Close()
+}
+
+type splunkHECAuditLogStreamSink struct {
+	httpClient *http.Client
+	url        string
+	token      string
+}
+
+func (s *splunkHECAuditLogStreamSink) send(ctx context.Context, qAuditLogEvent queries.AuditLogEvent, eventJSON []byte) error {
+	body, err := auditlogstream.SplunkHECEvent("", *qAuditLogEvent.EventTime, eventJSON)
+	if err != nil {
+		return fmt.Errorf("format splunk hec event: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new http request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Splunk %s", s.token))
+
+	httpRes, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("send http request: %w", err)
+	}
+
+	defer func() { _ = httpRes.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(httpRes.Body, maxWebhookDeliveryResponseBodyBytes))
+
+	if httpRes.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad response status code: %s", httpRes.Status)
+	}
+
+	return nil
+}
+
+func (s *splunkHECAuditLogStreamSink) close() error {
+	return nil
+}
+
+// formatAuditLogStreamEvent returns an audit log event as JSON, in the same
+// shape the backend API returns it in.
+func formatAuditLogStreamEvent(qAuditLogEvent queries.AuditLogEvent) ([]byte, error) {
+	var eventDetails structpb.Struct
+	if err := protojson.Unmarshal(qAuditLogEvent.EventDetails, &eventDetails); err != nil {
+		return nil, fmt.Errorf("unmarshal event details: %w", err)
+	}
+
+	auditLogEvent := &backendv1.AuditLogEvent{
+		Id:           idformat.AuditLogEvent.Format(qAuditLogEvent.ID),
+		EventName:    qAuditLogEvent.EventName,
+		EventTime:    timestamppb.New(*qAuditLogEvent.EventTime),
+		EventDetails: &eventDetails,
+	}
+
+	if qAuditLogEvent.OrganizationID != nil {
+		auditLogEvent.OrganizationId = idformat.Organization.Format(*qAuditLogEvent.OrganizationID)
+	}
+	if qAuditLogEvent.ActorUserID != nil {
+		auditLogEvent.ActorUserId = idformat.User.Format(*qAuditLogEvent.ActorUserID)
+	}
+	if qAuditLogEvent.ActorSessionID != nil {
+		auditLogEvent.ActorSessionId = idformat.Session.Format(*qAuditLogEvent.ActorSessionID)
+	}
+	if qAuditLogEvent.ActorApiKeyID != nil {
+		auditLogEvent.ActorApiKeyId = idformat.APIKey.Format(*qAuditLogEvent.ActorApiKeyID)
+	}
+	if qAuditLogEvent.ActorBackendApiKeyID != nil {
+		auditLogEvent.ActorBackendApiKeyId = idformat.BackendAPIKey.Format(*qAuditLogEvent.ActorBackendApiKeyID)
+	}
+	if qAuditLogEvent.ActorIntermediateSessionID != nil {
+		auditLogEvent.ActorIntermediateSessionId = idformat.IntermediateSession.Format(*qAuditLogEvent.ActorIntermediateSessionID)
+	}
+	if qAuditLogEvent.ActorScimApiKeyID != nil {
+		auditLogEvent.ActorScimApiKeyId = idformat.SCIMAPIKey.Format(*qAuditLogEvent.ActorScimApiKeyID)
+	}
+
+	eventJSON, err := protojson.Marshal(auditLogEvent)
+	if err != nil {
+		return nil, fmt.Errorf("marshal audit log event: %w", err)
+	}
+
+	return eventJSON, nil
+}
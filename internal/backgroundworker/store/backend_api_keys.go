@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// expiredBackendAPIKeysBatchSize is how many expired backend API keys
+// LogExpiredBackendAPIKeys handles per transaction.
+const expiredBackendAPIKeysBatchSize = 100
+
+// LogExpiredBackendAPIKeys logs a "tesseral.backend_api_keys.expire" audit
+// event for every backend API key that has expired but was not logged as
+// such yet. It returns how many keys it logged.
+func (s *Store) LogExpiredBackendAPIKeys(ctx context.Context) (int, error) {
+	var count int
+	for {
+		n, err := s.logExpiredBackendAPIKeysBatch(ctx)
+		if err != nil {
+			return count, err
+		}
+
+		count += n
+		if n < expiredBackendAPIKeysBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func (s *Store) logExpiredBackendAPIKeysBatch(ctx context.Context) (int, error) {
+	riverClient, err := river.ClientFromContextSafely[pgx.Tx](ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get river client: %w", err)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := queries.New(tx)
+	qBackendAPIKeys, err := q.MarkExpiredBackendAPIKeysLogged(ctx, expiredBackendAPIKeysBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("mark expired backend api keys logged: %w", err)
+	}
+
+	for _, qBackendAPIKey := range qBackendAPIKeys {
+		auditBackendAPIKey, err := s.AuditlogStore.GetBackendAPIKey(ctx, tx, qBackendAPIKey.ID)
+		if err != nil {
+			return 0, fmt.Errorf("get audit backend api key: %w", err)
+		}
+
+		eventDetails := &auditlogv1.ExpireBackendAPIKey{
+			BackendApiKey: auditBackendAPIKey,
+		}
+
+		eventDetailsBytes, err := protojson.Marshal(eventDetails)
+		if err != nil {
+			return 0, fmt.Errorf("marshal event details: %w", err)
+		}
+
+		eventID := uuidv7.NewWithTime(time.Now())
+
+		// the event happened when the key expired, not when it was noticed
+		eventTime := *qBackendAPIKey.ExpireTime
+		resourceType := queries.AuditLogEventResourceTypeBackendApiKey
+		if err := q.CreateAuditLogEvent(ctx, queries.CreateAuditLogEventParams{
+			ID:           eventID,
+			ProjectID:    qBackendAPIKey.ProjectID,
+			ResourceType: &resourceType,
+			ResourceID:   &qBackendAPIKey.ID,
+			EventName:    "tesseral.backend_api_keys.expire",
+			EventTime:    &eventTime,
+			EventDetails: eventDetailsBytes,
+		}); err != nil {
+			return 0, fmt.Errorf("create audit log event: %w", err)
+		}
+
+		if err := InsertTypedEventTx(ctx, tx, riverClient, NewTypedEventArgsParams{
+			ProjectID:         qBackendAPIKey.ProjectID,
+			AuditLogEventID:   eventID,
+			AuditLogEventName: "tesseral.backend_api_keys.expire",
+			EventTime:         eventTime,
+			EventDetails:      eventDetails,
+		}); err != nil {
+			return 0, fmt.Errorf("insert typed event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+
+	return len(qBackendAPIKeys), nil
+}
@@ -1,15 +1,20 @@
 package store
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"text/template"
-
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
+	"github.com/tesseral-labs/tesseral/internal/emailtemplate"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
 )
 
 type SendEmailVerifyEmailRequest struct {
@@ -49,56 +54,28 @@ func (s *Store) SendEmailVerifyEmail(ctx context.Context, req *SendEmailVerifyEm
 		vaultDomain = s.ConsoleDomain
 	}
 
-	var body bytes.Buffer
-	if err := emailVerificationEmailBodyTmpl.Execute(&body, struct {
-		ProjectDisplayName    string
-		EmailVerificationLink string
-		EmailVerificationCode string
-	}{
-		ProjectDisplayName:    qProject.DisplayName,
-		EmailVerificationLink: fmt.Sprintf("https://%s/verify-email?code=%s", vaultDomain, req.EmailVerificationCode),
-		EmailVerificationCode: req.EmailVerificationCode,
-	}); err != nil {
-		return fmt.Errorf("execute email verification email body template: %w", err)
-	}
-
-	if _, err := s.SES.SendEmail(ctx, &sesv2.SendEmailInput{
-		Content: &types.EmailContent{
-			Simple: &types.Message{
-				Subject: &types.Content{
-					Data: aws.String(fmt.Sprintf("%s - Verify your email address", qProject.DisplayName)),
-				},
-				Body: &types.Body{
-					Text: &types.Content{
-						Data: aws.String(body.String()),
-					},
-				},
-			},
-		},
-		Destination: &types.Destination{
-			ToAddresses: []string{req.EmailAddress},
+	locales, err := s.getEmailLocales(ctx, projectID, req.EmailAddress)
+	if err != nil {
+		return fmt.Errorf("get email locales: %w", err)
+	}
+
+	if err := s.sendTemplatedEmail(ctx, &sendTemplatedEmailParams{
+		Project: qProject,
+		Type:    emailtemplate.TypeVerifyEmail,
+		Locales: locales,
+		To:      req.EmailAddress,
+		Data: &emailtemplate.Data{
+			ProjectDisplayName:    qProject.DisplayName,
+			EmailVerificationLink: fmt.Sprintf("https://%s/verify-email?code=%s", vaultDomain, req.EmailVerificationCode),
+			EmailVerificationCode: req.EmailVerificationCode,
 		},
-		FromEmailAddress: aws.String(fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain)),
 	}); err != nil {
-		return fmt.Errorf("send email: %w", err)
+		return fmt.Errorf("send templated email: %w", err)
 	}
 
 	return nil
 }
 
-var emailVerificationEmailBodyTmpl = template.Must(template.New("emailVerificationEmailBody").Parse(`Hello,
-
-To continue logging in to {{ .ProjectDisplayName }}, please verify your email address by visiting the link below.
-
-{{ .EmailVerificationLink }}
-
-You can also go back to the "Check your email" page and enter this verification code manually:
-
-{{ .EmailVerificationCode }}
-
-If you did not request this verification, please ignore this email.
-`))
-
 type SendEmailPasswordResetRequest struct {
 	ProjectID         string
 	EmailAddress      string
@@ -131,61 +108,27 @@ func (s *Store) SendEmailPasswordReset(ctx context.Context, req *SendEmailPasswo
 		return nil
 	}
 
-	var body bytes.Buffer
-	if err := passwordResetEmailBodyTmpl.Execute(&body, struct {
-		ProjectDisplayName string
-		PasswordResetCode  string
-	}{
-		ProjectDisplayName: qProject.DisplayName,
-		PasswordResetCode:  req.PasswordResetCode,
-	}); err != nil {
-		return fmt.Errorf("execute password reset email body template: %w", err)
-	}
-
-	if _, err := s.SES.SendEmail(ctx, &sesv2.SendEmailInput{
-		Content: &types.EmailContent{
-			Simple: &types.Message{
-				Subject: &types.Content{
-					Data: aws.String(fmt.Sprintf("%s - Reset password", qProject.DisplayName)),
-				},
-				Body: &types.Body{
-					Text: &types.Content{
-						Data: aws.String(body.String()),
-					},
-				},
-			},
-		},
-		Destination: &types.Destination{
-			ToAddresses: []string{req.EmailAddress},
+	locales, err := s.getEmailLocales(ctx, projectID, req.EmailAddress)
+	if err != nil {
+		return fmt.Errorf("get email locales: %w", err)
+	}
+
+	if err := s.sendTemplatedEmail(ctx, &sendTemplatedEmailParams{
+		Project: qProject,
+		Type:    emailtemplate.TypePasswordReset,
+		Locales: locales,
+		To:      req.EmailAddress,
+		Data: &emailtemplate.Data{
+			ProjectDisplayName: qProject.DisplayName,
+			PasswordResetCode:  req.PasswordResetCode,
 		},
-		FromEmailAddress: aws.String(fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain)),
 	}); err != nil {
-		return fmt.Errorf("send email: %w", err)
+		return fmt.Errorf("send templated email: %w", err)
 	}
 
 	return nil
 }
 
-var passwordResetEmailBodyTmpl = template.Must(template.New("passwordResetEmailBody").Parse(`Hello,
-
-Someone has requested a password reset for your {{ .ProjectDisplayName }} account. If you did not request this, please ignore this email.
-
-To continue logging in to {{ .ProjectDisplayName }}, please go back to the "Forgot password" page and enter this verification code:
-
-{{ .PasswordResetCode }}
-
-If you did not request this verification, please ignore this email.
-`))
-
-var userInviteEmailBodyTmpl = template.Must(template.New("userInviteEmailBodyTmpl").Parse(`Hello,
-
-You have been invited to join {{ .OrganizationDisplayName }} in {{ .ProjectDisplayName }}.
-
-You can accept this invite by signing up for {{ .ProjectDisplayName }}:
-
-{{ .SignupLink }}
-`))
-
 type SendEmailUserInviteRequest struct {
 	ProjectID    string
 	UserInviteID string
@@ -236,39 +179,182 @@ func (s *Store) SendEmailUserInvite(ctx context.Context, req *SendEmailUserInvit
 		vaultDomain = s.ConsoleDomain
 	}
 
-	var body bytes.Buffer
-	if err := userInviteEmailBodyTmpl.Execute(&body, struct {
-		ProjectDisplayName      string
-		OrganizationDisplayName string
-		SignupLink              string
-	}{
-		ProjectDisplayName:      qProject.DisplayName,
-		OrganizationDisplayName: qOrganization.DisplayName,
-		SignupLink:              fmt.Sprintf("https://%s/signup", vaultDomain),
-	}); err != nil {
-		return fmt.Errorf("execute user invite email body template: %w", err)
-	}
-
-	if _, err := s.SES.SendEmail(ctx, &sesv2.SendEmailInput{
-		Content: &types.EmailContent{
-			Simple: &types.Message{
-				Subject: &types.Content{
-					Data: aws.String(fmt.Sprintf("%s - You've been invited to join %s", qProject.DisplayName, qOrganization.DisplayName)),
-				},
-				Body: &types.Body{
-					Text: &types.Content{
-						Data: aws.String(body.String()),
-					},
-				},
-			},
-		},
-		Destination: &types.Destination{
-			ToAddresses: []string{qUserInvite.Email},
+	// the invitee has no user yet, so the best we can do is their
+	// organization's locale
+	var locales []string
+	if qOrganization.Locale != nil {
+		locales = append(locales, *qOrganization.Locale)
+	}
+
+	if err := s.sendTemplatedEmail(ctx, &sendTemplatedEmailParams{
+		Project:      qProject,
+		Type:         emailtemplate.TypeUserInvite,
+		Locales:      locales,
+		To:           qUserInvite.Email,
+		UserInviteID: &qUserInvite.ID,
+		Data: &emailtemplate.Data{
+			ProjectDisplayName:      qProject.DisplayName,
+			OrganizationDisplayName: qOrganization.DisplayName,
+			SignupLink:              fmt.Sprintf("https://%s/signup", vaultDomain),
 		},
-		FromEmailAddress: aws.String(fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain)),
+	}); err != nil {
+		return fmt.Errorf("send templated email: %w", err)
+	}
+
+	return nil
+}
+
+// getEmailLocales returns the preferred locales, most preferred first, of the
+// user in projectID with the given email. If several users in the project
+// share the email, the most recently updated one is used.
+func (s *Store) getEmailLocales(ctx context.Context, projectID uuid.UUID, email string) ([]string, error) {
+	qLocales, err := s.q().GetEmailLocalesByProjectIDAndEmail(ctx, queries.GetEmailLocalesByProjectIDAndEmailParams{
+		ProjectID: projectID,
+		Email:     email,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get email locales: %w", err)
+	}
+
+	var locales []string
+	if qLocales.UserLocale != nil {
+		locales = append(locales, *qLocales.UserLocale)
+	}
+	if qLocales.OrganizationLocale != nil {
+		locales = append(locales, *qLocales.OrganizationLocale)
+	}
+	return locales, nil
+}
+
+type sendTemplatedEmailParams struct {
+	Project queries.Project
+	Type    emailtemplate.Type
+
+	// Locales are the recipient's preferred locales, most preferred first.
+	Locales []string
+
+	To string
+
+	// UserInviteID, if set, is the user invite whose delivery status tracks
+	// this email.
+	UserInviteID *uuid.UUID
+
+	Data *emailtemplate.Data
+}
+
+// sendTemplatedEmail renders the project's template for the recipient's
+// locales, branded with the project's logo and primary color, and sends it,
+// unless the recipient is on the project's suppression list. Either way, it
+// records an email event.
+func (s *Store) sendTemplatedEmail(ctx context.Context, params *sendTemplatedEmailParams) error {
+	qProject := params.Project
+
+	qEmailSuppression, err := s.getEmailSuppression(ctx, qProject.ID, params.To)
+	if err != nil {
+		return fmt.Errorf("get email suppression: %w", err)
+	}
+
+	if qEmailSuppression != nil {
+		slog.InfoContext(ctx, "send_templated_email_suppressed", "type", params.Type, "reason", qEmailSuppression.Reason)
+
+		if err := s.createSentEmailEvent(ctx, params, queries.EmailEventTypeSuppressed, nil, fmt.Sprintf("recipient is suppressed due to a previous %s", qEmailSuppression.Reason)); err != nil {
+			return fmt.Errorf("create email event: %w", err)
+		}
+		return nil
+	}
+
+	qProjectUISettings, err := s.q().GetProjectUISettings(ctx, qProject.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("get project ui settings: %w", err)
+	}
+
+	qEmailTemplates, err := s.q().ListEmailTemplatesByProjectIDAndType(ctx, queries.ListEmailTemplatesByProjectIDAndTypeParams{
+		ProjectID: qProject.ID,
+		Type:      queries.EmailTemplateType(params.Type),
+	})
+	if err != nil {
+		return fmt.Errorf("list email templates: %w", err)
+	}
+
+	overrides := map[string]*emailtemplate.Template{}
+	for _, qEmailTemplate := range qEmailTemplates {
+		overrides[qEmailTemplate.Locale] = &emailtemplate.Template{
+			Subject:  qEmailTemplate.Subject,
+			TextBody: qEmailTemplate.TextBody,
+			HTMLBody: qEmailTemplate.HtmlBody,
+		}
+	}
+
+	// logos uploaded to the user content bucket are only reachable through
+	// short-lived presigned urls, so only use a logo that has an explicit url
+	data := params.Data
+	data.LogoURL = derefOrEmpty(qProjectUISettings.LogoUrl)
+	data.PrimaryColor = derefOrEmpty(qProjectUISettings.PrimaryColor)
+
+	tmpl, locale := emailtemplate.Resolve(params.Type, overrides, params.Locales...)
+	email, err := emailtemplate.Render(tmpl, data)
+	if err != nil {
+		return fmt.Errorf("render email template: %w", err)
+	}
+
+	slog.InfoContext(ctx, "send_templated_email", "type", params.Type, "locale", locale, "override", overrides[locale] == tmpl)
+
+	messageID := uuid.New()
+	if err := s.EmailSender.Send(ctx, &emailsender.Message{
+		ID:       messageID.String(),
+		From:     fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain),
+		To:       []string{params.To},
+		Subject:  email.Subject,
+		TextBody: email.TextBody,
+		HTMLBody: email.HTMLBody,
 	}); err != nil {
 		return fmt.Errorf("send email: %w", err)
 	}
 
+	if err := s.createSentEmailEvent(ctx, params, queries.EmailEventTypeSent, &messageID, ""); err != nil {
+		return fmt.Errorf("create email event: %w", err)
+	}
+
+	return nil
+}
+
+// createSentEmailEvent records the outcome of sendTemplatedEmail, and updates
+// the delivery status of the user invite the email is for, if any.
+func (s *Store) createSentEmailEvent(ctx context.Context, params *sendTemplatedEmailParams, eventType queries.EmailEventType, messageID *uuid.UUID, detail string) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := queries.New(tx)
+	if _, err := q.CreateEmailEvent(ctx, queries.CreateEmailEventParams{
+		ID:           uuidv7.NewWithTime(time.Now()),
+		ProjectID:    params.Project.ID,
+		MessageID:    messageID,
+		EmailAddress: strings.ToLower(params.To),
+		Type:         eventType,
+		UserInviteID: params.UserInviteID,
+		Detail:       refOrNil(detail),
+	}); err != nil {
+		return fmt.Errorf("create email event: %w", err)
+	}
+
+	if params.UserInviteID != nil {
+		if err := q.UpdateUserInviteEmailDeliveryStatus(ctx, queries.UpdateUserInviteEmailDeliveryStatusParams{
+			ID:                  *params.UserInviteID,
+			EmailDeliveryStatus: queries.NullEmailEventType{EmailEventType: eventType, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("update user invite email delivery status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/emailevent"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// RecordEmailEvent records a delivery notification about an email sent by
+// sendTemplatedEmail. Permanent bounces and complaints add the recipients to
+// the project's suppression list.
+//
+// The notification is attributed to a project by its message ID. If it has
+// none, or the message is unknown, the project is inferred from the domain
+// the email was sent from, if exactly one project sends from it.
+func (s *Store) RecordEmailEvent(ctx context.Context, event *emailevent.Event) error {
+	var projectID uuid.UUID
+	var messageID, userInviteID *uuid.UUID
+
+	if id, err := uuid.Parse(event.MessageID); err == nil {
+		qSentEmailEvent, err := s.q().GetSentEmailEventByMessageID(ctx, &id)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("get sent email event by message id: %w", err)
+		}
+
+		if err == nil {
+			projectID = qSentEmailEvent.ProjectID
+			messageID = &id
+			userInviteID = qSentEmailEvent.UserInviteID
+		}
+	}
+
+	if projectID == uuid.Nil {
+		projectIDs, err := s.q().ListProjectIDsByEmailSendFromDomain(ctx, event.SourceDomain())
+		if err != nil {
+			return fmt.Errorf("list project ids by email send from domain: %w", err)
+		}
+
+		if len(projectIDs) != 1 {
+			slog.WarnContext(ctx, "email_event_unattributed", "type", event.Type, "message_id", event.MessageID, "source_domain", event.SourceDomain(), "matching_projects", len(projectIDs))
+			return nil
+		}
+
+		projectID = projectIDs[0]
+	}
+
+	var eventType queries.EmailEventType
+	var suppressionReason queries.EmailSuppressionReason
+	switch event.Type {
+	case emailevent.TypeDelivered:
+		eventType = queries.EmailEventTypeDelivered
+	case emailevent.TypeBounced:
+		eventType = queries.EmailEventTypeBounced
+		suppressionReason = queries.EmailSuppressionReasonBounce
+	case emailevent.TypeComplained:
+		eventType = queries.EmailEventTypeComplained
+		suppressionReason = queries.EmailSuppressionReasonComplaint
+	default:
+		return fmt.Errorf("unknown email event type: %q", event.Type)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := queries.New(tx)
+	for _, recipient := range event.Recipients {
+		if _, err := q.CreateEmailEvent(ctx, queries.CreateEmailEventParams{
+			ID:           uuidv7.NewWithTime(time.Now()),
+			ProjectID:    projectID,
+			MessageID:    messageID,
+			EmailAddress: strings.ToLower(recipient),
+			Type:         eventType,
+			UserInviteID: userInviteID,
+			Detail:       refOrNil(event.Detail),
+		}); err != nil {
+			return fmt.Errorf("create email event: %w", err)
+		}
+
+		if event.Permanent && suppressionReason != "" {
+			qEmailSuppressions, err := q.CreateEmailSuppressionIfNotExists(ctx, queries.CreateEmailSuppressionIfNotExistsParams{
+				ID:           uuidv7.NewWithTime(time.Now()),
+				ProjectID:    projectID,
+				EmailAddress: recipient,
+				Reason:       suppressionReason,
+			})
+			if err != nil {
+				return fmt.Errorf("create email suppression if not exists: %w", err)
+			}
+
+			// recipients that were already suppressed aren't suppressed again
+			for _, qEmailSuppression := range qEmailSuppressions {
+				if err := s.logCreateEmailSuppressionAuditEvent(ctx, tx, qEmailSuppression); err != nil {
+					return err
+				}
+
+				slog.InfoContext(ctx, "email_suppressed", "project_id", projectID, "reason", suppressionReason)
+			}
+		}
+	}
+
+	if userInviteID != nil {
+		if err := q.UpdateUserInviteEmailDeliveryStatus(ctx, queries.UpdateUserInviteEmailDeliveryStatusParams{
+			ID:                  *userInviteID,
+			EmailDeliveryStatus: queries.NullEmailEventType{EmailEventType: eventType, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("update user invite email delivery status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) logCreateEmailSuppressionAuditEvent(ctx context.Context, tx pgx.Tx, qEmailSuppression queries.EmailSuppression) error {
+	auditEmailSuppression, err := s.AuditlogStore.GetEmailSuppression(ctx, tx, qEmailSuppression.ID)
+	if err != nil {
+		return fmt.Errorf("get audit email suppression: %w", err)
+	}
+
+	eventDetailsBytes, err := protojson.Marshal(&auditlogv1.CreateEmailSuppression{
+		EmailSuppression: auditEmailSuppression,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal event details: %w", err)
+	}
+
+	eventTime := time.Now()
+	if err := queries.New(tx).CreateAuditLogEvent(ctx, queries.CreateAuditLogEventParams{
+		ID:           uuidv7.NewWithTime(eventTime),
+		ProjectID:    qEmailSuppression.ProjectID,
+		EventName:    "tesseral.email_suppressions.create",
+		EventTime:    &eventTime,
+		EventDetails: eventDetailsBytes,
+	}); err != nil {
+		return fmt.Errorf("create audit log event: %w", err)
+	}
+
+	return nil
+}
+
+// getEmailSuppression returns the suppression for email in projectID, or nil
+// if email is not suppressed.
+func (s *Store) getEmailSuppression(ctx context.Context, projectID uuid.UUID, email string) (*queries.EmailSuppression, error) {
+	qEmailSuppression, err := s.q().GetEmailSuppression(ctx, queries.GetEmailSuppressionParams{
+		ProjectID:    projectID,
+		EmailAddress: email,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get email suppression: %w", err)
+	}
+
+	return &qEmailSuppression, nil
+}
@@ -7,6 +7,7 @@ package queries
 import (
 	"database/sql/driver"
 	"fmt"
+	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,16 +17,19 @@ import (
 type AuditLogEventResourceType string
 
 const (
-	AuditLogEventResourceTypeApiKey         AuditLogEventResourceType = "api_key"
-	AuditLogEventResourceTypeOrganization   AuditLogEventResourceType = "organization"
-	AuditLogEventResourceTypePasskey        AuditLogEventResourceType = "passkey"
-	AuditLogEventResourceTypeRole           AuditLogEventResourceType = "role"
-	AuditLogEventResourceTypeSamlConnection AuditLogEventResourceType = "saml_connection"
-	AuditLogEventResourceTypeScimApiKey     AuditLogEventResourceType = "scim_api_key"
-	AuditLogEventResourceTypeSession        AuditLogEventResourceType = "session"
-	AuditLogEventResourceTypeUser           AuditLogEventResourceType = "user"
-	AuditLogEventResourceTypeUserInvite     AuditLogEventResourceType = "user_invite"
-	AuditLogEventResourceTypeOidcConnection AuditLogEventResourceType = "oidc_connection"
+	AuditLogEventResourceTypeApiKey               AuditLogEventResourceType = "api_key"
+	AuditLogEventResourceTypeOrganization         AuditLogEventResourceType = "organization"
+	AuditLogEventResourceTypePasskey              AuditLogEventResourceType = "passkey"
+	AuditLogEventResourceTypeRole                 AuditLogEventResourceType = "role"
+	AuditLogEventResourceTypeSamlConnection       AuditLogEventResourceType = "saml_connection"
+	AuditLogEventResourceTypeScimApiKey           AuditLogEventResourceType = "scim_api_key"
+	AuditLogEventResourceTypeSession              AuditLogEventResourceType = "session"
+	AuditLogEventResourceTypeUser                 AuditLogEventResourceType = "user"
+	AuditLogEventResourceTypeUserInvite           AuditLogEventResourceType = "user_invite"
+	AuditLogEventResourceTypeOidcConnection       AuditLogEventResourceType = "oidc_connection"
+	AuditLogEventResourceTypeBackendApiKey        AuditLogEventResourceType = "backend_api_key"
+	AuditLogEventResourceTypeRelationship         AuditLogEventResourceType = "relationship"
+	AuditLogEventResourceTypeRoleElevationRequest AuditLogEventResourceType = "role_elevation_request"
 )
 
 func (e *AuditLogEventResourceType) Scan(src interface{}) error {
@@ -63,6 +67,49 @@ func (ns NullAuditLogEventResourceType) Value() (driver.Value, error) {
 	return string(ns.AuditLogEventResourceType), nil
 }
 
+type AuditLogStreamType string
+
+const (
+	AuditLogStreamTypeWebhook   AuditLogStreamType = "webhook"
+	AuditLogStreamTypeSyslog    AuditLogStreamType = "syslog"
+	AuditLogStreamTypeSplunkHec AuditLogStreamType = "splunk_hec"
+)
+
+func (e *AuditLogStreamType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AuditLogStreamType(s)
+	case string:
+		*e = AuditLogStreamType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AuditLogStreamType: %T", src)
+	}
+	return nil
+}
+
+type NullAuditLogStreamType struct {
+	AuditLogStreamType AuditLogStreamType
+	Valid              bool // Valid is true if AuditLogStreamType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAuditLogStreamType) Scan(value interface{}) error {
+	if value == nil {
+		ns.AuditLogStreamType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AuditLogStreamType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAuditLogStreamType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AuditLogStreamType), nil
+}
+
 type AuthMethod string
 
 const (
@@ -106,6 +153,136 @@ func (ns NullAuthMethod) Value() (driver.Value, error) {
 	return string(ns.AuthMethod), nil
 }
 
+type EmailEventType string
+
+const (
+	EmailEventTypeSent       EmailEventType = "sent"
+	EmailEventTypeDelivered  EmailEventType = "delivered"
+	EmailEventTypeBounced    EmailEventType = "bounced"
+	EmailEventTypeComplained EmailEventType = "complained"
+	EmailEventTypeSuppressed EmailEventType = "suppressed"
+)
+
+func (e *EmailEventType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmailEventType(s)
+	case string:
+		*e = EmailEventType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmailEventType: %T", src)
+	}
+	return nil
+}
+
+type NullEmailEventType struct {
+	EmailEventType EmailEventType
+	Valid          bool // Valid is true if EmailEventType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmailEventType) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmailEventType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmailEventType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmailEventType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmailEventType), nil
+}
+
+type EmailSuppressionReason string
+
+const (
+	EmailSuppressionReasonBounce    EmailSuppressionReason = "bounce"
+	EmailSuppressionReasonComplaint EmailSuppressionReason = "complaint"
+)
+
+func (e *EmailSuppressionReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmailSuppressionReason(s)
+	case string:
+		*e = EmailSuppressionReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmailSuppressionReason: %T", src)
+	}
+	return nil
+}
+
+type NullEmailSuppressionReason struct {
+	EmailSuppressionReason EmailSuppressionReason
+	Valid                  bool // Valid is true if EmailSuppressionReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmailSuppressionReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmailSuppressionReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmailSuppressionReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmailSuppressionReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmailSuppressionReason), nil
+}
+
+type EmailTemplateType string
+
+const (
+	EmailTemplateTypeVerifyEmail   EmailTemplateType = "verify_email"
+	EmailTemplateTypePasswordReset EmailTemplateType = "password_reset"
+	EmailTemplateTypeUserInvite    EmailTemplateType = "user_invite"
+)
+
+func (e *EmailTemplateType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmailTemplateType(s)
+	case string:
+		*e = EmailTemplateType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmailTemplateType: %T", src)
+	}
+	return nil
+}
+
+type NullEmailTemplateType struct {
+	EmailTemplateType EmailTemplateType
+	Valid             bool // Valid is true if EmailTemplateType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmailTemplateType) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmailTemplateType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmailTemplateType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmailTemplateType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmailTemplateType), nil
+}
+
 type LogInLayout string
 
 const (
@@ -159,6 +336,7 @@ const (
 	PrimaryAuthFactorGithub        PrimaryAuthFactor = "github"
 	PrimaryAuthFactorPassword      PrimaryAuthFactor = "password"
 	PrimaryAuthFactorOidc          PrimaryAuthFactor = "oidc"
+	PrimaryAuthFactorPasskey       PrimaryAuthFactor = "passkey"
 )
 
 func (e *PrimaryAuthFactor) Scan(src interface{}) error {
@@ -196,6 +374,92 @@ func (ns NullPrimaryAuthFactor) Value() (driver.Value, error) {
 	return string(ns.PrimaryAuthFactor), nil
 }
 
+type RoleElevationRequestStatus string
+
+const (
+	RoleElevationRequestStatusPending  RoleElevationRequestStatus = "pending"
+	RoleElevationRequestStatusApproved RoleElevationRequestStatus = "approved"
+	RoleElevationRequestStatusDenied   RoleElevationRequestStatus = "denied"
+)
+
+func (e *RoleElevationRequestStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RoleElevationRequestStatus(s)
+	case string:
+		*e = RoleElevationRequestStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RoleElevationRequestStatus: %T", src)
+	}
+	return nil
+}
+
+type NullRoleElevationRequestStatus struct {
+	RoleElevationRequestStatus RoleElevationRequestStatus
+	Valid                      bool // Valid is true if RoleElevationRequestStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRoleElevationRequestStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.RoleElevationRequestStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RoleElevationRequestStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRoleElevationRequestStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RoleElevationRequestStatus), nil
+}
+
+type WebhookDeliveryState string
+
+const (
+	WebhookDeliveryStateSucceeded    WebhookDeliveryState = "succeeded"
+	WebhookDeliveryStateFailed       WebhookDeliveryState = "failed"
+	WebhookDeliveryStateDeadLettered WebhookDeliveryState = "dead_lettered"
+)
+
+func (e *WebhookDeliveryState) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryState(s)
+	case string:
+		*e = WebhookDeliveryState(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryState: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryState struct {
+	WebhookDeliveryState WebhookDeliveryState
+	Valid                bool // Valid is true if WebhookDeliveryState is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryState) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryState, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryState.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryState) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryState), nil
+}
+
 type Action struct {
 	ID          uuid.UUID
 	ProjectID   uuid.UUID
@@ -204,14 +468,19 @@ type Action struct {
 }
 
 type ApiKey struct {
-	ID                uuid.UUID
-	OrganizationID    uuid.UUID
-	DisplayName       string
-	SecretTokenSha256 []byte
-	SecretTokenSuffix *string
-	ExpireTime        *time.Time
-	CreateTime        *time.Time
-	UpdateTime        *time.Time
+	ID                            uuid.UUID
+	OrganizationID                uuid.UUID
+	DisplayName                   string
+	SecretTokenSha256             []byte
+	SecretTokenSuffix             *string
+	ExpireTime                    *time.Time
+	CreateTime                    *time.Time
+	UpdateTime                    *time.Time
+	RateLimitPerMinute            *int32
+	PreviousSecretTokenSha256     []byte
+	PreviousSecretTokenExpireTime *time.Time
+	UserID                        *uuid.UUID
+	Actions                       []string
 }
 
 type ApiKeyRoleAssignment struct {
@@ -221,6 +490,13 @@ type ApiKeyRoleAssignment struct {
 	CreateTime *time.Time
 }
 
+type ApiKeyUsageBucket struct {
+	ApiKeyID         uuid.UUID
+	StartTime        *time.Time
+	RequestCount     int64
+	RateLimitedCount int64
+}
+
 type AuditLogEvent struct {
 	ID                         uuid.UUID
 	ProjectID                  uuid.UUID
@@ -238,6 +514,27 @@ type AuditLogEvent struct {
 	EventTime                  *time.Time
 	EventDetails               []byte
 	ActorScimApiKeyID          *uuid.UUID
+	TransactionID              int64
+}
+
+type AuditLogStream struct {
+	ID                       uuid.UUID
+	ProjectID                uuid.UUID
+	OrganizationID           *uuid.UUID
+	DisplayName              string
+	Type                     AuditLogStreamType
+	Enabled                  bool
+	WebhookUrl               *string
+	SyslogAddress            *string
+	SyslogTls                bool
+	SplunkHecUrl             *string
+	SplunkHecTokenCiphertext []byte
+	CursorAuditLogEventID    uuid.UUID
+	LastError                *string
+	LastErrorTime            *time.Time
+	CreateTime               *time.Time
+	UpdateTime               *time.Time
+	CursorTransactionID      int64
 }
 
 type BackendApiKey struct {
@@ -248,6 +545,50 @@ type BackendApiKey struct {
 	CreateTime         *time.Time
 	UpdateTime         *time.Time
 	AuthenticationOnly bool
+	Scopes             []string
+	OrganizationIds    []uuid.UUID
+	ExpireTime         *time.Time
+	ExpireLogged       bool
+	LastUsedTime       *time.Time
+	LastUsedIp         *string
+	IpAllowlistCidrs   []netip.Prefix
+}
+
+type BackendApiKeyIp struct {
+	BackendApiKeyID uuid.UUID
+	Ip              string
+	CreateTime      *time.Time
+}
+
+type EmailEvent struct {
+	ID           uuid.UUID
+	ProjectID    uuid.UUID
+	MessageID    *uuid.UUID
+	EmailAddress string
+	Type         EmailEventType
+	UserInviteID *uuid.UUID
+	Detail       *string
+	CreateTime   *time.Time
+}
+
+type EmailSuppression struct {
+	ID           uuid.UUID
+	ProjectID    uuid.UUID
+	EmailAddress string
+	Reason       EmailSuppressionReason
+	CreateTime   *time.Time
+}
+
+type EmailTemplate struct {
+	ID         uuid.UUID
+	ProjectID  uuid.UUID
+	Type       EmailTemplateType
+	Locale     string
+	Subject    string
+	TextBody   string
+	HtmlBody   string
+	CreateTime *time.Time
+	UpdateTime *time.Time
 }
 
 type IntermediateSession struct {
@@ -292,6 +633,8 @@ type IntermediateSession struct {
 	OidcState                             *string
 	OidcCodeVerifier                      *string
 	VerifiedOidcConnectionID              *uuid.UUID
+	VerifiedPasskeyID                     *uuid.UUID
+	PasswordExpired                       bool
 }
 
 type OauthVerifiedEmail struct {
@@ -316,25 +659,29 @@ type OidcConnection struct {
 }
 
 type Organization struct {
-	ID                        uuid.UUID
-	ProjectID                 uuid.UUID
-	DisplayName               string
-	ScimEnabled               bool
-	CreateTime                *time.Time
-	UpdateTime                *time.Time
-	LoginsDisabled            bool
-	LogInWithGoogle           bool
-	LogInWithMicrosoft        bool
-	LogInWithPassword         bool
-	LogInWithAuthenticatorApp bool
-	LogInWithPasskey          bool
-	RequireMfa                bool
-	LogInWithEmail            bool
-	LogInWithSaml             bool
-	CustomRolesEnabled        bool
-	LogInWithGithub           bool
-	ApiKeysEnabled            bool
-	LogInWithOidc             bool
+	ID                             uuid.UUID
+	ProjectID                      uuid.UUID
+	DisplayName                    string
+	ScimEnabled                    bool
+	CreateTime                     *time.Time
+	UpdateTime                     *time.Time
+	LoginsDisabled                 bool
+	LogInWithGoogle                bool
+	LogInWithMicrosoft             bool
+	LogInWithPassword              bool
+	LogInWithAuthenticatorApp      bool
+	LogInWithPasskey               bool
+	RequireMfa                     bool
+	LogInWithEmail                 bool
+	LogInWithSaml                  bool
+	CustomRolesEnabled             bool
+	LogInWithGithub                bool
+	ApiKeysEnabled                 bool
+	LogInWithOidc                  bool
+	PasskeyRequireAttestation      bool
+	PasskeyRequireUserVerification bool
+	Locale                         *string
+	LogInWithPasswordlessPasskey   bool
 }
 
 type OrganizationDomain struct {
@@ -349,12 +696,39 @@ type OrganizationGoogleHostedDomain struct {
 	GoogleHostedDomain string
 }
 
+type OrganizationIpAllowlistCidr struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	Cidr           netip.Prefix
+}
+
 type OrganizationMicrosoftTenantID struct {
 	ID                uuid.UUID
 	OrganizationID    uuid.UUID
 	MicrosoftTenantID string
 }
 
+type OrganizationPasskeyAaguid struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	Aaguid         uuid.UUID
+	Allowed        bool
+}
+
+type OrganizationPasswordPolicy struct {
+	OrganizationID         uuid.UUID
+	MinLength              int32
+	RequireLowercase       bool
+	RequireUppercase       bool
+	RequireDigit           bool
+	RequireSymbol          bool
+	DisallowPersonalInfo   bool
+	HistoryCount           int32
+	MaxAgeDays             int32
+	LockoutAttempts        int32
+	LockoutDurationSeconds int32
+}
+
 type Passkey struct {
 	ID           uuid.UUID
 	UserID       uuid.UUID
@@ -365,6 +739,7 @@ type Passkey struct {
 	Aaguid       string
 	Disabled     bool
 	RpID         string
+	SignCount    int64
 }
 
 type Project struct {
@@ -405,6 +780,7 @@ type Project struct {
 	CustomEmailVerifyEmail               bool
 	CustomEmailPasswordReset             bool
 	CustomEmailUserInvite                bool
+	LogInWithPasswordlessPasskey         bool
 }
 
 type ProjectEmailQuotaDailyUsage struct {
@@ -424,6 +800,20 @@ type ProjectOnboardingProgress struct {
 	UpdateTime                  *time.Time
 }
 
+type ProjectPasswordPolicy struct {
+	ProjectID              uuid.UUID
+	MinLength              int32
+	RequireLowercase       bool
+	RequireUppercase       bool
+	RequireDigit           bool
+	RequireSymbol          bool
+	DisallowPersonalInfo   bool
+	HistoryCount           int32
+	MaxAgeDays             int32
+	LockoutAttempts        int32
+	LockoutDurationSeconds int32
+}
+
 type ProjectTrustedDomain struct {
 	ID        uuid.UUID
 	ProjectID uuid.UUID
@@ -447,12 +837,15 @@ type ProjectUiSetting struct {
 }
 
 type ProjectWebhookSetting struct {
-	ID               uuid.UUID
-	ProjectID        uuid.UUID
-	AppID            *string
-	CreateTime       *time.Time
-	UpdateTime       *time.Time
-	DirectWebhookUrl *string
+	ID                              uuid.UUID
+	ProjectID                       uuid.UUID
+	AppID                           *string
+	CreateTime                      *time.Time
+	UpdateTime                      *time.Time
+	DirectWebhookUrl                *string
+	SigningSecretCiphertext         []byte
+	PreviousSigningSecretCiphertext []byte
+	PreviousSigningSecretExpireTime *time.Time
 }
 
 type PublishableKey struct {
@@ -464,6 +857,17 @@ type PublishableKey struct {
 	DevMode     bool
 }
 
+type Relationship struct {
+	ID                 uuid.UUID
+	OrganizationID     uuid.UUID
+	ResourceRelationID uuid.UUID
+	ResourceID         string
+	UserID             *uuid.UUID
+	ApiKeyID           *uuid.UUID
+	RoleID             *uuid.UUID
+	CreateTime         *time.Time
+}
+
 type RelayedSession struct {
 	SessionID                     uuid.UUID
 	RelayedSessionTokenExpireTime *time.Time
@@ -472,14 +876,31 @@ type RelayedSession struct {
 	RelayedRefreshTokenSha256     []byte
 }
 
-type Role struct {
+type ResourceRelation struct {
 	ID             uuid.UUID
-	ProjectID      uuid.UUID
-	OrganizationID *uuid.UUID
-	CreateTime     *time.Time
-	UpdateTime     *time.Time
-	DisplayName    string
+	ResourceTypeID uuid.UUID
+	Name           string
 	Description    string
+	ImpliedBy      []string
+}
+
+type ResourceType struct {
+	ID          uuid.UUID
+	ProjectID   uuid.UUID
+	Name        string
+	Description string
+}
+
+type Role struct {
+	ID              uuid.UUID
+	ProjectID       uuid.UUID
+	OrganizationID  *uuid.UUID
+	CreateTime      *time.Time
+	UpdateTime      *time.Time
+	DisplayName     string
+	Description     string
+	ActionWildcards []string
+	DefaultRole     bool
 }
 
 type RoleAction struct {
@@ -488,6 +909,24 @@ type RoleAction struct {
 	ActionID uuid.UUID
 }
 
+type RoleElevationRequest struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	RoleID          uuid.UUID
+	Reason          string
+	DurationSeconds int32
+	Status          RoleElevationRequestStatus
+	ReviewerUserID  *uuid.UUID
+	ReviewTime      *time.Time
+	CreateTime      *time.Time
+}
+
+type RoleInheritedRole struct {
+	ID              uuid.UUID
+	RoleID          uuid.UUID
+	InheritedRoleID uuid.UUID
+}
+
 type SamlConnection struct {
 	ID                 uuid.UUID
 	OrganizationID     uuid.UUID
@@ -547,6 +986,8 @@ type User struct {
 	DisplayName                         *string
 	ProfilePictureUrl                   *string
 	GithubUserID                        *string
+	PasswordUpdateTime                  *time.Time
+	Locale                              *string
 }
 
 type UserAuthenticatorAppChallenge struct {
@@ -564,22 +1005,78 @@ type UserImpersonationToken struct {
 }
 
 type UserInvite struct {
+	ID                  uuid.UUID
+	OrganizationID      uuid.UUID
+	CreateTime          *time.Time
+	UpdateTime          *time.Time
+	Email               string
+	IsOwner             bool
+	RoleID              *uuid.UUID
+	EmailDeliveryStatus NullEmailEventType
+}
+
+type UserPasswordHistory struct {
 	ID             uuid.UUID
-	OrganizationID uuid.UUID
+	UserID         uuid.UUID
 	CreateTime     *time.Time
-	UpdateTime     *time.Time
-	Email          string
-	IsOwner        bool
-	RoleID         *uuid.UUID
+	PasswordBcrypt string
 }
 
 type UserRoleAssignment struct {
-	ID     uuid.UUID
-	RoleID uuid.UUID
-	UserID uuid.UUID
+	ID         uuid.UUID
+	RoleID     uuid.UUID
+	UserID     uuid.UUID
+	ExpireTime *time.Time
 }
 
 type VaultDomainSetting struct {
 	ProjectID     uuid.UUID
 	PendingDomain string
 }
+
+type WebhookDelivery struct {
+	ID                uuid.UUID
+	ProjectID         uuid.UUID
+	WebhookEndpointID *uuid.UUID
+	MessageID         string
+	EventType         string
+	Payload           []byte
+	Url               string
+	Attempt           int32
+	State             WebhookDeliveryState
+	StatusCode        *int32
+	LatencyMillis     int32
+	ResponseBody      *string
+	Error             *string
+	CreateTime        *time.Time
+}
+
+type WebhookEndpoint struct {
+	ID          uuid.UUID
+	ProjectID   uuid.UUID
+	Url         string
+	Description string
+	EventTypes  []string
+	Enabled     bool
+	CreateTime  *time.Time
+	UpdateTime  *time.Time
+}
+
+type WebhookOutboxEvent struct {
+	ID              uuid.UUID
+	ProjectID       uuid.UUID
+	ResourceKey     string
+	Sequence        int64
+	EventType       string
+	Payload         []byte
+	CreateTime      *time.Time
+	Attempts        int32
+	NextAttemptTime *time.Time
+}
+
+type WebhookResourceSequence struct {
+	ProjectID    uuid.UUID
+	ResourceKey  string
+	LastSequence int64
+	UpdateTime   *time.Time
+}
@@ -7,13 +7,474 @@ package queries
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const advisoryUnlock = `-- name: AdvisoryUnlock :one
+SELECT
+    pg_advisory_unlock(hashtextextended($1::text, 0))
+`
+
+func (q *Queries) AdvisoryUnlock(ctx context.Context, lockKey string) (bool, error) {
+	row := q.db.QueryRow(ctx, advisoryUnlock, lockKey)
+	var pg_advisory_unlock bool
+	err := row.Scan(&pg_advisory_unlock)
+	return pg_advisory_unlock, err
+}
+
+const createAuditLogEvent = `-- name: CreateAuditLogEvent :exec
+INSERT INTO audit_log_events (id, project_id, organization_id, resource_type, resource_id, event_name, event_time, event_details)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateAuditLogEventParams struct {
+	ID             uuid.UUID
+	ProjectID      uuid.UUID
+	OrganizationID *uuid.UUID
+	ResourceType   *AuditLogEventResourceType
+	ResourceID     *uuid.UUID
+	EventName      string
+	EventTime      *time.Time
+	EventDetails   []byte
+}
+
+func (q *Queries) CreateAuditLogEvent(ctx context.Context, arg CreateAuditLogEventParams) error {
+	_, err := q.db.Exec(ctx, createAuditLogEvent,
+		arg.ID,
+		arg.ProjectID,
+		arg.OrganizationID,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.EventName,
+		arg.EventTime,
+		arg.EventDetails,
+	)
+	return err
+}
+
+const createEmailEvent = `-- name: CreateEmailEvent :one
+INSERT INTO email_events (id, project_id, message_id, email_address, type, user_invite_id, detail)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+    id, project_id, message_id, email_address, type, user_invite_id, detail, create_time
+`
+
+type CreateEmailEventParams struct {
+	ID           uuid.UUID
+	ProjectID    uuid.UUID
+	MessageID    *uuid.UUID
+	EmailAddress string
+	Type         EmailEventType
+	UserInviteID *uuid.UUID
+	Detail       *string
+}
+
+func (q *Queries) CreateEmailEvent(ctx context.Context, arg CreateEmailEventParams) (EmailEvent, error) {
+	row := q.db.QueryRow(ctx, createEmailEvent,
+		arg.ID,
+		arg.ProjectID,
+		arg.MessageID,
+		arg.EmailAddress,
+		arg.Type,
+		arg.UserInviteID,
+		arg.Detail,
+	)
+	var i EmailEvent
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.MessageID,
+		&i.EmailAddress,
+		&i.Type,
+		&i.UserInviteID,
+		&i.Detail,
+		&i.CreateTime,
+	)
+	return i, err
+}
+
+const createEmailSuppressionIfNotExists = `-- name: CreateEmailSuppressionIfNotExists :many
+INSERT INTO email_suppressions (id, project_id, email_address, reason)
+    VALUES ($1, $2, lower($4::varchar), $3)
+ON CONFLICT (project_id, email_address)
+    DO NOTHING
+RETURNING
+    id, project_id, email_address, reason, create_time
+`
+
+type CreateEmailSuppressionIfNotExistsParams struct {
+	ID           uuid.UUID
+	ProjectID    uuid.UUID
+	Reason       EmailSuppressionReason
+	EmailAddress string
+}
+
+func (q *Queries) CreateEmailSuppressionIfNotExists(ctx context.Context, arg CreateEmailSuppressionIfNotExistsParams) ([]EmailSuppression, error) {
+	rows, err := q.db.Query(ctx, createEmailSuppressionIfNotExists,
+		arg.ID,
+		arg.ProjectID,
+		arg.Reason,
+		arg.EmailAddress,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmailSuppression
+	for rows.Next() {
+		var i EmailSuppression
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.EmailAddress,
+			&i.Reason,
+			&i.CreateTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createProjectWebhookSettingsSigningSecret = `-- name: CreateProjectWebhookSettingsSigningSecret :one
+UPDATE
+    project_webhook_settings
+SET
+    signing_secret_ciphertext = $2,
+    update_time = now()
+WHERE
+    project_id = $1
+    AND signing_secret_ciphertext IS NULL
+RETURNING
+    id, project_id, app_id, create_time, update_time, direct_webhook_url, signing_secret_ciphertext, previous_signing_secret_ciphertext, previous_signing_secret_expire_time
+`
+
+type CreateProjectWebhookSettingsSigningSecretParams struct {
+	ProjectID               uuid.UUID
+	SigningSecretCiphertext []byte
+}
+
+func (q *Queries) CreateProjectWebhookSettingsSigningSecret(ctx context.Context, arg CreateProjectWebhookSettingsSigningSecretParams) (ProjectWebhookSetting, error) {
+	row := q.db.QueryRow(ctx, createProjectWebhookSettingsSigningSecret, arg.ProjectID, arg.SigningSecretCiphertext)
+	var i ProjectWebhookSetting
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.AppID,
+		&i.CreateTime,
+		&i.UpdateTime,
+		&i.DirectWebhookUrl,
+		&i.SigningSecretCiphertext,
+		&i.PreviousSigningSecretCiphertext,
+		&i.PreviousSigningSecretExpireTime,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, project_id, webhook_endpoint_id, message_id, event_type, payload, url, attempt, state, status_code, latency_millis, response_body, error)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING
+    id, project_id, webhook_endpoint_id, message_id, event_type, payload, url, attempt, state, status_code, latency_millis, response_body, error, create_time
+`
+
+type CreateWebhookDeliveryParams struct {
+	ID                uuid.UUID
+	ProjectID         uuid.UUID
+	WebhookEndpointID *uuid.UUID
+	MessageID         string
+	EventType         string
+	Payload           []byte
+	Url               string
+	Attempt           int32
+	State             WebhookDeliveryState
+	StatusCode        *int32
+	LatencyMillis     int32
+	ResponseBody      *string
+	Error             *string
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, createWebhookDelivery,
+		arg.ID,
+		arg.ProjectID,
+		arg.WebhookEndpointID,
+		arg.MessageID,
+		arg.EventType,
+		arg.Payload,
+		arg.Url,
+		arg.Attempt,
+		arg.State,
+		arg.StatusCode,
+		arg.LatencyMillis,
+		arg.ResponseBody,
+		arg.Error,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.WebhookEndpointID,
+		&i.MessageID,
+		&i.EventType,
+		&i.Payload,
+		&i.Url,
+		&i.Attempt,
+		&i.State,
+		&i.StatusCode,
+		&i.LatencyMillis,
+		&i.ResponseBody,
+		&i.Error,
+		&i.CreateTime,
+	)
+	return i, err
+}
+
+const createWebhookOutboxEvent = `-- name: CreateWebhookOutboxEvent :one
+INSERT INTO webhook_outbox_events (id, project_id, resource_key, sequence, event_type, payload)
+    VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING
+    id, project_id, resource_key, sequence, event_type, payload, create_time, attempts, next_attempt_time
+`
+
+type CreateWebhookOutboxEventParams struct {
+	ID          uuid.UUID
+	ProjectID   uuid.UUID
+	ResourceKey string
+	Sequence    int64
+	EventType   string
+	Payload     []byte
+}
+
+func (q *Queries) CreateWebhookOutboxEvent(ctx context.Context, arg CreateWebhookOutboxEventParams) (WebhookOutboxEvent, error) {
+	row := q.db.QueryRow(ctx, createWebhookOutboxEvent,
+		arg.ID,
+		arg.ProjectID,
+		arg.ResourceKey,
+		arg.Sequence,
+		arg.EventType,
+		arg.Payload,
+	)
+	var i WebhookOutboxEvent
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ResourceKey,
+		&i.Sequence,
+		&i.EventType,
+		&i.Payload,
+		&i.CreateTime,
+		&i.Attempts,
+		&i.NextAttemptTime,
+	)
+	return i, err
+}
+
+const deleteStaleWebhookResourceSequences = `-- name: DeleteStaleWebhookResourceSequences :execrows
+DELETE FROM webhook_resource_sequences
+WHERE update_time < $1
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            webhook_outbox_events
+        WHERE
+            webhook_outbox_events.project_id = webhook_resource_sequences.project_id
+            AND webhook_outbox_events.resource_key = webhook_resource_sequences.resource_key)
+`
+
+func (q *Queries) DeleteStaleWebhookResourceSequences(ctx context.Context, updateTime *time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStaleWebhookResourceSequences, updateTime)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserRoleAssignment = `-- name: DeleteUserRoleAssignment :exec
+DELETE FROM user_role_assignments
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUserRoleAssignment(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserRoleAssignment, id)
+	return err
+}
+
+const deleteWebhookOutboxEvent = `-- name: DeleteWebhookOutboxEvent :exec
+DELETE FROM webhook_outbox_events
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhookOutboxEvent(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteWebhookOutboxEvent, id)
+	return err
+}
+
+const expireAPIKeyPreviousSecretTokens = `-- name: ExpireAPIKeyPreviousSecretTokens :many
+UPDATE
+    api_keys
+SET
+    previous_secret_token_sha256 = NULL
+FROM
+    organizations
+WHERE
+    api_keys.organization_id = organizations.id
+    AND api_keys.id IN (
+        SELECT
+            id
+        FROM
+            api_keys
+        WHERE
+            previous_secret_token_sha256 IS NOT NULL
+            AND previous_secret_token_expire_time <= now()
+        ORDER BY
+            previous_secret_token_expire_time
+        LIMIT $1
+        FOR UPDATE
+            SKIP LOCKED)
+RETURNING
+    api_keys.id,
+    api_keys.organization_id,
+    api_keys.previous_secret_token_expire_time,
+    organizations.project_id
+`
+
+type ExpireAPIKeyPreviousSecretTokensRow struct {
+	ID                            uuid.UUID
+	OrganizationID                uuid.UUID
+	PreviousSecretTokenExpireTime *time.Time
+	ProjectID                     uuid.UUID
+}
+
+func (q *Queries) ExpireAPIKeyPreviousSecretTokens(ctx context.Context, limit int32) ([]ExpireAPIKeyPreviousSecretTokensRow, error) {
+	rows, err := q.db.Query(ctx, expireAPIKeyPreviousSecretTokens, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExpireAPIKeyPreviousSecretTokensRow
+	for rows.Next() {
+		var i ExpireAPIKeyPreviousSecretTokensRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.PreviousSecretTokenExpireTime,
+			&i.ProjectID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAuditLogStream = `-- name: GetAuditLogStream :one
+SELECT
+    id, project_id, organization_id, display_name, type, enabled, webhook_url, syslog_address, syslog_tls, splunk_hec_url, splunk_hec_token_ciphertext, cursor_audit_log_event_id, last_error, last_error_time, create_time, update_time, cursor_transaction_id
+FROM
+    audit_log_streams
+WHERE
+    id = $1
+`
+
+func (q *Queries) GetAuditLogStream(ctx context.Context, id uuid.UUID) (AuditLogStream, error) {
+	row := q.db.QueryRow(ctx, getAuditLogStream, id)
+	var i AuditLogStream
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.OrganizationID,
+		&i.DisplayName,
+		&i.Type,
+		&i.Enabled,
+		&i.WebhookUrl,
+		&i.SyslogAddress,
+		&i.SyslogTls,
+		&i.SplunkHecUrl,
+		&i.SplunkHecTokenCiphertext,
+		&i.CursorAuditLogEventID,
+		&i.LastError,
+		&i.LastErrorTime,
+		&i.CreateTime,
+		&i.UpdateTime,
+		&i.CursorTransactionID,
+	)
+	return i, err
+}
+
+const getEmailLocalesByProjectIDAndEmail = `-- name: GetEmailLocalesByProjectIDAndEmail :one
+SELECT
+    users.locale AS user_locale,
+    organizations.locale AS organization_locale
+FROM
+    users
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    organizations.project_id = $1
+    AND users.email = $2
+ORDER BY
+    users.update_time DESC
+LIMIT 1
+`
+
+type GetEmailLocalesByProjectIDAndEmailParams struct {
+	ProjectID uuid.UUID
+	Email     string
+}
+
+type GetEmailLocalesByProjectIDAndEmailRow struct {
+	UserLocale         *string
+	OrganizationLocale *string
+}
+
+func (q *Queries) GetEmailLocalesByProjectIDAndEmail(ctx context.Context, arg GetEmailLocalesByProjectIDAndEmailParams) (GetEmailLocalesByProjectIDAndEmailRow, error) {
+	row := q.db.QueryRow(ctx, getEmailLocalesByProjectIDAndEmail, arg.ProjectID, arg.Email)
+	var i GetEmailLocalesByProjectIDAndEmailRow
+	err := row.Scan(&i.UserLocale, &i.OrganizationLocale)
+	return i, err
+}
+
+const getEmailSuppression = `-- name: GetEmailSuppression :one
+SELECT
+    id, project_id, email_address, reason, create_time
+FROM
+    email_suppressions
+WHERE
+    project_id = $1
+    AND email_address = lower($2::varchar)
+`
+
+type GetEmailSuppressionParams struct {
+	ProjectID    uuid.UUID
+	EmailAddress string
+}
+
+func (q *Queries) GetEmailSuppression(ctx context.Context, arg GetEmailSuppressionParams) (EmailSuppression, error) {
+	row := q.db.QueryRow(ctx, getEmailSuppression, arg.ProjectID, arg.EmailAddress)
+	var i EmailSuppression
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.EmailAddress,
+		&i.Reason,
+		&i.CreateTime,
+	)
+	return i, err
+}
+
 const getOrganization = `-- name: GetOrganization :one
 SELECT
-    id, project_id, display_name, scim_enabled, create_time, update_time, logins_disabled, log_in_with_google, log_in_with_microsoft, log_in_with_password, log_in_with_authenticator_app, log_in_with_passkey, require_mfa, log_in_with_email, log_in_with_saml, custom_roles_enabled, log_in_with_github, api_keys_enabled, log_in_with_oidc
+    id, project_id, display_name, scim_enabled, create_time, update_time, logins_disabled, log_in_with_google, log_in_with_microsoft, log_in_with_password, log_in_with_authenticator_app, log_in_with_passkey, require_mfa, log_in_with_email, log_in_with_saml, custom_roles_enabled, log_in_with_github, api_keys_enabled, log_in_with_oidc, passkey_require_attestation, passkey_require_user_verification, locale, log_in_with_passwordless_passkey
 FROM
     organizations
 WHERE
@@ -43,13 +504,17 @@ func (q *Queries) GetOrganization(ctx context.Context, id uuid.UUID) (Organizati
 		&i.LogInWithGithub,
 		&i.ApiKeysEnabled,
 		&i.LogInWithOidc,
+		&i.PasskeyRequireAttestation,
+		&i.PasskeyRequireUserVerification,
+		&i.Locale,
+		&i.LogInWithPasswordlessPasskey,
 	)
 	return i, err
 }
 
 const getProject = `-- name: GetProject :one
 SELECT
-    id, organization_id, log_in_with_password, log_in_with_google, log_in_with_microsoft, google_oauth_client_id, microsoft_oauth_client_id, google_oauth_client_secret_ciphertext, microsoft_oauth_client_secret_ciphertext, display_name, create_time, update_time, logins_disabled, log_in_with_authenticator_app, log_in_with_passkey, log_in_with_email, log_in_with_saml, redirect_uri, after_login_redirect_uri, after_signup_redirect_uri, vault_domain, email_send_from_domain, cookie_domain, email_quota_daily, stripe_customer_id, entitled_custom_vault_domains, entitled_backend_api_keys, log_in_with_github, github_oauth_client_id, github_oauth_client_secret_ciphertext, api_keys_enabled, api_key_secret_token_prefix, audit_logs_enabled, log_in_with_oidc, custom_email_verify_email, custom_email_password_reset, custom_email_user_invite
+    id, organization_id, log_in_with_password, log_in_with_google, log_in_with_microsoft, google_oauth_client_id, microsoft_oauth_client_id, google_oauth_client_secret_ciphertext, microsoft_oauth_client_secret_ciphertext, display_name, create_time, update_time, logins_disabled, log_in_with_authenticator_app, log_in_with_passkey, log_in_with_email, log_in_with_saml, redirect_uri, after_login_redirect_uri, after_signup_redirect_uri, vault_domain, email_send_from_domain, cookie_domain, email_quota_daily, stripe_customer_id, entitled_custom_vault_domains, entitled_backend_api_keys, log_in_with_github, github_oauth_client_id, github_oauth_client_secret_ciphertext, api_keys_enabled, api_key_secret_token_prefix, audit_logs_enabled, log_in_with_oidc, custom_email_verify_email, custom_email_password_reset, custom_email_user_invite, log_in_with_passwordless_passkey
 FROM
     projects
 WHERE
@@ -97,13 +562,44 @@ func (q *Queries) GetProject(ctx context.Context, id uuid.UUID) (Project, error)
 		&i.CustomEmailVerifyEmail,
 		&i.CustomEmailPasswordReset,
 		&i.CustomEmailUserInvite,
+		&i.LogInWithPasswordlessPasskey,
+	)
+	return i, err
+}
+
+const getProjectUISettings = `-- name: GetProjectUISettings :one
+SELECT
+    id, project_id, primary_color, detect_dark_mode_enabled, dark_mode_primary_color, create_time, update_time, log_in_layout, auto_create_organizations, self_serve_create_organizations, self_serve_create_users, logo_url, dark_mode_logo_url
+FROM
+    project_ui_settings
+WHERE
+    project_id = $1
+`
+
+func (q *Queries) GetProjectUISettings(ctx context.Context, projectID uuid.UUID) (ProjectUiSetting, error) {
+	row := q.db.QueryRow(ctx, getProjectUISettings, projectID)
+	var i ProjectUiSetting
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.PrimaryColor,
+		&i.DetectDarkModeEnabled,
+		&i.DarkModePrimaryColor,
+		&i.CreateTime,
+		&i.UpdateTime,
+		&i.LogInLayout,
+		&i.AutoCreateOrganizations,
+		&i.SelfServeCreateOrganizations,
+		&i.SelfServeCreateUsers,
+		&i.LogoUrl,
+		&i.DarkModeLogoUrl,
 	)
 	return i, err
 }
 
 const getProjectWebhookSettings = `-- name: GetProjectWebhookSettings :one
 SELECT
-    id, project_id, app_id, create_time, update_time, direct_webhook_url
+    id, project_id, app_id, create_time, update_time, direct_webhook_url, signing_secret_ciphertext, previous_signing_secret_ciphertext, previous_signing_secret_expire_time
 FROM
     project_webhook_settings
 WHERE
@@ -120,13 +616,42 @@ func (q *Queries) GetProjectWebhookSettings(ctx context.Context, projectID uuid.
 		&i.CreateTime,
 		&i.UpdateTime,
 		&i.DirectWebhookUrl,
+		&i.SigningSecretCiphertext,
+		&i.PreviousSigningSecretCiphertext,
+		&i.PreviousSigningSecretExpireTime,
+	)
+	return i, err
+}
+
+const getSentEmailEventByMessageID = `-- name: GetSentEmailEventByMessageID :one
+SELECT
+    id, project_id, message_id, email_address, type, user_invite_id, detail, create_time
+FROM
+    email_events
+WHERE
+    message_id = $1
+    AND type = 'sent'
+`
+
+func (q *Queries) GetSentEmailEventByMessageID(ctx context.Context, messageID *uuid.UUID) (EmailEvent, error) {
+	row := q.db.QueryRow(ctx, getSentEmailEventByMessageID, messageID)
+	var i EmailEvent
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.MessageID,
+		&i.EmailAddress,
+		&i.Type,
+		&i.UserInviteID,
+		&i.Detail,
+		&i.CreateTime,
 	)
 	return i, err
 }
 
 const getUserInvite = `-- name: GetUserInvite :one
 SELECT
-    id, organization_id, create_time, update_time, email, is_owner, role_id
+    id, organization_id, create_time, update_time, email, is_owner, role_id, email_delivery_status
 FROM
     user_invites
 WHERE
@@ -144,6 +669,534 @@ func (q *Queries) GetUserInvite(ctx context.Context, id uuid.UUID) (UserInvite,
 		&i.Email,
 		&i.IsOwner,
 		&i.RoleID,
+		&i.EmailDeliveryStatus,
 	)
 	return i, err
 }
+
+const getWebhookEndpoint = `-- name: GetWebhookEndpoint :one
+SELECT
+    id, project_id, url, description, event_types, enabled, create_time, update_time
+FROM
+    webhook_endpoints
+WHERE
+    id = $1
+`
+
+func (q *Queries) GetWebhookEndpoint(ctx context.Context, id uuid.UUID) (WebhookEndpoint, error) {
+	row := q.db.QueryRow(ctx, getWebhookEndpoint, id)
+	var i WebhookEndpoint
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Enabled,
+		&i.CreateTime,
+		&i.UpdateTime,
+	)
+	return i, err
+}
+
+const incrementWebhookOutboxEventAttempts = `-- name: IncrementWebhookOutboxEventAttempts :one
+UPDATE
+    webhook_outbox_events
+SET
+    attempts = attempts + 1,
+    next_attempt_time = $2
+WHERE
+    id = $1
+RETURNING
+    attempts
+`
+
+type IncrementWebhookOutboxEventAttemptsParams struct {
+	ID              uuid.UUID
+	NextAttemptTime *time.Time
+}
+
+func (q *Queries) IncrementWebhookOutboxEventAttempts(ctx context.Context, arg IncrementWebhookOutboxEventAttemptsParams) (int32, error) {
+	row := q.db.QueryRow(ctx, incrementWebhookOutboxEventAttempts, arg.ID, arg.NextAttemptTime)
+	var attempts int32
+	err := row.Scan(&attempts)
+	return attempts, err
+}
+
+const incrementWebhookResourceSequence = `-- name: IncrementWebhookResourceSequence :one
+INSERT INTO webhook_resource_sequences (project_id, resource_key, last_sequence)
+    VALUES ($1, $2, floor(extract(epoch FROM now()) * 1000)::bigint)
+ON CONFLICT (project_id, resource_key)
+    DO UPDATE SET
+        last_sequence = webhook_resource_sequences.last_sequence + 1,
+        update_time = now()
+    RETURNING
+        last_sequence
+`
+
+type IncrementWebhookResourceSequenceParams struct {
+	ProjectID   uuid.UUID
+	ResourceKey string
+}
+
+func (q *Queries) IncrementWebhookResourceSequence(ctx context.Context, arg IncrementWebhookResourceSequenceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, incrementWebhookResourceSequence, arg.ProjectID, arg.ResourceKey)
+	var last_sequence int64
+	err := row.Scan(&last_sequence)
+	return last_sequence, err
+}
+
+const listAuditLogEventsAfterCursor = `-- name: ListAuditLogEventsAfterCursor :many
+SELECT
+    id, project_id, organization_id, actor_user_id, actor_session_id, actor_api_key_id, actor_console_user_id, actor_console_session_id, actor_backend_api_key_id, actor_intermediate_session_id, resource_type, resource_id, event_name, event_time, event_details, actor_scim_api_key_id, transaction_id
+FROM
+    audit_log_events
+WHERE
+    project_id = $2
+    AND (organization_id = $3
+        OR $3 IS NULL)
+    AND (transaction_id, id) > ($4::bigint, $5::uuid)
+    AND transaction_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY
+    transaction_id,
+    id
+LIMIT $1
+`
+
+type ListAuditLogEventsAfterCursorParams struct {
+	Limit               int32
+	ProjectID           uuid.UUID
+	OrganizationID      *uuid.UUID
+	CursorTransactionID int64
+	CursorID            uuid.UUID
+}
+
+func (q *Queries) ListAuditLogEventsAfterCursor(ctx context.Context, arg ListAuditLogEventsAfterCursorParams) ([]AuditLogEvent, error) {
+	rows, err := q.db.Query(ctx, listAuditLogEventsAfterCursor,
+		arg.Limit,
+		arg.ProjectID,
+		arg.OrganizationID,
+		arg.CursorTransactionID,
+		arg.CursorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLogEvent
+	for rows.Next() {
+		var i AuditLogEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.OrganizationID,
+			&i.ActorUserID,
+			&i.ActorSessionID,
+			&i.ActorApiKeyID,
+			&i.ActorConsoleUserID,
+			&i.ActorConsoleSessionID,
+			&i.ActorBackendApiKeyID,
+			&i.ActorIntermediateSessionID,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.EventName,
+			&i.EventTime,
+			&i.EventDetails,
+			&i.ActorScimApiKeyID,
+			&i.TransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEmailTemplatesByProjectIDAndType = `-- name: ListEmailTemplatesByProjectIDAndType :many
+SELECT
+    id, project_id, type, locale, subject, text_body, html_body, create_time, update_time
+FROM
+    email_templates
+WHERE
+    project_id = $1
+    AND type = $2
+`
+
+type ListEmailTemplatesByProjectIDAndTypeParams struct {
+	ProjectID uuid.UUID
+	Type      EmailTemplateType
+}
+
+func (q *Queries) ListEmailTemplatesByProjectIDAndType(ctx context.Context, arg ListEmailTemplatesByProjectIDAndTypeParams) ([]EmailTemplate, error) {
+	rows, err := q.db.Query(ctx, listEmailTemplatesByProjectIDAndType, arg.ProjectID, arg.Type)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmailTemplate
+	for rows.Next() {
+		var i EmailTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Type,
+			&i.Locale,
+			&i.Subject,
+			&i.TextBody,
+			&i.HtmlBody,
+			&i.CreateTime,
+			&i.UpdateTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledAuditLogStreamIDs = `-- name: ListEnabledAuditLogStreamIDs :many
+SELECT
+    id
+FROM
+    audit_log_streams
+WHERE
+    enabled
+`
+
+func (q *Queries) ListEnabledAuditLogStreamIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listEnabledAuditLogStreamIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledWebhookEndpointsByEventType = `-- name: ListEnabledWebhookEndpointsByEventType :many
+SELECT
+    id, project_id, url, description, event_types, enabled, create_time, update_time
+FROM
+    webhook_endpoints
+WHERE
+    project_id = $1
+    AND enabled
+    AND (cardinality(event_types) = 0
+        OR $2::varchar = ANY (event_types))
+ORDER BY
+    id
+`
+
+type ListEnabledWebhookEndpointsByEventTypeParams struct {
+	ProjectID uuid.UUID
+	EventType string
+}
+
+func (q *Queries) ListEnabledWebhookEndpointsByEventType(ctx context.Context, arg ListEnabledWebhookEndpointsByEventTypeParams) ([]WebhookEndpoint, error) {
+	rows, err := q.db.Query(ctx, listEnabledWebhookEndpointsByEventType, arg.ProjectID, arg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookEndpoint
+	for rows.Next() {
+		var i WebhookEndpoint
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Url,
+			&i.Description,
+			&i.EventTypes,
+			&i.Enabled,
+			&i.CreateTime,
+			&i.UpdateTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredUserRoleAssignments = `-- name: ListExpiredUserRoleAssignments :many
+SELECT
+    user_role_assignments.id,
+    user_role_assignments.user_id,
+    user_role_assignments.expire_time,
+    users.organization_id,
+    organizations.project_id
+FROM
+    user_role_assignments
+    JOIN users ON user_role_assignments.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    user_role_assignments.expire_time <= now()
+ORDER BY
+    user_role_assignments.expire_time
+LIMIT $1
+FOR UPDATE
+    OF user_role_assignments SKIP LOCKED
+`
+
+type ListExpiredUserRoleAssignmentsRow struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	ExpireTime     *time.Time
+	OrganizationID uuid.UUID
+	ProjectID      uuid.UUID
+}
+
+func (q *Queries) ListExpiredUserRoleAssignments(ctx context.Context, limit int32) ([]ListExpiredUserRoleAssignmentsRow, error) {
+	rows, err := q.db.Query(ctx, listExpiredUserRoleAssignments, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListExpiredUserRoleAssignmentsRow
+	for rows.Next() {
+		var i ListExpiredUserRoleAssignmentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ExpireTime,
+			&i.OrganizationID,
+			&i.ProjectID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectIDsByEmailSendFromDomain = `-- name: ListProjectIDsByEmailSendFromDomain :many
+SELECT
+    id
+FROM
+    projects
+WHERE
+    email_send_from_domain = $1
+`
+
+func (q *Queries) ListProjectIDsByEmailSendFromDomain(ctx context.Context, emailSendFromDomain string) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listProjectIDsByEmailSendFromDomain, emailSendFromDomain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookOutboxEvents = `-- name: ListWebhookOutboxEvents :many
+SELECT
+    id, project_id, resource_key, sequence, event_type, payload, create_time, attempts, next_attempt_time
+FROM
+    webhook_outbox_events
+WHERE
+    project_id = $1
+    AND resource_key = $2
+ORDER BY
+    sequence
+LIMIT $3
+`
+
+type ListWebhookOutboxEventsParams struct {
+	ProjectID   uuid.UUID
+	ResourceKey string
+	Limit       int32
+}
+
+func (q *Queries) ListWebhookOutboxEvents(ctx context.Context, arg ListWebhookOutboxEventsParams) ([]WebhookOutboxEvent, error) {
+	rows, err := q.db.Query(ctx, listWebhookOutboxEvents, arg.ProjectID, arg.ResourceKey, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookOutboxEvent
+	for rows.Next() {
+		var i WebhookOutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ResourceKey,
+			&i.Sequence,
+			&i.EventType,
+			&i.Payload,
+			&i.CreateTime,
+			&i.Attempts,
+			&i.NextAttemptTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markExpiredBackendAPIKeysLogged = `-- name: MarkExpiredBackendAPIKeysLogged :many
+UPDATE
+    backend_api_keys
+SET
+    expire_logged = TRUE
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            backend_api_keys
+        WHERE
+            expire_time <= now()
+            AND NOT expire_logged
+        ORDER BY
+            expire_time
+        LIMIT $1
+        FOR UPDATE
+            SKIP LOCKED)
+RETURNING
+    id, project_id, secret_token_sha256, display_name, create_time, update_time, authentication_only, scopes, organization_ids, expire_time, expire_logged, last_used_time, last_used_ip, ip_allowlist_cidrs
+`
+
+func (q *Queries) MarkExpiredBackendAPIKeysLogged(ctx context.Context, limit int32) ([]BackendApiKey, error) {
+	rows, err := q.db.Query(ctx, markExpiredBackendAPIKeysLogged, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BackendApiKey
+	for rows.Next() {
+		var i BackendApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.SecretTokenSha256,
+			&i.DisplayName,
+			&i.CreateTime,
+			&i.UpdateTime,
+			&i.AuthenticationOnly,
+			&i.Scopes,
+			&i.OrganizationIds,
+			&i.ExpireTime,
+			&i.ExpireLogged,
+			&i.LastUsedTime,
+			&i.LastUsedIp,
+			&i.IpAllowlistCidrs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tryAdvisoryLock = `-- name: TryAdvisoryLock :one
+SELECT
+    pg_try_advisory_lock(hashtextextended($1::text, 0))
+`
+
+func (q *Queries) TryAdvisoryLock(ctx context.Context, lockKey string) (bool, error) {
+	row := q.db.QueryRow(ctx, tryAdvisoryLock, lockKey)
+	var pg_try_advisory_lock bool
+	err := row.Scan(&pg_try_advisory_lock)
+	return pg_try_advisory_lock, err
+}
+
+const updateAuditLogStreamCursor = `-- name: UpdateAuditLogStreamCursor :exec
+UPDATE
+    audit_log_streams
+SET
+    cursor_transaction_id = $2,
+    cursor_audit_log_event_id = $3,
+    last_error = NULL,
+    last_error_time = NULL
+WHERE
+    id = $1
+`
+
+type UpdateAuditLogStreamCursorParams struct {
+	ID                    uuid.UUID
+	CursorTransactionID   int64
+	CursorAuditLogEventID uuid.UUID
+}
+
+func (q *Queries) UpdateAuditLogStreamCursor(ctx context.Context, arg UpdateAuditLogStreamCursorParams) error {
+	_, err := q.db.Exec(ctx, updateAuditLogStreamCursor, arg.ID, arg.CursorTransactionID, arg.CursorAuditLogEventID)
+	return err
+}
+
+const updateAuditLogStreamLastError = `-- name: UpdateAuditLogStreamLastError :exec
+UPDATE
+    audit_log_streams
+SET
+    last_error = $2,
+    last_error_time = now()
+WHERE
+    id = $1
+`
+
+type UpdateAuditLogStreamLastErrorParams struct {
+	ID        uuid.UUID
+	LastError *string
+}
+
+func (q *Queries) UpdateAuditLogStreamLastError(ctx context.Context, arg UpdateAuditLogStreamLastErrorParams) error {
+	_, err := q.db.Exec(ctx, updateAuditLogStreamLastError, arg.ID, arg.LastError)
+	return err
+}
+
+const updateUserInviteEmailDeliveryStatus = `-- name: UpdateUserInviteEmailDeliveryStatus :exec
+UPDATE
+    user_invites
+SET
+    email_delivery_status = $2
+WHERE
+    id = $1
+`
+
+type UpdateUserInviteEmailDeliveryStatusParams struct {
+	ID                  uuid.UUID
+	EmailDeliveryStatus NullEmailEventType
+}
+
+func (q *Queries) UpdateUserInviteEmailDeliveryStatus(ctx context.Context, arg UpdateUserInviteEmailDeliveryStatusParams) error {
+	_, err := q.db.Exec(ctx, updateUserInviteEmailDeliveryStatus, arg.ID, arg.EmailDeliveryStatus)
+	return err
+}
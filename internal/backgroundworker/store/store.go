@@ -3,21 +3,44 @@ package store
 import (
 	"net/http"
 
-	"github.com/aws/aws-sdk-go-v2/service/sesv2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	svix "github.com/svix/svix-webhooks/go"
+	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
 	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
+	"github.com/tesseral-labs/tesseral/internal/kms"
+	"github.com/tesseral-labs/tesseral/internal/restrictedhttp"
 )
 
 type Store struct {
-	DB                      *pgxpool.Pool
-	Svix                    *svix.Svix
-	DirectWebhookHTTPClient *http.Client
-	SES                     *sesv2.Client
-	ConsoleProjectID        string
-	ConsoleDomain           string
+	DB                       *pgxpool.Pool
+	Svix                     *svix.Svix
+	DirectWebhookHTTPClient  *http.Client
+	EgressPolicy             *restrictedhttp.Policy
+	WebhookSigningSecretsKMS *kms.KMS
+	AuditLogStreamSecretsKMS *kms.KMS
+	EmailSender              emailsender.Sender
+	ConsoleProjectID         string
+	ConsoleDomain            string
+	AuditlogStore            *auditlogstore.Store
 }
 
 func (s *Store) q() *queries.Queries {
 	return queries.New(s.DB)
 }
+
+func refOrNil[T comparable](t T) *T {
+	var z T
+	if t == z {
+		return nil
+	}
+	return &t
+}
+
+func derefOrEmpty[T any](t *T) T {
+	var z T
+	if t == nil {
+		return z
+	}
+	return *t
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// TypedEventSchemaVersion is the version of the payload schema of typed
+// webhook events, sent as their "version" field. Bump it when making
+// backwards-incompatible changes to typed event payloads.
+const TypedEventSchemaVersion = 1
+
+// TypedEventTypes maps audit log event names to the typed webhook events they
+// are sent as. The typed event's "data" is the audit log event's details, so
+// updates carry both the current and the previous resource.
+var TypedEventTypes = []struct {
+	AuditLogEventName string
+	EventType         string
+}{
+	{"tesseral.organizations.create", "organization.created"},
+	{"tesseral.organizations.update", "organization.updated"},
+	{"tesseral.organizations.delete", "organization.deleted"},
+	{"tesseral.users.create", "user.created"},
+	{"tesseral.users.update", "user.updated"},
+	{"tesseral.users.delete", "user.deleted"},
+	{"tesseral.users.assign_role", "user_role_assignment.created"},
+	{"tesseral.users.unassign_role", "user_role_assignment.deleted"},
+	{"tesseral.role_elevation_requests.create", "role_elevation_request.created"},
+	{"tesseral.role_elevation_requests.approve", "role_elevation_request.approved"},
+	{"tesseral.role_elevation_requests.deny", "role_elevation_request.denied"},
+	{"tesseral.roles.create", "role.created"},
+	{"tesseral.roles.update", "role.updated"},
+	{"tesseral.roles.delete", "role.deleted"},
+	{"tesseral.api_keys.create", "api_key.created"},
+	{"tesseral.api_keys.update", "api_key.updated"},
+	{"tesseral.api_keys.revoke", "api_key.revoked"},
+	{"tesseral.api_keys.delete", "api_key.deleted"},
+	{"tesseral.api_keys.assign_role", "api_key_role_assignment.created"},
+	{"tesseral.api_keys.unassign_role", "api_key_role_assignment.deleted"},
+	{"tesseral.relationships.create", "relationship.created"},
+	{"tesseral.relationships.delete", "relationship.deleted"},
+	{"tesseral.saml_connections.create", "saml_connection.created"},
+	{"tesseral.saml_connections.update", "saml_connection.updated"},
+	{"tesseral.saml_connections.delete", "saml_connection.deleted"},
+	{"tesseral.oidc_connections.create", "oidc_connection.created"},
+	{"tesseral.oidc_connections.update", "oidc_connection.updated"},
+	{"tesseral.oidc_connections.delete", "oidc_connection.deleted"},
+	{"tesseral.user_invites.create", "user_invite.created"},
+	{"tesseral.user_invites.delete", "user_invite.deleted"},
+	{"tesseral.sessions.create", "session.created"},
+}
+
+type NewTypedEventArgsParams struct {
+	ProjectID         uuid.UUID
+	AuditLogEventID   uuid.UUID
+	AuditLogEventName string
+	EventTime         time.Time
+	EventDetails      proto.Message
+}
+
+// TypedEventArgs are the arguments to a River job that sends a typed webhook
+// event to the webhook endpoints subscribed to it.
+//
+// Unlike Args in webhookworker, typed events are never sent to a project's
+// direct webhook URL or Svix app; those only receive the events they always
+// have.
+//
+// They live here, rather than alongside the worker that handles them, so that
+// this package's own audit log writers can enqueue them.
+type TypedEventArgs struct {
+	ProjectID string
+	EventType string
+	Payload   map[string]any
+
+	// MessageID is the audit log event ID, so retried jobs are delivered with
+	// the same message ID.
+	MessageID string
+}
+
+func (TypedEventArgs) Kind() string {
+	return "typed_webhook"
+}
+
+// NewTypedEventArgs returns the args for a job that sends the typed webhook
+// event corresponding to an audit log event. It returns false if the audit log
+// event has no corresponding webhook event.
+func NewTypedEventArgs(params NewTypedEventArgsParams) (TypedEventArgs, bool, error) {
+	eventType, ok := typedEventType(params.AuditLogEventName)
+	if !ok {
+		return TypedEventArgs{}, false, nil
+	}
+
+	eventDetailsBytes, err := protojson.Marshal(params.EventDetails)
+	if err != nil {
+		return TypedEventArgs{}, false, fmt.Errorf("marshal event details: %w", err)
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(eventDetailsBytes, &data); err != nil {
+		return TypedEventArgs{}, false, fmt.Errorf("unmarshal event details: %w", err)
+	}
+
+	messageID := idformat.AuditLogEvent.Format(params.AuditLogEventID)
+	return TypedEventArgs{
+		ProjectID: idformat.Project.Format(params.ProjectID),
+		EventType: eventType,
+		MessageID: messageID,
+		Payload: map[string]any{
+			"type":       eventType,
+			"version":    TypedEventSchemaVersion,
+			"id":         messageID,
+			"createTime": params.EventTime.UTC().Format(time.RFC3339Nano),
+			"data":       data,
+		},
+	}, true, nil
+}
+
+// InsertTypedEventTx enqueues the typed webhook event corresponding to an audit
+// log event as part of tx, if there is one.
+func InsertTypedEventTx(ctx context.Context, tx pgx.Tx, riverClient *river.Client[pgx.Tx], params NewTypedEventArgsParams) error {
+	typedEventArgs, ok, err := NewTypedEventArgs(params)
+	if err != nil {
+		return fmt.Errorf("new typed event args: %w", err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	if _, err := riverClient.InsertTx(ctx, tx, typedEventArgs, nil); err != nil {
+		return fmt.Errorf("insert typed event job: %w", err)
+	}
+
+	return nil
+}
+
+// SendTypedWebhook fans a typed webhook event out to the webhook endpoints
+// subscribed to it.
+func (s *Store) SendTypedWebhook(ctx context.Context, args TypedEventArgs) error {
+	projectID, err := idformat.Project.Parse(args.ProjectID)
+	if err != nil {
+		return fmt.Errorf("parse project id: %w", err)
+	}
+
+	if err := s.enqueueWebhookEndpointJobs(ctx, projectID, args.EventType, args.MessageID, args.Payload); err != nil {
+		return fmt.Errorf("enqueue webhook endpoint jobs: %w", err)
+	}
+
+	return nil
+}
+
+func typedEventType(auditLogEventName string) (string, bool) {
+	for _, t := range TypedEventTypes {
+		if t.AuditLogEventName == auditLogEventName {
+			return t.EventType, true
+		}
+	}
+	return "", false
+}
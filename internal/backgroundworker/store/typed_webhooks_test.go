@@ -0,0 +1,52 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestNewTypedEventArgs(t *testing.T) {
+	projectID := uuid.New()
+	auditLogEventID := uuid.New()
+	eventTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	args, ok, err := NewTypedEventArgs(NewTypedEventArgsParams{
+		ProjectID:         projectID,
+		AuditLogEventID:   auditLogEventID,
+		AuditLogEventName: "tesseral.users.update",
+		EventTime:         eventTime,
+		EventDetails: &auditlogv1.UpdateUser{
+			User:         &auditlogv1.User{Email: "new@example.com"},
+			PreviousUser: &auditlogv1.User{Email: "old@example.com"},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, idformat.Project.Format(projectID), args.ProjectID)
+	require.Equal(t, "user.updated", args.EventType)
+	require.Equal(t, idformat.AuditLogEvent.Format(auditLogEventID), args.MessageID)
+	require.Equal(t, map[string]any{
+		"type":       "user.updated",
+		"version":    TypedEventSchemaVersion,
+		"id":         idformat.AuditLogEvent.Format(auditLogEventID),
+		"createTime": "2025-01-02T03:04:05Z",
+		"data": map[string]any{
+			"user":         map[string]any{"email": "new@example.com"},
+			"previousUser": map[string]any{"email": "old@example.com"},
+		},
+	}, args.Payload)
+}
+
+func TestNewTypedEventArgs_UnmappedEvent(t *testing.T) {
+	_, ok, err := NewTypedEventArgs(NewTypedEventArgsParams{
+		AuditLogEventName: "tesseral.sessions.refresh",
+		EventDetails:      &auditlogv1.UpdateUser{},
+	})
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// expiredUserRoleAssignmentsBatchSize is how many user role assignments
+// ExpireUserRoleAssignments handles per transaction.
+const expiredUserRoleAssignmentsBatchSize = 100
+
+// ExpireUserRoleAssignments deletes every user role assignment that has
+// expired, and logs a "tesseral.users.unassign_role" audit event for each. It
+// returns how many assignments it deleted.
+func (s *Store) ExpireUserRoleAssignments(ctx context.Context) (int, error) {
+	var count int
+	for {
+		n, err := s.expireUserRoleAssignmentsBatch(ctx)
+		if err != nil {
+			return count, err
+		}
+
+		count += n
+		if n < expiredUserRoleAssignmentsBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func (s *Store) expireUserRoleAssignmentsBatch(ctx context.Context) (int, error) {
+	riverClient, err := river.ClientFromContextSafely[pgx.Tx](ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get river client: %w", err)
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := queries.New(tx)
+	qUserRoleAssignments, err := q.ListExpiredUserRoleAssignments(ctx, expiredUserRoleAssignmentsBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list expired user role assignments: %w", err)
+	}
+
+	for _, qUserRoleAssignment := range qUserRoleAssignments {
+		// the audit model must be read before the assignment is deleted
+		auditUserRoleAssignment, err := s.AuditlogStore.GetUserRoleAssignment(ctx, tx, qUserRoleAssignment.ID)
+		if err != nil {
+			return 0, fmt.Errorf("get audit user role assignment: %w", err)
+		}
+
+		if err := q.DeleteUserRoleAssignment(ctx, qUserRoleAssignment.ID); err != nil {
+			return 0, fmt.Errorf("delete user role assignment: %w", err)
+		}
+
+		eventDetails := &auditlogv1.UnassignUserRole{
+			UserRoleAssignment: auditUserRoleAssignment,
+		}
+
+		eventDetailsBytes, err := protojson.Marshal(eventDetails)
+		if err != nil {
+			return 0, fmt.Errorf("marshal event details: %w", err)
+		}
+
+		// the event happened when the assignment expired, not when it was
+		// noticed
+		eventID := uuidv7.NewWithTime(time.Now())
+		eventTime := *qUserRoleAssignment.ExpireTime
+		resourceType := queries.AuditLogEventResourceTypeUser
+		if err := q.CreateAuditLogEvent(ctx, queries.CreateAuditLogEventParams{
+			ID:             eventID,
+			ProjectID:      qUserRoleAssignment.ProjectID,
+			OrganizationID: &qUserRoleAssignment.OrganizationID,
+			ResourceType:   &resourceType,
+			ResourceID:     &qUserRoleAssignment.UserID,
+			EventName:      "tesseral.users.unassign_role",
+			EventTime:      &eventTime,
+			EventDetails:   eventDetailsBytes,
+		}); err != nil {
+			return 0, fmt.Errorf("create audit log event: %w", err)
+		}
+
+		if err := InsertTypedEventTx(ctx, tx, riverClient, NewTypedEventArgsParams{
+			ProjectID:         qUserRoleAssignment.ProjectID,
+			AuditLogEventID:   eventID,
+			AuditLogEventName: "tesseral.users.unassign_role",
+			EventTime:         eventTime,
+			EventDetails:      eventDetails,
+		}); err != nil {
+			return 0, fmt.Errorf("insert typed event: %w", err)
+		}
+
+		userID := idformat.User.Format(qUserRoleAssignment.UserID)
+		sequence, err := EnqueueOrderedWebhookTx(ctx, tx, riverClient, &EnqueueOrderedWebhookRequest{
+			ProjectID:   qUserRoleAssignment.ProjectID,
+			ResourceKey: userID,
+			EventType:   "sync.user_role_assignments",
+			Payload: map[string]any{
+				"type":   "sync.user_role_assignments",
+				"userId": userID,
+			},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("enqueue ordered webhook: %w", err)
+		}
+
+		slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.user_role_assignments", "user_id", userID)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+
+	return len(qUserRoleAssignments), nil
+}
@@ -0,0 +1,304 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// ErrWebhookResourceLocked is returned by SendOrderedWebhooks when another
+// worker is already sending a resource's webhooks. The caller should try again
+// shortly.
+var ErrWebhookResourceLocked = errors.New("webhook resource is locked by another worker")
+
+// WebhookNotDueError is returned by SendOrderedWebhooks when the next webhook
+// in a resource's outbox failed recently and isn't due to be retried yet. The
+// caller should try again at RetryTime.
+type WebhookNotDueError struct {
+	RetryTime time.Time
+}
+
+func (e *WebhookNotDueError) Error() string {
+	return fmt.Sprintf("webhook is not due to be retried until %s", e.RetryTime.Format(time.RFC3339))
+}
+
+const (
+	webhookOutboxBatchSize = 100
+
+	// defaultWebhookOutboxMaxAttempts is how many times a webhook in an outbox
+	// is attempted, if SendOrderedWebhooksRequest doesn't say otherwise.
+	defaultWebhookOutboxMaxAttempts = 10
+
+	// webhookResourceSequenceRetention is how long a resource's sequence is
+	// kept after its last webhook.
+	webhookResourceSequenceRetention = 30 * 24 * time.Hour
+)
+
+// webhookOutboxBackoff returns how long to wait before retrying a webhook in
+// an outbox after its attempt-th failed attempt. It grows with the fourth
+// power of attempt, like River's default retry policy.
+func webhookOutboxBackoff(attempt int) time.Duration {
+	return time.Duration(attempt*attempt*attempt*attempt) * time.Second
+}
+
+// OrderedWebhookArgs are the args for webhookworker.OrderedWorker, which sends
+// the webhooks in a resource's outbox in sequence order.
+//
+// They are defined here rather than in webhookworker so that
+// EnqueueOrderedWebhookTx can insert them without an import cycle.
+type OrderedWebhookArgs struct {
+	ProjectID   string
+	ResourceKey string
+}
+
+func (OrderedWebhookArgs) Kind() string {
+	return "webhook_ordered"
+}
+
+type EnqueueOrderedWebhookRequest struct {
+	ProjectID uuid.UUID
+
+	// ResourceKey identifies the resource the webhook is about, typically its
+	// formatted ID. Webhooks with the same ResourceKey are delivered in the
+	// order they were enqueued in.
+	ResourceKey string
+
+	EventType string
+	Payload   map[string]any
+}
+
+// EnqueueOrderedWebhookTx adds a webhook to a resource's outbox as part of tx,
+// and enqueues a job to deliver it.
+//
+// The webhook is assigned the next sequence number for its resource, which is
+// added to its payload as "sequence" so that receivers can discard stale
+// webhooks. Assigning the sequence number locks the resource's sequence row
+// until tx ends, so sequence order matches commit order.
+//
+// A resource's first sequence number is the current Unix time in
+// milliseconds, rather than 1, so that a resource whose sequence was pruned by
+// PruneWebhookResourceSequences resumes above every sequence number it was
+// sent before.
+func EnqueueOrderedWebhookTx(ctx context.Context, tx pgx.Tx, riverClient *river.Client[pgx.Tx], req *EnqueueOrderedWebhookRequest) (int64, error) {
+	q := queries.New(tx)
+
+	sequence, err := q.IncrementWebhookResourceSequence(ctx, queries.IncrementWebhookResourceSequenceParams{
+		ProjectID:   req.ProjectID,
+		ResourceKey: req.ResourceKey,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("increment webhook resource sequence: %w", err)
+	}
+
+	payload := make(map[string]any, len(req.Payload)+1)
+	for k, v := range req.Payload {
+		payload[k] = v
+	}
+	payload["sequence"] = sequence
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	if _, err := q.CreateWebhookOutboxEvent(ctx, queries.CreateWebhookOutboxEventParams{
+		ID:          uuid.New(),
+		ProjectID:   req.ProjectID,
+		ResourceKey: req.ResourceKey,
+		Sequence:    sequence,
+		EventType:   req.EventType,
+		Payload:     payloadBytes,
+	}); err != nil {
+		return 0, fmt.Errorf("create webhook outbox event: %w", err)
+	}
+
+	if _, err := riverClient.InsertTx(ctx, tx, OrderedWebhookArgs{
+		ProjectID:   idformat.Project.Format(req.ProjectID),
+		ResourceKey: req.ResourceKey,
+	}, nil); err != nil {
+		return 0, fmt.Errorf("insert job: %w", err)
+	}
+
+	return sequence, nil
+}
+
+type SendOrderedWebhooksRequest struct {
+	ProjectID   string
+	ResourceKey string
+
+	// MaxAttempts is how many times a webhook is attempted before it is
+	// dead-lettered. Defaults to 10.
+	MaxAttempts int
+}
+
+// SendOrderedWebhooks sends every webhook in a resource's outbox, in sequence
+// order, removing each from the outbox once sent. If a webhook fails to send,
+// it and every later webhook for the resource stay in the outbox to be sent
+// by a later call, unless it has been attempted MaxAttempts times; it is then
+// dead-lettered and removed, so it doesn't hold up later webhooks forever.
+//
+// A webhook that fails to send isn't retried until a backoff has passed,
+// however many calls are made in the meantime; until then, calls return a
+// WebhookNotDueError without attempting it. This way, only attempts count
+// towards MaxAttempts, not calls.
+//
+// Each webhook is sent synchronously, to every subscribed webhook endpoint and
+// to the project's legacy destinations, before the next is attempted. A
+// webhook that failed to send to any of them is resent to all of them, with
+// the same message ID.
+//
+// At most one call per resource runs at a time; others return
+// ErrWebhookResourceLocked.
+func (s *Store) SendOrderedWebhooks(ctx context.Context, req *SendOrderedWebhooksRequest) error {
+	projectID, err := idformat.Project.Parse(req.ProjectID)
+	if err != nil {
+		return fmt.Errorf("parse project id: %w", err)
+	}
+
+	// session-level advisory locks are tied to a connection, so hold onto one
+	// for the duration
+	conn, err := s.DB.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	lockKey := fmt.Sprintf("webhook_outbox/%s/%s", req.ProjectID, req.ResourceKey)
+	locked, err := queries.New(conn).TryAdvisoryLock(ctx, lockKey)
+	if err != nil {
+		return fmt.Errorf("try advisory lock: %w", err)
+	}
+
+	if !locked {
+		return ErrWebhookResourceLocked
+	}
+
+	defer func() {
+		// unlock even if ctx is done; if that fails, close the connection
+		// rather than return it to the pool still holding the lock
+		if _, err := queries.New(conn).AdvisoryUnlock(context.WithoutCancel(ctx), lockKey); err != nil {
+			slog.ErrorContext(ctx, "advisory_unlock_failed", "error", err)
+			_ = conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}()
+
+	maxAttempts := req.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = defaultWebhookOutboxMaxAttempts
+	}
+
+	for {
+		qWebhookOutboxEvents, err := s.q().ListWebhookOutboxEvents(ctx, queries.ListWebhookOutboxEventsParams{
+			ProjectID:   projectID,
+			ResourceKey: req.ResourceKey,
+			Limit:       webhookOutboxBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("list webhook outbox events: %w", err)
+		}
+
+		for _, qWebhookOutboxEvent := range qWebhookOutboxEvents {
+			if qWebhookOutboxEvent.NextAttemptTime != nil && time.Now().Before(*qWebhookOutboxEvent.NextAttemptTime) {
+				return &WebhookNotDueError{RetryTime: *qWebhookOutboxEvent.NextAttemptTime}
+			}
+
+			attempt := int(qWebhookOutboxEvent.Attempts) + 1
+			if err := s.sendOrderedWebhook(ctx, projectID, qWebhookOutboxEvent, attempt, maxAttempts); err != nil {
+				nextAttemptTime := time.Now().Add(webhookOutboxBackoff(attempt))
+				attempts, incrementErr := s.q().IncrementWebhookOutboxEventAttempts(ctx, queries.IncrementWebhookOutboxEventAttemptsParams{
+					ID:              qWebhookOutboxEvent.ID,
+					NextAttemptTime: &nextAttemptTime,
+				})
+				if incrementErr != nil {
+					return fmt.Errorf("increment webhook outbox event attempts: %w", incrementErr)
+				}
+
+				if int(attempts) < maxAttempts {
+					return fmt.Errorf("send webhook with sequence %d: %w", qWebhookOutboxEvent.Sequence, err)
+				}
+
+				slog.ErrorContext(ctx, "webhook_outbox_event_dead_lettered", "resource_key", req.ResourceKey, "sequence", qWebhookOutboxEvent.Sequence, "error", err)
+			}
+
+			if err := s.q().DeleteWebhookOutboxEvent(ctx, qWebhookOutboxEvent.ID); err != nil {
+				return fmt.Errorf("delete webhook outbox event: %w", err)
+			}
+		}
+
+		if len(qWebhookOutboxEvents) < webhookOutboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// sendOrderedWebhook sends a webhook from an outbox to every webhook endpoint
+// subscribed to it and to the project's legacy destinations. It attempts all
+// of them even if some fail.
+func (s *Store) sendOrderedWebhook(ctx context.Context, projectID uuid.UUID, qWebhookOutboxEvent queries.WebhookOutboxEvent, attempt, maxAttempts int) error {
+	var payload map[string]any
+	if err := json.Unmarshal(qWebhookOutboxEvent.Payload, &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	qProjectWebhookSettings, err := s.q().GetProjectWebhookSettings(ctx, projectID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("get project webhook settings: %w", err)
+	}
+
+	qWebhookEndpoints, err := s.q().ListEnabledWebhookEndpointsByEventType(ctx, queries.ListEnabledWebhookEndpointsByEventTypeParams{
+		ProjectID: projectID,
+		EventType: qWebhookOutboxEvent.EventType,
+	})
+	if err != nil {
+		return fmt.Errorf("list enabled webhook endpoints by event type: %w", err)
+	}
+
+	messageID := fmt.Sprintf("msg_%s", qWebhookOutboxEvent.ID)
+
+	var errs []error
+	for _, qWebhookEndpoint := range qWebhookEndpoints {
+		if err := s.SendWebhookToEndpoint(ctx, &SendWebhookToEndpointRequest{
+			WebhookEndpointID: idformat.WebhookEndpoint.Format(qWebhookEndpoint.ID),
+			EventType:         qWebhookOutboxEvent.EventType,
+			Payload:           payload,
+			MessageID:         messageID,
+			Attempt:           attempt,
+			MaxAttempts:       maxAttempts,
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("send webhook to endpoint: %w", err))
+		}
+	}
+
+	if err := s.sendLegacyWebhook(ctx, projectID, qProjectWebhookSettings, messageID, &SendWebhookRequest{
+		EventType:   qWebhookOutboxEvent.EventType,
+		Payload:     payload,
+		Attempt:     attempt,
+		MaxAttempts: maxAttempts,
+	}); err != nil {
+		errs = append(errs, fmt.Errorf("send legacy webhook: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// PruneWebhookResourceSequences deletes the sequences of resources with no
+// outstanding webhooks that haven't had a webhook in a while. It returns how
+// many it deleted.
+func (s *Store) PruneWebhookResourceSequences(ctx context.Context) (int64, error) {
+	updateTime := time.Now().Add(-webhookResourceSequenceRetention)
+	count, err := s.q().DeleteStaleWebhookResourceSequences(ctx, &updateTime)
+	if err != nil {
+		return 0, fmt.Errorf("delete stale webhook resource sequences: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,107 @@
+package store_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/storetesting"
+)
+
+var environment *storetesting.Environment
+
+func TestMain(m *testing.M) {
+	testEnvironment, cleanup := storetesting.NewEnvironment()
+	defer cleanup()
+
+	environment = testEnvironment
+	m.Run()
+}
+
+func TestSendOrderedWebhooks_QueuedJobsShareBackoff(t *testing.T) {
+	ctx := t.Context()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	s := &store.Store{
+		DB:                       environment.DB,
+		DirectWebhookHTTPClient:  server.Client(),
+		WebhookSigningSecretsKMS: environment.KMS.WebhookSigningSecretsKMS,
+	}
+
+	projectID, _ := environment.NewProject(t)
+	projectUUID, err := idformat.Project.Parse(projectID)
+	require.NoError(t, err)
+
+	_, err = environment.DB.Exec(ctx, `
+INSERT INTO project_webhook_settings (id, project_id, direct_webhook_url)
+  VALUES ($1, $2, $3);
+`, uuid.New(), projectUUID, server.URL)
+	require.NoError(t, err)
+
+	// each webhook enqueues its own job, all for the same resource
+	resourceKey := idformat.User.Format(uuid.New())
+	const queuedJobs = 3
+	for range queuedJobs {
+		tx, err := environment.DB.Begin(ctx)
+		require.NoError(t, err)
+
+		_, err = store.EnqueueOrderedWebhookTx(ctx, tx, environment.River, &store.EnqueueOrderedWebhookRequest{
+			ProjectID:   projectUUID,
+			ResourceKey: resourceKey,
+			EventType:   "sync.user",
+			Payload:     map[string]any{"type": "sync.user", "userId": resourceKey},
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit(ctx))
+	}
+
+	req := &store.SendOrderedWebhooksRequest{
+		ProjectID:   projectID,
+		ResourceKey: resourceKey,
+		MaxAttempts: 2,
+	}
+
+	// the first job attempts the head webhook and fails
+	err = s.SendOrderedWebhooks(ctx, req)
+	require.Error(t, err)
+
+	var notDueErr *store.WebhookNotDueError
+	require.False(t, errors.As(err, &notDueErr))
+
+	// the other jobs find it backing off, and don't attempt it
+	for range queuedJobs - 1 {
+		err := s.SendOrderedWebhooks(ctx, req)
+		require.ErrorAs(t, err, &notDueErr)
+		require.True(t, notDueErr.RetryTime.After(time.Now()))
+	}
+
+	require.Equal(t, int64(1), requests.Load())
+
+	var attempts []int32
+	rows, err := environment.DB.Query(ctx, `
+SELECT attempts FROM webhook_outbox_events WHERE project_id = $1 AND resource_key = $2 ORDER BY sequence;
+`, projectUUID, resourceKey)
+	require.NoError(t, err)
+	for rows.Next() {
+		var a int32
+		require.NoError(t, rows.Scan(&a))
+		attempts = append(attempts, a)
+	}
+	require.NoError(t, rows.Err())
+
+	// nothing was dead-lettered, and only the head webhook was attempted
+	require.Equal(t, []int32{1, 0, 0}, attempts)
+}
@@ -6,11 +6,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
 	"github.com/svix/svix-webhooks/go/models"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/standardwebhooks"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 )
 
@@ -18,6 +25,16 @@ type SendWebhookRequest struct {
 	ProjectID string
 	EventType string
 	Payload   map[string]any
+
+	// MessageID identifies the webhook to receivers, so they can deduplicate
+	// retries. If empty, a random one is generated.
+	MessageID string
+
+	// Attempt and MaxAttempts are recorded in the webhook delivery log of a
+	// project's direct webhook URL. If zero, the delivery is recorded as a
+	// first attempt that is never dead-lettered.
+	Attempt     int
+	MaxAttempts int
 }
 
 func (s *Store) SendWebhook(ctx context.Context, req *SendWebhookRequest) error {
@@ -26,41 +43,57 @@ func (s *Store) SendWebhook(ctx context.Context, req *SendWebhookRequest) error
 		return fmt.Errorf("parse project id: %w", err)
 	}
 
+	// A project without webhook settings may still have webhook endpoints, so
+	// we don't bail out here if there are none
 	qProjectWebhookSettings, err := s.q().GetProjectWebhookSettings(ctx, projectID)
-	if err != nil {
-		// We want to ignore this error if the project does not have webhook settings
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return fmt.Errorf("get project by id: %w", err)
 	}
 
 	slog.InfoContext(ctx, "project_webhook_settings", "direct_webhook_url", qProjectWebhookSettings.DirectWebhookUrl, "svix_app_id", qProjectWebhookSettings.AppID)
 
-	if qProjectWebhookSettings.DirectWebhookUrl != nil {
-		slog.InfoContext(ctx, "handle_direct_webhook", "url", *qProjectWebhookSettings.DirectWebhookUrl)
+	messageID := req.MessageID
+	if messageID == "" {
+		messageID = fmt.Sprintf("msg_%s", uuid.New())
+	}
 
-		body, err := json.Marshal(req.Payload)
-		if err != nil {
-			return fmt.Errorf("marshal webhook body: %w", err)
-		}
+	// fan out before attempting the legacy destinations below, so that a
+	// failing legacy destination doesn't hold up webhook endpoints
+	if err := s.enqueueWebhookEndpointJobs(ctx, projectID, req.EventType, messageID, req.Payload); err != nil {
+		return fmt.Errorf("enqueue webhook endpoint jobs: %w", err)
+	}
 
-		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, *qProjectWebhookSettings.DirectWebhookUrl, bytes.NewReader(body))
-		if err != nil {
-			return fmt.Errorf("new http request: %w", err)
-		}
+	if err := s.sendLegacyWebhook(ctx, projectID, qProjectWebhookSettings, messageID, req); err != nil {
+		return fmt.Errorf("send legacy webhook: %w", err)
+	}
 
-		httpReq.Header.Set("Content-Type", "application/json")
+	return nil
+}
 
-		httpRes, err := s.DirectWebhookHTTPClient.Do(httpReq)
-		if err != nil {
-			return fmt.Errorf("send http request: %w", err)
-		}
+// sendLegacyWebhook sends a webhook to a project's direct webhook URL or Svix
+// app, if it has either.
+func (s *Store) sendLegacyWebhook(ctx context.Context, projectID uuid.UUID, qProjectWebhookSettings queries.ProjectWebhookSetting, messageID string, req *SendWebhookRequest) error {
+	if qProjectWebhookSettings.DirectWebhookUrl != nil {
+		slog.InfoContext(ctx, "handle_direct_webhook", "url", *qProjectWebhookSettings.DirectWebhookUrl)
 
-		defer func() { _ = httpRes.Body.Close() }()
+		res, sendErr := s.sendDirectWebhook(ctx, qProjectWebhookSettings, *qProjectWebhookSettings.DirectWebhookUrl, messageID, req.Payload)
 
-		if httpRes.StatusCode != http.StatusOK {
-			return fmt.Errorf("bad response status code: %s", httpRes.Status)
+		// direct webhook URL deliveries have no webhook endpoint, but are
+		// logged alongside webhook endpoint deliveries all the same
+		if err := s.createWebhookDelivery(ctx, &webhookDelivery{
+			ProjectID:   projectID,
+			URL:         *qProjectWebhookSettings.DirectWebhookUrl,
+			MessageID:   messageID,
+			EventType:   req.EventType,
+			Payload:     req.Payload,
+			Attempt:     max(req.Attempt, 1),
+			MaxAttempts: req.MaxAttempts,
+		}, res, sendErr); err != nil {
+			slog.ErrorContext(ctx, "create_webhook_delivery_error", "error", err)
+		}
+
+		if sendErr != nil {
+			return fmt.Errorf("send direct webhook: %w", sendErr)
 		}
 	} else if qProjectWebhookSettings.AppID != nil && *qProjectWebhookSettings.AppID != "" {
 		slog.InfoContext(ctx, "handle_svix_webhook", "svix_app_id", *qProjectWebhookSettings.AppID)
@@ -79,3 +112,333 @@ func (s *Store) SendWebhook(ctx context.Context, req *SendWebhookRequest) error
 
 	return nil
 }
+
+// WebhookEndpointArgs are the arguments to a River job that sends a webhook to
+// a single webhook endpoint.
+//
+// They live here, rather than alongside the worker that handles them, because
+// SendWebhook enqueues them.
+type WebhookEndpointArgs struct {
+	WebhookEndpointID string
+	EventType         string
+	Payload           map[string]any
+
+	// MessageID is shared by every endpoint a webhook is fanned out to.
+	MessageID string
+}
+
+func (WebhookEndpointArgs) Kind() string {
+	return "webhook_endpoint"
+}
+
+// enqueueWebhookEndpointJobs fans a webhook out to every enabled webhook
+// endpoint in the project subscribed to its event type, as one River job per
+// endpoint. That way, a failing endpoint is retried without resending to the
+// others.
+func (s *Store) enqueueWebhookEndpointJobs(ctx context.Context, projectID uuid.UUID, eventType, messageID string, payload map[string]any) error {
+	qWebhookEndpoints, err := s.q().ListEnabledWebhookEndpointsByEventType(ctx, queries.ListEnabledWebhookEndpointsByEventTypeParams{
+		ProjectID: projectID,
+		EventType: eventType,
+	})
+	if err != nil {
+		return fmt.Errorf("list enabled webhook endpoints by event type: %w", err)
+	}
+
+	if len(qWebhookEndpoints) == 0 {
+		return nil
+	}
+
+	riverClient, err := river.ClientFromContextSafely[pgx.Tx](ctx)
+	if err != nil {
+		return fmt.Errorf("get river client: %w", err)
+	}
+
+	var params []river.InsertManyParams
+	for _, qWebhookEndpoint := range qWebhookEndpoints {
+		params = append(params, river.InsertManyParams{
+			Args: WebhookEndpointArgs{
+				WebhookEndpointID: idformat.WebhookEndpoint.Format(qWebhookEndpoint.ID),
+				EventType:         eventType,
+				Payload:           payload,
+				MessageID:         messageID,
+			},
+			// SendWebhook may be retried after this point; don't enqueue the
+			// same message to an endpoint twice
+			InsertOpts: &river.InsertOpts{
+				UniqueOpts: river.UniqueOpts{ByArgs: true},
+			},
+		})
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := riverClient.InsertManyTx(ctx, tx, params); err != nil {
+		return fmt.Errorf("insert webhook endpoint jobs: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+type SendWebhookToEndpointRequest struct {
+	WebhookEndpointID string
+	EventType         string
+	Payload           map[string]any
+	MessageID         string
+
+	// Attempt and MaxAttempts are recorded in the webhook delivery log. A
+	// failed final attempt is recorded as dead-lettered.
+	Attempt     int
+	MaxAttempts int
+}
+
+func (s *Store) SendWebhookToEndpoint(ctx context.Context, req *SendWebhookToEndpointRequest) error {
+	webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.WebhookEndpointID)
+	if err != nil {
+		return fmt.Errorf("parse webhook endpoint id: %w", err)
+	}
+
+	qWebhookEndpoint, err := s.q().GetWebhookEndpoint(ctx, webhookEndpointID)
+	if err != nil {
+		// the endpoint may have been deleted since the webhook was enqueued
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	if !qWebhookEndpoint.Enabled {
+		slog.InfoContext(ctx, "skip_disabled_webhook_endpoint", "webhook_endpoint_id", req.WebhookEndpointID)
+		return nil
+	}
+
+	qProjectWebhookSettings, err := s.q().GetProjectWebhookSettings(ctx, qWebhookEndpoint.ProjectID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("get project webhook settings: %w", err)
+	}
+
+	res, sendErr := s.sendDirectWebhook(ctx, qProjectWebhookSettings, qWebhookEndpoint.Url, req.MessageID, req.Payload)
+
+	// Failing to record a delivery shouldn't cause a successfully delivered
+	// webhook to be sent again, so we only log such errors.
+	if err := s.createWebhookDelivery(ctx, &webhookDelivery{
+		ProjectID:         qWebhookEndpoint.ProjectID,
+		WebhookEndpointID: &qWebhookEndpoint.ID,
+		URL:               qWebhookEndpoint.Url,
+		MessageID:         req.MessageID,
+		EventType:         req.EventType,
+		Payload:           req.Payload,
+		Attempt:           req.Attempt,
+		MaxAttempts:       req.MaxAttempts,
+	}, res, sendErr); err != nil {
+		slog.ErrorContext(ctx, "create_webhook_delivery_error", "error", err)
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("send direct webhook: %w", sendErr)
+	}
+
+	return nil
+}
+
+// webhookDelivery is an attempt to deliver a webhook, to be recorded in the
+// webhook delivery log.
+type webhookDelivery struct {
+	ProjectID uuid.UUID
+
+	// WebhookEndpointID is nil for deliveries to a project's direct webhook
+	// URL.
+	WebhookEndpointID *uuid.UUID
+
+	URL       string
+	MessageID string
+	EventType string
+	Payload   map[string]any
+
+	Attempt int
+
+	// MaxAttempts is zero if the delivery is never dead-lettered.
+	MaxAttempts int
+}
+
+func (s *Store) createWebhookDelivery(ctx context.Context, delivery *webhookDelivery, res *directWebhookResult, sendErr error) error {
+	payload, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	state := queries.WebhookDeliveryStateSucceeded
+	var errorMessage *string
+	if sendErr != nil {
+		state = queries.WebhookDeliveryStateFailed
+		if delivery.MaxAttempts > 0 && delivery.Attempt >= delivery.MaxAttempts {
+			state = queries.WebhookDeliveryStateDeadLettered
+		}
+
+		errorMessage = refOrNil(sendErr.Error())
+	}
+
+	var statusCode *int32
+	var responseBody *string
+	if res.StatusCode != 0 {
+		statusCode = refOrNil(int32(res.StatusCode))
+		responseBody = refOrNil(res.ResponseBody)
+	}
+
+	if _, err := s.q().CreateWebhookDelivery(ctx, queries.CreateWebhookDeliveryParams{
+		ID:                uuid.Must(uuid.NewV7()),
+		ProjectID:         delivery.ProjectID,
+		WebhookEndpointID: delivery.WebhookEndpointID,
+		MessageID:         delivery.MessageID,
+		EventType:         delivery.EventType,
+		Payload:           payload,
+		Url:               delivery.URL,
+		Attempt:           int32(delivery.Attempt),
+		State:             state,
+		StatusCode:        statusCode,
+		LatencyMillis:     int32(res.Latency.Milliseconds()),
+		ResponseBody:      responseBody,
+		Error:             errorMessage,
+	}); err != nil {
+		return fmt.Errorf("create webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// maxWebhookDeliveryResponseBodyBytes is how much of an endpoint's response
+// body is kept in the webhook delivery log.
+const maxWebhookDeliveryResponseBodyBytes = 4096
+
+type directWebhookResult struct {
+	// StatusCode is zero if no response was received.
+	StatusCode   int
+	ResponseBody string
+	Latency      time.Duration
+}
+
+// sendDirectWebhook POSTs a webhook to url, signed with the project's webhook
+// signing secrets. It returns an error unless url responds with 200 OK.
+func (s *Store) sendDirectWebhook(ctx context.Context, qProjectWebhookSettings queries.ProjectWebhookSetting, url, messageID string, payload map[string]any) (*directWebhookResult, error) {
+	var res directWebhookResult
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return &res, fmt.Errorf("marshal webhook body: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return &res, fmt.Errorf("new http request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	qProjectWebhookSettings, err = s.ensureWebhookSigningSecret(ctx, qProjectWebhookSettings)
+	if err != nil {
+		return &res, fmt.Errorf("ensure webhook signing secret: %w", err)
+	}
+
+	signingSecrets, err := s.getWebhookSigningSecrets(ctx, qProjectWebhookSettings)
+	if err != nil {
+		return &res, fmt.Errorf("get webhook signing secrets: %w", err)
+	}
+
+	standardwebhooks.SetHeaders(httpReq.Header, signingSecrets, messageID, time.Now(), body)
+
+	start := time.Now()
+	httpRes, err := s.DirectWebhookHTTPClient.Do(httpReq)
+	res.Latency = time.Since(start)
+	if err != nil {
+		return &res, fmt.Errorf("send http request: %w", err)
+	}
+
+	defer func() { _ = httpRes.Body.Close() }()
+
+	res.StatusCode = httpRes.StatusCode
+
+	resBody, err := io.ReadAll(io.LimitReader(httpRes.Body, maxWebhookDeliveryResponseBodyBytes))
+	if err != nil {
+		return &res, fmt.Errorf("read response body: %w", err)
+	}
+	res.ResponseBody = strings.ToValidUTF8(string(resBody), "")
+
+	if httpRes.StatusCode != http.StatusOK {
+		return &res, fmt.Errorf("bad response status code: %s", httpRes.Status)
+	}
+
+	return &res, nil
+}
+
+// ensureWebhookSigningSecret returns qProjectWebhookSettings with a signing
+// secret, generating one if the project has never rotated its secret, so that
+// direct webhooks are never sent unsigned.
+func (s *Store) ensureWebhookSigningSecret(ctx context.Context, qProjectWebhookSettings queries.ProjectWebhookSetting) (queries.ProjectWebhookSetting, error) {
+	// projects without webhook settings have nowhere to keep a secret
+	if qProjectWebhookSettings.ID == uuid.Nil || qProjectWebhookSettings.SigningSecretCiphertext != nil {
+		return qProjectWebhookSettings, nil
+	}
+
+	signingSecret, err := standardwebhooks.NewSecret()
+	if err != nil {
+		return queries.ProjectWebhookSetting{}, fmt.Errorf("new signing secret: %w", err)
+	}
+
+	signingSecretCiphertext, err := s.WebhookSigningSecretsKMS.Encrypt(ctx, signingSecret)
+	if err != nil {
+		return queries.ProjectWebhookSetting{}, fmt.Errorf("encrypt signing secret: %w", err)
+	}
+
+	qUpdatedProjectWebhookSettings, err := s.q().CreateProjectWebhookSettingsSigningSecret(ctx, queries.CreateProjectWebhookSettingsSigningSecretParams{
+		ProjectID:               qProjectWebhookSettings.ProjectID,
+		SigningSecretCiphertext: signingSecretCiphertext,
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return queries.ProjectWebhookSetting{}, fmt.Errorf("create project webhook settings signing secret: %w", err)
+		}
+
+		// a secret was created concurrently; use that one instead
+		qUpdatedProjectWebhookSettings, err = s.q().GetProjectWebhookSettings(ctx, qProjectWebhookSettings.ProjectID)
+		if err != nil {
+			return queries.ProjectWebhookSetting{}, fmt.Errorf("get project webhook settings: %w", err)
+		}
+	}
+
+	slog.InfoContext(ctx, "create_webhook_signing_secret", "project_id", idformat.Project.Format(qProjectWebhookSettings.ProjectID))
+	return qUpdatedProjectWebhookSettings, nil
+}
+
+// getWebhookSigningSecrets returns the secrets direct webhooks must be signed
+// with: the project's current secret, and its previous one if it has not yet
+// expired.
+func (s *Store) getWebhookSigningSecrets(ctx context.Context, qProjectWebhookSettings queries.ProjectWebhookSetting) ([][]byte, error) {
+	var signingSecrets [][]byte
+
+	if qProjectWebhookSettings.SigningSecretCiphertext != nil {
+		signingSecret, err := s.WebhookSigningSecretsKMS.Decrypt(ctx, qProjectWebhookSettings.SigningSecretCiphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt signing secret: %w", err)
+		}
+		signingSecrets = append(signingSecrets, signingSecret)
+	}
+
+	if qProjectWebhookSettings.PreviousSigningSecretCiphertext != nil &&
+		qProjectWebhookSettings.PreviousSigningSecretExpireTime != nil &&
+		qProjectWebhookSettings.PreviousSigningSecretExpireTime.After(time.Now()) {
+		previousSigningSecret, err := s.WebhookSigningSecretsKMS.Decrypt(ctx, qProjectWebhookSettings.PreviousSigningSecretCiphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt previous signing secret: %w", err)
+		}
+		signingSecrets = append(signingSecrets, previousSigningSecret)
+	}
+
+	return signingSecrets, nil
+}
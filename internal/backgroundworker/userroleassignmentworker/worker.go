@@ -0,0 +1,52 @@
+package userroleassignmentworker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+)
+
+// ExpireInterval is how often user role assignments are checked for expiry.
+const ExpireInterval = time.Minute
+
+// ExpireWorker removes user role assignments once they expire, and sends the
+// corresponding audit events and webhooks. It is meant to be run as a periodic
+// job, every ExpireInterval.
+type ExpireWorker struct {
+	Store *store.Store
+	river.WorkerDefaults[ExpireArgs]
+}
+
+type ExpireArgs struct{}
+
+func (ExpireArgs) Kind() string {
+	return "user_role_assignment_expire"
+}
+
+func (w *ExpireWorker) Work(ctx context.Context, job *river.Job[ExpireArgs]) error {
+	count, err := w.Store.ExpireUserRoleAssignments(ctx)
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "user_role_assignments_expired", "count", count)
+	}
+
+	return nil
+}
+
+// PeriodicJob runs ExpireWorker every ExpireInterval.
+func PeriodicJob() *river.PeriodicJob {
+	return river.NewPeriodicJob(
+		river.PeriodicInterval(ExpireInterval),
+		func() (river.JobArgs, *river.InsertOpts) {
+			return ExpireArgs{}, nil
+		},
+		&river.PeriodicJobOpts{RunOnStart: true},
+	)
+}
@@ -0,0 +1,86 @@
+package webhookworker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+)
+
+const (
+	defaultEndpointMaxAttempts    = 10
+	defaultEndpointRetryBaseDelay = time.Second * 30
+	defaultEndpointRetryMaxDelay  = time.Hour * 6
+)
+
+// EndpointWorker sends a webhook to a single webhook endpoint. Its jobs are
+// enqueued by store.SendWebhook, which fans webhooks out to every matching
+// endpoint.
+type EndpointWorker struct {
+	Store *store.Store
+
+	// MaxAttempts is how many times a webhook is attempted before it is
+	// dead-lettered. Defaults to 10.
+	MaxAttempts int
+
+	// RetryBaseDelay is the delay before the first retry. Each subsequent retry
+	// waits twice as long as the previous one, up to RetryMaxDelay. Defaults to
+	// 30 seconds and 6 hours, respectively.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	river.WorkerDefaults[store.WebhookEndpointArgs]
+}
+
+func (w *EndpointWorker) Work(ctx context.Context, job *river.Job[store.WebhookEndpointArgs]) error {
+	slog.InfoContext(ctx, "work", "webhook_endpoint_id", job.Args.WebhookEndpointID, "event_type", job.Args.EventType, "attempt", job.Attempt)
+
+	maxAttempts := min(w.maxAttempts(), job.MaxAttempts)
+	if err := w.Store.SendWebhookToEndpoint(ctx, &store.SendWebhookToEndpointRequest{
+		WebhookEndpointID: job.Args.WebhookEndpointID,
+		EventType:         job.Args.EventType,
+		Payload:           job.Args.Payload,
+		MessageID:         job.Args.MessageID,
+		Attempt:           job.Attempt,
+		MaxAttempts:       maxAttempts,
+	}); err != nil {
+		// the delivery is recorded as dead-lettered; stop retrying
+		if job.Attempt >= maxAttempts {
+			return river.JobCancel(fmt.Errorf("store: %w", err))
+		}
+
+		return fmt.Errorf("store: %w", err)
+	}
+
+	return nil
+}
+
+// NextRetry implements exponential backoff between attempts.
+func (w *EndpointWorker) NextRetry(job *river.Job[store.WebhookEndpointArgs]) time.Time {
+	baseDelay := w.RetryBaseDelay
+	if baseDelay == 0 {
+		baseDelay = defaultEndpointRetryBaseDelay
+	}
+
+	maxDelay := w.RetryMaxDelay
+	if maxDelay == 0 {
+		maxDelay = defaultEndpointRetryMaxDelay
+	}
+
+	delay := baseDelay
+	for i := 1; i < job.Attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	return time.Now().Add(min(delay, maxDelay))
+}
+
+func (w *EndpointWorker) maxAttempts() int {
+	if w.MaxAttempts == 0 {
+		return defaultEndpointMaxAttempts
+	}
+	return w.MaxAttempts
+}
@@ -0,0 +1,21 @@
+package webhookworker
+
+import "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+
+// EventTypes are the webhook event types webhook endpoints may subscribe to.
+var EventTypes = func() []string {
+	eventTypes := []string{
+		"sync.organization",
+		"sync.user",
+		"sync.user_role_assignments",
+		"custom_email.verify_email",
+		"custom_email.password_reset",
+		"custom_email.user_invite",
+	}
+
+	for _, t := range store.TypedEventTypes {
+		eventTypes = append(eventTypes, t.EventType)
+	}
+
+	return eventTypes
+}()
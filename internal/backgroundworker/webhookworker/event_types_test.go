@@ -0,0 +1,15 @@
+package webhookworker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventTypes_Unique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, eventType := range EventTypes {
+		require.False(t, seen[eventType], "duplicate event type: %s", eventType)
+		seen[eventType] = true
+	}
+}
@@ -0,0 +1,57 @@
+package webhookworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+)
+
+// orderedSnoozeDelay is how long an OrderedWorker job waits before trying
+// again when another job is already sending its resource's webhooks.
+const orderedSnoozeDelay = time.Second
+
+// OrderedWorker sends the webhooks in a resource's outbox in order. Its jobs
+// are enqueued by store.EnqueueOrderedWebhookTx.
+//
+// Jobs for the same resource never send concurrently. Any job for a resource
+// sends every webhook outstanding for it, so jobs that find the outbox
+// already drained complete without doing anything. Jobs that find the
+// resource's next webhook waiting out a backoff snooze until it is due, rather
+// than counting as an attempt at it.
+type OrderedWorker struct {
+	Store *store.Store
+
+	// MaxAttempts is how many times a webhook is attempted before it is
+	// dead-lettered. Defaults to 10.
+	MaxAttempts int
+
+	river.WorkerDefaults[store.OrderedWebhookArgs]
+}
+
+func (w *OrderedWorker) Work(ctx context.Context, job *river.Job[store.OrderedWebhookArgs]) error {
+	slog.InfoContext(ctx, "work", "project_id", job.Args.ProjectID, "resource_key", job.Args.ResourceKey, "attempt", job.Attempt)
+
+	if err := w.Store.SendOrderedWebhooks(ctx, &store.SendOrderedWebhooksRequest{
+		ProjectID:   job.Args.ProjectID,
+		ResourceKey: job.Args.ResourceKey,
+		MaxAttempts: w.MaxAttempts,
+	}); err != nil {
+		if errors.Is(err, store.ErrWebhookResourceLocked) {
+			return river.JobSnooze(orderedSnoozeDelay)
+		}
+
+		var notDueErr *store.WebhookNotDueError
+		if errors.As(err, &notDueErr) {
+			return river.JobSnooze(max(time.Until(notDueErr.RetryTime), orderedSnoozeDelay))
+		}
+
+		return fmt.Errorf("store: %w", err)
+	}
+
+	return nil
+}
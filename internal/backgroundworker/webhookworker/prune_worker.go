@@ -0,0 +1,52 @@
+package webhookworker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+)
+
+// PruneInterval is how often stale webhook resource sequences are pruned.
+const PruneInterval = time.Hour
+
+// PruneWorker deletes the sequences of resources that haven't had an ordered
+// webhook in a while. It is meant to be run as a periodic job, every
+// PruneInterval.
+type PruneWorker struct {
+	Store *store.Store
+	river.WorkerDefaults[PruneArgs]
+}
+
+type PruneArgs struct{}
+
+func (PruneArgs) Kind() string {
+	return "webhook_prune"
+}
+
+func (w *PruneWorker) Work(ctx context.Context, job *river.Job[PruneArgs]) error {
+	count, err := w.Store.PruneWebhookResourceSequences(ctx)
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "webhook_resource_sequences_pruned", "count", count)
+	}
+
+	return nil
+}
+
+// PrunePeriodicJob runs PruneWorker every PruneInterval.
+func PrunePeriodicJob() *river.PeriodicJob {
+	return river.NewPeriodicJob(
+		river.PeriodicInterval(PruneInterval),
+		func() (river.JobArgs, *river.InsertOpts) {
+			return PruneArgs{}, nil
+		},
+		&river.PeriodicJobOpts{RunOnStart: true},
+	)
+}
@@ -0,0 +1,27 @@
+package webhookworker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/riverqueue/river"
+	"github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+)
+
+// TypedEventWorker sends typed webhook events to the webhook endpoints
+// subscribed to them. Its jobs are enqueued by store.InsertTypedEventTx.
+type TypedEventWorker struct {
+	Store *store.Store
+	river.WorkerDefaults[store.TypedEventArgs]
+}
+
+func (w *TypedEventWorker) Work(ctx context.Context, job *river.Job[store.TypedEventArgs]) error {
+	slog.InfoContext(ctx, "work", "project_id", job.Args.ProjectID, "event_type", job.Args.EventType, "message_id", job.Args.MessageID)
+
+	if err := w.Store.SendTypedWebhook(ctx, job.Args); err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+
+	return nil
+}
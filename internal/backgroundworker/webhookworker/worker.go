@@ -28,9 +28,12 @@ func (w *Worker) Work(ctx context.Context, job *river.Job[Args]) error {
 	slog.InfoContext(ctx, "work", "args", job.Args)
 
 	if err := w.Store.SendWebhook(ctx, &store.SendWebhookRequest{
-		ProjectID: job.Args.ProjectID,
-		EventType: job.Args.EventName,
-		Payload:   job.Args.Payload,
+		ProjectID:   job.Args.ProjectID,
+		EventType:   job.Args.EventName,
+		Payload:     job.Args.Payload,
+		MessageID:   fmt.Sprintf("msg_%d", job.ID),
+		Attempt:     job.Attempt,
+		MaxAttempts: job.MaxAttempts,
 	}); err != nil {
 		return fmt.Errorf("store: %w", err)
 	}
@@ -0,0 +1,119 @@
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// NewHandler records the IP address of the client making each request onto
+// the request context.
+//
+// If trustedHeader is non-empty, the client IP is read from that header
+// instead of the connection's remote address. Only use this when a trusted
+// proxy sets that header; clients can otherwise spoof their IP.
+//
+// Headers like X-Forwarded-For are lists, to which each proxy appends the
+// address it received the request from. Clients control the start of the
+// list, so the client IP is taken to be the entry trustedProxies from the
+// end, where trustedProxies is the number of proxies in front of the server.
+// Values below 1 are treated as 1.
+func NewHandler(trustedHeader string, trustedProxies int, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if addr, ok := parseRequest(trustedHeader, trustedProxies, r); ok {
+			r = r.WithContext(NewContext(r.Context(), addr))
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func NewContext(ctx context.Context, addr netip.Addr) context.Context {
+	return context.WithValue(ctx, ctxKey{}, addr.Unmap())
+}
+
+// FromContext returns the client IP recorded by NewHandler. If there is no
+// recorded IP, it returns the zero netip.Addr, which is not valid.
+func FromContext(ctx context.Context) netip.Addr {
+	addr, _ := ctx.Value(ctxKey{}).(netip.Addr)
+	return addr
+}
+
+// Allowed returns whether addr is permitted by an allowlist of CIDRs. An empty
+// allowlist permits every address, including invalid ones.
+func Allowed(addr netip.Addr, allowlist []netip.Prefix) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+
+	if !addr.IsValid() {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range allowlist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCIDR parses either a CIDR ("192.0.2.0/24") or a single IP address
+// ("192.0.2.1"), which is treated as a CIDR matching only that address.
+func ParseCIDR(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	if prefix != prefix.Masked() {
+		return netip.Prefix{}, fmt.Errorf("cidr %q has bits set to right of mask; did you mean %q?", s, prefix.Masked().String())
+	}
+	return prefix, nil
+}
+
+func parseRequest(trustedHeader string, trustedProxies int, r *http.Request) (netip.Addr, bool) {
+	if trustedHeader != "" {
+		trustedProxies = max(trustedProxies, 1)
+
+		// proxies may each set the header or append to it, so consider every
+		// instance of the header as one list
+		var values []string
+		for _, header := range r.Header.Values(trustedHeader) {
+			values = append(values, strings.Split(header, ",")...)
+		}
+
+		// fewer entries than trusted proxies means the request did not come
+		// through all of them
+		if len(values) < trustedProxies {
+			return netip.Addr{}, false
+		}
+
+		addr, err := netip.ParseAddr(strings.TrimSpace(values[len(values)-trustedProxies]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		return addr, true
+	}
+
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addrPort.Addr(), true
+}
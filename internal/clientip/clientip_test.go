@@ -0,0 +1,118 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowed(t *testing.T) {
+	t.Parallel()
+
+	allowlist := []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.0/24"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+
+	testCases := []struct {
+		name      string
+		addr      netip.Addr
+		allowlist []netip.Prefix
+		want      bool
+	}{
+		{name: "empty allowlist", addr: netip.MustParseAddr("203.0.113.1"), want: true},
+		{name: "empty allowlist, unknown addr", addr: netip.Addr{}, want: true},
+		{name: "unknown addr", addr: netip.Addr{}, allowlist: allowlist, want: false},
+		{name: "ipv4 match", addr: netip.MustParseAddr("192.0.2.10"), allowlist: allowlist, want: true},
+		{name: "ipv4 mismatch", addr: netip.MustParseAddr("192.0.3.10"), allowlist: allowlist, want: false},
+		{name: "ipv4-mapped ipv6 match", addr: netip.MustParseAddr("::ffff:192.0.2.10"), allowlist: allowlist, want: true},
+		{name: "ipv6 match", addr: netip.MustParseAddr("2001:db8::1"), allowlist: allowlist, want: true},
+		{name: "ipv6 mismatch", addr: netip.MustParseAddr("2001:db9::1"), allowlist: allowlist, want: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, Allowed(tt.addr, tt.allowlist))
+		})
+	}
+}
+
+func TestParseCIDR(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		in      string
+		out     string
+		wantErr bool
+	}{
+		{in: "192.0.2.0/24", out: "192.0.2.0/24"},
+		{in: "192.0.2.1", out: "192.0.2.1/32"},
+		{in: "::ffff:192.0.2.1", out: "192.0.2.1/32"},
+		{in: "2001:db8::/32", out: "2001:db8::/32"},
+		{in: "2001:db8::1", out: "2001:db8::1/128"},
+		{in: "192.0.2.1/24", wantErr: true},
+		{in: "192.0.2.0/33", wantErr: true},
+		{in: "example.com", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			prefix, err := ParseCIDR(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.out, prefix.String())
+		})
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		trustedHeader  string
+		trustedProxies int
+		remoteAddr     string
+		headerValue    string
+		want           string
+	}{
+		{name: "remote addr", remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+		{name: "remote addr ignores header", remoteAddr: "192.0.2.1:1234", headerValue: "198.51.100.1", want: "192.0.2.1"},
+		{name: "trusted header", trustedHeader: "X-Forwarded-For", remoteAddr: "192.0.2.1:1234", headerValue: "198.51.100.1", want: "198.51.100.1"},
+		{name: "trusted header list", trustedHeader: "X-Forwarded-For", remoteAddr: "192.0.2.1:1234", headerValue: "198.51.100.1, 203.0.113.1", want: "203.0.113.1"},
+		{name: "trusted header spoofed leading entry", trustedHeader: "X-Forwarded-For", remoteAddr: "192.0.2.1:1234", headerValue: "10.0.0.1, 203.0.113.1", want: "203.0.113.1"},
+		{name: "trusted header two proxies", trustedHeader: "X-Forwarded-For", trustedProxies: 2, remoteAddr: "192.0.2.1:1234", headerValue: "10.0.0.1, 203.0.113.1, 198.51.100.1", want: "203.0.113.1"},
+		{name: "trusted header too few entries", trustedHeader: "X-Forwarded-For", trustedProxies: 2, remoteAddr: "192.0.2.1:1234", headerValue: "203.0.113.1", want: "invalid IP"},
+		{name: "trusted header missing", trustedHeader: "X-Forwarded-For", remoteAddr: "192.0.2.1:1234", want: "invalid IP"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got netip.Addr
+			h := NewHandler(tt.trustedHeader, tt.trustedProxies, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.headerValue != "" {
+				r.Header.Set("X-Forwarded-For", tt.headerValue)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
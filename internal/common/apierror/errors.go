@@ -12,10 +12,13 @@ var errNotFound = "not_found"
 var errPasswordsUnavailableForEmail = "passwords_unavailable_for_email"
 var errIncorrectPassword = "incorrect_password"
 var errPasswordCompromised = "password_compromised"
+var errPasswordPolicyViolation = "password_policy_violation"
 var errPermissionDenied = "permission_denied"
 var errUnauthenticated = "unauthenticated"
 var errUnauthenticatedApiKey = "unauthenticated_api_key"
 var errIncorrectTOTPCode = "incorrect_totp_code"
+var errIPAddressNotAllowed = "ip_address_not_allowed"
+var errRateLimitExceeded = "rate_limit_exceeded"
 
 func NewAlreadyExistsError(description string, sourceError error) error {
 	apiErr := New(errAlreadyExists, sourceError)
@@ -165,6 +168,21 @@ func NewPasswordCompromisedError(description string, sourceError error) error {
 	return err
 }
 
+func NewPasswordPolicyViolationError(description string, sourceError error) error {
+	apiErr := New(errPasswordPolicyViolation, sourceError)
+
+	err := connect.NewError(connect.CodeFailedPrecondition, apiErr)
+
+	// Add details to the connect error
+	if detail, detailErr := connect.NewErrorDetail(&commonv1.ErrorDetail{
+		Description: description,
+	}); detailErr == nil {
+		err.AddDetail(detail)
+	}
+
+	return err
+}
+
 func NewInvalidTOTPCodeError(description string, sourceError error) error {
 	apiErr := New(errIncorrectTOTPCode, sourceError)
 
@@ -179,3 +197,33 @@ func NewInvalidTOTPCodeError(description string, sourceError error) error {
 
 	return err
 }
+
+func NewIPAddressNotAllowedError(description string, sourceError error) error {
+	apiErr := New(errIPAddressNotAllowed, sourceError)
+
+	err := connect.NewError(connect.CodePermissionDenied, apiErr)
+
+	// Add details to the connect error
+	if detail, detailErr := connect.NewErrorDetail(&commonv1.ErrorDetail{
+		Description: description,
+	}); detailErr == nil {
+		err.AddDetail(detail)
+	}
+
+	return err
+}
+
+func NewRateLimitExceededError(description string, sourceError error) error {
+	apiErr := New(errRateLimitExceeded, sourceError)
+
+	err := connect.NewError(connect.CodeResourceExhausted, apiErr)
+
+	// Add details to the connect error
+	if detail, detailErr := connect.NewErrorDetail(&commonv1.ErrorDetail{
+		Description: description,
+	}); detailErr == nil {
+		err.AddDetail(detail)
+	}
+
+	return err
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	commonv1 "github.com/tesseral-labs/tesseral/internal/common/gen/tesseral/common/v1"
 	"github.com/tesseral-labs/tesseral/internal/common/store/queries"
@@ -102,6 +103,15 @@ func (s *Store) IssueAccessToken(ctx context.Context, projectID uuid.UUID, refre
 		qDetails.ImpersonatorUserID = qSessionDetails.ImpersonatorUserID
 	}
 
+	qIPAllowlistCIDRs, err := s.q.GetOrganizationIPAllowlistCIDRs(ctx, qDetails.OrganizationID)
+	if err != nil {
+		return "", fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	if !clientip.Allowed(clientip.FromContext(ctx), qIPAllowlistCIDRs) {
+		return "", apierror.NewIPAddressNotAllowedError("client ip address is not allowed by organization ip allowlist", fmt.Errorf("client ip not in organization ip allowlist"))
+	}
+
 	issAndAud := fmt.Sprintf("https://%s.tesseral.app", strings.ReplaceAll(idformat.Project.Format(projectID), "_", "-"))
 	now := time.Now()
 
@@ -0,0 +1,150 @@
+package emailevent
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// ParseDSN parses an RFC 3464 delivery status notification, as an SMTP server
+// sends to the envelope sender of an email it could not (or, when requested,
+// could) deliver. It returns nil, without an error, for DSNs that report
+// neither a delivery nor a failure, such as delays.
+func ParseDSN(r io.Reader) (*Event, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("parse content type: %w", err)
+	}
+
+	if mediaType != "multipart/report" || !strings.EqualFold(params["report-type"], "delivery-status") {
+		return nil, fmt.Errorf("message is not a delivery status notification: %s", mediaType)
+	}
+
+	var status, original []byte
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read part: %w", err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			if status, err = io.ReadAll(part); err != nil {
+				return nil, fmt.Errorf("read delivery status: %w", err)
+			}
+		case "message/rfc822", "text/rfc822-headers", "message/global", "message/global-headers":
+			if original, err = io.ReadAll(part); err != nil {
+				return nil, fmt.Errorf("read original message: %w", err)
+			}
+		}
+	}
+
+	if status == nil {
+		return nil, fmt.Errorf("delivery status notification has no delivery status")
+	}
+
+	// the delivery status is a block of per-message fields, followed by a
+	// block of fields per recipient
+	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(status)))
+	if _, err := tp.ReadMIMEHeader(); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("read per-message fields: %w", err)
+	}
+
+	event := &Event{}
+	var details []string
+	for {
+		fields, err := tp.ReadMIMEHeader()
+		if len(fields) > 0 {
+			recipient := fields.Get("Final-Recipient")
+			if recipient == "" {
+				recipient = fields.Get("Original-Recipient")
+			}
+
+			var recipientType Type
+			switch strings.ToLower(strings.TrimSpace(fields.Get("Action"))) {
+			case "failed":
+				recipientType = TypeBounced
+			case "delivered":
+				recipientType = TypeDelivered
+			}
+
+			// a DSN may report different actions per recipient; report the
+			// first one that matters, and only recipients with that action
+			if recipientType != "" && recipient != "" && (event.Type == "" || event.Type == recipientType) {
+				event.Type = recipientType
+				event.Recipients = append(event.Recipients, normalizeAddress(recipient))
+
+				if recipientType == TypeBounced {
+					// 5.x.x statuses are permanent failures; 4.x.x are
+					// transient ones the server gave up retrying
+					if strings.HasPrefix(strings.TrimSpace(fields.Get("Status")), "5") {
+						event.Permanent = true
+					}
+
+					detail := fields.Get("Diagnostic-Code")
+					if detail == "" {
+						detail = fields.Get("Status")
+					}
+					if detail != "" {
+						details = append(details, detail)
+					}
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read per-recipient fields: %w", err)
+		}
+	}
+
+	if event.Type == "" {
+		return nil, nil
+	}
+
+	event.Detail = strings.Join(details, "; ")
+
+	if original != nil {
+		// the original message may be truncated after its headers, so only
+		// read those
+		tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(original)))
+		headers, _ := tp.ReadMIMEHeader()
+
+		event.Source = headers.Get("From")
+		event.MessageID = parseMessageIDLocalPart(headers.Get("Message-ID"))
+	}
+
+	return event, nil
+}
+
+// parseMessageIDLocalPart returns the local part of a Message-ID header, i.e.
+// "abc" for "<abc@example.com>".
+func parseMessageIDLocalPart(messageID string) string {
+	messageID = strings.TrimSpace(messageID)
+	messageID = strings.TrimPrefix(messageID, "<")
+	messageID = strings.TrimSuffix(messageID, ">")
+
+	localPart, _, ok := strings.Cut(messageID, "@")
+	if !ok {
+		return ""
+	}
+	return localPart
+}
@@ -0,0 +1,72 @@
+// Package emailevent parses delivery notifications for sent email: Amazon SES
+// notifications delivered through SNS, and RFC 3464 delivery status
+// notifications (DSNs) from SMTP servers.
+package emailevent
+
+import (
+	"net/mail"
+	"strings"
+)
+
+type Type string
+
+const (
+	TypeDelivered  Type = "delivered"
+	TypeBounced    Type = "bounced"
+	TypeComplained Type = "complained"
+)
+
+// Event is a notification about an email that was sent.
+type Event struct {
+	Type Type
+
+	// MessageID is the emailsender.Message ID of the email the event is about,
+	// or empty if the notification does not include it.
+	MessageID string
+
+	// Source is the address the email was sent from, if known.
+	Source string
+
+	// Recipients are the addresses the event applies to.
+	Recipients []string
+
+	// Permanent is whether further email to Recipients should be suppressed.
+	// It is always true for complaints, and true for bounces that are not
+	// transient.
+	Permanent bool
+
+	// Detail is a human-readable description of the event, e.g. a
+	// diagnostic code from the recipient's mail server.
+	Detail string
+}
+
+// SourceDomain returns the domain of e.Source, or the empty string.
+func (e *Event) SourceDomain() string {
+	source := e.Source
+	if addr, err := mail.ParseAddress(source); err == nil {
+		source = addr.Address
+	}
+
+	i := strings.LastIndex(source, "@")
+	if i == -1 {
+		return ""
+	}
+	return strings.ToLower(source[i+1:])
+}
+
+// normalizeAddress strips any display name and address type from an address,
+// and lowercases it.
+func normalizeAddress(address string) string {
+	address = strings.TrimSpace(address)
+
+	// DSN recipients are of the form "rfc822; user@example.com"
+	if i := strings.Index(address, ";"); i != -1 {
+		address = strings.TrimSpace(address[i+1:])
+	}
+
+	if addr, err := mail.ParseAddress(address); err == nil {
+		address = addr.Address
+	}
+
+	return strings.ToLower(address)
+}
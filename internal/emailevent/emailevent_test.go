@@ -0,0 +1,262 @@
+package emailevent
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSESNotification_Bounce(t *testing.T) {
+	event, err := ParseSESNotification([]byte(`{
+		"eventType": "Bounce",
+		"bounce": {
+			"bounceType": "Permanent",
+			"bounceSubType": "General",
+			"bouncedRecipients": [{"emailAddress": "Jane <Jane@Example.com>", "diagnosticCode": "smtp; 550 5.1.1 user unknown"}]
+		},
+		"mail": {
+			"source": "noreply@mail.example.com",
+			"tags": {"tesseral_message_id": ["0195f1a2-7b3c-7d4e-8f90-a1b2c3d4e5f6"]}
+		}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, &Event{
+		Type:       TypeBounced,
+		MessageID:  "0195f1a2-7b3c-7d4e-8f90-a1b2c3d4e5f6",
+		Source:     "noreply@mail.example.com",
+		Recipients: []string{"jane@example.com"},
+		Permanent:  true,
+		Detail:     "smtp; 550 5.1.1 user unknown",
+	}, event)
+	require.Equal(t, "mail.example.com", event.SourceDomain())
+}
+
+func TestParseSESNotification_TransientBounce(t *testing.T) {
+	event, err := ParseSESNotification([]byte(`{
+		"notificationType": "Bounce",
+		"bounce": {"bounceType": "Transient", "bounceSubType": "MailboxFull", "bouncedRecipients": [{"emailAddress": "jane@example.com"}]},
+		"mail": {"source": "Example <noreply@mail.example.com>"}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, TypeBounced, event.Type)
+	require.False(t, event.Permanent)
+	require.Empty(t, event.MessageID)
+	require.Equal(t, "Transient bounce (MailboxFull)", event.Detail)
+	require.Equal(t, "mail.example.com", event.SourceDomain())
+}
+
+func TestParseSESNotification_Complaint(t *testing.T) {
+	event, err := ParseSESNotification([]byte(`{
+		"notificationType": "Complaint",
+		"complaint": {"complaintFeedbackType": "abuse", "complainedRecipients": [{"emailAddress": "jane@example.com"}]},
+		"mail": {"source": "noreply@mail.example.com"}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, TypeComplained, event.Type)
+	require.True(t, event.Permanent)
+	require.Equal(t, []string{"jane@example.com"}, event.Recipients)
+}
+
+func TestParseSESNotification_Ignored(t *testing.T) {
+	event, err := ParseSESNotification([]byte(`{"eventType": "Open", "mail": {}}`))
+	require.NoError(t, err)
+	require.Nil(t, event)
+}
+
+const testDSN = "From: MAILER-DAEMON@mx.example.com\r\n" +
+	"To: noreply@mail.example.com\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"BOUNDARY\"\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Your message could not be delivered.\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.com\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; Jane@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; john@example.com\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.4.1\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"From: noreply@mail.example.com\r\n" +
+	"To: jane@example.com\r\n" +
+	"Message-ID: <0195f1a2-7b3c-7d4e-8f90-a1b2c3d4e5f6@mail.example.com>\r\n" +
+	"--BOUNDARY--\r\n"
+
+func TestParseDSN(t *testing.T) {
+	event, err := ParseDSN(strings.NewReader(testDSN))
+	require.NoError(t, err)
+	require.Equal(t, &Event{
+		Type:       TypeBounced,
+		MessageID:  "0195f1a2-7b3c-7d4e-8f90-a1b2c3d4e5f6",
+		Source:     "noreply@mail.example.com",
+		Recipients: []string{"jane@example.com"},
+		Permanent:  true,
+		Detail:     "smtp; 550 5.1.1 user unknown",
+	}, event)
+}
+
+func TestParseDSN_NotADSN(t *testing.T) {
+	_, err := ParseDSN(strings.NewReader("From: a@example.com\r\nContent-Type: text/plain\r\n\r\nhello\r\n"))
+	require.Error(t, err)
+}
+
+func TestValidateSNSURL(t *testing.T) {
+	require.NoError(t, ValidateSNSURL("https://sns.us-east-1.amazonaws.com/SimpleNotificationService-abc.pem"))
+	require.NoError(t, ValidateSNSURL("https://sns.cn-north-1.amazonaws.com.cn/SimpleNotificationService-abc.pem"))
+	require.Error(t, ValidateSNSURL("http://sns.us-east-1.amazonaws.com/SimpleNotificationService-abc.pem"))
+	require.Error(t, ValidateSNSURL("https://sns.us-east-1.amazonaws.com.evil.example.com/cert.pem"))
+	require.Error(t, ValidateSNSURL("https://evil.example.com/sns.us-east-1.amazonaws.com"))
+}
+
+const testCertURL = "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-test.pem"
+
+// newTestVerifier returns a verifier with a self-signed certificate cached
+// for testCertURL, and a function that signs messages with its key.
+func newTestVerifier(t *testing.T) (*SNSVerifier, func(msg *SNSMessage)) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+	}, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	verifier := &SNSVerifier{
+		HTTPClient: http.DefaultClient,
+		certs:      map[string]*x509.Certificate{testCertURL: cert},
+	}
+
+	sign := func(msg *SNSMessage) {
+		msg.SignatureVersion = "2"
+		msg.SigningCertURL = testCertURL
+
+		stringToSign, err := snsStringToSign(msg)
+		require.NoError(t, err)
+
+		digest := sha256.Sum256([]byte(stringToSign))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+
+		msg.Signature = base64.StdEncoding.EncodeToString(signature)
+	}
+
+	return verifier, sign
+}
+
+func TestSNSVerifier(t *testing.T) {
+	verifier, sign := newTestVerifier(t)
+
+	msg := &SNSMessage{
+		Type:      "Notification",
+		MessageId: "1",
+		TopicArn:  "arn:aws:sns:us-east-1:123456789012:ses",
+		Message:   `{"eventType": "Open"}`,
+		Timestamp: "2025-01-01T00:00:00.000Z",
+	}
+	sign(msg)
+	require.NoError(t, verifier.Verify(context.Background(), msg))
+
+	msg.Message = `{"eventType": "Bounce"}`
+	require.Error(t, verifier.Verify(context.Background(), msg))
+}
+
+type testRecorder struct {
+	events []*Event
+}
+
+func (r *testRecorder) RecordEmailEvent(ctx context.Context, event *Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestHandler_SES(t *testing.T) {
+	verifier, sign := newTestVerifier(t)
+	recorder := &testRecorder{}
+	handler := Handler(Config{SNSTopicARNs: "arn:aws:sns:us-east-1:123456789012:ses"}, verifier, recorder)
+
+	msg := &SNSMessage{
+		Type:      "Notification",
+		MessageId: "1",
+		TopicArn:  "arn:aws:sns:us-east-1:123456789012:ses",
+		Message:   `{"eventType": "Complaint", "complaint": {"complainedRecipients": [{"emailAddress": "jane@example.com"}]}, "mail": {}}`,
+		Timestamp: "2025-01-01T00:00:00.000Z",
+	}
+	sign(msg)
+
+	body, err := json.Marshal(msg)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ses", strings.NewReader(string(body))))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Len(t, recorder.events, 1)
+	require.Equal(t, TypeComplained, recorder.events[0].Type)
+
+	// a validly signed message from another topic is rejected
+	msg.TopicArn = "arn:aws:sns:us-east-1:999999999999:other"
+	sign(msg)
+	body, err = json.Marshal(msg)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ses", strings.NewReader(string(body))))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Len(t, recorder.events, 1)
+}
+
+func TestHandler_DSN(t *testing.T) {
+	recorder := &testRecorder{}
+	handler := Handler(Config{DSNToken: "secret"}, &SNSVerifier{}, recorder)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/dsn", strings.NewReader(testDSN)))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/dsn", strings.NewReader(testDSN))
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Len(t, recorder.events, 1)
+	require.Equal(t, []string{"jane@example.com"}, recorder.events[0].Recipients)
+
+	// the ses endpoint is disabled without topics
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ses", strings.NewReader("{}")))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
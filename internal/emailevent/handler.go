@@ -0,0 +1,163 @@
+package emailevent
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxNotificationBytes bounds the size of the notifications the handler reads.
+// SNS messages are at most 256 KiB; DSNs may include the original message.
+const maxNotificationBytes = 1 << 20
+
+type Config struct {
+	// SNSTopicARNs is a comma-separated list of the SNS topics SES publishes
+	// notifications to. Notifications from other topics are rejected. If
+	// empty, the SES endpoint is disabled.
+	SNSTopicARNs string `conf:"sns_topic_arns,noredact"`
+
+	// DSNToken is the bearer token required to submit DSNs. If empty, the DSN
+	// endpoint is disabled.
+	DSNToken string `conf:"dsn_token"`
+}
+
+// Recorder records the events the handler receives.
+type Recorder interface {
+	RecordEmailEvent(ctx context.Context, event *Event) error
+}
+
+// Handler serves two endpoints:
+//
+//   - POST /ses accepts SNS HTTP(S) deliveries of SES notifications. It
+//     confirms subscriptions to the configured topics.
+//   - POST /dsn accepts a raw RFC 3464 DSN, authenticated with a bearer token,
+//     e.g. piped from the mailbox that receives bounces.
+func Handler(config Config, verifier *SNSVerifier, recorder Recorder) http.Handler {
+	var topicARNs []string
+	for _, arn := range strings.Split(config.SNSTopicARNs, ",") {
+		if arn = strings.TrimSpace(arn); arn != "" {
+			topicARNs = append(topicARNs, arn)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /ses", func(w http.ResponseWriter, r *http.Request) {
+		if len(topicARNs) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		var msg SNSMessage
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxNotificationBytes)).Decode(&msg); err != nil {
+			http.Error(w, "invalid sns message", http.StatusBadRequest)
+			return
+		}
+
+		if !slices.Contains(topicARNs, msg.TopicArn) {
+			slog.WarnContext(r.Context(), "sns_unknown_topic", "topic_arn", msg.TopicArn)
+			http.Error(w, "unknown topic", http.StatusForbidden)
+			return
+		}
+
+		if err := verifier.Verify(r.Context(), &msg); err != nil {
+			slog.WarnContext(r.Context(), "sns_verify_failed", "error", err)
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+
+		switch msg.Type {
+		case "SubscriptionConfirmation":
+			if err := confirmSubscription(r.Context(), verifier.HTTPClient, msg.SubscribeURL); err != nil {
+				slog.ErrorContext(r.Context(), "sns_confirm_subscription_failed", "error", err)
+				http.Error(w, "confirm subscription failed", http.StatusBadGateway)
+				return
+			}
+
+			slog.InfoContext(r.Context(), "sns_subscription_confirmed", "topic_arn", msg.TopicArn)
+		case "Notification":
+			event, err := ParseSESNotification([]byte(msg.Message))
+			if err != nil {
+				slog.WarnContext(r.Context(), "parse_ses_notification_failed", "error", err)
+				http.Error(w, "invalid ses notification", http.StatusBadRequest)
+				return
+			}
+
+			if !record(w, r, recorder, event) {
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("POST /dsn", func(w http.ResponseWriter, r *http.Request) {
+		if config.DSNToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(config.DSNToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		event, err := ParseDSN(io.LimitReader(r.Body, maxNotificationBytes))
+		if err != nil {
+			slog.WarnContext(r.Context(), "parse_dsn_failed", "error", err)
+			http.Error(w, "invalid dsn", http.StatusBadRequest)
+			return
+		}
+
+		if !record(w, r, recorder, event) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+func record(w http.ResponseWriter, r *http.Request, recorder Recorder, event *Event) bool {
+	if event == nil {
+		return true
+	}
+
+	if err := recorder.RecordEmailEvent(r.Context(), event); err != nil {
+		slog.ErrorContext(r.Context(), "record_email_event_failed", "error", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return false
+	}
+
+	return true
+}
+
+func confirmSubscription(ctx context.Context, client *http.Client, subscribeURL string) error {
+	if err := ValidateSNSURL(subscribeURL); err != nil {
+		return fmt.Errorf("invalid subscribe url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("get subscribe url: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("get subscribe url: bad status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
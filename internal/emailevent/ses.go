@@ -0,0 +1,113 @@
+package emailevent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
+)
+
+// sesNotification is the union of the SES event publishing format and the
+// older identity notification format. The two differ only in whether the
+// event type is named eventType or notificationType.
+type sesNotification struct {
+	EventType        string `json:"eventType"`
+	NotificationType string `json:"notificationType"`
+
+	Mail struct {
+		Source string              `json:"source"`
+		Tags   map[string][]string `json:"tags"`
+	} `json:"mail"`
+
+	Bounce *struct {
+		BounceType        string `json:"bounceType"`
+		BounceSubType     string `json:"bounceSubType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+
+	Complaint *struct {
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+
+	Delivery *struct {
+		Recipients []string `json:"recipients"`
+	} `json:"delivery"`
+}
+
+// ParseSESNotification parses the Message of an SNS notification published by
+// SES. It returns nil, without an error, for SES events other than bounces,
+// complaints, and deliveries.
+func ParseSESNotification(message []byte) (*Event, error) {
+	var n sesNotification
+	if err := json.Unmarshal(message, &n); err != nil {
+		return nil, fmt.Errorf("unmarshal ses notification: %w", err)
+	}
+
+	eventType := n.EventType
+	if eventType == "" {
+		eventType = n.NotificationType
+	}
+
+	event := &Event{Source: n.Mail.Source}
+	if ids := n.Mail.Tags[emailsender.MessageIDTag]; len(ids) > 0 {
+		event.MessageID = ids[0]
+	}
+
+	switch eventType {
+	case "Bounce":
+		if n.Bounce == nil {
+			return nil, fmt.Errorf("bounce notification has no bounce")
+		}
+
+		event.Type = TypeBounced
+		// "Undetermined" bounces are treated as permanent, as SES does for its
+		// account-level suppression list
+		event.Permanent = n.Bounce.BounceType != "Transient"
+
+		var details []string
+		for _, r := range n.Bounce.BouncedRecipients {
+			event.Recipients = append(event.Recipients, normalizeAddress(r.EmailAddress))
+			if r.DiagnosticCode != "" {
+				details = append(details, r.DiagnosticCode)
+			}
+		}
+
+		if len(details) > 0 {
+			event.Detail = strings.Join(details, "; ")
+		} else {
+			event.Detail = fmt.Sprintf("%s bounce (%s)", n.Bounce.BounceType, n.Bounce.BounceSubType)
+		}
+	case "Complaint":
+		if n.Complaint == nil {
+			return nil, fmt.Errorf("complaint notification has no complaint")
+		}
+
+		event.Type = TypeComplained
+		event.Permanent = true
+		event.Detail = n.Complaint.ComplaintFeedbackType
+
+		for _, r := range n.Complaint.ComplainedRecipients {
+			event.Recipients = append(event.Recipients, normalizeAddress(r.EmailAddress))
+		}
+	case "Delivery":
+		if n.Delivery == nil {
+			return nil, fmt.Errorf("delivery notification has no delivery")
+		}
+
+		event.Type = TypeDelivered
+		for _, r := range n.Delivery.Recipients {
+			event.Recipients = append(event.Recipients, normalizeAddress(r))
+		}
+	default:
+		return nil, nil
+	}
+
+	return event, nil
+}
@@ -0,0 +1,204 @@
+package emailevent
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SNSMessage is the JSON body of an SNS HTTP(S) delivery.
+type SNSMessage struct {
+	Type             string `json:"Type"`
+	MessageId        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// snsHostPattern matches the hosts SNS serves signing certificates and
+// subscription confirmations from.
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SNSVerifier verifies the signatures of SNS messages.
+type SNSVerifier struct {
+	HTTPClient *http.Client
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate
+}
+
+// Verify returns an error unless msg is signed by SNS.
+func (v *SNSVerifier) Verify(ctx context.Context, msg *SNSMessage) error {
+	if err := ValidateSNSURL(msg.SigningCertURL); err != nil {
+		return fmt.Errorf("invalid signing cert url: %w", err)
+	}
+
+	cert, err := v.getCert(ctx, msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+
+	return verifySNSSignature(msg, cert)
+}
+
+func (v *SNSVerifier) getCert(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	v.mu.Lock()
+	cert, ok := v.certs[certURL]
+	v.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	res, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get signing cert: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get signing cert: bad status code: %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read signing cert: %w", err)
+	}
+
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, fmt.Errorf("signing cert is not pem-encoded")
+	}
+
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing cert: %w", err)
+	}
+
+	v.mu.Lock()
+	if v.certs == nil {
+		v.certs = map[string]*x509.Certificate{}
+	}
+	v.certs[certURL] = cert
+	v.mu.Unlock()
+
+	return cert, nil
+}
+
+// ValidateSNSURL returns an error unless u is an https URL on an SNS host.
+func ValidateSNSURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("parse url: %w", err)
+	}
+
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("url scheme is not https: %q", parsed.Scheme)
+	}
+
+	if !snsHostPattern.MatchString(parsed.Host) {
+		return fmt.Errorf("url host is not an sns host: %q", parsed.Host)
+	}
+
+	return nil
+}
+
+func verifySNSSignature(msg *SNSMessage, cert *x509.Certificate) error {
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("signing cert does not have an rsa public key")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	stringToSign, err := snsStringToSign(msg)
+	if err != nil {
+		return err
+	}
+
+	var hash crypto.Hash
+	var digest []byte
+	switch msg.SignatureVersion {
+	case "1":
+		sum := sha1.Sum([]byte(stringToSign))
+		hash, digest = crypto.SHA1, sum[:]
+	case "2":
+		sum := sha256.Sum256([]byte(stringToSign))
+		hash, digest = crypto.SHA256, sum[:]
+	default:
+		return fmt.Errorf("unsupported signature version: %q", msg.SignatureVersion)
+	}
+
+	if err := rsa.VerifyPKCS1v15(pub, hash, digest, signature); err != nil {
+		return fmt.Errorf("verify signature: %w", err)
+	}
+
+	return nil
+}
+
+// snsStringToSign builds the canonical string SNS signs: a fixed, per-type
+// list of field names and values, each followed by a newline.
+func snsStringToSign(msg *SNSMessage) (string, error) {
+	var fields [][2]string
+	switch msg.Type {
+	case "Notification":
+		fields = [][2]string{
+			{"Message", msg.Message},
+			{"MessageId", msg.MessageId},
+		}
+		if msg.Subject != "" {
+			fields = append(fields, [2]string{"Subject", msg.Subject})
+		}
+		fields = append(fields,
+			[2]string{"Timestamp", msg.Timestamp},
+			[2]string{"TopicArn", msg.TopicArn},
+			[2]string{"Type", msg.Type},
+		)
+	case "SubscriptionConfirmation", "UnsubscribeConfirmation":
+		fields = [][2]string{
+			{"Message", msg.Message},
+			{"MessageId", msg.MessageId},
+			{"SubscribeURL", msg.SubscribeURL},
+			{"Timestamp", msg.Timestamp},
+			{"Token", msg.Token},
+			{"TopicArn", msg.TopicArn},
+			{"Type", msg.Type},
+		}
+	default:
+		return "", fmt.Errorf("unsupported message type: %q", msg.Type)
+	}
+
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteString(f[0])
+		sb.WriteString("\n")
+		sb.WriteString(f[1])
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
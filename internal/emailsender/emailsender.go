@@ -0,0 +1,112 @@
+// Package emailsender sends transactional email through a configurable
+// backend.
+package emailsender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+)
+
+const (
+	BackendSES  = "ses"
+	BackendSMTP = "smtp"
+	BackendFile = "file"
+)
+
+// MessageIDTag is the name of the SES email tag that carries Message.ID.
+const MessageIDTag = "tesseral_message_id"
+
+// Message is an email to send. At least one of TextBody and HTMLBody must be
+// set; if both are, the message is sent as multipart/alternative.
+type Message struct {
+	// ID, if set, identifies the message in delivery notifications. SES
+	// reports it as the MessageIDTag email tag; other backends use it as the
+	// local part of the Message-ID header. It must consist of letters, digits,
+	// '-', and '_'.
+	ID string
+
+	From     string
+	To       []string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type Config struct {
+	// Backend is one of "ses", "smtp", or "file". Defaults to "ses".
+	Backend string `conf:"backend,noredact"`
+
+	// SESConfigurationSet, if set, is the SES configuration set to send with.
+	// Configure its event destination to publish bounces, complaints, and
+	// deliveries to SNS; message tags are only included in those events.
+	SESConfigurationSet string `conf:"ses_configuration_set,noredact"`
+
+	// SMTPAddr is the `host:port` of the SMTP server to send through.
+	SMTPAddr string `conf:"smtp_addr,noredact"`
+
+	// SMTPTLS is one of "starttls", "implicit", or "none". Defaults to
+	// "starttls", which requires the server to support STARTTLS.
+	SMTPTLS string `conf:"smtp_tls,noredact"`
+
+	// SMTPUsername and SMTPPassword, if set, are used to authenticate with
+	// SMTP PLAIN auth.
+	SMTPUsername string `conf:"smtp_username,noredact"`
+	SMTPPassword string `conf:"smtp_password"`
+
+	// FileDirectory is the directory the file backend writes messages to.
+	FileDirectory string `conf:"file_directory,noredact"`
+}
+
+// UsesSES returns whether config selects the SES backend.
+func (c Config) UsesSES() bool {
+	return c.Backend == "" || c.Backend == BackendSES
+}
+
+// New returns the Sender config selects. ses is only used, and only required,
+// for the SES backend.
+func New(config Config, ses *sesv2.Client) (Sender, error) {
+	switch config.Backend {
+	case "", BackendSES:
+		if ses == nil {
+			return nil, fmt.Errorf("ses backend requires an ses client")
+		}
+
+		return &SESSender{SES: ses, ConfigurationSet: config.SESConfigurationSet}, nil
+	case BackendSMTP:
+		if config.SMTPAddr == "" {
+			return nil, fmt.Errorf("smtp backend requires smtp_addr")
+		}
+
+		tlsMode := config.SMTPTLS
+		if tlsMode == "" {
+			tlsMode = smtpTLSStartTLS
+		}
+
+		switch tlsMode {
+		case smtpTLSStartTLS, smtpTLSImplicit, smtpTLSNone:
+		default:
+			return nil, fmt.Errorf("unknown smtp_tls: %q", config.SMTPTLS)
+		}
+
+		return &SMTPSender{
+			Addr:     config.SMTPAddr,
+			TLS:      tlsMode,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+		}, nil
+	case BackendFile:
+		if config.FileDirectory == "" {
+			return nil, fmt.Errorf("file backend requires file_directory")
+		}
+
+		return &FileSender{Directory: config.FileDirectory}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend: %q", config.Backend)
+	}
+}
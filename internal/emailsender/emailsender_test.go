@@ -0,0 +1,164 @@
+package emailsender
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMIME_TextOnly(t *testing.T) {
+	data, err := buildMIME(&Message{
+		From:     "noreply@mail.example.com",
+		To:       []string{"user@example.com"},
+		Subject:  "Vérify your email",
+		TextBody: "Hello, world",
+	}, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	require.Equal(t, "noreply@mail.example.com", msg.Header.Get("From"))
+	require.Equal(t, "user@example.com", msg.Header.Get("To"))
+	require.Equal(t, "Thu, 02 Jan 2025 03:04:05 +0000", msg.Header.Get("Date"))
+	require.True(t, strings.HasSuffix(msg.Header.Get("Message-ID"), "@mail.example.com>"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, "Vérify your email", subject)
+
+	require.Equal(t, "text/plain; charset=utf-8", msg.Header.Get("Content-Type"))
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	require.NoError(t, err)
+	require.Equal(t, "Hello, world", string(body))
+}
+
+func TestBuildMIME_Multipart(t *testing.T) {
+	data, err := buildMIME(&Message{
+		From:     "noreply@mail.example.com",
+		To:       []string{"user@example.com"},
+		Subject:  "Hello",
+		TextBody: "Hello, text",
+		HTMLBody: "<p>Hello, html</p>",
+	}, time.Now())
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	var contentTypes, bodies []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		// multipart.Reader transparently decodes quoted-printable parts
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+
+	require.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, contentTypes)
+	require.Equal(t, []string{"Hello, text", "<p>Hello, html</p>"}, bodies)
+}
+
+func TestBuildMIME_MessageID(t *testing.T) {
+	data, err := buildMIME(&Message{
+		ID:       "0195f1a2-7b3c-7d4e-8f90-a1b2c3d4e5f6",
+		From:     "noreply@mail.example.com",
+		To:       []string{"user@example.com"},
+		TextBody: "Hello",
+	}, time.Now())
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	require.Equal(t, "<0195f1a2-7b3c-7d4e-8f90-a1b2c3d4e5f6@mail.example.com>", msg.Header.Get("Message-ID"))
+}
+
+func TestBuildMIME_NoBody(t *testing.T) {
+	_, err := buildMIME(&Message{From: "noreply@example.com"}, time.Now())
+	require.Error(t, err)
+}
+
+func TestFileSender(t *testing.T) {
+	dir := t.TempDir()
+	sender := &FileSender{Directory: dir}
+
+	for _, subject := range []string{"First", "Second"} {
+		require.NoError(t, sender.Send(context.Background(), &Message{
+			From:     "noreply@mail.example.com",
+			To:       []string{"user@example.com"},
+			Subject:  subject,
+			TextBody: subject + " body",
+		}))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	srv := httptest.NewServer(MailboxHandler(dir))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/")
+	require.NoError(t, err)
+	listing, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	// newest first
+	require.Less(t, strings.Index(string(listing), "Second"), strings.Index(string(listing), "First"))
+
+	res, err = http.Get(srv.URL + "/" + entries[0].Name())
+	require.NoError(t, err)
+	raw, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Contains(t, string(raw), "Subject: First")
+
+	res, err = http.Get(srv.URL + "/..%2fsecret.eml")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{}, nil)
+	require.Error(t, err)
+
+	_, err = New(Config{Backend: BackendSMTP}, nil)
+	require.Error(t, err)
+
+	_, err = New(Config{Backend: BackendSMTP, SMTPAddr: "smtp.example.com:587", SMTPTLS: "bogus"}, nil)
+	require.Error(t, err)
+
+	sender, err := New(Config{Backend: BackendSMTP, SMTPAddr: "smtp.example.com:587"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, smtpTLSStartTLS, sender.(*SMTPSender).TLS)
+
+	_, err = New(Config{Backend: BackendFile}, nil)
+	require.Error(t, err)
+
+	_, err = New(Config{Backend: "carrier_pigeon"}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package emailsender
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileSender writes each email it sends to Directory as a .eml file, instead
+// of delivering it. It is meant for local development and testing; see
+// MailboxHandler to browse the messages it writes.
+type FileSender struct {
+	Directory string
+}
+
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := buildMIME(msg, now)
+	if err != nil {
+		return fmt.Errorf("build mime message: %w", err)
+	}
+
+	if err := os.MkdirAll(s.Directory, 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	// names sort by send time; write to a temporary file first, so that
+	// readers never see a partially-written message
+	name := fmt.Sprintf("%020d-%s.eml", now.UnixNano(), uuid.New())
+	tmp, err := os.CreateTemp(s.Directory, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.Directory, name)); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+
+	return nil
+}
+
+// MailboxHandler serves a listing of the messages a FileSender has written to
+// directory, newest first, and the raw contents of each message.
+func MailboxHandler(directory string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if name != "" {
+			// only serve files the listing links to
+			if name != filepath.Base(name) || !strings.HasSuffix(name, ".eml") {
+				http.NotFound(w, r)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			http.ServeFile(w, r, filepath.Join(directory, name))
+			return
+		}
+
+		entries, err := os.ReadDir(directory)
+		if err != nil && !os.IsNotExist(err) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var messages []mailboxMessage
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".eml") {
+				continue
+			}
+
+			messages = append(messages, readMailboxMessage(directory, entry.Name()))
+		}
+
+		slices.Reverse(messages)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := mailboxTmpl.Execute(w, messages); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+type mailboxMessage struct {
+	Name    string
+	Date    string
+	To      string
+	Subject string
+}
+
+func readMailboxMessage(directory, name string) mailboxMessage {
+	m := mailboxMessage{Name: name}
+
+	f, err := os.Open(filepath.Join(directory, name))
+	if err != nil {
+		return m
+	}
+	defer func() { _ = f.Close() }()
+
+	msg, err := mail.ReadMessage(f)
+	if err != nil {
+		return m
+	}
+
+	m.Date = msg.Header.Get("Date")
+	m.To = msg.Header.Get("To")
+	m.Subject = msg.Header.Get("Subject")
+	if subject, err := new(mime.WordDecoder).DecodeHeader(m.Subject); err == nil {
+		m.Subject = subject
+	}
+
+	return m
+}
+
+var mailboxTmpl = template.Must(template.New("mailbox").Parse(`<!doctype html>
+<html>
+<head><title>Mailbox</title></head>
+<body>
+<table>
+<tr><th>Date</th><th>To</th><th>Subject</th></tr>
+{{ range . }}<tr><td>{{ .Date }}</td><td>{{ .To }}</td><td><a href="{{ .Name }}">{{ .Subject }}</a></td></tr>
+{{ else }}<tr><td colspan="3">No messages.</td></tr>
+{{ end }}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,96 @@
+package emailsender
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// buildMIME renders msg as an RFC 5322 message, for backends that deliver raw
+// messages.
+func buildMIME(msg *Message, now time.Time) ([]byte, error) {
+	if msg.TextBody == "" && msg.HTMLBody == "" {
+		return nil, fmt.Errorf("message has no body")
+	}
+
+	var buf bytes.Buffer
+
+	domain := "localhost"
+	if i := strings.LastIndex(msg.From, "@"); i != -1 {
+		domain = msg.From[i+1:]
+	}
+
+	fmt.Fprintf(&buf, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	messageID := msg.ID
+	if messageID == "" {
+		messageID = uuid.NewString()
+	}
+
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", messageID, domain)
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+
+	if msg.TextBody == "" || msg.HTMLBody == "" {
+		contentType, body := "text/plain", msg.TextBody
+		if msg.HTMLBody != "" {
+			contentType, body = "text/html", msg.HTMLBody
+		}
+
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+		fmt.Fprintf(&buf, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	// per RFC 2046, the preferred alternative goes last
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", msg.TextBody},
+		{"text/html", msg.HTMLBody},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf("%s; charset=utf-8", part.contentType)},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create %s part: %w", part.contentType, err)
+		}
+
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qpw := quotedprintable.NewWriter(w)
+	if _, err := qpw.Write([]byte(body)); err != nil {
+		return fmt.Errorf("write quoted-printable body: %w", err)
+	}
+	if err := qpw.Close(); err != nil {
+		return fmt.Errorf("close quoted-printable writer: %w", err)
+	}
+	return nil
+}
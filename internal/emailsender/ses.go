@@ -0,0 +1,59 @@
+package emailsender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// SESSender sends email through Amazon SES.
+type SESSender struct {
+	SES *sesv2.Client
+
+	// ConfigurationSet, if set, is the SES configuration set to send with.
+	ConfigurationSet string
+}
+
+func (s *SESSender) Send(ctx context.Context, msg *Message) error {
+	body := &types.Body{}
+	if msg.TextBody != "" {
+		body.Text = &types.Content{Data: aws.String(msg.TextBody)}
+	}
+	if msg.HTMLBody != "" {
+		body.Html = &types.Content{Data: aws.String(msg.HTMLBody)}
+	}
+
+	input := &sesv2.SendEmailInput{
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{
+					Data: aws.String(msg.Subject),
+				},
+				Body: body,
+			},
+		},
+		Destination: &types.Destination{
+			ToAddresses: msg.To,
+		},
+		FromEmailAddress: aws.String(msg.From),
+	}
+
+	if s.ConfigurationSet != "" {
+		input.ConfigurationSetName = aws.String(s.ConfigurationSet)
+	}
+
+	if msg.ID != "" {
+		input.EmailTags = []types.MessageTag{
+			{Name: aws.String(MessageIDTag), Value: aws.String(msg.ID)},
+		}
+	}
+
+	if _, err := s.SES.SendEmail(ctx, input); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package emailtemplate
+
+// Locales lists the locales with built-in templates.
+var Locales = []string{"en", "es", "fr", "de", "pt"}
+
+func builtin(typ Type, locale string) *Template {
+	return builtins[typ][locale]
+}
+
+const buttonStyle = `display: inline-block; padding: 10px 20px; background-color: {{ .PrimaryColor }}; color: #ffffff; text-decoration: none; border-radius: 6px; font-weight: 600;`
+
+const codeStyle = `font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 13px; background-color: #f4f4f5; padding: 8px 12px; border-radius: 4px; word-break: break-all;`
+
+var builtins = map[Type]map[string]*Template{
+	TypeVerifyEmail: {
+		"en": {
+			Subject: `{{ .ProjectDisplayName }} - Verify your email address`,
+			TextBody: `Hello,
+
+To continue logging in to {{ .ProjectDisplayName }}, please verify your email address by visiting the link below.
+
+{{ .EmailVerificationLink }}
+
+You can also go back to the "Check your email" page and enter this verification code manually:
+
+{{ .EmailVerificationCode }}
+
+If you did not request this verification, please ignore this email.
+`,
+			HTMLBody: `<p>Hello,</p>
+<p>To continue logging in to {{ .ProjectDisplayName }}, please verify your email address.</p>
+<p><a href="{{ .EmailVerificationLink }}" style="` + buttonStyle + `">Verify email address</a></p>
+<p>You can also go back to the "Check your email" page and enter this verification code manually:</p>
+<p style="` + codeStyle + `">{{ .EmailVerificationCode }}</p>
+<p>If you did not request this verification, please ignore this email.</p>`,
+		},
+		"es": {
+			Subject: `{{ .ProjectDisplayName }} - Verifica tu dirección de correo electrónico`,
+			TextBody: `Hola:
+
+Para continuar iniciando sesión en {{ .ProjectDisplayName }}, verifica tu dirección de correo electrónico visitando el siguiente enlace.
+
+{{ .EmailVerificationLink }}
+
+También puedes volver a la página "Revisa tu correo electrónico" e introducir este código de verificación manualmente:
+
+{{ .EmailVerificationCode }}
+
+Si no solicitaste esta verificación, ignora este correo electrónico.
+`,
+			HTMLBody: `<p>Hola:</p>
+<p>Para continuar iniciando sesión en {{ .ProjectDisplayName }}, verifica tu dirección de correo electrónico.</p>
+<p><a href="{{ .EmailVerificationLink }}" style="` + buttonStyle + `">Verificar correo electrónico</a></p>
+<p>También puedes volver a la página "Revisa tu correo electrónico" e introducir este código de verificación manualmente:</p>
+<p style="` + codeStyle + `">{{ .EmailVerificationCode }}</p>
+<p>Si no solicitaste esta verificación, ignora este correo electrónico.</p>`,
+		},
+		"fr": {
+			Subject: `{{ .ProjectDisplayName }} - Vérifiez votre adresse e-mail`,
+			TextBody: `Bonjour,
+
+Pour continuer à vous connecter à {{ .ProjectDisplayName }}, veuillez vérifier votre adresse e-mail en cliquant sur le lien ci-dessous.
+
+{{ .EmailVerificationLink }}
+
+Vous pouvez également revenir à la page « Consultez vos e-mails » et saisir ce code de vérification manuellement :
+
+{{ .EmailVerificationCode }}
+
+Si vous n'êtes pas à l'origine de cette demande, veuillez ignorer cet e-mail.
+`,
+			HTMLBody: `<p>Bonjour,</p>
+<p>Pour continuer à vous connecter à {{ .ProjectDisplayName }}, veuillez vérifier votre adresse e-mail.</p>
+<p><a href="{{ .EmailVerificationLink }}" style="` + buttonStyle + `">Vérifier l'adresse e-mail</a></p>
+<p>Vous pouvez également revenir à la page « Consultez vos e-mails » et saisir ce code de vérification manuellement :</p>
+<p style="` + codeStyle + `">{{ .EmailVerificationCode }}</p>
+<p>Si vous n'êtes pas à l'origine de cette demande, veuillez ignorer cet e-mail.</p>`,
+		},
+		"de": {
+			Subject: `{{ .ProjectDisplayName }} - Bestätigen Sie Ihre E-Mail-Adresse`,
+			TextBody: `Hallo,
+
+um die Anmeldung bei {{ .ProjectDisplayName }} fortzusetzen, bestätigen Sie bitte Ihre E-Mail-Adresse über den folgenden Link.
+
+{{ .EmailVerificationLink }}
+
+Sie können auch zur Seite „Überprüfen Sie Ihre E-Mails“ zurückkehren und diesen Bestätigungscode manuell eingeben:
+
+{{ .EmailVerificationCode }}
+
+Falls Sie diese Bestätigung nicht angefordert haben, ignorieren Sie diese E-Mail bitte.
+`,
+			HTMLBody: `<p>Hallo,</p>
+<p>um die Anmeldung bei {{ .ProjectDisplayName }} fortzusetzen, bestätigen Sie bitte Ihre E-Mail-Adresse.</p>
+<p><a href="{{ .EmailVerificationLink }}" style="` + buttonStyle + `">E-Mail-Adresse bestätigen</a></p>
+<p>Sie können auch zur Seite „Überprüfen Sie Ihre E-Mails“ zurückkehren und diesen Bestätigungscode manuell eingeben:</p>
+<p style="` + codeStyle + `">{{ .EmailVerificationCode }}</p>
+<p>Falls Sie diese Bestätigung nicht angefordert haben, ignorieren Sie diese E-Mail bitte.</p>`,
+		},
+		"pt": {
+			Subject: `{{ .ProjectDisplayName }} - Verifique seu endereço de e-mail`,
+			TextBody: `Olá,
+
+Para continuar entrando em {{ .ProjectDisplayName }}, verifique seu endereço de e-mail acessando o link abaixo.
+
+{{ .EmailVerificationLink }}
+
+Você também pode voltar à página "Verifique seu e-mail" e inserir este código de verificação manualmente:
+
+{{ .EmailVerificationCode }}
+
+Se você não solicitou esta verificação, ignore este e-mail.
+`,
+			HTMLBody: `<p>Olá,</p>
+<p>Para continuar entrando em {{ .ProjectDisplayName }}, verifique seu endereço de e-mail.</p>
+<p><a href="{{ .EmailVerificationLink }}" style="` + buttonStyle + `">Verificar e-mail</a></p>
+<p>Você também pode voltar à página "Verifique seu e-mail" e inserir este código de verificação manualmente:</p>
+<p style="` + codeStyle + `">{{ .EmailVerificationCode }}</p>
+<p>Se você não solicitou esta verificação, ignore este e-mail.</p>`,
+		},
+	},
+	TypePasswordReset: {
+		"en": {
+			Subject: `{{ .ProjectDisplayName }} - Reset password`,
+			TextBody: `Hello,
+
+Someone has requested a password reset for your {{ .ProjectDisplayName }} account. If you did not request this, please ignore this email.
+
+To continue logging in to {{ .ProjectDisplayName }}, please go back to the "Forgot password" page and enter this verification code:
+
+{{ .PasswordResetCode }}
+
+If you did not request this verification, please ignore this email.
+`,
+			HTMLBody: `<p>Hello,</p>
+<p>Someone has requested a password reset for your {{ .ProjectDisplayName }} account. If you did not request this, please ignore this email.</p>
+<p>To continue logging in to {{ .ProjectDisplayName }}, please go back to the "Forgot password" page and enter this verification code:</p>
+<p style="` + codeStyle + `">{{ .PasswordResetCode }}</p>
+<p>If you did not request this verification, please ignore this email.</p>`,
+		},
+		"es": {
+			Subject: `{{ .ProjectDisplayName }} - Restablecer contraseña`,
+			TextBody: `Hola:
+
+Alguien ha solicitado restablecer la contraseña de tu cuenta de {{ .ProjectDisplayName }}. Si no lo solicitaste, ignora este correo electrónico.
+
+Para continuar iniciando sesión en {{ .ProjectDisplayName }}, vuelve a la página "¿Olvidaste tu contraseña?" e introduce este código de verificación:
+
+{{ .PasswordResetCode }}
+
+Si no solicitaste esta verificación, ignora este correo electrónico.
+`,
+			HTMLBody: `<p>Hola:</p>
+<p>Alguien ha solicitado restablecer la contraseña de tu cuenta de {{ .ProjectDisplayName }}. Si no lo solicitaste, ignora este correo electrónico.</p>
+<p>Para continuar iniciando sesión en {{ .ProjectDisplayName }}, vuelve a la página "¿Olvidaste tu contraseña?" e introduce este código de verificación:</p>
+<p style="` + codeStyle + `">{{ .PasswordResetCode }}</p>
+<p>Si no solicitaste esta verificación, ignora este correo electrónico.</p>`,
+		},
+		"fr": {
+			Subject: `{{ .ProjectDisplayName }} - Réinitialiser le mot de passe`,
+			TextBody: `Bonjour,
+
+Quelqu'un a demandé la réinitialisation du mot de passe de votre compte {{ .ProjectDisplayName }}. Si vous n'êtes pas à l'origine de cette demande, veuillez ignorer cet e-mail.
+
+Pour continuer à vous connecter à {{ .ProjectDisplayName }}, revenez à la page « Mot de passe oublié » et saisissez ce code de vérification :
+
+{{ .PasswordResetCode }}
+
+Si vous n'êtes pas à l'origine de cette demande, veuillez ignorer cet e-mail.
+`,
+			HTMLBody: `<p>Bonjour,</p>
+<p>Quelqu'un a demandé la réinitialisation du mot de passe de votre compte {{ .ProjectDisplayName }}. Si vous n'êtes pas à l'origine de cette demande, veuillez ignorer cet e-mail.</p>
+<p>Pour continuer à vous connecter à {{ .ProjectDisplayName }}, revenez à la page « Mot de passe oublié » et saisissez ce code de vérification :</p>
+<p style="` + codeStyle + `">{{ .PasswordResetCode }}</p>
+<p>Si vous n'êtes pas à l'origine de cette demande, veuillez ignorer cet e-mail.</p>`,
+		},
+		"de": {
+			Subject: `{{ .ProjectDisplayName }} - Passwort zurücksetzen`,
+			TextBody: `Hallo,
+
+jemand hat das Zurücksetzen des Passworts für Ihr {{ .ProjectDisplayName }}-Konto angefordert. Falls Sie dies nicht angefordert haben, ignorieren Sie diese E-Mail bitte.
+
+Um die Anmeldung bei {{ .ProjectDisplayName }} fortzusetzen, kehren Sie zur Seite „Passwort vergessen“ zurück und geben Sie diesen Bestätigungscode ein:
+
+{{ .PasswordResetCode }}
+
+Falls Sie diese Bestätigung nicht angefordert haben, ignorieren Sie diese E-Mail bitte.
+`,
+			HTMLBody: `<p>Hallo,</p>
+<p>jemand hat das Zurücksetzen des Passworts für Ihr {{ .ProjectDisplayName }}-Konto angefordert. Falls Sie dies nicht angefordert haben, ignorieren Sie diese E-Mail bitte.</p>
+<p>Um die Anmeldung bei {{ .ProjectDisplayName }} fortzusetzen, kehren Sie zur Seite „Passwort vergessen“ zurück und geben Sie diesen Bestätigungscode ein:</p>
+<p style="` + codeStyle + `">{{ .PasswordResetCode }}</p>
+<p>Falls Sie diese Bestätigung nicht angefordert haben, ignorieren Sie diese E-Mail bitte.</p>`,
+		},
+		"pt": {
+			Subject: `{{ .ProjectDisplayName }} - Redefinir senha`,
+			TextBody: `Olá,
+
+Alguém solicitou a redefinição da senha da sua conta {{ .ProjectDisplayName }}. Se você não fez essa solicitação, ignore este e-mail.
+
+Para continuar entrando em {{ .ProjectDisplayName }}, volte à página "Esqueci minha senha" e insira este código de verificação:
+
+{{ .PasswordResetCode }}
+
+Se você não solicitou esta verificação, ignore este e-mail.
+`,
+			HTMLBody: `<p>Olá,</p>
+<p>Alguém solicitou a redefinição da senha da sua conta {{ .ProjectDisplayName }}. Se você não fez essa solicitação, ignore este e-mail.</p>
+<p>Para continuar entrando em {{ .ProjectDisplayName }}, volte à página "Esqueci minha senha" e insira este código de verificação:</p>
+<p style="` + codeStyle + `">{{ .PasswordResetCode }}</p>
+<p>Se você não solicitou esta verificação, ignore este e-mail.</p>`,
+		},
+	},
+	TypeUserInvite: {
+		"en": {
+			Subject: `{{ .ProjectDisplayName }} - You've been invited to join {{ .OrganizationDisplayName }}`,
+			TextBody: `Hello,
+
+You have been invited to join {{ .OrganizationDisplayName }} in {{ .ProjectDisplayName }}.
+
+You can accept this invite by signing up for {{ .ProjectDisplayName }}:
+
+{{ .SignupLink }}
+`,
+			HTMLBody: `<p>Hello,</p>
+<p>You have been invited to join {{ .OrganizationDisplayName }} in {{ .ProjectDisplayName }}.</p>
+<p>You can accept this invite by signing up for {{ .ProjectDisplayName }}:</p>
+<p><a href="{{ .SignupLink }}" style="` + buttonStyle + `">Accept invite</a></p>`,
+		},
+		"es": {
+			Subject: `{{ .ProjectDisplayName }} - Te han invitado a unirte a {{ .OrganizationDisplayName }}`,
+			TextBody: `Hola:
+
+Te han invitado a unirte a {{ .OrganizationDisplayName }} en {{ .ProjectDisplayName }}.
+
+Puedes aceptar esta invitación registrándote en {{ .ProjectDisplayName }}:
+
+{{ .SignupLink }}
+`,
+			HTMLBody: `<p>Hola:</p>
+<p>Te han invitado a unirte a {{ .OrganizationDisplayName }} en {{ .ProjectDisplayName }}.</p>
+<p>Puedes aceptar esta invitación registrándote en {{ .ProjectDisplayName }}:</p>
+<p><a href="{{ .SignupLink }}" style="` + buttonStyle + `">Aceptar invitación</a></p>`,
+		},
+		"fr": {
+			Subject: `{{ .ProjectDisplayName }} - Vous avez été invité à rejoindre {{ .OrganizationDisplayName }}`,
+			TextBody: `Bonjour,
+
+Vous avez été invité à rejoindre {{ .OrganizationDisplayName }} sur {{ .ProjectDisplayName }}.
+
+Vous pouvez accepter cette invitation en vous inscrivant sur {{ .ProjectDisplayName }} :
+
+{{ .SignupLink }}
+`,
+			HTMLBody: `<p>Bonjour,</p>
+<p>Vous avez été invité à rejoindre {{ .OrganizationDisplayName }} sur {{ .ProjectDisplayName }}.</p>
+<p>Vous pouvez accepter cette invitation en vous inscrivant sur {{ .ProjectDisplayName }} :</p>
+<p><a href="{{ .SignupLink }}" style="` + buttonStyle + `">Accepter l'invitation</a></p>`,
+		},
+		"de": {
+			Subject: `{{ .ProjectDisplayName }} - Sie wurden eingeladen, {{ .OrganizationDisplayName }} beizutreten`,
+			TextBody: `Hallo,
+
+Sie wurden eingeladen, {{ .OrganizationDisplayName }} in {{ .ProjectDisplayName }} beizutreten.
+
+Sie können diese Einladung annehmen, indem Sie sich bei {{ .ProjectDisplayName }} registrieren:
+
+{{ .SignupLink }}
+`,
+			HTMLBody: `<p>Hallo,</p>
+<p>Sie wurden eingeladen, {{ .OrganizationDisplayName }} in {{ .ProjectDisplayName }} beizutreten.</p>
+<p>Sie können diese Einladung annehmen, indem Sie sich bei {{ .ProjectDisplayName }} registrieren:</p>
+<p><a href="{{ .SignupLink }}" style="` + buttonStyle + `">Einladung annehmen</a></p>`,
+		},
+		"pt": {
+			Subject: `{{ .ProjectDisplayName }} - Você foi convidado para participar de {{ .OrganizationDisplayName }}`,
+			TextBody: `Olá,
+
+Você foi convidado para participar de {{ .OrganizationDisplayName }} em {{ .ProjectDisplayName }}.
+
+Você pode aceitar este convite cadastrando-se em {{ .ProjectDisplayName }}:
+
+{{ .SignupLink }}
+`,
+			HTMLBody: `<p>Olá,</p>
+<p>Você foi convidado para participar de {{ .OrganizationDisplayName }} em {{ .ProjectDisplayName }}.</p>
+<p>Você pode aceitar este convite cadastrando-se em {{ .ProjectDisplayName }}:</p>
+<p><a href="{{ .SignupLink }}" style="` + buttonStyle + `">Aceitar convite</a></p>`,
+		},
+	},
+}
@@ -0,0 +1,229 @@
+// Package emailtemplate renders the emails Tesseral sends on behalf of
+// projects: built-in, localized defaults and per-project overrides, wrapped in
+// a layout branded with the project's logo and primary color.
+package emailtemplate
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"regexp"
+	texttemplate "text/template"
+
+	"golang.org/x/text/language"
+)
+
+type Type string
+
+const (
+	TypeVerifyEmail   Type = "verify_email"
+	TypePasswordReset Type = "password_reset"
+	TypeUserInvite    Type = "user_invite"
+)
+
+// DefaultLocale is used when neither the recipient nor their organization has
+// a locale, or when no template exists for their locale.
+const DefaultLocale = "en"
+
+// DefaultPrimaryColor is used when a project has not configured a primary
+// color.
+const DefaultPrimaryColor = "#0f172a"
+
+// Template is the source of an email. Subject and TextBody are text/template
+// templates; HTMLBody is an html/template template, rendered into the branded
+// layout. All three are executed against Data.
+type Template struct {
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Data is the set of variables available to templates. Fields that do not
+// apply to an email's Type are empty.
+type Data struct {
+	ProjectDisplayName      string
+	OrganizationDisplayName string
+	LogoURL                 string
+	PrimaryColor            string
+	EmailVerificationLink   string
+	EmailVerificationCode   string
+	PasswordResetCode       string
+	SignupLink              string
+}
+
+// Email is a rendered Template.
+type Email struct {
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}){1,2}$`)
+
+// Render executes t against data.
+func Render(t *Template, data *Data) (*Email, error) {
+	subjectTmpl, err := texttemplate.New("subject").Option("missingkey=error").Parse(t.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("parse subject: %w", err)
+	}
+
+	textTmpl, err := texttemplate.New("text_body").Option("missingkey=error").Parse(t.TextBody)
+	if err != nil {
+		return nil, fmt.Errorf("parse text body: %w", err)
+	}
+
+	layout, err := layoutTmpl.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("clone layout: %w", err)
+	}
+
+	if _, err := layout.New("content").Parse(t.HTMLBody); err != nil {
+		return nil, fmt.Errorf("parse html body: %w", err)
+	}
+
+	// the primary color is interpolated into style attributes; anything other
+	// than a hex color would be replaced by html/template with a placeholder
+	// anyway
+	branded := *data
+	if !hexColorPattern.MatchString(branded.PrimaryColor) {
+		branded.PrimaryColor = DefaultPrimaryColor
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, &branded); err != nil {
+		return nil, fmt.Errorf("execute subject: %w", err)
+	}
+
+	if err := textTmpl.Execute(&text, &branded); err != nil {
+		return nil, fmt.Errorf("execute text body: %w", err)
+	}
+
+	if err := layout.Execute(&html, &branded); err != nil {
+		return nil, fmt.Errorf("execute html body: %w", err)
+	}
+
+	return &Email{
+		Subject:  subject.String(),
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}, nil
+}
+
+// Validate returns an error if t does not parse, or if it refers to variables
+// that do not exist.
+func Validate(t *Template) error {
+	_, err := Render(t, SampleData("Example", "", ""))
+	return err
+}
+
+// SampleData returns Data with placeholder values for every variable, for
+// validating and previewing templates.
+func SampleData(projectDisplayName, logoURL, primaryColor string) *Data {
+	return &Data{
+		ProjectDisplayName:      projectDisplayName,
+		OrganizationDisplayName: "Acme Corporation",
+		LogoURL:                 logoURL,
+		PrimaryColor:            primaryColor,
+		EmailVerificationLink:   "https://vault.example.com/verify-email?code=email_verification_challenge_code_example",
+		EmailVerificationCode:   "email_verification_challenge_code_example",
+		PasswordResetCode:       "password_reset_code_example",
+		SignupLink:              "https://vault.example.com/signup",
+	}
+}
+
+// ParseLocale validates a BCP 47 language tag, returning it in canonical form.
+func ParseLocale(s string) (string, error) {
+	tag, err := language.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("parse locale: %w", err)
+	}
+	return tag.String(), nil
+}
+
+// Candidates returns the locales to try, in order, for a recipient whose
+// preferred locales are given in order of preference. Each locale is followed
+// by its base language, and DefaultLocale is always last. Empty and invalid
+// locales are skipped.
+func Candidates(locales ...string) []string {
+	var candidates []string
+	add := func(locale string) {
+		for _, c := range candidates {
+			if c == locale {
+				return
+			}
+		}
+		candidates = append(candidates, locale)
+	}
+
+	for _, locale := range locales {
+		if locale == "" {
+			continue
+		}
+
+		tag, err := language.Parse(locale)
+		if err != nil {
+			continue
+		}
+
+		add(tag.String())
+		base, _ := tag.Base()
+		add(base.String())
+	}
+
+	add(DefaultLocale)
+	return candidates
+}
+
+// Resolve returns the template to use for an email of type typ, given a
+// project's overrides for that type keyed by locale, and the recipient's
+// preferred locales. A built-in template in a preferred locale wins over an
+// override in a less-preferred one.
+func Resolve(typ Type, overrides map[string]*Template, locales ...string) (*Template, string) {
+	for _, locale := range Candidates(locales...) {
+		if t, ok := overrides[locale]; ok {
+			return t, locale
+		}
+		if t := builtin(typ, locale); t != nil {
+			return t, locale
+		}
+	}
+
+	// unreachable, as there is always a built-in template in DefaultLocale
+	return builtin(typ, DefaultLocale), DefaultLocale
+}
+
+// Default returns the built-in template for typ in locale, falling back to
+// DefaultLocale.
+func Default(typ Type, locale string) *Template {
+	t, _ := Resolve(typ, nil, locale)
+	return t
+}
+
+var layoutTmpl = htmltemplate.Must(htmltemplate.New("layout").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body style="margin: 0; padding: 0; background-color: #f4f4f5; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif; color: #18181b;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="background-color: #f4f4f5; padding: 32px 0;">
+<tr>
+<td align="center">
+<table role="presentation" width="560" cellpadding="0" cellspacing="0" style="max-width: 560px; width: 100%; background-color: #ffffff; border-radius: 8px; border-top: 4px solid {{ .PrimaryColor }};">
+<tr>
+<td style="padding: 32px 32px 0 32px;">
+{{ if .LogoURL }}<img src="{{ .LogoURL }}" alt="{{ .ProjectDisplayName }}" height="40" style="height: 40px; max-width: 200px;">{{ else }}<strong style="font-size: 20px;">{{ .ProjectDisplayName }}</strong>{{ end }}
+</td>
+</tr>
+<tr>
+<td style="padding: 24px 32px 32px 32px; font-size: 15px; line-height: 24px;">
+{{ template "content" . }}
+</td>
+</tr>
+</table>
+</td>
+</tr>
+</table>
+</body>
+</html>
+`))
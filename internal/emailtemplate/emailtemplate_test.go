@@ -0,0 +1,108 @@
+package emailtemplate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltins(t *testing.T) {
+	for _, typ := range []Type{TypeVerifyEmail, TypePasswordReset, TypeUserInvite} {
+		for _, locale := range Locales {
+			tmpl := builtin(typ, locale)
+			require.NotNil(t, tmpl, "%s %s", typ, locale)
+			require.NoError(t, Validate(tmpl), "%s %s", typ, locale)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	email, err := Render(Default(TypeVerifyEmail, "en"), &Data{
+		ProjectDisplayName:    "Acme <Cloud>",
+		LogoURL:               "https://cdn.example.com/logo.png",
+		PrimaryColor:          "#ff0000",
+		EmailVerificationLink: "https://vault.example.com/verify-email?code=abc",
+		EmailVerificationCode: "abc",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "Acme <Cloud> - Verify your email address", email.Subject)
+	require.Contains(t, email.TextBody, "https://vault.example.com/verify-email?code=abc")
+	require.Contains(t, email.HTMLBody, `<img src="https://cdn.example.com/logo.png" alt="Acme &lt;Cloud&gt;"`)
+	require.Contains(t, email.HTMLBody, "background-color: #ff0000")
+	require.Contains(t, email.HTMLBody, `href="https://vault.example.com/verify-email?code=abc"`)
+	require.NotContains(t, email.HTMLBody, "<Cloud>")
+}
+
+func TestRender_InvalidPrimaryColor(t *testing.T) {
+	email, err := Render(Default(TypeUserInvite, "en"), &Data{
+		PrimaryColor: "red; background-image: url(https://evil.example.com)",
+	})
+	require.NoError(t, err)
+	require.Contains(t, email.HTMLBody, "background-color: "+DefaultPrimaryColor)
+	require.NotContains(t, email.HTMLBody, "evil.example.com")
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(&Template{
+		Subject:  "Welcome to {{ .ProjectDisplayName }}",
+		TextBody: "{{ .SignupLink }}",
+		HTMLBody: `<a href="{{ .SignupLink }}">Sign up</a>`,
+	}))
+
+	require.Error(t, Validate(&Template{
+		Subject:  "{{ .ProjectDisplayName",
+		TextBody: "text",
+		HTMLBody: "html",
+	}))
+
+	require.Error(t, Validate(&Template{
+		Subject:  "subject",
+		TextBody: "{{ .NoSuchVariable }}",
+		HTMLBody: "html",
+	}))
+
+	require.Error(t, Validate(&Template{
+		Subject:  "subject",
+		TextBody: "text",
+		HTMLBody: "{{ .NoSuchVariable }}",
+	}))
+}
+
+func TestCandidates(t *testing.T) {
+	require.Equal(t, []string{"en"}, Candidates())
+	require.Equal(t, []string{"en"}, Candidates("", "not a locale!"))
+	require.Equal(t, []string{"pt-BR", "pt", "de", "en"}, Candidates("pt-br", "de"))
+	require.Equal(t, []string{"en-GB", "en"}, Candidates("en-GB", "en"))
+}
+
+func TestResolve(t *testing.T) {
+	override := &Template{Subject: "override"}
+
+	tmpl, locale := Resolve(TypeVerifyEmail, map[string]*Template{"fr": override}, "fr-CA")
+	require.Equal(t, override, tmpl)
+	require.Equal(t, "fr", locale)
+
+	// a built-in template in the recipient's language wins over an override in
+	// the default locale
+	tmpl, locale = Resolve(TypeVerifyEmail, map[string]*Template{"en": override}, "de")
+	require.True(t, strings.HasPrefix(tmpl.Subject, "{{ .ProjectDisplayName }} - Bestätigen"))
+	require.Equal(t, "de", locale)
+
+	tmpl, locale = Resolve(TypeVerifyEmail, map[string]*Template{"en": override}, "zh")
+	require.Equal(t, override, tmpl)
+	require.Equal(t, "en", locale)
+
+	_, locale = Resolve(TypePasswordReset, nil, "zh")
+	require.Equal(t, "en", locale)
+}
+
+func TestParseLocale(t *testing.T) {
+	locale, err := ParseLocale("pt-br")
+	require.NoError(t, err)
+	require.Equal(t, "pt-BR", locale)
+
+	_, err = ParseLocale("not a locale!")
+	require.Error(t, err)
+}
@@ -61,3 +61,21 @@ func (s *Service) UpdateAPIKey(ctx context.Context, req *connect.Request[fronten
 
 	return connect.NewResponse(res), nil
 }
+
+func (s *Service) GetAPIKeyUsage(ctx context.Context, req *connect.Request[frontendv1.GetAPIKeyUsageRequest]) (*connect.Response[frontendv1.GetAPIKeyUsageResponse], error) {
+	res, err := s.Store.GetAPIKeyUsage(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) RotateAPIKey(ctx context.Context, req *connect.Request[frontendv1.RotateAPIKeyRequest]) (*connect.Response[frontendv1.RotateAPIKeyResponse], error) {
+	res, err := s.Store.RotateAPIKey(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
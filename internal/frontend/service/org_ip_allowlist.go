@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+)
+
+func (s *Service) GetOrganizationIPAllowlist(ctx context.Context, req *connect.Request[frontendv1.GetOrganizationIPAllowlistRequest]) (*connect.Response[frontendv1.GetOrganizationIPAllowlistResponse], error) {
+	res, err := s.Store.GetOrganizationIPAllowlist(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateOrganizationIPAllowlist(ctx context.Context, req *connect.Request[frontendv1.UpdateOrganizationIPAllowlistRequest]) (*connect.Response[frontendv1.UpdateOrganizationIPAllowlistResponse], error) {
+	res, err := s.Store.UpdateOrganizationIPAllowlist(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
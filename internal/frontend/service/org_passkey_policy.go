@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+)
+
+func (s *Service) GetOrganizationPasskeyPolicy(ctx context.Context, req *connect.Request[frontendv1.GetOrganizationPasskeyPolicyRequest]) (*connect.Response[frontendv1.GetOrganizationPasskeyPolicyResponse], error) {
+	res, err := s.Store.GetOrganizationPasskeyPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateOrganizationPasskeyPolicy(ctx context.Context, req *connect.Request[frontendv1.UpdateOrganizationPasskeyPolicyRequest]) (*connect.Response[frontendv1.UpdateOrganizationPasskeyPolicyResponse], error) {
+	res, err := s.Store.UpdateOrganizationPasskeyPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
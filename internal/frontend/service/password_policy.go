@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+)
+
+func (s *Service) GetOrganizationPasswordPolicy(ctx context.Context, req *connect.Request[frontendv1.GetOrganizationPasswordPolicyRequest]) (*connect.Response[frontendv1.GetOrganizationPasswordPolicyResponse], error) {
+	res, err := s.Store.GetOrganizationPasswordPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateOrganizationPasswordPolicy(ctx context.Context, req *connect.Request[frontendv1.UpdateOrganizationPasswordPolicyRequest]) (*connect.Response[frontendv1.UpdateOrganizationPasswordPolicyResponse], error) {
+	res, err := s.Store.UpdateOrganizationPasswordPolicy(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+)
+
+func (s *Service) ListRoleElevationRequests(ctx context.Context, req *connect.Request[frontendv1.ListRoleElevationRequestsRequest]) (*connect.Response[frontendv1.ListRoleElevationRequestsResponse], error) {
+	res, err := s.Store.ListRoleElevationRequests(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetRoleElevationRequest(ctx context.Context, req *connect.Request[frontendv1.GetRoleElevationRequestRequest]) (*connect.Response[frontendv1.GetRoleElevationRequestResponse], error) {
+	res, err := s.Store.GetRoleElevationRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CreateRoleElevationRequest(ctx context.Context, req *connect.Request[frontendv1.CreateRoleElevationRequestRequest]) (*connect.Response[frontendv1.CreateRoleElevationRequestResponse], error) {
+	res, err := s.Store.CreateRoleElevationRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) ApproveRoleElevationRequest(ctx context.Context, req *connect.Request[frontendv1.ApproveRoleElevationRequestRequest]) (*connect.Response[frontendv1.ApproveRoleElevationRequestResponse], error) {
+	res, err := s.Store.ApproveRoleElevationRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DenyRoleElevationRequest(ctx context.Context, req *connect.Request[frontendv1.DenyRoleElevationRequestRequest]) (*connect.Response[frontendv1.DenyRoleElevationRequestResponse], error) {
+	res, err := s.Store.DenyRoleElevationRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
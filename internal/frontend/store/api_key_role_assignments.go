@@ -34,16 +34,21 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *frontendv1.
 		return nil, apierror.NewInvalidArgumentError("invalid role id", fmt.Errorf("parse role id: %w", err))
 	}
 
-	if _, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+	qAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
 		ID:             apiKeyID,
 		OrganizationID: authn.OrganizationID(ctx),
-	}); err != nil {
+	})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
 		}
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
 
+	if qAPIKey.UserID != nil {
+		return nil, apierror.NewFailedPreconditionError("personal api keys have their owner's actions, and cannot be assigned roles", fmt.Errorf("api key is a personal api key"))
+	}
+
 	if _, err := q.GetRole(ctx, queries.GetRoleParams{
 		ID:             roleID,
 		OrganizationID: &orgID,
@@ -72,7 +77,7 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *frontendv1.
 		return nil, fmt.Errorf("get audit log api key role assignment: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.assign_role",
 		EventDetails: &auditlogv1.AssignAPIKeyRole{
 			ApiKeyRoleAssignment: auditAPIKeyRoleAssignment,
@@ -127,7 +132,7 @@ func (s *Store) DeleteAPIKeyRoleAssignment(ctx context.Context, req *frontendv1.
 		return nil, fmt.Errorf("delete api key role assignment: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.unassign_role",
 		EventDetails: &auditlogv1.UnassignAPIKeyRole{
 			ApiKeyRoleAssignment: auditAPIKeyRoleAssignment,
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxAPIKeyUsageBuckets is the most buckets GetAPIKeyUsage returns, e.g. a
+// day of minutes.
+const maxAPIKeyUsageBuckets = 24 * 60
+
+func (s *Store) GetAPIKeyUsage(ctx context.Context, req *frontendv1.GetAPIKeyUsageRequest) (*frontendv1.GetAPIKeyUsageResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	apiKeyID, err := idformat.APIKey.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid api key id", fmt.Errorf("parse api key id: %w", err))
+	}
+
+	if _, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+		ID:             apiKeyID,
+		OrganizationID: authn.OrganizationID(ctx),
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
+		}
+
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+
+	startTime, endTime, granularity, err := parseAPIKeyUsagePeriod(req.StartTime, req.EndTime, req.Granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	qBuckets, err := q.ListAPIKeyUsageBuckets(ctx, queries.ListAPIKeyUsageBucketsParams{
+		Granularity: granularity,
+		ApiKeyID:    apiKeyID,
+		StartTime:   &startTime,
+		EndTime:     &endTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list api key usage buckets: %w", err)
+	}
+
+	var buckets []*frontendv1.APIKeyUsageBucket
+	for _, qBucket := range qBuckets {
+		buckets = append(buckets, &frontendv1.APIKeyUsageBucket{
+			StartTime:        timestamppb.New(*qBucket.StartTime),
+			RequestCount:     qBucket.RequestCount,
+			RateLimitedCount: qBucket.RateLimitedCount,
+		})
+	}
+
+	return &frontendv1.GetAPIKeyUsageResponse{
+		Buckets: buckets,
+	}, nil
+}
+
+// parseAPIKeyUsagePeriod applies the defaults of GetAPIKeyUsageRequest, and
+// returns the granularity as a date_trunc field.
+func parseAPIKeyUsagePeriod(start, end *timestamppb.Timestamp, granularity frontendv1.APIKeyUsageGranularity) (time.Time, time.Time, string, error) {
+	endTime := time.Now()
+	if end != nil {
+		endTime = end.AsTime()
+	}
+
+	startTime := endTime.Add(-24 * time.Hour)
+	if start != nil {
+		startTime = start.AsTime()
+	}
+
+	if !startTime.Before(endTime) {
+		return time.Time{}, time.Time{}, "", apierror.NewInvalidArgumentError("start_time must be before end_time", fmt.Errorf("start time not before end time"))
+	}
+
+	var field string
+	var width time.Duration
+	switch granularity {
+	case frontendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_MINUTE:
+		field, width = "minute", time.Minute
+	case frontendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_UNSPECIFIED, frontendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_HOUR:
+		field, width = "hour", time.Hour
+	case frontendv1.APIKeyUsageGranularity_API_KEY_USAGE_GRANULARITY_DAY:
+		field, width = "day", 24*time.Hour
+	default:
+		return time.Time{}, time.Time{}, "", apierror.NewInvalidArgumentError("invalid granularity", fmt.Errorf("invalid granularity: %v", granularity))
+	}
+
+	if endTime.Sub(startTime)/width > maxAPIKeyUsageBuckets {
+		return time.Time{}, time.Time{}, "", apierror.NewInvalidArgumentError(fmt.Sprintf("period must contain at most %d buckets", maxAPIKeyUsageBuckets), fmt.Errorf("too many buckets"))
+	}
+
+	return startTime, endTime, field, nil
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -71,13 +72,52 @@ func (s *Store) CreateAPIKey(ctx context.Context, req *frontendv1.CreateAPIKeyRe
 		expireTime = &formattedExpireTime
 	}
 
+	rateLimitPerMinute, err := parseAPIKeyRateLimitPerMinute(req.ApiKey.RateLimitPerMinute)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID *uuid.UUID
+	var userActions []string
+	if req.ApiKey.UserId != nil {
+		id, err := idformat.User.Parse(*req.ApiKey.UserId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
+		}
+
+		if id != authn.UserID(ctx) {
+			return nil, apierror.NewPermissionDeniedError("personal api keys can only be created for the current user", fmt.Errorf("user id is not the current user"))
+		}
+
+		qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("get user by id: %w", err)
+		}
+
+		userActions, err = s.getUserActions(ctx, q, qUser)
+		if err != nil {
+			return nil, err
+		}
+
+		userID = &qUser.ID
+	}
+
+	// a personal api key may only be limited to actions its owner has
+	actions, err := parsePersonalAPIKeyActions(userID, req.ApiKey.Actions, userActions)
+	if err != nil {
+		return nil, err
+	}
+
 	qAPIKey, err := q.CreateAPIKey(ctx, queries.CreateAPIKeyParams{
-		ID:                uuid.New(),
-		DisplayName:       req.ApiKey.DisplayName,
-		ExpireTime:        expireTime,
-		OrganizationID:    authn.OrganizationID(ctx),
-		SecretTokenSha256: secretTokenSha256[:],
-		SecretTokenSuffix: &secretTokenSuffix,
+		ID:                 uuid.New(),
+		DisplayName:        req.ApiKey.DisplayName,
+		ExpireTime:         expireTime,
+		OrganizationID:     authn.OrganizationID(ctx),
+		SecretTokenSha256:  secretTokenSha256[:],
+		SecretTokenSuffix:  &secretTokenSuffix,
+		RateLimitPerMinute: rateLimitPerMinute,
+		UserID:             userID,
+		Actions:            actions,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create api key: %w", err)
@@ -90,7 +130,7 @@ func (s *Store) CreateAPIKey(ctx context.Context, req *frontendv1.CreateAPIKeyRe
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.create",
 		EventDetails: &auditlogv1.CreateAPIKey{
 			ApiKey: auditAPIKey,
@@ -134,6 +174,10 @@ func (s *Store) DeleteAPIKey(ctx context.Context, req *frontendv1.DeleteAPIKeyRe
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
 
+	if err := s.validateCanManageAPIKey(ctx, qApiKey); err != nil {
+		return nil, err
+	}
+
 	if qApiKey.SecretTokenSha256 != nil {
 		return nil, apierror.NewFailedPreconditionError("api key must be revoked to be deleted", fmt.Errorf("api key mut be revoked to be deleted"))
 	}
@@ -150,7 +194,7 @@ func (s *Store) DeleteAPIKey(ctx context.Context, req *frontendv1.DeleteAPIKeyRe
 		return nil, fmt.Errorf("delete api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.delete",
 		EventDetails: &auditlogv1.DeleteAPIKey{
 			ApiKey: auditAPIKey,
@@ -205,6 +249,15 @@ func (s *Store) ListAPIKeys(ctx context.Context, req *frontendv1.ListAPIKeysRequ
 	}
 	defer rollback()
 
+	var userID *uuid.UUID
+	if req.UserId != "" {
+		id, err := idformat.User.Parse(req.UserId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
+		}
+		userID = (*uuid.UUID)(&id)
+	}
+
 	var startID uuid.UUID
 	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
 		return nil, err
@@ -215,6 +268,7 @@ func (s *Store) ListAPIKeys(ctx context.Context, req *frontendv1.ListAPIKeysRequ
 		ID:             startID,
 		OrganizationID: authn.OrganizationID(ctx),
 		Limit:          int32(limit + 1),
+		UserID:         userID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list api keys: %w", err)
@@ -260,6 +314,10 @@ func (s *Store) RevokeAPIKey(ctx context.Context, req *frontendv1.RevokeAPIKeyRe
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
 
+	if err := s.validateCanManageAPIKey(ctx, qAPIKey); err != nil {
+		return nil, err
+	}
+
 	auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qAPIKey.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit log api key: %w", err)
@@ -278,7 +336,7 @@ func (s *Store) RevokeAPIKey(ctx context.Context, req *frontendv1.RevokeAPIKeyRe
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.revoke",
 		EventDetails: &auditlogv1.RevokeAPIKey{
 			ApiKey:         auditAPIKey,
@@ -297,6 +355,112 @@ func (s *Store) RevokeAPIKey(ctx context.Context, req *frontendv1.RevokeAPIKeyRe
 	return &frontendv1.RevokeAPIKeyResponse{}, nil
 }
 
+func (s *Store) RotateAPIKey(ctx context.Context, req *frontendv1.RotateAPIKeyRequest) (*frontendv1.RotateAPIKeyResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	apiKeyID, err := idformat.APIKey.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid api key id", fmt.Errorf("parse api key id: %w", err))
+	}
+
+	qPreviousAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+		ID:             apiKeyID,
+		OrganizationID: authn.OrganizationID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
+		}
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+
+	if err := s.validateCanManageAPIKey(ctx, qPreviousAPIKey); err != nil {
+		return nil, err
+	}
+
+	if qPreviousAPIKey.SecretTokenSha256 == nil {
+		return nil, apierror.NewFailedPreconditionError("revoked api keys cannot be rotated", fmt.Errorf("api key is revoked"))
+	}
+
+	if qPreviousAPIKey.ExpireTime != nil && !qPreviousAPIKey.ExpireTime.After(time.Now()) {
+		return nil, apierror.NewFailedPreconditionError("expired api keys cannot be rotated", fmt.Errorf("api key is expired"))
+	}
+
+	var previousSecretTokenExpireTime *time.Time
+	if req.PreviousSecretTokenExpireTime != nil {
+		t := req.PreviousSecretTokenExpireTime.AsTime()
+		if !t.After(time.Now()) {
+			return nil, apierror.NewInvalidArgumentError("previous_secret_token_expire_time must be in the future", fmt.Errorf("previous secret token expire time not in the future"))
+		}
+		previousSecretTokenExpireTime = &t
+	}
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	if qProject.ApiKeySecretTokenPrefix == nil || *qProject.ApiKeySecretTokenPrefix == "" {
+		return nil, apierror.NewInvalidArgumentError("api key secret token prefix is required", fmt.Errorf("api key secret token prefix is required"))
+	}
+
+	var secretTokenValue [35]byte
+	if _, err := rand.Read(secretTokenValue[:]); err != nil {
+		return nil, fmt.Errorf("generate secret token: %w", err)
+	}
+
+	secretToken := prettysecret.Format(*qProject.ApiKeySecretTokenPrefix, secretTokenValue)
+	secretTokenSuffix := secretToken[len(secretToken)-apiKeySecretTokenSuffixLength:]
+	secretTokenSha256 := sha256.Sum256(secretTokenValue[:])
+
+	auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit log api key: %w", err)
+	}
+
+	qAPIKey, err := q.RotateAPIKey(ctx, queries.RotateAPIKeyParams{
+		ID:                            apiKeyID,
+		SecretTokenSha256:             secretTokenSha256[:],
+		SecretTokenSuffix:             &secretTokenSuffix,
+		OrganizationID:                authn.OrganizationID(ctx),
+		PreviousSecretTokenExpireTime: previousSecretTokenExpireTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	auditAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit log api key: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.api_keys.rotate",
+		EventDetails: &auditlogv1.RotateAPIKey{
+			ApiKey:         auditAPIKey,
+			PreviousApiKey: auditPreviousAPIKey,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeApiKey,
+		ResourceID:   &qAPIKey.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	apiKey := parseAPIKey(qAPIKey)
+	apiKey.SecretToken = secretToken
+	return &frontendv1.RotateAPIKeyResponse{
+		ApiKey: apiKey,
+	}, nil
+}
+
 func (s *Store) UpdateAPIKey(ctx context.Context, req *frontendv1.UpdateAPIKeyRequest) (*frontendv1.UpdateAPIKeyResponse, error) {
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -320,15 +484,28 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *frontendv1.UpdateAPIKeyRe
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
 
+	if err := s.validateCanManageAPIKey(ctx, qApiKey); err != nil {
+		return nil, err
+	}
+
 	auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qApiKey.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
+	rateLimitPerMinute := qApiKey.RateLimitPerMinute
+	if req.ApiKey.RateLimitPerMinute != nil {
+		rateLimitPerMinute, err = parseAPIKeyRateLimitPerMinute(req.ApiKey.RateLimitPerMinute)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	updatedApiKey, err := q.UpdateAPIKey(ctx, queries.UpdateAPIKeyParams{
-		ID:             apiKeyID,
-		DisplayName:    req.ApiKey.DisplayName,
-		OrganizationID: authn.OrganizationID(ctx),
+		ID:                 apiKeyID,
+		DisplayName:        req.ApiKey.DisplayName,
+		OrganizationID:     authn.OrganizationID(ctx),
+		RateLimitPerMinute: rateLimitPerMinute,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("update api key: %w", err)
@@ -339,7 +516,7 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *frontendv1.UpdateAPIKeyRe
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.api_keys.update",
 		EventDetails: &auditlogv1.UpdateAPIKey{
 			ApiKey:         auditAPIKey,
@@ -360,15 +537,104 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *frontendv1.UpdateAPIKeyRe
 	}, nil
 }
 
+// parseAPIKeyRateLimitPerMinute validates a requested rate limit. 0 means the
+// key is not rate limited.
+func parseAPIKeyRateLimitPerMinute(rateLimitPerMinute *int32) (*int32, error) {
+	if rateLimitPerMinute == nil || *rateLimitPerMinute == 0 {
+		return nil, nil
+	}
+
+	if *rateLimitPerMinute < 0 {
+		return nil, apierror.NewInvalidArgumentError("rate limit per minute must not be negative", fmt.Errorf("negative rate limit per minute: %d", *rateLimitPerMinute))
+	}
+
+	return rateLimitPerMinute, nil
+}
+
+// validateCanManageAPIKey returns an error if the current user may not modify
+// qAPIKey. A personal API key can only be modified by its owner, or by an
+// owner of the organization.
+func (s *Store) validateCanManageAPIKey(ctx context.Context, qAPIKey queries.ApiKey) error {
+	if qAPIKey.UserID == nil || *qAPIKey.UserID == authn.UserID(ctx) {
+		return nil
+	}
+
+	return s.validateIsOwner(ctx)
+}
+
+// getUserActions returns the actions qUser has. Owners have every action in
+// the project.
+func (s *Store) getUserActions(ctx context.Context, q *queries.Queries, qUser queries.User) ([]string, error) {
+	if !qUser.IsOwner {
+		userActions, err := q.GetUserActions(ctx, qUser.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get user actions: %w", err)
+		}
+		return userActions, nil
+	}
+
+	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project actions: %w", err)
+	}
+
+	var actions []string
+	for _, qAction := range qActions {
+		actions = append(actions, qAction.Name)
+	}
+	return actions, nil
+}
+
+// parsePersonalAPIKeyActions validates the actions requested for an API key
+// owned by userID. Only personal API keys may be limited to actions, and only
+// to ones in allowedActions.
+func parsePersonalAPIKeyActions(userID *uuid.UUID, actions []string, allowedActions []string) ([]string, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+
+	if userID == nil {
+		return nil, apierror.NewInvalidArgumentError("actions can only be set on personal api keys", fmt.Errorf("actions set on api key without user id"))
+	}
+
+	for _, action := range actions {
+		if !slices.Contains(allowedActions, action) {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("action not allowed: %s", action), fmt.Errorf("action not allowed: %s", action))
+		}
+	}
+
+	actions = slices.Clone(actions)
+	slices.Sort(actions)
+	return slices.Compact(actions), nil
+}
+
 func parseAPIKey(qAPIKey queries.ApiKey) *frontendv1.APIKey {
+	var userID *string
+	if qAPIKey.UserID != nil {
+		userID = refOrNil(idformat.User.Format(*qAPIKey.UserID))
+	}
+
 	return &frontendv1.APIKey{
-		Id:                idformat.APIKey.Format(qAPIKey.ID),
-		DisplayName:       qAPIKey.DisplayName,
-		CreateTime:        timestamppb.New(*qAPIKey.CreateTime),
-		UpdateTime:        timestamppb.New(*qAPIKey.UpdateTime),
-		ExpireTime:        timestampOrNil(qAPIKey.ExpireTime),
-		Revoked:           qAPIKey.SecretTokenSha256 == nil,
-		SecretToken:       "", // intentionally left blank
-		SecretTokenSuffix: derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		Id:                            idformat.APIKey.Format(qAPIKey.ID),
+		DisplayName:                   qAPIKey.DisplayName,
+		CreateTime:                    timestamppb.New(*qAPIKey.CreateTime),
+		UpdateTime:                    timestamppb.New(*qAPIKey.UpdateTime),
+		ExpireTime:                    timestampOrNil(qAPIKey.ExpireTime),
+		Revoked:                       qAPIKey.SecretTokenSha256 == nil,
+		SecretToken:                   "", // intentionally left blank
+		SecretTokenSuffix:             derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		RateLimitPerMinute:            qAPIKey.RateLimitPerMinute,
+		PreviousSecretTokenExpireTime: previousSecretTokenExpireTimeOrNil(qAPIKey),
+		UserId:                        userID,
+		Actions:                       qAPIKey.Actions,
+	}
+}
+
+// previousSecretTokenExpireTimeOrNil returns when the previous secret token of
+// qAPIKey expires, if qAPIKey is being rotated.
+func previousSecretTokenExpireTimeOrNil(qAPIKey queries.ApiKey) *timestamppb.Timestamp {
+	if qAPIKey.PreviousSecretTokenSha256 == nil || qAPIKey.PreviousSecretTokenExpireTime == nil || !qAPIKey.PreviousSecretTokenExpireTime.After(time.Now()) {
+		return nil
 	}
+	return timestamppb.New(*qAPIKey.PreviousSecretTokenExpireTime)
 }
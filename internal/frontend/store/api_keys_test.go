@@ -2,12 +2,16 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestCreateAPIKey_ApiKeysEnabled(t *testing.T) {
@@ -272,3 +276,149 @@ func TestListAPIKeys_Pagination(t *testing.T) {
 	}
 	require.ElementsMatch(t, createdIDs, allIDs)
 }
+
+func TestUpdateAPIKey_RateLimitPerMinute(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:    "Test Organization",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &frontendv1.CreateAPIKeyRequest{
+		ApiKey: &frontendv1.APIKey{
+			DisplayName:        "Test Key",
+			RateLimitPerMinute: refOrNil(int32(100)),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(100), createResp.ApiKey.GetRateLimitPerMinute())
+
+	// leaving the limit unset keeps it
+	updateResp, err := u.Store.UpdateAPIKey(ctx, &frontendv1.UpdateAPIKeyRequest{
+		Id:     createResp.ApiKey.Id,
+		ApiKey: &frontendv1.APIKey{DisplayName: "Renamed Key"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(100), updateResp.ApiKey.GetRateLimitPerMinute())
+
+	updateResp, err = u.Store.UpdateAPIKey(ctx, &frontendv1.UpdateAPIKeyRequest{
+		Id: createResp.ApiKey.Id,
+		ApiKey: &frontendv1.APIKey{
+			DisplayName:        "Renamed Key",
+			RateLimitPerMinute: refOrNil(int32(0)),
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, updateResp.ApiKey.RateLimitPerMinute)
+}
+
+func TestGetAPIKeyUsage(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:    "Test Organization",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &frontendv1.CreateAPIKeyRequest{
+		ApiKey: &frontendv1.APIKey{
+			DisplayName: "Test Key",
+		},
+	})
+	require.NoError(t, err)
+
+	usageResp, err := u.Store.GetAPIKeyUsage(ctx, &frontendv1.GetAPIKeyUsageRequest{Id: createResp.ApiKey.Id})
+	require.NoError(t, err)
+	require.Empty(t, usageResp.Buckets)
+
+	_, err = u.Store.GetAPIKeyUsage(ctx, &frontendv1.GetAPIKeyUsageRequest{Id: idformat.APIKey.Format(uuid.New())})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:    "Test Organization",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &frontendv1.CreateAPIKeyRequest{
+		ApiKey: &frontendv1.APIKey{
+			DisplayName: "Test Key",
+		},
+	})
+	require.NoError(t, err)
+
+	rotateResp, err := u.Store.RotateAPIKey(ctx, &frontendv1.RotateAPIKeyRequest{
+		Id:                            createResp.ApiKey.Id,
+		PreviousSecretTokenExpireTime: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, createResp.ApiKey.Id, rotateResp.ApiKey.Id)
+	require.NotEmpty(t, rotateResp.ApiKey.SecretToken)
+	require.NotEqual(t, createResp.ApiKey.SecretToken, rotateResp.ApiKey.SecretToken)
+	require.NotNil(t, rotateResp.ApiKey.PreviousSecretTokenExpireTime)
+
+	_, err = u.Store.RotateAPIKey(ctx, &frontendv1.RotateAPIKeyRequest{
+		Id:                            createResp.ApiKey.Id,
+		PreviousSecretTokenExpireTime: timestamppb.New(time.Now().Add(-time.Hour)),
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestCreateAPIKey_PersonalAPIKey(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:    "Test Organization",
+		ApiKeysEnabled: refOrNil(true),
+	})
+	userID := idformat.User.Format(authn.UserID(ctx))
+
+	_, err := u.Store.CreateAPIKey(ctx, &frontendv1.CreateAPIKeyRequest{
+		ApiKey: &frontendv1.APIKey{
+			DisplayName: "Organization Key",
+		},
+	})
+	require.NoError(t, err)
+
+	personalRes, err := u.Store.CreateAPIKey(ctx, &frontendv1.CreateAPIKeyRequest{
+		ApiKey: &frontendv1.APIKey{
+			DisplayName: "Personal Key",
+			UserId:      &userID,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, userID, personalRes.ApiKey.GetUserId())
+
+	listRes, err := u.Store.ListAPIKeys(ctx, &frontendv1.ListAPIKeysRequest{
+		UserId: userID,
+	})
+	require.NoError(t, err)
+	require.Len(t, listRes.ApiKeys, 1)
+	require.Equal(t, personalRes.ApiKey.Id, listRes.ApiKeys[0].Id)
+
+	// personal api keys cannot be created on behalf of other users
+	otherUserID := u.Environment.NewUser(t, idformat.Organization.Format(authn.OrganizationID(ctx)), &backendv1.User{
+		Email: "other@example.com",
+	})
+	_, err = u.Store.CreateAPIKey(ctx, &frontendv1.CreateAPIKeyRequest{
+		ApiKey: &frontendv1.APIKey{
+			DisplayName: "Other Key",
+			UserId:      &otherUserID,
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodePermissionDenied, connectErr.Code())
+}
@@ -41,11 +41,15 @@ func TestListAuditLogEvents(t *testing.T) {
 		eventDetails, err := structpb.NewStruct(eventDetailsMap)
 		require.NoError(t, err)
 
-		_, err = u.Store.logAuditEvent(ctx, u.Store.q, logAuditEventParams{
+		tx, err := u.Environment.DB.Begin(ctx)
+		require.NoError(t, err)
+
+		_, err = u.Store.logAuditEvent(ctx, tx, logAuditEventParams{
 			EventName:    fmt.Sprintf("custom.event.%d", i),
 			EventDetails: eventDetails,
 		})
 		require.NoError(t, err)
+		require.NoError(t, tx.Commit(ctx))
 	}
 
 	// Resource events
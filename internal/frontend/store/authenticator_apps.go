@@ -121,7 +121,7 @@ func (s *Store) RegisterAuthenticatorApp(ctx context.Context, req *frontendv1.Re
 		return nil, fmt.Errorf("get audit user: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.update",
 		EventDetails: &auditlogv1.UpdateUser{
 			User:         auditUser,
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
 	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
@@ -24,7 +26,9 @@ type logAuditEventParams struct {
 	ResourceID     *uuid.UUID
 }
 
-func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, req logAuditEventParams) (queries.AuditLogEvent, error) {
+func (s *Store) logAuditEvent(ctx context.Context, tx pgx.Tx, req logAuditEventParams) (queries.AuditLogEvent, error) {
+	q := queries.New(tx)
+
 	// Generate the UUIDv7 based on the event time.
 	eventTime := time.Now()
 	eventID := uuidv7.NewWithTime(eventTime)
@@ -52,6 +56,17 @@ func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, req logAu
 		return queries.AuditLogEvent{}, err
 	}
 
+	// Resource changes are also sent as typed webhook events.
+	if err := backgroundworkerstore.InsertTypedEventTx(ctx, tx, s.riverClient, backgroundworkerstore.NewTypedEventArgsParams{
+		ProjectID:         qEvent.ProjectID,
+		AuditLogEventID:   qEvent.ID,
+		AuditLogEventName: qEvent.EventName,
+		EventTime:         eventTime,
+		EventDetails:      req.EventDetails,
+	}); err != nil {
+		return queries.AuditLogEvent{}, fmt.Errorf("insert typed event: %w", err)
+	}
+
 	return qEvent, nil
 }
 
@@ -70,6 +85,8 @@ func parseSessionEventDetails(qSession queries.Session, impersonatorEmail *strin
 		primaryAuthFactor = frontendv1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_SAML
 	case queries.PrimaryAuthFactorOidc:
 		primaryAuthFactor = frontendv1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_OIDC
+	case queries.PrimaryAuthFactorPasskey:
+		primaryAuthFactor = frontendv1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_PASSKEY
 	default:
 		primaryAuthFactor = frontendv1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_UNSPECIFIED
 	}
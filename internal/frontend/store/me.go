@@ -42,7 +42,7 @@ func (s *Store) UpdateMe(ctx context.Context, req *frontendv1.UpdateMeRequest) (
 		return nil, fmt.Errorf("get audit user: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.update",
 		EventDetails: &auditlogv1.UpdateUser{
 			User:         auditUser,
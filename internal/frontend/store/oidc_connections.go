@@ -177,7 +177,7 @@ func (s *Store) CreateOIDCConnection(ctx context.Context, req *frontendv1.Create
 		return nil, fmt.Errorf("get audit oidc connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.oidc_connections.create",
 		EventDetails: &auditlogv1.CreateOIDCConnection{
 			OidcConnection: auditOIDCConnection,
@@ -290,7 +290,7 @@ func (s *Store) UpdateOIDCConnection(ctx context.Context, req *frontendv1.Update
 		return nil, fmt.Errorf("get audit oidc connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.oidc_connections.update",
 		EventDetails: &auditlogv1.UpdateOIDCConnection{
 			OidcConnection:         auditOIDCConnection,
@@ -347,7 +347,7 @@ func (s *Store) DeleteOIDCConnection(ctx context.Context, req *frontendv1.Delete
 		return nil, fmt.Errorf("delete oidc connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.oidc_connections.delete",
 		EventDetails: &auditlogv1.DeleteOIDCConnection{
 			OidcConnection: auditOIDCConnection,
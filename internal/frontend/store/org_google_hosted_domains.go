@@ -33,7 +33,7 @@ func (s *Store) UpdateOrganizationGoogleHostedDomains(ctx context.Context, req *
 		return nil, fmt.Errorf("validate is owner: %w", err)
 	}
 
-	_, q, commit, rollback, err := s.tx(ctx)
+	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +65,7 @@ func (s *Store) UpdateOrganizationGoogleHostedDomains(ctx context.Context, req *
 	}
 
 	googleHostedDomains := parseOrganizationGoogleHostedDomains(qGoogleHostedDomains)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.organizations.update_google_hosted_domains",
 		EventDetails: &auditlogv1.UpdateOrganizationGoogleHostedDomains{
 			GoogleHostedDomains:         googleHostedDomains.GoogleHostedDomains,
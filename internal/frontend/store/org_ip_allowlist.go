@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+)
+
+func (s *Store) GetOrganizationIPAllowlist(ctx context.Context, req *frontendv1.GetOrganizationIPAllowlistRequest) (*frontendv1.GetOrganizationIPAllowlistResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qCIDRs, err := q.GetOrganizationIPAllowlistCIDRs(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	return &frontendv1.GetOrganizationIPAllowlistResponse{
+		OrganizationIpAllowlist: parseOrganizationIPAllowlist(qCIDRs),
+	}, nil
+}
+
+func (s *Store) UpdateOrganizationIPAllowlist(ctx context.Context, req *frontendv1.UpdateOrganizationIPAllowlistRequest) (*frontendv1.UpdateOrganizationIPAllowlistResponse, error) {
+	if err := s.validateIsOwner(ctx); err != nil {
+		return nil, fmt.Errorf("validate is owner: %w", err)
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	// Get the current organization ip allowlist before deleting it to log the changes
+	qPreviousCIDRs, err := q.GetOrganizationIPAllowlistCIDRs(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	if err := q.DeleteOrganizationIPAllowlistCIDRs(ctx, authn.OrganizationID(ctx)); err != nil {
+		return nil, fmt.Errorf("delete organization ip allowlist cidrs: %w", err)
+	}
+
+	var prefixes []netip.Prefix
+	for _, cidr := range req.OrganizationIpAllowlist.Cidrs {
+		prefix, err := clientip.ParseCIDR(cidr)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid cidr: %q", cidr), fmt.Errorf("parse cidr: %w", err))
+		}
+
+		if slices.Contains(prefixes, prefix) {
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	// Don't let users lock themselves out of their own organization.
+	if !clientip.Allowed(clientip.FromContext(ctx), prefixes) {
+		return nil, apierror.NewFailedPreconditionError("ip allowlist must include your current ip address", fmt.Errorf("ip allowlist excludes client ip"))
+	}
+
+	for _, prefix := range prefixes {
+		if _, err := q.CreateOrganizationIPAllowlistCIDR(ctx, queries.CreateOrganizationIPAllowlistCIDRParams{
+			ID:             uuid.New(),
+			OrganizationID: authn.OrganizationID(ctx),
+			Cidr:           prefix,
+		}); err != nil {
+			return nil, fmt.Errorf("create organization ip allowlist cidr: %w", err)
+		}
+	}
+
+	qCIDRs, err := q.GetOrganizationIPAllowlistCIDRs(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	ipAllowlist := parseOrganizationIPAllowlist(qCIDRs)
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.organizations.update_ip_allowlist",
+		EventDetails: &auditlogv1.UpdateOrganizationIPAllowlist{
+			Cidrs:         ipAllowlist.Cidrs,
+			PreviousCidrs: parseOrganizationIPAllowlist(qPreviousCIDRs).Cidrs,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeOrganization,
+		ResourceID:   refOrNil(authn.OrganizationID(ctx)),
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.UpdateOrganizationIPAllowlistResponse{
+		OrganizationIpAllowlist: ipAllowlist,
+	}, nil
+}
+
+func parseOrganizationIPAllowlist(qCIDRs []queries.OrganizationIpAllowlistCidr) *frontendv1.OrganizationIPAllowlist {
+	var cidrs []string
+	for _, qCIDR := range qCIDRs {
+		cidrs = append(cidrs, qCIDR.Cidr.String())
+	}
+	return &frontendv1.OrganizationIPAllowlist{
+		Cidrs: cidrs,
+	}
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"net/netip"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+)
+
+func TestGetOrganizationIPAllowlist_Empty(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	resp, err := u.Store.GetOrganizationIPAllowlist(ctx, &frontendv1.GetOrganizationIPAllowlistRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.OrganizationIpAllowlist)
+	require.Empty(t, resp.OrganizationIpAllowlist.Cidrs)
+}
+
+func TestUpdateOrganizationIPAllowlist_AddAndGet(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	ctx = clientip.NewContext(ctx, netip.MustParseAddr("192.0.2.1"))
+
+	cidrs := []string{"192.0.2.0/24", "198.51.100.0/24"}
+	updateResp, err := u.Store.UpdateOrganizationIPAllowlist(ctx, &frontendv1.UpdateOrganizationIPAllowlistRequest{
+		OrganizationIpAllowlist: &frontendv1.OrganizationIPAllowlist{
+			Cidrs: cidrs,
+		},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, cidrs, updateResp.OrganizationIpAllowlist.Cidrs)
+
+	getResp, err := u.Store.GetOrganizationIPAllowlist(ctx, &frontendv1.GetOrganizationIPAllowlistRequest{})
+	require.NoError(t, err)
+	require.ElementsMatch(t, cidrs, getResp.OrganizationIpAllowlist.Cidrs)
+}
+
+func TestUpdateOrganizationIPAllowlist_ExcludesCurrentIP(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	ctx = clientip.NewContext(ctx, netip.MustParseAddr("203.0.113.1"))
+
+	_, err := u.Store.UpdateOrganizationIPAllowlist(ctx, &frontendv1.UpdateOrganizationIPAllowlistRequest{
+		OrganizationIpAllowlist: &frontendv1.OrganizationIPAllowlist{
+			Cidrs: []string{"192.0.2.0/24"},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+)
+
+func (s *Store) GetOrganizationPasskeyPolicy(ctx context.Context, req *frontendv1.GetOrganizationPasskeyPolicyRequest) (*frontendv1.GetOrganizationPasskeyPolicyResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qOrg, err := q.GetOrganizationByID(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization by id: %w", err)
+	}
+
+	qAAGUIDs, err := q.GetOrganizationPasskeyAAGUIDs(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization passkey aaguids: %w", err)
+	}
+
+	return &frontendv1.GetOrganizationPasskeyPolicyResponse{
+		OrganizationPasskeyPolicy: parseOrganizationPasskeyPolicy(qOrg, qAAGUIDs),
+	}, nil
+}
+
+func (s *Store) UpdateOrganizationPasskeyPolicy(ctx context.Context, req *frontendv1.UpdateOrganizationPasskeyPolicyRequest) (*frontendv1.UpdateOrganizationPasskeyPolicyResponse, error) {
+	if err := s.validateIsOwner(ctx); err != nil {
+		return nil, fmt.Errorf("validate is owner: %w", err)
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qPreviousOrg, err := q.GetOrganizationByID(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization by id: %w", err)
+	}
+
+	// Get the current organization passkey aaguids before deleting them to log the changes
+	qPreviousAAGUIDs, err := q.GetOrganizationPasskeyAAGUIDs(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization passkey aaguids: %w", err)
+	}
+
+	aaguids, err := validatePasskeyPolicyAAGUIDs(req.OrganizationPasskeyPolicy.AllowedAaguids, req.OrganizationPasskeyPolicy.DeniedAaguids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validatePasskeyPolicyAttestation(req.OrganizationPasskeyPolicy.RequireAttestation, req.OrganizationPasskeyPolicy.AllowedAaguids); err != nil {
+		return nil, err
+	}
+
+	qOrg, err := q.UpdateOrganizationPasskeyPolicy(ctx, queries.UpdateOrganizationPasskeyPolicyParams{
+		ID:                             authn.OrganizationID(ctx),
+		PasskeyRequireAttestation:      req.OrganizationPasskeyPolicy.RequireAttestation,
+		PasskeyRequireUserVerification: req.OrganizationPasskeyPolicy.RequireUserVerification,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update organization passkey policy: %w", err)
+	}
+
+	if err := q.DeleteOrganizationPasskeyAAGUIDs(ctx, authn.OrganizationID(ctx)); err != nil {
+		return nil, fmt.Errorf("delete organization passkey aaguids: %w", err)
+	}
+
+	for aaguid, allowed := range aaguids {
+		if _, err := q.CreateOrganizationPasskeyAAGUID(ctx, queries.CreateOrganizationPasskeyAAGUIDParams{
+			ID:             uuid.New(),
+			OrganizationID: authn.OrganizationID(ctx),
+			Aaguid:         aaguid,
+			Allowed:        allowed,
+		}); err != nil {
+			return nil, fmt.Errorf("create organization passkey aaguid: %w", err)
+		}
+	}
+
+	qAAGUIDs, err := q.GetOrganizationPasskeyAAGUIDs(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization passkey aaguids: %w", err)
+	}
+
+	passkeyPolicy := parseOrganizationPasskeyPolicy(qOrg, qAAGUIDs)
+	previousPasskeyPolicy := parseOrganizationPasskeyPolicy(qPreviousOrg, qPreviousAAGUIDs)
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.organizations.update_passkey_policy",
+		EventDetails: &auditlogv1.UpdateOrganizationPasskeyPolicy{
+			PasskeyPolicy: &auditlogv1.OrganizationPasskeyPolicy{
+				RequireAttestation:      passkeyPolicy.RequireAttestation,
+				AllowedAaguids:          passkeyPolicy.AllowedAaguids,
+				DeniedAaguids:           passkeyPolicy.DeniedAaguids,
+				RequireUserVerification: passkeyPolicy.RequireUserVerification,
+			},
+			PreviousPasskeyPolicy: &auditlogv1.OrganizationPasskeyPolicy{
+				RequireAttestation:      previousPasskeyPolicy.RequireAttestation,
+				AllowedAaguids:          previousPasskeyPolicy.AllowedAaguids,
+				DeniedAaguids:           previousPasskeyPolicy.DeniedAaguids,
+				RequireUserVerification: previousPasskeyPolicy.RequireUserVerification,
+			},
+		},
+		ResourceType: queries.AuditLogEventResourceTypeOrganization,
+		ResourceID:   refOrNil(authn.OrganizationID(ctx)),
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.UpdateOrganizationPasskeyPolicyResponse{
+		OrganizationPasskeyPolicy: passkeyPolicy,
+	}, nil
+}
+
+// validatePasskeyPolicyAAGUIDs parses allowed and denied AAGUIDs into a map
+// from AAGUID to whether it is allowed.
+func validatePasskeyPolicyAAGUIDs(allowed, denied []string) (map[uuid.UUID]bool, error) {
+	aaguids := map[uuid.UUID]bool{}
+	for _, s := range allowed {
+		aaguid, err := uuid.Parse(s)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid aaguid: %q", s), fmt.Errorf("parse aaguid: %w", err))
+		}
+		aaguids[aaguid] = true
+	}
+
+	for _, s := range denied {
+		aaguid, err := uuid.Parse(s)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid aaguid: %q", s), fmt.Errorf("parse aaguid: %w", err))
+		}
+
+		if aaguids[aaguid] {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("aaguid is both allowed and denied: %q", s), fmt.Errorf("aaguid both allowed and denied"))
+		}
+		aaguids[aaguid] = false
+	}
+
+	return aaguids, nil
+}
+
+// validatePasskeyPolicyAttestation rejects passkey policies that require
+// trusted attestations when no FIDO metadata is configured to trust
+// attestations against, because no passkey could then be registered.
+func (s *Store) validatePasskeyPolicyAttestation(requireAttestation bool, allowed []string) error {
+	if !requireAttestation && len(allowed) == 0 {
+		return nil
+	}
+
+	if s.fidoMetadata.Empty() {
+		return apierror.NewFailedPreconditionError("passkey attestation cannot be required, or aaguids allowed, because no fido metadata is configured", fmt.Errorf("fido metadata is empty"))
+	}
+
+	return nil
+}
+
+func parseOrganizationPasskeyPolicy(qOrg queries.Organization, qAAGUIDs []queries.OrganizationPasskeyAaguid) *frontendv1.OrganizationPasskeyPolicy {
+	var allowed, denied []string
+	for _, qAAGUID := range qAAGUIDs {
+		if qAAGUID.Allowed {
+			allowed = append(allowed, qAAGUID.Aaguid.String())
+		} else {
+			denied = append(denied, qAAGUID.Aaguid.String())
+		}
+	}
+	return &frontendv1.OrganizationPasskeyPolicy{
+		RequireAttestation:      qOrg.PasskeyRequireAttestation,
+		AllowedAaguids:          allowed,
+		DeniedAaguids:           denied,
+		RequireUserVerification: qOrg.PasskeyRequireUserVerification,
+	}
+}
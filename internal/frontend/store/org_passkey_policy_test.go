@@ -0,0 +1,71 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/webauthn"
+)
+
+func TestGetOrganizationPasskeyPolicy_Empty(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	resp, err := u.Store.GetOrganizationPasskeyPolicy(ctx, &frontendv1.GetOrganizationPasskeyPolicyRequest{})
+	require.NoError(t, err)
+	require.False(t, resp.OrganizationPasskeyPolicy.RequireAttestation)
+	require.Empty(t, resp.OrganizationPasskeyPolicy.AllowedAaguids)
+	require.Empty(t, resp.OrganizationPasskeyPolicy.DeniedAaguids)
+}
+
+func TestUpdateOrganizationPasskeyPolicy_AddAndGet(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	allowed := []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"}
+	updateResp, err := u.Store.UpdateOrganizationPasskeyPolicy(ctx, &frontendv1.UpdateOrganizationPasskeyPolicyRequest{
+		OrganizationPasskeyPolicy: &frontendv1.OrganizationPasskeyPolicy{
+			AllowedAaguids: allowed,
+		},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, allowed, updateResp.OrganizationPasskeyPolicy.AllowedAaguids)
+
+	getResp, err := u.Store.GetOrganizationPasskeyPolicy(ctx, &frontendv1.GetOrganizationPasskeyPolicyRequest{})
+	require.NoError(t, err)
+	require.ElementsMatch(t, allowed, getResp.OrganizationPasskeyPolicy.AllowedAaguids)
+	require.Empty(t, getResp.OrganizationPasskeyPolicy.DeniedAaguids)
+}
+
+func TestUpdateOrganizationPasskeyPolicy_AttestationWithoutFIDOMetadata(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	u.Store.fidoMetadata = &webauthn.Metadata{}
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	for _, policy := range []*frontendv1.OrganizationPasskeyPolicy{
+		{RequireAttestation: true},
+		{AllowedAaguids: []string{"cb69481e-8ff7-4039-93ec-0a2729a154a8"}},
+	} {
+		_, err := u.Store.UpdateOrganizationPasskeyPolicy(ctx, &frontendv1.UpdateOrganizationPasskeyPolicyRequest{
+			OrganizationPasskeyPolicy: policy,
+		})
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
-	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
@@ -151,6 +151,24 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *frontendv1.UpdateOr
 		updates.LogInWithPasskey = *req.Organization.LogInWithPasskey
 	}
 
+	updates.LogInWithPasswordlessPasskey = qOrg.LogInWithPasswordlessPasskey
+	if req.Organization.LogInWithPasswordlessPasskey != nil {
+		if *req.Organization.LogInWithPasswordlessPasskey && !qProject.LogInWithPasswordlessPasskey {
+			return nil, apierror.NewPermissionDeniedError("log in with passwordless passkey is not enabled for this project", fmt.Errorf("log in with passwordless passkey is not enabled for this project"))
+		}
+
+		if *req.Organization.LogInWithPasswordlessPasskey && !updates.LogInWithPasskey {
+			return nil, apierror.NewInvalidArgumentError("log in with passwordless passkey requires log in with passkey to be enabled", fmt.Errorf("log in with passwordless passkey requires log in with passkey to be enabled"))
+		}
+
+		updates.LogInWithPasswordlessPasskey = *req.Organization.LogInWithPasswordlessPasskey
+	}
+
+	// passwordless passkey login is turned off along with passkeys
+	if !updates.LogInWithPasskey {
+		updates.LogInWithPasswordlessPasskey = false
+	}
+
 	updates.RequireMfa = qOrg.RequireMfa
 	if req.Organization.RequireMfa != nil {
 		if *req.Organization.RequireMfa {
@@ -172,7 +190,7 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *frontendv1.UpdateOr
 		return nil, fmt.Errorf("get audit organization: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.organizations.update",
 		EventDetails: &auditlogv1.UpdateOrganization{
 			Organization:         auditOrganization,
@@ -200,45 +218,49 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *frontendv1.UpdateOr
 }
 
 func (s *Store) sendSyncOrganizationEvent(ctx context.Context, tx pgx.Tx, qOrg queries.Organization) error {
-	// Add the sync organization event to the background worker queue
-	jobInsertRes, err := s.riverClient.InsertTx(ctx, tx, webhookworker.Args{
-		ProjectID: idformat.Project.Format(authn.ProjectID(ctx)),
-		EventName: "sync.organization",
-		Payload: map[string]interface{}{
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	orgID := idformat.Organization.Format(qOrg.ID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: orgID,
+		EventType:   "sync.organization",
+		Payload: map[string]any{
 			"type":           "sync.organization",
-			"organizationId": idformat.Organization.Format(qOrg.ID),
+			"organizationId": orgID,
 		},
-	}, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("insert job: %w", err)
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
 	}
 
-	slog.InfoContext(ctx, "webhook_worker_job_inserted", "job_id", jobInsertRes.Job.ID, "event_type", "sync.organization", "organization_id", idformat.Organization.Format(qOrg.ID))
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.organization", "organization_id", orgID)
 
 	return nil
 }
 
 func parseOrganization(qProject queries.Project, qOrg queries.Organization) *frontendv1.Organization {
 	return &frontendv1.Organization{
-		Id:                        idformat.Organization.Format(qOrg.ID),
-		DisplayName:               qOrg.DisplayName,
-		CreateTime:                timestamppb.New(*qOrg.CreateTime),
-		UpdateTime:                timestamppb.New(*qOrg.UpdateTime),
-		LogInWithGoogle:           &qOrg.LogInWithGoogle,
-		LogInWithMicrosoft:        &qOrg.LogInWithMicrosoft,
-		LogInWithGithub:           &qOrg.LogInWithGithub,
-		LogInWithEmail:            &qOrg.LogInWithEmail,
-		LogInWithPassword:         &qOrg.LogInWithPassword,
-		LogInWithSaml:             &qOrg.LogInWithSaml,
-		LogInWithOidc:             &qOrg.LogInWithOidc,
-		LogInWithAuthenticatorApp: &qOrg.LogInWithAuthenticatorApp,
-		LogInWithPasskey:          &qOrg.LogInWithPasskey,
-		RequireMfa:                &qOrg.RequireMfa,
-		GoogleHostedDomains:       nil, // TODO
-		MicrosoftTenantIds:        nil, // TODO,
-		CustomRolesEnabled:        qOrg.CustomRolesEnabled,
-		ApiKeysEnabled:            qOrg.ApiKeysEnabled && qProject.ApiKeysEnabled && qProject.EntitledBackendApiKeys,
-		ScimEnabled:               qOrg.ScimEnabled,
+		Id:                           idformat.Organization.Format(qOrg.ID),
+		DisplayName:                  qOrg.DisplayName,
+		CreateTime:                   timestamppb.New(*qOrg.CreateTime),
+		UpdateTime:                   timestamppb.New(*qOrg.UpdateTime),
+		LogInWithGoogle:              &qOrg.LogInWithGoogle,
+		LogInWithMicrosoft:           &qOrg.LogInWithMicrosoft,
+		LogInWithGithub:              &qOrg.LogInWithGithub,
+		LogInWithEmail:               &qOrg.LogInWithEmail,
+		LogInWithPassword:            &qOrg.LogInWithPassword,
+		LogInWithSaml:                &qOrg.LogInWithSaml,
+		LogInWithOidc:                &qOrg.LogInWithOidc,
+		LogInWithAuthenticatorApp:    &qOrg.LogInWithAuthenticatorApp,
+		LogInWithPasskey:             &qOrg.LogInWithPasskey,
+		LogInWithPasswordlessPasskey: &qOrg.LogInWithPasswordlessPasskey,
+		RequireMfa:                   &qOrg.RequireMfa,
+		GoogleHostedDomains:          nil, // TODO
+		MicrosoftTenantIds:           nil, // TODO,
+		CustomRolesEnabled:           qOrg.CustomRolesEnabled,
+		ApiKeysEnabled:               qOrg.ApiKeysEnabled && qProject.ApiKeysEnabled && qProject.EntitledBackendApiKeys,
+		ScimEnabled:                  qOrg.ScimEnabled,
 	}
 }
 
@@ -91,7 +91,7 @@ func (s *Store) DeleteMyPasskey(ctx context.Context, req *frontendv1.DeleteMyPas
 		return nil, fmt.Errorf("delete passkey: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.passkeys.delete",
 		EventDetails: &auditlogv1.DeletePasskey{
 			Passkey: auditPasskey,
@@ -146,6 +146,11 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *frontendv1.RegisterPas
 		return nil, fmt.Errorf("get project by id: %w", err)
 	}
 
+	qOrg, err := q.GetOrganizationByID(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization by id: %w", err)
+	}
+
 	cred, err := webauthn.Parse(&webauthn.ParseRequest{
 		RPID:              qProject.CookieDomain,
 		AttestationObject: req.AttestationObject,
@@ -154,6 +159,10 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *frontendv1.RegisterPas
 		return nil, fmt.Errorf("parse webauthn credential: %w", err)
 	}
 
+	if err := s.enforceOrganizationPasskeyPolicy(ctx, q, qOrg, cred, req.ClientDataJson); err != nil {
+		return nil, fmt.Errorf("enforce organization passkey policy: %w", err)
+	}
+
 	publicKey, err := x509.MarshalPKIXPublicKey(cred.PublicKey)
 	if err != nil {
 		return nil, fmt.Errorf("marshal public key: %w", err)
@@ -176,7 +185,7 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *frontendv1.RegisterPas
 		return nil, fmt.Errorf("get audit passkey: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.passkeys.create",
 		EventDetails: &auditlogv1.CreatePasskey{
 			Passkey: auditPasskey,
@@ -196,6 +205,39 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *frontendv1.RegisterPas
 	}, nil
 }
 
+// enforceOrganizationPasskeyPolicy returns an error if the organization's
+// passkey policy does not permit cred.
+func (s *Store) enforceOrganizationPasskeyPolicy(ctx context.Context, q *queries.Queries, qOrg queries.Organization, cred *webauthn.Credential, clientDataJSON string) error {
+	qAAGUIDs, err := q.GetOrganizationPasskeyAAGUIDs(ctx, qOrg.ID)
+	if err != nil {
+		return fmt.Errorf("get organization passkey aaguids: %w", err)
+	}
+
+	passkeyPolicy := parseOrganizationPasskeyPolicy(qOrg, qAAGUIDs)
+	policy := webauthn.AAGUIDPolicy{
+		RequireAttestation: passkeyPolicy.RequireAttestation,
+		AllowedAAGUIDs:     passkeyPolicy.AllowedAaguids,
+		DeniedAAGUIDs:      passkeyPolicy.DeniedAaguids,
+	}
+	if !policy.Enforced() {
+		return nil
+	}
+
+	attestation, err := cred.VerifyAttestation(&webauthn.VerifyAttestationRequest{
+		ClientDataJSON: clientDataJSON,
+		Metadata:       s.fidoMetadata,
+	})
+	if err != nil {
+		return apierror.NewInvalidArgumentError("invalid passkey attestation", fmt.Errorf("verify attestation: %w", err))
+	}
+
+	if err := policy.Check(cred, attestation); err != nil {
+		return apierror.NewPermissionDeniedError("passkey authenticator is not allowed by organization policy", fmt.Errorf("check passkey policy: %w", err))
+	}
+
+	return nil
+}
+
 func parsePasskey(qPasskey queries.Passkey) *frontendv1.Passkey {
 	return &frontendv1.Passkey{
 		Id:           idformat.Passkey.Format(qPasskey.ID),
@@ -0,0 +1,206 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/passwordpolicy"
+)
+
+func (s *Store) GetOrganizationPasswordPolicy(ctx context.Context, req *frontendv1.GetOrganizationPasswordPolicyRequest) (*frontendv1.GetOrganizationPasswordPolicyResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qPasswordPolicy, err := q.GetOrganizationPasswordPolicy(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &frontendv1.GetOrganizationPasswordPolicyResponse{PasswordPolicy: &frontendv1.PasswordPolicy{}}, nil
+		}
+
+		return nil, fmt.Errorf("get organization password policy: %w", err)
+	}
+
+	return &frontendv1.GetOrganizationPasswordPolicyResponse{
+		PasswordPolicy: parsePasswordPolicy(qPasswordPolicy),
+	}, nil
+}
+
+func (s *Store) UpdateOrganizationPasswordPolicy(ctx context.Context, req *frontendv1.UpdateOrganizationPasswordPolicyRequest) (*frontendv1.UpdateOrganizationPasswordPolicyResponse, error) {
+	if err := s.validateIsOwner(ctx); err != nil {
+		return nil, fmt.Errorf("validate is owner: %w", err)
+	}
+
+	if req.PasswordPolicy == nil {
+		return nil, apierror.NewInvalidArgumentError("password_policy is required", fmt.Errorf("password policy is nil"))
+	}
+	if err := passwordPolicyFromProto(req.PasswordPolicy).Validate(); err != nil {
+		return nil, apierror.NewInvalidArgumentError(err.Error(), fmt.Errorf("validate password policy: %w", err))
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	previousPasswordPolicy := &frontendv1.PasswordPolicy{}
+	qPreviousPasswordPolicy, err := q.GetOrganizationPasswordPolicy(ctx, authn.OrganizationID(ctx))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get organization password policy: %w", err)
+	}
+	if err == nil {
+		previousPasswordPolicy = parsePasswordPolicy(qPreviousPasswordPolicy)
+	}
+
+	qPasswordPolicy, err := q.UpsertOrganizationPasswordPolicy(ctx, queries.UpsertOrganizationPasswordPolicyParams{
+		OrganizationID:         authn.OrganizationID(ctx),
+		MinLength:              req.PasswordPolicy.MinLength,
+		RequireLowercase:       req.PasswordPolicy.RequireLowercase,
+		RequireUppercase:       req.PasswordPolicy.RequireUppercase,
+		RequireDigit:           req.PasswordPolicy.RequireDigit,
+		RequireSymbol:          req.PasswordPolicy.RequireSymbol,
+		DisallowPersonalInfo:   req.PasswordPolicy.DisallowPersonalInfo,
+		HistoryCount:           req.PasswordPolicy.HistoryCount,
+		MaxAgeDays:             req.PasswordPolicy.MaxAgeDays,
+		LockoutAttempts:        req.PasswordPolicy.LockoutAttempts,
+		LockoutDurationSeconds: req.PasswordPolicy.LockoutDurationSeconds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("upsert organization password policy: %w", err)
+	}
+
+	passwordPolicy := parsePasswordPolicy(qPasswordPolicy)
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.organizations.update_password_policy",
+		EventDetails: &auditlogv1.UpdateOrganizationPasswordPolicy{
+			PasswordPolicy:         auditlogPasswordPolicy(passwordPolicy),
+			PreviousPasswordPolicy: auditlogPasswordPolicy(previousPasswordPolicy),
+		},
+		ResourceType: queries.AuditLogEventResourceTypeOrganization,
+		ResourceID:   refOrNil(authn.OrganizationID(ctx)),
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.UpdateOrganizationPasswordPolicyResponse{
+		PasswordPolicy: passwordPolicy,
+	}, nil
+}
+
+// getEffectivePasswordPolicy returns the stricter of a project's and an
+// organization's password policies.
+func getEffectivePasswordPolicy(ctx context.Context, q *queries.Queries, projectID, organizationID uuid.UUID) (passwordpolicy.Policy, error) {
+	var projectPolicy, organizationPolicy passwordpolicy.Policy
+
+	qProjectPasswordPolicy, err := q.GetProjectPasswordPolicy(ctx, projectID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return passwordpolicy.Policy{}, fmt.Errorf("get project password policy: %w", err)
+	}
+	if err == nil {
+		projectPolicy = passwordPolicyFromProto(parsePasswordPolicy(queries.OrganizationPasswordPolicy{
+			MinLength:              qProjectPasswordPolicy.MinLength,
+			RequireLowercase:       qProjectPasswordPolicy.RequireLowercase,
+			RequireUppercase:       qProjectPasswordPolicy.RequireUppercase,
+			RequireDigit:           qProjectPasswordPolicy.RequireDigit,
+			RequireSymbol:          qProjectPasswordPolicy.RequireSymbol,
+			DisallowPersonalInfo:   qProjectPasswordPolicy.DisallowPersonalInfo,
+			HistoryCount:           qProjectPasswordPolicy.HistoryCount,
+			MaxAgeDays:             qProjectPasswordPolicy.MaxAgeDays,
+			LockoutAttempts:        qProjectPasswordPolicy.LockoutAttempts,
+			LockoutDurationSeconds: qProjectPasswordPolicy.LockoutDurationSeconds,
+		}))
+	}
+
+	qOrganizationPasswordPolicy, err := q.GetOrganizationPasswordPolicy(ctx, organizationID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return passwordpolicy.Policy{}, fmt.Errorf("get organization password policy: %w", err)
+	}
+	if err == nil {
+		organizationPolicy = passwordPolicyFromProto(parsePasswordPolicy(qOrganizationPasswordPolicy))
+	}
+
+	return passwordpolicy.Stricter(projectPolicy, organizationPolicy), nil
+}
+
+func passwordPolicyFromProto(passwordPolicy *frontendv1.PasswordPolicy) passwordpolicy.Policy {
+	return passwordpolicy.Policy{
+		MinLength:            int(passwordPolicy.MinLength),
+		RequireLowercase:     passwordPolicy.RequireLowercase,
+		RequireUppercase:     passwordPolicy.RequireUppercase,
+		RequireDigit:         passwordPolicy.RequireDigit,
+		RequireSymbol:        passwordPolicy.RequireSymbol,
+		DisallowPersonalInfo: passwordPolicy.DisallowPersonalInfo,
+		HistoryCount:         int(passwordPolicy.HistoryCount),
+		MaxAge:               time.Duration(passwordPolicy.MaxAgeDays) * time.Hour * 24,
+		LockoutAttempts:      int(passwordPolicy.LockoutAttempts),
+		LockoutDuration:      time.Duration(passwordPolicy.LockoutDurationSeconds) * time.Second,
+	}
+}
+
+func parsePasswordPolicy(qPasswordPolicy queries.OrganizationPasswordPolicy) *frontendv1.PasswordPolicy {
+	return &frontendv1.PasswordPolicy{
+		MinLength:              qPasswordPolicy.MinLength,
+		RequireLowercase:       qPasswordPolicy.RequireLowercase,
+		RequireUppercase:       qPasswordPolicy.RequireUppercase,
+		RequireDigit:           qPasswordPolicy.RequireDigit,
+		RequireSymbol:          qPasswordPolicy.RequireSymbol,
+		DisallowPersonalInfo:   qPasswordPolicy.DisallowPersonalInfo,
+		HistoryCount:           qPasswordPolicy.HistoryCount,
+		MaxAgeDays:             qPasswordPolicy.MaxAgeDays,
+		LockoutAttempts:        qPasswordPolicy.LockoutAttempts,
+		LockoutDurationSeconds: qPasswordPolicy.LockoutDurationSeconds,
+	}
+}
+
+func auditlogPasswordPolicy(passwordPolicy *frontendv1.PasswordPolicy) *auditlogv1.PasswordPolicy {
+	return &auditlogv1.PasswordPolicy{
+		MinLength:              passwordPolicy.MinLength,
+		RequireLowercase:       passwordPolicy.RequireLowercase,
+		RequireUppercase:       passwordPolicy.RequireUppercase,
+		RequireDigit:           passwordPolicy.RequireDigit,
+		RequireSymbol:          passwordPolicy.RequireSymbol,
+		DisallowPersonalInfo:   passwordPolicy.DisallowPersonalInfo,
+		HistoryCount:           passwordPolicy.HistoryCount,
+		MaxAgeDays:             passwordPolicy.MaxAgeDays,
+		LockoutAttempts:        passwordPolicy.LockoutAttempts,
+		LockoutDurationSeconds: passwordPolicy.LockoutDurationSeconds,
+	}
+}
+
+// addUserPasswordHistory records a user's replaced password, so that password
+// policies can prevent its reuse. Passwords beyond the historyCount most recent
+// are forgotten, as no policy checks them.
+func addUserPasswordHistory(ctx context.Context, q *queries.Queries, userID uuid.UUID, passwordBcrypt string, historyCount int) error {
+	if _, err := q.CreateUserPasswordHistory(ctx, queries.CreateUserPasswordHistoryParams{
+		ID:             uuid.New(),
+		UserID:         userID,
+		PasswordBcrypt: passwordBcrypt,
+	}); err != nil {
+		return fmt.Errorf("create user password history: %w", err)
+	}
+
+	if err := q.DeleteUserPasswordHistoriesBeyondCount(ctx, queries.DeleteUserPasswordHistoriesBeyondCountParams{
+		UserID: userID,
+		Limit:  int32(historyCount),
+	}); err != nil {
+		return fmt.Errorf("delete user password histories beyond count: %w", err)
+	}
+
+	return nil
+}
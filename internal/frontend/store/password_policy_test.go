@@ -0,0 +1,45 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+)
+
+func TestGetOrganizationPasswordPolicy_Empty(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	resp, err := u.Store.GetOrganizationPasswordPolicy(ctx, &frontendv1.GetOrganizationPasswordPolicyRequest{})
+	require.NoError(t, err)
+	require.Zero(t, resp.PasswordPolicy.MinLength)
+}
+
+func TestUpdateOrganizationPasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	updateResp, err := u.Store.UpdateOrganizationPasswordPolicy(ctx, &frontendv1.UpdateOrganizationPasswordPolicyRequest{
+		PasswordPolicy: &frontendv1.PasswordPolicy{
+			MinLength:        10,
+			RequireUppercase: true,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(10), updateResp.PasswordPolicy.MinLength)
+
+	getResp, err := u.Store.GetOrganizationPasswordPolicy(ctx, &frontendv1.GetOrganizationPasswordPolicyRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(10), getResp.PasswordPolicy.MinLength)
+	require.True(t, getResp.PasswordPolicy.RequireUppercase)
+}
@@ -35,22 +35,23 @@ func (s *Store) GetProject(ctx context.Context, req *frontendv1.GetProjectReques
 
 func parseProject(qProject *queries.Project) *frontendv1.Project {
 	return &frontendv1.Project{
-		Id:                        idformat.Project.Format(qProject.ID),
-		CreateTime:                timestamppb.New(*qProject.CreateTime),
-		UpdateTime:                timestamppb.New(*qProject.UpdateTime),
-		DisplayName:               qProject.DisplayName,
-		LogInWithGoogle:           qProject.LogInWithGoogle,
-		LogInWithMicrosoft:        qProject.LogInWithMicrosoft,
-		LogInWithGithub:           qProject.LogInWithGithub,
-		LogInWithEmail:            qProject.LogInWithEmail,
-		LogInWithPassword:         qProject.LogInWithPassword,
-		LogInWithAuthenticatorApp: qProject.LogInWithAuthenticatorApp,
-		LogInWithPasskey:          qProject.LogInWithPasskey,
-		LogInWithSaml:             qProject.LogInWithSaml,
-		LogInWithOidc:             qProject.LogInWithOidc,
-		VaultDomain:               qProject.VaultDomain,
-		ApiKeysEnabled:            qProject.ApiKeysEnabled && qProject.EntitledBackendApiKeys,
-		ApiKeySecretTokenPrefix:   derefOrEmpty(qProject.ApiKeySecretTokenPrefix),
-		AuditLogsEnabled:          qProject.AuditLogsEnabled,
+		Id:                           idformat.Project.Format(qProject.ID),
+		CreateTime:                   timestamppb.New(*qProject.CreateTime),
+		UpdateTime:                   timestamppb.New(*qProject.UpdateTime),
+		DisplayName:                  qProject.DisplayName,
+		LogInWithGoogle:              qProject.LogInWithGoogle,
+		LogInWithMicrosoft:           qProject.LogInWithMicrosoft,
+		LogInWithGithub:              qProject.LogInWithGithub,
+		LogInWithEmail:               qProject.LogInWithEmail,
+		LogInWithPassword:            qProject.LogInWithPassword,
+		LogInWithAuthenticatorApp:    qProject.LogInWithAuthenticatorApp,
+		LogInWithPasskey:             qProject.LogInWithPasskey,
+		LogInWithPasswordlessPasskey: qProject.LogInWithPasswordlessPasskey,
+		LogInWithSaml:                qProject.LogInWithSaml,
+		LogInWithOidc:                qProject.LogInWithOidc,
+		VaultDomain:                  qProject.VaultDomain,
+		ApiKeysEnabled:               qProject.ApiKeysEnabled && qProject.EntitledBackendApiKeys,
+		ApiKeySecretTokenPrefix:      derefOrEmpty(qProject.ApiKeySecretTokenPrefix),
+		AuditLogsEnabled:             qProject.AuditLogsEnabled,
 	}
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	commonv1 "github.com/tesseral-labs/tesseral/internal/common/gen/tesseral/common/v1"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
@@ -21,7 +22,7 @@ import (
 )
 
 func (s *Store) CreateRefreshAuditLogEvent(ctx context.Context, accessToken string) error {
-	_, q, commit, rollback, err := s.tx(ctx)
+	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return fmt.Errorf("store: %w", err)
 	}
@@ -76,7 +77,7 @@ func (s *Store) CreateRefreshAuditLogEvent(ctx context.Context, accessToken stri
 	// from the session details. As such, we can't use the logAuditEvent() fuction
 	// directly, so we're calling the CreateAuditLogEvent query directly.
 	resourceType := queries.AuditLogEventResourceTypeSession
-	if _, err := q.CreateAuditLogEvent(ctx, queries.CreateAuditLogEventParams{
+	qEvent, err := q.CreateAuditLogEvent(ctx, queries.CreateAuditLogEventParams{
 		ID:             eventID,
 		ProjectID:      projectID,
 		OrganizationID: (*uuid.UUID)(&orgID),
@@ -87,10 +88,21 @@ func (s *Store) CreateRefreshAuditLogEvent(ctx context.Context, accessToken stri
 		EventName:      "tesseral.sessions.refresh",
 		EventTime:      &eventTime,
 		EventDetails:   eventDetailsBytes,
-	}); err != nil {
+	})
+	if err != nil {
 		return fmt.Errorf("log audit event: %w", err)
 	}
 
+	if err := backgroundworkerstore.InsertTypedEventTx(ctx, tx, s.riverClient, backgroundworkerstore.NewTypedEventArgsParams{
+		ProjectID:         qEvent.ProjectID,
+		AuditLogEventID:   qEvent.ID,
+		AuditLogEventName: qEvent.EventName,
+		EventTime:         eventTime,
+		EventDetails:      eventDetails,
+	}); err != nil {
+		return fmt.Errorf("insert typed event: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return fmt.Errorf("commit: %w", err)
 	}
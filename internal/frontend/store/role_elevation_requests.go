@@ -0,0 +1,386 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// maxRoleElevationDurationSeconds is the longest a User may request a Role
+// for.
+const maxRoleElevationDurationSeconds = 60 * 60 * 24 * 30
+
+func (s *Store) ListRoleElevationRequests(ctx context.Context, req *frontendv1.ListRoleElevationRequestsRequest) (*frontendv1.ListRoleElevationRequestsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
+	// owners review requests, so they see everyone's; other users only see
+	// their own
+	var userID *uuid.UUID
+	if !qUser.IsOwner {
+		userID = &qUser.ID
+	}
+
+	var status queries.NullRoleElevationRequestStatus
+	if req.Status != frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_UNSPECIFIED {
+		qStatus, err := enumRoleElevationRequestStatus(req.Status)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid status", fmt.Errorf("enum role elevation request status: %w", err))
+		}
+
+		status = queries.NullRoleElevationRequestStatus{RoleElevationRequestStatus: qStatus, Valid: true}
+	}
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qRoleElevationRequests, err := q.ListRoleElevationRequests(ctx, queries.ListRoleElevationRequestsParams{
+		OrganizationID: authn.OrganizationID(ctx),
+		UserID:         userID,
+		Status:         status,
+		ID:             startID,
+		PageLimit:      int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list role elevation requests: %w", err)
+	}
+
+	var roleElevationRequests []*frontendv1.RoleElevationRequest
+	for _, qRoleElevationRequest := range qRoleElevationRequests {
+		roleElevationRequests = append(roleElevationRequests, parseRoleElevationRequest(qRoleElevationRequest))
+	}
+
+	var nextPageToken string
+	if len(roleElevationRequests) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qRoleElevationRequests[limit].ID)
+		roleElevationRequests = roleElevationRequests[:limit]
+	}
+
+	return &frontendv1.ListRoleElevationRequestsResponse{
+		RoleElevationRequests: roleElevationRequests,
+		NextPageToken:         nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetRoleElevationRequest(ctx context.Context, req *frontendv1.GetRoleElevationRequestRequest) (*frontendv1.GetRoleElevationRequestResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qRoleElevationRequest, err := s.getRoleElevationRequest(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// authz
+	if qRoleElevationRequest.UserID != authn.UserID(ctx) {
+		qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("get user by id: %w", err)
+		}
+
+		if !qUser.IsOwner {
+			return nil, apierror.NewNotFoundError("role elevation request not found", fmt.Errorf("role elevation request belongs to another user"))
+		}
+	}
+
+	return &frontendv1.GetRoleElevationRequestResponse{RoleElevationRequest: parseRoleElevationRequest(*qRoleElevationRequest)}, nil
+}
+
+func (s *Store) CreateRoleElevationRequest(ctx context.Context, req *frontendv1.CreateRoleElevationRequestRequest) (*frontendv1.CreateRoleElevationRequestResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	roleID, err := idformat.Role.Parse(req.RoleElevationRequest.RoleId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid role id", fmt.Errorf("parse role id: %w", err))
+	}
+
+	if req.RoleElevationRequest.Reason == "" {
+		return nil, apierror.NewInvalidArgumentError("reason is required", fmt.Errorf("reason is required"))
+	}
+
+	if req.RoleElevationRequest.DurationSeconds <= 0 || req.RoleElevationRequest.DurationSeconds > maxRoleElevationDurationSeconds {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("duration_seconds must be between 1 and %d", maxRoleElevationDurationSeconds), fmt.Errorf("invalid duration seconds"))
+	}
+
+	// ensure role belongs to project/organization
+	orgID := authn.OrganizationID(ctx)
+	if _, err := q.GetRole(ctx, queries.GetRoleParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: &orgID,
+		ID:             roleID,
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("role not found", fmt.Errorf("get role: %w", err))
+		}
+		return nil, fmt.Errorf("get role: %w", err)
+	}
+
+	qRoleElevationRequest, err := q.CreateRoleElevationRequest(ctx, queries.CreateRoleElevationRequestParams{
+		ID:              uuid.New(),
+		UserID:          authn.UserID(ctx),
+		RoleID:          roleID,
+		Reason:          req.RoleElevationRequest.Reason,
+		DurationSeconds: req.RoleElevationRequest.DurationSeconds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create role elevation request: %w", err)
+	}
+
+	auditRoleElevationRequest, err := s.auditlogStore.GetRoleElevationRequest(ctx, tx, qRoleElevationRequest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit role elevation request: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.role_elevation_requests.create",
+		EventDetails: &auditlogv1.CreateRoleElevationRequest{
+			RoleElevationRequest: auditRoleElevationRequest,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeRoleElevationRequest,
+		ResourceID:   &qRoleElevationRequest.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.CreateRoleElevationRequestResponse{RoleElevationRequest: parseRoleElevationRequest(qRoleElevationRequest)}, nil
+}
+
+func (s *Store) ApproveRoleElevationRequest(ctx context.Context, req *frontendv1.ApproveRoleElevationRequestRequest) (*frontendv1.ApproveRoleElevationRequestResponse, error) {
+	if err := s.validateIsOwner(ctx); err != nil {
+		return nil, fmt.Errorf("validate is owner: %w", err)
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qRoleElevationRequest, err := s.reviewRoleElevationRequest(ctx, q, req.Id, queries.RoleElevationRequestStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	// the role is granted for the requested duration starting from approval,
+	// not from when it was requested
+	expireTime := time.Now().Add(time.Duration(qRoleElevationRequest.DurationSeconds) * time.Second)
+	if err := q.UpsertUserRoleAssignment(ctx, queries.UpsertUserRoleAssignmentParams{
+		ID:         uuid.New(),
+		RoleID:     qRoleElevationRequest.RoleID,
+		UserID:     qRoleElevationRequest.UserID,
+		ExpireTime: &expireTime,
+	}); err != nil {
+		return nil, fmt.Errorf("upsert user role assignment: %w", err)
+	}
+
+	qUserRoleAssignment, err := q.GetUserRoleAssignmentByUserAndRole(ctx, queries.GetUserRoleAssignmentByUserAndRoleParams{
+		UserID: qRoleElevationRequest.UserID,
+		RoleID: qRoleElevationRequest.RoleID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get user role assignment by user and role: %w", err)
+	}
+
+	auditRoleElevationRequest, err := s.auditlogStore.GetRoleElevationRequest(ctx, tx, qRoleElevationRequest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit role elevation request: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.role_elevation_requests.approve",
+		EventDetails: &auditlogv1.ApproveRoleElevationRequest{
+			RoleElevationRequest: auditRoleElevationRequest,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeRoleElevationRequest,
+		ResourceID:   &qRoleElevationRequest.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	auditUserRoleAssignment, err := s.auditlogStore.GetUserRoleAssignment(ctx, tx, qUserRoleAssignment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit user role assignment: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.users.assign_role",
+		EventDetails: &auditlogv1.AssignUserRole{
+			UserRoleAssignment: auditUserRoleAssignment,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeUser,
+		ResourceID:   &qUserRoleAssignment.UserID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	// expiry sends the same event when the role is unassigned again
+	if err := s.sendSyncUserRoleAssignmentsEvent(ctx, tx, qUserRoleAssignment.UserID); err != nil {
+		return nil, fmt.Errorf("send sync user role assignments event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.ApproveRoleElevationRequestResponse{
+		RoleElevationRequest: parseRoleElevationRequest(*qRoleElevationRequest),
+		UserRoleAssignment:   parseUserRoleAssignment(qUserRoleAssignment),
+	}, nil
+}
+
+func (s *Store) DenyRoleElevationRequest(ctx context.Context, req *frontendv1.DenyRoleElevationRequestRequest) (*frontendv1.DenyRoleElevationRequestResponse, error) {
+	if err := s.validateIsOwner(ctx); err != nil {
+		return nil, fmt.Errorf("validate is owner: %w", err)
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qRoleElevationRequest, err := s.reviewRoleElevationRequest(ctx, q, req.Id, queries.RoleElevationRequestStatusDenied)
+	if err != nil {
+		return nil, err
+	}
+
+	auditRoleElevationRequest, err := s.auditlogStore.GetRoleElevationRequest(ctx, tx, qRoleElevationRequest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit role elevation request: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.role_elevation_requests.deny",
+		EventDetails: &auditlogv1.DenyRoleElevationRequest{
+			RoleElevationRequest: auditRoleElevationRequest,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeRoleElevationRequest,
+		ResourceID:   &qRoleElevationRequest.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.DenyRoleElevationRequestResponse{RoleElevationRequest: parseRoleElevationRequest(*qRoleElevationRequest)}, nil
+}
+
+func (s *Store) getRoleElevationRequest(ctx context.Context, q *queries.Queries, id string) (*queries.RoleElevationRequest, error) {
+	roleElevationRequestID, err := idformat.RoleElevationRequest.Parse(id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid role elevation request id", fmt.Errorf("parse role elevation request id: %w", err))
+	}
+
+	qRoleElevationRequest, err := q.GetRoleElevationRequest(ctx, queries.GetRoleElevationRequestParams{
+		ID:             roleElevationRequestID,
+		OrganizationID: authn.OrganizationID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("role elevation request not found", fmt.Errorf("get role elevation request: %w", err))
+		}
+		return nil, fmt.Errorf("get role elevation request: %w", err)
+	}
+
+	return &qRoleElevationRequest, nil
+}
+
+// reviewRoleElevationRequest moves a pending request to status on behalf of
+// the current user. Requests can only be reviewed once.
+func (s *Store) reviewRoleElevationRequest(ctx context.Context, q *queries.Queries, id string, status queries.RoleElevationRequestStatus) (*queries.RoleElevationRequest, error) {
+	qRoleElevationRequest, err := s.getRoleElevationRequest(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := authn.UserID(ctx)
+	qReviewedRoleElevationRequest, err := q.ReviewRoleElevationRequest(ctx, queries.ReviewRoleElevationRequestParams{
+		ID:             qRoleElevationRequest.ID,
+		Status:         status,
+		ReviewerUserID: &userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewFailedPreconditionError("role elevation request has already been reviewed", fmt.Errorf("review role elevation request: %w", err))
+		}
+		return nil, fmt.Errorf("review role elevation request: %w", err)
+	}
+
+	return &qReviewedRoleElevationRequest, nil
+}
+
+func parseRoleElevationRequest(qRoleElevationRequest queries.RoleElevationRequest) *frontendv1.RoleElevationRequest {
+	var status frontendv1.RoleElevationRequestStatus
+	switch qRoleElevationRequest.Status {
+	case queries.RoleElevationRequestStatusPending:
+		status = frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_PENDING
+	case queries.RoleElevationRequestStatusApproved:
+		status = frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_APPROVED
+	case queries.RoleElevationRequestStatusDenied:
+		status = frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_DENIED
+	}
+
+	var reviewerUserID string
+	if qRoleElevationRequest.ReviewerUserID != nil {
+		reviewerUserID = idformat.User.Format(*qRoleElevationRequest.ReviewerUserID)
+	}
+
+	return &frontendv1.RoleElevationRequest{
+		Id:              idformat.RoleElevationRequest.Format(qRoleElevationRequest.ID),
+		UserId:          idformat.User.Format(qRoleElevationRequest.UserID),
+		RoleId:          idformat.Role.Format(qRoleElevationRequest.RoleID),
+		Reason:          qRoleElevationRequest.Reason,
+		DurationSeconds: qRoleElevationRequest.DurationSeconds,
+		Status:          status,
+		ReviewerUserId:  reviewerUserID,
+		ReviewTime:      timestampOrNil(qRoleElevationRequest.ReviewTime),
+		CreateTime:      timestampOrNil(qRoleElevationRequest.CreateTime),
+	}
+}
+
+func enumRoleElevationRequestStatus(status frontendv1.RoleElevationRequestStatus) (queries.RoleElevationRequestStatus, error) {
+	switch status {
+	case frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_PENDING:
+		return queries.RoleElevationRequestStatusPending, nil
+	case frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_APPROVED:
+		return queries.RoleElevationRequestStatusApproved, nil
+	case frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_DENIED:
+		return queries.RoleElevationRequestStatusDenied, nil
+	default:
+		return "", fmt.Errorf("unknown role elevation request status: %v", status)
+	}
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestRoleElevationRequests(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ownerCtx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:        "test",
+		CustomRolesEnabled: refOrNil(true),
+	})
+
+	orgID := idformat.Organization.Format(authn.OrganizationID(ownerCtx))
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "user1@example.com",
+	})
+	userCtx := authn.NewContext(t.Context(), authn.ContextData{
+		ProjectID:      u.ProjectID,
+		OrganizationID: orgID,
+		UserID:         userID,
+		SessionID:      idformat.Session.Format(uuid.New()),
+	})
+
+	roleResp, err := u.Store.CreateRole(ownerCtx, &frontendv1.CreateRoleRequest{
+		Role: &frontendv1.Role{
+			DisplayName: "admin",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateRoleElevationRequest(userCtx, &frontendv1.CreateRoleElevationRequestRequest{
+		RoleElevationRequest: &frontendv1.RoleElevationRequest{
+			RoleId: roleResp.Role.Id,
+			Reason: "incident",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	createResp, err := u.Store.CreateRoleElevationRequest(userCtx, &frontendv1.CreateRoleElevationRequestRequest{
+		RoleElevationRequest: &frontendv1.RoleElevationRequest{
+			RoleId:          roleResp.Role.Id,
+			Reason:          "incident",
+			DurationSeconds: 3600,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, userID, createResp.RoleElevationRequest.UserId)
+	require.Equal(t, frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_PENDING, createResp.RoleElevationRequest.Status)
+
+	// only owners may review requests
+	_, err = u.Store.ApproveRoleElevationRequest(userCtx, &frontendv1.ApproveRoleElevationRequestRequest{
+		Id: createResp.RoleElevationRequest.Id,
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodePermissionDenied, connectErr.Code())
+
+	listResp, err := u.Store.ListRoleElevationRequests(ownerCtx, &frontendv1.ListRoleElevationRequestsRequest{
+		Status: frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_PENDING,
+	})
+	require.NoError(t, err)
+	require.Len(t, listResp.RoleElevationRequests, 1)
+
+	approveResp, err := u.Store.ApproveRoleElevationRequest(ownerCtx, &frontendv1.ApproveRoleElevationRequestRequest{
+		Id: createResp.RoleElevationRequest.Id,
+	})
+	require.NoError(t, err)
+	require.Equal(t, frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_APPROVED, approveResp.RoleElevationRequest.Status)
+	require.Equal(t, userID, approveResp.UserRoleAssignment.UserId)
+	require.Equal(t, roleResp.Role.Id, approveResp.UserRoleAssignment.RoleId)
+	require.WithinDuration(t, time.Now().Add(time.Hour), approveResp.UserRoleAssignment.ExpireTime.AsTime(), time.Minute)
+
+	// consumers of sync events see the assignment, as they later see its expiry
+	var syncEvents int
+	err = u.Environment.DB.QueryRow(t.Context(), `
+SELECT count(*) FROM webhook_outbox_events WHERE resource_key = $1 AND event_type = 'sync.user_role_assignments';
+`, userID).Scan(&syncEvents)
+	require.NoError(t, err)
+	require.Equal(t, 1, syncEvents)
+
+	// requests can only be reviewed once
+	_, err = u.Store.DenyRoleElevationRequest(ownerCtx, &frontendv1.DenyRoleElevationRequestRequest{
+		Id: createResp.RoleElevationRequest.Id,
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	secondResp, err := u.Store.CreateRoleElevationRequest(userCtx, &frontendv1.CreateRoleElevationRequestRequest{
+		RoleElevationRequest: &frontendv1.RoleElevationRequest{
+			RoleId:          roleResp.Role.Id,
+			Reason:          "another incident",
+			DurationSeconds: 600,
+		},
+	})
+	require.NoError(t, err)
+
+	denyResp, err := u.Store.DenyRoleElevationRequest(ownerCtx, &frontendv1.DenyRoleElevationRequestRequest{
+		Id: secondResp.RoleElevationRequest.Id,
+	})
+	require.NoError(t, err)
+	require.Equal(t, frontendv1.RoleElevationRequestStatus_ROLE_ELEVATION_REQUEST_STATUS_DENIED, denyResp.RoleElevationRequest.Status)
+
+	userListResp, err := u.Store.ListRoleElevationRequests(userCtx, &frontendv1.ListRoleElevationRequestsRequest{})
+	require.NoError(t, err)
+	require.Len(t, userListResp.RoleElevationRequests, 2)
+}
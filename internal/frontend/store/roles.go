@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -51,6 +54,11 @@ func (s *Store) ListRoles(ctx context.Context, req *frontendv1.ListRolesRequest)
 		return nil, fmt.Errorf("batch get role actions by role ids: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, qRoleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role ids: %w", err)
+	}
+
 	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
@@ -58,7 +66,7 @@ func (s *Store) ListRoles(ctx context.Context, req *frontendv1.ListRolesRequest)
 
 	var roles []*frontendv1.Role
 	for _, qRole := range qRoles {
-		roles = append(roles, parseRole(qRole, qRoleActions, qActions))
+		roles = append(roles, parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions))
 	}
 
 	var nextPageToken string
@@ -105,12 +113,17 @@ func (s *Store) GetRole(ctx context.Context, req *frontendv1.GetRoleRequest) (*f
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
 	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
-	return &frontendv1.GetRoleResponse{Role: parseRole(qRole, qRoleActions, qActions)}, nil
+	return &frontendv1.GetRoleResponse{Role: parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions)}, nil
 }
 
 func (s *Store) CreateRole(ctx context.Context, req *frontendv1.CreateRoleRequest) (*frontendv1.CreateRoleResponse, error) {
@@ -138,34 +151,29 @@ func (s *Store) CreateRole(ctx context.Context, req *frontendv1.CreateRoleReques
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
-	var qActionIDs []uuid.UUID
-	for _, action := range req.Role.Actions {
-		var ok bool
-		for _, qAction := range qActions {
-			if qAction.Name == action {
-				qActionIDs = append(qActionIDs, qAction.ID)
-				ok = true
-				break
-			}
-		}
-		if !ok {
-			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action %q", action), fmt.Errorf("action %q not found", action))
-		}
+	qActionIDs, actionWildcards, err := parseRoleActions(req.Role.Actions, qActions)
+	if err != nil {
+		return nil, err
 	}
 
 	orgID := authn.OrganizationID(ctx)
 
 	qRole, err := q.CreateRole(ctx, queries.CreateRoleParams{
-		ID:             uuid.New(),
-		ProjectID:      authn.ProjectID(ctx),
-		OrganizationID: &orgID,
-		DisplayName:    req.Role.DisplayName,
-		Description:    req.Role.Description,
+		ID:              uuid.New(),
+		ProjectID:       authn.ProjectID(ctx),
+		OrganizationID:  &orgID,
+		DisplayName:     req.Role.DisplayName,
+		Description:     req.Role.Description,
+		ActionWildcards: actionWildcards,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create role: %w", err)
 	}
 
+	if err := s.setRoleInheritedRoles(ctx, q, qRole, req.Role.InheritedRoleIds); err != nil {
+		return nil, err
+	}
+
 	for _, actionID := range qActionIDs {
 		if err := q.UpsertRoleAction(ctx, queries.UpsertRoleActionParams{
 			ID:       uuid.New(),
@@ -181,12 +189,17 @@ func (s *Store) CreateRole(ctx context.Context, req *frontendv1.CreateRoleReques
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
 	auditRole, err := s.auditlogStore.GetRole(ctx, tx, qRole.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit role: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.roles.create",
 		EventDetails: &auditlogv1.CreateRole{
 			Role: auditRole,
@@ -201,7 +214,7 @@ func (s *Store) CreateRole(ctx context.Context, req *frontendv1.CreateRoleReques
 		return nil, fmt.Errorf("commit: %w", err)
 	}
 
-	return &frontendv1.CreateRoleResponse{Role: parseRole(qRole, qRoleActions, qActions)}, nil
+	return &frontendv1.CreateRoleResponse{Role: parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions)}, nil
 }
 
 func (s *Store) UpdateRole(ctx context.Context, req *frontendv1.UpdateRoleRequest) (*frontendv1.UpdateRoleResponse, error) {
@@ -265,23 +278,15 @@ func (s *Store) UpdateRole(ctx context.Context, req *frontendv1.UpdateRoleReques
 		updates.Description = req.Role.Description
 	}
 
+	updates.ActionWildcards = qRole.ActionWildcards
 	if req.Role.Actions != nil {
-		var qActionIDs []uuid.UUID
-		for _, action := range req.Role.Actions {
-			var ok bool
-			for _, qAction := range qActions {
-				if qAction.Name == action {
-					qActionIDs = append(qActionIDs, qAction.ID)
-					ok = true
-					break
-				}
-			}
-
-			if !ok {
-				return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action %q", action), fmt.Errorf("action %q not found", action))
-			}
+		qActionIDs, actionWildcards, err := parseRoleActions(req.Role.Actions, qActions)
+		if err != nil {
+			return nil, err
 		}
 
+		updates.ActionWildcards = actionWildcards
+
 		for _, qActionID := range qActionIDs {
 			if err := q.UpsertRoleAction(ctx, queries.UpsertRoleActionParams{
 				ID:       uuid.New(),
@@ -300,6 +305,12 @@ func (s *Store) UpdateRole(ctx context.Context, req *frontendv1.UpdateRoleReques
 		}
 	}
 
+	if req.Role.InheritedRoleIds != nil {
+		if err := s.setRoleInheritedRoles(ctx, q, qRole, req.Role.InheritedRoleIds); err != nil {
+			return nil, err
+		}
+	}
+
 	qUpdatedRole, err := q.UpdateRole(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update role: %w", err)
@@ -310,13 +321,18 @@ func (s *Store) UpdateRole(ctx context.Context, req *frontendv1.UpdateRoleReques
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
+	qUpdatedRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qUpdatedRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
 	auditRole, err := s.auditlogStore.GetRole(ctx, tx, qUpdatedRole.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit role: %w", err)
 	}
 
-	role := parseRole(qUpdatedRole, qUpdatedRoleActions, qActions)
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	role := parseRole(qUpdatedRole, qUpdatedRoleActions, qUpdatedRoleInheritedRoles, qActions)
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.roles.update",
 		EventDetails: &auditlogv1.UpdateRole{
 			Role:         auditRole,
@@ -374,7 +390,7 @@ func (s *Store) DeleteRole(ctx context.Context, req *frontendv1.DeleteRoleReques
 		return nil, fmt.Errorf("delete role: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.roles.delete",
 		EventDetails: &auditlogv1.DeleteRole{
 			Role: auditRole,
@@ -392,7 +408,100 @@ func (s *Store) DeleteRole(ctx context.Context, req *frontendv1.DeleteRoleReques
 	return &frontendv1.DeleteRoleResponse{}, nil
 }
 
-func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qActions []queries.Action) *frontendv1.Role {
+// actionWildcardPattern matches the wildcards a role may grant actions with:
+// "*", "x.*" or "x.y.*".
+var actionWildcardPattern = regexp.MustCompile(`^([a-z0-9_]+\.){0,2}\*$`)
+
+// parseRoleActions splits the actions requested for a role into the IDs of the
+// qActions they name and wildcards.
+func parseRoleActions(actions []string, qActions []queries.Action) ([]uuid.UUID, []string, error) {
+	var qActionIDs []uuid.UUID
+	actionWildcards := []string{}
+	for _, action := range actions {
+		if strings.HasSuffix(action, "*") {
+			if !actionWildcardPattern.MatchString(action) {
+				return nil, nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action wildcard %q", action), fmt.Errorf("invalid action wildcard %q", action))
+			}
+
+			if !slices.Contains(actionWildcards, action) {
+				actionWildcards = append(actionWildcards, action)
+			}
+			continue
+		}
+
+		var ok bool
+		for _, qAction := range qActions {
+			if qAction.Name == action {
+				qActionIDs = append(qActionIDs, qAction.ID)
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action %q", action), fmt.Errorf("action %q not found", action))
+		}
+	}
+
+	return qActionIDs, actionWildcards, nil
+}
+
+// setRoleInheritedRoles replaces the roles qRole inherits with the roles
+// identified by inheritedRoleIDs. Only project-level roles and roles in the
+// current organization can be inherited.
+func (s *Store) setRoleInheritedRoles(ctx context.Context, q *queries.Queries, qRole queries.Role, inheritedRoleIDs []string) error {
+	orgID := authn.OrganizationID(ctx)
+
+	var qInheritedRoleIDs []uuid.UUID
+	for _, inheritedRoleID := range inheritedRoleIDs {
+		roleID, err := idformat.Role.Parse(inheritedRoleID)
+		if err != nil {
+			return apierror.NewInvalidArgumentError("invalid inherited role id", fmt.Errorf("parse inherited role id: %w", err))
+		}
+
+		qInheritedRole, err := q.GetRole(ctx, queries.GetRoleParams{
+			ProjectID:      authn.ProjectID(ctx),
+			OrganizationID: &orgID,
+			ID:             roleID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return apierror.NewNotFoundError("inherited role not found", fmt.Errorf("get inherited role: %w", err))
+			}
+			return fmt.Errorf("get inherited role: %w", err)
+		}
+
+		if !slices.Contains(qInheritedRoleIDs, qInheritedRole.ID) {
+			qInheritedRoleIDs = append(qInheritedRoleIDs, qInheritedRole.ID)
+		}
+	}
+
+	qTransitivelyInheritedRoleIDs, err := q.ListTransitivelyInheritedRoleIDs(ctx, qInheritedRoleIDs)
+	if err != nil {
+		return fmt.Errorf("list transitively inherited role ids: %w", err)
+	}
+
+	if slices.Contains(qTransitivelyInheritedRoleIDs, qRole.ID) {
+		return apierror.NewInvalidArgumentError("a role cannot inherit itself, directly or indirectly", fmt.Errorf("role inheritance cycle"))
+	}
+
+	if err := q.DeleteRoleInheritedRoles(ctx, qRole.ID); err != nil {
+		return fmt.Errorf("delete role inherited roles: %w", err)
+	}
+
+	for _, qInheritedRoleID := range qInheritedRoleIDs {
+		if err := q.CreateRoleInheritedRole(ctx, queries.CreateRoleInheritedRoleParams{
+			ID:              uuid.New(),
+			RoleID:          qRole.ID,
+			InheritedRoleID: qInheritedRoleID,
+		}); err != nil {
+			return fmt.Errorf("create role inherited role: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qRoleInheritedRoles []queries.RoleInheritedRole, qActions []queries.Action) *frontendv1.Role {
 	var orgID string
 	if qRole.OrganizationID != nil {
 		orgID = idformat.Organization.Format(*qRole.OrganizationID)
@@ -412,13 +521,24 @@ func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qActions [
 		}
 	}
 
+	actions = append(actions, qRole.ActionWildcards...)
+
+	var inheritedRoleIDs []string
+	for _, qRoleInheritedRole := range qRoleInheritedRoles {
+		if qRoleInheritedRole.RoleID == qRole.ID {
+			inheritedRoleIDs = append(inheritedRoleIDs, idformat.Role.Format(qRoleInheritedRole.InheritedRoleID))
+		}
+	}
+
 	return &frontendv1.Role{
-		Id:             idformat.Role.Format(qRole.ID),
-		OrganizationId: orgID,
-		CreateTime:     timestamppb.New(*qRole.CreateTime),
-		UpdateTime:     timestamppb.New(*qRole.UpdateTime),
-		DisplayName:    qRole.DisplayName,
-		Description:    qRole.Description,
-		Actions:        actions,
+		Id:               idformat.Role.Format(qRole.ID),
+		OrganizationId:   orgID,
+		CreateTime:       timestamppb.New(*qRole.CreateTime),
+		UpdateTime:       timestamppb.New(*qRole.UpdateTime),
+		DisplayName:      qRole.DisplayName,
+		Description:      qRole.Description,
+		Actions:          actions,
+		InheritedRoleIds: inheritedRoleIDs,
+		DefaultRole:      qRole.DefaultRole,
 	}
 }
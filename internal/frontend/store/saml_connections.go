@@ -178,7 +178,7 @@ func (s *Store) CreateSAMLConnection(ctx context.Context, req *frontendv1.Create
 		return nil, fmt.Errorf("get audit saml connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.saml_connections.create",
 		EventDetails: &auditlogv1.CreateSAMLConnection{
 			SamlConnection: auditSAMLConnection,
@@ -297,7 +297,7 @@ func (s *Store) UpdateSAMLConnection(ctx context.Context, req *frontendv1.Update
 		return nil, fmt.Errorf("get audit saml connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.saml_connections.update",
 		EventDetails: &auditlogv1.UpdateSAMLConnection{
 			SamlConnection:         auditSAMLConnection,
@@ -354,7 +354,7 @@ func (s *Store) DeleteSAMLConnection(ctx context.Context, req *frontendv1.Delete
 		return nil, fmt.Errorf("delete saml connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.saml_connections.delete",
 		EventDetails: &auditlogv1.DeleteSAMLConnection{
 			SamlConnection: auditSAMLConnection,
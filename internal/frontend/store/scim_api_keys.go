@@ -125,7 +125,7 @@ func (s *Store) CreateSCIMAPIKey(ctx context.Context, req *frontendv1.CreateSCIM
 		return nil, fmt.Errorf("get audit scim api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.scim_api_keys.create",
 		EventDetails: &auditlogv1.CreateSCIMAPIKey{
 			ScimApiKey: auditSCIMAPIKey,
@@ -198,7 +198,7 @@ func (s *Store) UpdateSCIMAPIKey(ctx context.Context, req *frontendv1.UpdateSCIM
 		return nil, fmt.Errorf("get audit scim api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.scim_api_keys.update",
 		EventDetails: &auditlogv1.UpdateSCIMAPIKey{
 			ScimApiKey:         auditSCIMAPIKey,
@@ -259,7 +259,7 @@ func (s *Store) DeleteSCIMAPIKey(ctx context.Context, req *frontendv1.DeleteSCIM
 		return nil, fmt.Errorf("delete scim api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.scim_api_keys.delete",
 		EventDetails: &auditlogv1.DeleteSCIMAPIKey{
 			ScimApiKey: auditSCIMAPIKey,
@@ -321,7 +321,7 @@ func (s *Store) RevokeSCIMAPIKey(ctx context.Context, req *frontendv1.RevokeSCIM
 		return nil, fmt.Errorf("get audit scim api key: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.scim_api_keys.revoke",
 		EventDetails: &auditlogv1.RevokeSCIMAPIKey{
 			ScimApiKey:         auditSCIMAPIKey,
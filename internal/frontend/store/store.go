@@ -18,6 +18,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/kms"
 	"github.com/tesseral-labs/tesseral/internal/oidcclient"
 	"github.com/tesseral-labs/tesseral/internal/pagetoken"
+	"github.com/tesseral-labs/tesseral/internal/webauthn"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -34,6 +35,7 @@ type Store struct {
 	svixClient                 *svix.Svix
 	auditlogStore              *auditlogstore.Store
 	oidc                       *oidcclient.Client
+	fidoMetadata               *webauthn.Metadata
 	riverClient                *river.Client[pgx.Tx]
 }
 
@@ -48,6 +50,7 @@ type NewStoreParams struct {
 	SvixClient                 *svix.Svix
 	AuditlogStore              *auditlogstore.Store
 	OIDCClient                 *oidcclient.Client
+	FIDOMetadata               *webauthn.Metadata
 	RiverClient                *river.Client[pgx.Tx]
 }
 
@@ -67,6 +70,7 @@ func New(p NewStoreParams) *Store {
 		svixClient:                 p.SvixClient,
 		auditlogStore:              p.AuditlogStore,
 		oidc:                       p.OIDCClient,
+		fidoMetadata:               p.FIDOMetadata,
 		riverClient:                p.RiverClient,
 	}
 
@@ -42,6 +42,7 @@ func newTestUtil(t *testing.T) *testUtil {
 		OIDCClientSecretsKMS:       environment.KMS.OIDCClientSecretsKMS,
 		AuthenticatorAppSecretsKMS: environment.KMS.AuthenticatorAppSecretsKMS,
 		OIDCClient:                 &oidcclient.Client{HTTPClient: http.DefaultClient},
+		FIDOMetadata:               storetesting.NewFIDOMetadata("cb69481e-8ff7-4039-93ec-0a2729a154a8", "ee882879-721c-4913-9775-3dfcce97072a"),
 	})
 	commonStore := commonstore.New(commonstore.NewStoreParams{
 		AppAuthRootDomain:     environment.ConsoleDomain,
@@ -161,7 +161,7 @@ func (s *Store) CreateUserInvite(ctx context.Context, req *frontendv1.CreateUser
 		return nil, fmt.Errorf("get audit log user invite: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.user_invites.create",
 		EventDetails: &auditlogv1.CreateUserInvite{
 			UserInvite: auditUserInvite,
@@ -230,7 +230,7 @@ func (s *Store) DeleteUserInvite(ctx context.Context, req *frontendv1.DeleteUser
 		return nil, fmt.Errorf("delete user invite: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.user_invites.delete",
 		EventDetails: &auditlogv1.DeleteUserInvite{
 			UserInvite: auditUserInvite,
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
@@ -188,6 +191,15 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *frontendv1.Cr
 		return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
 	}
 
+	var expireTime *time.Time
+	if req.UserRoleAssignment.ExpireTime != nil {
+		t := req.UserRoleAssignment.ExpireTime.AsTime()
+		if !t.After(time.Now()) {
+			return nil, apierror.NewInvalidArgumentError("expire_time must be in the future", fmt.Errorf("expire time is not in the future"))
+		}
+		expireTime = &t
+	}
+
 	// ensure both role and user belong to project/organization
 	orgID := authn.OrganizationID(ctx)
 	if _, err := q.GetRole(ctx, queries.GetRoleParams{
@@ -212,9 +224,10 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *frontendv1.Cr
 	}
 
 	if err := q.UpsertUserRoleAssignment(ctx, queries.UpsertUserRoleAssignmentParams{
-		ID:     uuid.New(),
-		RoleID: roleID,
-		UserID: userID,
+		ID:         uuid.New(),
+		RoleID:     roleID,
+		UserID:     userID,
+		ExpireTime: expireTime,
 	}); err != nil {
 		return nil, fmt.Errorf("upsert user role assignment: %w", err)
 	}
@@ -232,7 +245,7 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *frontendv1.Cr
 		return nil, fmt.Errorf("get audit user role assignment: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.assign_role",
 		EventDetails: &auditlogv1.AssignUserRole{
 			UserRoleAssignment: auditUserRoleAssignment,
@@ -288,7 +301,7 @@ func (s *Store) DeleteUserRoleAssignment(ctx context.Context, req *frontendv1.De
 		return nil, fmt.Errorf("delete user role assignment: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.unassign_role",
 		EventDetails: &auditlogv1.UnassignUserRole{
 			UserRoleAssignment: auditUserRoleAssignment,
@@ -308,8 +321,31 @@ func (s *Store) DeleteUserRoleAssignment(ctx context.Context, req *frontendv1.De
 
 func parseUserRoleAssignment(qUserRoleAssignment queries.UserRoleAssignment) *frontendv1.UserRoleAssignment {
 	return &frontendv1.UserRoleAssignment{
-		Id:     idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
-		RoleId: idformat.Role.Format(qUserRoleAssignment.RoleID),
-		UserId: idformat.User.Format(qUserRoleAssignment.UserID),
+		Id:         idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
+		RoleId:     idformat.Role.Format(qUserRoleAssignment.RoleID),
+		UserId:     idformat.User.Format(qUserRoleAssignment.UserID),
+		ExpireTime: timestampOrNil(qUserRoleAssignment.ExpireTime),
 	}
 }
+
+func (s *Store) sendSyncUserRoleAssignmentsEvent(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	formattedUserID := idformat.User.Format(userID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: formattedUserID,
+		EventType:   "sync.user_role_assignments",
+		Payload: map[string]any{
+			"type":   "sync.user_role_assignments",
+			"userId": formattedUserID,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
+	}
+
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.user_role_assignments", "user_id", formattedUserID)
+
+	return nil
+}
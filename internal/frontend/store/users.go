@@ -9,12 +9,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
-	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/bcryptcost"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
 	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/passwordpolicy"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -36,11 +37,56 @@ func (s *Store) SetUserPassword(ctx context.Context, req *frontendv1.SetPassword
 	}
 	defer rollback()
 
+	qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
+	qOrg, err := q.GetOrganizationByID(ctx, authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get organization by id: %w", err)
+	}
+
+	policy, err := getEffectivePasswordPolicy(ctx, q, authn.ProjectID(ctx), authn.OrganizationID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get effective password policy: %w", err)
+	}
+
+	// the user's current password is not in their history, so check it first
+	var previousPasswordBcrypts []string
+	if qUser.PasswordBcrypt != nil {
+		previousPasswordBcrypts = append(previousPasswordBcrypts, *qUser.PasswordBcrypt)
+	}
+
+	qPasswordHistoryBcrypts, err := q.ListUserPasswordHistoryBcrypts(ctx, queries.ListUserPasswordHistoryBcryptsParams{
+		UserID: qUser.ID,
+		Limit:  int32(policy.HistoryCount),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list user password history bcrypts: %w", err)
+	}
+	previousPasswordBcrypts = append(previousPasswordBcrypts, qPasswordHistoryBcrypts...)
+
+	if err := policy.Check(&passwordpolicy.CheckRequest{
+		Password:                req.Password,
+		Email:                   qUser.Email,
+		OrganizationDisplayName: qOrg.DisplayName,
+		PreviousPasswordBcrypts: previousPasswordBcrypts,
+	}); err != nil {
+		return nil, apierror.NewPasswordPolicyViolationError(err.Error(), fmt.Errorf("check password policy: %w", err))
+	}
+
 	passwordBcryptBytes, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcryptcost.Cost)
 	if err != nil {
 		return nil, apierror.NewFailedPreconditionError("could not generate password hash", fmt.Errorf("generate bcrypt hash: %w", err))
 	}
 
+	if qUser.PasswordBcrypt != nil {
+		if err := addUserPasswordHistory(ctx, q, qUser.ID, *qUser.PasswordBcrypt, policy.HistoryCount); err != nil {
+			return nil, fmt.Errorf("add user password history: %w", err)
+		}
+	}
+
 	passwordBcrypt := string(passwordBcryptBytes)
 	if _, err = q.SetPassword(ctx, queries.SetPasswordParams{
 		ID:             authn.UserID(ctx),
@@ -181,7 +227,7 @@ func (s *Store) UpdateUser(ctx context.Context, req *frontendv1.UpdateUserReques
 		return nil, fmt.Errorf("get audit user: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.update",
 		EventDetails: &auditlogv1.UpdateUser{
 			User:         auditUser,
@@ -243,11 +289,15 @@ func (s *Store) DeleteUser(ctx context.Context, req *frontendv1.DeleteUserReques
 		return nil, fmt.Errorf("get audit user: %w", err)
 	}
 
+	if err := s.revokeUserAPIKeys(ctx, tx, q, qUser); err != nil {
+		return nil, err
+	}
+
 	if err := q.DeleteUser(ctx, userID); err != nil {
 		return nil, fmt.Errorf("delete user: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.delete",
 		EventDetails: &auditlogv1.DeleteUser{
 			User: auditUser,
@@ -271,20 +321,23 @@ func (s *Store) DeleteUser(ctx context.Context, req *frontendv1.DeleteUserReques
 }
 
 func (s *Store) sendSyncUserEvent(ctx context.Context, tx pgx.Tx, qUser queries.User) error {
-	// Add the sync organization event to the background worker queue
-	jobInsertRes, err := s.riverClient.InsertTx(ctx, tx, webhookworker.Args{
-		ProjectID: idformat.Project.Format(authn.ProjectID(ctx)),
-		EventName: "sync.user",
-		Payload: map[string]interface{}{
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	userID := idformat.User.Format(qUser.ID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: userID,
+		EventType:   "sync.user",
+		Payload: map[string]any{
 			"type":   "sync.user",
-			"userId": idformat.User.Format(qUser.ID),
+			"userId": userID,
 		},
-	}, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("insert job: %w", err)
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
 	}
 
-	slog.InfoContext(ctx, "webhook_worker_job_inserted", "job_id", jobInsertRes.Job.ID, "event_type", "sync.user", "user_id", idformat.User.Format(qUser.ID))
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.user", "user_id", userID)
 
 	return nil
 }
@@ -304,3 +357,48 @@ func parseUser(qUser queries.User) *frontendv1.User {
 		ProfilePictureUrl:   qUser.ProfilePictureUrl,
 	}
 }
+
+// revokeUserAPIKeys revokes the personal API keys of qUser, logging an audit
+// event for each. It is called before deleting a user: deletion cascades to
+// the keys, but would leave no record of them being revoked.
+func (s *Store) revokeUserAPIKeys(ctx context.Context, tx pgx.Tx, q *queries.Queries, qUser queries.User) error {
+	apiKeyIDs, err := q.ListUnrevokedUserAPIKeyIDs(ctx, &qUser.ID)
+	if err != nil {
+		return fmt.Errorf("list unrevoked user api key ids: %w", err)
+	}
+
+	var auditPreviousAPIKeys []*auditlogv1.APIKey
+	for _, apiKeyID := range apiKeyIDs {
+		auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+		if err != nil {
+			return fmt.Errorf("get audit log api key: %w", err)
+		}
+		auditPreviousAPIKeys = append(auditPreviousAPIKeys, auditPreviousAPIKey)
+	}
+
+	if err := q.RevokeUserAPIKeys(ctx, &qUser.ID); err != nil {
+		return fmt.Errorf("revoke user api keys: %w", err)
+	}
+
+	for i, apiKeyID := range apiKeyIDs {
+		auditAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+		if err != nil {
+			return fmt.Errorf("get audit log api key: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+			EventName: "tesseral.api_keys.revoke",
+			EventDetails: &auditlogv1.RevokeAPIKey{
+				ApiKey:         auditAPIKey,
+				PreviousApiKey: auditPreviousAPIKeys[i],
+			},
+			OrganizationID: &qUser.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeApiKey,
+			ResourceID:     &apiKeyID,
+		}); err != nil {
+			return fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	return nil
+}
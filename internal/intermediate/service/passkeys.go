@@ -39,3 +39,19 @@ func (s *Service) VerifyPasskey(ctx context.Context, req *connect.Request[interm
 	}
 	return connect.NewResponse(res), nil
 }
+
+func (s *Service) IssuePasskeyLoginChallenge(ctx context.Context, req *connect.Request[intermediatev1.IssuePasskeyLoginChallengeRequest]) (*connect.Response[intermediatev1.IssuePasskeyLoginChallengeResponse], error) {
+	res, err := s.Store.IssuePasskeyLoginChallenge(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) VerifyPasskeyLogin(ctx context.Context, req *connect.Request[intermediatev1.VerifyPasskeyLoginRequest]) (*connect.Response[intermediatev1.VerifyPasskeyLoginResponse], error) {
+	res, err := s.Store.VerifyPasskeyLogin(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
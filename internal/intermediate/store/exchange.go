@@ -11,7 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
-	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
@@ -65,6 +65,10 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 		return nil, fmt.Errorf("enforce organization login enabled: %w", err)
 	}
 
+	if err := s.enforceOrganizationIPAllowlist(ctx, q, qOrg); err != nil {
+		return nil, fmt.Errorf("enforce organization ip allowlist: %w", err)
+	}
+
 	if err := s.validateAuthRequirementsSatisfied(ctx, q, qIntermediateSession.ID); err != nil {
 		return nil, fmt.Errorf("validate auth requirements satisfied: %w", err)
 	}
@@ -106,7 +110,7 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 			return nil, fmt.Errorf("get audit user: %w", err)
 		}
 
-		if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 			EventName: "tesseral.users.create",
 			EventDetails: &auditlogv1.CreateUser{
 				User: auditUser,
@@ -136,6 +140,19 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 				return nil, fmt.Errorf("get audit user: %w", err)
 			}
 
+			// keep the replaced password, so that password policies can
+			// prevent its reuse
+			if qIntermediateSession.NewUserPasswordBcrypt != nil && qUser.PasswordBcrypt != nil {
+				policy, err := getEffectivePasswordPolicy(ctx, q, qOrg.ProjectID, qOrg.ID)
+				if err != nil {
+					return nil, fmt.Errorf("get effective password policy: %w", err)
+				}
+
+				if err := addUserPasswordHistory(ctx, q, qUser.ID, *qUser.PasswordBcrypt, policy.HistoryCount); err != nil {
+					return nil, fmt.Errorf("add user password history: %w", err)
+				}
+			}
+
 			qUpdatedUser, err := q.UpdateUserDetails(ctx, queries.UpdateUserDetailsParams{
 				ID:                qUser.ID,
 				GithubUserID:      qIntermediateSession.GithubUserID,
@@ -154,7 +171,7 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 				return nil, fmt.Errorf("get audit user: %w", err)
 			}
 
-			if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+			if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 				EventName: "tesseral.users.update",
 				EventDetails: &auditlogv1.UpdateUser{
 					User:         auditUser,
@@ -274,7 +291,7 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 		oidcConnectionID = refOrNil(idformat.OIDCConnection.Format(*qIntermediateSession.VerifiedOidcConnectionID))
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.sessions.create",
 		EventDetails: &auditlogv1.CreateSession{
 			Session:          auditSession,
@@ -295,6 +312,12 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 		}
 	}
 
+	if newUser {
+		if err := s.assignDefaultRoles(ctx, tx, q, *qUser); err != nil {
+			return nil, fmt.Errorf("assign default roles: %w", err)
+		}
+	}
+
 	if err := commit(); err != nil {
 		return nil, err
 	}
@@ -310,20 +333,23 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 }
 
 func (s *Store) sendSyncUserEvent(ctx context.Context, tx pgx.Tx, qUser queries.User) error {
-	// Add the sync organization event to the background worker queue
-	jobInsertRes, err := s.riverClient.InsertTx(ctx, tx, webhookworker.Args{
-		ProjectID: idformat.Project.Format(authn.ProjectID(ctx)),
-		EventName: "sync.user",
-		Payload: map[string]interface{}{
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	userID := idformat.User.Format(qUser.ID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: userID,
+		EventType:   "sync.user",
+		Payload: map[string]any{
 			"type":   "sync.user",
-			"userId": idformat.User.Format(qUser.ID),
+			"userId": userID,
 		},
-	}, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("insert job: %w", err)
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
 	}
 
-	slog.InfoContext(ctx, "webhook_worker_job_inserted", "job_id", jobInsertRes.Job.ID, "event_type", "sync.user", "user_id", idformat.User.Format(qUser.ID))
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.user", "user_id", userID)
 
 	return nil
 }
@@ -371,11 +397,19 @@ func validateAuthRequirementsSatisfiedInner(qIntermediateSession queries.Interme
 		*qIntermediateSession.PrimaryAuthFactor == queries.PrimaryAuthFactorSaml ||
 			*qIntermediateSession.PrimaryAuthFactor == queries.PrimaryAuthFactorOidc
 
-	if !isEnterpriseLogin {
+	// a passkey login is already multi-factor: possession of the passkey, plus
+	// the user verification required by VerifyPasskeyLogin
+	isPasskeyLogin := *qIntermediateSession.PrimaryAuthFactor == queries.PrimaryAuthFactorPasskey
+
+	if !isEnterpriseLogin && !isPasskeyLogin {
 		if qOrg.LogInWithPassword && !qIntermediateSession.PasswordVerified {
 			return apierror.NewFailedPreconditionError("password not verified", nil)
 		}
 
+		if qIntermediateSession.PasswordExpired && qIntermediateSession.NewUserPasswordBcrypt == nil {
+			return apierror.NewFailedPreconditionError("password expired", nil)
+		}
+
 		if qOrg.RequireMfa {
 			hasPasskey := qOrg.LogInWithPasskey && qIntermediateSession.PasskeyVerified
 			hasAuthenticatorApp := qOrg.LogInWithAuthenticatorApp && qIntermediateSession.AuthenticatorAppVerified
@@ -433,6 +467,13 @@ func validateAuthRequirementsSatisfiedInner(qIntermediateSession queries.Interme
 		if qOrg.LogInWithOidc {
 			return nil
 		}
+	case queries.PrimaryAuthFactorPasskey:
+		if qIntermediateSession.VerifiedPasskeyID == nil {
+			return apierror.NewFailedPreconditionError("passkey not verified", nil)
+		}
+		if qOrg.LogInWithPasswordlessPasskey && qIntermediateSession.PasskeyVerified {
+			return nil
+		}
 	}
 
 	return apierror.NewFailedPreconditionError("no authentication method satisfied", nil)
@@ -4,11 +4,14 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
 )
 
 func TestStore_validateAuthRequirementsSatisfiedInner(t *testing.T) {
+	passkeyID := uuid.New()
+
 	testCases := []struct {
 		name                 string
 		qIntermediateSession queries.IntermediateSession
@@ -110,6 +113,37 @@ func TestStore_validateAuthRequirementsSatisfiedInner(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "password expired",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorEmail),
+				PasswordVerified:  true,
+				PasswordExpired:   true,
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithEmail:    true,
+				LogInWithPassword: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "password expired new password registered",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor:     primaryAuthFactor(queries.PrimaryAuthFactorEmail),
+				PasswordVerified:      true,
+				PasswordExpired:       true,
+				NewUserPasswordBcrypt: aws.String("bcrypt"),
+				Email:                 aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithEmail:    true,
+				LogInWithPassword: true,
+			},
+			wantErr: false,
+		},
 		{
 			name: "password email not verified",
 			qIntermediateSession: queries.IntermediateSession{
@@ -205,6 +239,95 @@ func TestStore_validateAuthRequirementsSatisfiedInner(t *testing.T) {
 			},
 			wantErr: true,
 		},
+
+		{
+			name: "passkey happy path",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorPasskey),
+				VerifiedPasskeyID: &passkeyID,
+				PasskeyVerified:   true,
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithPasskey:             true,
+				LogInWithPasswordlessPasskey: true,
+				LogInWithPassword:            true,
+				RequireMfa:                   true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "passwordless passkey not enabled",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorPasskey),
+				VerifiedPasskeyID: &passkeyID,
+				PasskeyVerified:   true,
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithPasskey: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "passkey missing verified passkey id",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorPasskey),
+				PasskeyVerified:   true,
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithPasskey:             true,
+				LogInWithPasswordlessPasskey: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "passkey not enabled",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorPasskey),
+				VerifiedPasskeyID: &passkeyID,
+				PasskeyVerified:   true,
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithEmail: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "passkey not verified",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorPasskey),
+				VerifiedPasskeyID: &passkeyID,
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithPasskey:             true,
+				LogInWithPasswordlessPasskey: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "passkey email not verified",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorPasskey),
+				VerifiedPasskeyID: &passkeyID,
+				PasskeyVerified:   true,
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: false,
+			qOrg: queries.Organization{
+				LogInWithPasskey:             true,
+				LogInWithPasswordlessPasskey: true,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range testCases {
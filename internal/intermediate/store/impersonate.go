@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
@@ -74,7 +76,7 @@ func (s *Store) RedeemUserImpersonationToken(ctx context.Context, req *intermedi
 		return nil, fmt.Errorf("get audit session: %w", err)
 	}
 
-	if err := logImpersonateAuditEvent(ctx, q, logAuditEventParams{
+	if err := s.logImpersonateAuditEvent(ctx, tx, logAuditEventParams{
 		EventName:      "tesseral.sessions.create",
 		EventDetails:   &auditlogv1.CreateSession{Session: auditSession},
 		OrganizationID: &qImpersonatedUser.OrganizationID,
@@ -94,7 +96,7 @@ func (s *Store) RedeemUserImpersonationToken(ctx context.Context, req *intermedi
 	}, nil
 }
 
-func logImpersonateAuditEvent(ctx context.Context, q *queries.Queries, data logAuditEventParams) error {
+func (s *Store) logImpersonateAuditEvent(ctx context.Context, tx pgx.Tx, data logAuditEventParams) error {
 	// Generate the UUIDv7 based on the event time.
 	eventTime := time.Now()
 	eventID := uuidv7.NewWithTime(eventTime)
@@ -115,6 +117,20 @@ func logImpersonateAuditEvent(ctx context.Context, q *queries.Queries, data logA
 		EventDetails:   eventDetailsBytes,
 	}
 
-	_, err = q.CreateAuditLogEvent(ctx, qEventParams)
-	return err
+	qEvent, err := queries.New(tx).CreateAuditLogEvent(ctx, qEventParams)
+	if err != nil {
+		return err
+	}
+
+	if err := backgroundworkerstore.InsertTypedEventTx(ctx, tx, s.riverClient, backgroundworkerstore.NewTypedEventArgsParams{
+		ProjectID:         qEvent.ProjectID,
+		AuditLogEventID:   qEvent.ID,
+		AuditLogEventName: qEvent.EventName,
+		EventTime:         eventTime,
+		EventDetails:      data.EventDetails,
+	}); err != nil {
+		return fmt.Errorf("insert typed event: %w", err)
+	}
+
+	return nil
 }
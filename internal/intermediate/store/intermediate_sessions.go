@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/tesseral-labs/tesseral/internal/clientip"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
@@ -126,6 +127,24 @@ func (s *Store) getIntermediateSessionEmailVerified(ctx context.Context, q *quer
 		return true, nil
 	}
 
+	// A passkey login resolves the email from the passkey's user, so the email
+	// is verified as long as the intermediate session still refers to that
+	// user.
+	if qIntermediateSession.VerifiedPasskeyID != nil && qIntermediateSession.OrganizationID != nil {
+		qVerifiedPasskeyID, err := q.GetEmailVerifiedByPasskeyID(ctx, queries.GetEmailVerifiedByPasskeyIDParams{
+			ID:             *qIntermediateSession.VerifiedPasskeyID,
+			OrganizationID: *qIntermediateSession.OrganizationID,
+			Email:          *qIntermediateSession.Email,
+		})
+		if err != nil {
+			return false, fmt.Errorf("get email verified by passkey id: %w", err)
+		}
+
+		if qVerifiedPasskeyID {
+			return true, nil
+		}
+	}
+
 	if qIntermediateSession.EmailVerificationChallengeCompleted {
 		return true, nil
 	}
@@ -140,6 +159,18 @@ func enforceOrganizationLoginEnabled(qOrganization queries.Organization) error {
 	return nil
 }
 
+func (s *Store) enforceOrganizationIPAllowlist(ctx context.Context, q *queries.Queries, qOrganization queries.Organization) error {
+	qIPAllowlistCIDRs, err := q.GetOrganizationIPAllowlistCIDRs(ctx, qOrganization.ID)
+	if err != nil {
+		return fmt.Errorf("get organization ip allowlist cidrs: %w", err)
+	}
+
+	if !clientip.Allowed(clientip.FromContext(ctx), qIPAllowlistCIDRs) {
+		return apierror.NewIPAddressNotAllowedError("client ip address is not allowed by organization ip allowlist", fmt.Errorf("client ip not in organization ip allowlist"))
+	}
+	return nil
+}
+
 func enforceProjectLoginEnabled(qProject queries.Project) error {
 	if qProject.LoginsDisabled {
 		return apierror.NewPermissionDeniedError("login disabled", fmt.Errorf("project login disabled"))
@@ -170,6 +201,8 @@ func parseIntermediateSession(qIntermediateSession queries.IntermediateSession,
 			primaryAuthFactor = intermediatev1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_SAML
 		case queries.PrimaryAuthFactorOidc:
 			primaryAuthFactor = intermediatev1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_OIDC
+		case queries.PrimaryAuthFactorPasskey:
+			primaryAuthFactor = intermediatev1.PrimaryAuthFactor_PRIMARY_AUTH_FACTOR_PASSKEY
 		}
 	}
 
@@ -187,6 +220,7 @@ func parseIntermediateSession(qIntermediateSession queries.IntermediateSession,
 		PasswordVerified:                     qIntermediateSession.PasswordVerified,
 		AuthenticatorAppVerified:             qIntermediateSession.AuthenticatorAppVerified,
 		PasskeyVerified:                      qIntermediateSession.PasskeyVerified,
+		PasswordExpired:                      qIntermediateSession.PasswordExpired,
 		PrimaryAuthFactor:                    primaryAuthFactor,
 		NewUserPasswordRegistered:            qIntermediateSession.NewUserPasswordBcrypt != nil,
 		OrganizationId:                       organizationID,
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/uuidv7"
@@ -21,7 +23,9 @@ type logAuditEventParams struct {
 	ResourceID     *uuid.UUID
 }
 
-func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, data logAuditEventParams) (queries.AuditLogEvent, error) {
+func (s *Store) logAuditEvent(ctx context.Context, tx pgx.Tx, data logAuditEventParams) (queries.AuditLogEvent, error) {
+	q := queries.New(tx)
+
 	// Generate the UUIDv7 based on the event time.
 	eventTime := time.Now()
 	eventID := uuidv7.NewWithTime(eventTime)
@@ -49,5 +53,16 @@ func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, data logA
 		return queries.AuditLogEvent{}, err
 	}
 
+	// Resource changes are also sent as typed webhook events.
+	if err := backgroundworkerstore.InsertTypedEventTx(ctx, tx, s.riverClient, backgroundworkerstore.NewTypedEventArgsParams{
+		ProjectID:         qEvent.ProjectID,
+		AuditLogEventID:   qEvent.ID,
+		AuditLogEventName: qEvent.EventName,
+		EventTime:         eventTime,
+		EventDetails:      data.EventDetails,
+	}); err != nil {
+		return queries.AuditLogEvent{}, fmt.Errorf("insert typed event: %w", err)
+	}
+
 	return qEvent, nil
 }
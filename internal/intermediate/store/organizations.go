@@ -8,7 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/tesseral-labs/tesseral/internal/backgroundworker/webhookworker"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/emailaddr"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
@@ -457,20 +457,23 @@ func (s *Store) getVisibleOrganizations(ctx context.Context, q *queries.Queries,
 }
 
 func (s *Store) sendSyncOrganizationEvent(ctx context.Context, tx pgx.Tx, qOrg queries.Organization) error {
-	// Add the sync organization event to the background worker queue
-	jobInsertRes, err := s.riverClient.InsertTx(ctx, tx, webhookworker.Args{
-		ProjectID: idformat.Project.Format(authn.ProjectID(ctx)),
-		EventName: "sync.organization",
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	orgID := idformat.Organization.Format(qOrg.ID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: orgID,
+		EventType:   "sync.organization",
 		Payload: map[string]any{
 			"type":           "sync.organization",
-			"organizationId": idformat.Organization.Format(qOrg.ID),
+			"organizationId": orgID,
 		},
-	}, nil)
+	})
 	if err != nil {
-		return fmt.Errorf("insert job: %w", err)
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
 	}
 
-	slog.InfoContext(ctx, "webhook_worker_job_inserted", "job_id", jobInsertRes.Job.ID, "event_type", "sync.organization", "organization_id", idformat.Organization.Format(qOrg.ID))
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.organization", "organization_id", orgID)
 
 	return nil
 }
@@ -487,19 +490,20 @@ func parseOrganization(qOrg queries.Organization, qSAMLConnection *queries.SamlC
 	}
 
 	return &intermediatev1.Organization{
-		Id:                        idformat.Organization.Format(qOrg.ID),
-		DisplayName:               qOrg.DisplayName,
-		LogInWithEmail:            qOrg.LogInWithEmail,
-		LogInWithGoogle:           qOrg.LogInWithGoogle,
-		LogInWithGithub:           qOrg.LogInWithGithub,
-		LogInWithMicrosoft:        qOrg.LogInWithMicrosoft,
-		LogInWithPassword:         qOrg.LogInWithPassword,
-		LogInWithAuthenticatorApp: qOrg.LogInWithAuthenticatorApp,
-		LogInWithPasskey:          qOrg.LogInWithPasskey,
-		LogInWithSaml:             qOrg.LogInWithSaml,
-		LogInWithOidc:             qOrg.LogInWithOidc,
-		RequireMfa:                qOrg.RequireMfa,
-		PrimarySamlConnectionId:   primarySamlConnectionID,
-		PrimaryOidcConnectionId:   primaryOIDCConnectionID,
+		Id:                           idformat.Organization.Format(qOrg.ID),
+		DisplayName:                  qOrg.DisplayName,
+		LogInWithEmail:               qOrg.LogInWithEmail,
+		LogInWithGoogle:              qOrg.LogInWithGoogle,
+		LogInWithGithub:              qOrg.LogInWithGithub,
+		LogInWithMicrosoft:           qOrg.LogInWithMicrosoft,
+		LogInWithPassword:            qOrg.LogInWithPassword,
+		LogInWithAuthenticatorApp:    qOrg.LogInWithAuthenticatorApp,
+		LogInWithPasskey:             qOrg.LogInWithPasskey,
+		LogInWithPasswordlessPasskey: qOrg.LogInWithPasswordlessPasskey,
+		LogInWithSaml:                qOrg.LogInWithSaml,
+		LogInWithOidc:                qOrg.LogInWithOidc,
+		RequireMfa:                   qOrg.RequireMfa,
+		PrimarySamlConnectionId:      primarySamlConnectionID,
+		PrimaryOidcConnectionId:      primaryOIDCConnectionID,
 	}
 }
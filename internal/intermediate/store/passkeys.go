@@ -5,8 +5,12 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"log/slog"
 
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
@@ -60,6 +64,19 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *intermediatev1.Registe
 		return nil, fmt.Errorf("get project by id: %w", err)
 	}
 
+	qIntermediateSession, err := q.GetIntermediateSessionByID(ctx, authn.IntermediateSessionID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get intermediate session by id: %w", err)
+	}
+
+	qOrg, err := q.GetProjectOrganizationByID(ctx, queries.GetProjectOrganizationByIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        *qIntermediateSession.OrganizationID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization by id: %w", err)
+	}
+
 	cred, err := webauthn.Parse(&webauthn.ParseRequest{
 		RPID:              qProject.CookieDomain,
 		AttestationObject: req.AttestationObject,
@@ -68,6 +85,10 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *intermediatev1.Registe
 		return nil, fmt.Errorf("parse webauthn credential: %w", err)
 	}
 
+	if err := s.enforceOrganizationPasskeyPolicy(ctx, q, qOrg, cred, req.ClientDataJson); err != nil {
+		return nil, fmt.Errorf("enforce organization passkey policy: %w", err)
+	}
+
 	publicKey, err := x509.MarshalPKIXPublicKey(cred.PublicKey)
 	if err != nil {
 		return nil, fmt.Errorf("marshal public key: %w", err)
@@ -90,6 +111,44 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *intermediatev1.Registe
 	return &intermediatev1.RegisterPasskeyResponse{}, nil
 }
 
+// enforceOrganizationPasskeyPolicy returns an error if the organization's
+// passkey policy does not permit cred.
+func (s *Store) enforceOrganizationPasskeyPolicy(ctx context.Context, q *queries.Queries, qOrg queries.Organization, cred *webauthn.Credential, clientDataJSON string) error {
+	qAAGUIDs, err := q.GetOrganizationPasskeyAAGUIDs(ctx, qOrg.ID)
+	if err != nil {
+		return fmt.Errorf("get organization passkey aaguids: %w", err)
+	}
+
+	policy := webauthn.AAGUIDPolicy{
+		RequireAttestation: qOrg.PasskeyRequireAttestation,
+	}
+	for _, qAAGUID := range qAAGUIDs {
+		if qAAGUID.Allowed {
+			policy.AllowedAAGUIDs = append(policy.AllowedAAGUIDs, qAAGUID.Aaguid.String())
+		} else {
+			policy.DeniedAAGUIDs = append(policy.DeniedAAGUIDs, qAAGUID.Aaguid.String())
+		}
+	}
+
+	if !policy.Enforced() {
+		return nil
+	}
+
+	attestation, err := cred.VerifyAttestation(&webauthn.VerifyAttestationRequest{
+		ClientDataJSON: clientDataJSON,
+		Metadata:       s.fidoMetadata,
+	})
+	if err != nil {
+		return apierror.NewInvalidArgumentError("invalid passkey attestation", fmt.Errorf("verify attestation: %w", err))
+	}
+
+	if err := policy.Check(cred, attestation); err != nil {
+		return apierror.NewPermissionDeniedError("passkey authenticator is not allowed by organization policy", fmt.Errorf("check passkey policy: %w", err))
+	}
+
+	return nil
+}
+
 func (s *Store) IssuePasskeyChallenge(ctx context.Context, req *intermediatev1.IssuePasskeyChallengeRequest) (*intermediatev1.IssuePasskeyChallengeResponse, error) {
 	_, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -150,7 +209,7 @@ func (s *Store) IssuePasskeyChallenge(ctx context.Context, req *intermediatev1.I
 }
 
 func (s *Store) VerifyPasskey(ctx context.Context, req *intermediatev1.VerifyPasskeyRequest) (*intermediatev1.VerifyPasskeyResponse, error) {
-	_, q, commit, rollback, err := s.tx(ctx)
+	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -202,17 +261,33 @@ func (s *Store) VerifyPasskey(ctx context.Context, req *intermediatev1.VerifyPas
 		PublicKey: publicKey,
 	}
 
-	if err := credential.Verify(&webauthn.VerifyRequest{
+	assertion, err := credential.Verify(&webauthn.VerifyRequest{
 		RPID:              qPasskey.RpID,
 		ChallengeSHA256:   qIntermediateSession.PasskeyVerifyChallengeSha256,
 		ClientDataJSON:    req.ClientDataJson,
 		AuthenticatorData: req.AuthenticatorData,
 		Signature:         req.Signature,
 		Origins:           origins,
-	}); err != nil {
+
+		RequireUserVerification: qOrg.PasskeyRequireUserVerification,
+	})
+	if err != nil {
 		return nil, apierror.NewInvalidArgumentError("invalid passkey verification", fmt.Errorf("verify passkey: %w", err))
 	}
 
+	cloned, err := s.updatePasskeySignCount(ctx, tx, q, qOrg, qPasskey, assertion)
+	if err != nil {
+		return nil, fmt.Errorf("update passkey sign count: %w", err)
+	}
+
+	if cloned {
+		// commit so that the audit event is recorded
+		if err := commit(); err != nil {
+			return nil, fmt.Errorf("commit: %w", err)
+		}
+		return nil, apierror.NewPermissionDeniedError("passkey may have been cloned", fmt.Errorf("passkey sign count did not increase"))
+	}
+
 	if _, err := q.UpdateIntermediateSessionPasskeyVerified(ctx, authn.IntermediateSessionID(ctx)); err != nil {
 		return nil, fmt.Errorf("update intermediate session passkey verified: %w", err)
 	}
@@ -224,6 +299,223 @@ func (s *Store) VerifyPasskey(ctx context.Context, req *intermediatev1.VerifyPas
 	return &intermediatev1.VerifyPasskeyResponse{}, nil
 }
 
+func (s *Store) IssuePasskeyLoginChallenge(ctx context.Context, req *intermediatev1.IssuePasskeyLoginChallengeRequest) (*intermediatev1.IssuePasskeyLoginChallengeResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	if err := enforceProjectLoginEnabled(qProject); err != nil {
+		return nil, fmt.Errorf("enforce project login enabled: %w", err)
+	}
+
+	if !qProject.LogInWithPasswordlessPasskey {
+		return nil, apierror.NewFailedPreconditionError("log in with passwordless passkey not enabled", nil)
+	}
+
+	var challenge [32]byte
+	if _, err := rand.Read(challenge[:]); err != nil {
+		return nil, fmt.Errorf("read random bytes: %w", err)
+	}
+
+	challengeSHA256 := sha256.Sum256(challenge[:])
+	if _, err := q.UpdateIntermediateSessionPasskeyVerifyChallengeSHA256(ctx, queries.UpdateIntermediateSessionPasskeyVerifyChallengeSHA256Params{
+		ID:                           authn.IntermediateSessionID(ctx),
+		PasskeyVerifyChallengeSha256: challengeSHA256[:],
+	}); err != nil {
+		return nil, fmt.Errorf("update intermediate session passkey verify challenge: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	// no credential ids are returned; the authenticator chooses a discoverable
+	// credential, and VerifyPasskeyLogin resolves the user from it
+	return &intermediatev1.IssuePasskeyLoginChallengeResponse{
+		RpId:      qProject.CookieDomain,
+		Challenge: challenge[:],
+	}, nil
+}
+
+func (s *Store) VerifyPasskeyLogin(ctx context.Context, req *intermediatev1.VerifyPasskeyLoginRequest) (*intermediatev1.VerifyPasskeyLoginResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	if err := enforceProjectLoginEnabled(qProject); err != nil {
+		return nil, fmt.Errorf("enforce project login enabled: %w", err)
+	}
+
+	if !qProject.LogInWithPasswordlessPasskey {
+		return nil, apierror.NewFailedPreconditionError("log in with passwordless passkey not enabled", nil)
+	}
+
+	qIntermediateSession, err := q.GetIntermediateSessionByID(ctx, authn.IntermediateSessionID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get intermediate session by id: %w", err)
+	}
+
+	if qIntermediateSession.PasskeyVerifyChallengeSha256 == nil {
+		return nil, apierror.NewFailedPreconditionError("passkey challenge not issued", nil)
+	}
+
+	qPasskey, err := q.GetProjectPasskeyByCredentialID(ctx, queries.GetProjectPasskeyByCredentialIDParams{
+		CredentialID: req.CredentialId,
+		ProjectID:    authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewInvalidArgumentError("invalid passkey verification", fmt.Errorf("passkey not found"))
+		}
+		return nil, fmt.Errorf("get project passkey by credential id: %w", err)
+	}
+
+	qUser, err := q.GetUserByID(ctx, qPasskey.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
+	qOrg, err := q.GetProjectOrganizationByID(ctx, queries.GetProjectOrganizationByIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        qUser.OrganizationID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization by id: %w", err)
+	}
+
+	if !qOrg.LogInWithPasswordlessPasskey {
+		return nil, apierror.NewFailedPreconditionError("log in with passwordless passkey not enabled for organization", nil)
+	}
+
+	qTrustedDomains, err := q.GetProjectTrustedDomains(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project trusted domains: %w", err)
+	}
+
+	// the set of origins we expect passkeys to be using
+	var origins []string
+	for _, qProjectTrustedDomain := range qTrustedDomains {
+		origins = append(origins, fmt.Sprintf("https://%s", qProjectTrustedDomain.Domain))
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(qPasskey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("marshal public key: %w", err)
+	}
+
+	credential := webauthn.Credential{
+		PublicKey: publicKey,
+	}
+
+	assertion, err := credential.Verify(&webauthn.VerifyRequest{
+		RPID:              qPasskey.RpID,
+		ChallengeSHA256:   qIntermediateSession.PasskeyVerifyChallengeSha256,
+		ClientDataJSON:    req.ClientDataJson,
+		AuthenticatorData: req.AuthenticatorData,
+		Signature:         req.Signature,
+		Origins:           origins,
+
+		// the passkey is the only factor in a passkey login, so it must
+		// verify the user, not merely their presence
+		RequireUserVerification: true,
+	})
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid passkey verification", fmt.Errorf("verify passkey: %w", err))
+	}
+
+	cloned, err := s.updatePasskeySignCount(ctx, tx, q, qOrg, qPasskey, assertion)
+	if err != nil {
+		return nil, fmt.Errorf("update passkey sign count: %w", err)
+	}
+
+	if cloned {
+		// commit so that the audit event is recorded
+		if err := commit(); err != nil {
+			return nil, fmt.Errorf("commit: %w", err)
+		}
+		return nil, apierror.NewPermissionDeniedError("passkey may have been cloned", fmt.Errorf("passkey sign count did not increase"))
+	}
+
+	if _, err := q.UpdateIntermediateSessionPasskeyLogin(ctx, queries.UpdateIntermediateSessionPasskeyLoginParams{
+		ID:                authn.IntermediateSessionID(ctx),
+		Email:             &qUser.Email,
+		OrganizationID:    &qOrg.ID,
+		VerifiedPasskeyID: &qPasskey.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("update intermediate session passkey login: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &intermediatev1.VerifyPasskeyLoginResponse{}, nil
+}
+
+// updatePasskeySignCount records the signature counter from a passkey
+// assertion. If the counter did not increase, the passkey may have been
+// cloned; updatePasskeySignCount logs an audit event and returns true instead.
+//
+// The counter is only updated if it increases, so that concurrent assertions
+// with the same counter cannot both succeed.
+func (s *Store) updatePasskeySignCount(ctx context.Context, tx pgx.Tx, q *queries.Queries, qOrg queries.Organization, qPasskey queries.Passkey, assertion *webauthn.Assertion) (bool, error) {
+	previousSignCount := uint32(qPasskey.SignCount)
+	if !assertion.Cloned(previousSignCount) {
+		_, err := q.UpdatePasskeySignCount(ctx, queries.UpdatePasskeySignCountParams{
+			ID:        qPasskey.ID,
+			SignCount: int64(assertion.SignCount),
+		})
+		if err == nil {
+			return false, nil
+		}
+
+		// another assertion advanced the counter since qPasskey was read
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return false, fmt.Errorf("update passkey sign count: %w", err)
+		}
+	}
+
+	slog.WarnContext(ctx, "passkey_clone_detected",
+		"passkey_id", idformat.Passkey.Format(qPasskey.ID),
+		"sign_count", assertion.SignCount,
+		"previous_sign_count", previousSignCount)
+
+	auditPasskey, err := s.auditlogStore.GetPasskey(ctx, tx, qPasskey.ID)
+	if err != nil {
+		return false, fmt.Errorf("get audit passkey: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+		EventName: "tesseral.passkeys.detect_clone",
+		EventDetails: &auditlogv1.DetectPasskeyClone{
+			Passkey:           auditPasskey,
+			SignCount:         assertion.SignCount,
+			PreviousSignCount: previousSignCount,
+		},
+		OrganizationID: &qOrg.ID,
+		ResourceType:   queries.AuditLogEventResourceTypePasskey,
+		ResourceID:     &qPasskey.ID,
+	}); err != nil {
+		return false, fmt.Errorf("log audit event: %w", err)
+	}
+
+	return true, nil
+}
+
 func (s *Store) checkShouldRegisterPasskey(ctx context.Context, q *queries.Queries) error {
 	// don't register passkeys if you're already matching a user, and that user
 	// has at least one active passkey
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/passwordpolicy"
+)
+
+// getEffectivePasswordPolicy returns the stricter of a project's and an
+// organization's password policies.
+func getEffectivePasswordPolicy(ctx context.Context, q *queries.Queries, projectID, organizationID uuid.UUID) (passwordpolicy.Policy, error) {
+	var projectPolicy, organizationPolicy passwordpolicy.Policy
+
+	qProjectPasswordPolicy, err := q.GetProjectPasswordPolicy(ctx, projectID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return passwordpolicy.Policy{}, fmt.Errorf("get project password policy: %w", err)
+	}
+	if err == nil {
+		projectPolicy = passwordpolicy.Policy{
+			MinLength:            int(qProjectPasswordPolicy.MinLength),
+			RequireLowercase:     qProjectPasswordPolicy.RequireLowercase,
+			RequireUppercase:     qProjectPasswordPolicy.RequireUppercase,
+			RequireDigit:         qProjectPasswordPolicy.RequireDigit,
+			RequireSymbol:        qProjectPasswordPolicy.RequireSymbol,
+			DisallowPersonalInfo: qProjectPasswordPolicy.DisallowPersonalInfo,
+			HistoryCount:         int(qProjectPasswordPolicy.HistoryCount),
+			MaxAge:               time.Duration(qProjectPasswordPolicy.MaxAgeDays) * time.Hour * 24,
+			LockoutAttempts:      int(qProjectPasswordPolicy.LockoutAttempts),
+			LockoutDuration:      time.Duration(qProjectPasswordPolicy.LockoutDurationSeconds) * time.Second,
+		}
+	}
+
+	qOrganizationPasswordPolicy, err := q.GetOrganizationPasswordPolicy(ctx, organizationID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return passwordpolicy.Policy{}, fmt.Errorf("get organization password policy: %w", err)
+	}
+	if err == nil {
+		organizationPolicy = passwordpolicy.Policy{
+			MinLength:            int(qOrganizationPasswordPolicy.MinLength),
+			RequireLowercase:     qOrganizationPasswordPolicy.RequireLowercase,
+			RequireUppercase:     qOrganizationPasswordPolicy.RequireUppercase,
+			RequireDigit:         qOrganizationPasswordPolicy.RequireDigit,
+			RequireSymbol:        qOrganizationPasswordPolicy.RequireSymbol,
+			DisallowPersonalInfo: qOrganizationPasswordPolicy.DisallowPersonalInfo,
+			HistoryCount:         int(qOrganizationPasswordPolicy.HistoryCount),
+			MaxAge:               time.Duration(qOrganizationPasswordPolicy.MaxAgeDays) * time.Hour * 24,
+			LockoutAttempts:      int(qOrganizationPasswordPolicy.LockoutAttempts),
+			LockoutDuration:      time.Duration(qOrganizationPasswordPolicy.LockoutDurationSeconds) * time.Second,
+		}
+	}
+
+	return passwordpolicy.Stricter(projectPolicy, organizationPolicy), nil
+}
+
+// checkPasswordPolicy returns an error if password does not satisfy the
+// effective password policy for a user in qOrg. qUser may be nil, if the user
+// does not yet exist.
+func checkPasswordPolicy(ctx context.Context, q *queries.Queries, qOrg queries.Organization, qUser *queries.User, email, password string) error {
+	policy, err := getEffectivePasswordPolicy(ctx, q, qOrg.ProjectID, qOrg.ID)
+	if err != nil {
+		return fmt.Errorf("get effective password policy: %w", err)
+	}
+
+	// the user's current password is not in their history, so check it first
+	var previousPasswordBcrypts []string
+	if qUser != nil && qUser.PasswordBcrypt != nil {
+		previousPasswordBcrypts = append(previousPasswordBcrypts, *qUser.PasswordBcrypt)
+	}
+
+	if qUser != nil {
+		qPasswordHistoryBcrypts, err := q.ListUserPasswordHistoryBcrypts(ctx, queries.ListUserPasswordHistoryBcryptsParams{
+			UserID: qUser.ID,
+			Limit:  int32(policy.HistoryCount),
+		})
+		if err != nil {
+			return fmt.Errorf("list user password history bcrypts: %w", err)
+		}
+		previousPasswordBcrypts = append(previousPasswordBcrypts, qPasswordHistoryBcrypts...)
+	}
+
+	return policy.Check(&passwordpolicy.CheckRequest{
+		Password:                password,
+		Email:                   email,
+		OrganizationDisplayName: qOrg.DisplayName,
+		PreviousPasswordBcrypts: previousPasswordBcrypts,
+	})
+}
+
+// addUserPasswordHistory records a user's replaced password, so that password
+// policies can prevent its reuse. Passwords beyond the historyCount most recent
+// are forgotten, as no policy checks them.
+func addUserPasswordHistory(ctx context.Context, q *queries.Queries, userID uuid.UUID, passwordBcrypt string, historyCount int) error {
+	if _, err := q.CreateUserPasswordHistory(ctx, queries.CreateUserPasswordHistoryParams{
+		ID:             uuid.New(),
+		UserID:         userID,
+		PasswordBcrypt: passwordBcrypt,
+	}); err != nil {
+		return fmt.Errorf("create user password history: %w", err)
+	}
+
+	if err := q.DeleteUserPasswordHistoriesBeyondCount(ctx, queries.DeleteUserPasswordHistoriesBeyondCountParams{
+		UserID: userID,
+		Limit:  int32(historyCount),
+	}); err != nil {
+		return fmt.Errorf("delete user password histories beyond count: %w", err)
+	}
+
+	return nil
+}
@@ -16,17 +16,11 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/passwordpolicy"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const (
-	// after this many failed attempts, lock out a user
-	passwordLockoutAttempts = 5
-	// how long to lock users out
-	passwordLockoutDuration = time.Minute * 10
-)
-
 func (s *Store) RegisterPassword(ctx context.Context, req *intermediatev1.RegisterPasswordRequest) (*intermediatev1.RegisterPasswordResponse, error) {
 	// Check if the password is compromised.
 	pwned, err := s.hibp.Pwned(ctx, req.Password)
@@ -48,7 +42,9 @@ func (s *Store) RegisterPassword(ctx context.Context, req *intermediatev1.Regist
 		return nil, err
 	}
 
-	if qIntermediateSession.PasswordVerified {
+	// users whose password has expired must register a new one, even though
+	// they have verified their old one
+	if qIntermediateSession.PasswordVerified && !qIntermediateSession.PasswordExpired {
 		return nil, apierror.NewFailedPreconditionError("password already verified", fmt.Errorf("password already verified"))
 	}
 
@@ -88,16 +84,31 @@ func (s *Store) RegisterPassword(ctx context.Context, req *intermediatev1.Regist
 
 	// only allow password registration if the matching user doesn't already
 	// have one, or if the intermediate session has verified a password reset
-	// code
+	// code or an expired password
 	qUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
 		return nil, fmt.Errorf("match user: %w", err)
 	}
 
-	if qUser != nil && qUser.PasswordBcrypt != nil && !qIntermediateSession.PasswordResetCodeVerified {
+	if qUser != nil && qUser.PasswordBcrypt != nil && !qIntermediateSession.PasswordResetCodeVerified && !qIntermediateSession.PasswordExpired {
 		return nil, apierror.NewFailedPreconditionError("user already has password configured", fmt.Errorf("user already has password configured"))
 	}
 
+	if qIntermediateSession.PasswordExpired && qUser != nil && qUser.PasswordBcrypt != nil {
+		if err := bcrypt.CompareHashAndPassword([]byte(*qUser.PasswordBcrypt), []byte(req.Password)); err == nil {
+			return nil, apierror.NewPasswordPolicyViolationError("Password must be different from your expired password.", fmt.Errorf("password reused after expiry"))
+		}
+	}
+
+	if err := checkPasswordPolicy(ctx, q, qOrg, qUser, derefOrEmpty(qIntermediateSession.Email), req.Password); err != nil {
+		var violation *passwordpolicy.ViolationError
+		if errors.As(err, &violation) {
+			return nil, apierror.NewPasswordPolicyViolationError(violation.Reason, fmt.Errorf("check password policy: %w", err))
+		}
+
+		return nil, fmt.Errorf("check password policy: %w", err)
+	}
+
 	passwordBcryptBytes, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcryptcost.Cost)
 	if err != nil {
 		return nil, fmt.Errorf("generate bcrypt hash: %w", err)
@@ -199,7 +210,12 @@ func (s *Store) VerifyPassword(ctx context.Context, req *intermediatev1.VerifyPa
 		return nil, apierror.NewFailedPreconditionError("no corresponding user found", nil)
 	}
 
-	if err := s.attemptMatchPassword(ctx, q, *qMatchingUser, req.Password); err != nil {
+	policy, err := getEffectivePasswordPolicy(ctx, q, authn.ProjectID(ctx), qOrg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get effective password policy: %w", err)
+	}
+
+	if err := s.attemptMatchPassword(ctx, q, policy, *qMatchingUser, req.Password); err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 			return nil, apierror.NewIncorrectPasswordError("incorrect password", nil)
 		}
@@ -233,6 +249,10 @@ func (s *Store) VerifyPassword(ctx context.Context, req *intermediatev1.VerifyPa
 		return nil, fmt.Errorf("update intermediate session password verified: %w", err)
 	}
 
+	if err := updateIntermediateSessionPasswordExpired(ctx, q, policy, qIntermediateSession.ID, *qMatchingUser); err != nil {
+		return nil, fmt.Errorf("update intermediate session password expired: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
@@ -284,7 +304,12 @@ func (s *Store) logInWithPassword(ctx context.Context, req *intermediatev1.Verif
 
 	qMatchingUser := qUsers[0]
 
-	if err := s.attemptMatchPassword(ctx, q, qMatchingUser, req.Password); err != nil {
+	policy, err := getEffectivePasswordPolicy(ctx, q, authn.ProjectID(ctx), qMatchingUser.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("get effective password policy: %w", err)
+	}
+
+	if err := s.attemptMatchPassword(ctx, q, policy, qMatchingUser, req.Password); err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 			return nil, apierror.NewIncorrectPasswordError("incorrect password", nil)
 		}
@@ -331,6 +356,10 @@ func (s *Store) logInWithPassword(ctx context.Context, req *intermediatev1.Verif
 		return nil, fmt.Errorf("update intermediate session primary auth factor: %w", err)
 	}
 
+	if err := updateIntermediateSessionPasswordExpired(ctx, q, policy, authn.IntermediateSessionID(ctx), qMatchingUser); err != nil {
+		return nil, fmt.Errorf("update intermediate session password expired: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
@@ -338,7 +367,20 @@ func (s *Store) logInWithPassword(ctx context.Context, req *intermediatev1.Verif
 	return &intermediatev1.VerifyPasswordResponse{}, nil
 }
 
-func (s *Store) attemptMatchPassword(ctx context.Context, q *queries.Queries, qUser queries.User, password string) error {
+// updateIntermediateSessionPasswordExpired marks an intermediate session as
+// requiring a new password, if qUser's password is older than policy allows.
+func updateIntermediateSessionPasswordExpired(ctx context.Context, q *queries.Queries, policy passwordpolicy.Policy, intermediateSessionID uuid.UUID, qUser queries.User) error {
+	if qUser.PasswordUpdateTime == nil || !policy.Expired(*qUser.PasswordUpdateTime, time.Now()) {
+		return nil
+	}
+
+	if _, err := q.UpdateIntermediateSessionPasswordExpired(ctx, intermediateSessionID); err != nil {
+		return fmt.Errorf("update intermediate session password expired: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) attemptMatchPassword(ctx context.Context, q *queries.Queries, policy passwordpolicy.Policy, qUser queries.User, password string) error {
 	if qUser.PasswordBcrypt == nil {
 		return apierror.NewFailedPreconditionError("user does not have password configured", nil)
 	}
@@ -349,9 +391,9 @@ func (s *Store) attemptMatchPassword(ctx context.Context, q *queries.Queries, qU
 
 	if err := bcrypt.CompareHashAndPassword([]byte(*qUser.PasswordBcrypt), []byte(password)); err != nil {
 		attempts := qUser.FailedPasswordAttempts + 1
-		if attempts >= passwordLockoutAttempts {
+		if int(attempts) >= policy.EffectiveLockoutAttempts() {
 			// lock the user out
-			passwordLockoutExpireTime := time.Now().Add(passwordLockoutDuration)
+			passwordLockoutExpireTime := time.Now().Add(policy.EffectiveLockoutDuration())
 			if _, err := q.UpdateUserPasswordLockoutExpireTime(ctx, queries.UpdateUserPasswordLockoutExpireTimeParams{
 				ID:                        qUser.ID,
 				PasswordLockoutExpireTime: &passwordLockoutExpireTime,
@@ -93,6 +93,8 @@ func (s *Store) GetSettings(ctx context.Context, req *intermediatev1.GetSettings
 			LogInWithPassword:            qProject.LogInWithPassword,
 			LogInWithSaml:                qProject.LogInWithSaml,
 			LogInWithOidc:                qProject.LogInWithOidc,
+			LogInWithPasskey:             qProject.LogInWithPasskey,
+			LogInWithPasswordlessPasskey: qProject.LogInWithPasswordlessPasskey,
 			RedirectUri:                  qProject.RedirectUri,
 			AfterLoginRedirectUri:        qProject.AfterLoginRedirectUri,
 			AfterSignupRedirectUri:       qProject.AfterSignupRedirectUri,
@@ -21,6 +21,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/kms"
 	"github.com/tesseral-labs/tesseral/internal/microsoftoauth"
 	"github.com/tesseral-labs/tesseral/internal/pagetoken"
+	"github.com/tesseral-labs/tesseral/internal/webauthn"
 )
 
 type Store struct {
@@ -48,6 +49,7 @@ type Store struct {
 	stripeClient                      *stripeclient.API
 	svixClient                        *svix.Svix
 	auditlogStore                     *auditlogstore.Store
+	fidoMetadata                      *webauthn.Metadata
 	defaultGoogleOAuthClientID        string
 	defaultGoogleOAuthClientSecret    string
 	defaultGoogleOAuthRedirectURI     string
@@ -81,6 +83,7 @@ type NewStoreParams struct {
 	StripeClient                      *stripeclient.API
 	SvixClient                        *svix.Svix
 	AuditlogStore                     *auditlogstore.Store
+	FIDOMetadata                      *webauthn.Metadata
 	DefaultGoogleOAuthClientID        string
 	DefaultGoogleOAuthClientSecret    string
 	DefaultGoogleOAuthRedirectURI     string
@@ -120,6 +123,7 @@ func New(p NewStoreParams) *Store {
 		stripeClient:                      p.StripeClient,
 		svixClient:                        p.SvixClient,
 		auditlogStore:                     p.AuditlogStore,
+		fidoMetadata:                      p.FIDOMetadata,
 		defaultGoogleOAuthClientID:        p.DefaultGoogleOAuthClientID,
 		defaultGoogleOAuthClientSecret:    p.DefaultGoogleOAuthClientSecret,
 		defaultGoogleOAuthRedirectURI:     p.DefaultGoogleOAuthRedirectURI,
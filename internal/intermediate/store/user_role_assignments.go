@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
+	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
+	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// assignDefaultRoles assigns a user who has just joined an organization the
+// project's default roles.
+func (s *Store) assignDefaultRoles(ctx context.Context, tx pgx.Tx, q *queries.Queries, qUser queries.User) error {
+	qUserRoleAssignments, err := q.CreateDefaultUserRoleAssignments(ctx, qUser.ID)
+	if err != nil {
+		return fmt.Errorf("create default user role assignments: %w", err)
+	}
+
+	if len(qUserRoleAssignments) == 0 {
+		return nil
+	}
+
+	for _, qUserRoleAssignment := range qUserRoleAssignments {
+		auditUserRoleAssignment, err := s.auditlogStore.GetUserRoleAssignment(ctx, tx, qUserRoleAssignment.ID)
+		if err != nil {
+			return fmt.Errorf("get audit user role assignment: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+			EventName: "tesseral.users.assign_role",
+			EventDetails: &auditlogv1.AssignUserRole{
+				UserRoleAssignment: auditUserRoleAssignment,
+			},
+			OrganizationID: &qUser.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeUser,
+			ResourceID:     &qUser.ID,
+		}); err != nil {
+			return fmt.Errorf("log audit event: %w", err)
+		}
+	}
+
+	if err := s.sendSyncUserRoleAssignmentsEvent(ctx, tx, qUser); err != nil {
+		return fmt.Errorf("send sync user role assignments event: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) sendSyncUserRoleAssignmentsEvent(ctx context.Context, tx pgx.Tx, qUser queries.User) error {
+	// Add the event to the resource's webhook outbox, so that it is delivered in
+	// order with other events about the same resource
+	userID := idformat.User.Format(qUser.ID)
+	sequence, err := backgroundworkerstore.EnqueueOrderedWebhookTx(ctx, tx, s.riverClient, &backgroundworkerstore.EnqueueOrderedWebhookRequest{
+		ProjectID:   authn.ProjectID(ctx),
+		ResourceKey: userID,
+		EventType:   "sync.user_role_assignments",
+		Payload: map[string]any{
+			"type":   "sync.user_role_assignments",
+			"userId": userID,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("enqueue ordered webhook: %w", err)
+	}
+
+	slog.InfoContext(ctx, "webhook_outbox_event_inserted", "sequence", sequence, "event_type", "sync.user_role_assignments", "user_id", userID)
+
+	return nil
+}
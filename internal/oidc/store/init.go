@@ -94,7 +94,7 @@ func (s *Store) GetOIDCConnectionInitData(ctx context.Context, oidcConnectionID
 		return nil, fmt.Errorf("create OIDC session: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.oidc_connections.initiate",
 		EventDetails: &auditlogv1.InitiateOIDCConnection{
 			OidcConnection: auditOidcConnection,
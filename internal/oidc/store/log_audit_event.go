@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	backgroundworkerstore "github.com/tesseral-labs/tesseral/internal/backgroundworker/store"
 	"github.com/tesseral-labs/tesseral/internal/oidc/authn"
 	"github.com/tesseral-labs/tesseral/internal/oidc/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/uuidv7"
@@ -21,7 +23,7 @@ type logAuditEventParams struct {
 	OrganizationID *uuid.UUID
 }
 
-func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, req logAuditEventParams) (queries.AuditLogEvent, error) {
+func (s *Store) logAuditEvent(ctx context.Context, tx pgx.Tx, req logAuditEventParams) (queries.AuditLogEvent, error) {
 	// Generate the UUIDv7 based on the event time.
 	eventTime := time.Now()
 	eventID := uuidv7.NewWithTime(eventTime)
@@ -42,10 +44,21 @@ func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, req logAu
 		EventDetails:   eventDetailsBytes,
 	}
 
-	qEvent, err := q.CreateAuditLogEvent(ctx, qEventParams)
+	qEvent, err := queries.New(tx).CreateAuditLogEvent(ctx, qEventParams)
 	if err != nil {
 		return queries.AuditLogEvent{}, err
 	}
 
+	// Resource changes are also sent as typed webhook events.
+	if err := backgroundworkerstore.InsertTypedEventTx(ctx, tx, s.riverClient, backgroundworkerstore.NewTypedEventArgsParams{
+		ProjectID:         qEvent.ProjectID,
+		AuditLogEventID:   qEvent.ID,
+		AuditLogEventName: qEvent.EventName,
+		EventTime:         eventTime,
+		EventDetails:      req.EventDetails,
+	}); err != nil {
+		return queries.AuditLogEvent{}, fmt.Errorf("insert typed event: %w", err)
+	}
+
 	return qEvent, nil
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
 	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
 	"github.com/tesseral-labs/tesseral/internal/kms"
 	"github.com/tesseral-labs/tesseral/internal/oidc/store/queries"
@@ -18,6 +19,7 @@ type Store struct {
 	oidcClientSecretsKMS *kms.KMS
 	oidc                 *oidcclient.Client
 	auditlogStore        *auditlogstore.Store
+	riverClient          *river.Client[pgx.Tx]
 }
 
 type NewStoreParams struct {
@@ -25,6 +27,7 @@ type NewStoreParams struct {
 	OIDCClientSecretsKMS *kms.KMS
 	OIDCClient           *oidcclient.Client
 	AuditlogStore        *auditlogstore.Store
+	RiverClient          *river.Client[pgx.Tx]
 }
 
 func New(p NewStoreParams) *Store {
@@ -34,6 +37,7 @@ func New(p NewStoreParams) *Store {
 		oidcClientSecretsKMS: p.OIDCClientSecretsKMS,
 		oidc:                 p.OIDCClient,
 		auditlogStore:        p.AuditlogStore,
+		riverClient:          p.RiverClient,
 	}
 
 	return store
@@ -0,0 +1,237 @@
+// Package passwordpolicy implements the rules Projects and Organizations can
+// place on their Users' passwords.
+package passwordpolicy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// DefaultLockoutAttempts is how many failed password attempts lock a
+	// user out, if a policy does not say otherwise.
+	DefaultLockoutAttempts = 5
+
+	// DefaultLockoutDuration is how long users are locked out for, if a
+	// policy does not say otherwise.
+	DefaultLockoutDuration = time.Minute * 10
+
+	// MaxHistoryCount is the most previous passwords a policy may prevent
+	// reuse of.
+	MaxHistoryCount = 24
+
+	maxMinLength       = 128
+	maxMaxAge          = time.Hour * 24 * 3650
+	maxLockoutAttempts = 100
+	maxLockoutDuration = time.Hour * 24
+)
+
+// Policy is a set of rules for passwords. The zero Policy imposes no rules
+// beyond the default lockout.
+type Policy struct {
+	// MinLength is the minimum number of characters in a password.
+	MinLength int
+
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+
+	// DisallowPersonalInfo forbids passwords that contain the user's email
+	// address, the local part of their email address, or their
+	// organization's display name.
+	DisallowPersonalInfo bool
+
+	// HistoryCount is how many of the user's previous passwords may not be
+	// reused.
+	HistoryCount int
+
+	// MaxAge is how long a password may be used before it must be reset. If
+	// zero, passwords do not expire.
+	MaxAge time.Duration
+
+	// LockoutAttempts is how many failed password attempts lock a user out.
+	// If zero, DefaultLockoutAttempts is used.
+	LockoutAttempts int
+
+	// LockoutDuration is how long a user is locked out for. If zero,
+	// DefaultLockoutDuration is used.
+	LockoutDuration time.Duration
+}
+
+// Validate returns an error if p is not a policy Projects or Organizations
+// may configure. Its message is suitable for displaying to end users.
+func (p Policy) Validate() error {
+	if p.MinLength < 0 || p.MinLength > maxMinLength {
+		return fmt.Errorf("min_length must be between 0 and %d", maxMinLength)
+	}
+	if p.HistoryCount < 0 || p.HistoryCount > MaxHistoryCount {
+		return fmt.Errorf("history_count must be between 0 and %d", MaxHistoryCount)
+	}
+	if p.MaxAge < 0 || p.MaxAge > maxMaxAge {
+		return fmt.Errorf("max_age_days must be between 0 and %d", maxMaxAge/(time.Hour*24))
+	}
+	if p.LockoutAttempts < 0 || p.LockoutAttempts > maxLockoutAttempts {
+		return fmt.Errorf("lockout_attempts must be between 0 and %d", maxLockoutAttempts)
+	}
+	if p.LockoutDuration < 0 || p.LockoutDuration > maxLockoutDuration {
+		return fmt.Errorf("lockout_duration_seconds must be between 0 and %d", int(maxLockoutDuration.Seconds()))
+	}
+	return nil
+}
+
+// Stricter returns a policy that satisfies the rules of both a and b.
+//
+// Organization policies are combined with their project's policy this way, so
+// that an organization may tighten, but never loosen, its project's policy.
+func Stricter(a, b Policy) Policy {
+	return Policy{
+		MinLength:            max(a.MinLength, b.MinLength),
+		RequireLowercase:     a.RequireLowercase || b.RequireLowercase,
+		RequireUppercase:     a.RequireUppercase || b.RequireUppercase,
+		RequireDigit:         a.RequireDigit || b.RequireDigit,
+		RequireSymbol:        a.RequireSymbol || b.RequireSymbol,
+		DisallowPersonalInfo: a.DisallowPersonalInfo || b.DisallowPersonalInfo,
+		HistoryCount:         max(a.HistoryCount, b.HistoryCount),
+		MaxAge:               minNonZero(a.MaxAge, b.MaxAge),
+		LockoutAttempts:      minNonZero(a.LockoutAttempts, b.LockoutAttempts),
+		LockoutDuration:      max(a.LockoutDuration, b.LockoutDuration),
+	}
+}
+
+func minNonZero[T int | time.Duration](a, b T) T {
+	if a == 0 {
+		return b
+	}
+	if b == 0 {
+		return a
+	}
+	return min(a, b)
+}
+
+// EffectiveLockoutAttempts returns LockoutAttempts, or its default.
+func (p Policy) EffectiveLockoutAttempts() int {
+	if p.LockoutAttempts == 0 {
+		return DefaultLockoutAttempts
+	}
+	return p.LockoutAttempts
+}
+
+// EffectiveLockoutDuration returns LockoutDuration, or its default.
+func (p Policy) EffectiveLockoutDuration() time.Duration {
+	if p.LockoutDuration == 0 {
+		return DefaultLockoutDuration
+	}
+	return p.LockoutDuration
+}
+
+// Expired returns whether a password last set at updateTime must be reset.
+func (p Policy) Expired(updateTime, now time.Time) bool {
+	if p.MaxAge == 0 {
+		return false
+	}
+	return now.Sub(updateTime) > p.MaxAge
+}
+
+// ViolationError describes how a password does not satisfy a policy. Its
+// message is suitable for displaying to end users.
+type ViolationError struct {
+	Reason string
+}
+
+func (e *ViolationError) Error() string {
+	return e.Reason
+}
+
+type CheckRequest struct {
+	Password string
+
+	// Email and OrganizationDisplayName are used to enforce
+	// DisallowPersonalInfo.
+	Email                   string
+	OrganizationDisplayName string
+
+	// PreviousPasswordBcrypts are the bcrypt hashes of the user's previous
+	// passwords, most recent first. They are used to enforce HistoryCount.
+	PreviousPasswordBcrypts []string
+}
+
+// Check returns a *ViolationError if req.Password does not satisfy p.
+func (p Policy) Check(req *CheckRequest) error {
+	if len([]rune(req.Password)) < p.MinLength {
+		return &ViolationError{Reason: fmt.Sprintf("Password must be at least %d characters long.", p.MinLength)}
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSymbol bool
+	for _, r := range req.Password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireLowercase && !hasLower {
+		return &ViolationError{Reason: "Password must contain a lowercase letter."}
+	}
+	if p.RequireUppercase && !hasUpper {
+		return &ViolationError{Reason: "Password must contain an uppercase letter."}
+	}
+	if p.RequireDigit && !hasDigit {
+		return &ViolationError{Reason: "Password must contain a digit."}
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return &ViolationError{Reason: "Password must contain a symbol."}
+	}
+
+	if p.DisallowPersonalInfo && containsPersonalInfo(req) {
+		return &ViolationError{Reason: "Password must not contain your email address or organization name."}
+	}
+
+	previous := req.PreviousPasswordBcrypts
+	if len(previous) > p.HistoryCount {
+		previous = previous[:p.HistoryCount]
+	}
+	for _, passwordBcrypt := range previous {
+		if bcrypt.CompareHashAndPassword([]byte(passwordBcrypt), []byte(req.Password)) == nil {
+			return &ViolationError{Reason: fmt.Sprintf("Password must not be one of your last %d passwords.", p.HistoryCount)}
+		}
+	}
+
+	return nil
+}
+
+// personalInfoMinLength is the shortest piece of personal information
+// DisallowPersonalInfo looks for. Shorter strings are too likely to appear
+// in a password by coincidence.
+const personalInfoMinLength = 3
+
+func containsPersonalInfo(req *CheckRequest) bool {
+	password := strings.ToLower(req.Password)
+
+	var infos []string
+	if req.Email != "" {
+		email := strings.ToLower(req.Email)
+		localPart, _, _ := strings.Cut(email, "@")
+		infos = append(infos, email, localPart)
+	}
+	if req.OrganizationDisplayName != "" {
+		infos = append(infos, strings.ToLower(req.OrganizationDisplayName))
+	}
+
+	for _, info := range infos {
+		if len(info) >= personalInfoMinLength && strings.Contains(password, info) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package passwordpolicy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tesseral-labs/tesseral/internal/passwordpolicy"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	oldBcrypt, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	olderBcrypt, err := bcrypt.GenerateFromPassword([]byte("older-password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		policy   passwordpolicy.Policy
+		password string
+		wantErr  bool
+	}{
+		{"zero policy", passwordpolicy.Policy{}, "a", false},
+		{"min length ok", passwordpolicy.Policy{MinLength: 8}, "abcdefgh", false},
+		{"min length too short", passwordpolicy.Policy{MinLength: 8}, "abcdefg", true},
+		{"min length counts characters", passwordpolicy.Policy{MinLength: 4}, "ééé", true},
+		{"require lowercase ok", passwordpolicy.Policy{RequireLowercase: true}, "ABCd", false},
+		{"require lowercase missing", passwordpolicy.Policy{RequireLowercase: true}, "ABCD", true},
+		{"require uppercase ok", passwordpolicy.Policy{RequireUppercase: true}, "abcD", false},
+		{"require uppercase missing", passwordpolicy.Policy{RequireUppercase: true}, "abcd", true},
+		{"require digit ok", passwordpolicy.Policy{RequireDigit: true}, "abc1", false},
+		{"require digit missing", passwordpolicy.Policy{RequireDigit: true}, "abcd", true},
+		{"require symbol ok", passwordpolicy.Policy{RequireSymbol: true}, "abc!", false},
+		{"require symbol missing", passwordpolicy.Policy{RequireSymbol: true}, "abc1", true},
+		{"require symbol ignores spaces", passwordpolicy.Policy{RequireSymbol: true}, "abc d", true},
+		{"personal info ok", passwordpolicy.Policy{DisallowPersonalInfo: true}, "correct horse", false},
+		{"personal info email", passwordpolicy.Policy{DisallowPersonalInfo: true}, "x-JOHN.DOE@example.com", true},
+		{"personal info local part", passwordpolicy.Policy{DisallowPersonalInfo: true}, "john.doe123", true},
+		{"personal info organization", passwordpolicy.Policy{DisallowPersonalInfo: true}, "acmecorp!", true},
+		{"history ok", passwordpolicy.Policy{HistoryCount: 2}, "new-password", false},
+		{"history reused", passwordpolicy.Policy{HistoryCount: 2}, "older-password", true},
+		{"history beyond count", passwordpolicy.Policy{HistoryCount: 1}, "older-password", false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(&passwordpolicy.CheckRequest{
+				Password:                tt.password,
+				Email:                   "john.doe@example.com",
+				OrganizationDisplayName: "AcmeCorp",
+				PreviousPasswordBcrypts: []string{string(oldBcrypt), string(olderBcrypt)},
+			})
+			if tt.wantErr {
+				var violation *passwordpolicy.ViolationError
+				assert.ErrorAs(t, err, &violation)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	assert.NoError(t, passwordpolicy.Policy{}.Validate())
+	assert.NoError(t, passwordpolicy.Policy{
+		MinLength:       128,
+		HistoryCount:    24,
+		MaxAge:          time.Hour * 24 * 3650,
+		LockoutAttempts: 100,
+		LockoutDuration: time.Hour * 24,
+	}.Validate())
+
+	assert.Error(t, passwordpolicy.Policy{MinLength: -1}.Validate())
+	assert.Error(t, passwordpolicy.Policy{MinLength: 129}.Validate())
+	assert.Error(t, passwordpolicy.Policy{HistoryCount: 25}.Validate())
+	assert.Error(t, passwordpolicy.Policy{MaxAge: time.Hour * 24 * 3651}.Validate())
+	assert.Error(t, passwordpolicy.Policy{LockoutAttempts: 101}.Validate())
+	assert.Error(t, passwordpolicy.Policy{LockoutDuration: time.Hour*24 + time.Second}.Validate())
+}
+
+func TestStricter(t *testing.T) {
+	project := passwordpolicy.Policy{
+		MinLength:       8,
+		RequireDigit:    true,
+		HistoryCount:    3,
+		MaxAge:          time.Hour * 24 * 90,
+		LockoutAttempts: 5,
+	}
+	organization := passwordpolicy.Policy{
+		MinLength:       12,
+		RequireSymbol:   true,
+		HistoryCount:    1,
+		LockoutAttempts: 3,
+		LockoutDuration: time.Hour,
+	}
+
+	assert.Equal(t, passwordpolicy.Policy{
+		MinLength:       12,
+		RequireDigit:    true,
+		RequireSymbol:   true,
+		HistoryCount:    3,
+		MaxAge:          time.Hour * 24 * 90,
+		LockoutAttempts: 3,
+		LockoutDuration: time.Hour,
+	}, passwordpolicy.Stricter(project, organization))
+}
+
+func TestPolicy_Lockout(t *testing.T) {
+	var p passwordpolicy.Policy
+	assert.Equal(t, passwordpolicy.DefaultLockoutAttempts, p.EffectiveLockoutAttempts())
+	assert.Equal(t, passwordpolicy.DefaultLockoutDuration, p.EffectiveLockoutDuration())
+
+	p = passwordpolicy.Policy{LockoutAttempts: 10, LockoutDuration: time.Hour}
+	assert.Equal(t, 10, p.EffectiveLockoutAttempts())
+	assert.Equal(t, time.Hour, p.EffectiveLockoutDuration())
+}
+
+func TestPolicy_Expired(t *testing.T) {
+	now := time.Now()
+
+	var p passwordpolicy.Policy
+	assert.False(t, p.Expired(now.Add(-time.Hour*24*365), now))
+
+	p = passwordpolicy.Policy{MaxAge: time.Hour * 24 * 30}
+	assert.False(t, p.Expired(now.Add(-time.Hour*24*29), now))
+	assert.True(t, p.Expired(now.Add(-time.Hour*24*31), now))
+}
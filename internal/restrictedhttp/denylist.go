@@ -8,13 +8,20 @@ import (
 type denyList []*net.IPNet
 
 func (d denyList) check(addr net.Addr) error {
+	if d.contains(addr) {
+		return fmt.Errorf("unauthorized attempt to connect to an address in a denied network (%s)", addr)
+	}
+	return nil
+}
+
+func (d denyList) contains(addr net.Addr) bool {
 	ip := ipAddressOf(addr)
 	for _, ipnet := range d {
 		if ipnet.Contains(ip) {
-			return fmt.Errorf("unauthorized attempt to connect to an address in a denied network (%s)", addr)
+			return true
 		}
 	}
-	return nil
+	return false
 }
 
 var (
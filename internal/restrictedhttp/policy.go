@@ -0,0 +1,223 @@
+package restrictedhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout          = 30 * time.Second
+	defaultMaxResponseBytes = 1 << 20
+)
+
+// Config is the egress policy for outbound requests to customer-supplied
+// destinations, such as webhook URLs.
+//
+// The zero value is the strictest policy: https only, no private networks, a
+// 30 second timeout, and 1 MiB responses. Self-hosters that run Tesseral inside
+// a private network can loosen it with allowlists.
+type Config struct {
+	// AllowHTTP permits plain http URLs.
+	AllowHTTP bool `conf:"allow_http,noredact"`
+
+	// AllowedCIDRs is a comma-separated list of networks, such as
+	// "10.1.0.0/16", that may be connected to even though they are private.
+	AllowedCIDRs string `conf:"allowed_cidrs,noredact"`
+
+	// AllowedHosts is a comma-separated list of hostnames that may be
+	// connected to regardless of the addresses they resolve to.
+	AllowedHosts string `conf:"allowed_hosts,noredact"`
+
+	// ProxyURL, if set, is an HTTP proxy that requests are sent through. The
+	// proxy is always connected to, even if it is in a private network; it is
+	// responsible for filtering the destinations it connects to.
+	ProxyURL string `conf:"proxy_url"`
+
+	// Timeout is the maximum duration of a request, including reading its
+	// response. Defaults to 30 seconds.
+	Timeout time.Duration `conf:"timeout,noredact"`
+
+	// MaxResponseBytes is the largest response body that will be read.
+	// Defaults to 1 MiB.
+	MaxResponseBytes int64 `conf:"max_response_bytes,noredact"`
+}
+
+// Policy enforces a Config on outbound connections and requests.
+type Policy struct {
+	allowHTTP        bool
+	proxyURL         *url.URL
+	timeout          time.Duration
+	maxResponseBytes int64
+	dialer           *restrictedDialer
+}
+
+func NewPolicy(config Config) (*Policy, error) {
+	var allowList denyList
+	for _, s := range splitList(config.AllowedCIDRs) {
+		_, ipnet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse allowed cidr %q: %w", s, err)
+		}
+		allowList = append(allowList, ipnet)
+	}
+
+	allowedHosts := splitList(config.AllowedHosts)
+
+	var proxyURL *url.URL
+	if config.ProxyURL != "" {
+		u, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy url: %w", err)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("proxy url has no host: %q", config.ProxyURL)
+		}
+
+		proxyURL = u
+		allowedHosts = append(allowedHosts, u.Hostname())
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	maxResponseBytes := config.MaxResponseBytes
+	if maxResponseBytes == 0 {
+		maxResponseBytes = defaultMaxResponseBytes
+	}
+
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+	return &Policy{
+		allowHTTP:        config.AllowHTTP,
+		proxyURL:         proxyURL,
+		timeout:          timeout,
+		maxResponseBytes: maxResponseBytes,
+		dialer: &restrictedDialer{
+			dial:         defaultTransport.DialContext,
+			denyList:     privateIPNetworks,
+			allowList:    allowList,
+			allowedHosts: allowedHosts,
+		},
+	}, nil
+}
+
+// DialContext connects to addr, unless the policy forbids it. Use it for
+// non-HTTP connections to customer-supplied destinations.
+func (p *Policy) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := p.dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		slog.ErrorContext(ctx, "restricted_dial_error", "network", network, "addr", addr, "error", err)
+	}
+	return conn, err
+}
+
+// Client returns an HTTP client that enforces the policy.
+func (p *Policy) Client() *http.Client {
+	proxy := http.ProxyFromEnvironment
+	if p.proxyURL != nil {
+		proxy = http.ProxyURL(p.proxyURL)
+	}
+
+	return &http.Client{
+		Timeout: p.timeout,
+		Transport: &policyRoundTripper{
+			policy: p,
+			next: &http.Transport{
+				Proxy:                 proxy,
+				DialContext:           p.DialContext,
+				ForceAttemptHTTP2:     true,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+			},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return p.checkScheme(req.URL)
+		},
+	}
+}
+
+func (p *Policy) checkScheme(u *url.URL) error {
+	if u.Scheme == "https" || (p.allowHTTP && u.Scheme == "http") {
+		return nil
+	}
+	return fmt.Errorf("unauthorized attempt to make a request to a non-https url: %s", u.Redacted())
+}
+
+type policyRoundTripper struct {
+	policy *Policy
+	next   http.RoundTripper
+}
+
+func (t *policyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.checkScheme(req.URL); err != nil {
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.ContentLength > t.policy.maxResponseBytes {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("response body of %d bytes exceeds limit of %d bytes", res.ContentLength, t.policy.maxResponseBytes)
+	}
+
+	res.Body = &limitedBody{
+		ReadCloser: res.Body,
+		remaining:  t.policy.maxResponseBytes,
+		limit:      t.policy.maxResponseBytes,
+	}
+	return res, nil
+}
+
+// limitedBody is like io.LimitReader, except that it returns an error rather
+// than io.EOF when the limit is exceeded, so that callers don't mistake a
+// truncated body for a complete one.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, fmt.Errorf("response body exceeds limit of %d bytes", b.limit)
+	}
+
+	// read one byte past the limit, to detect bodies that exceed it
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n - 1, fmt.Errorf("response body exceeds limit of %d bytes", b.limit)
+	}
+	return n, err
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return slices.Clip(items)
+}
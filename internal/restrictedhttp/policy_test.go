@@ -0,0 +1,104 @@
+package restrictedhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+
+		// flushing forces a chunked response, without a Content-Length
+		if r.URL.Query().Has("chunked") {
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("https is required by default", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := NewPolicy(Config{AllowedCIDRs: "127.0.0.0/8"})
+		require.NoError(t, err)
+
+		_, err = policy.Client().Get(srv.URL)
+		require.ErrorContains(t, err, "non-https url")
+	})
+
+	t.Run("private networks are denied by default", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := NewPolicy(Config{AllowHTTP: true})
+		require.NoError(t, err)
+
+		_, err = policy.Client().Get(srv.URL)
+		require.ErrorContains(t, err, "denied network")
+	})
+
+	t.Run("allowed cidrs may be connected to", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := NewPolicy(Config{AllowHTTP: true, AllowedCIDRs: "10.0.0.0/8, 127.0.0.0/8"})
+		require.NoError(t, err)
+
+		res, err := policy.Client().Get(srv.URL)
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Len(t, body, 100)
+	})
+
+	t.Run("allowed hosts may be connected to", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := NewPolicy(Config{AllowHTTP: true, AllowedHosts: "127.0.0.1"})
+		require.NoError(t, err)
+
+		res, err := policy.Client().Get(srv.URL)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	})
+
+	t.Run("responses with a large content length are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := NewPolicy(Config{AllowHTTP: true, AllowedCIDRs: "127.0.0.0/8", MaxResponseBytes: 10})
+		require.NoError(t, err)
+
+		_, err = policy.Client().Get(srv.URL)
+		require.ErrorContains(t, err, "exceeds limit of 10 bytes")
+	})
+
+	t.Run("large chunked responses fail to read", func(t *testing.T) {
+		t.Parallel()
+
+		policy, err := NewPolicy(Config{AllowHTTP: true, AllowedCIDRs: "127.0.0.0/8", MaxResponseBytes: 150})
+		require.NoError(t, err)
+
+		res, err := policy.Client().Get(srv.URL + "/?chunked")
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+
+		body, err := io.ReadAll(res.Body)
+		require.ErrorContains(t, err, "exceeds limit of 150 bytes")
+		require.Len(t, body, 150)
+	})
+}
+
+func TestNewPolicy_InvalidCIDR(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewPolicy(Config{AllowedCIDRs: "10.0.0.0"})
+	require.Error(t, err)
+}
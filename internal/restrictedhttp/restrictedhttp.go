@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"slices"
 	"time"
 )
 
@@ -39,14 +40,24 @@ func NewTransport() *http.Transport {
 type restrictedDialer struct {
 	dial     func(ctx context.Context, network, addr string) (net.Conn, error)
 	denyList denyList
+
+	// allowList takes precedence over denyList.
+	allowList denyList
+
+	// allowedHosts are dialed without any checks.
+	allowedHosts []string
 }
 
 func (d *restrictedDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
-	host, _, err := net.SplitHostPort(addr)
+	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to split host and port from '%s': %w", addr, err)
 	}
 
+	if slices.Contains(d.allowedHosts, host) {
+		return d.dial(ctx, network, addr)
+	}
+
 	var ipAddr *net.IPAddr
 	if ip := net.ParseIP(host); ip != nil {
 		ipAddr = &net.IPAddr{IP: ip}
@@ -71,17 +82,21 @@ func (d *restrictedDialer) DialContext(ctx context.Context, network, addr string
 		ipAddr = &addrs[0]
 	}
 
-	if err := d.denyList.check(ipAddr); err != nil {
-		return nil, &net.OpError{
-			Op:   "dial",
-			Net:  network,
-			Addr: ipAddr,
-			Err: &net.AddrError{
-				Err:  err.Error(),
-				Addr: addr,
-			},
+	if !d.allowList.contains(ipAddr) {
+		if err := d.denyList.check(ipAddr); err != nil {
+			return nil, &net.OpError{
+				Op:   "dial",
+				Net:  network,
+				Addr: ipAddr,
+				Err: &net.AddrError{
+					Err:  err.Error(),
+					Addr: addr,
+				},
+			}
 		}
 	}
 
-	return d.dial(ctx, network, addr)
+	// dial the address that was checked, rather than resolving host again, so
+	// that a DNS record can't change in between
+	return d.dial(ctx, network, net.JoinHostPort(ipAddr.IP.String(), port))
 }
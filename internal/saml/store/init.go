@@ -46,7 +46,7 @@ func (s *Store) GetSAMLConnectionInitData(ctx context.Context, samlConnectionID
 		return nil, fmt.Errorf("get audit saml connection: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.saml_connections.initiate",
 		EventDetails: &auditlogv1.InitiateSAMLConnection{
 			SamlConnection: auditSamlConnection,
@@ -6,6 +6,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
 	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
 	"github.com/tesseral-labs/tesseral/internal/saml/store/queries"
 )
@@ -14,11 +15,13 @@ type Store struct {
 	db            *pgxpool.Pool
 	q             *queries.Queries
 	auditlogStore *auditlogstore.Store
+	riverClient   *river.Client[pgx.Tx]
 }
 
 type NewStoreParams struct {
 	DB            *pgxpool.Pool
 	AuditlogStore *auditlogstore.Store
+	RiverClient   *river.Client[pgx.Tx]
 }
 
 func New(p NewStoreParams) *Store {
@@ -26,6 +29,7 @@ func New(p NewStoreParams) *Store {
 		db:            p.DB,
 		q:             queries.New(p.DB),
 		auditlogStore: p.AuditlogStore,
+		riverClient:   p.RiverClient,
 	}
 
 	return store
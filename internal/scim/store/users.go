@@ -176,7 +176,7 @@ func (s *Store) CreateUser(ctx context.Context, user User) (User, error) {
 		return nil, fmt.Errorf("get user for audit log: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.create",
 		EventDetails: &auditlogv1.CreateUser{
 			User: auditUser,
@@ -188,6 +188,10 @@ func (s *Store) CreateUser(ctx context.Context, user User) (User, error) {
 		return nil, fmt.Errorf("log audit event: %w", err)
 	}
 
+	if err := s.assignDefaultRoles(ctx, tx, q, qUser); err != nil {
+		return nil, fmt.Errorf("assign default roles: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
@@ -249,7 +253,7 @@ func (s *Store) UpdateUser(ctx context.Context, id string, user User) (User, err
 		return nil, fmt.Errorf("get user for audit log: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.update",
 		EventDetails: &auditlogv1.UpdateUser{
 			PreviousUser: auditPreviousUser,
@@ -364,7 +368,7 @@ func (s *Store) PatchUser(ctx context.Context, id string, operations PatchOperat
 		return nil, fmt.Errorf("get user for audit log: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.update",
 		EventDetails: &auditlogv1.UpdateUser{
 			PreviousUser: auditPreviousUser,
@@ -418,6 +422,10 @@ func (s *Store) DeleteUser(ctx context.Context, id string) (User, error) {
 		return nil, fmt.Errorf("get user for audit log: %w", err)
 	}
 
+	if err := s.revokeUserAPIKeys(ctx, tx, q, qUser); err != nil {
+		return nil, err
+	}
+
 	if _, err := q.DeleteUser(ctx, queries.DeleteUserParams{
 		ID:             userID,
 		OrganizationID: authn.OrganizationID(ctx),
@@ -425,7 +433,7 @@ func (s *Store) DeleteUser(ctx context.Context, id string) (User, error) {
 		return nil, fmt.Errorf("delete user: %w", err)
 	}
 
-	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+	if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
 		EventName: "tesseral.users.delete",
 		EventDetails: &auditlogv1.DeleteUser{
 			User: auditUser,
@@ -537,3 +545,48 @@ func jsonify(t any) map[string]any {
 	}
 	return v
 }
+
+// revokeUserAPIKeys revokes the personal API keys of qUser, logging an audit
+// event for each. It is called before deleting a user: deletion cascades to
+// the keys, but would leave no record of them being revoked.
+func (s *Store) revokeUserAPIKeys(ctx context.Context, tx pgx.Tx, q *queries.Queries, qUser queries.User) error {
+	apiKeyIDs, err := q.ListUnrevokedUserAPIKeyIDs(ctx, &qUser.ID)
+	if err != nil {
+		return fmt.Errorf("list unrevoked user api key ids: %w", err)
+	}
+
+	var auditPreviousAPIKeys []*auditlogv1.APIKey
+	for _, apiKeyID := range apiKeyIDs {
+		auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+		if err != nil {
+			return fmt.Errorf("get audit log api key: %w", err)
+		}
+		auditPreviousAPIKeys = append(auditPreviousAPIKeys, auditPreviousAPIKey)
+	}
+
+	if err := q.RevokeUserAPIKeys(ctx, &qUser.ID); err != nil {
+		return fmt.Errorf("revoke user api keys: %w", err)
+	}
+
+	for i, apiKeyID := range apiKeyIDs {
+		auditAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, apiKeyID)
+		if err != nil {
+			return fmt.Errorf("get audit log api key: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, tx, logAuditEventParams{
+			EventName: "tesseral.api_keys.revoke",
+			EventDetails: &auditlogv1.RevokeAPIKey{
+				ApiKey:         auditAPIKey,
+				PreviousApiKey: auditPreviousAPIKeys[i],
+			},
+			OrganizationID: &qUser.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeApiKey,
+			ResourceID:     &apiKeyID,
+		}); err != nil {
+			return fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	return nil
+}
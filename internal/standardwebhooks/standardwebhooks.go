@@ -0,0 +1,82 @@
+// Package standardwebhooks signs webhooks in the format described by the
+// Standard Webhooks specification.
+//
+// See: https://github.com/standard-webhooks/standard-webhooks/blob/main/spec/standard-webhooks.md
+package standardwebhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderID        = "webhook-id"
+	HeaderTimestamp = "webhook-timestamp"
+	HeaderSignature = "webhook-signature"
+)
+
+const secretPrefix = "whsec_"
+
+// secretSize is the number of random bytes in a new secret. The spec requires
+// between 24 and 64.
+const secretSize = 32
+
+// NewSecret returns a new random signing secret.
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("read random bytes: %w", err)
+	}
+	return secret, nil
+}
+
+// FormatSecret returns secret in the `whsec_...` form receivers expect.
+func FormatSecret(secret []byte) string {
+	return secretPrefix + base64.StdEncoding.EncodeToString(secret)
+}
+
+// ParseSecret parses a secret in the `whsec_...` form.
+func ParseSecret(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, secretPrefix) {
+		return nil, fmt.Errorf("secret does not start with %q", secretPrefix)
+	}
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, secretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("decode secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Sign returns the `v1,...` signature of a message under secret.
+func Sign(secret []byte, id string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = fmt.Fprintf(mac, "%s.%d.", id, timestamp.Unix())
+	_, _ = mac.Write(body)
+	return "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders sets the webhook-id, webhook-timestamp, and webhook-signature
+// headers on h. The message is signed with each of secrets, so that receivers
+// holding any one of them can verify it; this allows secrets to be rotated
+// without downtime. If secrets is empty, no webhook-signature is set.
+func SetHeaders(h http.Header, secrets [][]byte, id string, timestamp time.Time, body []byte) {
+	h.Set(HeaderID, id)
+	h.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+
+	if len(secrets) == 0 {
+		return
+	}
+
+	var signatures []string
+	for _, secret := range secrets {
+		signatures = append(signatures, Sign(secret, id, timestamp, body))
+	}
+	h.Set(HeaderSignature, strings.Join(signatures, " "))
+}
@@ -0,0 +1,56 @@
+package standardwebhooks
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	// test vector from the Standard Webhooks reference implementations
+	secret, err := ParseSecret("whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw")
+	require.NoError(t, err)
+
+	signature := Sign(secret, "msg_p5jXN8AQM9LWM0D4loKWxJek", time.Unix(1614265330, 0), []byte(`{"test": 2432232314}`))
+	assert.Equal(t, "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=", signature)
+}
+
+func TestFormatSecret(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+
+	s := FormatSecret(secret)
+	assert.True(t, strings.HasPrefix(s, "whsec_"))
+
+	parsed, err := ParseSecret(s)
+	require.NoError(t, err)
+	assert.Equal(t, secret, parsed)
+
+	_, err = ParseSecret("not-a-secret")
+	assert.Error(t, err)
+}
+
+func TestSetHeaders(t *testing.T) {
+	secret1, err := NewSecret()
+	require.NoError(t, err)
+	secret2, err := NewSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1614265330, 0)
+	body := []byte(`{}`)
+
+	h := http.Header{}
+	SetHeaders(h, [][]byte{secret1, secret2}, "msg_1", now, body)
+	assert.Equal(t, "msg_1", h.Get(HeaderID))
+	assert.Equal(t, "1614265330", h.Get(HeaderTimestamp))
+	assert.Equal(t, Sign(secret1, "msg_1", now, body)+" "+Sign(secret2, "msg_1", now, body), h.Get(HeaderSignature))
+
+	h = http.Header{}
+	SetHeaders(h, nil, "msg_1", now, body)
+	assert.Equal(t, "msg_1", h.Get(HeaderID))
+	assert.Empty(t, h.Get(HeaderSignature))
+}
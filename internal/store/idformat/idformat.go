@@ -26,6 +26,8 @@ var (
 	PasswordResetCode             = prettyuuid.MustNewFormat("password_reset_code_", alphabet)
 	Role                          = prettyuuid.MustNewFormat("role_", alphabet)
 	UserRoleAssignment            = prettyuuid.MustNewFormat("user_role_assignment_", alphabet)
+	Relationship                  = prettyuuid.MustNewFormat("relationship_", alphabet)
+	RoleElevationRequest          = prettyuuid.MustNewFormat("role_elevation_request_", alphabet)
 
 	IntermediateSessionSecretToken = prettyuuid.MustNewFormat("tesseral_secret_intermediate_session_token_", alphabet)
 
@@ -46,7 +48,13 @@ var (
 	ProjectUISettings  = prettyuuid.MustNewFormat("project_ui_settings_", alphabet)
 
 	ProjectWebhookSettings = prettyuuid.MustNewFormat("project_webhook_settings_", alphabet)
+	WebhookEndpoint        = prettyuuid.MustNewFormat("webhook_endpoint_", alphabet)
+	WebhookDelivery        = prettyuuid.MustNewFormat("webhook_delivery_", alphabet)
 	AuditLogEvent          = prettyuuid.MustNewFormat("audit_log_event_", alphabet)
+	AuditLogStream         = prettyuuid.MustNewFormat("audit_log_stream_", alphabet)
+	EmailTemplate          = prettyuuid.MustNewFormat("email_template_", alphabet)
+	EmailEvent             = prettyuuid.MustNewFormat("email_event_", alphabet)
+	EmailSuppression       = prettyuuid.MustNewFormat("email_suppression_", alphabet)
 
 	OIDCConnection = prettyuuid.MustNewFormat("oidc_connection_", alphabet)
 )
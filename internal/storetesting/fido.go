@@ -0,0 +1,39 @@
+package storetesting
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tesseral-labs/tesseral/internal/webauthn"
+)
+
+// NewFIDOMetadata returns FIDO metadata that lists an authenticator for each
+// of aaguids, so that stores can be tested with passkey policies that require
+// trusted attestations.
+func NewFIDOMetadata(aaguids ...string) *webauthn.Metadata {
+	var entries []map[string]any
+	for _, aaguid := range aaguids {
+		entries = append(entries, map[string]any{
+			"aaguid": aaguid,
+			"metadataStatement": map[string]any{
+				"description": fmt.Sprintf("Test Authenticator %s", aaguid),
+			},
+		})
+	}
+
+	payload, err := json.Marshal(map[string]any{"entries": entries})
+	if err != nil {
+		panic(fmt.Errorf("marshal fido metadata: %w", err))
+	}
+
+	// ParseMetadata does not verify the blob's signature, so the header and
+	// signature are placeholders
+	blob := "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".c2ln"
+	metadata, err := webauthn.ParseMetadata([]byte(blob))
+	if err != nil {
+		panic(fmt.Errorf("parse fido metadata: %w", err))
+	}
+
+	return metadata
+}
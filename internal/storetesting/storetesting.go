@@ -41,6 +41,8 @@ func NewEnvironment() (*Environment, func()) {
 
 	riverWorkers := river.NewWorkers()
 	river.AddWorker(riverWorkers, &webhookworker.Worker{})
+	river.AddWorker(riverWorkers, &webhookworker.TypedEventWorker{})
+	river.AddWorker(riverWorkers, &webhookworker.OrderedWorker{})
 	riverClient, err := river.NewClient(riverpgxv5.New(db), &river.Config{
 		Middleware: []rivertype.Middleware{
 			otelriver.NewMiddleware(nil),
@@ -130,8 +132,8 @@ INSERT INTO organizations (id, display_name, project_id, log_in_with_google, log
 
 	// Create the project with the new vault domain
 	_, err = e.DB.Exec(t.Context(), `
-INSERT INTO projects (id, organization_id, display_name, log_in_with_google, log_in_with_microsoft, log_in_with_github, log_in_with_email, log_in_with_password, log_in_with_saml, log_in_with_oidc, log_in_with_authenticator_app, log_in_with_passkey, vault_domain, email_send_from_domain, redirect_uri, cookie_domain, api_keys_enabled, api_key_secret_token_prefix, entitled_backend_api_keys, entitled_custom_vault_domains, audit_logs_enabled)
-  VALUES ($1::uuid, $2::uuid, $3, true, true, true, true, true, true, true, true, true, $4, $4, $4, $4, true, 'test_sk_', true, true, true);
+INSERT INTO projects (id, organization_id, display_name, log_in_with_google, log_in_with_microsoft, log_in_with_github, log_in_with_email, log_in_with_password, log_in_with_saml, log_in_with_oidc, log_in_with_authenticator_app, log_in_with_passkey, log_in_with_passwordless_passkey, vault_domain, email_send_from_domain, redirect_uri, cookie_domain, api_keys_enabled, api_key_secret_token_prefix, entitled_backend_api_keys, entitled_custom_vault_domains, audit_logs_enabled)
+  VALUES ($1::uuid, $2::uuid, $3, true, true, true, true, true, true, true, true, true, true, $4, $4, $4, $4, true, 'test_sk_', true, true, true);
 `,
 		projectID.String(),
 		organizationID.String(),
@@ -213,8 +215,8 @@ func (e *Environment) NewOrganization(t *testing.T, projectID string, organizati
 
 	// Create the organization
 	_, err = e.DB.Exec(t.Context(), `
-INSERT INTO organizations (id, display_name, project_id, log_in_with_google, log_in_with_microsoft, log_in_with_email, log_in_with_password, log_in_with_saml, log_in_with_oidc, log_in_with_authenticator_app, log_in_with_passkey, scim_enabled, api_keys_enabled, custom_roles_enabled)
-  VALUES ($1::uuid, $2, $3::uuid, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+INSERT INTO organizations (id, display_name, project_id, log_in_with_google, log_in_with_microsoft, log_in_with_email, log_in_with_password, log_in_with_saml, log_in_with_oidc, log_in_with_authenticator_app, log_in_with_passkey, log_in_with_passwordless_passkey, scim_enabled, api_keys_enabled, custom_roles_enabled)
+  VALUES ($1::uuid, $2, $3::uuid, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15);
 `,
 		organizationID.String(),
 		organization.DisplayName,
@@ -227,6 +229,7 @@ INSERT INTO organizations (id, display_name, project_id, log_in_with_google, log
 		organization.GetLogInWithOidc(),
 		organization.GetLogInWithAuthenticatorApp(),
 		organization.GetLogInWithPasskey(),
+		organization.GetLogInWithPasswordlessPasskey(),
 		organization.GetScimEnabled(),
 		organization.GetApiKeysEnabled(),
 		organization.GetCustomRolesEnabled(),
@@ -0,0 +1,450 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// COSE algorithm identifiers.
+//
+// See: https://www.iana.org/assignments/cose/cose.xhtml#algorithms
+const (
+	algES256 = -7
+	algEdDSA = -8
+	algES384 = -35
+	algES512 = -36
+	algPS256 = -37
+	algRS256 = -257
+	algRS384 = -258
+	algRS512 = -259
+	algRS1   = -65535
+)
+
+type attestationStatement struct {
+	Alg      int64    `cbor:"alg"`
+	Sig      []byte   `cbor:"sig"`
+	X5C      [][]byte `cbor:"x5c"`
+	Ver      string   `cbor:"ver"`
+	CertInfo []byte   `cbor:"certInfo"`
+	PubArea  []byte   `cbor:"pubArea"`
+}
+
+// Attestation is the result of verifying a credential's attestation
+// statement.
+type Attestation struct {
+	// Format is the attestation statement format, e.g. "packed".
+	Format string
+
+	// Trusted is whether the attestation is signed by a certificate that
+	// chains to a FIDO Metadata Service root for the authenticator, and the
+	// authenticator has not been reported as compromised.
+	Trusted bool
+
+	// Description is the authenticator's description in the FIDO Metadata
+	// Service, if any.
+	Description string
+}
+
+type VerifyAttestationRequest struct {
+	ClientDataJSON string
+	Metadata       *Metadata
+}
+
+// VerifyAttestation verifies the attestation statement that accompanied c
+// when it was registered.
+//
+// Supported formats are "none", "packed", "fido-u2f", "tpm", and "apple". An
+// attestation statement that is present but invalid is an error. A valid
+// attestation that does not chain to a known root is not an error, but is
+// not Trusted.
+func (c *Credential) VerifyAttestation(req *VerifyAttestationRequest) (*Attestation, error) {
+	if c.AttestationFormat == "none" {
+		return &Attestation{Format: c.AttestationFormat}, nil
+	}
+
+	clientDataBytes, err := base64.RawURLEncoding.DecodeString(req.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var clientData struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(clientDataBytes, &clientData); err != nil {
+		return nil, err
+	}
+
+	if clientData.Type != "webauthn.create" {
+		return nil, fmt.Errorf("invalid client data type")
+	}
+
+	clientDataHash := sha256.Sum256(clientDataBytes)
+
+	var certs []*x509.Certificate
+	for _, der := range c.attStmt.X5C {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("parse x5c certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	switch c.AttestationFormat {
+	case "packed":
+		err = c.verifyPacked(clientDataHash[:], certs)
+	case "fido-u2f":
+		err = c.verifyFIDOU2F(clientDataHash[:], certs)
+	case "tpm":
+		err = c.verifyTPM(clientDataHash[:], certs)
+	case "apple":
+		err = c.verifyApple(clientDataHash[:], certs)
+	default:
+		return nil, fmt.Errorf("unsupported attestation format: %q", c.AttestationFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("verify %s attestation: %w", c.AttestationFormat, err)
+	}
+
+	attestation := &Attestation{Format: c.AttestationFormat}
+
+	// self attestation, or a metadata-less deployment, cannot be trusted
+	if len(certs) == 0 || req.Metadata == nil {
+		return attestation, nil
+	}
+
+	entry := req.Metadata.lookup(c.AAGUID, certs[0])
+	if entry == nil {
+		return attestation, nil
+	}
+
+	attestation.Description = entry.description
+	if entry.compromised {
+		return attestation, nil
+	}
+
+	roots := x509.NewCertPool()
+	for _, root := range entry.roots {
+		roots.AddCert(root)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return attestation, nil
+	}
+
+	attestation.Trusted = true
+	return attestation, nil
+}
+
+// https://www.w3.org/TR/webauthn-2/#sctn-packed-attestation
+func (c *Credential) verifyPacked(clientDataHash []byte, certs []*x509.Certificate) error {
+	signed := slices.Concat(c.authData, clientDataHash)
+
+	// self attestation
+	if len(certs) == 0 {
+		if c.attStmt.Alg != c.alg {
+			return fmt.Errorf("self attestation alg does not match credential alg")
+		}
+		return verifySignature(c.PublicKey, c.attStmt.Alg, signed, c.attStmt.Sig)
+	}
+
+	attestnCert := certs[0]
+	if err := verifySignature(attestnCert.PublicKey, c.attStmt.Alg, signed, c.attStmt.Sig); err != nil {
+		return err
+	}
+
+	if attestnCert.Version != 3 {
+		return fmt.Errorf("attestation certificate must be version 3")
+	}
+
+	if !slices.Equal(attestnCert.Subject.OrganizationalUnit, []string{"Authenticator Attestation"}) {
+		return fmt.Errorf("attestation certificate has invalid subject organizational unit")
+	}
+
+	if attestnCert.IsCA {
+		return fmt.Errorf("attestation certificate must not be a ca")
+	}
+
+	return c.checkCertificateAAGUID(attestnCert)
+}
+
+// https://www.w3.org/TR/webauthn-2/#sctn-fido-u2f-attestation
+func (c *Credential) verifyFIDOU2F(clientDataHash []byte, certs []*x509.Certificate) error {
+	if len(certs) != 1 {
+		return fmt.Errorf("fido-u2f attestation must have exactly one certificate")
+	}
+
+	certPub, ok := certs[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok || certPub.Curve != elliptic.P256() {
+		return fmt.Errorf("fido-u2f attestation certificate must have a p-256 public key")
+	}
+
+	credPub, ok := c.PublicKey.(*ecdsa.PublicKey)
+	if !ok || credPub.Curve != elliptic.P256() {
+		return fmt.Errorf("fido-u2f credential must have a p-256 public key")
+	}
+
+	rpIDHash := c.authData[:32]
+	publicKeyU2F := slices.Concat([]byte{0x04}, credPub.X.FillBytes(make([]byte, 32)), credPub.Y.FillBytes(make([]byte, 32)))
+
+	signed := slices.Concat([]byte{0x00}, rpIDHash, clientDataHash, c.ID, publicKeyU2F)
+	return verifySignature(certPub, algES256, signed, c.attStmt.Sig)
+}
+
+// https://www.w3.org/TR/webauthn-2/#sctn-tpm-attestation
+func (c *Credential) verifyTPM(clientDataHash []byte, certs []*x509.Certificate) error {
+	if c.attStmt.Ver != "2.0" {
+		return fmt.Errorf("unsupported tpm version: %q", c.attStmt.Ver)
+	}
+
+	if len(certs) == 0 {
+		return fmt.Errorf("tpm attestation must have a certificate")
+	}
+
+	pubArea, err := parseTPMPublic(c.attStmt.PubArea)
+	if err != nil {
+		return fmt.Errorf("parse pubArea: %w", err)
+	}
+
+	if !pubArea.matches(c.PublicKey) {
+		return fmt.Errorf("pubArea does not match credential public key")
+	}
+
+	certInfo, err := parseTPMAttest(c.attStmt.CertInfo)
+	if err != nil {
+		return fmt.Errorf("parse certInfo: %w", err)
+	}
+
+	attToBeSigned := slices.Concat(c.authData, clientDataHash)
+	hash, err := hashForAlg(c.attStmt.Alg)
+	if err != nil {
+		return err
+	}
+	extraData := hash.New()
+	extraData.Write(attToBeSigned)
+	if !bytes.Equal(certInfo.extraData, extraData.Sum(nil)) {
+		return fmt.Errorf("certInfo extraData does not match attestation")
+	}
+
+	nameHash, err := tpmAlgHash(pubArea.nameAlg)
+	if err != nil {
+		return err
+	}
+	name := nameHash.New()
+	name.Write(c.attStmt.PubArea)
+	if !bytes.Equal(certInfo.attestedName, slices.Concat(uint16Bytes(pubArea.nameAlg), name.Sum(nil))) {
+		return fmt.Errorf("certInfo attested name does not match pubArea")
+	}
+
+	aikCert := certs[0]
+	if err := verifySignature(aikCert.PublicKey, c.attStmt.Alg, c.attStmt.CertInfo, c.attStmt.Sig); err != nil {
+		return err
+	}
+
+	if aikCert.Version != 3 {
+		return fmt.Errorf("aik certificate must be version 3")
+	}
+
+	if len(aikCert.Subject.Names) != 0 {
+		return fmt.Errorf("aik certificate subject must be empty")
+	}
+
+	if !slices.ContainsFunc(aikCert.UnknownExtKeyUsage, oidTCGKPAIKCertificate.Equal) {
+		return fmt.Errorf("aik certificate must have tcg-kp-AIKCertificate extended key usage")
+	}
+
+	if aikCert.IsCA {
+		return fmt.Errorf("aik certificate must not be a ca")
+	}
+
+	return c.checkCertificateAAGUID(aikCert)
+}
+
+// https://www.w3.org/TR/webauthn-2/#sctn-apple-anonymous-attestation
+func (c *Credential) verifyApple(clientDataHash []byte, certs []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return fmt.Errorf("apple attestation must have a certificate")
+	}
+
+	credCert := certs[0]
+	nonce := sha256.Sum256(slices.Concat(c.authData, clientDataHash))
+
+	var certNonce struct {
+		Nonce []byte `asn1:"tag:1,explicit"`
+	}
+	var found bool
+	for _, ext := range credCert.Extensions {
+		if !ext.Id.Equal(oidAppleAnonymousAttestationNonce) {
+			continue
+		}
+
+		if _, err := asn1.Unmarshal(ext.Value, &certNonce); err != nil {
+			return fmt.Errorf("parse nonce extension: %w", err)
+		}
+		found = true
+	}
+
+	if !found || !bytes.Equal(certNonce.Nonce, nonce[:]) {
+		return fmt.Errorf("credential certificate nonce does not match attestation")
+	}
+
+	if !publicKeysEqual(credCert.PublicKey, c.PublicKey) {
+		return fmt.Errorf("credential certificate public key does not match credential public key")
+	}
+
+	return nil
+}
+
+var (
+	oidFIDOGenCEAAGUID                = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 45724, 1, 1, 4}
+	oidTCGKPAIKCertificate            = asn1.ObjectIdentifier{2, 23, 133, 8, 3}
+	oidAppleAnonymousAttestationNonce = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 8, 2}
+)
+
+// checkCertificateAAGUID checks that, if cert has an id-fido-gen-ce-aaguid
+// extension, it matches the credential's AAGUID.
+func (c *Credential) checkCertificateAAGUID(cert *x509.Certificate) error {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidFIDOGenCEAAGUID) {
+			continue
+		}
+
+		if ext.Critical {
+			return fmt.Errorf("id-fido-gen-ce-aaguid extension must not be critical")
+		}
+
+		var aaguidBytes []byte
+		if _, err := asn1.Unmarshal(ext.Value, &aaguidBytes); err != nil {
+			return fmt.Errorf("parse id-fido-gen-ce-aaguid extension: %w", err)
+		}
+
+		aaguid, err := uuid.FromBytes(aaguidBytes)
+		if err != nil {
+			return fmt.Errorf("parse id-fido-gen-ce-aaguid extension: %w", err)
+		}
+
+		if aaguid.String() != c.AAGUID {
+			return fmt.Errorf("attestation certificate aaguid does not match credential aaguid")
+		}
+	}
+
+	return nil
+}
+
+func verifySignature(pub crypto.PublicKey, alg int64, signed, sig []byte) error {
+	switch alg {
+	case algEdDSA:
+		pub, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key does not match alg")
+		}
+		if !ed25519.Verify(pub, signed, sig) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	}
+
+	hash, err := hashForAlg(alg)
+	if err != nil {
+		return err
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch alg {
+	case algES256, algES384, algES512:
+		pub, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key does not match alg")
+		}
+		if !ecdsa.VerifyASN1(pub, digest, sig) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	case algPS256:
+		pub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key does not match alg")
+		}
+		if err := rsa.VerifyPSS(pub, hash, digest, sig, nil); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+		return nil
+	default:
+		pub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key does not match alg")
+		}
+		if err := rsa.VerifyPKCS1v15(pub, hash, digest, sig); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+		return nil
+	}
+}
+
+func hashForAlg(alg int64) (crypto.Hash, error) {
+	switch alg {
+	case algES256, algPS256, algRS256:
+		return crypto.SHA256, nil
+	case algES384, algRS384:
+		return crypto.SHA384, nil
+	case algES512, algRS512:
+		return crypto.SHA512, nil
+	case algRS1:
+		return crypto.SHA1, nil
+	default:
+		return 0, fmt.Errorf("unsupported alg: %d", alg)
+	}
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	type equaler interface {
+		Equal(crypto.PublicKey) bool
+	}
+
+	e, ok := a.(equaler)
+	return ok && e.Equal(b)
+}
+
+// subjectKeyID returns the subject key identifier of cert, computing it per
+// RFC 5280 section 4.2.1.2 method (1) if the certificate does not have one.
+func subjectKeyID(cert *x509.Certificate) []byte {
+	if len(cert.SubjectKeyId) != 0 {
+		return cert.SubjectKeyId
+	}
+
+	var spki struct {
+		Algorithm asn1.RawValue
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil
+	}
+
+	id := sha1.Sum(spki.PublicKey.Bytes)
+	return id[:]
+}
@@ -0,0 +1,145 @@
+package webauthn
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Metadata is a parsed FIDO Metadata Service blob.
+//
+// See: https://fidoalliance.org/specs/mds/fido-metadata-service-v3.0-ps-20210518.html
+type Metadata struct {
+	byAAGUID map[string]*metadataEntry
+	byKeyID  map[string]*metadataEntry
+}
+
+type metadataEntry struct {
+	description string
+	roots       []*x509.Certificate
+	compromised bool
+}
+
+// LoadMetadata reads a FIDO Metadata Service blob from the file at path. Use
+// bin/update-fido-mds to download a blob and verify its signature. If path is
+// empty, LoadMetadata returns an empty Metadata, against which every
+// attestation is untrusted.
+func LoadMetadata(path string) (*Metadata, error) {
+	if path == "" {
+		return &Metadata{}, nil
+	}
+
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read metadata blob: %w", err)
+	}
+
+	return ParseMetadata(blob)
+}
+
+// Empty returns whether m has no authenticators. No attestation can be trusted
+// against an empty Metadata, so policies that require trusted attestations
+// cannot be satisfied. A nil Metadata is empty.
+func (m *Metadata) Empty() bool {
+	return m == nil || len(m.byAAGUID) == 0 && len(m.byKeyID) == 0
+}
+
+// ParseMetadata parses a FIDO Metadata Service blob.
+//
+// ParseMetadata does not verify the blob's signature; the blob is trusted
+// because it is part of the deployment's configuration. bin/update-fido-mds
+// verifies the signature when downloading a blob.
+func ParseMetadata(blob []byte) (*Metadata, error) {
+	parts := strings.Split(strings.TrimSpace(string(blob)), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("metadata blob is not a jwt")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode metadata blob payload: %w", err)
+	}
+
+	var data struct {
+		Entries []struct {
+			AAGUID                               string   `json:"aaguid"`
+			AttestationCertificateKeyIdentifiers []string `json:"attestationCertificateKeyIdentifiers"`
+			MetadataStatement                    struct {
+				Description                 string   `json:"description"`
+				AttestationRootCertificates []string `json:"attestationRootCertificates"`
+			} `json:"metadataStatement"`
+			StatusReports []struct {
+				Status string `json:"status"`
+			} `json:"statusReports"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal metadata blob payload: %w", err)
+	}
+
+	metadata := &Metadata{
+		byAAGUID: map[string]*metadataEntry{},
+		byKeyID:  map[string]*metadataEntry{},
+	}
+	for _, e := range data.Entries {
+		entry := &metadataEntry{
+			description: e.MetadataStatement.Description,
+		}
+
+		for _, s := range e.MetadataStatement.AttestationRootCertificates {
+			der, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("decode attestation root certificate: %w", err)
+			}
+
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("parse attestation root certificate: %w", err)
+			}
+
+			entry.roots = append(entry.roots, cert)
+		}
+
+		for _, report := range e.StatusReports {
+			if compromisedStatuses[report.Status] {
+				entry.compromised = true
+			}
+		}
+
+		if e.AAGUID != "" {
+			metadata.byAAGUID[strings.ToLower(e.AAGUID)] = entry
+		}
+		for _, keyID := range e.AttestationCertificateKeyIdentifiers {
+			metadata.byKeyID[strings.ToLower(keyID)] = entry
+		}
+	}
+
+	return metadata, nil
+}
+
+// compromisedStatuses are the MDS authenticator statuses that indicate an
+// authenticator's attestations can no longer be trusted.
+var compromisedStatuses = map[string]bool{
+	"REVOKED":                      true,
+	"USER_VERIFICATION_BYPASS":     true,
+	"ATTESTATION_KEY_COMPROMISE":   true,
+	"USER_KEY_REMOTE_COMPROMISE":   true,
+	"USER_KEY_PHYSICAL_COMPROMISE": true,
+}
+
+// lookup finds the metadata for an authenticator. FIDO2 authenticators are
+// identified by AAGUID, whereas U2F authenticators are identified by the
+// subject key identifier of their attestation certificate.
+func (m *Metadata) lookup(aaguid string, attestnCert *x509.Certificate) *metadataEntry {
+	if m == nil {
+		return nil
+	}
+	if entry, ok := m.byAAGUID[aaguid]; ok {
+		return entry
+	}
+	return m.byKeyID[hex.EncodeToString(subjectKeyID(attestnCert))]
+}
@@ -0,0 +1,43 @@
+package webauthn
+
+import (
+	"fmt"
+	"slices"
+)
+
+// AAGUIDPolicy restricts which authenticators may register credentials.
+type AAGUIDPolicy struct {
+	// RequireAttestation requires that credentials have a Trusted attestation.
+	RequireAttestation bool
+
+	// AllowedAAGUIDs, if non-empty, is the set of AAGUIDs that may register
+	// credentials. A non-empty AllowedAAGUIDs implies RequireAttestation,
+	// because an unattested AAGUID is chosen by the client.
+	AllowedAAGUIDs []string
+
+	// DeniedAAGUIDs is a set of AAGUIDs that may not register credentials.
+	DeniedAAGUIDs []string
+}
+
+// Enforced returns whether p restricts any authenticators.
+func (p *AAGUIDPolicy) Enforced() bool {
+	return p.RequireAttestation || len(p.AllowedAAGUIDs) > 0 || len(p.DeniedAAGUIDs) > 0
+}
+
+// Check returns an error if p does not permit cred. attestation must be the
+// result of verifying cred's attestation.
+func (p *AAGUIDPolicy) Check(cred *Credential, attestation *Attestation) error {
+	if slices.Contains(p.DeniedAAGUIDs, cred.AAGUID) {
+		return fmt.Errorf("aaguid %s is denied", cred.AAGUID)
+	}
+
+	if (p.RequireAttestation || len(p.AllowedAAGUIDs) > 0) && !attestation.Trusted {
+		return fmt.Errorf("attestation is not trusted")
+	}
+
+	if len(p.AllowedAAGUIDs) > 0 && !slices.Contains(p.AllowedAAGUIDs, cred.AAGUID) {
+		return fmt.Errorf("aaguid %s is not allowed", cred.AAGUID)
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// Constants from the TPM 2.0 Library specification, Part 2: Structures.
+const (
+	tpmAlgRSA    = 0x0001
+	tpmAlgSHA1   = 0x0004
+	tpmAlgSHA256 = 0x000b
+	tpmAlgSHA384 = 0x000c
+	tpmAlgSHA512 = 0x000d
+	tpmAlgNull   = 0x0010
+	tpmAlgECC    = 0x0023
+
+	tpmECCNistP256 = 0x0003
+	tpmECCNistP384 = 0x0004
+	tpmECCNistP521 = 0x0005
+
+	tpmGeneratedValue  = 0xff544347
+	tpmSTAttestCertify = 0x8017
+)
+
+// tpmPublic is the subset of a TPMT_PUBLIC needed to verify a TPM
+// attestation.
+type tpmPublic struct {
+	typ     uint16
+	nameAlg uint16
+
+	// for tpmAlgRSA
+	rsaExponent uint32
+	rsaModulus  []byte
+
+	// for tpmAlgECC
+	eccCurveID uint16
+	eccX       []byte
+	eccY       []byte
+}
+
+func parseTPMPublic(b []byte) (*tpmPublic, error) {
+	r := &tpmReader{b: b}
+
+	var pub tpmPublic
+	pub.typ = r.uint16()
+	pub.nameAlg = r.uint16()
+	_ = r.uint32()  // objectAttributes
+	_ = r.sized16() // authPolicy
+
+	switch pub.typ {
+	case tpmAlgRSA:
+		r.symDefObject()
+		r.scheme()
+		_ = r.uint16() // keyBits
+		pub.rsaExponent = r.uint32()
+		pub.rsaModulus = r.sized16()
+	case tpmAlgECC:
+		r.symDefObject()
+		r.scheme()
+		pub.eccCurveID = r.uint16()
+		r.scheme() // kdf
+		pub.eccX = r.sized16()
+		pub.eccY = r.sized16()
+	default:
+		return nil, fmt.Errorf("unsupported tpm key type: %#x", pub.typ)
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &pub, nil
+}
+
+func (p *tpmPublic) matches(pub any) bool {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if p.typ != tpmAlgRSA {
+			return false
+		}
+
+		// an exponent of zero indicates the default exponent, 2^16 + 1
+		exponent := int(p.rsaExponent)
+		if exponent == 0 {
+			exponent = 65537
+		}
+
+		return exponent == pub.E && big.NewInt(0).SetBytes(p.rsaModulus).Cmp(pub.N) == 0
+	case *ecdsa.PublicKey:
+		if p.typ != tpmAlgECC {
+			return false
+		}
+
+		var curve elliptic.Curve
+		switch p.eccCurveID {
+		case tpmECCNistP256:
+			curve = elliptic.P256()
+		case tpmECCNistP384:
+			curve = elliptic.P384()
+		case tpmECCNistP521:
+			curve = elliptic.P521()
+		default:
+			return false
+		}
+
+		return curve == pub.Curve &&
+			big.NewInt(0).SetBytes(p.eccX).Cmp(pub.X) == 0 &&
+			big.NewInt(0).SetBytes(p.eccY).Cmp(pub.Y) == 0
+	default:
+		return false
+	}
+}
+
+// tpmAttest is the subset of a TPMS_ATTEST needed to verify a TPM
+// attestation.
+type tpmAttest struct {
+	extraData    []byte
+	attestedName []byte
+}
+
+func parseTPMAttest(b []byte) (*tpmAttest, error) {
+	r := &tpmReader{b: b}
+
+	if r.uint32() != tpmGeneratedValue {
+		return nil, fmt.Errorf("invalid magic")
+	}
+
+	if r.uint16() != tpmSTAttestCertify {
+		return nil, fmt.Errorf("invalid type")
+	}
+
+	var attest tpmAttest
+	_ = r.sized16() // qualifiedSigner
+	attest.extraData = r.sized16()
+	_ = r.next(17) // clockInfo
+	_ = r.next(8)  // firmwareVersion
+	attest.attestedName = r.sized16()
+	_ = r.sized16() // qualifiedName
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &attest, nil
+}
+
+func tpmAlgHash(alg uint16) (crypto.Hash, error) {
+	switch alg {
+	case tpmAlgSHA1:
+		return crypto.SHA1, nil
+	case tpmAlgSHA256:
+		return crypto.SHA256, nil
+	case tpmAlgSHA384:
+		return crypto.SHA384, nil
+	case tpmAlgSHA512:
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported tpm hash alg: %#x", alg)
+	}
+}
+
+func uint16Bytes(n uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, n)
+}
+
+// tpmReader reads big-endian TPM structures, recording the first error it
+// encounters.
+type tpmReader struct {
+	b   []byte
+	err error
+}
+
+func (r *tpmReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if len(r.b) < n {
+		r.err = fmt.Errorf("unexpected end of tpm structure")
+		return nil
+	}
+
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out
+}
+
+func (r *tpmReader) uint16() uint16 {
+	b := r.next(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *tpmReader) uint32() uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+// sized16 reads a TPM2B structure, which is a uint16 size followed by that
+// many bytes.
+func (r *tpmReader) sized16() []byte {
+	return bytes.Clone(r.next(int(r.uint16())))
+}
+
+// symDefObject reads a TPMT_SYM_DEF_OBJECT.
+func (r *tpmReader) symDefObject() {
+	if r.uint16() != tpmAlgNull {
+		_ = r.uint16() // keyBits
+		_ = r.uint16() // mode
+	}
+}
+
+// scheme reads a TPMT_RSA_SCHEME, TPMT_ECC_SCHEME, or TPMT_KDF_SCHEME, all of
+// which are an algorithm optionally followed by a hash algorithm.
+func (r *tpmReader) scheme() {
+	if r.uint16() != tpmAlgNull {
+		_ = r.uint16() // hashAlg
+	}
+}
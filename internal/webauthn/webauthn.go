@@ -2,14 +2,16 @@ package webauthn
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/rsa"
 	"crypto/sha256"
-	"encoding/asn1"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/fxamacker/cbor/v2"
@@ -20,6 +22,14 @@ type Credential struct {
 	ID        []byte
 	PublicKey any // *ecdsa.PublicKey or *rsa.PublicKey
 	AAGUID    string
+
+	// AttestationFormat is the attestation statement format the authenticator
+	// used, e.g. "none" or "packed".
+	AttestationFormat string
+
+	alg      int64
+	authData []byte
+	attStmt  attestationStatement
 }
 
 type ParseRequest struct {
@@ -34,7 +44,9 @@ func Parse(req *ParseRequest) (*Credential, error) {
 	}
 
 	var attestationData struct {
-		AuthData []byte `cbor:"authData"`
+		Fmt      string               `cbor:"fmt"`
+		AttStmt  attestationStatement `cbor:"attStmt"`
+		AuthData []byte               `cbor:"authData"`
 	}
 	if err := cbor.Unmarshal(attestation, &attestationData); err != nil {
 		return nil, err
@@ -50,50 +62,96 @@ func Parse(req *ParseRequest) (*Credential, error) {
 	}
 
 	flags := b.Next(1) // flags
-	if flags[0]&(0x1<<6) == 0 {
+	if len(flags) != 1 || flags[0]&(0x1<<6) == 0 {
 		return nil, fmt.Errorf("attestation object must have AT flag set")
 	}
 
 	_ = b.Next(4) // signature counter
 
 	aaguidBytes := b.Next(16) // aaguid
+	if len(aaguidBytes) != 16 {
+		return nil, fmt.Errorf("attestation object too short")
+	}
 	aaguid := uuid.UUID(aaguidBytes).String()
 
-	lenCredID := binary.BigEndian.Uint16(b.Next(2)) // credential id len
-	id := b.Next(int(lenCredID))                    // n bytes of credential id
+	lenCredIDBytes := b.Next(2)
+	if len(lenCredIDBytes) != 2 {
+		return nil, fmt.Errorf("attestation object too short")
+	}
+	lenCredID := binary.BigEndian.Uint16(lenCredIDBytes) // credential id len
+	id := b.Next(int(lenCredID))                         // n bytes of credential id
 
 	// remaining data is cbor-encoded COSE key
+	alg, pub, err := parseCOSEKey(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:                id,
+		PublicKey:         pub,
+		AAGUID:            aaguid,
+		AttestationFormat: attestationData.Fmt,
+		alg:               alg,
+		authData:          attestationData.AuthData,
+		attStmt:           attestationData.AttStmt,
+	}, nil
+}
+
+func parseCOSEKey(b *bytes.Buffer) (int64, any, error) {
 	var coseKey struct {
-		Alg int    `cbor:"3,keyasint"`
-		Crv int    `cbor:"-1,keyasint"`
-		X   []byte `cbor:"-2,keyasint"`
-		Y   []byte `cbor:"-3,keyasint"`
+		Kty int64 `cbor:"1,keyasint"`
+		Alg int64 `cbor:"3,keyasint"`
 	}
-	if err := cbor.NewDecoder(b).Decode(&coseKey); err != nil {
-		return nil, err
+	var raw cbor.RawMessage
+	if err := cbor.NewDecoder(b).Decode(&raw); err != nil {
+		return 0, nil, err
+	}
+	if err := cbor.Unmarshal(raw, &coseKey); err != nil {
+		return 0, nil, err
 	}
 
-	var pub any
 	switch coseKey.Alg {
-	case -7:
-		if coseKey.Crv != 1 {
-			return nil, fmt.Errorf("unsupported curve")
+	case algES256:
+		var ec2Key struct {
+			Crv int    `cbor:"-1,keyasint"`
+			X   []byte `cbor:"-2,keyasint"`
+			Y   []byte `cbor:"-3,keyasint"`
+		}
+		if err := cbor.Unmarshal(raw, &ec2Key); err != nil {
+			return 0, nil, err
 		}
 
-		pub = &ecdsa.PublicKey{
+		if ec2Key.Crv != 1 {
+			return 0, nil, fmt.Errorf("unsupported curve")
+		}
+
+		return coseKey.Alg, &ecdsa.PublicKey{
 			Curve: elliptic.P256(),
-			X:     big.NewInt(0).SetBytes(coseKey.X),
-			Y:     big.NewInt(0).SetBytes(coseKey.Y),
+			X:     big.NewInt(0).SetBytes(ec2Key.X),
+			Y:     big.NewInt(0).SetBytes(ec2Key.Y),
+		}, nil
+	case algRS256:
+		var rsaKey struct {
+			N []byte `cbor:"-1,keyasint"`
+			E []byte `cbor:"-2,keyasint"`
+		}
+		if err := cbor.Unmarshal(raw, &rsaKey); err != nil {
+			return 0, nil, err
 		}
+
+		e := big.NewInt(0).SetBytes(rsaKey.E)
+		if !e.IsInt64() || e.Int64() > math.MaxInt32 {
+			return 0, nil, fmt.Errorf("unsupported rsa exponent")
+		}
+
+		return coseKey.Alg, &rsa.PublicKey{
+			N: big.NewInt(0).SetBytes(rsaKey.N),
+			E: int(e.Int64()),
+		}, nil
 	default:
-		return nil, fmt.Errorf("unsupported algorithm")
+		return 0, nil, fmt.Errorf("unsupported algorithm")
 	}
-
-	return &Credential{
-		ID:        id,
-		PublicKey: pub,
-		AAGUID:    aaguid,
-	}, nil
 }
 
 type VerifyRequest struct {
@@ -103,24 +161,51 @@ type VerifyRequest struct {
 	ClientDataJSON    string
 	AuthenticatorData string
 	Signature         string
+
+	// RequireUserVerification, if true, requires that the authenticator
+	// verified the user (e.g. by biometric or PIN), not merely their presence.
+	RequireUserVerification bool
+}
+
+// Assertion is the result of verifying a WebAuthn assertion.
+type Assertion struct {
+	// SignCount is the authenticator's signature counter. Authenticators that
+	// do not implement a counter always report zero.
+	SignCount uint32
+
+	// UserVerified is whether the authenticator verified the user (e.g. by
+	// biometric or PIN), not merely their presence.
+	UserVerified bool
+}
+
+// Cloned reports whether the assertion's signature counter indicates the
+// authenticator may have been cloned, given the counter from the previous
+// assertion.
+//
+// See: https://www.w3.org/TR/webauthn-2/#signature-counter
+func (a *Assertion) Cloned(previousSignCount uint32) bool {
+	if a.SignCount == 0 && previousSignCount == 0 {
+		return false
+	}
+	return a.SignCount <= previousSignCount
 }
 
-func (c *Credential) Verify(req *VerifyRequest) error {
+func (c *Credential) Verify(req *VerifyRequest) (*Assertion, error) {
 	clientDataBytes, err := base64.RawURLEncoding.DecodeString(req.ClientDataJSON)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	clientDataHash := sha256.Sum256(clientDataBytes)
 
 	authenticatorDataBytes, err := base64.RawURLEncoding.DecodeString(req.AuthenticatorData)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	signatureBytes, err := base64.RawURLEncoding.DecodeString(req.Signature)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var signedBytes []byte
@@ -128,16 +213,17 @@ func (c *Credential) Verify(req *VerifyRequest) error {
 	signedBytes = append(signedBytes, clientDataHash[:]...)
 	hash := sha256.Sum256(signedBytes)
 
-	var sig struct {
-		R, S *big.Int
-	}
-	if _, err := asn1.Unmarshal(signatureBytes, &sig); err != nil {
-		return err
-	}
-
-	// TODO rsa
-	if !ecdsa.Verify(c.PublicKey.(*ecdsa.PublicKey), hash[:], sig.R, sig.S) {
-		return fmt.Errorf("invalid signature")
+	switch pub := c.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, hash[:], signatureBytes) {
+			return nil, fmt.Errorf("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], signatureBytes); err != nil {
+			return nil, fmt.Errorf("invalid signature: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type")
 	}
 
 	// verify rp id hash
@@ -145,12 +231,22 @@ func (c *Credential) Verify(req *VerifyRequest) error {
 	rpIDSHA256 := sha256.Sum256([]byte(req.RPID))
 	rpHash := b.Next(32) // 32-byte rp hash
 	if !bytes.Equal(rpHash, rpIDSHA256[:]) {
-		return fmt.Errorf("invalid rp id")
+		return nil, fmt.Errorf("invalid rp id")
 	}
 
 	flags := b.Next(1) // flags
-	if flags[0]&(0x1) == 0 {
-		return fmt.Errorf("authenticator data must have UP flag set")
+	if len(flags) != 1 || flags[0]&(0x1) == 0 {
+		return nil, fmt.Errorf("authenticator data must have UP flag set")
+	}
+
+	userVerified := flags[0]&(0x1<<2) != 0
+	if req.RequireUserVerification && !userVerified {
+		return nil, fmt.Errorf("authenticator data must have UV flag set")
+	}
+
+	signCount := b.Next(4) // 4-byte big-endian signature counter
+	if len(signCount) != 4 {
+		return nil, fmt.Errorf("authenticator data missing sign count")
 	}
 
 	var clientData struct {
@@ -160,15 +256,15 @@ func (c *Credential) Verify(req *VerifyRequest) error {
 		CrossOrigin bool   `json:"crossOrigin"`
 	}
 	if err := json.Unmarshal(clientDataBytes, &clientData); err != nil {
-		return err
+		return nil, err
 	}
 
 	if clientData.Type != "webauthn.get" {
-		return fmt.Errorf("invalid client data type")
+		return nil, fmt.Errorf("invalid client data type")
 	}
 
 	if clientData.CrossOrigin {
-		return fmt.Errorf("cross-origin not supported")
+		return nil, fmt.Errorf("cross-origin not supported")
 	}
 
 	var originOK bool
@@ -180,18 +276,21 @@ func (c *Credential) Verify(req *VerifyRequest) error {
 	}
 
 	if !originOK {
-		return fmt.Errorf("invalid origin")
+		return nil, fmt.Errorf("invalid origin")
 	}
 
 	challengeBytes, err := base64.RawURLEncoding.DecodeString(clientData.Challenge)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	challengeBytesSHA256 := sha256.Sum256(challengeBytes)
 	if !bytes.Equal(challengeBytesSHA256[:], req.ChallengeSHA256) {
-		return fmt.Errorf("invalid challenge")
+		return nil, fmt.Errorf("invalid challenge")
 	}
 
-	return nil
+	return &Assertion{
+		SignCount:    binary.BigEndian.Uint32(signCount),
+		UserVerified: userVerified,
+	}, nil
 }
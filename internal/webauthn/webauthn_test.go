@@ -14,12 +14,40 @@ func TestParse(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	require.NoError(t, c.Verify(&webauthn.VerifyRequest{
+	assertion, err := c.Verify(&webauthn.VerifyRequest{
 		RPID:              "localhost",
 		Origins:           []string{"http://localhost:3002"},
 		ChallengeSHA256:   []byte{132, 143, 185, 51, 158, 98, 37, 12, 23, 156, 66, 204, 255, 170, 216, 93, 168, 10, 69, 31, 108, 79, 71, 89, 15, 138, 213, 219, 29, 51, 128, 200},
 		ClientDataJSON:    "eyJ0eXBlIjoid2ViYXV0aG4uZ2V0IiwiY2hhbGxlbmdlIjoiZVZCb2NkcnU3cm1VdzhJVloyVW1JdVB6cXp0NEx0VnZnU2JpcGdGOWRGQSIsIm9yaWdpbiI6Imh0dHA6Ly9sb2NhbGhvc3Q6MzAwMiIsImNyb3NzT3JpZ2luIjpmYWxzZSwib3RoZXJfa2V5c19jYW5fYmVfYWRkZWRfaGVyZSI6ImRvIG5vdCBjb21wYXJlIGNsaWVudERhdGFKU09OIGFnYWluc3QgYSB0ZW1wbGF0ZS4gU2VlIGh0dHBzOi8vZ29vLmdsL3lhYlBleCJ9",
 		AuthenticatorData: "SZYN5YgOjGh0NBcPZHZgW4_krrmihjLHmVzzuoMdl2MdAAAAAA",
 		Signature:         "MEQCICUsfxpP1H2YjKM3PUwdX6rlTcIkrSUtsggWnqyEHE2NAiAwvtKsHzJtzE9ITWTP4rvIvkYoGss3Dg_a3RNkoNoXSg",
-	}))
+
+		RequireUserVerification: true,
+	})
+	require.NoError(t, err)
+	require.True(t, assertion.UserVerified)
+	require.Equal(t, uint32(0), assertion.SignCount)
+}
+
+func TestAssertion_Cloned(t *testing.T) {
+	testCases := []struct {
+		name              string
+		signCount         uint32
+		previousSignCount uint32
+		want              bool
+	}{
+		{"no counter", 0, 0, false},
+		{"first use", 1, 0, false},
+		{"increasing", 5, 4, false},
+		{"repeated", 4, 4, true},
+		{"decreasing", 3, 4, true},
+		{"counter reset", 0, 4, true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assertion := webauthn.Assertion{SignCount: tt.signCount}
+			require.Equal(t, tt.want, assertion.Cloned(tt.previousSignCount))
+		})
+	}
 }
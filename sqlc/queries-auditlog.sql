@@ -22,6 +22,14 @@ FROM
 WHERE
     id = $1;
 
+-- name: GetBackendAPIKey :one
+SELECT
+    *
+FROM
+    backend_api_keys
+WHERE
+    id = $1;
+
 -- name: GetOrganization :one
 SELECT
     *
@@ -62,6 +70,14 @@ FROM
 WHERE
     role_id = ANY ($1::uuid[]);
 
+-- name: BatchGetRoleInheritedRolesByRoleID :many
+SELECT
+    *
+FROM
+    role_inherited_roles
+WHERE
+    role_id = ANY ($1::uuid[]);
+
 -- name: GetSAMLConnection :one
 SELECT
     *
@@ -94,6 +110,14 @@ FROM
 WHERE
     id = $1;
 
+-- name: GetRoleElevationRequest :one
+SELECT
+    *
+FROM
+    role_elevation_requests
+WHERE
+    id = $1;
+
 -- name: GetUser :one
 SELECT
     *
@@ -118,3 +142,23 @@ FROM
 WHERE
     id = $1;
 
+
+-- name: GetRelationship :one
+SELECT
+    relationships.*,
+    resource_types.name AS resource_type_name,
+    resource_relations.name AS resource_relation_name
+FROM
+    relationships
+    JOIN resource_relations ON relationships.resource_relation_id = resource_relations.id
+    JOIN resource_types ON resource_relations.resource_type_id = resource_types.id
+WHERE
+    relationships.id = $1;
+
+-- name: GetEmailSuppression :one
+SELECT
+    *
+FROM
+    email_suppressions
+WHERE
+    id = $1;
@@ -1,6 +1,6 @@
 -- name: CreateOrganization :one
-INSERT INTO organizations (id, project_id, display_name, log_in_with_google, log_in_with_microsoft, log_in_with_github, log_in_with_email, log_in_with_password, log_in_with_saml, log_in_with_oidc, log_in_with_authenticator_app, log_in_with_passkey, scim_enabled)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+INSERT INTO organizations (id, project_id, display_name, log_in_with_google, log_in_with_microsoft, log_in_with_github, log_in_with_email, log_in_with_password, log_in_with_saml, log_in_with_oidc, log_in_with_authenticator_app, log_in_with_passkey, scim_enabled, locale, log_in_with_passwordless_passkey)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 RETURNING
     *;
 
@@ -27,7 +27,9 @@ SELECT
 FROM
     backend_api_keys
 WHERE
-    secret_token_sha256 = $1;
+    secret_token_sha256 = $1
+    AND (expire_time > now()
+        OR expire_time IS NULL);
 
 -- name: GetSessionSigningKeysByProjectID :many
 SELECT
@@ -37,6 +39,17 @@ FROM
 WHERE
     project_id = $1;
 
+-- name: GetCurrentSessionSigningKeyByProjectID :one
+SELECT
+    *
+FROM
+    session_signing_keys
+WHERE
+    project_id = $1
+ORDER BY
+    create_time DESC
+LIMIT 1;
+
 -- name: ListOrganizationsByProjectId :many
 SELECT
     *
@@ -80,12 +93,14 @@ SET
     log_in_with_password = $6,
     log_in_with_authenticator_app = $7,
     log_in_with_passkey = $8,
+    log_in_with_passwordless_passkey = $17,
     log_in_with_saml = $9,
     log_in_with_oidc = $15,
     scim_enabled = $10,
     require_mfa = $11,
     custom_roles_enabled = $12,
-    api_keys_enabled = $14
+    api_keys_enabled = $14,
+    locale = $16
 WHERE
     id = $1
 RETURNING
@@ -110,6 +125,7 @@ SET
     log_in_with_oidc = $24,
     log_in_with_authenticator_app = $8,
     log_in_with_passkey = $9,
+    log_in_with_passwordless_passkey = $28,
     google_oauth_client_id = $10,
     google_oauth_client_secret_ciphertext = $11,
     microsoft_oauth_client_id = $12,
@@ -223,7 +239,16 @@ WHERE
 UPDATE
     organizations
 SET
-    log_in_with_passkey = FALSE
+    log_in_with_passkey = FALSE,
+    log_in_with_passwordless_passkey = FALSE
+WHERE
+    project_id = $1;
+
+-- name: DisableProjectOrganizationsLogInWithPasswordlessPasskey :exec
+UPDATE
+    organizations
+SET
+    log_in_with_passwordless_passkey = FALSE
 WHERE
     project_id = $1;
 
@@ -432,8 +457,8 @@ WHERE
     AND project_id = $2;
 
 -- name: CreateBackendAPIKey :one
-INSERT INTO backend_api_keys (id, project_id, display_name, secret_token_sha256, authentication_only)
-    VALUES ($1, $2, $3, $4, $5)
+INSERT INTO backend_api_keys (id, project_id, display_name, secret_token_sha256, authentication_only, scopes, organization_ids, expire_time, ip_allowlist_cidrs)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING
     *;
 
@@ -443,12 +468,32 @@ UPDATE
 SET
     update_time = now(),
     display_name = $1,
-    authentication_only = $2
+    authentication_only = $2,
+    scopes = $3,
+    organization_ids = $4,
+    ip_allowlist_cidrs = $5
 WHERE
-    id = $3
+    id = $6
 RETURNING
     *;
 
+-- name: UpdateBackendAPIKeyLastUsed :exec
+UPDATE
+    backend_api_keys
+SET
+    last_used_time = $2,
+    last_used_ip = $3
+WHERE
+    id = $1
+    AND (last_used_time IS NULL
+        OR last_used_time < $2);
+
+-- name: CreateBackendAPIKeyIP :execrows
+INSERT INTO backend_api_key_ips (backend_api_key_id, ip)
+    VALUES ($1, $2)
+ON CONFLICT
+    DO NOTHING;
+
 -- name: DeleteBackendAPIKey :exec
 DELETE FROM backend_api_keys
 WHERE id = $1;
@@ -530,8 +575,8 @@ WHERE
     AND organizations.project_id = $2;
 
 -- name: CreateUser :one
-INSERT INTO users (id, organization_id, google_user_id, microsoft_user_id, github_user_id, email, is_owner)
-    VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO users (id, organization_id, google_user_id, microsoft_user_id, github_user_id, email, is_owner, locale)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING
     *;
 
@@ -546,12 +591,35 @@ SET
     github_user_id = $8,
     is_owner = $5,
     display_name = $6,
-    profile_picture_url = $7
+    profile_picture_url = $7,
+    locale = $9
 WHERE
     id = $1
 RETURNING
     *;
 
+-- name: ListUnrevokedUserAPIKeyIDs :many
+SELECT
+    id
+FROM
+    api_keys
+WHERE
+    user_id = $1
+    AND secret_token_sha256 IS NOT NULL;
+
+-- name: RevokeUserAPIKeys :exec
+UPDATE
+    api_keys
+SET
+    update_time = now(),
+    secret_token_sha256 = NULL,
+    secret_token_suffix = NULL,
+    previous_secret_token_sha256 = NULL,
+    previous_secret_token_expire_time = NULL
+WHERE
+    user_id = $1
+    AND secret_token_sha256 IS NOT NULL;
+
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
@@ -748,6 +816,62 @@ INSERT INTO organization_microsoft_tenant_ids (id, organization_id, microsoft_te
 RETURNING
     *;
 
+-- name: GetOrganizationIPAllowlistCIDRs :many
+SELECT
+    organization_ip_allowlist_cidrs.*
+FROM
+    organization_ip_allowlist_cidrs
+    JOIN organizations ON organization_ip_allowlist_cidrs.organization_id = organizations.id
+WHERE
+    organization_ip_allowlist_cidrs.organization_id = $1
+    AND organizations.project_id = $2
+ORDER BY
+    organization_ip_allowlist_cidrs.cidr;
+
+-- name: DeleteOrganizationIPAllowlistCIDRs :exec
+DELETE FROM organization_ip_allowlist_cidrs
+WHERE organization_id = $1;
+
+-- name: CreateOrganizationIPAllowlistCIDR :one
+INSERT INTO organization_ip_allowlist_cidrs (id, organization_id, cidr)
+    VALUES ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: GetOrganizationPasskeyAAGUIDs :many
+SELECT
+    organization_passkey_aaguids.*
+FROM
+    organization_passkey_aaguids
+    JOIN organizations ON organization_passkey_aaguids.organization_id = organizations.id
+WHERE
+    organization_passkey_aaguids.organization_id = $1
+    AND organizations.project_id = $2
+ORDER BY
+    organization_passkey_aaguids.aaguid;
+
+-- name: DeleteOrganizationPasskeyAAGUIDs :exec
+DELETE FROM organization_passkey_aaguids
+WHERE organization_id = $1;
+
+-- name: CreateOrganizationPasskeyAAGUID :one
+INSERT INTO organization_passkey_aaguids (id, organization_id, aaguid, allowed)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+-- name: UpdateOrganizationPasskeyPolicy :one
+UPDATE
+    organizations
+SET
+    update_time = now(),
+    passkey_require_attestation = $2,
+    passkey_require_user_verification = $3
+WHERE
+    id = $1
+RETURNING
+    *;
+
 -- name: ListPasskeys :many
 SELECT
     *
@@ -971,8 +1095,8 @@ WHERE
     AND project_id = $2;
 
 -- name: CreateRole :one
-INSERT INTO roles (id, project_id, organization_id, display_name, description)
-    VALUES ($1, $2, $3, $4, $5)
+INSERT INTO roles (id, project_id, organization_id, display_name, description, action_wildcards, default_role)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING
     *;
 
@@ -982,12 +1106,46 @@ UPDATE
 SET
     update_time = now(),
     display_name = $2,
-    description = $3
+    description = $3,
+    action_wildcards = $4,
+    default_role = $5
 WHERE
     id = $1
 RETURNING
     *;
 
+-- name: BatchGetRoleInheritedRolesByRoleID :many
+SELECT
+    *
+FROM
+    role_inherited_roles
+WHERE
+    role_id = ANY ($1::uuid[]);
+
+-- name: CreateRoleInheritedRole :exec
+INSERT INTO role_inherited_roles (id, role_id, inherited_role_id)
+    VALUES ($1, $2, $3);
+
+-- name: DeleteRoleInheritedRoles :exec
+DELETE FROM role_inherited_roles
+WHERE role_id = $1;
+
+-- name: ListTransitivelyInheritedRoleIDs :many
+WITH RECURSIVE inherited_roles (role_id) AS (
+    SELECT
+        unnest(@role_ids::uuid[])
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN inherited_roles ON role_inherited_roles.role_id = inherited_roles.role_id
+)
+SELECT
+    role_id::uuid
+FROM
+    inherited_roles;
+
 -- name: UpsertRoleAction :exec
 INSERT INTO role_actions (id, role_id, action_id)
     VALUES ($1, $2, $3)
@@ -1038,10 +1196,16 @@ WHERE
     AND roles.project_id = $2;
 
 -- name: UpsertUserRoleAssignment :exec
-INSERT INTO user_role_assignments (id, role_id, user_id)
-    VALUES ($1, $2, $3)
+INSERT INTO user_role_assignments (id, role_id, user_id, expire_time)
+    VALUES ($1, $2, $3, $4)
 ON CONFLICT (role_id, user_id)
-    DO NOTHING;
+    DO UPDATE SET
+        expire_time = CASE WHEN user_role_assignments.expire_time IS NULL
+            OR excluded.expire_time IS NULL THEN
+            NULL
+        ELSE
+            greatest (user_role_assignments.expire_time, excluded.expire_time)
+        END;
 
 -- name: GetUserRoleAssignmentByUserAndRole :one
 SELECT
@@ -1065,8 +1229,8 @@ WHERE
     project_id = $1;
 
 -- name: CreateAPIKey :one
-INSERT INTO api_keys (id, organization_id, display_name, secret_token_sha256, secret_token_suffix, expire_time)
-    VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO api_keys (id, organization_id, display_name, secret_token_sha256, secret_token_suffix, expire_time, rate_limit_per_minute, user_id, actions)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING
     *;
 
@@ -1082,7 +1246,8 @@ UPDATE
     api_keys
 SET
     update_time = now(),
-    display_name = $2
+    display_name = $2,
+    rate_limit_per_minute = $4
 FROM
     organizations AS organization
 WHERE
@@ -1104,13 +1269,18 @@ WHERE
 -- name: GetAPIKeyDetailsBySecretTokenSHA256 :one
 SELECT
     api_keys.id,
-    api_keys.organization_id
+    api_keys.organization_id,
+    api_keys.expire_time,
+    api_keys.rate_limit_per_minute,
+    api_keys.user_id,
+    api_keys.actions
 FROM
     api_keys
     JOIN organizations AS organization ON api_keys.organization_id = organization.id
-WHERE
-    api_keys.secret_token_sha256 = $1
-    AND organization.project_id = $2
+WHERE (api_keys.secret_token_sha256 = $1
+    OR (api_keys.previous_secret_token_sha256 = $1
+        AND api_keys.previous_secret_token_expire_time > now()))
+AND organization.project_id = $2
     AND (api_keys.expire_time > now()
         OR api_keys.expire_time IS NULL);
 
@@ -1124,6 +1294,8 @@ WHERE
     organization.id = $1
     AND organization.project_id = $2
     AND api_keys.id >= $3
+    AND (sqlc.narg (user_id)::uuid IS NULL
+        OR api_keys.user_id = sqlc.narg (user_id))
 ORDER BY
     api_keys.id
 LIMIT $4;
@@ -1134,13 +1306,37 @@ UPDATE
 SET
     update_time = now(),
     secret_token_sha256 = NULL,
-    secret_token_suffix = NULL
+    secret_token_suffix = NULL,
+    previous_secret_token_sha256 = NULL,
+    previous_secret_token_expire_time = NULL
 FROM
     organizations AS organization
 WHERE
     api_keys.id = $1
     AND organization.project_id = $2;
 
+-- name: RotateAPIKey :one
+UPDATE
+    api_keys
+SET
+    update_time = now(),
+    previous_secret_token_sha256 = CASE WHEN sqlc.narg (previous_secret_token_expire_time)::timestamptz IS NULL THEN
+        NULL
+    ELSE
+        api_keys.secret_token_sha256
+    END,
+    previous_secret_token_expire_time = sqlc.narg (previous_secret_token_expire_time),
+    secret_token_sha256 = $2,
+    secret_token_suffix = $3
+FROM
+    organizations AS organization
+WHERE
+    api_keys.organization_id = organization.id
+    AND api_keys.id = $1
+    AND organization.project_id = $4
+RETURNING
+    api_keys.*;
+
 -- name: CreateAPIKeyRoleAssignment :one
 INSERT INTO api_key_role_assignments (id, api_key_id, role_id)
     VALUES ($1, $2, $3)
@@ -1148,16 +1344,82 @@ RETURNING
     *;
 
 -- name: GetAPIKeyActions :many
+WITH RECURSIVE api_key_roles (role_id) AS (
+    SELECT
+        api_key_role_assignments.role_id
+    FROM
+        api_key_role_assignments
+    WHERE
+        api_key_role_assignments.api_key_id = $1
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN api_key_roles ON role_inherited_roles.role_id = api_key_roles.role_id
+)
 SELECT DISTINCT
-    (actions.name)
+    actions.name
 FROM
-    api_keys
-    JOIN api_key_role_assignments ON api_keys.id = api_key_role_assignments.api_key_id
-    JOIN roles ON api_key_role_assignments.role_id = roles.id
-    JOIN role_actions ON roles.id = role_actions.role_id
-    JOIN actions ON role_actions.action_id = actions.id
+    api_key_roles
+    JOIN roles ON api_key_roles.role_id = roles.id
+    JOIN actions ON roles.project_id = actions.project_id
 WHERE
-    api_keys.id = $1;
+    EXISTS (
+        SELECT
+            1
+        FROM
+            role_actions
+        WHERE
+            role_actions.role_id = roles.id
+            AND role_actions.action_id = actions.id)
+    OR EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(roles.action_wildcards) AS action_wildcard (pattern)
+        WHERE
+            starts_with (actions.name, rtrim(action_wildcard.pattern, '*')));
+
+-- name: GetUserActions :many
+WITH RECURSIVE user_roles (role_id) AS (
+    SELECT
+        user_role_assignments.role_id
+    FROM
+        user_role_assignments
+    WHERE
+        user_role_assignments.user_id = @user_id
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN user_roles ON role_inherited_roles.role_id = user_roles.role_id
+)
+SELECT DISTINCT
+    actions.name
+FROM
+    user_roles
+    JOIN roles ON user_roles.role_id = roles.id
+    JOIN actions ON roles.project_id = actions.project_id
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            role_actions
+        WHERE
+            role_actions.role_id = roles.id
+            AND role_actions.action_id = actions.id)
+    OR EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(roles.action_wildcards) AS action_wildcard (pattern)
+        WHERE
+            starts_with (actions.name, rtrim(action_wildcard.pattern, '*')));
 
 -- name: GetAPIKeyRoleAssignment :one
 SELECT
@@ -1329,6 +1591,19 @@ INSERT INTO project_webhook_settings (id, project_id, app_id)
 RETURNING
     *;
 
+-- name: UpdateProjectWebhookSettingsSigningSecret :one
+UPDATE
+    project_webhook_settings
+SET
+    signing_secret_ciphertext = $2,
+    previous_signing_secret_ciphertext = $3,
+    previous_signing_secret_expire_time = $4,
+    update_time = now()
+WHERE
+    project_id = $1
+RETURNING
+    *;
+
 -- name: GetProjectOnboardingProgress :one
 SELECT
     *
@@ -1350,3 +1625,554 @@ ON CONFLICT (project_id)
     RETURNING
         *;
 
+
+-- name: GetProjectPasswordPolicy :one
+SELECT
+    *
+FROM
+    project_password_policies
+WHERE
+    project_id = $1;
+
+-- name: UpsertProjectPasswordPolicy :one
+INSERT INTO project_password_policies (project_id, min_length, require_lowercase, require_uppercase, require_digit, require_symbol, disallow_personal_info, history_count, max_age_days, lockout_attempts, lockout_duration_seconds)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (project_id)
+    DO UPDATE SET
+        min_length = excluded.min_length,
+        require_lowercase = excluded.require_lowercase,
+        require_uppercase = excluded.require_uppercase,
+        require_digit = excluded.require_digit,
+        require_symbol = excluded.require_symbol,
+        disallow_personal_info = excluded.disallow_personal_info,
+        history_count = excluded.history_count,
+        max_age_days = excluded.max_age_days,
+        lockout_attempts = excluded.lockout_attempts,
+        lockout_duration_seconds = excluded.lockout_duration_seconds
+    RETURNING
+        *;
+
+-- name: GetOrganizationPasswordPolicy :one
+SELECT
+    *
+FROM
+    organization_password_policies
+WHERE
+    organization_id = $1;
+
+-- name: UpsertOrganizationPasswordPolicy :one
+INSERT INTO organization_password_policies (organization_id, min_length, require_lowercase, require_uppercase, require_digit, require_symbol, disallow_personal_info, history_count, max_age_days, lockout_attempts, lockout_duration_seconds)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (organization_id)
+    DO UPDATE SET
+        min_length = excluded.min_length,
+        require_lowercase = excluded.require_lowercase,
+        require_uppercase = excluded.require_uppercase,
+        require_digit = excluded.require_digit,
+        require_symbol = excluded.require_symbol,
+        disallow_personal_info = excluded.disallow_personal_info,
+        history_count = excluded.history_count,
+        max_age_days = excluded.max_age_days,
+        lockout_attempts = excluded.lockout_attempts,
+        lockout_duration_seconds = excluded.lockout_duration_seconds
+    RETURNING
+        *;
+
+-- name: ListWebhookEndpoints :many
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    project_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: GetWebhookEndpoint :one
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (id, project_id, url, description, event_types, enabled)
+    VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING
+    *;
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE
+    webhook_endpoints
+SET
+    update_time = now(),
+    url = $2,
+    description = $3,
+    event_types = $4,
+    enabled = $5
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+SELECT
+    *
+FROM
+    webhook_deliveries
+WHERE
+    project_id = @project_id
+    AND (webhook_endpoint_id = sqlc.narg ('webhook_endpoint_id')
+        OR sqlc.narg ('webhook_endpoint_id') IS NULL)
+    AND id <= @id
+ORDER BY
+    id DESC
+LIMIT $1;
+
+-- name: GetWebhookDelivery :one
+SELECT
+    *
+FROM
+    webhook_deliveries
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: ListAuditLogStreams :many
+SELECT
+    *
+FROM
+    audit_log_streams
+WHERE
+    project_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: GetAuditLogStream :one
+SELECT
+    *
+FROM
+    audit_log_streams
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: CreateAuditLogStream :one
+INSERT INTO audit_log_streams (id, project_id, organization_id, display_name, type, enabled, webhook_url, syslog_address, syslog_tls, splunk_hec_url, splunk_hec_token_ciphertext, cursor_audit_log_event_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING
+    *;
+
+-- name: UpdateAuditLogStream :one
+UPDATE
+    audit_log_streams
+SET
+    update_time = now(),
+    display_name = $2,
+    enabled = $3,
+    webhook_url = $4,
+    syslog_address = $5,
+    syslog_tls = $6,
+    splunk_hec_url = $7,
+    splunk_hec_token_ciphertext = $8
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: DeleteAuditLogStream :exec
+DELETE FROM audit_log_streams
+WHERE id = $1;
+
+-- name: ListEmailTemplates :many
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    project_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: ListEmailTemplatesByType :many
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    project_id = $1
+    AND type = $2;
+
+-- name: GetEmailTemplate :one
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: CreateEmailTemplate :one
+INSERT INTO email_templates (id, project_id, type, locale, subject, text_body, html_body)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+    *;
+
+-- name: UpdateEmailTemplate :one
+UPDATE
+    email_templates
+SET
+    update_time = now(),
+    subject = $2,
+    text_body = $3,
+    html_body = $4
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: DeleteEmailTemplate :exec
+DELETE FROM email_templates
+WHERE id = $1;
+
+-- name: ListEmailEvents :many
+SELECT
+    *
+FROM
+    email_events
+WHERE
+    project_id = $1
+    AND id <= $2
+    AND (email_address = sqlc.narg ('email_address')
+        OR sqlc.narg ('email_address') IS NULL)
+    AND (user_invite_id = sqlc.narg ('user_invite_id')
+        OR sqlc.narg ('user_invite_id') IS NULL)
+ORDER BY
+    id DESC
+LIMIT $3;
+
+-- name: UpsertAPIKeyUsageBucket :one
+INSERT INTO api_key_usage_buckets (api_key_id, start_time, request_count, rate_limited_count)
+SELECT
+    id,
+    $2,
+    $3,
+    $4
+FROM
+    api_keys
+WHERE
+    id = $1
+ON CONFLICT (api_key_id,
+    start_time)
+    DO UPDATE SET
+        request_count = api_key_usage_buckets.request_count + excluded.request_count,
+        rate_limited_count = api_key_usage_buckets.rate_limited_count + excluded.rate_limited_count
+    RETURNING
+        request_count;
+
+-- name: ListAPIKeyUsageBucketRequestCounts :many
+SELECT
+    api_key_id,
+    request_count
+FROM
+    api_key_usage_buckets
+WHERE
+    start_time = @start_time
+    AND api_key_id = ANY (@api_key_ids::uuid[]);
+
+-- name: DeleteAPIKeyUsageBucketsBefore :exec
+DELETE FROM api_key_usage_buckets
+WHERE start_time < $1;
+
+-- name: ListAPIKeyUsageBuckets :many
+SELECT
+    date_trunc(@granularity::text, start_time)::timestamp with time zone AS start_time,
+    sum(request_count)::bigint AS request_count,
+    sum(rate_limited_count)::bigint AS rate_limited_count
+FROM
+    api_key_usage_buckets
+WHERE
+    api_key_id = @api_key_id
+    AND start_time >= @start_time
+    AND start_time < @end_time
+GROUP BY
+    1
+ORDER BY
+    1;
+
+-- name: GetResourceTypes :many
+SELECT
+    *
+FROM
+    resource_types
+WHERE
+    project_id = $1
+ORDER BY
+    name;
+
+-- name: GetResourceRelations :many
+SELECT
+    resource_relations.*,
+    resource_types.name AS resource_type_name
+FROM
+    resource_relations
+    JOIN resource_types ON resource_relations.resource_type_id = resource_types.id
+WHERE
+    resource_types.project_id = $1
+ORDER BY
+    resource_types.name,
+    resource_relations.name;
+
+-- name: UpsertResourceType :one
+INSERT INTO resource_types (id, project_id, name, description)
+    VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id, name)
+    DO UPDATE SET
+        description = excluded.description
+    RETURNING
+        *;
+
+-- name: UpsertResourceRelation :exec
+INSERT INTO resource_relations (id, resource_type_id, name, description, implied_by)
+    VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (resource_type_id, name)
+    DO UPDATE SET
+        description = excluded.description,
+        implied_by = excluded.implied_by;
+
+-- name: DeleteResourceRelationsByNameNotInList :exec
+DELETE FROM resource_relations
+WHERE resource_type_id = $1
+    AND NOT (name = ANY (@names::varchar[]));
+
+-- name: DeleteResourceTypesByNameNotInList :exec
+DELETE FROM resource_types
+WHERE project_id = $1
+    AND NOT (name = ANY (@names::varchar[]));
+
+-- name: GetRelationship :one
+SELECT
+    relationships.*
+FROM
+    relationships
+    JOIN organizations ON relationships.organization_id = organizations.id
+WHERE
+    relationships.id = $1
+    AND organizations.project_id = $2;
+
+-- name: ListRelationships :many
+SELECT
+    *
+FROM
+    relationships
+WHERE
+    organization_id = $1
+    AND (sqlc.narg (resource_relation_ids)::uuid[] IS NULL
+        OR resource_relation_id = ANY (sqlc.narg (resource_relation_ids)::uuid[]))
+    AND (sqlc.narg (resource_id)::varchar IS NULL
+        OR resource_id = sqlc.narg (resource_id)::varchar)
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: CreateRelationship :one
+INSERT INTO relationships (id, organization_id, resource_relation_id, resource_id, user_id, api_key_id, role_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+    *;
+
+-- name: DeleteRelationship :exec
+DELETE FROM relationships
+WHERE id = $1;
+
+-- name: CheckUserRelationship :one
+WITH RECURSIVE user_roles (role_id) AS (
+    SELECT
+        user_role_assignments.role_id
+    FROM
+        user_role_assignments
+    WHERE
+        user_role_assignments.user_id = @user_id
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN user_roles ON role_inherited_roles.role_id = user_roles.role_id
+)
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            relationships
+        WHERE
+            relationships.organization_id = @organization_id
+            AND relationships.resource_relation_id = ANY (@resource_relation_ids::uuid[])
+            AND relationships.resource_id = @resource_id
+            AND (relationships.user_id = @user_id::uuid
+                OR relationships.role_id IN (
+                    SELECT
+                        user_roles.role_id
+                    FROM
+                        user_roles)));
+
+-- name: ListUserRelationshipResourceIDs :many
+WITH RECURSIVE user_roles (role_id) AS (
+    SELECT
+        user_role_assignments.role_id
+    FROM
+        user_role_assignments
+    WHERE
+        user_role_assignments.user_id = @user_id
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN user_roles ON role_inherited_roles.role_id = user_roles.role_id
+)
+SELECT DISTINCT
+    relationships.resource_id
+FROM
+    relationships
+WHERE
+    relationships.organization_id = @organization_id
+    AND relationships.resource_relation_id = ANY (@resource_relation_ids::uuid[])
+    AND (relationships.user_id = @user_id::uuid
+        OR relationships.role_id IN (
+            SELECT
+                user_roles.role_id
+            FROM
+                user_roles))
+    AND relationships.resource_id > @start_resource_id
+ORDER BY
+    relationships.resource_id
+LIMIT @page_limit;
+
+-- name: CheckAPIKeyRelationship :one
+WITH RECURSIVE api_key_roles (role_id) AS (
+    SELECT
+        api_key_role_assignments.role_id
+    FROM
+        api_key_role_assignments
+    WHERE
+        api_key_role_assignments.api_key_id = @api_key_id
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN api_key_roles ON role_inherited_roles.role_id = api_key_roles.role_id
+)
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            relationships
+        WHERE
+            relationships.organization_id = @organization_id
+            AND relationships.resource_relation_id = ANY (@resource_relation_ids::uuid[])
+            AND relationships.resource_id = @resource_id
+            AND (relationships.api_key_id = @api_key_id::uuid
+                OR relationships.role_id IN (
+                    SELECT
+                        api_key_roles.role_id
+                    FROM
+                        api_key_roles)));
+
+-- name: ListAPIKeyRelationshipResourceIDs :many
+WITH RECURSIVE api_key_roles (role_id) AS (
+    SELECT
+        api_key_role_assignments.role_id
+    FROM
+        api_key_role_assignments
+    WHERE
+        api_key_role_assignments.api_key_id = @api_key_id
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN api_key_roles ON role_inherited_roles.role_id = api_key_roles.role_id
+)
+SELECT DISTINCT
+    relationships.resource_id
+FROM
+    relationships
+WHERE
+    relationships.organization_id = @organization_id
+    AND relationships.resource_relation_id = ANY (@resource_relation_ids::uuid[])
+    AND (relationships.api_key_id = @api_key_id::uuid
+        OR relationships.role_id IN (
+            SELECT
+                api_key_roles.role_id
+            FROM
+                api_key_roles))
+    AND relationships.resource_id > @start_resource_id
+ORDER BY
+    relationships.resource_id
+LIMIT @page_limit;
+
+-- name: ListEmailSuppressions :many
+SELECT
+    *
+FROM
+    email_suppressions
+WHERE
+    project_id = $1
+    AND id <= $2
+    AND (email_address = sqlc.narg ('email_address')
+        OR sqlc.narg ('email_address') IS NULL)
+ORDER BY
+    id DESC
+LIMIT $3;
+
+-- name: GetEmailSuppression :one
+SELECT
+    *
+FROM
+    email_suppressions
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: DeleteEmailSuppression :exec
+DELETE FROM email_suppressions
+WHERE id = $1;
+
+-- name: CreateDefaultUserRoleAssignments :many
+INSERT INTO user_role_assignments (id, role_id, user_id)
+SELECT
+    gen_random_uuid (),
+    roles.id,
+    users.id
+FROM
+    users
+    JOIN organizations ON users.organization_id = organizations.id
+    JOIN roles ON organizations.project_id = roles.project_id
+WHERE
+    users.id = $1
+    AND roles.default_role
+ON CONFLICT (role_id, user_id)
+    DO NOTHING
+RETURNING
+    *;
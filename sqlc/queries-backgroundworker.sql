@@ -6,6 +6,18 @@ FROM
 WHERE
     project_id = $1;
 
+-- name: CreateProjectWebhookSettingsSigningSecret :one
+UPDATE
+    project_webhook_settings
+SET
+    signing_secret_ciphertext = $2,
+    update_time = now()
+WHERE
+    project_id = $1
+    AND signing_secret_ciphertext IS NULL
+RETURNING
+    *;
+
 -- name: GetProject :one
 SELECT
     *
@@ -30,3 +42,301 @@ FROM
 WHERE
     id = $1;
 
+
+-- name: ListEnabledWebhookEndpointsByEventType :many
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    project_id = $1
+    AND enabled
+    AND (cardinality(event_types) = 0
+        OR @event_type::varchar = ANY (event_types))
+ORDER BY
+    id;
+
+-- name: GetWebhookEndpoint :one
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    id = $1;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, project_id, webhook_endpoint_id, message_id, event_type, payload, url, attempt, state, status_code, latency_millis, response_body, error)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING
+    *;
+
+-- name: ListEnabledAuditLogStreamIDs :many
+SELECT
+    id
+FROM
+    audit_log_streams
+WHERE
+    enabled;
+
+-- name: GetAuditLogStream :one
+SELECT
+    *
+FROM
+    audit_log_streams
+WHERE
+    id = $1;
+
+-- name: ListAuditLogEventsAfterCursor :many
+SELECT
+    *
+FROM
+    audit_log_events
+WHERE
+    project_id = @project_id
+    AND (organization_id = sqlc.narg ('organization_id')
+        OR sqlc.narg ('organization_id') IS NULL)
+    AND (transaction_id, id) > (@cursor_transaction_id::bigint, @cursor_id::uuid)
+    AND transaction_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY
+    transaction_id,
+    id
+LIMIT $1;
+
+-- name: UpdateAuditLogStreamCursor :exec
+UPDATE
+    audit_log_streams
+SET
+    cursor_transaction_id = $2,
+    cursor_audit_log_event_id = $3,
+    last_error = NULL,
+    last_error_time = NULL
+WHERE
+    id = $1;
+
+-- name: UpdateAuditLogStreamLastError :exec
+UPDATE
+    audit_log_streams
+SET
+    last_error = $2,
+    last_error_time = now()
+WHERE
+    id = $1;
+
+-- name: IncrementWebhookResourceSequence :one
+INSERT INTO webhook_resource_sequences (project_id, resource_key, last_sequence)
+    VALUES ($1, $2, floor(extract(epoch FROM now()) * 1000)::bigint)
+ON CONFLICT (project_id, resource_key)
+    DO UPDATE SET
+        last_sequence = webhook_resource_sequences.last_sequence + 1,
+        update_time = now()
+    RETURNING
+        last_sequence;
+
+-- name: DeleteStaleWebhookResourceSequences :execrows
+DELETE FROM webhook_resource_sequences
+WHERE update_time < $1
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            webhook_outbox_events
+        WHERE
+            webhook_outbox_events.project_id = webhook_resource_sequences.project_id
+            AND webhook_outbox_events.resource_key = webhook_resource_sequences.resource_key);
+
+-- name: CreateWebhookOutboxEvent :one
+INSERT INTO webhook_outbox_events (id, project_id, resource_key, sequence, event_type, payload)
+    VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING
+    *;
+
+-- name: ListWebhookOutboxEvents :many
+SELECT
+    *
+FROM
+    webhook_outbox_events
+WHERE
+    project_id = $1
+    AND resource_key = $2
+ORDER BY
+    sequence
+LIMIT $3;
+
+-- name: DeleteWebhookOutboxEvent :exec
+DELETE FROM webhook_outbox_events
+WHERE id = $1;
+
+-- name: IncrementWebhookOutboxEventAttempts :one
+UPDATE
+    webhook_outbox_events
+SET
+    attempts = attempts + 1,
+    next_attempt_time = $2
+WHERE
+    id = $1
+RETURNING
+    attempts;
+
+-- name: TryAdvisoryLock :one
+SELECT
+    pg_try_advisory_lock(hashtextextended(@lock_key::text, 0));
+
+-- name: AdvisoryUnlock :one
+SELECT
+    pg_advisory_unlock(hashtextextended(@lock_key::text, 0));
+
+-- name: GetProjectUISettings :one
+SELECT
+    *
+FROM
+    project_ui_settings
+WHERE
+    project_id = $1;
+
+-- name: ListEmailTemplatesByProjectIDAndType :many
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    project_id = $1
+    AND type = $2;
+
+-- name: GetEmailLocalesByProjectIDAndEmail :one
+SELECT
+    users.locale AS user_locale,
+    organizations.locale AS organization_locale
+FROM
+    users
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    organizations.project_id = $1
+    AND users.email = $2
+ORDER BY
+    users.update_time DESC
+LIMIT 1;
+
+-- name: GetEmailSuppression :one
+SELECT
+    *
+FROM
+    email_suppressions
+WHERE
+    project_id = $1
+    AND email_address = lower(@email_address::varchar);
+
+-- name: CreateEmailSuppressionIfNotExists :many
+INSERT INTO email_suppressions (id, project_id, email_address, reason)
+    VALUES ($1, $2, lower(@email_address::varchar), $3)
+ON CONFLICT (project_id, email_address)
+    DO NOTHING
+RETURNING
+    *;
+
+-- name: CreateEmailEvent :one
+INSERT INTO email_events (id, project_id, message_id, email_address, type, user_invite_id, detail)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+    *;
+
+-- name: GetSentEmailEventByMessageID :one
+SELECT
+    *
+FROM
+    email_events
+WHERE
+    message_id = $1
+    AND type = 'sent';
+
+-- name: ListProjectIDsByEmailSendFromDomain :many
+SELECT
+    id
+FROM
+    projects
+WHERE
+    email_send_from_domain = $1;
+
+-- name: UpdateUserInviteEmailDeliveryStatus :exec
+UPDATE
+    user_invites
+SET
+    email_delivery_status = $2
+WHERE
+    id = $1;
+
+-- name: MarkExpiredBackendAPIKeysLogged :many
+UPDATE
+    backend_api_keys
+SET
+    expire_logged = TRUE
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            backend_api_keys
+        WHERE
+            expire_time <= now()
+            AND NOT expire_logged
+        ORDER BY
+            expire_time
+        LIMIT $1
+        FOR UPDATE
+            SKIP LOCKED)
+RETURNING
+    *;
+
+-- name: ExpireAPIKeyPreviousSecretTokens :many
+UPDATE
+    api_keys
+SET
+    previous_secret_token_sha256 = NULL
+FROM
+    organizations
+WHERE
+    api_keys.organization_id = organizations.id
+    AND api_keys.id IN (
+        SELECT
+            id
+        FROM
+            api_keys
+        WHERE
+            previous_secret_token_sha256 IS NOT NULL
+            AND previous_secret_token_expire_time <= now()
+        ORDER BY
+            previous_secret_token_expire_time
+        LIMIT $1
+        FOR UPDATE
+            SKIP LOCKED)
+RETURNING
+    api_keys.id,
+    api_keys.organization_id,
+    api_keys.previous_secret_token_expire_time,
+    organizations.project_id;
+
+-- name: CreateAuditLogEvent :exec
+INSERT INTO audit_log_events (id, project_id, organization_id, resource_type, resource_id, event_name, event_time, event_details)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListExpiredUserRoleAssignments :many
+SELECT
+    user_role_assignments.id,
+    user_role_assignments.user_id,
+    user_role_assignments.expire_time,
+    users.organization_id,
+    organizations.project_id
+FROM
+    user_role_assignments
+    JOIN users ON user_role_assignments.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    user_role_assignments.expire_time <= now()
+ORDER BY
+    user_role_assignments.expire_time
+LIMIT $1
+FOR UPDATE
+    OF user_role_assignments SKIP LOCKED;
+
+-- name: DeleteUserRoleAssignment :exec
+DELETE FROM user_role_assignments
+WHERE id = $1;
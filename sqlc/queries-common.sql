@@ -62,6 +62,14 @@ FROM
 WHERE
     id = $1;
 
+-- name: GetOrganizationIPAllowlistCIDRs :many
+SELECT
+    cidr
+FROM
+    organization_ip_allowlist_cidrs
+WHERE
+    organization_id = $1;
+
 -- name: GetProjectActions :many
 SELECT
     name
@@ -71,16 +79,44 @@ WHERE
     project_id = $1;
 
 -- name: GetUserActions :many
+WITH RECURSIVE user_roles (role_id) AS (
+    SELECT
+        user_role_assignments.role_id
+    FROM
+        user_role_assignments
+    WHERE
+        user_role_assignments.user_id = @user_id
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN user_roles ON role_inherited_roles.role_id = user_roles.role_id
+)
 SELECT DISTINCT
-    (actions.name)
+    actions.name
 FROM
-    users
-    JOIN user_role_assignments ON users.id = user_role_assignments.user_id
-    JOIN roles ON user_role_assignments.role_id = roles.id
-    JOIN role_actions ON roles.id = role_actions.role_id
-    JOIN actions ON role_actions.action_id = actions.id
+    user_roles
+    JOIN roles ON user_roles.role_id = roles.id
+    JOIN actions ON roles.project_id = actions.project_id
 WHERE
-    user_id = $1;
+    EXISTS (
+        SELECT
+            1
+        FROM
+            role_actions
+        WHERE
+            role_actions.role_id = roles.id
+            AND role_actions.action_id = actions.id)
+    OR EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(roles.action_wildcards) AS action_wildcard (pattern)
+        WHERE
+            starts_with (actions.name, rtrim(action_wildcard.pattern, '*')));
 
 -- name: GetCurrentSessionSigningKeyByProjectID :one
 SELECT
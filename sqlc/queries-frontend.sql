@@ -46,6 +46,7 @@ SET
     log_in_with_saml = $11,
     log_in_with_authenticator_app = $7,
     log_in_with_passkey = $8,
+    log_in_with_passwordless_passkey = $12,
     require_mfa = $9
 WHERE
     id = $1
@@ -269,7 +270,8 @@ UPDATE
     users
 SET
     update_time = now(),
-    password_bcrypt = $2
+    password_bcrypt = $2,
+    password_update_time = now()
 WHERE
     id = $1
 RETURNING
@@ -319,6 +321,28 @@ WHERE
 RETURNING
     *;
 
+-- name: ListUnrevokedUserAPIKeyIDs :many
+SELECT
+    id
+FROM
+    api_keys
+WHERE
+    user_id = $1
+    AND secret_token_sha256 IS NOT NULL;
+
+-- name: RevokeUserAPIKeys :exec
+UPDATE
+    api_keys
+SET
+    update_time = now(),
+    secret_token_sha256 = NULL,
+    secret_token_suffix = NULL,
+    previous_secret_token_sha256 = NULL,
+    previous_secret_token_expire_time = NULL
+WHERE
+    user_id = $1
+    AND secret_token_sha256 IS NOT NULL;
+
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
@@ -477,6 +501,58 @@ INSERT INTO organization_microsoft_tenant_ids (id, organization_id, microsoft_te
 RETURNING
     *;
 
+-- name: GetOrganizationIPAllowlistCIDRs :many
+SELECT
+    *
+FROM
+    organization_ip_allowlist_cidrs
+WHERE
+    organization_id = $1
+ORDER BY
+    cidr;
+
+-- name: DeleteOrganizationIPAllowlistCIDRs :exec
+DELETE FROM organization_ip_allowlist_cidrs
+WHERE organization_id = $1;
+
+-- name: CreateOrganizationIPAllowlistCIDR :one
+INSERT INTO organization_ip_allowlist_cidrs (id, organization_id, cidr)
+    VALUES ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: GetOrganizationPasskeyAAGUIDs :many
+SELECT
+    *
+FROM
+    organization_passkey_aaguids
+WHERE
+    organization_id = $1
+ORDER BY
+    aaguid;
+
+-- name: DeleteOrganizationPasskeyAAGUIDs :exec
+DELETE FROM organization_passkey_aaguids
+WHERE organization_id = $1;
+
+-- name: CreateOrganizationPasskeyAAGUID :one
+INSERT INTO organization_passkey_aaguids (id, organization_id, aaguid, allowed)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+-- name: UpdateOrganizationPasskeyPolicy :one
+UPDATE
+    organizations
+SET
+    update_time = now(),
+    passkey_require_attestation = $2,
+    passkey_require_user_verification = $3
+WHERE
+    id = $1
+RETURNING
+    *;
+
 -- name: ListSwitchableOrganizations :many
 SELECT
     id,
@@ -553,8 +629,8 @@ WHERE
         OR organization_id = $3);
 
 -- name: CreateRole :one
-INSERT INTO roles (id, project_id, organization_id, display_name, description)
-    VALUES ($1, $2, $3, $4, $5)
+INSERT INTO roles (id, project_id, organization_id, display_name, description, action_wildcards)
+    VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING
     *;
 
@@ -580,12 +656,45 @@ UPDATE
 SET
     update_time = now(),
     display_name = $2,
-    description = $3
+    description = $3,
+    action_wildcards = $4
 WHERE
     id = $1
 RETURNING
     *;
 
+-- name: BatchGetRoleInheritedRolesByRoleID :many
+SELECT
+    *
+FROM
+    role_inherited_roles
+WHERE
+    role_id = ANY ($1::uuid[]);
+
+-- name: CreateRoleInheritedRole :exec
+INSERT INTO role_inherited_roles (id, role_id, inherited_role_id)
+    VALUES ($1, $2, $3);
+
+-- name: DeleteRoleInheritedRoles :exec
+DELETE FROM role_inherited_roles
+WHERE role_id = $1;
+
+-- name: ListTransitivelyInheritedRoleIDs :many
+WITH RECURSIVE inherited_roles (role_id) AS (
+    SELECT
+        unnest(@role_ids::uuid[])
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN inherited_roles ON role_inherited_roles.role_id = inherited_roles.role_id
+)
+SELECT
+    role_id::uuid
+FROM
+    inherited_roles;
+
 -- name: DeleteRoleActionsByActionIDNotInList :exec
 DELETE FROM role_actions
 WHERE role_id = $1
@@ -632,10 +741,16 @@ WHERE
         OR roles.organization_id = $3);
 
 -- name: UpsertUserRoleAssignment :exec
-INSERT INTO user_role_assignments (id, role_id, user_id)
-    VALUES ($1, $2, $3)
+INSERT INTO user_role_assignments (id, role_id, user_id, expire_time)
+    VALUES ($1, $2, $3, $4)
 ON CONFLICT (role_id, user_id)
-    DO NOTHING;
+    DO UPDATE SET
+        expire_time = CASE WHEN user_role_assignments.expire_time IS NULL
+            OR excluded.expire_time IS NULL THEN
+            NULL
+        ELSE
+            greatest (user_role_assignments.expire_time, excluded.expire_time)
+        END;
 
 -- name: GetUserRoleAssignmentByUserAndRole :one
 SELECT
@@ -650,6 +765,52 @@ WHERE
 DELETE FROM user_role_assignments
 WHERE id = $1;
 
+-- name: ListRoleElevationRequests :many
+SELECT
+    role_elevation_requests.*
+FROM
+    role_elevation_requests
+    JOIN users ON role_elevation_requests.user_id = users.id
+WHERE
+    users.organization_id = @organization_id
+    AND (sqlc.narg(user_id)::uuid IS NULL
+        OR role_elevation_requests.user_id = sqlc.narg(user_id)::uuid)
+    AND (sqlc.narg(status)::role_elevation_request_status IS NULL
+        OR role_elevation_requests.status = sqlc.narg(status)::role_elevation_request_status)
+    AND role_elevation_requests.id >= @id
+ORDER BY
+    role_elevation_requests.id
+LIMIT @page_limit;
+
+-- name: GetRoleElevationRequest :one
+SELECT
+    role_elevation_requests.*
+FROM
+    role_elevation_requests
+    JOIN users ON role_elevation_requests.user_id = users.id
+WHERE
+    role_elevation_requests.id = $1
+    AND users.organization_id = $2;
+
+-- name: CreateRoleElevationRequest :one
+INSERT INTO role_elevation_requests (id, user_id, role_id, reason, duration_seconds)
+    VALUES ($1, $2, $3, $4, $5)
+RETURNING
+    *;
+
+-- name: ReviewRoleElevationRequest :one
+UPDATE
+    role_elevation_requests
+SET
+    status = $2,
+    reviewer_user_id = $3,
+    review_time = now()
+WHERE
+    id = $1
+    AND status = 'pending'
+RETURNING
+    *;
+
 -- name: GetProjectWebhookSettings :one
 SELECT
     *
@@ -659,8 +820,8 @@ WHERE
     project_id = $1;
 
 -- name: CreateAPIKey :one
-INSERT INTO api_keys (id, organization_id, display_name, secret_token_sha256, secret_token_suffix, expire_time)
-    VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO api_keys (id, organization_id, display_name, secret_token_sha256, secret_token_suffix, expire_time, rate_limit_per_minute, user_id, actions)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING
     *;
 
@@ -675,7 +836,8 @@ UPDATE
     api_keys
 SET
     update_time = now(),
-    display_name = $2
+    display_name = $2,
+    rate_limit_per_minute = $4
 WHERE
     id = $1
     AND organization_id = $3
@@ -699,6 +861,8 @@ FROM
 WHERE
     organization_id = $1
     AND id >= $2
+    AND (sqlc.narg (user_id)::uuid IS NULL
+        OR user_id = sqlc.narg (user_id))
 ORDER BY
     id
 LIMIT $3;
@@ -709,13 +873,34 @@ UPDATE
 SET
     update_time = now(),
     secret_token_sha256 = NULL,
-    secret_token_suffix = NULL
+    secret_token_suffix = NULL,
+    previous_secret_token_sha256 = NULL,
+    previous_secret_token_expire_time = NULL
 WHERE
     id = $1
     AND organization_id = $2
 RETURNING
     *;
 
+-- name: RotateAPIKey :one
+UPDATE
+    api_keys
+SET
+    update_time = now(),
+    previous_secret_token_sha256 = CASE WHEN sqlc.narg (previous_secret_token_expire_time)::timestamptz IS NULL THEN
+        NULL
+    ELSE
+        secret_token_sha256
+    END,
+    previous_secret_token_expire_time = sqlc.narg (previous_secret_token_expire_time),
+    secret_token_sha256 = $2,
+    secret_token_suffix = $3
+WHERE
+    id = $1
+    AND organization_id = $4
+RETURNING
+    *;
+
 -- name: CreateAPIKeyRoleAssignment :one
 INSERT INTO api_key_role_assignments (id, api_key_id, role_id)
     VALUES ($1, $2, $3)
@@ -723,16 +908,82 @@ RETURNING
     *;
 
 -- name: GetAPIKeyActions :many
+WITH RECURSIVE api_key_roles (role_id) AS (
+    SELECT
+        api_key_role_assignments.role_id
+    FROM
+        api_key_role_assignments
+    WHERE
+        api_key_role_assignments.api_key_id = $1
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN api_key_roles ON role_inherited_roles.role_id = api_key_roles.role_id
+)
 SELECT DISTINCT
-    (actions.name)
+    actions.name
 FROM
-    api_keys
-    JOIN api_key_role_assignments ON api_key.id = api_key_role_assignments.api_key_id
-    JOIN roles ON api_key_role_assignments.role_id = roles.id
-    JOIN role_actions ON roles.id = role_actions.role_id
-    JOIN actions ON role_actions.action_id = actions.id
+    api_key_roles
+    JOIN roles ON api_key_roles.role_id = roles.id
+    JOIN actions ON roles.project_id = actions.project_id
 WHERE
-    api_key_id = $1;
+    EXISTS (
+        SELECT
+            1
+        FROM
+            role_actions
+        WHERE
+            role_actions.role_id = roles.id
+            AND role_actions.action_id = actions.id)
+    OR EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(roles.action_wildcards) AS action_wildcard (pattern)
+        WHERE
+            starts_with (actions.name, rtrim(action_wildcard.pattern, '*')));
+
+-- name: GetUserActions :many
+WITH RECURSIVE user_roles (role_id) AS (
+    SELECT
+        user_role_assignments.role_id
+    FROM
+        user_role_assignments
+    WHERE
+        user_role_assignments.user_id = @user_id
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN user_roles ON role_inherited_roles.role_id = user_roles.role_id
+)
+SELECT DISTINCT
+    actions.name
+FROM
+    user_roles
+    JOIN roles ON user_roles.role_id = roles.id
+    JOIN actions ON roles.project_id = actions.project_id
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            role_actions
+        WHERE
+            role_actions.role_id = roles.id
+            AND role_actions.action_id = actions.id)
+    OR EXISTS (
+        SELECT
+            1
+        FROM
+            unnest(roles.action_wildcards) AS action_wildcard (pattern)
+        WHERE
+            starts_with (actions.name, rtrim(action_wildcard.pattern, '*')));
 
 -- name: GetAPIKeyRoleAssignment :one
 SELECT
@@ -822,3 +1073,84 @@ LIMIT $1;
 -- ORDER BY
 --     event_time DESC
 -- LIMIT $1;
+
+-- name: GetProjectPasswordPolicy :one
+SELECT
+    *
+FROM
+    project_password_policies
+WHERE
+    project_id = $1;
+
+-- name: GetOrganizationPasswordPolicy :one
+SELECT
+    *
+FROM
+    organization_password_policies
+WHERE
+    organization_id = $1;
+
+-- name: UpsertOrganizationPasswordPolicy :one
+INSERT INTO organization_password_policies (organization_id, min_length, require_lowercase, require_uppercase, require_digit, require_symbol, disallow_personal_info, history_count, max_age_days, lockout_attempts, lockout_duration_seconds)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (organization_id)
+    DO UPDATE SET
+        min_length = excluded.min_length,
+        require_lowercase = excluded.require_lowercase,
+        require_uppercase = excluded.require_uppercase,
+        require_digit = excluded.require_digit,
+        require_symbol = excluded.require_symbol,
+        disallow_personal_info = excluded.disallow_personal_info,
+        history_count = excluded.history_count,
+        max_age_days = excluded.max_age_days,
+        lockout_attempts = excluded.lockout_attempts,
+        lockout_duration_seconds = excluded.lockout_duration_seconds
+    RETURNING
+        *;
+
+-- name: ListUserPasswordHistoryBcrypts :many
+SELECT
+    password_bcrypt
+FROM
+    user_password_histories
+WHERE
+    user_id = $1
+ORDER BY
+    create_time DESC
+LIMIT $2;
+
+-- name: CreateUserPasswordHistory :one
+INSERT INTO user_password_histories (id, user_id, password_bcrypt)
+    VALUES ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: DeleteUserPasswordHistoriesBeyondCount :exec
+DELETE FROM user_password_histories
+WHERE user_password_histories.user_id = $1
+    AND user_password_histories.id NOT IN (
+        SELECT
+            recent.id
+        FROM
+            user_password_histories AS recent
+        WHERE
+            recent.user_id = $1
+        ORDER BY
+            recent.create_time DESC
+        LIMIT $2);
+
+-- name: ListAPIKeyUsageBuckets :many
+SELECT
+    date_trunc(@granularity::text, start_time)::timestamp with time zone AS start_time,
+    sum(request_count)::bigint AS request_count,
+    sum(rate_limited_count)::bigint AS rate_limited_count
+FROM
+    api_key_usage_buckets
+WHERE
+    api_key_id = @api_key_id
+    AND start_time >= @start_time
+    AND start_time < @end_time
+GROUP BY
+    1
+ORDER BY
+    1;
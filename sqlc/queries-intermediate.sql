@@ -70,8 +70,12 @@ RETURNING
     *;
 
 -- name: CreateUser :one
-INSERT INTO users (id, organization_id, email, display_name, profile_picture_url, google_user_id, microsoft_user_id, github_user_id, is_owner, password_bcrypt)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+INSERT INTO users (id, organization_id, email, display_name, profile_picture_url, google_user_id, microsoft_user_id, github_user_id, is_owner, password_bcrypt, password_update_time)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CASE WHEN $10::varchar IS NULL THEN
+            NULL
+        ELSE
+            now()
+        END)
 RETURNING
     *;
 
@@ -139,6 +143,22 @@ WHERE
     id = $1
     AND project_id = $2;
 
+-- name: GetOrganizationIPAllowlistCIDRs :many
+SELECT
+    cidr
+FROM
+    organization_ip_allowlist_cidrs
+WHERE
+    organization_id = $1;
+
+-- name: GetOrganizationPasskeyAAGUIDs :many
+SELECT
+    *
+FROM
+    organization_passkey_aaguids
+WHERE
+    organization_id = $1;
+
 -- name: GetProjectByID :one
 SELECT
     *
@@ -782,7 +802,12 @@ SET
     microsoft_user_id = coalesce(sqlc.narg (microsoft_user_id), microsoft_user_id),
     display_name = coalesce(sqlc.narg (display_name), display_name),
     profile_picture_url = coalesce(sqlc.narg (profile_picture_url), profile_picture_url),
-    password_bcrypt = coalesce(sqlc.narg (password_bcrypt), password_bcrypt)
+    password_bcrypt = coalesce(sqlc.narg (password_bcrypt), password_bcrypt),
+    password_update_time = CASE WHEN sqlc.narg (password_bcrypt)::varchar IS NULL THEN
+        password_update_time
+    ELSE
+        now()
+    END
 WHERE
     id = $1
 RETURNING
@@ -808,3 +833,133 @@ INSERT INTO publishable_keys (id, project_id, display_name, dev_mode)
 RETURNING
     *;
 
+
+-- name: GetProjectPasskeyByCredentialID :one
+SELECT
+    passkeys.*
+FROM
+    passkeys
+    JOIN users ON passkeys.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    passkeys.credential_id = $1
+    AND passkeys.disabled = FALSE
+    AND organizations.project_id = $2;
+
+-- name: UpdateIntermediateSessionPasskeyLogin :one
+UPDATE
+    intermediate_sessions
+SET
+    email = $1,
+    organization_id = $2,
+    primary_auth_factor = 'passkey',
+    verified_passkey_id = $3,
+    passkey_verify_challenge_sha256 = NULL,
+    passkey_verified = TRUE,
+    update_time = now()
+WHERE
+    id = $4
+RETURNING
+    *;
+
+-- name: GetEmailVerifiedByPasskeyID :one
+SELECT
+    EXISTS (
+        SELECT
+            *
+        FROM
+            passkeys
+            JOIN users ON passkeys.user_id = users.id
+        WHERE
+            passkeys.id = $1
+            AND passkeys.disabled = FALSE
+            AND users.organization_id = $2
+            AND users.email = $3);
+
+-- name: UpdatePasskeySignCount :one
+UPDATE
+    passkeys
+SET
+    sign_count = $2,
+    update_time = now()
+WHERE
+    id = $1
+    AND (sign_count < $2
+        OR (sign_count = 0 AND $2 = 0))
+RETURNING
+    *;
+
+-- name: GetProjectPasswordPolicy :one
+SELECT
+    *
+FROM
+    project_password_policies
+WHERE
+    project_id = $1;
+
+-- name: GetOrganizationPasswordPolicy :one
+SELECT
+    *
+FROM
+    organization_password_policies
+WHERE
+    organization_id = $1;
+
+-- name: ListUserPasswordHistoryBcrypts :many
+SELECT
+    password_bcrypt
+FROM
+    user_password_histories
+WHERE
+    user_id = $1
+ORDER BY
+    create_time DESC
+LIMIT $2;
+
+-- name: CreateUserPasswordHistory :one
+INSERT INTO user_password_histories (id, user_id, password_bcrypt)
+    VALUES ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: DeleteUserPasswordHistoriesBeyondCount :exec
+DELETE FROM user_password_histories
+WHERE user_password_histories.user_id = $1
+    AND user_password_histories.id NOT IN (
+        SELECT
+            recent.id
+        FROM
+            user_password_histories AS recent
+        WHERE
+            recent.user_id = $1
+        ORDER BY
+            recent.create_time DESC
+        LIMIT $2);
+
+-- name: UpdateIntermediateSessionPasswordExpired :one
+UPDATE
+    intermediate_sessions
+SET
+    password_expired = TRUE
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: CreateDefaultUserRoleAssignments :many
+INSERT INTO user_role_assignments (id, role_id, user_id)
+SELECT
+    gen_random_uuid (),
+    roles.id,
+    users.id
+FROM
+    users
+    JOIN organizations ON users.organization_id = organizations.id
+    JOIN roles ON organizations.project_id = roles.project_id
+WHERE
+    users.id = $1
+    AND roles.default_role
+ON CONFLICT (role_id, user_id)
+    DO NOTHING
+RETURNING
+    *;
@@ -70,6 +70,28 @@ WHERE
 RETURNING
     *;
 
+-- name: ListUnrevokedUserAPIKeyIDs :many
+SELECT
+    id
+FROM
+    api_keys
+WHERE
+    user_id = $1
+    AND secret_token_sha256 IS NOT NULL;
+
+-- name: RevokeUserAPIKeys :exec
+UPDATE
+    api_keys
+SET
+    update_time = now(),
+    secret_token_sha256 = NULL,
+    secret_token_suffix = NULL,
+    previous_secret_token_sha256 = NULL,
+    previous_secret_token_expire_time = NULL
+WHERE
+    user_id = $1
+    AND secret_token_sha256 IS NOT NULL;
+
 -- name: DeleteUser :one
 DELETE FROM users
 WHERE id = $1
@@ -83,3 +105,21 @@ INSERT INTO audit_log_events (id, project_id, organization_id, actor_scim_api_ke
 RETURNING
     *;
 
+
+-- name: CreateDefaultUserRoleAssignments :many
+INSERT INTO user_role_assignments (id, role_id, user_id)
+SELECT
+    gen_random_uuid (),
+    roles.id,
+    users.id
+FROM
+    users
+    JOIN organizations ON users.organization_id = organizations.id
+    JOIN roles ON organizations.project_id = roles.project_id
+WHERE
+    users.id = $1
+    AND roles.default_role
+ON CONFLICT (role_id, user_id)
+    DO NOTHING
+RETURNING
+    *;
@@ -68,8 +68,13 @@ export function useRedirectNextLoginFlowPage(): () => void {
       return;
     }
 
+    // passkey logins don't also require a password
+    const isPasskeyLogin =
+      intermediateSession.primaryAuthFactor === PrimaryAuthFactor.PASSKEY;
+
     // verify password if there is one registered, and it's not already verified
     if (
+      !isPasskeyLogin &&
       organization.logInWithPassword &&
       organization.userHasPassword &&
       !intermediateSession.passwordVerified
@@ -110,6 +115,7 @@ export function useRedirectNextLoginFlowPage(): () => void {
     // register a password if the org uses them and the user + intermediate
     // session doesn't have one registered
     if (
+      !isPasskeyLogin &&
       organization.logInWithPassword &&
       !organization.userHasPassword &&
       !intermediateSession.passwordVerified
@@ -118,6 +124,15 @@ export function useRedirectNextLoginFlowPage(): () => void {
       return;
     }
 
+    // users whose password has expired must choose a new one
+    if (
+      intermediateSession.passwordExpired &&
+      !intermediateSession.newUserPasswordRegistered
+    ) {
+      navigate(`/register-password`);
+      return;
+    }
+
     // Check for needing to register a secondary factor. Users only need to
     // register these if the organization requires MFA.
     //
@@ -176,6 +191,8 @@ function isPrimaryAuthFactorAcceptable(
       return organization.logInWithMicrosoft;
     case PrimaryAuthFactor.GITHUB:
       return organization.logInWithGithub;
+    case PrimaryAuthFactor.PASSKEY:
+      return organization.logInWithPasskey;
     default:
       return false;
   }
@@ -1,7 +1,7 @@
 import { ConnectError } from "@connectrpc/connect";
 import { useMutation, useQuery } from "@connectrpc/connect-query";
 import { zodResolver } from "@hookform/resolvers/zod";
-import { LoaderCircleIcon } from "lucide-react";
+import { FingerprintIcon, LoaderCircleIcon } from "lucide-react";
 import React, { useEffect, useState } from "react";
 import { useForm } from "react-hook-form";
 import { useNavigate } from "react-router";
@@ -37,10 +37,12 @@ import {
   getGoogleOAuthRedirectURL,
   getMicrosoftOAuthRedirectURL,
   issueEmailVerificationChallenge,
+  issuePasskeyLoginChallenge,
   listOIDCOrganizations,
   listSAMLOrganizations,
   setEmailAsPrimaryLoginFactor,
   setPasswordAsPrimaryLoginFactor,
+  verifyPasskeyLogin,
   verifyPassword,
 } from "@/gen/tesseral/intermediate/v1/intermediate-IntermediateService_connectquery";
 import { useLoginPageQueryParams } from "@/hooks/use-login-page-query-params";
@@ -49,6 +51,7 @@ import {
   ProjectSettingsProvider,
   useProjectSettings,
 } from "@/lib/project-settings";
+import { base64urlEncode } from "@/lib/utils";
 
 export function LoginPage() {
   return (
@@ -259,6 +262,40 @@ function LoginPageContents() {
     window.location.href = `/api/oidc/v1/${oidcConnectionId}/init`;
   }
 
+  const { mutateAsync: issuePasskeyLoginChallengeAsync } = useMutation(
+    issuePasskeyLoginChallenge,
+  );
+  const { mutateAsync: verifyPasskeyLoginAsync } =
+    useMutation(verifyPasskeyLogin);
+
+  async function handleLogInWithPasskey() {
+    await createIntermediateSessionWithRelayedSessionState();
+    const challengeResponse = await issuePasskeyLoginChallengeAsync({});
+
+    // allowCredentials is left empty, so that the authenticator offers any
+    // discoverable credential it has for this rp id
+    const credential = (await navigator.credentials.get({
+      publicKey: {
+        challenge: new Uint8Array(challengeResponse.challenge).buffer,
+        allowCredentials: [],
+        rpId: challengeResponse.rpId,
+        userVerification: "required",
+        timeout: 60000,
+      },
+    })) as PublicKeyCredential;
+
+    const response = credential.response as AuthenticatorAssertionResponse;
+
+    await verifyPasskeyLoginAsync({
+      authenticatorData: base64urlEncode(response.authenticatorData),
+      clientDataJson: base64urlEncode(response.clientDataJSON),
+      credentialId: new Uint8Array(credential.rawId),
+      signature: base64urlEncode(response.signature),
+    });
+
+    redirectNextLoginFlowPage();
+  }
+
   const { data: listSAMLOrganizationsResponse } = useQuery(
     listSAMLOrganizations,
     {
@@ -282,7 +319,8 @@ function LoginPageContents() {
   const hasAboveFoldMethod =
     settings.logInWithGoogle ||
     settings.logInWithMicrosoft ||
-    settings.logInWithGithub;
+    settings.logInWithGithub ||
+    settings.logInWithPasswordlessPasskey;
   const hasBelowFoldMethod =
     settings.logInWithEmail ||
     settings.logInWithPassword ||
@@ -333,6 +371,16 @@ function LoginPageContents() {
                 Log in with GitHub
               </Button>
             )}
+            {settings.logInWithPasswordlessPasskey && (
+              <Button
+                className="w-full"
+                variant="outline"
+                onClick={handleLogInWithPasskey}
+              >
+                <FingerprintIcon />
+                Log in with Passkey
+              </Button>
+            )}
           </div>
 
           {hasAboveFoldMethod && hasBelowFoldMethod && (
@@ -58,6 +58,7 @@ export function RegisterPasskeyPage() {
         (credential.response as AuthenticatorAttestationResponse)
           .attestationObject,
       ),
+      clientDataJson: base64urlEncode(credential.response.clientDataJSON),
     });
 
     redirectNextLoginFlowPage();
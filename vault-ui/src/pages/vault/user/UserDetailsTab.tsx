@@ -1,3 +1,4 @@
+import { ConnectError } from "@connectrpc/connect";
 import { useMutation, useQuery } from "@connectrpc/connect-query";
 import { zodResolver } from "@hookform/resolvers/zod";
 import { RotateCcwKey, TriangleAlert } from "lucide-react";
@@ -40,6 +41,7 @@ import {
   updateMe,
   whoami,
 } from "@/gen/tesseral/frontend/v1/frontend-FrontendService_connectquery";
+import { parseErrorMessage } from "@/lib/errors";
 
 const schema = z.object({
   displayName: z.string().optional(),
@@ -200,7 +202,18 @@ function DangerZoneCard() {
       form.reset();
       toast.success("Password changed successfully.");
       setResetOpen(false);
-    } catch {
+    } catch (e) {
+      if (
+        e instanceof ConnectError &&
+        e.rawMessage === "password_policy_violation"
+      ) {
+        form.setError("password", {
+          type: "manual",
+          message: parseErrorMessage(e),
+        });
+        return;
+      }
+
       toast.error("Failed to change password. Please try again.");
       setResetOpen(false);
     }
@@ -284,6 +284,7 @@ function RegisterPasskeyButton() {
         (credential.response as AuthenticatorAttestationResponse)
           .attestationObject,
       ),
+      clientDataJson: base64urlEncode(credential.response.clientDataJSON),
     });
 
     await refetch();